@@ -0,0 +1,10 @@
+package analytics
+
+// Compounding est la fréquence de capitalisation des intérêts d'un compte rémunéré
+type Compounding string
+
+const (
+	CompoundDaily   Compounding = "daily"   // Intérêts capitalisés chaque jour
+	CompoundMonthly Compounding = "monthly" // Intérêts courus crédités en fin de mois
+	CompoundYearly  Compounding = "yearly"  // Intérêts courus crédités au 31 décembre (livrets réglementés)
+)
@@ -0,0 +1,143 @@
+// Package analytics regroupe les calculs financiers qui ne dépendent pas du
+// portefeuille : conventions de décompte des jours et de capitalisation, TRI, volatilité,
+// corrélations, mesures de risque et résolution de taux. Il ne manipule que des dates,
+// des montants et des rendements.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DayCount est la convention de décompte des jours convertissant une durée en années
+type DayCount string
+
+const (
+	DayCountActual36525 DayCount = "act/365.25" // Jours réels / 365,25 (convention par défaut)
+	DayCountActual365   DayCount = "act/365"    // Jours réels / 365
+	DayCountActual360   DayCount = "act/360"    // Jours réels / 360 (marché monétaire)
+	DayCount30360       DayCount = "30/360"     // Mois de 30 jours, années de 360 jours (30E/360)
+)
+
+// CompoundContinuous capitalise les intérêts en continu : une année au taux r multiplie
+// la valeur par e^r. Réservée aux conventions de calcul, pas aux comptes rémunérés.
+const CompoundContinuous Compounding = "continuous"
+
+// RateConventions fixe la façon dont les taux annuels sont calculés et appliqués :
+// décompte des jours des durées, et capitalisation du taux (un taux de 5% capitalisé
+// mensuellement fait croître une valeur de (1+0,05/12)^12 par an). Les taux de
+// performance, de projection et de référence suivent les mêmes conventions ; le TRI
+// reste un taux actuariel annuel, calculé sur les durées de la convention.
+type RateConventions struct {
+	DayCount             DayCount    `json:"day_count,omitempty"`              // act/365.25 si vide
+	Compounding          Compounding `json:"compounding,omitempty"`            // yearly si vide
+	MinAnnualizationDays int         `json:"min_annualization_days,omitempty"` // Durée minimale d'un rendement annualisé (jours), DefaultMinAnnualizationDays si nul
+}
+
+// DefaultMinAnnualizationDays est la durée en deçà de laquelle un rendement n'est pas
+// annualisé : sur quelques semaines, l'annualisation amplifie le moindre écart
+const DefaultMinAnnualizationDays = 90
+
+// CheckAnnualization refuse d'annualiser un rendement sur une période plus courte que
+// la durée minimale des conventions
+func (c RateConventions) CheckAnnualization(from, to time.Time) error {
+	minDays := c.MinAnnualizationDays
+	if minDays == 0 {
+		minDays = DefaultMinAnnualizationDays
+	}
+	if days := int(to.Sub(from).Hours() / 24); days < minDays {
+		return fmt.Errorf("%d jours, minimum %d: %w", days, minDays, ErrPeriodTooShort)
+	}
+	return nil
+}
+
+// YearFraction retourne la durée en années entre deux dates selon la convention
+func (d DayCount) YearFraction(from, to time.Time) float64 {
+	days := to.Sub(from).Hours() / 24
+	switch d {
+	case DayCountActual365:
+		return days / 365
+	case DayCountActual360:
+		return days / 360
+	case DayCount30360:
+		d1, d2 := min(from.Day(), 30), min(to.Day(), 30)
+		days360 := 360*(to.Year()-from.Year()) + 30*(int(to.Month())-int(from.Month())) + d2 - d1
+		return float64(days360) / 360
+	default:
+		return days / 365.25
+	}
+}
+
+// AddYearFraction est l'inverse de YearFraction : la date située years années après from
+// selon la convention
+func (d DayCount) AddYearFraction(from time.Time, years float64) time.Time {
+	daysPerYear := 365.25
+	switch d {
+	case DayCountActual365:
+		daysPerYear = 365
+	case DayCountActual360:
+		daysPerYear = 360
+	case DayCount30360:
+		// Mois entiers de 30 jours, puis jours restants
+		days360 := years * 360
+		months := math.Floor(days360 / 30)
+		return from.AddDate(0, int(months), 0).Add(time.Duration((days360 - months*30) * 24 * float64(time.Hour)))
+	}
+	return from.Add(time.Duration(years * daysPerYear * 24 * float64(time.Hour)))
+}
+
+// PeriodsPerYear retourne le nombre de capitalisations par an, 0 en continu
+func (c Compounding) PeriodsPerYear() float64 {
+	switch c {
+	case CompoundMonthly:
+		return 12
+	case CompoundDaily:
+		return 365
+	case CompoundContinuous:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// YearsBetween retourne la durée en années entre deux dates selon le décompte des jours
+// des conventions (ACT/365.25 par défaut)
+func (c RateConventions) YearsBetween(from, to time.Time) float64 {
+	return c.DayCount.YearFraction(from, to)
+}
+
+// AddYears retourne la date située years années après from selon le décompte des jours
+// des conventions, inverse de YearsBetween
+func (c RateConventions) AddYears(from time.Time, years float64) time.Time {
+	return c.DayCount.AddYearFraction(from, years)
+}
+
+// RateLog retourne le logarithme de la croissance sur un an au taux annuel rate (%)
+func (c RateConventions) RateLog(rate float64) float64 {
+	n := c.Compounding.PeriodsPerYear()
+	if n == 0 {
+		return rate / 100
+	}
+	return n * math.Log1p(rate/100/n)
+}
+
+// RateFromLog est l'inverse de RateLog : le taux annuel (%) correspondant à une
+// croissance logarithmique annuelle
+func (c RateConventions) RateFromLog(logGrowth float64) float64 {
+	n := c.Compounding.PeriodsPerYear()
+	if n == 0 {
+		return logGrowth * 100
+	}
+	return n * math.Expm1(logGrowth/n) * 100
+}
+
+// GrowthFactor retourne la croissance d'une valeur placée years années au taux annuel rate (%)
+func (c RateConventions) GrowthFactor(rate, years float64) float64 {
+	return math.Exp(c.RateLog(rate) * years)
+}
+
+// AnnualizedRate retourne le taux annuel (%) produisant la croissance growth en years années
+func (c RateConventions) AnnualizedRate(growth, years float64) float64 {
+	return c.RateFromLog(math.Log(growth) / years)
+}
@@ -0,0 +1,20 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAddYearsInvertsYearsBetween(t *testing.T) {
+	from := day(2024, time.January, 15)
+	for _, dayCount := range []DayCount{DayCountActual36525, DayCountActual365, DayCountActual360, DayCount30360} {
+		c := RateConventions{DayCount: dayCount}
+		for _, years := range []float64{0.5, 1, 2.75, 10} {
+			to := c.AddYears(from, years)
+			if got := c.YearsBetween(from, to); math.Abs(got-years) > 1e-9 {
+				t.Errorf("%s: %g ans après le %s donne le %s, soit %g ans", dayCount, years, from.Format(time.DateOnly), to.Format(time.DateOnly), got)
+			}
+		}
+	}
+}
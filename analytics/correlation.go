@@ -0,0 +1,29 @@
+package analytics
+
+import (
+	"math"
+)
+
+// Pearson calcule le coefficient de corrélation de deux séries de même longueur ;
+// il n'est pas défini si l'une des séries est constante
+func Pearson(a, b []float64) (float64, bool) {
+	n := float64(len(a))
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= n
+	meanB /= n
+
+	var cov, varA, varB float64
+	for i := range a {
+		cov += (a[i] - meanA) * (b[i] - meanB)
+		varA += (a[i] - meanA) * (a[i] - meanA)
+		varB += (b[i] - meanB) * (b[i] - meanB)
+	}
+	if varA == 0 || varB == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varA*varB), true
+}
@@ -0,0 +1,52 @@
+package analytics
+
+import (
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// AnnualCovariance retourne la matrice de covariance annualisée des rendements
+// logarithmiques d'indices alignés sur les mêmes dates, et le nombre de rendements, les
+// durées suivant les conventions c. Comme pour AnnualVolatility, les écarts sont mesurés
+// à la tendance de chaque série au prorata de la durée de chaque pas, puis rapportés à la
+// durée totale.
+func AnnualCovariance(c RateConventions, aligned []timeseries.Series[float64]) ([][]float64, int) {
+	returns := make([][]float64, len(aligned))
+	for i, s := range aligned {
+		returns[i] = timeseries.Values(timeseries.Diff(s))
+	}
+	dates := aligned[0]
+	years := make([]float64, len(dates)-1)
+	var totalYears float64
+	for k := range years {
+		years[k] = c.YearsBetween(dates[k].Date, dates[k+1].Date)
+		totalYears += years[k]
+	}
+
+	deviations := make([][]float64, len(returns))
+	for i, r := range returns {
+		var totalLog float64
+		for _, v := range r {
+			totalLog += v
+		}
+		drift := totalLog / totalYears
+		deviations[i] = make([]float64, len(r))
+		for k, v := range r {
+			deviations[i][k] = v - drift*years[k]
+		}
+	}
+
+	covariance := make([][]float64, len(returns))
+	for i := range covariance {
+		covariance[i] = make([]float64, len(returns))
+	}
+	for i := range deviations {
+		for j := i; j < len(deviations); j++ {
+			var sum float64
+			for k := range deviations[i] {
+				sum += deviations[i][k] * deviations[j][k]
+			}
+			covariance[i][j], covariance[j][i] = sum/totalYears, sum/totalYears
+		}
+	}
+	return covariance, len(years)
+}
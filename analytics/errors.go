@@ -0,0 +1,9 @@
+package analytics
+
+import "errors"
+
+// Erreurs sentinelles des calculs, reprises par le paquet portfolio
+var (
+	ErrInsufficientHistory = errors.New("historique insuffisant")
+	ErrPeriodTooShort      = errors.New("période trop courte pour annualiser")
+)
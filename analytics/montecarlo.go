@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// Growth applique un rendement logarithmique sur une durée en années à une valeur, par
+// exemple frais déduits
+type Growth func(value, years, logReturn float64) float64
+
+// BootstrapPath tire une trajectoire de start sur horizon années en rejouant au hasard
+// les rendements observés
+func BootstrapPath(rng *rand.Rand, start, horizon float64, returns []PeriodReturn, grow Growth) float64 {
+	value := start
+	for remaining := horizon; remaining > 0; {
+		r := returns[rng.IntN(len(returns))]
+		years := math.Min(r.Years, remaining)
+		value = grow(value, years, r.LogReturn*years/r.Years)
+		remaining -= years
+	}
+	return value
+}
+
+// BlockBootstrapPath tire une trajectoire en rejouant des blocs de block rendements
+// observés consécutifs. Le bootstrap est circulaire : un bloc commencé en fin
+// d'historique se poursuit au début.
+func BlockBootstrapPath(rng *rand.Rand, start, horizon float64, returns []PeriodReturn, block int, grow Growth) float64 {
+	value := start
+	for remaining, i, left := horizon, 0, 0; remaining > 0; i, left = (i+1)%len(returns), left-1 {
+		if left == 0 {
+			i, left = rng.IntN(len(returns)), block
+		}
+		r := returns[i]
+		years := math.Min(r.Years, remaining)
+		value = grow(value, years, r.LogReturn*years/r.Years)
+		remaining -= years
+	}
+	return value
+}
+
+// DefaultBlockLength est la longueur des blocs pour n rendements observés : la racine
+// cubique de n, règle usuelle du bootstrap par blocs
+func DefaultBlockLength(n int) int {
+	return int(math.Ceil(math.Cbrt(float64(n))))
+}
+
+// NormalPath tire une trajectoire log-normale par pas de step années, de dérive et de
+// volatilité annualisées (rendements logarithmiques)
+func NormalPath(rng *rand.Rand, start, horizon, step, drift, volatility float64, grow Growth) float64 {
+	value := start
+	for remaining := horizon; remaining > 0; {
+		years := math.Min(step, remaining)
+		value = grow(value, years, drift*years+volatility*math.Sqrt(years)*rng.NormFloat64())
+		remaining -= years
+	}
+	return value
+}
@@ -0,0 +1,25 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PeriodRate exprime un rendement sur période en pourcentage, annualisé si demandé selon
+// les conventions c
+func (c RateConventions) PeriodRate(r float64, start, end time.Time, annualize bool) (float64, error) {
+	if math.IsNaN(r) || math.IsInf(r, 0) {
+		return 0, fmt.Errorf("aucun capital engagé sur la période, rendement non calculable")
+	}
+	if !annualize {
+		return r * 100, nil
+	}
+	if err := c.CheckAnnualization(start, end); err != nil {
+		return 0, err
+	}
+	if r <= -1 {
+		return 0, fmt.Errorf("perte totale sur la période, taux non calculable")
+	}
+	return c.AnnualizedRate(1+r, c.YearsBetween(start, end)), nil
+}
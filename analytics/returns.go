@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// PeriodReturn est le rendement logarithmique observé entre deux NAV successives
+type PeriodReturn struct {
+	Start, End time.Time
+	Years      float64
+	LogReturn  float64
+}
+
+// PerformanceIndex chaîne les rendements en un indice logarithmique partant de 0
+func PerformanceIndex(returns []PeriodReturn) timeseries.Series[float64] {
+	if len(returns) == 0 {
+		return nil
+	}
+	index := timeseries.Series[float64]{{Date: returns[0].Start}}
+	for _, r := range returns {
+		index = append(index, timeseries.Point[float64]{Date: r.End, Value: index[len(index)-1].Value + r.LogReturn})
+	}
+	return index
+}
+
+// AnnualVolatility estime l'écart-type annualisé des rendements logarithmiques
+func AnnualVolatility(returns []PeriodReturn) float64 {
+	var totalYears, totalLog float64
+	for _, r := range returns {
+		totalYears += r.Years
+		totalLog += r.LogReturn
+	}
+	if totalYears == 0 {
+		return 0
+	}
+	drift := totalLog / totalYears
+
+	var variance float64
+	for _, r := range returns {
+		deviation := r.LogReturn - drift*r.Years
+		variance += deviation * deviation
+	}
+	return math.Sqrt(variance / totalYears)
+}
+
+// Percentile interpole linéairement le percentile q (0-100) d'une série triée
+func Percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := q / 100 * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+)
+
+// RiskMetrics regroupe les mesures de risque annualisées d'une série de rendements
+type RiskMetrics struct {
+	Return            float64 `json:"return"`             // Rendement annualisé (%)
+	Volatility        float64 `json:"volatility"`         // Écart-type annualisé des rendements (%)
+	DownsideDeviation float64 `json:"downside_deviation"` // Écart-type annualisé des rendements inférieurs au taux sans risque (%)
+	Sharpe            float64 `json:"sharpe"`             // (rendement - taux sans risque) / volatilité, 0 si volatilité nulle
+	Sortino           float64 `json:"sortino"`            // (rendement - taux sans risque) / semi-écart-type, 0 s'il est nul
+
+	// Mesures relatives à l'indice de référence de l'investissement, absentes sans indice
+	// ou sans historique commun suffisant (voir BenchmarkComparison)
+	Benchmark        string   `json:"benchmark,omitempty"`
+	TrackingError    *float64 `json:"tracking_error,omitempty"`    // Écart-type annualisé des écarts de rendement (%)
+	InformationRatio *float64 `json:"information_ratio,omitempty"` // Écart de rendement annualisé / tracking error
+}
+
+// Risk calcule les mesures annualisées à partir de rendements logarithmiques, les
+// taux suivant les conventions c
+func Risk(c RateConventions, returns []PeriodReturn, riskFreeRate float64) (RiskMetrics, error) {
+	if len(returns) < 2 {
+		return RiskMetrics{}, fmt.Errorf("au moins 2 rendements sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	var totalYears, totalLog float64
+	for _, r := range returns {
+		totalYears += r.Years
+		totalLog += r.LogReturn
+	}
+	if totalYears == 0 {
+		return RiskMetrics{}, fmt.Errorf("l'intervalle de temps doit être positif")
+	}
+
+	// Semi-variance : seuls les rendements inférieurs au taux sans risque comptent
+	riskFreeLog := c.RateLog(riskFreeRate)
+	var downside float64
+	for _, r := range returns {
+		if shortfall := r.LogReturn - riskFreeLog*r.Years; shortfall < 0 {
+			downside += shortfall * shortfall
+		}
+	}
+
+	metrics := RiskMetrics{
+		Return:            c.RateFromLog(totalLog / totalYears),
+		Volatility:        AnnualVolatility(returns) * 100,
+		DownsideDeviation: math.Sqrt(downside/totalYears) * 100,
+	}
+	excess := metrics.Return - riskFreeRate
+	if metrics.Volatility > 0 {
+		metrics.Sharpe = excess / metrics.Volatility
+	}
+	if metrics.DownsideDeviation > 0 {
+		metrics.Sortino = excess / metrics.DownsideDeviation
+	}
+	return metrics, nil
+}
@@ -0,0 +1,30 @@
+package analytics
+
+import "math"
+
+// RiskContributions décompose la volatilité σ = √(wᵀΣw) d'un portefeuille de poids w
+// et de matrice de covariance Σ : la contribution marginale de i est (Σw)ᵢ/σ et sa
+// contribution wᵢ(Σw)ᵢ/σ, les contributions sommant à σ. Les contributions sont nulles
+// si la volatilité l'est.
+func RiskContributions(covariance [][]float64, weights []float64) (volatility float64, marginal, component []float64) {
+	// Σw, puis σ² = wᵀΣw
+	sigmaW := make([]float64, len(weights))
+	var variance float64
+	for i := range weights {
+		for j := range weights {
+			sigmaW[i] += covariance[i][j] * weights[j]
+		}
+		variance += weights[i] * sigmaW[i]
+	}
+
+	volatility = math.Sqrt(math.Max(variance, 0))
+	marginal = make([]float64, len(weights))
+	component = make([]float64, len(weights))
+	if volatility > 0 {
+		for i := range weights {
+			marginal[i] = sigmaW[i] / volatility
+			component[i] = weights[i] * marginal[i]
+		}
+	}
+	return volatility, marginal, component
+}
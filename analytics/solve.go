@@ -0,0 +1,42 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bornes et précision de la recherche du taux requis (% annuel)
+const (
+	GoalMinRate       = -99.0
+	goalMaxRate       = 1000.0
+	goalRateTolerance = 1e-6
+)
+
+// SolveRate cherche le plus petit taux pour lequel project atteint targetValue
+func SolveRate(targetValue float64, project func(rate float64) (float64, error)) (float64, error) {
+	// La valeur projetée croît avec le taux : recherche par dichotomie
+	low, high := GoalMinRate, goalMaxRate
+	if value, err := project(high); err != nil {
+		return 0, err
+	} else if value < targetValue {
+		return 0, fmt.Errorf("objectif inatteignable avec un taux inférieur à %.0f%%", goalMaxRate)
+	}
+	if value, err := project(low); err != nil {
+		return 0, err
+	} else if value >= targetValue {
+		return low, nil
+	}
+	for high-low > goalRateTolerance {
+		mid := (low + high) / 2
+		value, err := project(mid)
+		if err != nil {
+			return 0, err
+		}
+		if value >= targetValue {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return math.Round(high*1e4) / 1e4, nil
+}
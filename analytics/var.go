@@ -0,0 +1,28 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+// HistoricalVaR retourne la perte, en fraction de la valeur, qui n'est dépassée que
+// dans une proportion 1 - confidence des rendements logarithmiques observés sur
+// l'horizon ; windowReturns est trié sur place
+func HistoricalVaR(windowReturns []float64, confidence float64) float64 {
+	sort.Float64s(windowReturns)
+	return math.Max(0, -math.Expm1(Percentile(windowReturns, (1-confidence)*100)))
+}
+
+// ParametricVaR retourne la perte, en fraction de la valeur, au niveau de confiance
+// donné sur un horizon en années, le rendement logarithmique suivant une loi normale de
+// dérive et de volatilité estimées sur les rendements observés : ln(1+R) ~ N(μh, σ²h)
+func ParametricVaR(returns []PeriodReturn, confidence, years float64) float64 {
+	var totalYears, totalLog float64
+	for _, r := range returns {
+		totalYears += r.Years
+		totalLog += r.LogReturn
+	}
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+	logReturn := totalLog/totalYears*years - z*AnnualVolatility(returns)*math.Sqrt(years)
+	return math.Max(0, -math.Expm1(logReturn))
+}
@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Paramètres de résolution du TRI
+const (
+	xirrTolerance     = 1e-9
+	xirrMaxIterations = 100
+)
+
+// Flow est un flux daté vu de l'investisseur : négatif quand il investit,
+// positif quand il récupère de l'argent (retrait ou valeur finale)
+type Flow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// XIRR résout Σ CFᵢ / (1 + r)^tᵢ = 0 par Newton-Raphson, avec repli sur une
+// dichotomie si Newton diverge ou sort du domaine r > -100 %. Les durées tᵢ suivent le
+// décompte des jours des conventions c.
+func (c RateConventions) XIRR(flows []Flow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, fmt.Errorf("au moins 2 flux sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	hasPositive, hasNegative := false, false
+	for _, f := range flows {
+		hasPositive = hasPositive || f.Amount > 0
+		hasNegative = hasNegative || f.Amount < 0
+	}
+	if !hasPositive || !hasNegative {
+		return 0, fmt.Errorf("les flux doivent comporter au moins un apport et une valeur de sortie")
+	}
+
+	sort.Slice(flows, func(i, j int) bool { return flows[i].Date.Before(flows[j].Date) })
+	origin := flows[0].Date
+	years := make([]float64, len(flows))
+	for i, f := range flows {
+		years[i] = c.YearsBetween(origin, f.Date)
+	}
+
+	npv := func(rate float64) (value, derivative float64) {
+		for i, f := range flows {
+			discount := math.Pow(1+rate, years[i])
+			value += f.Amount / discount
+			derivative -= years[i] * f.Amount / (discount * (1 + rate))
+		}
+		return value, derivative
+	}
+
+	// Newton-Raphson à partir de 10 %
+	rate := 0.1
+	for i := 0; i < xirrMaxIterations; i++ {
+		value, derivative := npv(rate)
+		if math.Abs(value) < xirrTolerance {
+			return rate * 100, nil
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < xirrTolerance {
+			return next * 100, nil
+		}
+		rate = next
+	}
+
+	// Dichotomie : chercher un intervalle où la VAN change de signe
+	low, high := -0.9999, 1.0
+	lowValue, _ := npv(low)
+	highValue, _ := npv(high)
+	for lowValue*highValue > 0 {
+		if high > 1e6 {
+			return 0, fmt.Errorf("le TRI n'a pas pu être déterminé")
+		}
+		high *= 2
+		highValue, _ = npv(high)
+	}
+
+	for i := 0; i < 4*xirrMaxIterations; i++ {
+		mid := (low + high) / 2
+		midValue, _ := npv(mid)
+		if math.Abs(midValue) < xirrTolerance || (high-low)/2 < xirrTolerance {
+			return mid * 100, nil
+		}
+		if midValue*lowValue < 0 {
+			high = mid
+		} else {
+			low, lowValue = mid, midValue
+		}
+	}
+
+	return (low + high) / 2 * 100, nil
+}
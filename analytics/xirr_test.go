@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestXIRR(t *testing.T) {
+	tests := []struct {
+		name        string
+		conventions RateConventions
+		flows       []Flow
+		want        float64
+	}{
+		{
+			name:  "gain sur une année bissextile",
+			flows: []Flow{{day(2020, 1, 1), -1000}, {day(2021, 1, 1), 1100}},
+			want:  (math.Pow(1.1, 365.25/366) - 1) * 100,
+		},
+		{
+			name:  "perte sur un an",
+			flows: []Flow{{day(2021, 1, 1), -1000}, {day(2022, 1, 1), 900}},
+			want:  (math.Pow(0.9, 365.25/365) - 1) * 100,
+		},
+		{
+			name:        "act/365",
+			conventions: RateConventions{DayCount: DayCountActual365},
+			flows:       []Flow{{day(2021, 1, 1), -1000}, {day(2022, 1, 1), 1050}},
+			want:        5,
+		},
+		{
+			name:        "flux dans le désordre",
+			conventions: RateConventions{DayCount: DayCountActual365},
+			flows:       []Flow{{day(2023, 1, 1), 1210}, {day(2021, 1, 1), -1000}},
+			want:        10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.conventions.XIRR(tt.flows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("TRI %.8f%%, %.8f%% attendu", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXIRRCancelsNPV(t *testing.T) {
+	var c RateConventions
+	flows := []Flow{
+		{day(2020, 1, 15), -1000},
+		{day(2020, 7, 1), -500},
+		{day(2021, 3, 10), 200},
+		{day(2022, 2, 1), -300},
+		{day(2023, 6, 30), 1900},
+	}
+	rate, err := c.XIRR(flows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	npv := 0.0
+	for _, f := range flows {
+		npv += f.Amount / math.Pow(1+rate/100, c.YearsBetween(flows[0].Date, f.Date))
+	}
+	if math.Abs(npv) > 1e-6 {
+		t.Errorf("VAN %.10f au taux %.6f%%, 0 attendu", npv, rate)
+	}
+}
+
+func TestXIRRErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []Flow
+		want  error
+	}{
+		{name: "aucun flux", want: ErrInsufficientHistory},
+		{name: "un seul flux", flows: []Flow{{day(2021, 1, 1), -1000}}, want: ErrInsufficientHistory},
+		{name: "que des apports", flows: []Flow{{day(2021, 1, 1), -1000}, {day(2022, 1, 1), -100}}},
+		{name: "que des sorties", flows: []Flow{{day(2021, 1, 1), 1000}, {day(2022, 1, 1), 100}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RateConventions{}.XIRR(tt.flows)
+			if err == nil {
+				t.Fatal("erreur attendue")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("erreur %v, %v attendu", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Package backtest rejoue une allocation à poids fixes sur des indices de performance
+// historiques : les lignes évoluent de date en date selon leur indice, reçoivent les
+// versements selon les poids cibles et sont rétablies à ces poids aux échéances de
+// rééquilibrage. Il ne connaît ni le portefeuille ni ses devises ; les mesures de
+// rendement, de volatilité et de baisse sont calculées par l'appelant à partir des
+// périodes retournées.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// Strategy est une stratégie d'allocation : des poids fixes, rétablis à chaque date de
+// rééquilibrage, et des versements répartis selon ces poids
+type Strategy struct {
+	Weights       map[string]float64 // Poids cibles par ligne (%, total 100)
+	Initial       float64            // Capital investi à la première date
+	Contribution  float64            // Montant de chaque versement
+	Contributions []time.Time        // Dates des versements
+	Rebalances    []time.Time        // Dates de rééquilibrage, aucune pour l'achat-conservation
+}
+
+// Period est le rendement logarithmique des lignes entre deux dates de simulation,
+// hors versements
+type Period struct {
+	Start, End time.Time
+	LogReturn  float64
+}
+
+// Result est le déroulé d'une simulation
+type Result struct {
+	Invested    float64                               // Capital initial et versements
+	Final       float64                               // Valeur finale
+	Values      timeseries.Series[map[string]float64] // Valeur de chaque ligne à chaque date
+	Periods     []Period                              // Rendement de chaque pas, tant que la valeur est positive
+	Rebalancing int                                   // Nombre de rééquilibrages effectués
+}
+
+// Run fait évoluer les lignes de dates[0] à la dernière date. indexes donne l'indice de
+// performance logarithmique de chaque ligne pondérée, lu aux dates par at (par exemple
+// timeseries.Interpolate), qui échoue sur un indice vide ; les versements et
+// rééquilibrages tombant entre deux dates sont appliqués à la date suivante. La
+// simulation s'arrête si ctx est annulé.
+func Run(ctx context.Context, s Strategy, indexes map[string]timeseries.Series[float64], dates []time.Time, at func(timeseries.Series[float64], time.Time) (float64, bool)) (*Result, error) {
+	if len(dates) < 2 {
+		return nil, fmt.Errorf("au moins 2 dates de simulation sont nécessaires")
+	}
+	names := make([]string, 0, len(s.Weights))
+	for name := range s.Weights {
+		if _, ok := indexes[name]; !ok {
+			return nil, fmt.Errorf("aucun indice de performance pour %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	holdings := make(map[string]float64)
+	allocate := func(amount float64) {
+		for _, name := range names {
+			holdings[name] += amount * s.Weights[name] / 100
+		}
+	}
+	valueOf := func() float64 {
+		v := 0.0
+		for _, name := range names {
+			v += holdings[name]
+		}
+		return v
+	}
+	point := func(date time.Time) timeseries.Point[map[string]float64] {
+		values := make(map[string]float64, len(names))
+		for _, name := range names {
+			values[name] = holdings[name]
+		}
+		return timeseries.Point[map[string]float64]{Date: date, Value: values}
+	}
+	within := func(schedule []time.Time, start, end time.Time) int {
+		n := 0
+		for _, date := range schedule {
+			if date.After(start) && !date.After(end) {
+				n++
+			}
+		}
+		return n
+	}
+
+	allocate(s.Initial)
+	result := &Result{Invested: s.Initial, Values: timeseries.Series[map[string]float64]{point(dates[0])}}
+	for i := 1; i < len(dates); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("backtest interrompu au %s: %w", dates[i-1].Format(time.DateOnly), err)
+		}
+		start, end := dates[i-1], dates[i]
+		before := valueOf()
+		for _, name := range names {
+			from, okFrom := at(indexes[name], start)
+			to, okTo := at(indexes[name], end)
+			if !okFrom || !okTo {
+				return nil, fmt.Errorf("indice de performance de %s indisponible au %s", name, end.Format(time.DateOnly))
+			}
+			holdings[name] *= math.Exp(to - from)
+		}
+		if after := valueOf(); before > 0 {
+			result.Periods = append(result.Periods, Period{Start: start, End: end, LogReturn: math.Log(after / before)})
+		}
+
+		if s.Contribution > 0 {
+			for n := within(s.Contributions, start, end); n > 0; n-- {
+				allocate(s.Contribution)
+				result.Invested += s.Contribution
+			}
+		}
+		if within(s.Rebalances, start, end) > 0 {
+			value := valueOf()
+			for _, name := range names {
+				holdings[name] = value * s.Weights[name] / 100
+			}
+			result.Rebalancing++
+		}
+		result.Values = append(result.Values, point(end))
+	}
+	result.Final = valueOf()
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// smtpFlags déclare les options d'envoi de courriels, alertes et rapports. La fonction
+// retournée donne le notificateur configuré, nil sans serveur ou sans destinataire.
+func smtpFlags(fs *flag.FlagSet) func() *portfolio.EmailNotifier {
+	smtpConfig := portfolio.ActiveConfig().SMTP
+	smtpAddr := fs.String("smtp", smtpConfig.Server, "serveur SMTP des courriels (hôte:port, mot de passe dans DAVID_SMTP_PASSWORD)")
+	from := fs.String("mail-from", smtpConfig.From, "expéditeur des courriels")
+	to := fs.String("mail-to", smtpConfig.To, "destinataires des courriels, séparés par des virgules")
+
+	return func() *portfolio.EmailNotifier {
+		if *smtpAddr == "" || *to == "" {
+			return nil
+		}
+		var auth smtp.Auth
+		if password := portfolio.EnvOrConfig("DAVID_SMTP_PASSWORD", smtpConfig.Password); password != "" {
+			host, _, _ := strings.Cut(*smtpAddr, ":")
+			auth = smtp.PlainAuth("", *from, password, host)
+		}
+		return &portfolio.EmailNotifier{Addr: *smtpAddr, Auth: auth, From: *from, To: strings.Split(*to, ",")}
+	}
+}
+
+// alertNotifierFlags déclare les options de notification communes à alerts et watch ;
+// mail est le serveur de courriel déclaré par smtpFlags
+func alertNotifierFlags(fs *flag.FlagSet, mail func() *portfolio.EmailNotifier) func() []portfolio.Notifier {
+	webhook := fs.String("webhook", "", "adresse du webhook recevant les alertes en JSON")
+
+	return func() []portfolio.Notifier {
+		notifiers := []portfolio.Notifier{portfolio.WriterNotifier{W: os.Stdout}}
+		if *webhook != "" {
+			notifiers = append(notifiers, portfolio.WebhookNotifier{URL: *webhook})
+		}
+		if n := mail(); n != nil {
+			notifiers = append(notifiers, *n)
+		}
+		if w := configWebhook(); w.Wants(portfolio.EventAlert) {
+			notifiers = append(notifiers, w)
+		}
+		return notifiers
+	}
+}
+
+func runAddAlert(args []string) error {
+	fs, file := newFlagSet("add-alert")
+	kind := fs.String("kind", "", "type d'alerte (value-below, drawdown, drift)")
+	threshold := fs.Float64("threshold", 0, "seuil : valeur, baisse (%) ou écart (points de %)")
+	name := fs.String("name", "", "investissement surveillé (tous si vide ; drawdown et drift)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	rule := portfolio.AlertRule{Kind: portfolio.AlertKind(*kind), Investment: *name, Threshold: *threshold}
+	if err := p.AddAlertRule(rule); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Alerte ajoutée: %s\n", rule)
+	return nil
+}
+
+func runRemoveAlert(args []string) error {
+	fs, file := newFlagSet("remove-alert")
+	index := fs.Int("index", -1, "index de la règle (voir 'david alerts')")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.RemoveAlertRule(*index); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Règle %d supprimée\n", *index)
+	return nil
+}
+
+func runAlerts(args []string) error {
+	fs, file := newFlagSet("alerts")
+	notifiers := alertNotifierFlags(fs, smtpFlags(fs))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	for i, rule := range p.AlertRules {
+		fmt.Printf("[%d] %s\n", i, rule)
+	}
+	alerts, err := p.EvaluateAlerts()
+	if err != nil {
+		return err
+	}
+	if len(alerts) == 0 {
+		fmt.Println("Aucune alerte déclenchée")
+		return nil
+	}
+	return portfolio.NotifyAll(context.Background(), notifiers(), alerts)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAnnualReturns(args []string) error {
+	fs, file := newFlagSet("annual")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	table, err := p.AnnualReturns()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== PERFORMANCES ANNUELLES (au %s) ===\n\n", portfolio.FormatDate(table.AsOf))
+	fmt.Printf("%-20s", "")
+	for _, year := range table.Years {
+		fmt.Printf("%10s", table.YearLabel(year))
+	}
+	fmt.Printf("%10s\n", "YTD")
+	for _, row := range table.Rows {
+		fmt.Printf("%-20s", row.Name)
+		for _, year := range table.Years {
+			fmt.Printf("%10s", portfolio.FormatPercentCell(row.Annual[year], hasKey(row.Annual, year)))
+		}
+		var ytd float64
+		if row.YTD != nil {
+			ytd = *row.YTD
+		}
+		fmt.Printf("%10s\n", portfolio.FormatPercentCell(ytd, row.YTD != nil))
+	}
+	return nil
+}
+
+func hasKey(m map[int]float64, key int) bool {
+	_, ok := m[key]
+	return ok
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runValueAsOf(args []string) error {
+	fs, file := newFlagSet("value-as-of")
+	date := fs.String("date", "", "date de valorisation (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	values, total, err := p.ValueAsOf(*date)
+	if err != nil {
+		return err
+	}
+	t, _ := portfolio.ParseDate(*date)
+	view, err := p.KnownAt(t)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== VALEUR AU %s (données connues à cette date) ===\n\n", portfolio.FormatDate(t))
+	for _, name := range view.InvestmentNames() {
+		value, valued := values[name]
+		if !valued {
+			continue
+		}
+		fmt.Printf("%-20.20s %12.2f %s", name, value, view.ConsolidationCurrency())
+		if navs := view.Investments[name].NAVHistory; len(navs) > 0 {
+			fmt.Printf("  (dernière NAV connue le %s)", portfolio.FormatDate(navs[len(navs)-1].Date.Time))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("\nTotal: %.2f %s\n", total, view.ConsolidationCurrency())
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+func runAttribution(args []string) error {
+	fs, file := newFlagSet("attribution")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return fmt.Errorf("--from et --to sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	a, err := p.PerformanceAttribution(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== ATTRIBUTION DE PERFORMANCE DU %s AU %s ===\n\n", *from, *to)
+	for _, line := range a.Investments {
+		fmt.Printf("%s: poids %.2f%% × rendement %.2f%% = %+.2f pts\n", line.Name, line.Weight, line.Return, line.Contribution)
+	}
+	fmt.Println()
+	classes := make([]string, 0, len(a.ByAssetClass))
+	for class := range a.ByAssetClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		fmt.Printf("%s: %+.2f pts\n", class, a.ByAssetClass[class])
+	}
+	fmt.Printf("\nRendement du portefeuille: %.2f%%\n", a.TotalReturn)
+	return nil
+}
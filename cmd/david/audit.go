@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runHistory(args []string) error {
+	fs, file := newFlagSet("history")
+	investment := fs.String("investment", "", "investissement (nom actuel ou ancien)")
+	who := fs.String("user", "", "auteur des modifications")
+	field := fs.String("field", "", "champ modifié (préfixe, ex. nav_history)")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start, end, err := portfolio.ParsePeriod(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	entries, err := portfolio.ReadAuditLog(*file, portfolio.AuditQuery{Investment: *investment, User: *who, Field: *field, From: start, To: end})
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		target := e.Investment
+		if target == "" {
+			target = "(portefeuille)"
+		}
+		if e.Renamed != "" {
+			target = fmt.Sprintf("%s (ex-%s)", target, e.Renamed)
+		}
+		fmt.Printf("%s  %-12s %-18s %-7s %s\n", e.Time.Local().Format(time.DateTime), e.User, e.Command, e.Action, target)
+		for _, c := range e.Changes {
+			switch {
+			case c.Old == "" && c.New == "":
+				fmt.Printf("    %s\n", c.Field)
+			case c.Old == "":
+				fmt.Printf("    %s: %s\n", c.Field, c.New)
+			case c.New == "":
+				fmt.Printf("    %s: %s supprimé\n", c.Field, c.Old)
+			default:
+				fmt.Printf("    %s: %s -> %s\n", c.Field, c.Old, c.New)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Println("Aucune modification enregistrée")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// tokensSuffix est l'extension du fichier des jetons d'API, à côté du portefeuille
+const tokensSuffix = ".tokens"
+
+// tokensFile retourne le fichier des jetons : la clé serve.tokens de la configuration,
+// sinon le fichier du portefeuille suivi de .tokens
+func tokensFile(file string) string {
+	if path := portfolio.ActiveConfig().Serve.Tokens; path != "" {
+		return path
+	}
+	return file + tokensSuffix
+}
+
+func runTokenAdd(args []string) error {
+	fs, file := newFlagSet("token-add")
+	name := fs.String("name", "", "nom du jeton (ex. tableau-de-bord)")
+	scope := fs.String("scope", string(portfolio.ScopeRead), "portée du jeton (read, write)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sc, err := portfolio.ParseTokenScope(*scope)
+	if err != nil {
+		return err
+	}
+
+	store, err := portfolio.LoadTokens(tokensFile(*file))
+	if err != nil {
+		return err
+	}
+	secret, err := store.Add(*name, sc)
+	if err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Jeton '%s' (%s) créé : %s\n", *name, sc, secret)
+	fmt.Println("Ce secret ne sera plus affiché ; à transmettre dans l'en-tête \"Authorization: Bearer <jeton>\"")
+	return nil
+}
+
+func runTokenRemove(args []string) error {
+	fs, file := newFlagSet("token-remove")
+	name := fs.String("name", "", "nom du jeton à révoquer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := portfolio.LoadTokens(tokensFile(*file))
+	if err != nil {
+		return err
+	}
+	if err := store.Remove(*name); err != nil {
+		return err
+	}
+	return store.Save()
+}
+
+func runTokens(args []string) error {
+	fs, file := newFlagSet("tokens")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := portfolio.LoadTokens(tokensFile(*file))
+	if err != nil {
+		return err
+	}
+	if !store.Enabled() {
+		fmt.Println("Aucun jeton : l'API de serve est ouverte à tous")
+		return nil
+	}
+	for _, t := range store.Tokens {
+		fmt.Printf("%-24s %-6s créé le %s\n", t.Name, t.Scope, portfolio.FormatDate(t.Created))
+	}
+	return nil
+}
+
+// tokenKey est la clé de contexte du jeton qui a authentifié une requête
+type tokenKey struct{}
+
+// requestActor retourne l'auteur des modifications d'une requête : le nom de son jeton,
+// ou l'utilisateur qui a lancé serve si l'API est ouverte
+func requestActor(ctx context.Context) portfolio.AuditActor {
+	if t, ok := ctx.Value(tokenKey{}).(*portfolio.APIToken); ok {
+		return portfolio.AuditActor{User: t.Name, Command: "serve"}
+	}
+	return portfolio.CommandActor("serve")
+}
+
+// requireToken protège un handler HTTP par les jetons de tokens : les GET demandent la
+// portée read, les autres méthodes la portée write. Le jeton est transmis au handler
+// dans le contexte de la requête (voir requestActor).
+func requireToken(tokens *portfolio.TokenStore, next http.Handler) http.Handler {
+	if !tokens.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := portfolio.ScopeWrite
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			scope = portfolio.ScopeRead
+		}
+		token, err := tokens.Authorize(r.Header.Get("Authorization"), scope)
+		if err != nil {
+			status := http.StatusForbidden
+			if errors.Is(err, portfolio.ErrUnauthenticated) {
+				status = http.StatusUnauthorized
+				w.Header().Set("WWW-Authenticate", `Bearer realm="david"`)
+			}
+			writeError(w, status, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, token)))
+	})
+}
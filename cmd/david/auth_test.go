@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// tokenServer crée un serveur dont le portefeuille est enregistré dans un répertoire
+// temporaire et protégé par un jeton read et un jeton write
+func tokenServer(t *testing.T) (s *server, read, write string) {
+	t.Helper()
+	dir := t.TempDir()
+	p := portfolio.NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	s = newServer(p, filepath.Join(dir, "portfolio.json"))
+	tokens, err := portfolio.LoadTokens(filepath.Join(dir, "portfolio.json.tokens"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read, err = tokens.Add("tableau-de-bord", portfolio.ScopeRead); err != nil {
+		t.Fatal(err)
+	}
+	if write, err = tokens.Add("saisie", portfolio.ScopeWrite); err != nil {
+		t.Fatal(err)
+	}
+	s.tokens = tokens
+	return s, read, write
+}
+
+func TestRequireToken(t *testing.T) {
+	s, read, write := tokenServer(t)
+	handler := s.routes()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		status int
+	}{
+		{name: "lecture sans jeton", method: http.MethodGet, path: "/portfolio", status: http.StatusUnauthorized},
+		{name: "jeton inconnu", method: http.MethodGet, path: "/portfolio", token: "inconnu", status: http.StatusUnauthorized},
+		{name: "lecture", method: http.MethodGet, path: "/portfolio", token: read, status: http.StatusOK},
+		{name: "écriture avec un jeton read", method: http.MethodPost, path: "/investments/A/navs", body: `{"date":"2024-02-01","value":1010}`, token: read, status: http.StatusForbidden},
+		{name: "écriture", method: http.MethodPost, path: "/investments/A/navs", body: `{"date":"2024-02-01","value":1010}`, token: write, status: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("%s %s: statut %d, %d attendu: %s", tt.method, tt.path, rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func TestServerAuditsTokenName(t *testing.T) {
+	s, _, write := tokenServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/investments/A/navs", strings.NewReader(`{"date":"2024-02-01","value":1010}`))
+	req.Header.Set("Authorization", "Bearer "+write)
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /investments/A/navs: statut %d: %s", rec.Code, rec.Body)
+	}
+
+	entries, err := portfolio.ReadAuditLog(s.file, portfolio.AuditQuery{Field: "nav_history[2024-02-01]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("%d entrées d'audit pour la NAV, 1 attendue", len(entries))
+	}
+	if e := entries[0]; e.User != "saisie" || e.Command != "serve" {
+		t.Errorf("NAV enregistrée par %s (%s), saisie (serve) attendu", e.User, e.Command)
+	}
+}
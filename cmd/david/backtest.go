@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runBacktest(args []string) error {
+	fs, file := newFlagSet("backtest")
+	weights := fs.String("weights", "", "poids de la stratégie en % (ex: \"A=60,B=40\")")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ)")
+	to := fs.String("to", portfolio.FormatDate(time.Now()), "fin de la période (AAAA-MM-JJ)")
+	step := fs.String("step", string(portfolio.StepMonthly), "pas de simulation (weekly, monthly, quarterly)")
+	rebalance := fs.String("rebalance", string(portfolio.StepYearly), "périodicité du rééquilibrage (monthly, quarterly, yearly, vide pour jamais)")
+	initial := fs.Float64("initial", 10000, "capital initial")
+	contribution := fs.Float64("contribution", 0, "montant de chaque versement")
+	contributionStep := fs.String("contribution-step", string(portfolio.StepMonthly), "périodicité des versements")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weights == "" || *from == "" {
+		return fmt.Errorf("--weights et --from sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	w, err := parseWeights(*weights)
+	if err != nil {
+		return err
+	}
+	start, end, err := portfolio.ParsePeriod(*from, *to)
+	if err != nil {
+		return err
+	}
+	s := portfolio.BacktestStrategy{Weights: w, Rebalance: portfolio.SeriesStep(*rebalance), Initial: portfolio.NewMoney(*initial),
+		Contribution: portfolio.NewMoney(*contribution), ContributionStep: portfolio.SeriesStep(*contributionStep)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	r, err := p.BacktestContext(ctx, s, start, end, portfolio.SeriesStep(*step))
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("=== BACKTEST DU %s AU %s ===\n", portfolio.FormatDate(r.From), portfolio.FormatDate(r.To))
+	fmt.Printf("%-22s %14s %14s\n", "", "Stratégie", "Achat-conserv.")
+	row := func(label string, a, b string) { fmt.Printf("%-22s %14s %14s\n", label, a, b) }
+	row("Capital investi", amount(r.Strategy.Invested.Float64()), amount(r.BuyAndHold.Invested.Float64()))
+	row("Valeur finale", amount(r.Strategy.Final.Float64()), amount(r.BuyAndHold.Final.Float64()))
+	row("Rendement annualisé", fmt.Sprintf("%.2f%%", r.Strategy.Return), fmt.Sprintf("%.2f%%", r.BuyAndHold.Return))
+	row("Volatilité", fmt.Sprintf("%.2f%%", r.Strategy.Volatility), fmt.Sprintf("%.2f%%", r.BuyAndHold.Volatility))
+	row("Baisse maximale", fmt.Sprintf("%.2f%%", r.Strategy.MaxDrawdown.Depth), fmt.Sprintf("%.2f%%", r.BuyAndHold.MaxDrawdown.Depth))
+	fmt.Printf("Rééquilibrages: %d\n", r.Rebalancing)
+	return nil
+}
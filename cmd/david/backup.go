@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// defaultBackupDir est le répertoire de sauvegarde par défaut : "backups" à côté du fichier
+func defaultBackupDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "backups")
+}
+
+func runBackup(args []string) error {
+	fs, file := newFlagSet("backup")
+	dir := fs.String("dir", "", "répertoire des sauvegardes (\"backups\" à côté du fichier si vide)")
+	keep := fs.Int("keep", 30, "nombre de sauvegardes conservées (toutes si 0)")
+	list := fs.Bool("list", false, "liste les sauvegardes existantes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = defaultBackupDir(*file)
+	}
+
+	if *list {
+		backups, err := portfolio.ListBackups(*file, *dir)
+		if err != nil {
+			return err
+		}
+		for _, b := range backups {
+			fmt.Printf("%s  %s  %d octets\n", b.Created.Local().Format(time.DateTime), b.Path, b.Size)
+		}
+		return nil
+	}
+
+	archive, err := portfolio.BackupFile(*file, *dir, *keep)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Sauvegarde: %s\n", archive)
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs, file := newFlagSet("restore")
+	dir := fs.String("dir", "", "répertoire des sauvegardes (\"backups\" à côté du fichier si vide)")
+	archive := fs.String("archive", "", "archive à restaurer (la plus récente si vide)")
+	verify := fs.Bool("verify", false, "vérifie l'archive sans rien restaurer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = defaultBackupDir(*file)
+	}
+	if *archive == "" {
+		backups, err := portfolio.ListBackups(*file, *dir)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return fmt.Errorf("aucune sauvegarde de %s dans %s", *file, *dir)
+		}
+		*archive = backups[len(backups)-1].Path
+	}
+
+	if *verify {
+		name, data, err := portfolio.VerifyBackup(*archive)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s intact (%d octets)\n", *archive, name, len(data))
+		return nil
+	}
+	if err := portfolio.RestoreBackup(*archive, *file, *dir); err != nil {
+		return err
+	}
+	fmt.Printf("%s restauré depuis %s\n", *file, *archive)
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddBenchmarkValue(args []string) error {
+	fs, file := newFlagSet("add-benchmark-value")
+	name := fs.String("benchmark", "", "nom de l'indice (créé s'il n'existe pas)")
+	date := fs.String("date", "", "date de la valeur (AAAA-MM-JJ)")
+	value := fs.Float64("value", 0, "valeur de l'indice")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--benchmark et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddBenchmarkValue(*name, *date, *value); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runSetBenchmark(args []string) error {
+	fs, file := newFlagSet("set-benchmark")
+	name := fs.String("name", "", "nom de l'investissement")
+	benchmark := fs.String("benchmark", "", "nom de l'indice (vide pour dissocier)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetInvestmentBenchmark(*name, *benchmark); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+// parseBenchmarkWindow lit une fenêtre de comparaison se terminant à end : nombre de
+// mois ("6m") ou d'années ("3y"), ou "all" pour tout l'historique (début nul)
+func parseBenchmarkWindow(raw string, end time.Time) (time.Time, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "all" {
+		return time.Time{}, nil
+	}
+	n, err := strconv.Atoi(raw[:max(len(raw)-1, 0)])
+	if err != nil || n <= 0 {
+		return time.Time{}, portfolio.InvalidField("window", raw, "fenêtre invalide: '%s' (ex. 6m, 3y, all)", raw)
+	}
+	switch raw[len(raw)-1] {
+	case 'm':
+		return end.AddDate(0, -n, 0), nil
+	case 'y':
+		return end.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, portfolio.InvalidField("window", raw, "fenêtre invalide: '%s' (ex. 6m, 3y, all)", raw)
+}
+
+func runBenchmarkReport(args []string) error {
+	fs, file := newFlagSet("benchmark")
+	portfolioBenchmark := fs.String("benchmark", "", "indice auquel comparer l'ensemble du portefeuille")
+	from := fs.String("from", "", "début de la comparaison (AAAA-MM-JJ)")
+	to := fs.String("to", "", "fin de la comparaison (AAAA-MM-JJ, aujourd'hui pour --window)")
+	var windows stringList
+	fs.Var(&windows, "window", "fenêtre se terminant à --to : 6m, 1y, 3y, all (répétable, remplace --from)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start, end, err := portfolio.ParsePeriod(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	type window struct {
+		label      string
+		start, end time.Time
+	}
+	periods := []window{{start: start, end: end}}
+	if len(windows) > 0 {
+		if *from != "" {
+			return fmt.Errorf("--from et --window sont incompatibles")
+		}
+		last := end
+		if last.IsZero() {
+			last = portfolio.Today()
+		}
+		periods = nil
+		for _, raw := range windows {
+			start, err := parseBenchmarkWindow(raw, last)
+			if err != nil {
+				return err
+			}
+			periods = append(periods, window{label: raw, start: start, end: end})
+		}
+	}
+
+	fmt.Println("=== COMPARAISON AUX INDICES ===")
+	for _, w := range periods {
+		fmt.Println()
+		if w.label != "" {
+			fmt.Printf("--- Fenêtre %s ---\n", w.label)
+		}
+		report, err := p.BenchmarkReportBetween(w.start, w.end)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(report))
+		for name := range report {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			printBenchmarkComparison(name, report[name])
+		}
+		if *portfolioBenchmark != "" {
+			comparison, err := p.CompareToBenchmarkBetween(*portfolioBenchmark, w.start, w.end)
+			if err != nil {
+				return err
+			}
+			printBenchmarkComparison("Portefeuille", comparison)
+		}
+	}
+	return nil
+}
+
+func printBenchmarkComparison(name string, c portfolio.BenchmarkComparison) {
+	fmt.Printf("%s vs %s: %.2f%% contre %.2f%% (écart %+.2f pts), tracking error %.2f%%, ratio d'information %.2f, bêta %.2f, alpha %+.2f%%, R² %.2f\n",
+		name, c.Benchmark, c.Return, c.BenchmarkReturn, c.ExcessReturn, c.TrackingError, c.InfoRatio, c.Beta, c.Alpha, c.RSquared)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddBond(args []string) error {
+	fs, file := newFlagSet("add-bond")
+	name := fs.String("name", "", "nom de l'obligation")
+	face := fs.Float64("face", 1000, "valeur nominale d'un titre")
+	quantity := fs.Float64("quantity", 1, "nombre de titres")
+	coupon := fs.Float64("coupon", 0, "taux du coupon annuel (% du nominal)")
+	frequency := fs.Int("frequency", 1, "coupons par an (1, 2, 4, 12)")
+	maturity := fs.String("maturity", "", "date d'échéance (AAAA-MM-JJ)")
+	price := fs.Float64("price", 100, "prix d'achat pied de coupon (% du nominal)")
+	date := fs.String("date", "", "date d'achat (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" || *maturity == "" {
+		return fmt.Errorf("--name, --date et --maturity sont obligatoires")
+	}
+	t, err := portfolio.ParseDate(*maturity)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	b := portfolio.Bond{FaceValue: portfolio.NewMoney(*face), Quantity: portfolio.NewQuantity(*quantity), CouponRate: *coupon, Frequency: *frequency, Maturity: portfolio.Date{Time: t}}
+	if err := p.AddBond(*name, b, *price, *date); err != nil {
+		return err
+	}
+	inv, _ := p.Investment(*name)
+	fmt.Printf("%s: prix coupon couru %s, taux actuariel %.3f%%\n", *name, inv.AmountInvested, inv.Bond.Yield)
+	return p.SaveJSON(*file)
+}
+
+func runBond(args []string) error {
+	fs, file := newFlagSet("bond")
+	name := fs.String("name", "", "nom de l'obligation")
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date d'évaluation (AAAA-MM-JJ)")
+	price := fs.Float64("price", 0, "prix de marché pied de coupon (% du nominal) pour le taux actuariel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+	if inv.Bond == nil {
+		return fmt.Errorf("'%s' n'est pas une obligation", *name)
+	}
+	t, err := portfolio.ParseDate(*date)
+	if err != nil {
+		return err
+	}
+	b := inv.Bond
+
+	fmt.Printf("Nominal %s × %s, coupon %.3f%% (%d/an), échéance %s\n", b.FaceValue, b.Quantity, b.CouponRate, b.Frequency, portfolio.FormatDate(b.Maturity.Time))
+	fmt.Printf("Taux actuariel à l'achat: %.3f%%\n", b.Yield)
+	fmt.Printf("Coupon couru au %s: %s\n", *date, b.AccruedInterest(t))
+	if *price > 0 {
+		ytm, err := b.YieldToMaturity(b.DirtyPrice(*price, t), t)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Taux actuariel au prix de %.3f%%: %.3f%%\n", *price, ytm)
+	}
+	fmt.Println("Flux à venir:")
+	for _, cf := range b.CashFlows(t) {
+		fmt.Printf("  %s  %s\n", portfolio.FormatDate(cf.Date.Time), cf.Amount)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetCalendar(args []string) error {
+	fs, file := newFlagSet("set-calendar")
+	holidays := fs.String("holidays", string(portfolio.HolidaysTarget), "jours fériés (target, euronext, france, vide pour les week-ends seuls)")
+	rolling := fs.String("rolling", string(portfolio.RollFollowing), "report des dates non ouvrées (following, modified-following, preceding, modified-preceding, unadjusted)")
+	var extra stringList
+	fs.Var(&extra, "extra", "jour chômé supplémentaire (AAAA-MM-JJ, répétable)")
+	remove := fs.Bool("delete", false, "supprime le calendrier")
+	check := fs.String("check", "", "affiche le report d'une date (AAAA-MM-JJ) sans modifier le portefeuille")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *check != "" {
+		t, err := portfolio.ParseDate(*check)
+		if err != nil {
+			return err
+		}
+		rolled := p.Calendar.Roll(t)
+		if rolled.Equal(t) {
+			fmt.Printf("%s est un jour ouvré\n", *check)
+		} else {
+			fmt.Printf("%s est chômé, reporté au %s\n", *check, portfolio.FormatDate(rolled))
+		}
+		return nil
+	}
+
+	var c *portfolio.Calendar
+	if !*remove {
+		c = &portfolio.Calendar{Holidays: portfolio.HolidaySet(*holidays), Rolling: portfolio.RollConvention(*rolling)}
+		for _, s := range extra {
+			t, err := portfolio.ParseDate(s)
+			if err != nil {
+				return err
+			}
+			c.Extra = append(c.Extra, portfolio.Date{Time: t})
+		}
+	}
+	if err := p.SetCalendar(c); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	if c == nil {
+		fmt.Println("Calendrier supprimé")
+		return nil
+	}
+	var days []string
+	for _, t := range c.Extra {
+		days = append(days, portfolio.FormatDate(t.Time))
+	}
+	fmt.Printf("Calendrier: jours fériés %s, report %s", *holidays, *rolling)
+	if len(days) > 0 {
+		fmt.Printf(", jours chômés %s", strings.Join(days, ", "))
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddCashAccount(args []string) error {
+	fs, file := newFlagSet("add-cash-account")
+	name := fs.String("name", "", "nom du compte")
+	amount := fs.Float64("amount", 0, "solde initial")
+	rate := fs.Float64("rate", 0, "taux nominal annuel (%)")
+	compounding := fs.String("compounding", string(analytics.CompoundYearly), "capitalisation des intérêts (daily, monthly, yearly)")
+	date := fs.String("date", "", "date d'ouverture (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddCashAccount(*name, *amount, *rate, analytics.Compounding(*compounding), *date); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runSetCashRate(args []string) error {
+	fs, file := newFlagSet("set-cash-rate")
+	name := fs.String("name", "", "nom du compte")
+	from := fs.String("from", "", "date d'effet du taux (AAAA-MM-JJ)")
+	rate := fs.Float64("rate", 0, "nouveau taux nominal annuel (%)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *from == "" {
+		return fmt.Errorf("--name et --from sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetCashRate(*name, *from, *rate); err != nil {
+		return err
+	}
+	p.AccrueInterest(portfolio.Today())
+	return p.SaveJSON(*file)
+}
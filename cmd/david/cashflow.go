@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddCashFlow(args []string) error {
+	fs, file := newFlagSet("add-cash-flow")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date du flux (AAAA-MM-JJ)")
+	amount := fs.Float64("amount", 0, "montant du flux")
+	flowType := fs.String("type", string(portfolio.Contribution), "type de flux (contribution ou withdrawal)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddCashFlow(*name, *date, *amount, portfolio.CashFlowType(*flowType)); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// defaultPortfolioFile est le fichier utilisé quand ni --file, ni DAVID_PORTFOLIO, ni la
+// configuration ne le précisent
+const defaultPortfolioFile = "portfolio.json"
+
+// command décrit une sous-commande de la ligne de commande
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands retourne la table des sous-commandes disponibles
+func commands() []command {
+	return []command{
+		{"add-investment", "ajoute un investissement (par montant ou par quantité et prix unitaire)", runAddInvestment},
+		{"add-nav", "ajoute une valorisation à un investissement", runAddNAV},
+		{"update-nav", "corrige la valeur d'une NAV existante", runUpdateNAV},
+		{"delete-nav", "supprime une NAV", runDeleteNAV},
+		{"dedup-navs", "fusionne les NAV de même date selon la politique de doublon", runDedupNAVs},
+		{"set-duplicate-policy", "choisit le traitement des NAV de même date (error, keep-first, keep-last, average)", runSetDuplicatePolicy},
+		{"set-missing-nav-policy", "choisit la valorisation des investissements sans NAV (error, invested, skip)", runSetMissingNAVPolicy},
+		{"ingest", "insère en flux des NAV au format CSV ou NDJSON", runIngest},
+		{"import-statement", "importe un relevé OFX, QIF, un export de courtier (Degiro, Boursorama, Interactive Brokers) ou de logiciel de suivi (Portfolio Performance, Ghostfolio)", runImportStatement},
+		{"add-statement-rule", "rattache les opérations d'un compte ou d'un titre des relevés à un investissement", runAddStatementRule},
+		{"compact-navs", "réduit un historique de NAV aux fins de période et à leurs extrêmes", runCompactNAVs},
+		{"undo", "annule la dernière modification", runUndo},
+		{"redo", "rétablit la dernière modification annulée", runRedo},
+		{"journal", "affiche l'historique des modifications", runJournal},
+		{"history", "affiche le journal d'audit des modifications (qui, quand, quoi)", runHistory},
+		{"set-format", "choisit le format du fichier du portefeuille (json ou binaire)", runSetFormat},
+		{"encrypt", "chiffre le fichier du portefeuille (phrase secrète dans DAVID_PASSPHRASE ou saisie)", runEncrypt},
+		{"decrypt", "enregistre le fichier du portefeuille en clair", runDecrypt},
+		{"backup", "archive le fichier du portefeuille (tar.gz horodaté avec sommes de contrôle)", runBackup},
+		{"restore", "restaure le fichier du portefeuille depuis une sauvegarde vérifiée", runRestore},
+		{"add-account", "ajoute un compte (fichier de portefeuille) au groupe consolidé", runAddAccount},
+		{"remove-account", "retire un compte du groupe consolidé", runRemoveAccount},
+		{"consolidated", "valorise et répartit l'ensemble des comptes du groupe", runConsolidated},
+		{"snapshot", "enregistre, liste ou supprime un instantané du portefeuille", runSnapshot},
+		{"diff", "compare deux instantanés ou un instantané à l'état courant", runDiff},
+		{"add-cash-account", "ajoute un compte rémunéré (livret, compte à terme) valorisé par ses intérêts", runAddCashAccount},
+		{"set-cash-rate", "change le taux d'un compte rémunéré à partir d'une date", runSetCashRate},
+		{"add-commitment", "ajoute un fonds de capital-investissement avec son engagement de souscription", runAddCommitment},
+		{"capital-call", "enregistre un appel de fonds sur un engagement", runCapitalCall},
+		{"commitments", "affiche engagements restants, multiples DPI/TVPI/RVPI et appels attendus", runCommitments},
+		{"add-recurring", "ajoute ou supprime un plan de versements exécuté automatiquement", runAddRecurringPlan},
+		{"recurring", "liste les plans de versements et enregistre les échéances atteintes (--apply)", runRecurring},
+		{"what-if", "compare les projections du portefeuille avec et sans modifications hypothétiques", runWhatIf},
+		{"compare", "compare deux portefeuilles : valeur, répartition, risque et projection", runCompare},
+		{"backtest", "rejoue une allocation à poids fixes sur l'historique et la compare à l'achat-conservation", runBacktest},
+		{"set-vesting", "attache un calendrier d'acquisition (blocage, tranches) à un investissement", runSetVesting},
+		{"vesting", "affiche la part acquise et les acquisitions à venir", runVesting},
+		{"reminders", "liste les rappels de revue (répartition, échéances, acquisitions) ou les exporte en iCalendar", runReminders},
+		{"set-liquidity", "classe un investissement selon sa liquidité (daily, monthly, quarterly, locked)", runSetLiquidity},
+		{"liquidity", "mesure les sommes disponibles sous une semaine, un mois et un an", runLiquidity},
+		{"add-bond", "ajoute une obligation à coupon fixe achetée à un prix pied de coupon", runAddBond},
+		{"bond", "affiche l'échéancier, le coupon couru et le taux actuariel d'une obligation", runBond},
+		{"add-cash-flow", "enregistre un apport ou un retrait sur un investissement", runAddCashFlow},
+		{"add-distribution", "enregistre un dividende ou une distribution", runAddDistribution},
+		{"add-transaction", "enregistre un achat ou une vente de parts", runAddTransaction},
+		{"sell", "vend une partie d'un investissement et calcule la plus-value réalisée", runSell},
+		{"lots", "affiche les lots de parts détenus (FIFO ou prix moyen pondéré)", runLots},
+		{"gains", "récapitule les plus-values réalisées d'une année pour la déclaration", runGains},
+		{"turnover", "mesure la rotation annuelle, le nombre d'ordres et les frais de transaction", runTurnover},
+		{"remove-investment", "supprime un investissement et son historique", runRemoveInvestment},
+		{"rename-investment", "renomme un investissement", runRenameInvestment},
+		{"close-investment", "clôture un investissement soldé en conservant son historique", runCloseInvestment},
+		{"tag", "étiquette un investissement (classe d'actifs, région, label)", runTag},
+		{"set-owner", "définit le titulaire d'un investissement ou du portefeuille (me, spouse, joint…)", runSetOwner},
+		{"household", "répartit la valeur et la projection du foyer entre ses titulaires", runHousehold},
+		{"add-note", "attache une note, un document ou un lien à un investissement ou une transaction", runAddNote},
+		{"notes", "liste les notes, documents et liens des investissements", runNotes},
+		{"list", "liste les investissements filtrés par étiquette, devise, performance ou poids", runList},
+		{"add-holding", "ajoute une ligne à un investissement composé (mandat, fonds de fonds)", runAddHolding},
+		{"add-holding-nav", "ajoute une NAV à une ligne d'un investissement composé", runAddHoldingNAV},
+		{"holdings", "affiche la composition des investissements composés", runHoldings},
+		{"summary", "affiche le résumé du portefeuille", runSummary},
+		{"project", "projette la valeur du portefeuille à une date donnée", runProject},
+		{"forecast-accuracy", "confronte les projections enregistrées aux valeurs réalisées", runForecastAccuracy},
+		{"allocation", "répartit la valeur du portefeuille selon une étiquette", runAllocation},
+		{"set-exposure", "change le sens d'une position (long, short, signed)", runSetExposure},
+		{"exposure", "affiche les expositions acheteuse, vendeuse, nette et brute", runExposure},
+		{"set-target", "définit l'allocation cible du portefeuille", runSetTarget},
+		{"set-glide-path", "fait décroître la part dynamique de l'allocation cible avec le temps ou l'âge", runSetGlidePath},
+		{"glide-path", "projette le portefeuille en suivant la trajectoire d'allocation", runGlidePath},
+		{"rebalance", "propose les arbitrages pour revenir à l'allocation cible", runRebalance},
+		{"set-fees", "définit les frais d'un investissement", runSetFees},
+		{"fee-impact", "mesure l'effet cumulé des frais sur la projection", runFeeImpact},
+		{"set-tax-wrapper", "définit l'enveloppe fiscale d'un investissement ou du compte (PEA, assurance-vie…)", runSetTaxWrapper},
+		{"monte-carlo", "simule la distribution de la valeur future (P5/P50/P95)", runMonteCarlo},
+		{"set-scenario", "définit un scénario de projection nommé", runSetScenario},
+		{"scenarios", "compare les projections des scénarios définis", runScenarios},
+		{"add-benchmark-value", "ajoute une valeur à un indice de référence", runAddBenchmarkValue},
+		{"set-benchmark", "associe un indice de référence à un investissement", runSetBenchmark},
+		{"benchmark", "compare les investissements à leurs indices de référence", runBenchmarkReport},
+		{"risk", "calcule volatilité, ratios de Sharpe et de Sortino", runRisk},
+		{"drawdown", "mesure la baisse maximale depuis un sommet", runDrawdown},
+		{"nav-at", "valorise un investissement à une date passée", runNAVAt},
+		{"value-as-of", "valorise le portefeuille à une date passée avec les seules données connues alors", runValueAsOf},
+		{"return", "calcule le rendement corrigé des flux d'un investissement ou du portefeuille sur une période", runReturn},
+		{"fx-return", "sépare le rendement local, le rendement converti et l'effet de change des investissements en devise", runFXReturn},
+		{"rolling", "calcule les rendements annualisés sur fenêtres glissantes", runRollingReturns},
+		{"annual", "affiche les performances par année (civile ou exercice, voir year_start) et depuis son début", runAnnualReturns},
+		{"series", "exporte en CSV la valeur historique du portefeuille", runSeries},
+		{"project-series", "exporte en CSV la valeur projetée jusqu'à un horizon", runProjectSeries},
+		{"add-liability", "enregistre (ou supprime) un emprunt amortissable", runAddLiability},
+		{"amortization", "affiche le tableau d'amortissement d'un emprunt", runAmortization},
+		{"net-worth", "calcule le patrimoine net (investissements moins dettes) et sa projection", runNetWorth},
+		{"inflation", "définit l'hypothèse d'inflation (taux constant ou indice des prix)", runInflation},
+		{"real", "projette le portefeuille en monnaie constante", runRealProjection},
+		{"goal", "calcule le versement ou le taux requis pour atteindre un objectif", runGoal},
+		{"sensitivity", "croise versements mensuels et taux de rendement pour comparer leur effet à une date", runSensitivity},
+		{"set-plan", "définit les versements programmés d'un investissement", runSetPlan},
+		{"withdrawals", "simule des retraits mensuels jusqu'à épuisement du capital", runWithdrawals},
+		{"trend", "estime le taux de tendance par régression sur toutes les NAV", runTrend},
+		{"set-rate-policy", "choisit la règle de taux de projection d'un investissement", runSetRatePolicy},
+		{"set-projection", "choisit le modèle de projection d'un investissement (compound, mean-reverting, monte-carlo…)", runSetProjection},
+		{"var", "calcule la valeur en risque et applique les tests de résistance", runVaR},
+		{"correlation", "affiche la matrice de corrélation des investissements", runCorrelation},
+		{"risk-contribution", "décompose la volatilité du portefeuille par investissement", runRiskContribution},
+		{"attribution", "décompose le rendement du portefeuille par investissement et classe d'actifs", runAttribution},
+		{"growth", "sépare l'argent versé de la croissance due au marché sur une période", runGrowth},
+		{"set-identifier", "associe un ISIN ou un ticker à un investissement", runSetIdentifier},
+		{"set-identifiers", "enregistre l'ISIN, le ticker et la place de cotation d'un investissement", runSetIdentifiers},
+		{"lookup", "retrouve un investissement par nom, identifiant, ISIN ou ticker", runLookup},
+		{"validate", "relève les incohérences des données du portefeuille", runValidate},
+		{"data-quality", "évalue les historiques de NAV : écarts, ancienneté, couverture", runDataQuality},
+		{"config", "affiche la configuration de l'utilisateur (DAVID_CONFIG ou ~/.config/david/config.toml ou config.yaml)", runConfig},
+		{"plugins", "liste les extensions de la configuration et leurs capacités (table [plugins])", runPlugins},
+		{"refresh", "met à jour les NAV depuis le fournisseur de cours", runRefresh},
+		{"watch-add", "ajoute un titre à la liste de suivi (suivi sans être détenu)", runWatchAdd},
+		{"watch-remove", "retire un titre de la liste de suivi", runWatchRemove},
+		{"watch-price", "enregistre le cours d'un titre suivi", runWatchPrice},
+		{"watchlist", "classe les titres suivis par performance, comme list", runWatchlist},
+		{"watch", "met à jour les NAV à intervalle régulier", runWatch},
+		{"tui", "tableau de bord interactif dans le terminal", runTUI},
+		{"repl", "session interactive : consultation, NAV, projections, complétion des noms", runREPL},
+		{"add-alert", "ajoute une règle d'alerte", runAddAlert},
+		{"remove-alert", "supprime une règle d'alerte", runRemoveAlert},
+		{"alerts", "évalue les règles d'alerte", runAlerts},
+		{"set-conventions", "définit le décompte des jours et la capitalisation des taux annuels", runSetConventions},
+		{"set-calendar", "définit le calendrier des jours ouvrés et le report des échéances", runSetCalendar},
+		{"set-locale", "définit la langue des résumés et rapports (ou variable DAVID_LANG)", runSetLocale},
+		{"report", "génère un rapport HTML avec graphiques", runReport},
+		{"pdf-report", "génère un rapport PDF imprimable", runPDFReport},
+		{"mail-report", "envoie le rapport HTML ou PDF par courriel, avec les alertes déclenchées", runMailReport},
+		{"plot", "trace les NAV ou la valeur du portefeuille en SVG ou PNG", runPlot},
+		{"export-xlsx", "exporte le portefeuille dans un classeur Excel", runExportXLSX},
+		{"export-tracker", "exporte les opérations pour Portfolio Performance (CSV) ou Ghostfolio (JSON)", runExportTracker},
+		{"serve", "expose le portefeuille via une API REST JSON", runServe},
+		{"token-add", "crée un jeton d'accès à l'API de serve (lecture seule ou écriture)", runTokenAdd},
+		{"token-remove", "révoque un jeton d'accès à l'API", runTokenRemove},
+		{"tokens", "liste les jetons d'accès à l'API", runTokens},
+		{"demo", "affiche le portefeuille d'exemple", runDemo},
+	}
+}
+
+// run exécute la sous-commande désignée par args[0]
+func run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+		return nil
+	}
+
+	if err := portfolio.LoadActiveConfig(); err != nil {
+		return err
+	}
+	envLogger()
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			err := cmd.run(args[1:])
+			flushWebhooks()
+			if errors.Is(err, flag.ErrHelp) {
+				// L'aide a déjà été affichée par le FlagSet
+				return nil
+			}
+			return err
+		}
+	}
+
+	printUsage()
+	return fmt.Errorf("commande inconnue: %s", args[0])
+}
+
+// printUsage affiche la liste des sous-commandes
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: david <commande> [options]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commandes:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Utiliser 'david <commande> -h' pour les options d'une commande.")
+}
+
+// newFlagSet crée le jeu d'options d'une sous-commande avec l'option --file commune
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	defaultFile := portfolio.EnvOrConfig("DAVID_PORTFOLIO", portfolio.ActiveConfig().Portfolio)
+	if defaultFile == "" {
+		defaultFile = defaultPortfolioFile
+	}
+	file := fs.String("file", defaultFile, "fichier du portefeuille (ou variable DAVID_PORTFOLIO, ou clé portfolio de la configuration)")
+	fs.StringVar(&ownerFilter, "owner", "", "restreint la commande aux investissements d'un titulaire (me, spouse, joint…)")
+	return fs, file
+}
+
+// loadPortfolioFile charge le portefeuille, ou en crée un vide si le fichier n'existe pas
+// encore, avec la devise et les conventions de la configuration, puis les taux de change
+// de la configuration. Ses enregistrements sont audités au nom de l'utilisateur et de la
+// commande command.
+func loadPortfolioFile(path, command string) (*portfolio.Portfolio, error) {
+	p, err := portfolio.LoadPortfolioJSON(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		p = portfolio.NewPortfolio()
+		portfolio.ActiveConfig().ApplyDefaults(p)
+	} else if err != nil {
+		return nil, err
+	}
+	if rates := portfolio.EnvOrConfig("DAVID_FX_RATES", portfolio.ActiveConfig().FXRates); rates != "" {
+		table, err := portfolio.LoadRateTable(rates)
+		if err != nil {
+			return nil, err
+		}
+		p.SetRates(table)
+	}
+	if ownerFilter != "" {
+		if p, err = p.ForOwner(ownerFilter); err != nil {
+			return nil, err
+		}
+	}
+	p.SetAuditActor(portfolio.CommandActor(command))
+	relayWebhook(p)
+	return p, nil
+}
+
+func runAddInvestment(args []string) error {
+	fs, file := newFlagSet("add-investment")
+	name := fs.String("name", "", "nom de l'investissement")
+	amount := fs.Float64("amount", 0, "montant investi")
+	quantity := fs.Float64("quantity", 0, "quantité d'actions (avec --unit-price)")
+	unitPrice := fs.Float64("unit-price", 0, "prix unitaire (avec --quantity)")
+	rate := fs.Float64("rate", 0, "taux de référence annuel (%)")
+	date := fs.String("date", "", "date d'investissement (AAAA-MM-JJ)")
+	currency := fs.String("currency", "", "devise de l'investissement (EUR par défaut)")
+	exposure := fs.String("exposure", "", "sens de la position (long, short, signed) ; pour signed, --amount est la prime payée")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+	e, err := portfolio.ParseExposure(*exposure)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if _, exists := p.Investments[*name]; exists {
+		return fmt.Errorf("l'investissement '%s' existe déjà: %w", *name, portfolio.ErrInvestmentExists)
+	}
+
+	switch {
+	case e == portfolio.ExposureSigned:
+		err = p.AddDerivative(*name, *amount, *date)
+	case *quantity != 0 || *unitPrice != 0:
+		err = p.AddInvestmentWithQuantity(*name, *quantity, *unitPrice, *rate, *date)
+	default:
+		err = p.AddInvestment(*name, *amount, *rate, *date)
+	}
+	if err != nil {
+		return err
+	}
+	if e == portfolio.ExposureShort {
+		if err := p.SetExposure(*name, e); err != nil {
+			return err
+		}
+	}
+	if err := p.SetInvestmentCurrency(*name, portfolio.Currency(*currency)); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runAddNAV(args []string) error {
+	fs, file := newFlagSet("add-nav")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de la valorisation (AAAA-MM-JJ)")
+	value := fs.Float64("value", 0, "valeur de la NAV")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddNAV(*name, *date, *value); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runSummary(args []string) error {
+	fs, file := newFlagSet("summary")
+	format := fs.String("format", "text", "format de sortie (text, json, csv, md)")
+	query := queryFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	q, err := query()
+	if err != nil {
+		return err
+	}
+	opts := portfolio.SummaryOptions{Locale: portfolio.EnvLocale(), Sort: q.Sort, Descending: q.Descending, Filters: q.Filters, Metrics: q.Metrics}
+	switch *format {
+	case "text":
+		return p.PrintSummary(opts)
+	case "md":
+		return report.RenderMarkdown(p, os.Stdout, report.ReportOptions{Locale: portfolio.EnvLocale(), Metrics: q.Metrics})
+	}
+	summary, err := p.SummaryWith(opts)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	case "csv":
+		return summary.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("format de sortie inconnu: %s", *format)
+	}
+}
+
+func runProject(args []string) error {
+	fs, file := newFlagSet("project")
+	date := fs.String("date", "", "date de projection (AAAA-MM-JJ)")
+	confidence := fs.Float64("confidence", 0, "niveau de confiance de l'intervalle affiché (ex. 0.9, 0 : aucun)")
+	netOfTax := fs.Bool("net-of-tax", false, "affiche aussi les valeurs nettes d'impôts selon l'enveloppe fiscale")
+	record := fs.Bool("record", false, "enregistre la projection pour en mesurer plus tard la précision (voir forecast-accuracy)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	if err := printProjection(p, *date); err != nil {
+		return err
+	}
+	if *record || p.RecordsForecasts() {
+		recorded, err := p.RecordForecasts(*date)
+		if err != nil {
+			return err
+		}
+		if len(recorded) > 0 {
+			if err := p.SaveJSON(*file); err != nil {
+				return err
+			}
+			fmt.Printf("\n%d projection(s) enregistrée(s)\n", len(recorded))
+		}
+	}
+	if *netOfTax {
+		if err := printNetOfTaxProjection(p, *date); err != nil {
+			return err
+		}
+	}
+	if *confidence == 0 {
+		return nil
+	}
+	return printProjectionInterval(p, *date, *confidence)
+}
+
+// printProjectionInterval affiche les intervalles de confiance des valeurs projetées
+func printProjectionInterval(p *portfolio.Portfolio, projectionDate string, confidence float64) error {
+	intervals, total, err := p.GetPortfolioValueInterval(projectionDate, confidence)
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("\nIntervalle de confiance à %.0f%%:\n", confidence*100)
+	for _, name := range p.InvestmentNames() {
+		if interval, open := intervals[name]; open {
+			fmt.Printf("%s: %s – %s\n", name, amount(interval.Low), amount(interval.High))
+		}
+	}
+	fmt.Printf("Total: %s – %s\n", amount(total.Low), amount(total.High))
+	return nil
+}
+
+// printProjection affiche la valeur projetée de chaque investissement, le total et le gain
+func printProjection(p *portfolio.Portfolio, projectionDate string) error {
+	fmt.Printf("=== PROJECTION AU %s ===\n\n", projectionDate)
+
+	values, totalValue, err := p.GetPortfolioValue(projectionDate)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	amount := report.AmountFormatter(p).Format
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, amount(values[name]))
+	}
+
+	fmt.Printf("\nValeur totale du portefeuille: %s\n", amount(totalValue))
+	printProjectionWarnings(os.Stdout, p)
+
+	// Capital net investi total, versements programmés d'ici la date de projection compris
+	end, err := portfolio.ParseDate(projectionDate)
+	if err != nil {
+		return err
+	}
+	var totalInvested portfolio.Money
+	for _, name := range p.InvestmentNames() {
+		// Un investissement écarté de la valorisation (MissingNAVSkip) l'est aussi du capital
+		inv, err := p.Investment(name)
+		if _, valued := values[name]; err != nil || inv.Closed || !valued {
+			continue
+		}
+		totalInvested += portfolio.NewMoney(inv.NetInvested().Float64() * inv.Sign())
+		if latestNAV, err := inv.GetLatestNAV(); err == nil {
+			for _, c := range inv.PlannedContributions(latestNAV.Date.Time, end) {
+				totalInvested += portfolio.NewMoney(c.Amount.Float64() * inv.Sign())
+			}
+		}
+	}
+	if totalInvested == 0 {
+		return nil
+	}
+
+	gain := totalValue - totalInvested.Float64()
+	gainPercent := (gain / totalInvested.Float64()) * 100
+	fmt.Printf("Montant investi total: %s\n", amount(totalInvested.Float64()))
+	fmt.Printf("Gain/Perte: %s (%.2f%%)\n", amount(gain), gainPercent)
+
+	return nil
+}
+
+// runDemo construit un portefeuille d'exemple et affiche son résumé et sa projection
+func runDemo(args []string) error {
+	// Créer un portefeuille
+	demo := portfolio.NewPortfolio()
+
+	// Ajouter des investissements
+	// Méthode 1: Par montant investi
+	demo.AddInvestment("Action Tech", 5000, 8.0, "2024-01-01")
+
+	// Méthode 2: Par quantité et prix unitaire
+	demo.AddInvestmentWithQuantity("Obligation Corp", 100, 30.0, 4.5, "2024-01-01")
+	demo.AddInvestmentWithQuantity("Fonds Immobilier", 50, 80.0, 6.0, "2024-01-01")
+
+	// Ajouter les NAV historiques
+	// Action Tech
+	demo.AddNAV("Action Tech", "2024-01-01", 5000)
+	demo.AddNAV("Action Tech", "2024-07-01", 5300)
+	demo.AddNAV("Action Tech", "2026-01-15", 6200)
+
+	// Obligation Corp
+	demo.AddNAV("Obligation Corp", "2024-01-01", 3000)
+	demo.AddNAV("Obligation Corp", "2024-07-01", 3067)
+	demo.AddNAV("Obligation Corp", "2026-01-15", 3235)
+
+	// Fonds Immobilier
+	demo.AddNAV("Fonds Immobilier", "2024-01-01", 4000)
+	demo.AddNAV("Fonds Immobilier", "2024-07-01", 4150)
+	demo.AddNAV("Fonds Immobilier", "2026-01-15", 4650)
+
+	// Afficher le résumé
+	demo.PrintPortfolioSummary()
+
+	// Projeter la valeur du portefeuille à une date future
+	return printProjection(demo, "2027-01-15")
+}
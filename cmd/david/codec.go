@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetFormat(args []string) error {
+	fs, file := newFlagSet("set-format")
+	format := fs.String("format", string(portfolio.FormatBinary), "format du fichier (json, binary)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetStorageFormat(portfolio.StorageFormat(*format)); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("%s est enregistré au format %s\n", *file, *format)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddCommitment(args []string) error {
+	fs, file := newFlagSet("add-commitment")
+	name := fs.String("name", "", "nom du fonds")
+	amount := fs.Float64("amount", 0, "montant souscrit")
+	firstCall := fs.Float64("first-call", 0, "premier appel versé à la souscription")
+	rate := fs.Float64("rate", 0, "taux de référence annuel (%)")
+	date := fs.String("date", "", "date de souscription (AAAA-MM-JJ)")
+	callsEnd := fs.String("calls-end", "", "fin de la période d'investissement (AAAA-MM-JJ)")
+	frequency := fs.String("frequency", string(portfolio.StepQuarterly), "périodicité attendue des appels (monthly, quarterly, yearly)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" || *callsEnd == "" {
+		return fmt.Errorf("--name, --date et --calls-end sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddCommitment(*name, *amount, *firstCall, *rate, *date, *callsEnd, portfolio.SeriesStep(*frequency)); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runCapitalCall(args []string) error {
+	fs, file := newFlagSet("capital-call")
+	name := fs.String("name", "", "nom du fonds")
+	date := fs.String("date", "", "date de l'appel (AAAA-MM-JJ)")
+	amount := fs.Float64("amount", 0, "montant appelé")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.CapitalCall(*name, *date, *amount); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runCommitments(args []string) error {
+	fs, file := newFlagSet("commitments")
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date de la situation (AAAA-MM-JJ)")
+	horizon := fs.Int("months", 12, "horizon des appels attendus (mois)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	t, err := portfolio.ParseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== ENGAGEMENTS AU %s ===\n", *date)
+	for _, name := range p.InvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Commitment == nil {
+			continue
+		}
+		s, err := inv.CommitmentStatus(t)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-20s engagé %s  appelé %s  restant %s  distribué %s  valeur %s  DPI %.2fx  RVPI %.2fx  TVPI %.2fx\n",
+			name, s.Committed, s.Called, s.Unfunded, s.Distributed, s.Value, s.DPI, s.RVPI, s.TVPI)
+	}
+
+	calls := p.ExpectedCapitalCalls(t, t.AddDate(0, *horizon, 0))
+	if len(calls) == 0 {
+		return nil
+	}
+	fmt.Printf("\nAppels attendus sur %d mois:\n", *horizon)
+	for _, name := range p.InvestmentNames() {
+		for _, cf := range calls[name] {
+			fmt.Printf("  %s  %-20s %s\n", portfolio.FormatDate(cf.Date.Time), name, cf.Amount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runCompare(args []string) error {
+	fs, file := newFlagSet("compare")
+	with := fs.String("with", "", "fichier du portefeuille à comparer")
+	var changes stringList
+	fs.Var(&changes, "change", "à défaut de --with, compare à la répartition envisagée \"action=add|remove|contribute|withdraw,investment=X,amount=10000,rate=5\" (répétable)")
+	date := fs.String("date", "", "date de projection (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+	if (*with == "") == (len(changes) == 0) {
+		return fmt.Errorf("--with ou au moins une --change est obligatoire, mais pas les deux")
+	}
+
+	a, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var b *portfolio.Portfolio
+	columns := [2]string{"Actuel", "Envisagé"}
+	if *with != "" {
+		if b, err = portfolio.LoadPortfolioJSON(*with); err != nil {
+			return err
+		}
+		columns = [2]string{filepath.Base(*file), filepath.Base(*with)}
+	} else {
+		var parsed []portfolio.Change
+		for _, s := range changes {
+			ch, err := parseChange(s)
+			if err != nil {
+				return err
+			}
+			parsed = append(parsed, ch)
+		}
+		if b, err = a.WhatIf(parsed...); err != nil {
+			return err
+		}
+	}
+
+	c, err := portfolio.ComparePortfolios(a, b, *date)
+	if err != nil {
+		return err
+	}
+
+	amountA, amountB := report.AmountFormatter(a).Format, report.AmountFormatter(b).Format
+	row := func(label, x, y string) { fmt.Printf("%-28s %18s %18s\n", label, x, y) }
+	fmt.Printf("=== COMPARAISON AU %s ===\n", *date)
+	row("", columns[0], columns[1])
+	row("Montant investi", amountA(c.A.Invested), amountB(c.B.Invested))
+	row("Valeur actuelle", amountA(c.A.Value), amountB(c.B.Value))
+	row("Valeur projetée au "+*date, amountA(c.A.Projection), amountB(c.B.Projection))
+
+	fmt.Println("\nRépartition par classe d'actifs:")
+	for _, label := range c.Labels {
+		x, okA := c.A.Allocation[label]
+		y, okB := c.B.Allocation[label]
+		row("  "+label, portfolio.FormatPercentCell(x, okA), portfolio.FormatPercentCell(y, okB))
+	}
+
+	fmt.Println("\nRisque:")
+	risk := func(m *analytics.RiskMetrics, format string, field func(analytics.RiskMetrics) float64) string {
+		if m == nil {
+			return "-"
+		}
+		return fmt.Sprintf(format, field(*m))
+	}
+	for _, r := range []struct {
+		label, format string
+		field         func(analytics.RiskMetrics) float64
+	}{
+		{"  Rendement annualisé", "%.2f%%", func(m analytics.RiskMetrics) float64 { return m.Return }},
+		{"  Volatilité", "%.2f%%", func(m analytics.RiskMetrics) float64 { return m.Volatility }},
+		{"  Sharpe", "%.2f", func(m analytics.RiskMetrics) float64 { return m.Sharpe }},
+		{"  Sortino", "%.2f", func(m analytics.RiskMetrics) float64 { return m.Sortino }},
+	} {
+		row(r.label, risk(c.A.Risk, r.format, r.field), risk(c.B.Risk, r.format, r.field))
+	}
+	return nil
+}
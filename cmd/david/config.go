@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runConfig(args []string) error {
+	fs, _ := newFlagSet("config")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := portfolio.ActiveConfig()
+	if c.Path == "" {
+		path, _ := portfolio.ConfigPath()
+		fmt.Printf("Aucune configuration (%s absent)\n", path)
+		return nil
+	}
+	fmt.Printf("Configuration: %s\n", c.Path)
+	show := func(key string, value any) {
+		if s := fmt.Sprint(value); s != "" && s != "0s" && s != "0" {
+			fmt.Printf("  %-35s %s\n", key, s)
+		}
+	}
+	show("portfolio", c.Portfolio)
+	show("base_currency", c.BaseCurrency)
+	show("locale", c.Locale)
+	show("log", c.Log)
+	show("year_start", c.YearStart)
+	show("fx_rates", c.FXRates)
+	show("conventions.day_count", c.Conventions.DayCount)
+	show("conventions.compounding", c.Conventions.Compounding)
+	show("conventions.min_annualization_days", c.Conventions.MinAnnualizationDays)
+	if c.RatePolicy != nil {
+		show("rate_policy.mode", c.RatePolicy.Mode)
+		show("rate_policy.realized_weight", c.RatePolicy.RealizedWeight)
+	}
+	show("alignment.step", c.Alignment.Step)
+	show("alignment.mode", c.Alignment.Mode)
+	show("guardrails.max_rate", c.Guardrails.MaxRate)
+	show("guardrails.max_horizon_years", c.Guardrails.MaxHorizon)
+	show("guardrails.min_rate_window_days", c.Guardrails.MinRateWindow)
+	show("quotes.provider_url", c.Quotes.ProviderURL)
+	show("quotes.crypto_url", c.Quotes.CryptoURL)
+	show("smtp.server", c.SMTP.Server)
+	show("smtp.from", c.SMTP.From)
+	show("smtp.to", c.SMTP.To)
+	if c.SMTP.Password != "" {
+		show("smtp.password", "********")
+	}
+	show("serve.addr", c.Serve.Addr)
+	show("serve.grpc_addr", c.Serve.GRPCAddr)
+	show("serve.refresh_every", c.Serve.RefreshEvery)
+	show("serve.timeout", c.Serve.Timeout)
+	show("serve.tokens", c.Serve.Tokens)
+	show("serve.cache_entries", c.Serve.CacheEntries)
+	show("webhook.url", c.Webhook.URL)
+	if c.Webhook.Secret != "" {
+		show("webhook.secret", "********")
+	}
+	if len(c.Webhook.Events) > 0 {
+		show("webhook.events", c.Webhook.Events)
+	}
+	show("webhook.retries", c.Webhook.Retries)
+	show("webhook.backoff", c.Webhook.Backoff)
+	for _, m := range c.Metrics {
+		show("metrics."+m.Name, m.Source)
+	}
+	for _, proj := range c.Projectors {
+		show("projectors."+proj.Name(), proj.(portfolio.ExprProjector).Source)
+	}
+	for _, pl := range c.Plugins {
+		show("plugins."+pl.Name, pl.Path)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+func runSetConventions(args []string) error {
+	fs, file := newFlagSet("set-conventions")
+	dayCount := fs.String("day-count", string(analytics.DayCountActual36525), "décompte des jours (act/365.25, act/365, act/360, 30/360)")
+	compounding := fs.String("compounding", string(analytics.CompoundYearly), "capitalisation des taux annuels (yearly, monthly, daily, continuous)")
+	minDays := fs.Int("min-annualization-days", analytics.DefaultMinAnnualizationDays, "durée minimale d'un rendement annualisé (jours, 1 pour toujours annualiser)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	c := analytics.RateConventions{DayCount: analytics.DayCount(*dayCount), Compounding: analytics.Compounding(*compounding), MinAnnualizationDays: *minDays}
+	// Les valeurs par défaut ne sont pas enregistrées
+	if c.DayCount == analytics.DayCountActual36525 {
+		c.DayCount = ""
+	}
+	if c.Compounding == analytics.CompoundYearly {
+		c.Compounding = ""
+	}
+	if c.MinAnnualizationDays == analytics.DefaultMinAnnualizationDays {
+		c.MinAnnualizationDays = 0
+	}
+	if err := p.SetConventions(c); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Conventions du portefeuille: %s, capitalisation %s, annualisation au-delà de %d jours\n", *dayCount, *compounding, *minDays)
+	return nil
+}
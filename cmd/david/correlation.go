@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runCorrelation(args []string) error {
+	fs, file := newFlagSet("correlation")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	m, err := p.CorrelationMatrix(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== CORRÉLATIONS DES RENDEMENTS (grille %s) ===\n", portfolio.ConfiguredAlignment().Step)
+	fmt.Println()
+	fmt.Printf("%-20s", "")
+	for _, name := range m.Names {
+		fmt.Printf("%12.12s", name)
+	}
+	fmt.Println()
+	for i, name := range m.Names {
+		fmt.Printf("%-20.20s", name)
+		for j := range m.Names {
+			if !m.Defined[i][j] {
+				fmt.Printf("%12s", "-")
+			} else {
+				fmt.Printf("%12.2f", m.Values[i][j])
+			}
+		}
+		fmt.Println()
+	}
+	return nil
+}
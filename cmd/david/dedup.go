@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetDuplicatePolicy(args []string) error {
+	fs, file := newFlagSet("set-duplicate-policy")
+	policy := fs.String("policy", "", "traitement des NAV de même date (error, keep-first, keep-last, average)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	parsed, err := portfolio.ParseDuplicateNAVPolicy(*policy)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetDuplicateNAVPolicy(parsed); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runDedupNAVs(args []string) error {
+	fs, file := newFlagSet("dedup-navs")
+	policy := fs.String("policy", "", "politique appliquée aux conflits (celle du portefeuille si vide)")
+	dryRun := fs.Bool("dry-run", false, "affiche les fusions sans modifier le portefeuille")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var parsed portfolio.DuplicateNAVPolicy
+	if *policy != "" {
+		var err error
+		if parsed, err = portfolio.ParseDuplicateNAVPolicy(*policy); err != nil {
+			return err
+		}
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	merges, err := p.DeduplicateNAVs(parsed, *dryRun)
+	if err != nil {
+		return err
+	}
+	if len(merges) == 0 {
+		fmt.Println("Aucune NAV en double")
+		return nil
+	}
+	for _, m := range merges {
+		fmt.Println(m)
+	}
+	if *dryRun {
+		return nil
+	}
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+)
+
+func runAddDistribution(args []string) error {
+	fs, file := newFlagSet("add-distribution")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de versement (AAAA-MM-JJ)")
+	amount := fs.Float64("amount", 0, "montant distribué")
+	reinvested := fs.Bool("reinvested", false, "distribution réinvestie dans l'investissement")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddDistribution(*name, *date, *amount, *reinvested); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runCompactNAVs(args []string) error {
+	fs, file := newFlagSet("compact-navs")
+	name := fs.String("name", "", "nom de l'investissement")
+	granularity := fs.String("granularity", string(portfolio.StepMonthly), "granularité conservée (weekly, monthly, quarterly, yearly)")
+	before := fs.String("before", "", "ne compacte que les NAV antérieures à cette date (AAAA-MM-JJ, tout l'historique si vide)")
+	dryRun := fs.Bool("dry-run", false, "affiche le nombre de NAV conservées sans modifier le portefeuille")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		inv, err := p.Investment(*name)
+		if err != nil {
+			return err
+		}
+		until, err := portfolio.ParseCutoff(*before)
+		if err != nil {
+			return err
+		}
+		navs, err := portfolio.DownsampleNAVs(inv.NAVHistory, portfolio.SeriesStep(*granularity), until)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d NAV sur %d seraient conservées\n", len(navs), len(inv.NAVHistory))
+		return nil
+	}
+	removed, err := p.CompactNAVs(*name, portfolio.SeriesStep(*granularity), *before)
+	if err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("%d NAV supprimées\n", removed)
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runDrawdown(args []string) error {
+	fs, file := newFlagSet("drawdown")
+	name := fs.String("name", "", "investissement à analyser (tout le portefeuille si vide)")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ, portefeuille uniquement)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ, portefeuille uniquement)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	var d portfolio.Drawdown
+	if *name != "" {
+		inv, err := p.Investment(*name)
+		if err != nil {
+			return err
+		}
+		if d, err = inv.MaxDrawdown(); err != nil {
+			return err
+		}
+	} else if d, err = p.MaxDrawdown(*from, *to); err != nil {
+		return err
+	}
+
+	if d.Depth == 0 {
+		fmt.Println("Aucune baisse sur la période")
+		return nil
+	}
+	fmt.Printf("Baisse maximale: %.2f%% (sommet %s, creux %s)\n", d.Depth, portfolio.FormatDate(d.Peak.Time), portfolio.FormatDate(d.Trough.Time))
+	if d.Recovered() {
+		fmt.Printf("Sommet retrouvé le %s\n", portfolio.FormatDate(d.Recovery.Time))
+	} else {
+		fmt.Println("Sommet non retrouvé")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runEncrypt(args []string) error {
+	fs, file := newFlagSet("encrypt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	passphrase, err := portfolio.ReadPassphrase("Nouvelle phrase secrète: ")
+	if err != nil {
+		return err
+	}
+	if os.Getenv("DAVID_PASSPHRASE") == "" {
+		confirm, err := portfolio.ReadPassphrase("Confirmation: ")
+		if err != nil {
+			return err
+		}
+		if confirm != passphrase {
+			return fmt.Errorf("les phrases secrètes ne correspondent pas")
+		}
+	}
+	if err := p.SetPassphrase(passphrase); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("%s est chiffré\n", *file)
+	return nil
+}
+
+func runDecrypt(args []string) error {
+	fs, file := newFlagSet("decrypt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetPassphrase(""); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("%s est enregistré en clair\n", *file)
+	return nil
+}
+
+// readTerminalPassphrase saisit une phrase secrète sans écho sur le terminal
+func readTerminalPassphrase(prompt string) (string, error) {
+	previous, err := stty("-echo")
+	if err != nil {
+		return "", fmt.Errorf("phrase secrète requise (variable DAVID_PASSPHRASE): %w", err)
+	}
+	defer stty(previous)
+
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
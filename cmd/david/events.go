@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// valuationEvent est la valorisation diffusée sur GET /events après chaque modification
+type valuationEvent struct {
+	ID          int                    `json:"id"`
+	Time        time.Time              `json:"time"`
+	Currency    portfolio.Currency     `json:"currency"`
+	Total       float64                `json:"total"`
+	Change      float64                `json:"change"` // Variation du total depuis l'événement précédent
+	Investments []investmentValueEvent `json:"investments"`
+}
+
+// investmentValueEvent est la valeur d'un investissement dans un événement de valorisation
+type investmentValueEvent struct {
+	Name      string         `json:"name"`
+	Value     float64        `json:"value"`  // Dans la devise de consolidation
+	Change    float64        `json:"change"` // Variation depuis l'événement précédent
+	LatestNAV *portfolio.NAV `json:"latest_nav,omitempty"`
+}
+
+// valuationHub diffuse les valorisations aux clients abonnés et retient la dernière
+// pour calculer les variations et l'envoyer aux nouveaux abonnés
+type valuationHub struct {
+	mu      sync.Mutex
+	clients map[chan valuationEvent]struct{}
+	last    *valuationEvent
+}
+
+func newValuationHub() *valuationHub {
+	return &valuationHub{clients: make(map[chan valuationEvent]struct{})}
+}
+
+// subscribe abonne un client ; le canal reçoit d'abord la dernière valorisation connue
+func (h *valuationHub) subscribe() (chan valuationEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan valuationEvent, 8)
+	if h.last != nil {
+		ch <- *h.last
+	}
+	h.clients[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.clients, ch)
+	}
+}
+
+// publish valorise le portefeuille et diffuse le résultat. Un client trop lent pour
+// vider son canal manque l'événement, pas les suivants.
+func (h *valuationHub) publish(p *portfolio.Portfolio) error {
+	summary, err := p.Summary()
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event := valuationEvent{Time: time.Now().UTC(), Currency: summary.BaseCurrency, Total: summary.TotalValue}
+	previous := make(map[string]float64)
+	if h.last != nil {
+		event.ID = h.last.ID + 1
+		event.Change = summary.TotalValue - h.last.Total
+		for _, inv := range h.last.Investments {
+			previous[inv.Name] = inv.Value
+		}
+	}
+	for _, line := range summary.Investments {
+		if line.Closed {
+			continue
+		}
+		ev := investmentValueEvent{Name: line.Name, Value: line.Value}
+		if old, ok := previous[line.Name]; ok {
+			ev.Change = line.Value - old
+		}
+		ev.LatestNAV = line.LatestNAV
+		event.Investments = append(event.Investments, ev)
+	}
+	h.last = &event
+
+	for ch := range h.clients {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// handleEvents diffuse les valorisations en Server-Sent Events (événement "valuation",
+// données JSON), avec un commentaire périodique pour maintenir la connexion
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("diffusion non prise en charge"))
+		return
+	}
+
+	s.mu.Lock()
+	if s.events.last == nil {
+		if err := s.events.publish(s.portfolio); err != nil {
+			s.mu.Unlock()
+			writeError(w, statusForError(err), err)
+			return
+		}
+	}
+	ch, unsubscribe := s.events.subscribe()
+	s.mu.Unlock()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event := <-ch:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("encodage de l'événement: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: valuation\ndata: %s\n\n", event.ID, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetFees(args []string) error {
+	fs, file := newFlagSet("set-fees")
+	name := fs.String("name", "", "nom de l'investissement")
+	ter := fs.Float64("ter", 0, "frais courants annuels (%)")
+	custody := fs.Float64("custody", 0, "droits de garde annuels fixes")
+	entry := fs.Float64("entry", 0, "frais d'entrée (%)")
+	exit := fs.Float64("exit", 0, "frais de sortie (%)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	var fees *portfolio.FeeSchedule
+	if *ter != 0 || *custody != 0 || *entry != 0 || *exit != 0 {
+		fees = &portfolio.FeeSchedule{TER: *ter, CustodyFee: portfolio.NewMoney(*custody), EntryFee: *entry, ExitFee: *exit}
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetFeeSchedule(*name, fees); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runFeeImpact(args []string) error {
+	fs, file := newFlagSet("fee-impact")
+	date := fs.String("date", "", "horizon de projection (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	report, err := p.FeeImpactReport(*date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== IMPACT DES FRAIS AU %s ===\n\n", *date)
+	for _, impact := range append(report.Investments, report.Total) {
+		fmt.Printf("%s: brut %.2f€, frais courants %.2f€, frais de sortie %.2f€, net %.2f€ (%.2f%%)\n",
+			impact.Name, impact.GrossValue, impact.OngoingFees, impact.ExitFees, impact.LiquidationValue, impact.Drag)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runForecastAccuracy(args []string) error {
+	fs, file := newFlagSet("forecast-accuracy")
+	record := fs.String("record", "", "on pour enregistrer désormais chaque projection de la commande project, off pour cesser")
+	verbose := fs.Bool("verbose", false, "détaille chaque projection évaluée")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	switch *record {
+	case "":
+	case "on", "off":
+		p.SetForecastRecording(*record == "on")
+		if err := p.SaveJSON(*file); err != nil {
+			return err
+		}
+		fmt.Printf("Enregistrement des projections: %s\n", *record)
+		return nil
+	default:
+		return portfolio.InvalidField("record", *record, "valeur inconnue: %s (on, off)", *record)
+	}
+
+	report := p.ForecastAccuracy()
+	if len(report) == 0 {
+		fmt.Println("Aucune projection enregistrée (project --record, ou forecast-accuracy --record on)")
+		return nil
+	}
+	fmt.Println("=== PRÉCISION DES PROJECTIONS ===")
+	for _, a := range report {
+		fmt.Printf("\n%s: %d évaluée(s), %d en attente\n", a.Investment, a.Evaluated, a.Pending)
+		if a.Evaluated == 0 {
+			continue
+		}
+		fmt.Printf("  Biais: %+.2f%%  Écart absolu moyen: %.2f%%\n", a.Bias, a.MAPE)
+		w := a.Worst
+		fmt.Printf("  Plus grand écart: %+.2f%% (projeté le %s pour le %s)\n", w.Error, portfolio.FormatDate(w.Recorded.Time), portfolio.FormatDate(w.Target.Time))
+		if !*verbose {
+			continue
+		}
+		for _, o := range a.Outcomes {
+			fmt.Printf("  %s → %s  %s à %.2f%%: projeté %.2f, réalisé %.2f (%+.2f%%)\n",
+				portfolio.FormatDate(o.Recorded.Time), portfolio.FormatDate(o.Target.Time), o.Model, o.Rate, o.Projected.Float64(), o.Realized, o.Error)
+		}
+	}
+	return nil
+}
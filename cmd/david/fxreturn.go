@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runFXReturn(args []string) error {
+	fs, file := newFlagSet("fx-return")
+	name := fs.String("name", "", "nom de l'investissement (tous ceux en devise étrangère si vide)")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ, date d'investissement par défaut)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ, dernière NAV par défaut)")
+	annualize := fs.Bool("annualize", false, "convertit les rendements en taux annuels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var returns []portfolio.CurrencyReturn
+	if *name == "" {
+		if returns, err = p.CurrencyReturns(*from, *to, *annualize); err != nil {
+			return err
+		}
+	} else {
+		r, err := p.CurrencyReturn(*name, *from, *to, *annualize)
+		if err != nil {
+			return err
+		}
+		returns = append(returns, r)
+	}
+	if len(returns) == 0 {
+		fmt.Println("Aucun investissement en devise étrangère")
+		return nil
+	}
+
+	base := p.ConsolidationCurrency()
+	fmt.Printf("%-20s %-6s %-23s %10s %10s %10s %10s\n", "Investissement", "Devise", "Période", "Local", string(base), "Change", "Effet")
+	for _, r := range returns {
+		fmt.Printf("%-20s %-6s %s → %s %9.2f%% %9.2f%% %9.2f%% %+9.2f%%\n", report.Truncate(r.Investment, 20), r.Currency,
+			portfolio.FormatDate(r.From), portfolio.FormatDate(r.To), r.Local, r.Base, r.FXChange, r.FXEffect)
+	}
+	if *annualize {
+		fmt.Println("\nRendements annualisés ; l'effet de change est l'écart entre le rendement converti et le rendement local")
+	} else {
+		fmt.Println("\nL'effet de change est l'écart entre le rendement converti et le rendement local")
+	}
+	return nil
+}
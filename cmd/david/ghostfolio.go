@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// trackerFormats sont les formats de export-tracker
+var trackerFormats = map[string]func(*portfolio.Portfolio, io.Writer) error{
+	"pp-csv":     (*portfolio.Portfolio).ExportPortfolioPerformanceCSV,
+	"ghostfolio": (*portfolio.Portfolio).ExportGhostfolio,
+}
+
+func runExportTracker(args []string) error {
+	fs, file := newFlagSet("export-tracker")
+	format := fs.String("format", "pp-csv", "format d'export (pp-csv pour Portfolio Performance, ghostfolio)")
+	output := fs.String("output", "", "fichier à écrire (sortie standard si vide)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	export, known := trackerFormats[*format]
+	if !known {
+		return portfolio.InvalidField("format", *format, "format d'export inconnu: %s (pp-csv, ghostfolio)", *format)
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return export(p, os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := export(p, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Export %s écrit dans %s\n", *format, *output)
+	return nil
+}
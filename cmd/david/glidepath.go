@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// parseGlidePoints lit des points "AAAA-MM-JJ=part" ; avec une date de naissance, un
+// point peut être donné par âge ("65=40" : 40 % au 65e anniversaire)
+func parseGlidePoints(list []string, birth time.Time) ([]portfolio.GlidePoint, error) {
+	points := make([]portfolio.GlidePoint, 0, len(list))
+	for _, item := range list {
+		when, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, portfolio.InvalidField("point", item, "point invalide: %s (date=part attendu)", item)
+		}
+		growth, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, portfolio.InvalidField("point", item, "part invalide: %s", raw)
+		}
+		when = strings.TrimSpace(when)
+		var date time.Time
+		if age, err := strconv.Atoi(when); err == nil {
+			if birth.IsZero() {
+				return nil, portfolio.InvalidField("point", item, "un point par âge nécessite --birth: %s", item)
+			}
+			date = birth.AddDate(age, 0, 0)
+		} else if date, err = portfolio.ParseDate(when); err != nil {
+			return nil, err
+		}
+		points = append(points, portfolio.GlidePoint{Date: portfolio.Date{Time: date}, Growth: growth})
+	}
+	return points, nil
+}
+
+func runSetGlidePath(args []string) error {
+	fs, file := newFlagSet("set-glide-path")
+	growth := fs.String("growth", "", "investissements de la poche dynamique, séparés par des virgules")
+	birth := fs.String("birth", "", "date de naissance (AAAA-MM-JJ) pour donner les points par âge")
+	var points stringList
+	fs.Var(&points, "point", "part dynamique visée \"AAAA-MM-JJ=90\" ou \"âge=90\" avec --birth (répétable) ; aucun point pour supprimer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var birthDate time.Time
+	if *birth != "" {
+		t, err := portfolio.ParseDate(*birth)
+		if err != nil {
+			return err
+		}
+		birthDate = t
+	}
+	parsed, err := parseGlidePoints(points, birthDate)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, name := range strings.Split(*growth, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetGlidePath(names, parsed); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runGlidePath(args []string) error {
+	fs, file := newFlagSet("glide-path")
+	to := fs.String("to", "", "horizon de la projection (AAAA-MM-JJ)")
+	step := fs.String("step", string(portfolio.StepYearly), "pas de rééquilibrage et d'affichage (monthly, quarterly, yearly)")
+	monthly := fs.Float64("monthly", 0, "versement mensuel réparti selon les poids cibles")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *to == "" {
+		return fmt.Errorf("--to est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	steps, err := p.GlidePathProjection(*to, portfolio.SeriesStep(*step), *monthly)
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Println("=== TRAJECTOIRE D'ALLOCATION ===")
+	fmt.Printf("%-12s %10s %18s\n", "Date", "Dynamique", "Valeur projetée")
+	for _, s := range steps {
+		fmt.Printf("%-12s %9.1f%% %18s\n", portfolio.FormatDate(s.Date), s.Growth, amount(s.Total))
+	}
+	return nil
+}
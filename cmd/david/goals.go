@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runGoal(args []string) error {
+	fs, file := newFlagSet("goal")
+	target := fs.Float64("target", 0, "valeur cible du portefeuille")
+	date := fs.String("date", "", "date de l'objectif (AAAA-MM-JJ)")
+	solve := fs.String("solve", "contribution", "inconnue à calculer (contribution ou rate)")
+	monthly := fs.Float64("monthly", 0, "versement mensuel prévu (avec --solve rate)")
+	name := fs.String("name", "", "investissement visé (avec --solve rate), tout le portefeuille si vide")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target <= 0 || *date == "" {
+		return fmt.Errorf("--target et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	switch *solve {
+	case "contribution":
+		amount, err := p.RequiredContribution(*target, *date)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Versement mensuel requis pour atteindre %.2f€ le %s: %.2f€\n", *target, *date, amount)
+	case "rate":
+		if *name != "" {
+			return printImpliedRate(p, *name, *target, *date)
+		}
+		rate, err := p.RequiredRate(*target, *date, *monthly)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Taux annuel requis pour atteindre %.2f€ le %s avec %.2f€ par mois: %.2f%%\n", *target, *date, *monthly, rate)
+	default:
+		return fmt.Errorf("inconnue à calculer invalide: %s (contribution ou rate)", *solve)
+	}
+	return nil
+}
+
+// printImpliedRate affiche le taux implicite d'un investissement face à son taux
+// historique et à son taux de projection, pour juger du réalisme de l'objectif
+func printImpliedRate(p *portfolio.Portfolio, name string, target float64, date string) error {
+	inv, err := p.Investment(name)
+	if err != nil {
+		return err
+	}
+	rate, err := inv.ImpliedRate(target, date)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Taux annuel implicite pour que %s atteigne %.2f le %s: %.2f%%\n", name, target, date, rate)
+	if historical, err := inv.CalculatePerformanceRate(); err == nil {
+		fmt.Printf("Taux historique: %.2f%% (écart %+.2f points)\n", historical, rate-historical)
+	}
+	if effective, err := inv.ProjectionRate(nil); err == nil {
+		fmt.Printf("Taux de projection actuel: %.2f%%\n", effective)
+	}
+	return nil
+}
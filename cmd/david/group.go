@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// defaultGroupFile est le fichier de groupe utilisé quand ni --group ni DAVID_GROUP ne sont fournis
+const defaultGroupFile = "david-group.json"
+
+// groupFileFlag ajoute l'option --group d'un fichier de groupe
+func groupFileFlag(fs *flag.FlagSet) *string {
+	defaultFile := os.Getenv("DAVID_GROUP")
+	if defaultFile == "" {
+		defaultFile = defaultGroupFile
+	}
+	return fs.String("group", defaultFile, "fichier du groupe de comptes (ou variable DAVID_GROUP)")
+}
+
+func runAddAccount(args []string) error {
+	fs, file := newFlagSet("add-account")
+	group := groupFileFlag(fs)
+	name := fs.String("name", "", "nom du compte (PEA, assurance-vie…)")
+	currency := fs.String("currency", "", "devise de consolidation du groupe (à la création)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	m, err := portfolio.ReadGroupManifest(*group)
+	if err != nil {
+		return err
+	}
+	if _, exists := m.Accounts[*name]; exists {
+		return fmt.Errorf("le compte '%s' existe déjà: %w", *name, portfolio.ErrAlreadyExists)
+	}
+	if *currency != "" {
+		if len(m.Accounts) > 0 && m.BaseCurrency != portfolio.Currency(*currency) {
+			return fmt.Errorf("la devise du groupe est déjà %s", m.BaseCurrency)
+		}
+		m.BaseCurrency = portfolio.Currency(*currency)
+	}
+	if _, err := portfolio.LoadPortfolioJSON(*file); err != nil {
+		return fmt.Errorf("compte %s: %w", *name, err)
+	}
+	path := *file
+	if rel, err := filepath.Rel(filepath.Dir(*group), *file); err == nil && !filepath.IsAbs(*file) {
+		path = rel
+	}
+	m.Accounts[*name] = path
+	if err := m.Save(*group); err != nil {
+		return err
+	}
+	fmt.Printf("Compte %s ajouté au groupe %s\n", *name, *group)
+	return nil
+}
+
+func runRemoveAccount(args []string) error {
+	fs := flag.NewFlagSet("remove-account", flag.ContinueOnError)
+	group := groupFileFlag(fs)
+	name := fs.String("name", "", "nom du compte")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := portfolio.ReadGroupManifest(*group)
+	if err != nil {
+		return err
+	}
+	if _, exists := m.Accounts[*name]; !exists {
+		return fmt.Errorf("aucun compte '%s': %w", *name, portfolio.ErrNotFound)
+	}
+	delete(m.Accounts, *name)
+	return m.Save(*group)
+}
+
+func runConsolidated(args []string) error {
+	fs := flag.NewFlagSet("consolidated", flag.ContinueOnError)
+	group := groupFileFlag(fs)
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date de valorisation (AAAA-MM-JJ)")
+	tag := fs.String("tag", "", "répartit la valeur selon une étiquette (ou \"account\") au lieu du résumé")
+	format := fs.String("format", "text", "format de sortie (text, json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	g, err := portfolio.LoadPortfolioGroup(*group)
+	if err != nil {
+		return err
+	}
+	if *tag != "" {
+		allocation, err := g.AllocationByTag(*tag, *date)
+		if err != nil {
+			return err
+		}
+		labels := make([]string, 0, len(allocation))
+		for label := range allocation {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			fmt.Printf("%-20s %6.2f%%\n", label, allocation[label])
+		}
+		return nil
+	}
+
+	s, err := g.Summary(*date)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case "text":
+	default:
+		return fmt.Errorf("format de sortie inconnu: %s", *format)
+	}
+
+	fmt.Printf("=== VUE CONSOLIDÉE AU %s ===\n", portfolio.FormatDate(s.Date))
+	for _, a := range s.Accounts {
+		fmt.Printf("%-20s %12.2f %s  investi %12.2f  %6.2f%%", a.Name, a.Value, s.BaseCurrency, a.Invested, a.Weight)
+		if a.XIRR != nil {
+			fmt.Printf("  TRI %+.2f%%", *a.XIRR)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%-20s %12.2f %s  investi %12.2f", "Total", s.TotalValue, s.BaseCurrency, s.TotalInvested)
+	if s.XIRR != nil {
+		fmt.Printf("  TRI %+.2f%%", *s.XIRR)
+	}
+	fmt.Println()
+	return nil
+}
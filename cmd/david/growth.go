@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runGrowth(args []string) error {
+	fs, file := newFlagSet("growth")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ)")
+	to := fs.String("to", portfolio.FormatDate(portfolio.Today()), "fin de la période (AAAA-MM-JJ)")
+	step := fs.String("step", "", "découpe la période selon ce pas (daily, weekly, monthly, quarterly, yearly)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return fmt.Errorf("--from est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	amount := report.AmountFormatter(p).Format
+
+	if *step != "" {
+		series, err := p.GrowthSeries(*from, *to, portfolio.SeriesStep(*step))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-23s %15s %15s %15s %15s %15s\n", "Période", "Début", "Versé (net)", "Croissance", "Distribué", "Fin")
+		for _, d := range series {
+			t := d.Total
+			fmt.Printf("%s → %s %15s %15s %15s %15s %15s\n", portfolio.FormatDate(d.From), portfolio.FormatDate(d.To),
+				amount(t.Start), amount(t.NetContributed()), amount(t.Growth), amount(t.Distributed), amount(t.End))
+		}
+		return nil
+	}
+
+	d, err := p.DecomposeGrowth(*from, *to)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("=== ÉPARGNE ET CROISSANCE DU %s AU %s ===\n\n", portfolio.FormatDate(d.From), portfolio.FormatDate(d.To))
+	for _, line := range d.Investments {
+		printGrowthLine(line, amount)
+	}
+	fmt.Println()
+	printGrowthLine(d.Total, amount)
+	return nil
+}
+
+func printGrowthLine(line portfolio.GrowthLine, amount func(float64) string) {
+	fmt.Printf("%s: %s → %s (%s)\n", line.Name, amount(line.Start), amount(line.End), amount(line.Change()))
+	fmt.Printf("  Argent versé: %s (apports %s, retraits %s)\n", amount(line.NetContributed()), amount(line.Contributed), amount(line.Withdrawn))
+	fmt.Printf("  Croissance:   %s", amount(line.Growth))
+	if line.Distributed != 0 {
+		fmt.Printf(" dont distributions versées %s", amount(line.Distributed))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// grpcService est le nom complet du service dans les chemins gRPC
+const grpcService = "/david.v1.Portfolio/"
+
+// Codes de statut gRPC utilisés par l'API
+const (
+	grpcOK                 = 0
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcNotFound           = 5
+	grpcAlreadyExists      = 6
+	grpcPermissionDenied   = 7
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnauthenticated    = 16
+)
+
+// Types de codage protobuf
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoEncoder construit un message protobuf. Les valeurs nulles ne sont pas écrites,
+// comme le prévoit proto3.
+type protoEncoder struct {
+	buf []byte
+}
+
+func (e *protoEncoder) tag(field, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wire))
+}
+
+func (e *protoEncoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, protoBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *protoEncoder) double(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, protoFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+// message écrit un sous-message, même vide (élément d'un champ répété)
+func (e *protoEncoder) message(field int, m []byte) {
+	e.tag(field, protoBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(m)))
+	e.buf = append(e.buf, m...)
+}
+
+// protoField est un champ lu dans un message protobuf
+type protoField struct {
+	number int
+	wire   int
+	num    uint64 // Valeur des types varint, fixed64 et fixed32
+	bytes  []byte // Valeur du type length-delimited
+}
+
+func (f protoField) string() string { return string(f.bytes) }
+
+func (f protoField) double() float64 { return math.Float64frombits(f.num) }
+
+func (f protoField) is(wire int) bool { return f.wire == wire }
+
+// decodeProto parcourt les champs d'un message protobuf
+func decodeProto(data []byte, fn func(protoField) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("message protobuf tronqué")
+		}
+		data = data[n:]
+		f := protoField{number: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case protoVarint:
+			if f.num, n = binary.Uvarint(data); n <= 0 {
+				return fmt.Errorf("varint protobuf invalide")
+			}
+			data = data[n:]
+		case protoFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("message protobuf tronqué")
+			}
+			f.num, data = binary.LittleEndian.Uint64(data), data[8:]
+		case protoFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("message protobuf tronqué")
+			}
+			f.num, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case protoBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return fmt.Errorf("message protobuf tronqué")
+			}
+			f.bytes, data = data[n:n+int(size)], data[n+int(size):]
+		default:
+			return fmt.Errorf("type protobuf %d non pris en charge", f.wire)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeInvestmentRequest lit un AddInvestmentRequest
+func decodeInvestmentRequest(data []byte) (investmentRequest, error) {
+	var req investmentRequest
+	err := decodeProto(data, func(f protoField) error {
+		switch {
+		case f.number == 1 && f.is(protoBytes):
+			req.Name = f.string()
+		case f.number == 2 && f.is(protoFixed64):
+			req.Amount = f.double()
+		case f.number == 3 && f.is(protoFixed64):
+			req.Quantity = f.double()
+		case f.number == 4 && f.is(protoFixed64):
+			req.UnitPrice = f.double()
+		case f.number == 5 && f.is(protoFixed64):
+			req.ReferenceRate = f.double()
+		case f.number == 6 && f.is(protoBytes):
+			req.InvestmentDate = f.string()
+		case f.number == 7 && f.is(protoBytes):
+			req.Currency = portfolio.Currency(f.string())
+		}
+		return nil
+	})
+	return req, err
+}
+
+// encodeInvestment écrit un message Investment
+func encodeInvestment(inv *portfolio.Investment) []byte {
+	var e protoEncoder
+	e.string(1, inv.Name)
+	e.string(2, string(inv.EffectiveCurrency()))
+	e.double(3, inv.AmountInvested.Float64())
+	e.double(4, inv.ReferenceRate)
+	e.string(5, portfolio.FormatDate(inv.InvestmentDate.Time))
+	for _, nav := range inv.NAVHistory {
+		var n protoEncoder
+		n.string(1, portfolio.FormatDate(nav.Date.Time))
+		n.double(2, nav.Value.Float64())
+		e.message(6, n.buf)
+	}
+	return e.buf
+}
+
+// encodeValuation écrit un message Valuation, investissements triés par nom
+func encodeValuation(date string, currency portfolio.Currency, total float64, values map[string]float64) []byte {
+	var e protoEncoder
+	e.string(1, date)
+	e.string(2, string(currency))
+	e.double(3, total)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var v protoEncoder
+		v.string(1, name)
+		v.double(2, values[name])
+		e.message(4, v.buf)
+	}
+	return e.buf
+}
+
+// grpcError est une erreur accompagnée de son code de statut gRPC
+type grpcError struct {
+	code int
+	err  error
+}
+
+func (e *grpcError) Error() string { return e.err.Error() }
+
+func (e *grpcError) Unwrap() error { return e.err }
+
+// grpcCodeForError traduit les erreurs sentinelles en codes de statut gRPC
+func grpcCodeForError(err error) int {
+	var ge *grpcError
+	switch {
+	case errors.As(err, &ge):
+		return ge.code
+	case errors.Is(err, portfolio.ErrInvestmentExists), errors.Is(err, portfolio.ErrDuplicateNAV), errors.Is(err, portfolio.ErrAlreadyExists):
+		return grpcAlreadyExists
+	case errors.Is(err, portfolio.ErrUnauthenticated):
+		return grpcUnauthenticated
+	case errors.Is(err, portfolio.ErrForbidden):
+		return grpcPermissionDenied
+	case errors.Is(err, portfolio.ErrInvestmentNotFound), errors.Is(err, portfolio.ErrNAVNotFound), errors.Is(err, portfolio.ErrNoNAV),
+		errors.Is(err, portfolio.ErrNotFound):
+		return grpcNotFound
+	case errors.Is(err, portfolio.ErrInvalidAmount), errors.Is(err, portfolio.ErrInvalidDate), errors.Is(err, portfolio.ErrHorizonTooLong), errors.As(err, new(*portfolio.ValidationError)):
+		return grpcInvalidArgument
+	case errors.Is(err, portfolio.ErrInsufficientHistory), errors.Is(err, portfolio.ErrRateNotFound), errors.Is(err, portfolio.ErrPeriodTooShort):
+		return grpcFailedPrecondition
+	default:
+		return grpcUnknown
+	}
+}
+
+// grpcHandler sert les appels gRPC du service Portfolio
+func (s *server) grpcHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			http.Error(w, "requête gRPC attendue", http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.WriteHeader(http.StatusOK)
+
+		err := s.serveGRPC(w, r)
+		code, message := grpcOK, ""
+		if err != nil {
+			code, message = grpcCodeForError(err), err.Error()
+		}
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+		if message != "" {
+			w.Header().Set(http.TrailerPrefix+"Grpc-Message", grpcPercentEncode(message))
+		}
+	})
+}
+
+// serveGRPC lit la requête de la méthode appelée et écrit sa ou ses réponses
+func (s *server) serveGRPC(w http.ResponseWriter, r *http.Request) error {
+	method, found := strings.CutPrefix(r.URL.Path, grpcService)
+	if !found {
+		return &grpcError{grpcUnimplemented, fmt.Errorf("service inconnu: %s", r.URL.Path)}
+	}
+	if s.tokens.Enabled() {
+		// Le jeton est transmis dans la métadonnée authorization, un en-tête HTTP/2
+		scope := portfolio.ScopeWrite
+		if method == "Value" || method == "Project" {
+			scope = portfolio.ScopeRead
+		}
+		token, err := s.tokens.Authorize(r.Header.Get("Authorization"), scope)
+		if err != nil {
+			return err
+		}
+		r = r.WithContext(context.WithValue(r.Context(), tokenKey{}, token))
+	}
+	req, err := readGRPCMessage(r.Body)
+	if err != nil {
+		return &grpcError{grpcInvalidArgument, err}
+	}
+
+	switch method {
+	case "AddInvestment":
+		in, err := decodeInvestmentRequest(req)
+		if err != nil {
+			return &grpcError{grpcInvalidArgument, err}
+		}
+		inv, err := s.grpcAddInvestment(requestActor(r.Context()), in)
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, encodeInvestment(inv))
+
+	case "AddNAV":
+		var name, date string
+		var value float64
+		err := decodeProto(req, func(f protoField) error {
+			switch {
+			case f.number == 1 && f.is(protoBytes):
+				name = f.string()
+			case f.number == 2 && f.is(protoBytes):
+				date = f.string()
+			case f.number == 3 && f.is(protoFixed64):
+				value = f.double()
+			}
+			return nil
+		})
+		if err != nil {
+			return &grpcError{grpcInvalidArgument, err}
+		}
+		inv, err := s.grpcAddNAV(requestActor(r.Context()), name, date, value)
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, encodeInvestment(inv))
+
+	case "Value":
+		var date string
+		err := decodeProto(req, func(f protoField) error {
+			if f.number == 1 && f.is(protoBytes) {
+				date = f.string()
+			}
+			return nil
+		})
+		if err != nil {
+			return &grpcError{grpcInvalidArgument, err}
+		}
+		valuation, err := s.valuation(date)
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, valuation)
+
+	case "Project":
+		var dates []string
+		err := decodeProto(req, func(f protoField) error {
+			if f.number == 1 && f.is(protoBytes) {
+				dates = append(dates, f.string())
+			}
+			return nil
+		})
+		if err != nil {
+			return &grpcError{grpcInvalidArgument, err}
+		}
+		for _, date := range dates {
+			if date == "" {
+				return &grpcError{grpcInvalidArgument, fmt.Errorf("date de projection vide: %w", portfolio.ErrInvalidDate)}
+			}
+			valuation, err := s.valuation(date)
+			if err != nil {
+				return err
+			}
+			if err := writeGRPCMessage(w, valuation); err != nil {
+				return err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		return nil
+
+	default:
+		return &grpcError{grpcUnimplemented, fmt.Errorf("méthode inconnue: %s", method)}
+	}
+}
+
+// grpcAddInvestment ajoute un investissement comme POST /investments et le persiste au
+// nom de actor
+func (s *server) grpcAddInvestment(actor portfolio.AuditActor, req investmentRequest) (*portfolio.Investment, error) {
+	if req.Name == "" || req.InvestmentDate == "" {
+		return nil, &grpcError{grpcInvalidArgument, fmt.Errorf("name et investment_date sont obligatoires")}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.addInvestment(req); err != nil {
+		return nil, err
+	}
+	if err := s.persist(actor); err != nil {
+		return nil, &grpcError{grpcInternal, err}
+	}
+	return s.portfolio.Investment(req.Name)
+}
+
+// grpcAddNAV enregistre une NAV comme POST /investments/{name}/navs et la persiste au nom
+// de actor
+func (s *server) grpcAddNAV(actor portfolio.AuditActor, name, date string, value float64) (*portfolio.Investment, error) {
+	if date == "" {
+		return nil, &grpcError{grpcInvalidArgument, fmt.Errorf("date est obligatoire")}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.portfolio.AddNAV(name, date, value); err != nil {
+		return nil, err
+	}
+	if err := s.persist(actor); err != nil {
+		return nil, &grpcError{grpcInternal, err}
+	}
+	return s.portfolio.Investment(name)
+}
+
+// valuation encode la valeur du portefeuille projetée à date, ou aux dernières NAV si
+// date est vide
+func (s *server) valuation(date string) ([]byte, error) {
+	if date != "" {
+		values, total, err := s.portfolio.GetPortfolioValue(date)
+		if err != nil {
+			return nil, err
+		}
+		return encodeValuation(date, s.portfolio.AmountFormatter().Currency, total, values), nil
+	}
+
+	summary, err := s.portfolio.Summary()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64)
+	var latest time.Time
+	for _, line := range summary.Investments {
+		if line.Closed {
+			continue
+		}
+		values[line.Name] = line.Value
+		if line.LatestNAV != nil && line.LatestNAV.Date.After(latest) {
+			latest = line.LatestNAV.Date.Time
+		}
+	}
+	if !latest.IsZero() {
+		date = portfolio.FormatDate(latest)
+	}
+	return encodeValuation(date, summary.BaseCurrency, summary.TotalValue, values), nil
+}
+
+// readGRPCMessage lit un message gRPC : indicateur de compression, longueur sur 4
+// octets gros-boutiste, puis le message protobuf
+func readGRPCMessage(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("message gRPC tronqué: %w", err)
+	}
+	if prefix[0] != 0 {
+		return nil, fmt.Errorf("messages gRPC compressés non pris en charge")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > 4<<20 {
+		return nil, fmt.Errorf("message gRPC trop volumineux (%d octets)", size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("message gRPC tronqué: %w", err)
+	}
+	return msg, nil
+}
+
+// writeGRPCMessage écrit un message gRPC non compressé
+func writeGRPCMessage(w io.Writer, msg []byte) error {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+// grpcPercentEncode encode grpc-message : octets hors ASCII imprimable et '%' en %XX
+func grpcPercentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// grpcServer crée le serveur de l'API gRPC, en HTTP/2 sans TLS (h2c) sur addr
+func (s *server) grpcServer(addr string) *http.Server {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.grpcHandler(),
+		Protocols:         &protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
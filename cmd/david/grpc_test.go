@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// grpcDescriptor compile proto/david.proto et retourne la description du service
+// Portfolio, à laquelle les messages échangés par les tests sont confrontés
+func grpcDescriptor(t *testing.T) protoreflect.ServiceDescriptor {
+	t.Helper()
+	compiler := protocompile.Compiler{Resolver: &protocompile.SourceResolver{ImportPaths: []string{filepath.Join("..", "..", "proto")}}}
+	files, err := compiler.Compile(context.Background(), "david.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := files[0].Services().ByName("Portfolio")
+	if service == nil {
+		t.Fatal("service Portfolio absent de david.proto")
+	}
+	return service
+}
+
+// protoMessage construit un message du type desc à partir de sa forme JSON
+func protoMessage(t *testing.T, desc protoreflect.MessageDescriptor, text string) *dynamicpb.Message {
+	t.Helper()
+	msg := dynamicpb.NewMessage(desc)
+	if err := protojson.Unmarshal([]byte(text), msg); err != nil {
+		t.Fatalf("%s %s: %v", desc.FullName(), text, err)
+	}
+	return msg
+}
+
+// grpcResult est la réponse d'un appel gRPC : messages reçus et statut des trailers
+type grpcResult struct {
+	messages [][]byte
+	status   int
+	message  string // grpc-message décodé
+}
+
+// grpcCall appelle method en HTTP/2 sans TLS, avec le jeton token s'il n'est pas vide
+func grpcCall(t *testing.T, addr, method, token string, req proto.Message) grpcResult {
+	t.Helper()
+	body, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := make([]byte, 5, 5+len(body))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+
+	httpReq, err := http.NewRequest(http.MethodPost, "http://"+addr+grpcService+method, bytes.NewReader(append(frame, body...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc+proto")
+	httpReq.Header.Set("TE", "trailers")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/grpc" {
+		t.Fatalf("%s: réponse %s %s, HTTP/2 200 application/grpc attendu", method, resp.Proto, resp.Status)
+	}
+
+	var result grpcResult
+	for {
+		msg, err := readGRPCMessage(resp.Body)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if msg == nil {
+			break
+		}
+		result.messages = append(result.messages, msg)
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if result.status, err = strconv.Atoi(resp.Trailer.Get("Grpc-Status")); err != nil {
+		t.Fatalf("%s: trailer grpc-status %q: %v", method, resp.Trailer.Get("Grpc-Status"), err)
+	}
+	if result.message, err = url.PathUnescape(resp.Trailer.Get("Grpc-Message")); err != nil {
+		t.Fatalf("%s: trailer grpc-message %q: %v", method, resp.Trailer.Get("Grpc-Message"), err)
+	}
+	return result
+}
+
+// grpcTestServer démarre l'API gRPC de s et retourne son adresse
+func grpcTestServer(t *testing.T, s *server) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := s.grpcServer(ln.Addr().String())
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+func TestGRPCRoundTrip(t *testing.T) {
+	service := grpcDescriptor(t)
+	tests := []struct {
+		name    string
+		method  string
+		request string
+		want    []string // Réponses attendues, sous forme JSON
+		status  int
+		message string // Début du message d'erreur attendu
+	}{
+		{
+			name: "AddInvestment", method: "AddInvestment",
+			request: `{"name":"B","amount":500,"referenceRate":3,"investmentDate":"2024-02-01"}`,
+			want:    []string{`{"name":"B","currency":"EUR","amountInvested":500,"referenceRate":3,"investmentDate":"2024-02-01"}`},
+		},
+		{
+			name: "AddInvestment par quantité", method: "AddInvestment",
+			request: `{"name":"B","quantity":10,"unitPrice":12.5,"referenceRate":3,"investmentDate":"2024-02-01","currency":"EUR"}`,
+			want:    []string{`{"name":"B","currency":"EUR","amountInvested":125,"referenceRate":3,"investmentDate":"2024-02-01"}`},
+		},
+		{
+			name: "AddInvestment existant", method: "AddInvestment",
+			request: `{"name":"A","amount":500,"investmentDate":"2024-02-01"}`,
+			status:  grpcAlreadyExists, message: "l'investissement 'A' existe déjà",
+		},
+		{
+			name: "AddInvestment incomplet", method: "AddInvestment",
+			request: `{"name":"B"}`,
+			status:  grpcInvalidArgument, message: "name et investment_date sont obligatoires",
+		},
+		{
+			name: "AddNAV", method: "AddNAV",
+			request: `{"name":"A","date":"2024-09-01","value":1080.5}`,
+			want: []string{`{"name":"A","currency":"EUR","amountInvested":1000,"referenceRate":5,"investmentDate":"2024-01-01",
+				"navHistory":[{"date":"2024-06-01","value":1050},{"date":"2024-09-01","value":1080.5}]}`},
+		},
+		{
+			name: "AddNAV investissement inconnu", method: "AddNAV",
+			request: `{"name":"X","date":"2024-09-01","value":10}`,
+			status:  grpcNotFound, message: "l'investissement 'X' n'existe pas",
+		},
+		{
+			name: "AddNAV sans date", method: "AddNAV",
+			request: `{"name":"A","value":10}`,
+			status:  grpcInvalidArgument, message: "date est obligatoire",
+		},
+		{
+			name: "Value", method: "Value",
+			request: `{}`,
+			want:    []string{`{"date":"2024-06-01","currency":"EUR","total":1050,"investments":[{"name":"A","value":1050}]}`},
+		},
+		{
+			name: "Project sans date", method: "Project",
+			request: `{"dates":[""]}`,
+			status:  grpcInvalidArgument, message: "date de projection vide",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := portfolio.NewPortfolio()
+			if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddNAV("A", "2024-06-01", 1050); err != nil {
+				t.Fatal(err)
+			}
+			addr := grpcTestServer(t, newServer(p, filepath.Join(t.TempDir(), "portfolio.json")))
+
+			method := service.Methods().ByName(protoreflect.Name(tt.method))
+			got := grpcCall(t, addr, tt.method, "", protoMessage(t, method.Input(), tt.request))
+			if got.status != tt.status || !strings.HasPrefix(got.message, tt.message) {
+				t.Fatalf("statut %d %q, %d %q attendu", got.status, got.message, tt.status, tt.message)
+			}
+			if len(got.messages) != len(tt.want) {
+				t.Fatalf("%d réponses, %d attendues", len(got.messages), len(tt.want))
+			}
+			for i, data := range got.messages {
+				msg := dynamicpb.NewMessage(method.Output())
+				if err := proto.Unmarshal(data, msg); err != nil {
+					t.Fatalf("réponse %d: %v", i, err)
+				}
+				// proto.Equal compare aussi les champs inconnus : un numéro ou un type de
+				// champ différent de david.proto est donc détecté
+				if want := protoMessage(t, method.Output(), tt.want[i]); !proto.Equal(msg, want) {
+					t.Errorf("réponse %d:\n%v\nattendu:\n%v", i, msg, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCProjectStream(t *testing.T) {
+	service := grpcDescriptor(t)
+	p := portfolio.NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-06-01", 1050); err != nil {
+		t.Fatal(err)
+	}
+	addr := grpcTestServer(t, newServer(p, filepath.Join(t.TempDir(), "portfolio.json")))
+
+	method := service.Methods().ByName("Project")
+	if !method.IsStreamingServer() {
+		t.Fatal("Project devrait être un flux serveur dans david.proto")
+	}
+	dates := []string{"2025-01-01", "2026-01-01", "2027-01-01"}
+	got := grpcCall(t, addr, "Project", "", protoMessage(t, method.Input(), `{"dates":["`+strings.Join(dates, `","`)+`"]}`))
+	if got.status != grpcOK {
+		t.Fatalf("statut %d %q", got.status, got.message)
+	}
+	if len(got.messages) != len(dates) {
+		t.Fatalf("%d réponses, une par date attendue", len(got.messages))
+	}
+	fields := method.Output().Fields()
+	for i, data := range got.messages {
+		msg := dynamicpb.NewMessage(method.Output())
+		if err := proto.Unmarshal(data, msg); err != nil {
+			t.Fatal(err)
+		}
+		if len(msg.GetUnknown()) > 0 {
+			t.Errorf("réponse %d: champs absents de david.proto", i)
+		}
+		_, total, err := p.GetPortfolioValue(dates[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if date := msg.Get(fields.ByName("date")).String(); date != dates[i] {
+			t.Errorf("réponse %d au %s, %s attendu", i, date, dates[i])
+		}
+		if got := msg.Get(fields.ByName("total")).Float(); got != total {
+			t.Errorf("réponse %d: total %.2f, %.2f attendu", i, got, total)
+		}
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	service := grpcDescriptor(t)
+	s, read, write := tokenServer(t)
+	addr := grpcTestServer(t, s)
+	tests := []struct {
+		name    string
+		method  string
+		token   string
+		request string
+		status  int
+	}{
+		{name: "sans jeton", method: "Value", request: `{}`, status: grpcUnauthenticated},
+		{name: "jeton inconnu", method: "Value", token: "inconnu", request: `{}`, status: grpcUnauthenticated},
+		{name: "lecture", method: "Value", token: read, request: `{}`, status: grpcOK},
+		{name: "écriture avec un jeton read", method: "AddNAV", token: read, request: `{"name":"A","date":"2024-02-01","value":1010}`, status: grpcPermissionDenied},
+		{name: "écriture", method: "AddNAV", token: write, request: `{"name":"A","date":"2024-02-01","value":1010}`, status: grpcOK},
+		{name: "méthode inconnue", method: "Delete", token: write, status: grpcUnimplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req proto.Message = dynamicpb.NewMessage(service.Methods().ByName("Value").Input())
+			if method := service.Methods().ByName(protoreflect.Name(tt.method)); method != nil {
+				req = protoMessage(t, method.Input(), tt.request)
+			}
+			if got := grpcCall(t, addr, tt.method, tt.token, req); got.status != tt.status {
+				t.Errorf("statut %d %q, %d attendu", got.status, got.message, tt.status)
+			}
+		})
+	}
+}
+
+func TestGRPCPercentEncode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "date invalide", want: "date invalide"},
+		{in: "l'investissement 'A' existe déjà", want: "l'investissement 'A' existe d%C3%A9j%C3%A0"},
+		{in: "100% investi", want: "100%25 investi"},
+		{in: "ligne 1\nligne 2", want: "ligne 1%0Aligne 2"},
+	}
+	for _, tt := range tests {
+		got := grpcPercentEncode(tt.in)
+		if got != tt.want {
+			t.Errorf("grpcPercentEncode(%q) = %q, %q attendu", tt.in, got, tt.want)
+		}
+		if decoded, err := url.PathUnescape(got); err != nil || decoded != tt.in {
+			t.Errorf("décodage de %q: %q, %v", got, decoded, err)
+		}
+	}
+}
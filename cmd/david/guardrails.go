@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// printProjectionWarnings affiche sur w les avertissements des projections
+func printProjectionWarnings(w io.Writer, p *portfolio.Portfolio) {
+	for _, warning := range p.ProjectionWarnings() {
+		fmt.Fprintf(w, "Attention: %s\n", warning)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetLocale(args []string) error {
+	fs, file := newFlagSet("set-locale")
+	locale := fs.String("locale", string(portfolio.DefaultLocale), "langue des résumés et rapports (fr, en)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetLocale(portfolio.Locale(*locale)); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Langue du portefeuille: %s\n", *locale)
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetIdentifiers(args []string) error {
+	fs, file := newFlagSet("set-identifiers")
+	name := fs.String("name", "", "nom de l'investissement")
+	isin := fs.String("isin", "", "code ISIN (vide pour le retirer)")
+	ticker := fs.String("ticker", "", "symbole de cotation (vide pour le retirer)")
+	mic := fs.String("mic", "", "place de cotation du ticker, code ISO 10383 (ex. XPAR)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetIdentifiers(*name, portfolio.InvestmentIdentifiers{ISIN: *isin, Ticker: *ticker, MIC: *mic}); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runLookup(args []string) error {
+	fs, file := newFlagSet("lookup")
+	key := fs.String("id", "", "nom, identifiant interne, ISIN, ticker (TICKER ou TICKER@MIC) ou identifiant de cotation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *key == "" {
+		return fmt.Errorf("--id est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Lookup(*key)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s (identifiant %s)\n", inv.Name, inv.ID)
+	if inv.ISIN != "" {
+		fmt.Printf("  ISIN:    %s\n", inv.ISIN)
+	}
+	if inv.Ticker != "" {
+		ticker := inv.Ticker
+		if inv.MIC != "" {
+			ticker += " (" + inv.MIC + ")"
+		}
+		fmt.Printf("  Ticker:  %s\n", ticker)
+	}
+	if inv.Identifier != "" {
+		fmt.Printf("  Cotation: %s\n", inv.Identifier)
+	}
+	return nil
+}
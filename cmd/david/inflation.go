@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+func runInflation(args []string) error {
+	fs, file := newFlagSet("inflation")
+	rate := fs.Float64("rate", math.NaN(), "taux d'inflation annuel constant (%)")
+	date := fs.String("date", "", "date d'une valeur de l'indice des prix (avec --value)")
+	value := fs.Float64("value", 0, "valeur de l'indice des prix")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if math.IsNaN(*rate) && *date == "" {
+		return fmt.Errorf("--rate ou --date et --value sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if !math.IsNaN(*rate) {
+		if err := p.SetInflationRate(*rate); err != nil {
+			return err
+		}
+	}
+	if *date != "" {
+		if err := p.AddInflationIndex(*date, *value); err != nil {
+			return err
+		}
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runRealProjection(args []string) error {
+	fs, file := newFlagSet("real")
+	date := fs.String("date", "", "date de projection (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	values, total, err := p.RealPortfolioValue(*date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== PROJECTION EN MONNAIE CONSTANTE AU %s ===\n\n", *date)
+	for _, name := range p.InvestmentNames() {
+		value, open := values[name]
+		if !open {
+			continue
+		}
+		fmt.Printf("%s: %.2f€", name, value)
+		if rate, err := p.RealPerformanceRate(name); err == nil {
+			fmt.Printf(" (performance réelle: %.2f%%)", rate)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("\nValeur totale réelle du portefeuille: %.2f€\n", total)
+	return nil
+}
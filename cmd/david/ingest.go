@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runIngest(args []string) error {
+	fs, file := newFlagSet("ingest")
+	input := fs.String("input", "-", "fichier à lire (- pour l'entrée standard)")
+	format := fs.String("format", string(portfolio.IngestCSV), "format des enregistrements (csv, ndjson)")
+	name := fs.String("name", "", "investissement des enregistrements qui n'en précisent pas")
+	batch := fs.Int("batch", portfolio.DefaultIngestBatch, "NAV insérées à la fois")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	r := io.Reader(os.Stdin)
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := p.IngestNAVs(ctx, r, portfolio.IngestOptions{
+		Format:     portfolio.IngestFormat(*format),
+		Investment: *name,
+		BatchSize:  *batch,
+		Progress: func(pr portfolio.IngestProgress) {
+			fmt.Fprintf(os.Stderr, "\r%d enregistrements lus, %d NAV insérées, %d rejetés", pr.Records, pr.Ingested, pr.Rejected)
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	for _, m := range report.Merges {
+		fmt.Fprintln(os.Stderr, m)
+	}
+	if hidden := report.Merged - len(report.Merges); hidden > 0 {
+		fmt.Fprintf(os.Stderr, "... et %d autre(s) fusion(s)\n", hidden)
+	}
+	for _, e := range report.Errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+	if hidden := report.Rejected - len(report.Errors); hidden > 0 {
+		fmt.Fprintf(os.Stderr, "... et %d autre(s) rejet(s)\n", hidden)
+	}
+	if err != nil {
+		return err
+	}
+	if report.Ingested > 0 {
+		if err := p.SaveJSON(*file); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%d NAV insérées, %d date(s) dédoublonnée(s), %d enregistrement(s) rejeté(s)\n", report.Ingested, report.Merged, report.Rejected)
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runNAVAt(args []string) error {
+	fs, file := newFlagSet("nav-at")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de valorisation (AAAA-MM-JJ)")
+	mode := fs.String("mode", string(portfolio.InterpolateLinear), "mode d'interpolation (last-known, linear, strict)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+	nav, err := inv.NAVAt(*date, portfolio.InterpolationMode(*mode))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(nav)
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func runUndo(args []string) error {
+	fs, file := newFlagSet("undo")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	entry, err := p.Undo()
+	if err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Annulé: %s %s %s\n", entry.Op, entry.Investment, entry.Detail)
+	return nil
+}
+
+func runRedo(args []string) error {
+	fs, file := newFlagSet("redo")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	entry, err := p.Redo()
+	if err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Rétabli: %s %s %s\n", entry.Op, entry.Investment, entry.Detail)
+	return nil
+}
+
+func runJournal(args []string) error {
+	fs, file := newFlagSet("journal")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	for _, entry := range p.JournalEntries() {
+		status := ""
+		switch {
+		case entry.Discarded:
+			status = " [annulée, abandonnée]"
+		case entry.UndoneAt != nil:
+			status = fmt.Sprintf(" [annulée le %s]", entry.UndoneAt.Local().Format(time.DateTime))
+		}
+		fmt.Printf("%s  %-15s %-20s %s%s\n", entry.Time.Local().Format(time.DateTime), entry.Op, entry.Investment, entry.Detail, status)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddTransaction(args []string) error {
+	fs, file := newFlagSet("add-transaction")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de la transaction (AAAA-MM-JJ)")
+	txType := fs.String("type", string(portfolio.Buy), "type de transaction (buy ou sell)")
+	units := fs.Float64("units", 0, "nombre de parts")
+	price := fs.Float64("price", 0, "prix unitaire")
+	fees := fs.Float64("fees", 0, "frais de transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddTransaction(*name, *date, portfolio.TransactionType(*txType), *units, *price, *fees); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
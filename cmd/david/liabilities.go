@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runAddLiability(args []string) error {
+	fs, file := newFlagSet("add-liability")
+	name := fs.String("name", "", "nom de l'emprunt")
+	kind := fs.String("kind", string(portfolio.LiabilityMortgage), "type d'emprunt (mortgage, loan)")
+	principal := fs.Float64("principal", 0, "capital emprunté")
+	rate := fs.Float64("rate", 0, "taux nominal annuel (%)")
+	start := fs.String("start", "", "date de déblocage (AAAA-MM-JJ)")
+	months := fs.Int("months", 0, "durée en mois")
+	currency := fs.String("currency", "", "devise de l'emprunt (EUR par défaut)")
+	remove := fs.Bool("delete", false, "supprime l'emprunt --name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *remove {
+		err = p.RemoveLiability(*name)
+	} else {
+		var t time.Time
+		if t, err = portfolio.ParseDate(*start); err != nil {
+			return err
+		}
+		err = p.AddLiability(portfolio.Liability{Name: *name, Kind: portfolio.LiabilityKind(*kind), Principal: portfolio.NewMoney(*principal),
+			AnnualRate: *rate, Start: portfolio.Date{Time: t}, Months: *months, Currency: portfolio.Currency(*currency)})
+	}
+	if err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runAmortization(args []string) error {
+	fs, file := newFlagSet("amortization")
+	name := fs.String("name", "", "nom de l'emprunt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	l, err := p.Liability(*name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-10s %12s %12s %12s %14s\n", "Échéance", "Mensualité", "Intérêts", "Capital", "Restant dû")
+	var interest portfolio.Money
+	for _, row := range l.Schedule() {
+		interest += row.Interest
+		fmt.Printf("%-10s %12s %12s %12s %14s\n", portfolio.FormatDate(row.Date), row.Payment, row.Interest, row.Principal, row.Balance)
+	}
+	fmt.Printf("Coût total des intérêts: %s\n", interest)
+	return nil
+}
+
+func runNetWorth(args []string) error {
+	fs, file := newFlagSet("net-worth")
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date du patrimoine (AAAA-MM-JJ), début de la série avec --to")
+	to := fs.String("to", "", "fin de la série de projection (AAAA-MM-JJ)")
+	step := fs.String("step", string(portfolio.StepYearly), "pas de la série (monthly, quarterly, yearly…)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var series []portfolio.NetWorth
+	if *to == "" {
+		point, err := p.NetWorth(*date)
+		if err != nil {
+			return err
+		}
+		series = []portfolio.NetWorth{point}
+	} else if series, err = p.NetWorthSeries(*date, *to, portfolio.SeriesStep(*step)); err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	if names := p.LiabilityNames(); len(names) > 0 {
+		fmt.Printf("Emprunts: %s\n", strings.Join(names, ", "))
+	}
+	for _, point := range series {
+		fmt.Printf("%s  actifs %s  dettes %s  net %s\n", portfolio.FormatDate(point.Date), amount(point.Assets), amount(point.Liabilities), amount(point.Net))
+	}
+	return nil
+}
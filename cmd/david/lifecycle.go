@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+)
+
+func runRemoveInvestment(args []string) error {
+	fs, file := newFlagSet("remove-investment")
+	name := fs.String("name", "", "nom de l'investissement")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.RemoveInvestment(*name); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runRenameInvestment(args []string) error {
+	fs, file := newFlagSet("rename-investment")
+	name := fs.String("name", "", "nom actuel de l'investissement")
+	newName := fs.String("new-name", "", "nouveau nom")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *newName == "" {
+		return fmt.Errorf("--name et --new-name sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.RenameInvestment(*name, *newName); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runCloseInvestment(args []string) error {
+	fs, file := newFlagSet("close-investment")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de clôture (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.CloseInvestment(*name, *date); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
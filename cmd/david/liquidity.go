@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runSetLiquidity(args []string) error {
+	fs, file := newFlagSet("set-liquidity")
+	name := fs.String("name", "", "nom de l'investissement")
+	tier := fs.String("tier", "", "niveau de liquidité : daily, monthly, quarterly, locked (vide pour le supprimer)")
+	until := fs.String("until", "", "fin du blocage pour le niveau locked (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetLiquidity(*name, portfolio.LiquidityTier(*tier), *until); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runLiquidity(args []string) error {
+	fs, file := newFlagSet("liquidity")
+	date := fs.String("date", portfolio.FormatDate(portfolio.Today()), "date de la situation (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	asOf, err := portfolio.ParseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	r, err := p.LiquidityReport(asOf)
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	share := func(v float64) float64 {
+		if r.Total == 0 {
+			return 0
+		}
+		return v / r.Total * 100
+	}
+	fmt.Printf("=== LIQUIDITÉ AU %s ===\n\n", *date)
+	fmt.Printf("Valeur du portefeuille: %s\n\n", amount(r.Total))
+	fmt.Println("Par niveau:")
+	for _, tier := range []portfolio.LiquidityTier{portfolio.LiquidityDaily, portfolio.LiquidityMonthly, portfolio.LiquidityQuarterly, portfolio.LiquidityLocked} {
+		if v, ok := r.ByTier[tier]; ok {
+			fmt.Printf("  %-10s %s (%.1f%%)\n", tier, amount(v), share(v))
+		}
+	}
+	if len(r.Missing) > 0 {
+		fmt.Printf("  %-10s %s (%.1f%%)\n", portfolio.UntaggedLabel, amount(r.Unclassified), share(r.Unclassified))
+	}
+
+	fmt.Println("\nDisponible sous:")
+	for _, b := range r.Horizons {
+		fmt.Printf("  %-10s (%s) %s (%.1f%%)\n", b.Horizon, portfolio.FormatDate(b.Date), amount(b.Amount), b.Share)
+	}
+	if len(r.Locked) > 0 {
+		fmt.Println("\nBloqués:")
+		for _, l := range r.Locked {
+			fmt.Printf("  %s jusqu'au %s: %s\n", l.Investment, portfolio.FormatDate(l.Until), amount(l.Value))
+		}
+	}
+	if len(r.Missing) > 0 {
+		fmt.Printf("\nSans niveau de liquidité (voir set-liquidity): %s\n", strings.Join(r.Missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// envLogger configure le journal de diagnostic selon DAVID_LOG ou la clé log de la
+// configuration (debug, info, warn, error), sur la sortie d'erreur ; un niveau vide ou
+// inconnu le laisse muet
+func envLogger() {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(portfolio.EnvOrConfig("DAVID_LOG", portfolio.ActiveConfig().Log)))); err != nil {
+		return
+	}
+	portfolio.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
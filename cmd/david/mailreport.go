@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// reportSentSuffix est l'extension du fichier qui conserve la date du dernier rapport
+// envoyé, à côté du portefeuille
+const reportSentSuffix = ".report-sent"
+
+// newReportMailer prépare l'envoi mensuel du rapport du portefeuille enregistré dans
+// file, avec des projections à 1, 5 et 10 ans
+func newReportMailer(mail *portfolio.EmailNotifier, format string, day int, file string) (*report.ReportMailer, error) {
+	if mail == nil {
+		return nil, fmt.Errorf("l'envoi du rapport demande --smtp et --mail-to (ou la table [smtp] de la configuration)")
+	}
+	if format != "html" && format != "pdf" {
+		return nil, portfolio.InvalidField("format", format, "format de rapport inconnu: %s (html, pdf)", format)
+	}
+	if day < 1 || day > 28 {
+		return nil, portfolio.InvalidField("day", day, "jour d'envoi invalide: %d (1 à 28)", day)
+	}
+	m := &report.ReportMailer{Mail: *mail, Format: format, Day: day, Stamp: file + reportSentSuffix}
+	m.Options = report.ReportOptions{Step: portfolio.StepMonthly, AllocationTag: portfolio.TagAssetClass, Locale: portfolio.EnvLocale(), Metrics: portfolio.ActiveConfig().Metrics}
+	for _, years := range []int{1, 5, 10} {
+		m.Options.ProjectionDates = append(m.Options.ProjectionDates, portfolio.FormatDate(portfolio.Today().AddDate(years, 0, 0)))
+	}
+	return m, nil
+}
+
+func runMailReport(args []string) error {
+	fs, file := newFlagSet("mail-report")
+	mail := smtpFlags(fs)
+	format := fs.String("format", "html", "format du rapport (html, pdf)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	m, err := newReportMailer(mail(), *format, 1, *file)
+	if err != nil {
+		return err
+	}
+	if err := m.Send(p, portfolio.Today()); err != nil {
+		return err
+	}
+	fmt.Printf("Rapport envoyé à %s\n", strings.Join(m.Mail.To, ", "))
+	return nil
+}
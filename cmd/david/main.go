@@ -0,0 +1,22 @@
+// La commande david gère un portefeuille d'investissements depuis le terminal ;
+// « david help » liste les commandes.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func main() {
+	portfolio.SetPassphrasePrompt(readTerminalPassphrase)
+	if err := run(os.Args[1:]); err != nil {
+		locale := portfolio.EnvLocale()
+		if locale == "" {
+			locale = portfolio.DefaultLocale
+		}
+		fmt.Fprint(os.Stderr, locale.Tf("Erreur: %s\n", portfolio.LocalizeError(err, locale)))
+		os.Exit(1)
+	}
+}
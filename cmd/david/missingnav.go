@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetMissingNAVPolicy(args []string) error {
+	fs, file := newFlagSet("set-missing-nav-policy")
+	policy := fs.String("policy", "", "valorisation des investissements sans NAV (error, invested, skip)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetMissingNAVPolicy(portfolio.MissingNAVPolicy(*policy)); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runMonteCarlo(args []string) error {
+	fs, file := newFlagSet("monte-carlo")
+	date := fs.String("date", "", "date de projection (AAAA-MM-JJ)")
+	name := fs.String("name", "", "investissement à simuler (tout le portefeuille si vide)")
+	paths := fs.Int("paths", 10000, "nombre de trajectoires")
+	method := fs.String("method", string(portfolio.MonteCarloNormal), "méthode de tirage (normal, bootstrap ou block-bootstrap)")
+	block := fs.Int("block", 0, "longueur des blocs de block-bootstrap, en rendements (0 : automatique)")
+	seed := fs.Uint64("seed", 0, "graine du générateur (0 : aléatoire)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	opts := portfolio.MonteCarloOptions{Method: portfolio.MonteCarloMethod(*method), Seed: *seed, Block: *block}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var result portfolio.MonteCarloResult
+	if *name != "" {
+		inv, err := p.Investment(*name)
+		if err != nil {
+			return err
+		}
+		result, err = inv.MonteCarloProjectWithContext(ctx, *date, *paths, opts)
+		if err != nil {
+			return err
+		}
+	} else {
+		result, err = p.MonteCarloProjectContext(ctx, *date, *paths, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("=== SIMULATION MONTE-CARLO AU %s (%d trajectoires) ===\n\n", *date, result.Paths)
+	fmt.Printf("P5:  %.2f€\n", result.P5)
+	fmt.Printf("P50: %.2f€\n", result.P50)
+	fmt.Printf("P95: %.2f€\n", result.P95)
+	fmt.Printf("Moyenne: %.2f€\n", result.Mean)
+	return nil
+}
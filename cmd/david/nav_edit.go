@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+)
+
+func runUpdateNAV(args []string) error {
+	fs, file := newFlagSet("update-nav")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de la NAV à corriger (AAAA-MM-JJ)")
+	value := fs.Float64("value", 0, "nouvelle valeur")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.UpdateNAV(*name, *date, *value); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runDeleteNAV(args []string) error {
+	fs, file := newFlagSet("delete-nav")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de la NAV à supprimer (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.DeleteNAV(*name, *date); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// printHoldings affiche la composition d'un sous-portefeuille, avec un retrait par niveau
+func printHoldings(p *portfolio.Portfolio, depth int) {
+	summary, err := p.Summary()
+	if err != nil {
+		fmt.Printf("%s(erreur: %v)\n", strings.Repeat("  ", depth), err)
+		return
+	}
+	for _, line := range summary.Investments {
+		fmt.Printf("%s%-*s %12.2f %s", strings.Repeat("  ", depth), 30-2*depth, line.Name, line.Value, summary.BaseCurrency)
+		if summary.TotalValue != 0 && !line.Closed {
+			fmt.Printf("  %6.2f%%", line.Value/summary.TotalValue*100)
+		}
+		if line.PerformanceRate != nil {
+			fmt.Printf("  perf %+.2f%%/an", *line.PerformanceRate)
+		}
+		fmt.Println()
+		if inv, err := p.Investment(line.Name); err == nil && inv.Holdings != nil {
+			printHoldings(inv.Holdings, depth+1)
+		}
+	}
+}
+
+func runAddHolding(args []string) error {
+	fs, file := newFlagSet("add-holding")
+	parent := fs.String("parent", "", "investissement composé (chemin \"Mandat/Fonds\" pour un niveau plus profond)")
+	name := fs.String("name", "", "nom de la ligne")
+	amount := fs.Float64("amount", 0, "montant investi")
+	rate := fs.Float64("rate", 0, "taux de référence annuel (%)")
+	date := fs.String("date", "", "date d'investissement (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *parent == "" || *name == "" || *date == "" {
+		return fmt.Errorf("--parent, --name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddHolding(*parent, *name, *amount, *rate, *date); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runAddHoldingNAV(args []string) error {
+	fs, file := newFlagSet("add-holding-nav")
+	parent := fs.String("parent", "", "investissement composé (chemin \"Mandat/Fonds\" pour un niveau plus profond)")
+	name := fs.String("name", "", "nom de la ligne")
+	date := fs.String("date", "", "date de la NAV (AAAA-MM-JJ)")
+	value := fs.Float64("value", 0, "valeur de la NAV")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *parent == "" || *name == "" || *date == "" {
+		return fmt.Errorf("--parent, --name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddHoldingNAV(*parent, *name, *date, *value); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runHoldings(args []string) error {
+	fs, file := newFlagSet("holdings")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	printHoldings(p, 0)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddNote(args []string) error {
+	fs, file := newFlagSet("add-note")
+	name := fs.String("name", "", "nom de l'investissement")
+	text := fs.String("text", "", "texte de la note")
+	document := fs.String("document", "", "chemin ou référence d'un document (contrat, DICI)")
+	link := fs.String("url", "", "lien associé")
+	date := fs.String("date", "", "date de la note (AAAA-MM-JJ, aujourd'hui par défaut)")
+	transaction := fs.String("transaction", "", "date de la transaction annotée (AAAA-MM-JJ)")
+	txType := fs.String("type", "", "type de la transaction annotée s'il y en a plusieurs ce jour-là (buy, sell)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+	note := portfolio.Note{Text: strings.TrimSpace(*text), Document: *document, URL: *link}
+	if *date != "" {
+		t, err := portfolio.ParseDate(*date)
+		if err != nil {
+			return err
+		}
+		note.Date = portfolio.Date{Time: t}
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddNote(*name, note, *transaction, portfolio.TransactionType(*txType)); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runNotes(args []string) error {
+	fs, file := newFlagSet("notes")
+	name := fs.String("name", "", "nom de l'investissement (tous par défaut)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	names := p.InvestmentNames()
+	if *name != "" {
+		names = []string{*name}
+	}
+	l := portfolio.EnvLocale()
+	if l == "" {
+		l = p.DisplayLocale()
+	}
+	for _, n := range names {
+		inv, err := p.Investment(n)
+		if err != nil {
+			return err
+		}
+		entries := inv.NoteEntries()
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Println(n)
+		for _, e := range entries {
+			fmt.Printf("  %s  %s\n", portfolio.FormatDate(e.Date.Time), e.Label(l))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// ownerFilter est le titulaire de l'option --owner : les commandes ne voient alors que
+// ses investissements (voir ForOwner)
+var ownerFilter string
+
+func runSetOwner(args []string) error {
+	fs, file := newFlagSet("set-owner")
+	name := fs.String("name", "", "nom de l'investissement (le portefeuille entier si vide)")
+	value := fs.String("value", "", "titulaire (me, spouse, joint ou un prénom ; vide pour celui du portefeuille)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetOwner(*name, *value); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runHousehold(args []string) error {
+	fs, file := newFlagSet("household")
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date de valorisation (AAAA-MM-JJ)")
+	to := fs.String("to", "", "horizon de projection (AAAA-MM-JJ, aucune projection si vide)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	owners, err := p.Household(*date, *to)
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("=== FOYER AU %s ===\n\n", *date)
+	var value, invested, projected float64
+	for _, s := range owners {
+		fmt.Printf("%-16s %16s  investi %16s  %6.2f%%", s.Owner, amount(s.Value), amount(s.Invested), s.Weight)
+		if s.Projected != nil {
+			fmt.Printf("  au %s: %s", *to, amount(*s.Projected))
+			projected += *s.Projected
+		}
+		fmt.Println()
+		value += s.Value
+		invested += s.Invested
+	}
+	fmt.Printf("%-16s %16s  investi %16s", "Foyer", amount(value), amount(invested))
+	if *to != "" {
+		fmt.Printf("          au %s: %s", *to, amount(projected))
+	}
+	fmt.Println()
+	return nil
+}
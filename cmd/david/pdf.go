@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runPDFReport(args []string) error {
+	fs, file := newFlagSet("pdf-report")
+	output := fs.String("output", "rapport.pdf", "fichier PDF à écrire")
+	title := fs.String("title", "", "titre du rapport")
+	step := fs.String("step", string(portfolio.StepMonthly), "pas du graphique de valeur (daily, weekly, monthly, quarterly, yearly)")
+	tag := fs.String("tag", portfolio.TagAssetClass, "étiquette de la répartition")
+	var projections stringList
+	fs.Var(&projections, "project", "date de projection à afficher (AAAA-MM-JJ, répétable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	opts := report.ReportOptions{Title: *title, Step: portfolio.SeriesStep(*step), AllocationTag: *tag, ProjectionDates: projections, Locale: portfolio.EnvLocale()}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := report.RenderPDF(p, f, opts); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Rapport écrit dans %s\n", *output)
+	reportWritten(*output, "pdf")
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+)
+
+func runReturn(args []string) error {
+	fs, file := newFlagSet("return")
+	name := fs.String("name", "", "nom de l'investissement (portefeuille entier si vide)")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ, date d'investissement par défaut)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ, dernière NAV ou aujourd'hui par défaut)")
+	annualize := fs.Bool("annualize", false, "convertit le rendement en taux annuel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var r float64
+	if *name == "" {
+		r, err = p.Return(*from, *to, *annualize)
+	} else {
+		inv, lookupErr := p.Investment(*name)
+		if lookupErr != nil {
+			return lookupErr
+		}
+		r, err = inv.Return(*from, *to, *annualize)
+	}
+	if err != nil {
+		return err
+	}
+	if *annualize {
+		fmt.Printf("Rendement annualisé: %.2f%%\n", r)
+	} else {
+		fmt.Printf("Rendement sur la période: %.2f%%\n", r)
+	}
+	return nil
+}
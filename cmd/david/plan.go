@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetPlan(args []string) error {
+	fs, file := newFlagSet("set-plan")
+	name := fs.String("name", "", "nom de l'investissement")
+	amount := fs.Float64("amount", 0, "montant de chaque versement (0 pour supprimer le plan)")
+	frequency := fs.String("frequency", string(portfolio.StepMonthly), "périodicité (weekly, monthly, quarterly, yearly)")
+	start := fs.String("start", "", "date du premier versement (AAAA-MM-JJ)")
+	end := fs.String("end", "", "date de fin du plan (AAAA-MM-JJ, facultative)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetContributionPlan(*name, *amount, portfolio.SeriesStep(*frequency), *start, *end); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runPlot(args []string) error {
+	fs, file := newFlagSet("plot")
+	name := fs.String("name", "", "investissement dont tracer les NAV (tout le portefeuille si vide)")
+	output := fs.String("output", "valeur.svg", "fichier image ; le format suit l'extension (.svg, .png)")
+	format := fs.String("format", "", "format d'image (svg, png), déduit de --output si vide")
+	project := fs.String("project", "", "date jusqu'à laquelle prolonger la courbe par la projection (AAAA-MM-JJ)")
+	from := fs.String("from", "", "début de l'historique (AAAA-MM-JJ, portefeuille uniquement)")
+	to := fs.String("to", "", "fin de l'historique (AAAA-MM-JJ, portefeuille uniquement)")
+	step := fs.String("step", string(portfolio.StepMonthly), "pas de l'historique du portefeuille (daily, weekly, monthly, quarterly, yearly)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f := report.PlotFormat(*format)
+	if f == "" {
+		f = report.PlotFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), "."))
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if *name != "" {
+		inv, err := p.Investment(*name)
+		if err != nil {
+			return err
+		}
+		if err := report.PlotNAV(inv, &buf, f, *project); err != nil {
+			return err
+		}
+	} else if err := report.PlotValue(p, &buf, f, report.PlotOptions{From: *from, To: *to, Step: portfolio.SeriesStep(*step), ProjectTo: *project}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Graphique écrit dans %s\n", *output)
+	return nil
+}
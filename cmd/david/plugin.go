@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// plugins retourne les extensions de la configuration
+func plugins() []portfolio.Plugin {
+	return portfolio.ActiveConfig().Plugins
+}
+
+// describedPlugins retourne les extensions qui déclarent une capacité, avec leur
+// description ; une extension qui ne répond pas est signalée sur la sortie d'erreur
+// et écartée
+func describedPlugins(capability string) []pluginWithInfo {
+	var found []pluginWithInfo
+	for _, pl := range plugins() {
+		info, err := pl.Describe(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Attention: %v\n", err)
+			continue
+		}
+		if info.Has(capability) {
+			found = append(found, pluginWithInfo{pl, info})
+		}
+	}
+	return found
+}
+
+// pluginWithInfo associe une extension à sa description
+type pluginWithInfo struct {
+	portfolio.Plugin
+	info portfolio.PluginInfo
+}
+
+// pluginQuoteProviders retourne un fournisseur de cours par extension, indexé par son
+// nom pour QuoteRouter. Les extensions ne sont lancées que si un identifiant les désigne.
+func pluginQuoteProviders(providers map[string]portfolio.QuoteProvider) map[string]portfolio.QuoteProvider {
+	for _, pl := range plugins() {
+		providers[pl.Name] = portfolio.PluginQuoteProvider{Plugin: pl}
+	}
+	return providers
+}
+
+// pluginEntry, pluginPosition et pluginAccount sont les formes échangées d'un relevé
+type pluginEntry struct {
+	Date        string                       `json:"date"`
+	Kind        portfolio.StatementEntryKind `json:"kind"`
+	Amount      float64                      `json:"amount"`
+	Units       float64                      `json:"units,omitempty"`
+	Price       float64                      `json:"price,omitempty"`
+	Fees        float64                      `json:"fees,omitempty"`
+	Security    pluginSecurity               `json:"security"`
+	Description string                       `json:"description,omitempty"`
+}
+
+type pluginSecurity struct {
+	ID     string `json:"id,omitempty"`
+	Ticker string `json:"ticker,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+type pluginPosition struct {
+	Date     string         `json:"date"`
+	Security pluginSecurity `json:"security"`
+	Units    float64        `json:"units"`
+	Price    float64        `json:"price,omitempty"`
+	Value    float64        `json:"value,omitempty"`
+}
+
+type pluginAccount struct {
+	ID        string             `json:"id"`
+	Currency  portfolio.Currency `json:"currency,omitempty"`
+	Entries   []pluginEntry      `json:"entries"`
+	Positions []pluginPosition   `json:"positions"`
+}
+
+// statement convertit le relevé retourné par une extension
+func (a pluginAccount) statement() (portfolio.StatementAccount, error) {
+	acct := portfolio.StatementAccount{ID: a.ID, Currency: a.Currency}
+	for i, e := range a.Entries {
+		t, err := portfolio.ParseDate(e.Date)
+		if err != nil {
+			return acct, fmt.Errorf("opération %d: %w", i+1, err)
+		}
+		switch e.Kind {
+		case portfolio.StatementCash, portfolio.StatementBuy, portfolio.StatementSell, portfolio.StatementIncome, portfolio.StatementReinvest:
+		default:
+			return acct, fmt.Errorf("opération %d: %w", i+1, portfolio.InvalidField("kind", e.Kind, "opération inconnue: %s", e.Kind))
+		}
+		acct.Entries = append(acct.Entries, portfolio.StatementEntry{
+			Date: t, Kind: e.Kind, Amount: portfolio.NewMoney(e.Amount), Units: portfolio.NewQuantity(e.Units), Price: portfolio.NewMoney(e.Price),
+			Fees: portfolio.NewMoney(e.Fees), Security: portfolio.StatementSecurity(e.Security), Description: e.Description,
+		})
+	}
+	for i, pos := range a.Positions {
+		t, err := portfolio.ParseDate(pos.Date)
+		if err != nil {
+			return acct, fmt.Errorf("position %d: %w", i+1, err)
+		}
+		acct.Positions = append(acct.Positions, portfolio.StatementPosition{
+			Date: t, Security: portfolio.StatementSecurity(pos.Security), Units: portfolio.NewQuantity(pos.Units), Price: portfolio.NewMoney(pos.Price), Value: portfolio.NewMoney(pos.Value),
+		})
+	}
+	return acct, nil
+}
+
+// pluginImporter lit les relevés d'un format pris en charge par une extension
+type pluginImporter struct {
+	Plugin portfolio.Plugin
+}
+
+func (imp pluginImporter) Name() string { return imp.Plugin.Name }
+
+func (imp pluginImporter) Detect(head []byte) bool {
+	var result struct {
+		Match bool `json:"match"`
+	}
+	err := imp.Plugin.Call(context.Background(), "detect", map[string][]byte{"head": head}, &result)
+	return err == nil && result.Match
+}
+
+func (imp pluginImporter) Parse(r io.Reader, opts portfolio.StatementOptions) (*portfolio.Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	params := struct {
+		Data       []byte `json:"data"`
+		Account    string `json:"account,omitempty"`
+		MonthFirst bool   `json:"month_first,omitempty"`
+	}{data, opts.Account, opts.MonthFirst}
+	var result struct {
+		Accounts []pluginAccount `json:"accounts"`
+	}
+	if err := imp.Plugin.Call(context.Background(), "parse", params, &result); err != nil {
+		return nil, err
+	}
+	st := &portfolio.Statement{}
+	for _, a := range result.Accounts {
+		acct, err := a.statement()
+		if err != nil {
+			return nil, fmt.Errorf("extension %s, compte %s: %w", imp.Plugin.Name, a.ID, err)
+		}
+		if acct.ID == "" {
+			acct.ID = portfolio.DefaultString(opts.Account, imp.Plugin.Name)
+		}
+		st.Accounts = append(st.Accounts, acct)
+	}
+	return st, nil
+}
+
+// registerPluginImporters déclare les extensions qui lisent des relevés, après les
+// adaptateurs intégrés dans l'ordre de détection
+func registerPluginImporters() {
+	for _, pl := range describedPlugins(portfolio.PluginImporter) {
+		portfolio.RegisterImporter(pluginImporter{Plugin: pl.Plugin})
+	}
+}
+
+// pluginRenderer retourne l'extension de rendu d'un format, désigné par le nom de
+// l'extension ou l'un des formats qu'elle déclare
+func pluginRenderer(format string) (portfolio.Plugin, string, error) {
+	for _, pl := range describedPlugins(portfolio.PluginRenderer) {
+		if pl.Name == format {
+			if len(pl.info.Formats) > 0 {
+				return pl.Plugin, pl.info.Formats[0], nil
+			}
+			return pl.Plugin, "", nil
+		}
+		if slices.Contains(pl.info.Formats, format) {
+			return pl.Plugin, format, nil
+		}
+	}
+	return portfolio.Plugin{}, "", fmt.Errorf("aucune extension ne produit le format '%s': %w", format, portfolio.ErrNotFound)
+}
+
+func runPlugins(args []string) error {
+	fs, _ := newFlagSet("plugins")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list := plugins()
+	if len(list) == 0 {
+		fmt.Println("Aucune extension (table [plugins] de la configuration)")
+		return nil
+	}
+	var errs []error
+	for _, pl := range list {
+		info, err := pl.Describe(context.Background())
+		if err != nil {
+			fmt.Printf("%-16s %s: %v\n", pl.Name, pl.Path, err)
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Printf("%-16s %s %s: %s", pl.Name, info.Name, info.Version, strings.Join(info.Capabilities, ", "))
+		if len(info.Formats) > 0 {
+			fmt.Printf(" (formats %s)", strings.Join(info.Formats, ", "))
+		}
+		fmt.Println()
+	}
+	return errors.Join(errs...)
+}
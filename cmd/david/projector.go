@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// parseProjectionParams lit des paramètres "nom=valeur"
+func parseProjectionParams(list []string) (map[string]float64, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+	params := make(map[string]float64, len(list))
+	for _, item := range list {
+		key, raw, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !portfolio.IsExprIdent(key) {
+			return nil, portfolio.InvalidField("param", item, "paramètre invalide: %s (nom=valeur attendu)", item)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, portfolio.InvalidField("param", item, "valeur invalide pour %s: %s", key, raw)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+func runSetProjection(args []string) error {
+	fs, file := newFlagSet("set-projection")
+	name := fs.String("name", "", "nom de l'investissement")
+	model := fs.String("model", "", "modèle de projection ("+strings.Join(portfolio.Projectors(), ", ")+") ; vide pour revenir à compound")
+	var params stringList
+	fs.Var(&params, "param", "paramètre du modèle \"nom=valeur\" (répétable), ex. long_rate=3")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+	parsed, err := parseProjectionParams(params)
+	if err != nil {
+		return err
+	}
+	if *model == "" && len(parsed) > 0 {
+		return fmt.Errorf("--param nécessite --model")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetProjection(*name, portfolio.ProjectionModel{Model: *model, Params: parsed}); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runDataQuality(args []string) error {
+	fs, file := newFlagSet("data-quality")
+	step := fs.String("step", "", "périodicité attendue des NAV (daily, weekly, monthly, quarterly, yearly ; déduite de l'historique si vide)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	report, err := p.DataQuality(portfolio.SeriesStep(*step))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("=== QUALITÉ DES DONNÉES ===")
+	for _, q := range report {
+		fmt.Printf("\n%s: %.0f/100\n", q.Investment, q.Score)
+		if q.NAVs == 0 {
+			fmt.Println("  Aucune NAV")
+			continue
+		}
+		fmt.Printf("  NAV: %d, dernière le %s (%d jours)", q.NAVs, portfolio.FormatDate(q.Latest), q.Staleness)
+		if q.Stale() {
+			fmt.Print(" — périmée")
+		}
+		fmt.Println()
+		if q.LongestGap > 0 {
+			fmt.Printf("  Plus long écart: %d jours, du %s au %s\n", q.LongestGap, portfolio.FormatDate(q.GapStart), portfolio.FormatDate(q.GapEnd))
+		}
+		fmt.Printf("  Couverture %s: %d/%d périodes (%.1f%%)\n", q.Expected, q.Covered, q.Periods, q.Coverage())
+	}
+	return nil
+}
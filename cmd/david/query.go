@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// parseQueryTag lit un filtre d'étiquette "clé=valeur", ou une classe d'actifs seule
+func parseQueryTag(raw string) portfolio.QueryFilter {
+	if tag, value, found := strings.Cut(raw, "="); found {
+		return portfolio.WithTag(strings.TrimSpace(tag), strings.TrimSpace(value))
+	}
+	return portfolio.WithAssetClass(strings.TrimSpace(raw))
+}
+
+// queryFlags déclare les options de filtrage et de tri communes aux commandes list et
+// summary, les indicateurs personnalisés venant de la configuration ; la fonction
+// retournée construit la requête une fois les options lues
+func queryFlags(fs *flag.FlagSet) func() (portfolio.QueryOptions, error) {
+	tags, currencies := new(stringList), new(stringList)
+	fs.Var(tags, "tag", "étiquette \"clé=valeur\" ou classe d'actifs (répétable)")
+	fs.Var(currencies, "currency", "devise de l'investissement (répétable)")
+	minReturn := fs.Float64("min-return", 0, "taux de performance minimal (%)")
+	maxReturn := fs.Float64("max-return", 0, "taux de performance maximal (%)")
+	maxDrawdown := fs.Float64("max-drawdown", 0, "perte maximale tolérée (%)")
+	minShare := fs.Float64("min-share", 0, "part minimale du portefeuille (%)")
+	maxShare := fs.Float64("max-share", 0, "part maximale du portefeuille (%)")
+	where := fs.String("where", "", "condition sur les variables et indicateurs (ex. \"return > 0.05 && share < 0.2\")")
+	sortKey := fs.String("sort", string(portfolio.SortByName), "tri (name, value, share, return, drawdown ou indicateur de la configuration)")
+	desc := fs.Bool("desc", false, "tri décroissant")
+
+	return func() (portfolio.QueryOptions, error) {
+		opts := portfolio.QueryOptions{Sort: portfolio.QuerySort(*sortKey), Descending: *desc, Metrics: portfolio.ActiveConfig().Metrics}
+		for _, tag := range *tags {
+			opts.Filters = append(opts.Filters, parseQueryTag(tag))
+		}
+		if len(*currencies) > 0 {
+			list := make([]portfolio.Currency, len(*currencies))
+			for i, cur := range *currencies {
+				list[i] = portfolio.Currency(strings.ToUpper(cur))
+			}
+			opts.Filters = append(opts.Filters, portfolio.WithCurrency(list...))
+		}
+
+		// Les bornes ne sont appliquées que si elles sont renseignées
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["min-return"] || set["max-return"] {
+			low, high := math.Inf(-1), math.Inf(1)
+			if set["min-return"] {
+				low = *minReturn
+			}
+			if set["max-return"] {
+				high = *maxReturn
+			}
+			opts.Filters = append(opts.Filters, portfolio.WithReturnBetween(low, high))
+		}
+		if set["max-drawdown"] {
+			opts.Filters = append(opts.Filters, portfolio.WithMaxDrawdown(*maxDrawdown))
+		}
+		if set["min-share"] || set["max-share"] {
+			high := 100.0
+			if set["max-share"] {
+				high = *maxShare
+			}
+			opts.Filters = append(opts.Filters, portfolio.WithShareBetween(*minShare, high))
+		}
+		if *where != "" {
+			cond, err := portfolio.ParseMetric("where", *where)
+			if err != nil {
+				return portfolio.QueryOptions{}, err
+			}
+			if err := portfolio.ValidateMetrics(append(slices.Clone(opts.Metrics), cond)); err != nil {
+				return portfolio.QueryOptions{}, err
+			}
+			opts.Filters = append(opts.Filters, portfolio.WithCondition(cond))
+		}
+		return opts, nil
+	}
+}
+
+func runList(args []string) error {
+	fs, file := newFlagSet("list")
+	query := queryFlags(fs)
+	limit := fs.Int("limit", 0, "nombre maximal d'investissements affichés")
+	closed := fs.Bool("closed", false, "inclut les investissements clôturés")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts, err := query()
+	if err != nil {
+		return err
+	}
+	opts.Limit, opts.IncludeClosed = *limit, *closed
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	results, err := p.Query(opts)
+	if err != nil {
+		return err
+	}
+
+	base := p.ConsolidationCurrency()
+	for _, r := range results {
+		fmt.Printf("%-30s %12.2f %s %6.2f%%  perf %s  drawdown %s", r.Name, r.Value, base, r.Share,
+			portfolio.FormatPercentCell(deref(r.Return)), portfolio.FormatPercentCell(deref(r.Drawdown)))
+		for _, m := range opts.Metrics {
+			value, ok := r.Metrics[m.Name]
+			fmt.Printf("  %s %s", m.Name, portfolio.FormatMetric(value, ok))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("\n%d investissement(s), valeur totale %.2f %s\n", len(results), results.TotalValue(), base)
+	return nil
+}
+
+// deref retourne la valeur pointée et si elle est présente
+func deref(v *float64) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	return *v, true
+}
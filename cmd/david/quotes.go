@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetIdentifier(args []string) error {
+	fs, file := newFlagSet("set-identifier")
+	name := fs.String("name", "", "nom de l'investissement")
+	identifier := fs.String("id", "", "ISIN, ticker ou crypto:<identifiant CoinGecko> (vide pour le retirer)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetIdentifier(*name, *identifier); err != nil {
+		return err
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Identifiant de %s: %s\n", *name, *identifier)
+	return nil
+}
+
+func runRefresh(args []string) error {
+	fs, file := newFlagSet("refresh")
+	baseURL := fs.String("provider-url", portfolio.ActiveConfig().Quotes.ProviderURL, "adresse de l'API Yahoo Finance (par défaut l'adresse publique)")
+	cryptoURL := fs.String("crypto-url", portfolio.ActiveConfig().Quotes.CryptoURL, "adresse de l'API CoinGecko pour les identifiants crypto:* (par défaut l'adresse publique)")
+	timeout := fs.Duration("timeout", 30*time.Second, "délai maximal de la mise à jour")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	p.SetQuoteProvider(portfolio.QuoteRouter{
+		Default:   portfolio.YahooQuoteProvider{BaseURL: *baseURL},
+		Providers: pluginQuoteProviders(map[string]portfolio.QuoteProvider{portfolio.CryptoPrefix: portfolio.CoinGeckoQuoteProvider{BaseURL: *cryptoURL, Currency: p.ConsolidationCurrency()}}),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	results, refreshErr := p.RefreshNAVs(ctx)
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("%s (%s): échec: %v\n", r.Name, r.Identifier, r.Err)
+			continue
+		}
+		fmt.Printf("%s (%s): NAV %.2f au %s\n", r.Name, r.Identifier, r.NAV.Value.Float64(), portfolio.FormatDate(r.NAV.Date.Time))
+	}
+	if err := p.SaveJSON(*file); err != nil {
+		return err
+	}
+	return refreshErr
+}
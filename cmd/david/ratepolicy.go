@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetRatePolicy(args []string) error {
+	fs, file := newFlagSet("set-rate-policy")
+	name := fs.String("name", "", "nom de l'investissement")
+	mode := fs.String("mode", string(portfolio.RateMin), "règle (min, max, realized, reference, blend, trend ; vide pour la règle par défaut)")
+	weight := fs.Float64("weight", 0.5, "poids du taux réalisé pour la règle blend (0 à 1)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	var policy *portfolio.RatePolicy
+	if *mode != "" {
+		policy = &portfolio.RatePolicy{Mode: portfolio.RateMode(*mode)}
+		if policy.Mode == portfolio.RateBlend {
+			policy.RealizedWeight = *weight
+		}
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetRatePolicy(*name, policy); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
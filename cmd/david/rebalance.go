@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseWeights lit une liste "nom=poids,nom=poids"
+func parseWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("poids invalide %q (attendu nom=poids)", part)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("poids invalide pour %s: %w", name, err)
+		}
+		weights[strings.TrimSpace(name)] = weight
+	}
+	return weights, nil
+}
+
+func runSetTarget(args []string) error {
+	fs, file := newFlagSet("set-target")
+	weights := fs.String("weights", "", "poids cibles en % (ex: \"Actions=60,Obligations=40\"), vide pour supprimer")
+	minTrade := fs.Float64("min-trade", 0, "montant minimal d'un arbitrage")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	parsed, err := parseWeights(*weights)
+	if err != nil {
+		return err
+	}
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetTargetAllocation(parsed, *minTrade); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runRebalance(args []string) error {
+	fs, file := newFlagSet("rebalance")
+	date := fs.String("date", "", "date du plan d'arbitrage (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	plan, err := p.RebalancePlan(*date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== PLAN D'ARBITRAGE AU %s ===\n\n", *date)
+	for _, trade := range plan {
+		fmt.Printf("%s: %.2f%% (cible %.2f%%, écart %+.2f pts)", trade.Name, trade.CurrentWeight, trade.TargetWeight, trade.Drift)
+		switch {
+		case trade.Amount > 0:
+			fmt.Printf(" -> acheter %.2f€\n", trade.Amount)
+		case trade.Amount < 0:
+			fmt.Printf(" -> vendre %.2f€\n", -trade.Amount)
+		default:
+			fmt.Println(" -> aucun arbitrage")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runAddRecurringPlan(args []string) error {
+	fs, file := newFlagSet("add-recurring")
+	name := fs.String("name", "", "nom du plan")
+	investment := fs.String("investment", "", "investissement alimenté")
+	amount := fs.Float64("amount", 0, "montant de chaque versement")
+	frequency := fs.String("frequency", string(portfolio.StepMonthly), "périodicité (weekly, monthly, quarterly, yearly)")
+	next := fs.String("next", "", "date de la prochaine échéance (AAAA-MM-JJ)")
+	end := fs.String("end", "", "date de fin du plan (AAAA-MM-JJ, facultative)")
+	remove := fs.Bool("delete", false, "supprime le plan --name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *remove {
+		err = p.RemoveRecurringPlan(*name)
+	} else {
+		err = p.AddRecurringPlan(*name, *investment, *amount, portfolio.SeriesStep(*frequency), *next, *end)
+	}
+	if err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runRecurring(args []string) error {
+	fs, file := newFlagSet("recurring")
+	apply := fs.Bool("apply", false, "enregistre les échéances atteintes à ce jour")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *apply {
+		flows, err := p.MaterializePlans(portfolio.Today())
+		for _, f := range flows {
+			fmt.Printf("%s: versement de %s sur %s au %s\n", f.Plan, f.Amount, f.Investment, portfolio.FormatDate(f.Date))
+		}
+		if len(flows) > 0 {
+			if err := p.SaveJSON(*file); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range p.RecurringPlanNames() {
+		r := p.RecurringPlans[name]
+		end := "sans fin"
+		if !r.End.IsZero() {
+			end = "jusqu'au " + portfolio.FormatDate(r.End.Time)
+		}
+		fmt.Printf("%-20s %-20s %s %s, prochaine échéance %s, %s\n", name, r.Investment, r.Amount, r.Frequency, portfolio.FormatDate(r.Start.Time), end)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// reminderPeriod lit une période de rappels, par défaut l'année qui vient
+func reminderPeriod(from, to string) (time.Time, time.Time, error) {
+	start, end, err := portfolio.ParsePeriod(from, to)
+	if err != nil {
+		return start, end, err
+	}
+	if start.IsZero() {
+		start = portfolio.Today()
+	}
+	if end.IsZero() {
+		end = start.AddDate(1, 0, 0)
+	}
+	return start, end, nil
+}
+
+func runReminders(args []string) error {
+	fs, file := newFlagSet("reminders")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ, aujourd'hui par défaut)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ, un an après le début par défaut)")
+	format := fs.String("format", "text", "format de sortie (text, ics)")
+	output := fs.String("output", "", "fichier à écrire (sortie standard par défaut)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start, end, err := reminderPeriod(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	reminders, err := p.Reminders(start, end)
+	if err != nil {
+		return err
+	}
+
+	var render func(w io.Writer) error
+	switch *format {
+	case "ics":
+		render = func(w io.Writer) error { return portfolio.WriteICS(w, reminders, time.Now()) }
+	case "text":
+		render = func(w io.Writer) error {
+			for _, r := range reminders {
+				fmt.Fprintf(w, "%s  %-12s %s\n", portfolio.FormatDate(r.Date.Time), r.Kind, r.Summary)
+			}
+			if len(reminders) == 0 {
+				fmt.Fprintf(w, "Aucun rappel du %s au %s\n", portfolio.FormatDate(start), portfolio.FormatDate(end))
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("format de sortie inconnu: %s", *format)
+	}
+
+	if *output == "" {
+		return render(os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Rappels écrits dans %s\n", *output)
+	return nil
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// replCommand est une commande de la session interactive
+type replCommand struct {
+	name, usage, description string
+	run                      func(r *repl, args []string) error
+}
+
+// replCommands retourne les commandes propres à la session ; les autres lignes sont
+// exécutées comme des commandes de david sur le fichier de la session
+func replCommands() []replCommand {
+	return []replCommand{
+		{"help", "help", "affiche cette aide", (*repl).help},
+		{"list", "list", "liste les investissements et leur valeur", (*repl).list},
+		{"show", "show <investissement>", "détaille un investissement et ses dernières NAV", (*repl).show},
+		{"nav", "nav <investissement> <date> <valeur>", "ajoute une NAV et enregistre le portefeuille", (*repl).nav},
+		{"date", "date <AAAA-MM-JJ>", "change la date de projection par défaut", (*repl).setDate},
+		{"rate", "rate [<investissement> [<taux %>]]", "impose un taux aux projections de la session, sans l'enregistrer (sans taux : le retire)", (*repl).setRate},
+		{"project", "project [<date>] [<investissement>]", "projette le portefeuille ou un investissement", (*repl).project},
+		{"reload", "reload", "relit le fichier du portefeuille", (*repl).reload},
+		{"quit", "quit", "termine la session (ou Ctrl-D)", nil},
+	}
+}
+
+// repl est l'état d'une session interactive
+type repl struct {
+	p     *portfolio.Portfolio
+	file  string
+	out   io.Writer
+	date  time.Time          // Date de projection par défaut
+	rates map[string]float64 // Taux (%) imposés aux projections de la session
+}
+
+// exec exécute une ligne ; done indique la fin de la session
+func (r *repl) exec(line string) (done bool, err error) {
+	args, err := splitArgs(line)
+	if err != nil || len(args) == 0 {
+		return false, err
+	}
+	if args[0] == "quit" || args[0] == "exit" {
+		return true, nil
+	}
+	for _, cmd := range replCommands() {
+		if cmd.name == args[0] {
+			return false, cmd.run(r, args[1:])
+		}
+	}
+	for _, cmd := range commands() {
+		if cmd.name != args[0] {
+			continue
+		}
+		if cmd.name == "repl" || cmd.name == "tui" {
+			return false, fmt.Errorf("%s ne peut pas être lancé depuis la session", cmd.name)
+		}
+		// La commande relit et enregistre le fichier : la session repart de son résultat
+		err := cmd.run(append(args[1:], "--file", r.file))
+		flushWebhooks()
+		if errors.Is(err, flag.ErrHelp) {
+			err = nil
+		}
+		if reloadErr := r.reload(nil); err == nil {
+			err = reloadErr
+		}
+		return false, err
+	}
+	return false, fmt.Errorf("commande inconnue: %s (help pour l'aide)", args[0])
+}
+
+func (r *repl) help([]string) error {
+	for _, cmd := range replCommands() {
+		fmt.Fprintf(r.out, "  %-38s %s\n", cmd.usage, cmd.description)
+	}
+	fmt.Fprintln(r.out, "\nToute autre commande de david (summary, risk...) s'exécute sur le fichier de la session.")
+	fmt.Fprintln(r.out, "Tab complète les commandes et les noms d'investissements ; un nom avec des espaces s'écrit entre guillemets.")
+	return nil
+}
+
+func (r *repl) list([]string) error {
+	summary, err := r.p.Summary()
+	if err != nil {
+		return err
+	}
+	amount := report.AmountFormatter(r.p).Format
+	for _, line := range summary.Investments {
+		latest := "aucune NAV"
+		if line.LatestNAV != nil {
+			latest = fmt.Sprintf("NAV %.2f au %s", line.LatestNAV.Value.Float64(), portfolio.FormatDate(line.LatestNAV.Date.Time))
+		}
+		if line.Closed {
+			latest = "clôturé"
+		}
+		fmt.Fprintf(r.out, "%-30s %18s  %s\n", report.Truncate(line.Name, 30), amount(line.Value), latest)
+	}
+	fmt.Fprintf(r.out, "%-30s %18s\n", "Total", amount(summary.TotalValue))
+	return nil
+}
+
+func (r *repl) show(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: show <investissement>")
+	}
+	inv, err := r.p.Investment(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "%s (%s)\n", inv.Name, inv.EffectiveCurrency())
+	fmt.Fprintf(r.out, "  Investi le %s : %.2f\n", portfolio.FormatDate(inv.InvestmentDate.Time), inv.AmountInvested.Float64())
+	fmt.Fprintf(r.out, "  Taux de référence : %.2f%%\n", inv.ReferenceRate)
+	if rate, err := inv.EffectiveRate(); err == nil {
+		fmt.Fprintf(r.out, "  Taux de projection : %.2f%%\n", rate)
+	}
+	if rate, ok := r.rates[inv.Name]; ok {
+		fmt.Fprintf(r.out, "  Taux imposé (session) : %.2f%%\n", rate)
+	}
+	fmt.Fprintf(r.out, "  %d NAV\n", len(inv.NAVHistory))
+	for _, nav := range inv.NAVHistory[max(len(inv.NAVHistory)-5, 0):] {
+		fmt.Fprintf(r.out, "    %s  %12.2f\n", portfolio.FormatDate(nav.Date.Time), nav.Value.Float64())
+	}
+	return nil
+}
+
+func (r *repl) nav(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: nav <investissement> <date> <valeur>")
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(args[2], ",", "."), 64)
+	if err != nil {
+		return fmt.Errorf("valeur invalide: %s", args[2])
+	}
+	if err := r.p.AddNAV(args[0], args[1], value); err != nil {
+		return err
+	}
+	if err := r.p.SaveJSON(r.file); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "NAV de %s au %s enregistrée\n", args[0], args[1])
+	return nil
+}
+
+func (r *repl) setDate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: date <AAAA-MM-JJ>")
+	}
+	t, err := portfolio.ParseDate(args[0])
+	if err != nil {
+		return err
+	}
+	r.date = t
+	return nil
+}
+
+func (r *repl) setRate(args []string) error {
+	switch len(args) {
+	case 0:
+		names := make([]string, 0, len(r.rates))
+		for name := range r.rates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(r.out, "  %-30s %.2f%%\n", name, r.rates[name])
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(r.out, "Aucun taux imposé")
+		}
+		return nil
+	case 1:
+		delete(r.rates, args[0])
+		return nil
+	case 2:
+		if _, err := r.p.Investment(args[0]); err != nil {
+			return err
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSuffix(strings.ReplaceAll(args[1], ",", "."), "%"), 64)
+		if err != nil {
+			return fmt.Errorf("taux invalide: %s", args[1])
+		}
+		r.rates[args[0]] = rate
+		return nil
+	default:
+		return fmt.Errorf("usage: rate [<investissement> [<taux %%>]]")
+	}
+}
+
+// scenario retourne le portefeuille de la session avec ses taux imposés, ou le
+// portefeuille lui-même sans taux imposé
+func (r *repl) scenario() (*portfolio.Portfolio, error) {
+	if len(r.rates) == 0 {
+		return r.p, nil
+	}
+	s, err := r.p.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for name, rate := range r.rates {
+		if inv, exists := s.Investments[name]; exists {
+			inv.ReferenceRate = rate
+			inv.RatePolicy = &portfolio.RatePolicy{Mode: portfolio.RateReference}
+		}
+	}
+	return s, nil
+}
+
+func (r *repl) project(args []string) error {
+	date := portfolio.FormatDate(r.date)
+	if len(args) > 0 {
+		if _, err := portfolio.ParseDate(args[0]); err == nil {
+			date, args = args[0], args[1:]
+		}
+	}
+	s, err := r.scenario()
+	if err != nil {
+		return err
+	}
+	amount := report.AmountFormatter(r.p).Format
+
+	if len(args) > 0 {
+		inv, err := s.Investment(args[0])
+		if err != nil {
+			return err
+		}
+		value, err := inv.ProjectNAV(date)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "%s au %s : %.2f %s\n", inv.Name, date, value, inv.EffectiveCurrency())
+		return nil
+	}
+
+	values, total, err := s.GetPortfolioValue(date)
+	if err != nil {
+		return err
+	}
+	for _, name := range s.InvestmentNames() {
+		if value, ok := values[name]; ok {
+			marker := ""
+			if _, imposed := r.rates[name]; imposed {
+				marker = fmt.Sprintf("  (taux imposé %.2f%%)", r.rates[name])
+			}
+			fmt.Fprintf(r.out, "%-30s %18s%s\n", report.Truncate(name, 30), amount(value), marker)
+		}
+	}
+	fmt.Fprintf(r.out, "%-30s %18s\n", "Total au "+date, amount(total))
+	return nil
+}
+
+func (r *repl) reload([]string) error {
+	p, err := loadPortfolioFile(r.file, "repl")
+	if err != nil {
+		return err
+	}
+	r.p = p
+	return nil
+}
+
+// complete retourne les complétions du dernier mot de line : commandes pour le premier
+// mot, noms d'investissements ensuite
+func (r *repl) complete(line string) (word string, candidates []string) {
+	start := strings.LastIndexAny(line, " \t") + 1
+	if quote := strings.Count(line, `"`); quote%2 == 1 {
+		start = strings.LastIndex(line, `"`)
+	}
+	word = line[start:]
+	prefix := strings.ToLower(strings.TrimPrefix(word, `"`))
+
+	var names []string
+	if strings.TrimSpace(line[:start]) == "" {
+		for _, cmd := range replCommands() {
+			names = append(names, cmd.name)
+		}
+		for _, cmd := range commands() {
+			names = append(names, cmd.name)
+		}
+	} else {
+		names = r.p.InvestmentNames()
+	}
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), prefix) && !slices.Contains(candidates, name) {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return word, candidates
+}
+
+// quoteArg met entre guillemets un argument qui contient des espaces
+func quoteArg(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// splitArgs découpe une ligne en arguments séparés par des espaces ; les guillemets
+// regroupent un argument qui en contient
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inQuotes, inArg := false, false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			inQuotes, inArg = !inQuotes, true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("guillemet non fermé")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// lineEditor lit les lignes de la session sur un terminal : édition, historique (flèches
+// haut et bas) et complétion (Tab)
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	history  []string
+	complete func(line string) (string, []string)
+}
+
+// readLine lit une ligne ; io.EOF signale Ctrl-D sur une ligne vide
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	var line []rune
+	pos := len(e.history)
+	redraw := func() { fmt.Fprintf(e.out, "\r\x1b[K%s%s", prompt, string(line)) }
+	redraw()
+	for {
+		key, err := readKey(e.in)
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case keyEnter:
+			fmt.Fprintln(e.out)
+			s := strings.TrimSpace(string(line))
+			if s != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != s) {
+				e.history = append(e.history, s)
+			}
+			return s, nil
+		case keyInterrupt:
+			fmt.Fprintln(e.out, "^C")
+			line = line[:0]
+		case 0x04:
+			if len(line) == 0 {
+				fmt.Fprintln(e.out)
+				return "", io.EOF
+			}
+		case keyBackspace:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		case keyUp:
+			if pos > 0 {
+				pos--
+				line = []rune(e.history[pos])
+			}
+		case keyDown:
+			if pos < len(e.history) {
+				pos++
+				line = line[:0]
+				if pos < len(e.history) {
+					line = []rune(e.history[pos])
+				}
+			}
+		case '\t':
+			line = e.completeLine(prompt, line)
+		default:
+			if key >= ' ' && key <= utf8.MaxRune {
+				line = append(line, rune(key))
+			}
+		}
+		redraw()
+	}
+}
+
+// completeLine complète le dernier mot : le nom entier s'il est unique, sinon le plus
+// long préfixe commun, en affichant les possibilités
+func (e *lineEditor) completeLine(prompt string, line []rune) []rune {
+	word, candidates := e.complete(string(line))
+	head := string(line)[:len(string(line))-len(word)]
+	switch len(candidates) {
+	case 0:
+		return line
+	case 1:
+		return []rune(head + quoteArg(candidates[0]) + " ")
+	}
+
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(strings.ToLower(c), strings.ToLower(common)) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	if len(common) > len(strings.TrimPrefix(word, `"`)) {
+		if strings.HasPrefix(word, `"`) || strings.ContainsAny(common, " \t") {
+			common = `"` + common
+		}
+		return []rune(head + common)
+	}
+	fmt.Fprintf(e.out, "\n%s\n", strings.Join(candidates, "  "))
+	return line
+}
+
+func runREPL(args []string) error {
+	fs, file := newFlagSet("repl")
+	date := fs.String("date", "", "date de projection par défaut (AAAA-MM-JJ, dans un an si vide)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	r := &repl{p: p, file: *file, out: os.Stdout, date: portfolio.Today().AddDate(1, 0, 0), rates: make(map[string]float64)}
+	if *date != "" {
+		if r.date, err = portfolio.ParseDate(*date); err != nil {
+			return err
+		}
+	}
+
+	// Sur un terminal, lecture touche par touche sans écho pour l'historique et la
+	// complétion ; sinon (script redirigé), lecture ligne par ligne sans invite
+	in := bufio.NewReader(os.Stdin)
+	next := func() (string, error) {
+		line, err := in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimSpace(line), err
+	}
+	if previous, err := stty("-icanon", "-echo", "-isig"); err == nil {
+		defer stty(previous)
+		editor := &lineEditor{in: in, out: os.Stdout, complete: r.complete}
+		next = func() (string, error) { return editor.readLine("david> ") }
+		fmt.Printf("Session sur %s — help pour l'aide, Tab pour compléter\n", *file)
+	}
+
+	for {
+		line, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		done, err := r.exec(line)
+		if err != nil {
+			fmt.Fprintf(r.out, "Erreur: %v\n", err)
+		}
+		if done {
+			return nil
+		}
+	}
+}
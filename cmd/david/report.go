@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runReport(args []string) error {
+	fs, file := newFlagSet("report")
+	output := fs.String("output", "", "fichier du rapport à écrire (sortie standard par défaut)")
+	tmplPath := fs.String("template", "", "gabarit text/template mettant en forme le rapport à la place du HTML")
+	renderer := fs.String("renderer", "", "extension (ou format d'une extension) mettant en forme le rapport à la place du HTML")
+	title := fs.String("title", "", "titre du rapport")
+	step := fs.String("step", string(portfolio.StepMonthly), "pas du graphique de valeur (daily, weekly, monthly, quarterly, yearly)")
+	tag := fs.String("tag", portfolio.TagAssetClass, "étiquette de la répartition")
+	var projections stringList
+	fs.Var(&projections, "project", "date de projection à afficher (AAAA-MM-JJ, répétable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	opts := report.ReportOptions{Title: *title, Step: portfolio.SeriesStep(*step), AllocationTag: *tag, ProjectionDates: projections, Locale: portfolio.EnvLocale(), Metrics: portfolio.ActiveConfig().Metrics}
+
+	render := func(w io.Writer, opts report.ReportOptions) error { return report.RenderHTML(p, w, opts) }
+	if *tmplPath != "" {
+		render = func(w io.Writer, opts report.ReportOptions) error { return renderTemplateFile(p, w, *tmplPath, opts) }
+	}
+	if *renderer != "" {
+		pl, format, err := pluginRenderer(*renderer)
+		if err != nil {
+			return err
+		}
+		render = func(w io.Writer, opts report.ReportOptions) error { return report.RenderPlugin(p, w, pl, format, opts) }
+	}
+
+	if *output == "" {
+		return render(os.Stdout, opts)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := render(f, opts); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Rapport écrit dans %s\n", *output)
+	reportWritten(*output, "html")
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+func runRisk(args []string) error {
+	fs, file := newFlagSet("risk")
+	riskFree := fs.Float64("risk-free", -1, "taux sans risque annuel (%), enregistré dans le portefeuille")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *riskFree >= 0 {
+		p.SetRiskFreeRate(*riskFree)
+		if err := p.SaveJSON(*file); err != nil {
+			return err
+		}
+	}
+
+	report, total, err := p.RiskReport()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== MESURES DE RISQUE (taux sans risque %.2f%%) ===\n\n", p.RiskFreeRate)
+	names := make([]string, 0, len(report))
+	for name := range report {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printRiskMetrics(name, report[name])
+	}
+	printRiskMetrics("Portefeuille", total)
+	return nil
+}
+
+func printRiskMetrics(name string, m analytics.RiskMetrics) {
+	fmt.Printf("%s: rendement %.2f%%, volatilité %.2f%%, Sharpe %.2f, Sortino %.2f",
+		name, m.Return, m.Volatility, m.Sharpe, m.Sortino)
+	if m.TrackingError != nil {
+		fmt.Printf(", tracking error %.2f%% et ratio d'information %.2f vs %s", *m.TrackingError, *m.InformationRatio, m.Benchmark)
+	}
+	fmt.Println()
+}
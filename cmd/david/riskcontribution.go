@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runRiskContribution(args []string) error {
+	fs, file := newFlagSet("risk-contribution")
+	from := fs.String("from", "", "début de la période (AAAA-MM-JJ)")
+	to := fs.String("to", "", "fin de la période (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	d, err := p.RiskDecomposition(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== CONTRIBUTIONS AU RISQUE (grille %s, %d rendements) ===\n\n", portfolio.ConfiguredAlignment().Step, d.Observations)
+	fmt.Printf("%-20s %9s %11s %10s %13s %9s\n", "Investissement", "Poids", "Volatilité", "Marginale", "Contribution", "Part")
+	for _, c := range d.Contributions {
+		fmt.Printf("%-20.20s %8.2f%% %10.2f%% %9.2f%% %12.2f%% %8.2f%%\n",
+			c.Name, c.Weight, c.Volatility, c.Marginal, c.Component, c.Share)
+	}
+	fmt.Printf("\nVolatilité du portefeuille: %.2f%%\n", d.Volatility)
+	if len(d.Contributions) > 0 && d.Contributions[0].Share > 50 {
+		top := d.Contributions[0]
+		fmt.Printf("%s porte %.0f%% du risque pour %.0f%% de la valeur\n", top.Name, top.Share, top.Weight)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runRollingReturns(args []string) error {
+	fs, file := newFlagSet("rolling")
+	name := fs.String("name", "", "nom de l'investissement")
+	months := fs.Int("window", 12, "durée de la fenêtre (mois)")
+	stepDays := fs.Int("step", 30, "décalage entre deux fenêtres (jours)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+
+	// Une fenêtre de n mois dure n douzièmes d'année de 365,25 jours
+	window := time.Duration(float64(*months) / 12 * 365.25 * 24 * float64(time.Hour))
+	series, err := inv.RollingReturns(window, time.Duration(*stepDays)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== RENDEMENTS GLISSANTS SUR %d MOIS: %s ===\n\n", *months, *name)
+	for _, r := range series {
+		fmt.Printf("%s → %s: %.2f%%\n", portfolio.FormatDate(r.Start.Time), portfolio.FormatDate(r.End.Time), r.Return)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// parseScenarioRule lit une règle "investment=X,class=Y,shock=-30,rate=5"
+func parseScenarioRule(s string) (portfolio.ScenarioRule, error) {
+	var rule portfolio.ScenarioRule
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return rule, fmt.Errorf("règle invalide %q (attendu clé=valeur)", part)
+		}
+		switch key {
+		case "investment":
+			rule.Investment = value
+		case "class":
+			rule.AssetClass = value
+		case "shock", "rate":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return rule, fmt.Errorf("valeur invalide pour %s: %w", key, err)
+			}
+			if key == "shock" {
+				rule.Shock = number
+			} else {
+				rule.Rate = &number
+			}
+		default:
+			return rule, fmt.Errorf("clé de règle inconnue: %s", key)
+		}
+	}
+	return rule, nil
+}
+
+// stringList collecte les occurrences répétées d'une option
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, " ") }
+
+func (l *stringList) Set(s string) error { *l = append(*l, s); return nil }
+
+func runSetScenario(args []string) error {
+	fs, file := newFlagSet("set-scenario")
+	name := fs.String("name", "", "nom du scénario")
+	var rules stringList
+	fs.Var(&rules, "rule", "règle \"investment=X|class=Y,shock=-30,rate=5\" (répétable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	scenario := portfolio.Scenario{Name: *name}
+	for _, r := range rules {
+		rule, err := parseScenarioRule(r)
+		if err != nil {
+			return err
+		}
+		scenario.Rules = append(scenario.Rules, rule)
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetScenario(scenario); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runScenarios(args []string) error {
+	fs, file := newFlagSet("scenarios")
+	date := fs.String("date", "", "date de projection (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(p.Scenarios))
+	for name := range p.Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return fmt.Errorf("aucun scénario défini")
+	}
+
+	fmt.Printf("=== SCÉNARIOS AU %s ===\n\n", *date)
+	results := make([]map[string]float64, len(names))
+	totals := make([]float64, len(names))
+	fmt.Printf("%-20s", "")
+	for i, name := range names {
+		if results[i], totals[i], err = p.ProjectScenario(name, *date); err != nil {
+			return err
+		}
+		fmt.Printf("%14s", name)
+	}
+	fmt.Println()
+	for _, inv := range p.InvestmentNames() {
+		if _, open := results[0][inv]; !open {
+			continue
+		}
+		fmt.Printf("%-20s", inv)
+		for i := range names {
+			fmt.Printf("%13.2f€", results[i][inv])
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%-20s", "Total")
+	for _, total := range totals {
+		fmt.Printf("%13.2f€", total)
+	}
+	fmt.Println()
+	return nil
+}
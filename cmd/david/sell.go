@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runSell(args []string) error {
+	fs, file := newFlagSet("sell")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", "", "date de la vente (AAAA-MM-JJ)")
+	amount := fs.Float64("amount", 0, "montant brut vendu")
+	units := fs.Float64("units", 0, "nombre de parts vendues (investissements suivis en parts)")
+	fees := fs.Float64("fees", 0, "frais de la vente")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	sale, err := p.Sell(*name, *date, *amount, *units, *fees)
+	if err != nil {
+		return err
+	}
+
+	amountOf := report.AmountFormatter(p).Format
+	if sale.Units > 0 {
+		fmt.Printf("%s parts vendues\n", sale.Units)
+	}
+	fmt.Printf("Produit de la vente: %s\n", amountOf(sale.Amount.Float64()))
+	fmt.Printf("Prix de revient sorti: %s\n", amountOf(sale.CostBasis.Float64()))
+	fmt.Printf("Plus-value réalisée: %s\n", amountOf(sale.Gain.Float64()))
+	return p.SaveJSON(*file)
+}
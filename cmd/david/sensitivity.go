@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// sweep retourne base ± k×step pour k de 0 à n, par ordre croissant, sans valeur
+// inférieure à floor
+func sweep(base, step float64, n int, floor float64) []float64 {
+	var values []float64
+	for k := -n; k <= n; k++ {
+		if v := base + float64(k)*step; v >= floor {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// heatShades sont les nuances de la carte de chaleur, de la valeur la plus faible à la plus forte
+var heatShades = []string{" ", "░", "▒", "▓", "█"}
+
+// heatShade retourne la nuance d'une valeur entre low et high
+func heatShade(value, low, high float64) string {
+	if high <= low {
+		return heatShades[len(heatShades)-1]
+	}
+	i := int((value - low) / (high - low) * float64(len(heatShades)))
+	return heatShades[min(max(i, 0), len(heatShades)-1)]
+}
+
+func runSensitivity(args []string) error {
+	fs, file := newFlagSet("sensitivity")
+	date := fs.String("date", "", "date visée (AAAA-MM-JJ)")
+	monthly := fs.Float64("monthly", 0, "versement mensuel de référence")
+	monthlyStep := fs.Float64("monthly-step", 100, "écart entre deux versements de la grille")
+	rate := fs.Float64("rate", 0, "taux annuel de référence (%) ; taux moyen des investissements pondéré par leurs valeurs si 0")
+	rateStep := fs.Float64("rate-step", 1, "écart entre deux taux de la grille (points)")
+	steps := fs.Int("steps", 2, "nombre de pas de part et d'autre de la référence")
+	format := fs.String("format", "text", "format de sortie (text, csv)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+	if *steps < 0 || *monthlyStep <= 0 || *rateStep <= 0 {
+		return fmt.Errorf("--steps, --monthly-step et --rate-step doivent être positifs")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *rate == 0 {
+		_, *rate, err = p.BlendedProjection(*date)
+		if err != nil {
+			return err
+		}
+		*rate = portfolio.NewMoney(*rate).RoundCents().Float64()
+	}
+	g, err := p.Sensitivity(*date, sweep(*monthly, *monthlyStep, *steps, 0), sweep(*rate, *rateStep, *steps, analytics.GoalMinRate))
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		header := []string{"rate"}
+		for _, c := range g.Contributions {
+			header = append(header, strconv.FormatFloat(c, 'f', 2, 64))
+		}
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		for i, r := range g.Rates {
+			row := []string{strconv.FormatFloat(r, 'f', 2, 64)}
+			for _, v := range g.Values[i] {
+				row = append(row, strconv.FormatFloat(v, 'f', 2, 64))
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	case "text":
+	default:
+		return fmt.Errorf("format invalide: %s (text, csv)", *format)
+	}
+
+	low, high := g.Values[0][0], g.Values[len(g.Rates)-1][len(g.Contributions)-1]
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("=== SENSIBILITÉ DE LA VALEUR AU %s ===\n\n", *date)
+	fmt.Printf("%-8s", "Taux")
+	for _, c := range g.Contributions {
+		fmt.Printf(" %18s", amount(c)+"/m")
+	}
+	fmt.Println()
+	for i, r := range g.Rates {
+		fmt.Printf("%6.2f%% ", r)
+		for _, v := range g.Values[i] {
+			fmt.Printf(" %16s %s", amount(v), heatShade(v, low, high))
+		}
+		fmt.Println()
+	}
+
+	// Effet d'un pas de versement et d'un pas de taux autour de la référence
+	ri, ci := slices.Index(g.Rates, *rate), slices.Index(g.Contributions, *monthly)
+	if ri < 0 || ci < 0 {
+		return nil
+	}
+	base := g.Values[ri][ci]
+	fmt.Println()
+	if ci+1 < len(g.Contributions) {
+		fmt.Printf("Verser %s de plus par mois: +%s\n", amount(*monthlyStep), amount(g.Values[ri][ci+1]-base))
+	}
+	if ri+1 < len(g.Rates) {
+		fmt.Printf("Obtenir %.2f point(s) de rendement de plus: +%s\n", *rateStep, amount(g.Values[ri+1][ci]-base))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSeries(args []string) error {
+	fs, file := newFlagSet("series")
+	from := fs.String("from", "", "début de la série (AAAA-MM-JJ, première date d'investissement par défaut)")
+	to := fs.String("to", "", "fin de la série (AAAA-MM-JJ, dernière NAV par défaut)")
+	step := fs.String("step", string(portfolio.StepMonthly), "pas de la série (daily, weekly, monthly, quarterly, yearly)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	series, err := p.ValueSeries(*from, *to, portfolio.SeriesStep(*step))
+	if err != nil {
+		return err
+	}
+	return writeSeriesCSV(p, series)
+}
+
+// writeSeriesCSV écrit une série de valeurs en CSV, directement exploitable par un
+// tableur ou un outil de graphique : date, total puis une colonne par investissement
+func writeSeriesCSV(p *portfolio.Portfolio, series []portfolio.ValuePoint) error {
+	names := p.InvestmentNames()
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(append([]string{"date", "total"}, names...)); err != nil {
+		return err
+	}
+	for _, point := range series {
+		record := []string{portfolio.FormatDate(point.Date.Time), strconv.FormatFloat(point.Total, 'f', 2, 64)}
+		for _, name := range names {
+			record = append(record, strconv.FormatFloat(point.Values[name], 'f', 2, 64))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func runProjectSeries(args []string) error {
+	fs, file := newFlagSet("project-series")
+	name := fs.String("name", "", "investissement projeté (tout le portefeuille si vide)")
+	to := fs.String("to", "", "horizon de la projection (AAAA-MM-JJ)")
+	step := fs.String("step", string(portfolio.StepMonthly), "pas de la série (daily, weekly, monthly, quarterly, yearly)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *to == "" {
+		return fmt.Errorf("--to est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	printProjectionWarnings(os.Stderr, p)
+	if *name == "" {
+		series, err := p.ProjectSeries(*to, portfolio.SeriesStep(*step))
+		if err != nil {
+			return err
+		}
+		return writeSeriesCSV(p, series)
+	}
+
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+	series, err := inv.ProjectSeries(*to, portfolio.SeriesStep(*step))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write([]string{"date", "value"}); err != nil {
+		return err
+	}
+	for _, point := range series {
+		if err := w.Write([]string{portfolio.FormatDate(point.Date.Time), point.Value.String()}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
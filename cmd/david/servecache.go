@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// defaultCacheEntries est le nombre de réponses conservées par défaut par le cache de serve
+const defaultCacheEntries = 256
+
+// resultCache conserve les réponses des endpoints de calcul de serve (projections,
+// Monte-Carlo à graine fixée, rappels), par révision du portefeuille et paramètres de la
+// requête. Une réponse calculée sur une révision antérieure n'est jamais servie ; le
+// cache est en outre vidé dès qu'un événement signale une modification.
+type resultCache struct {
+	mu         sync.Mutex
+	portfolio  *portfolio.Portfolio
+	maxEntries int
+	entries    map[string]*cachedResponse
+
+	hits, misses, invalidations, evictions uint64
+}
+
+// cachedResponse est une réponse conservée
+type cachedResponse struct {
+	revision    uint64
+	status      int
+	contentType string
+	body        []byte
+	used        time.Time // Dernier accès, pour l'éviction des moins récemment utilisées
+}
+
+// newResultCache crée un cache d'au plus maxEntries réponses (defaultCacheEntries si nul)
+// et l'abonne aux événements du portefeuille jusqu'à l'appel de la fonction retournée
+func newResultCache(p *portfolio.Portfolio, maxEntries int) (*resultCache, func()) {
+	if maxEntries == 0 {
+		maxEntries = defaultCacheEntries
+	}
+	c := &resultCache{portfolio: p, maxEntries: maxEntries, entries: make(map[string]*cachedResponse)}
+	events, unsubscribe := p.Subscribe()
+	go func() {
+		for range events {
+			c.invalidate()
+		}
+	}()
+	return c, unsubscribe
+}
+
+// invalidate vide le cache
+func (c *resultCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) > 0 {
+		c.invalidations++
+		clear(c.entries)
+	}
+}
+
+// cacheKey retourne la clé d'une requête : chemin, paramètres triés et jour courant, dont
+// dépendent l'ancienneté des NAV et les taux des comptes rémunérés
+func cacheKey(r *http.Request) string {
+	query := r.URL.Query()
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, name+"="+v)
+		}
+	}
+	sort.Strings(params)
+	return portfolio.FormatDate(portfolio.Today()) + " " + r.URL.Path + "?" + strings.Join(params, "&")
+}
+
+// get retourne la réponse conservée pour une clé si elle a été calculée sur la révision
+// courante
+func (c *resultCache) get(key string, revision uint64) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if !found || entry.revision != revision {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	entry.used = time.Now()
+	return entry, true
+}
+
+// put conserve une réponse, en évinçant la moins récemment utilisée si le cache est plein
+func (c *resultCache) put(key string, entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		oldest := ""
+		for k, e := range c.entries {
+			if oldest == "" || e.used.Before(c.entries[oldest].used) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+		c.evictions++
+	}
+	entry.used = time.Now()
+	c.entries[key] = entry
+}
+
+// recorder capture la réponse d'un endpoint tout en la transmettant au client
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// cached sert les réponses d'un endpoint depuis le cache. cacheable décide si une requête
+// peut l'être (toutes si nil) ; seules les réponses réussies sont conservées. Sans cache,
+// l'endpoint est appelé directement.
+func (c *resultCache) cached(next http.HandlerFunc, cacheable func(*http.Request) bool) http.HandlerFunc {
+	if c == nil || c.maxEntries < 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cacheable != nil && !cacheable(r) {
+			next(w, r)
+			return
+		}
+		key := cacheKey(r)
+		revision := c.portfolio.Revision()
+		if entry, found := c.get(key, revision); found {
+			w.Header().Set("Content-Type", entry.contentType)
+			w.Header().Set("X-Cache", "hit")
+			w.WriteHeader(entry.status)
+			if _, err := w.Write(entry.body); err != nil {
+				log.Printf("écriture de la réponse en cache: %v", err)
+			}
+			return
+		}
+
+		w.Header().Set("X-Cache", "miss")
+		rec := &recorder{ResponseWriter: w}
+		next(rec, r)
+		// Une modification pendant le calcul rend le résultat douteux : il n'est pas conservé
+		if rec.status == http.StatusOK && c.portfolio.Revision() == revision {
+			c.put(key, &cachedResponse{
+				revision:    revision,
+				status:      rec.status,
+				contentType: w.Header().Get("Content-Type"),
+				body:        bytes.Clone(rec.body.Bytes()),
+			})
+		}
+	}
+}
+
+// writeMetrics écrit les compteurs et la taille du cache au format texte de Prometheus
+func (c *resultCache) writeMetrics(w io.Writer) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	hits, misses, invalidations, evictions, entries := c.hits, c.misses, c.invalidations, c.evictions, len(c.entries)
+	c.mu.Unlock()
+
+	counters := []*portfolio.Counter{
+		{Name: "david_serve_cache_hits_total", Help: "Réponses servies depuis le cache depuis le lancement."},
+		{Name: "david_serve_cache_misses_total", Help: "Réponses calculées faute de résultat en cache depuis le lancement."},
+		{Name: "david_serve_cache_invalidations_total", Help: "Vidages du cache après une modification du portefeuille."},
+		{Name: "david_serve_cache_evictions_total", Help: "Réponses évincées du cache plein."},
+	}
+	for i, v := range []uint64{hits, misses, invalidations, evictions} {
+		counters[i].Add(float64(v))
+		if err := counters[i].WriteText(w); err != nil {
+			return err
+		}
+	}
+	size := &portfolio.Gauge{Name: "david_serve_cache_entries", Help: "Réponses actuellement en cache."}
+	size.Add(float64(entries))
+	return size.WriteText(w)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// projectionCache demande la projection et retourne l'en-tête X-Cache de la réponse
+func projectionCache(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projection?date=2025-01-01", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /projection: statut %d: %s", rec.Code, rec.Body)
+	}
+	return rec.Header().Get("X-Cache")
+}
+
+func TestServeCacheMissesAfterEachMutator(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(p *portfolio.Portfolio) error
+		mutate func(p *portfolio.Portfolio) error
+	}{
+		{name: "SetTag", mutate: func(p *portfolio.Portfolio) error { return p.SetTag("A", portfolio.TagRegion, "Europe") }},
+		{name: "SetRatePolicy", mutate: func(p *portfolio.Portfolio) error {
+			return p.SetRatePolicy("A", &portfolio.RatePolicy{Mode: portfolio.RateReference})
+		}},
+		{name: "SetFeeSchedule", mutate: func(p *portfolio.Portfolio) error { return p.SetFeeSchedule("A", &portfolio.FeeSchedule{TER: 1}) }},
+		{name: "SetProjection", mutate: func(p *portfolio.Portfolio) error {
+			return p.SetProjection("A", portfolio.ProjectionModel{Model: portfolio.Projectors()[0]})
+		}},
+		{name: "SetConventions", mutate: func(p *portfolio.Portfolio) error {
+			return p.SetConventions(analytics.RateConventions{DayCount: analytics.DayCountActual360})
+		}},
+		{name: "AddTransaction", mutate: func(p *portfolio.Portfolio) error {
+			return p.AddTransaction("A", "2024-03-01", portfolio.Buy, 10, 10.2, 1)
+		}},
+		{name: "AddDistribution", mutate: func(p *portfolio.Portfolio) error { return p.AddDistribution("A", "2024-04-01", 20, false) }},
+		{name: "RemoveInvestment", mutate: func(p *portfolio.Portfolio) error { return p.RemoveInvestment("B") }},
+		{name: "RenameInvestment", mutate: func(p *portfolio.Portfolio) error { return p.RenameInvestment("B", "C") }},
+		{name: "CloseInvestment", mutate: func(p *portfolio.Portfolio) error { return p.CloseInvestment("B", "2024-06-01") }},
+		{
+			name:   "ReopenInvestment",
+			setup:  func(p *portfolio.Portfolio) error { return p.CloseInvestment("B", "2024-06-01") },
+			mutate: func(p *portfolio.Portfolio) error { return p.ReopenInvestment("B") },
+		},
+		{name: "SetMissingNAVPolicy", mutate: func(p *portfolio.Portfolio) error { return p.SetMissingNAVPolicy(portfolio.MissingNAVSkip) }},
+		{name: "SetRates", mutate: func(p *portfolio.Portfolio) error { p.SetRates(portfolio.NewRateTable()); return nil }},
+		{
+			name:   "Undo",
+			setup:  func(p *portfolio.Portfolio) error { return p.SetTag("A", portfolio.TagRegion, "Europe") },
+			mutate: func(p *portfolio.Portfolio) error { _, err := p.Undo(); return err },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := portfolio.NewPortfolio()
+			if err := p.AddInvestmentWithQuantity("A", 100, 10, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddNAV("A", "2024-06-01", 1050); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddInvestment("B", 2000, 3, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddNAV("B", "2024-06-01", 2030); err != nil {
+				t.Fatal(err)
+			}
+			if tt.setup != nil {
+				if err := tt.setup(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s := newServer(p, "")
+			cache, unsubscribe := newResultCache(p, 0)
+			defer unsubscribe()
+			s.cache = cache
+			handler := s.routes()
+
+			if got := projectionCache(t, handler); got != "miss" {
+				t.Fatalf("première requête: X-Cache = %q, attendu miss", got)
+			}
+			if got := projectionCache(t, handler); got != "hit" {
+				t.Fatalf("requête répétée: X-Cache = %q, attendu hit", got)
+			}
+
+			events, stop := p.Subscribe()
+			defer stop()
+			revision := p.Revision()
+			if err := tt.mutate(p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Revision() == revision {
+				t.Errorf("révision inchangée (%d)", revision)
+			}
+			select {
+			case <-events:
+			default:
+				t.Errorf("aucun événement diffusé")
+			}
+			if got := projectionCache(t, handler); got != "miss" {
+				t.Errorf("après modification: X-Cache = %q, attendu miss", got)
+			}
+		})
+	}
+}
+
+func TestServeCacheMetricTypes(t *testing.T) {
+	c, stop := newResultCache(portfolio.NewPortfolio(), 10)
+	defer stop()
+
+	var b strings.Builder
+	if err := c.writeMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE david_serve_cache_hits_total counter\n",
+		"# TYPE david_serve_cache_misses_total counter\n",
+		"# TYPE david_serve_cache_invalidations_total counter\n",
+		"# TYPE david_serve_cache_evictions_total counter\n",
+		"# TYPE david_serve_cache_entries gauge\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("%q absent de:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// server expose le portefeuille via une API REST JSON. Les lectures s'appuient
+// sur la synchronisation de Portfolio ; mu sérialise les modifications et
+// l'écriture du fichier qui les suit.
+type server struct {
+	mu        sync.Mutex
+	portfolio *portfolio.Portfolio
+	file      string        // fichier de persistance, réécrit après chaque modification
+	timeout   time.Duration // durée maximale des calculs longs (Monte-Carlo), sans limite si nulle
+	events    *valuationHub
+	tokens    *portfolio.TokenStore // Jetons d'accès, API ouverte si nil ou vide
+	cache     *resultCache          // Réponses des calculs, recalculées à chaque requête si nil
+}
+
+// newServer crée un serveur pour un portefeuille chargé depuis file
+func newServer(p *portfolio.Portfolio, file string) *server {
+	return &server{portfolio: p, file: file, events: newValuationHub()}
+}
+
+// routes déclare les endpoints de l'API
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /portfolio", s.handleGetPortfolio)
+	mux.HandleFunc("POST /investments", s.handleAddInvestment)
+	mux.HandleFunc("POST /investments/{name}/navs", s.handleAddNAV)
+	mux.HandleFunc("PUT /investments/{name}/navs/{date}", s.handleUpdateNAV)
+	mux.HandleFunc("DELETE /investments/{name}/navs/{date}", s.handleDeleteNAV)
+	mux.HandleFunc("GET /projection", s.cache.cached(s.handleProjection, nil))
+	mux.HandleFunc("GET /monte-carlo", s.cache.cached(s.handleMonteCarlo, seeded))
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("GET /reminders", s.cache.cached(s.handleReminders, nil))
+	mux.HandleFunc("GET /reminders.ics", s.cache.cached(s.handleReminders, nil))
+	return requireToken(s.tokens, mux)
+}
+
+// investmentRequest est le corps attendu par POST /investments : soit amount,
+// soit quantity et unit_price
+type investmentRequest struct {
+	Name           string             `json:"name"`
+	Amount         float64            `json:"amount"`
+	Quantity       float64            `json:"quantity"`
+	UnitPrice      float64            `json:"unit_price"`
+	ReferenceRate  float64            `json:"reference_rate"`
+	InvestmentDate string             `json:"investment_date"`
+	Currency       portfolio.Currency `json:"currency"`
+}
+
+// projectionResponse est la réponse de GET /projection
+type projectionResponse struct {
+	Date     string             `json:"date"`
+	Values   map[string]float64 `json:"values"`
+	Total    float64            `json:"total"`
+	Skipped  []string           `json:"skipped,omitempty"`  // Investissements écartés faute de NAV, absents du total
+	Warnings []string           `json:"warnings,omitempty"` // Avertissements des projections (voir ProjectionWarnings)
+}
+
+func (s *server) handleGetPortfolio(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.portfolio)
+}
+
+func (s *server) handleAddInvestment(w http.ResponseWriter, r *http.Request) {
+	var req investmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("corps de requête invalide: %w", err))
+		return
+	}
+	if req.Name == "" || req.InvestmentDate == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("name et investment_date sont obligatoires"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.addInvestment(req); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	s.respondWithInvestment(w, r, req.Name)
+}
+
+// addInvestment ajoute l'investissement décrit par req sans écraser un investissement
+// existant ; l'appelant doit détenir s.mu
+func (s *server) addInvestment(req investmentRequest) error {
+	if _, err := s.portfolio.Investment(req.Name); err == nil {
+		return fmt.Errorf("l'investissement '%s' existe déjà: %w", req.Name, portfolio.ErrInvestmentExists)
+	}
+	if err := s.portfolio.CheckCurrency(req.Currency); err != nil {
+		return err
+	}
+
+	var err error
+	if req.Quantity != 0 || req.UnitPrice != 0 {
+		err = s.portfolio.AddInvestmentWithQuantity(req.Name, req.Quantity, req.UnitPrice, req.ReferenceRate, req.InvestmentDate)
+	} else {
+		err = s.portfolio.AddInvestment(req.Name, req.Amount, req.ReferenceRate, req.InvestmentDate)
+	}
+	if err != nil {
+		return err
+	}
+	return s.portfolio.SetInvestmentCurrency(req.Name, req.Currency)
+}
+
+func (s *server) handleAddNAV(w http.ResponseWriter, r *http.Request) {
+	var nav struct {
+		Date  string          `json:"date"`
+		Value portfolio.Money `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&nav); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("corps de requête invalide: %w", err))
+		return
+	}
+	if nav.Date == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("date est obligatoire"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	if err := s.portfolio.AddNAV(name, nav.Date, nav.Value.Float64()); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	s.respondWithInvestment(w, r, name)
+}
+
+func (s *server) handleUpdateNAV(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Value portfolio.Money `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("corps de requête invalide: %w", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	if err := s.portfolio.UpdateNAV(name, r.PathValue("date"), body.Value.Float64()); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	s.respondWithInvestment(w, r, name)
+}
+
+func (s *server) handleDeleteNAV(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	if err := s.portfolio.DeleteNAV(name, r.PathValue("date")); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	s.respondWithInvestment(w, r, name)
+}
+
+func (s *server) handleProjection(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("le paramètre date est obligatoire"))
+		return
+	}
+
+	v, err := s.portfolio.GetPortfolioValuation(date)
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, projectionResponse{
+		Date: date, Values: v.Values, Total: v.Total, Skipped: v.Skipped, Warnings: s.portfolio.ProjectionWarnings(),
+	})
+}
+
+// handleReminders renvoie les rappels de la période from-to (l'année qui vient par
+// défaut), en JSON ou au format iCalendar sur /reminders.ics pour un abonnement d'agenda
+func (s *server) handleReminders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to, err := reminderPeriod(query.Get("from"), query.Get("to"))
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	reminders, err := s.portfolio.Reminders(from, to)
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	if !strings.HasSuffix(r.URL.Path, ".ics") {
+		writeJSON(w, http.StatusOK, reminders)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := portfolio.WriteICS(w, reminders, time.Now()); err != nil {
+		log.Printf("écriture des rappels: %v", err)
+	}
+}
+
+// handleMonteCarlo simule la valeur du portefeuille à une date ; la simulation est
+// abandonnée si le client se déconnecte ou si elle dépasse s.timeout
+func (s *server) handleMonteCarlo(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	date := query.Get("date")
+	if date == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("le paramètre date est obligatoire"))
+		return
+	}
+	paths := 10000
+	if raw := query.Get("paths"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, portfolio.InvalidField("paths", raw, "nombre de trajectoires invalide: %s", raw))
+			return
+		}
+		if n > portfolio.MaxMonteCarloPaths {
+			writeError(w, http.StatusBadRequest, portfolio.InvalidField("paths", n, "au plus %d trajectoires", portfolio.MaxMonteCarloPaths))
+			return
+		}
+		paths = n
+	}
+	opts := portfolio.MonteCarloOptions{Method: portfolio.MonteCarloMethod(query.Get("method"))}
+	if raw := query.Get("seed"); raw != "" {
+		seed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, portfolio.InvalidField("seed", raw, "graine invalide: %s", raw))
+			return
+		}
+		opts.Seed = seed
+	}
+	if raw := query.Get("block"); raw != "" {
+		block, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, portfolio.InvalidField("block", raw, "longueur de bloc invalide: %s", raw))
+			return
+		}
+		if block > portfolio.MaxMonteCarloPaths {
+			writeError(w, http.StatusBadRequest, portfolio.InvalidField("block", block, "blocs d'au plus %d rendements", portfolio.MaxMonteCarloPaths))
+			return
+		}
+		opts.Block = block
+	}
+
+	ctx := r.Context()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	result, err := s.portfolio.MonteCarloProjectContext(ctx, date, paths, opts)
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// seeded indique si une simulation de Monte-Carlo est reproductible, donc mise en cache
+func seeded(r *http.Request) bool {
+	return r.URL.Query().Get("seed") != ""
+}
+
+// handleMetrics expose les jauges du portefeuille, puis celles du cache
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	if err := s.portfolio.WriteMetrics(&b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.cache.writeMetrics(&b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", portfolio.MetricsContentType)
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		log.Printf("écriture des métriques: %v", err)
+	}
+}
+
+// respondWithInvestment persiste le portefeuille au nom de l'auteur de la requête puis
+// renvoie l'investissement modifié ; l'appelant doit détenir s.mu
+func (s *server) respondWithInvestment(w http.ResponseWriter, r *http.Request, name string) {
+	if err := s.persist(requestActor(r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	inv, err := s.portfolio.Investment(name)
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+// persist enregistre le portefeuille, audité au nom de actor, et diffuse la nouvelle
+// valorisation sur /events ; l'appelant doit détenir s.mu
+func (s *server) persist(actor portfolio.AuditActor) error {
+	if s.file != "" {
+		s.portfolio.SetAuditActor(actor)
+		if err := s.portfolio.SaveJSON(s.file); err != nil {
+			return err
+		}
+	}
+	if err := s.events.publish(s.portfolio); err != nil {
+		log.Printf("diffusion de la valorisation: %v", err)
+	}
+	return nil
+}
+
+// writeJSON écrit une réponse JSON avec le statut donné
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("écriture de la réponse: %v", err)
+	}
+}
+
+// writeError écrit une erreur sous la forme {"error": "..."}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// statusForError traduit les erreurs sentinelles en codes HTTP
+func statusForError(err error) int {
+	var ve *portfolio.ValidationError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, portfolio.ErrInvestmentExists), errors.Is(err, portfolio.ErrDuplicateNAV), errors.Is(err, portfolio.ErrAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, portfolio.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, portfolio.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, portfolio.ErrInvestmentNotFound), errors.Is(err, portfolio.ErrNAVNotFound), errors.Is(err, portfolio.ErrNoNAV),
+		errors.Is(err, portfolio.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, portfolio.ErrInvalidAmount), errors.Is(err, portfolio.ErrInsufficientHistory), errors.Is(err, portfolio.ErrRateNotFound),
+		errors.Is(err, portfolio.ErrInvalidDate), errors.Is(err, portfolio.ErrPeriodTooShort), errors.Is(err, portfolio.ErrHorizonTooLong), errors.As(err, &ve):
+		return http.StatusBadRequest
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+func runServe(args []string) error {
+	fs, file := newFlagSet("serve")
+	serveConfig := portfolio.ActiveConfig().Serve
+	if serveConfig.Addr == "" {
+		serveConfig.Addr = ":8080"
+	}
+	if serveConfig.Timeout == 0 {
+		serveConfig.Timeout = 30 * time.Second
+	}
+	addr := fs.String("addr", serveConfig.Addr, "adresse d'écoute HTTP")
+	grpcAddr := fs.String("grpc-addr", serveConfig.GRPCAddr, "adresse d'écoute de l'API gRPC (proto/david.proto), désactivée si vide")
+	refreshEvery := fs.Duration("refresh-every", serveConfig.RefreshEvery, "intervalle de mise à jour des NAV depuis le fournisseur de cours (désactivée si nul)")
+	baseURL := fs.String("provider-url", portfolio.ActiveConfig().Quotes.ProviderURL, "adresse de l'API Yahoo Finance (par défaut l'adresse publique)")
+	timeout := fs.Duration("timeout", serveConfig.Timeout, "durée maximale d'un calcul long (Monte-Carlo), sans limite si nulle")
+	cacheEntries := fs.Int("cache-entries", serveConfig.CacheEntries, "réponses de calcul conservées en cache (256 si nul, cache désactivé si négatif)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	s := newServer(p, *file)
+	s.timeout = *timeout
+	if *cacheEntries >= 0 {
+		cache, unsubscribe := newResultCache(p, *cacheEntries)
+		defer unsubscribe()
+		s.cache = cache
+	}
+	if s.tokens, err = portfolio.LoadTokens(tokensFile(*file)); err != nil {
+		return err
+	}
+	if !s.tokens.Enabled() {
+		log.Printf("aucun jeton d'API (commande token-add) : l'API est ouverte à tous")
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errCh := make(chan error, 3)
+	go func() {
+		log.Printf("API disponible sur %s", *addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	if *grpcAddr != "" {
+		grpcSrv := s.grpcServer(*grpcAddr)
+		go func() {
+			log.Printf("API gRPC disponible sur %s", *grpcAddr)
+			errCh <- grpcSrv.ListenAndServe()
+		}()
+		defer grpcSrv.Close()
+	}
+	if *refreshEvery > 0 {
+		p.SetQuoteProvider(portfolio.YahooQuoteProvider{BaseURL: *baseURL})
+		w := &report.Watcher{
+			Portfolio: p,
+			Every:     *refreshEvery,
+			Timeout:   time.Minute,
+			Save: func() error {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				return s.persist(portfolio.CommandActor("serve"))
+			},
+		}
+		go func() { errCh <- w.Run(ctx) }()
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
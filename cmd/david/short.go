@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runSetExposure(args []string) error {
+	fs, file := newFlagSet("set-exposure")
+	name := fs.String("name", "", "nom de l'investissement")
+	exposure := fs.String("exposure", "", "sens de la position (long, short, signed)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	e, err := portfolio.ParseExposure(*exposure)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetExposure(*name, e); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runExposure(args []string) error {
+	fs, file := newFlagSet("exposure")
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date de valorisation (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	long, short, err := p.GrossExposure(*date)
+	if err != nil {
+		return err
+	}
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("Exposition au %s\n", *date)
+	fmt.Printf("  Acheteuse:  %s\n", amount(long))
+	fmt.Printf("  Vendeuse:   %s\n", amount(short))
+	fmt.Printf("  Nette:      %s\n", amount(long+short))
+	fmt.Printf("  Brute:      %s\n", amount(long-short))
+	if net := long + short; net != 0 {
+		fmt.Printf("  Levier:     %.2f\n", (long-short)/net)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSnapshot(args []string) error {
+	fs, file := newFlagSet("snapshot")
+	label := fs.String("label", "", "libellé de l'instantané (date du jour si vide)")
+	remove := fs.Bool("delete", false, "supprime l'instantané --label au lieu de le créer")
+	list := fs.Bool("list", false, "liste les instantanés enregistrés")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	switch {
+	case *list:
+		for _, l := range p.SnapshotLabels() {
+			s, _ := p.LookupSnapshot(l)
+			fmt.Printf("%-20s %s  %.2f %s\n", l, s.Taken.Local().Format(time.DateTime), s.TotalValue, s.BaseCurrency)
+		}
+		return nil
+	case *remove:
+		if err := p.DeleteSnapshot(*label); err != nil {
+			return err
+		}
+		fmt.Printf("Instantané %s supprimé\n", *label)
+	default:
+		s, err := p.Snapshot(*label)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Instantané %s enregistré: %.2f %s\n", s.Label, s.TotalValue, s.BaseCurrency)
+	}
+	return p.SaveJSON(*file)
+}
+
+func runDiff(args []string) error {
+	fs, file := newFlagSet("diff")
+	from := fs.String("from", "", "instantané de départ")
+	to := fs.String("to", portfolio.SnapshotNow, "instantané d'arrivée (l'état courant par défaut)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return fmt.Errorf("--from est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	d, err := p.DiffSnapshots(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== %s → %s ===\n", d.From, d.To)
+	fmt.Printf("Variation de valeur: %+.2f %s (flux: %+.2f)", d.ValueChange, d.Currency, d.Flows)
+	if d.Return != nil {
+		fmt.Printf(", rendement: %+.2f%%", *d.Return)
+	}
+	fmt.Println()
+	for _, list := range []struct {
+		title string
+		names []string
+	}{{"Nouveaux investissements", d.Added}, {"Investissements retirés", d.Removed}, {"Investissements clôturés", d.Closed}} {
+		if len(list.names) > 0 {
+			fmt.Printf("%s: %v\n", list.title, list.names)
+		}
+	}
+	for _, inv := range d.Investments {
+		fmt.Printf("%s: %+.2f %s", inv.Name, inv.ValueChange, d.Currency)
+		if inv.Return != nil {
+			fmt.Printf(" (%+.2f%%)", *inv.Return)
+		}
+		if inv.NewNAVs > 0 {
+			fmt.Printf(", %d NAV ajoutée(s)", inv.NewNAVs)
+			if inv.NewSince != "" {
+				fmt.Printf(" après le %s", inv.NewSince)
+			}
+			fmt.Printf(" jusqu'au %s", inv.NewUntil)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// createStatementInvestment retourne une fonction Resolve qui crée l'investissement d'un
+// titre inconnu à partir de son premier achat dans le relevé (parts, prix, date, devise
+// du compte et identifiants), nommé d'après le titre. Les comptes et titres sans achat
+// sont confiés à next, ou ignorés si next est nil.
+func createStatementInvestment(p *portfolio.Portfolio, st *portfolio.Statement, next func(string, portfolio.StatementSecurity) (portfolio.StatementRule, error)) func(string, portfolio.StatementSecurity) (portfolio.StatementRule, error) {
+	return func(account string, security portfolio.StatementSecurity) (portfolio.StatementRule, error) {
+		var first *portfolio.StatementEntry
+		var currency portfolio.Currency
+		for _, acct := range st.Accounts {
+			if acct.ID != account {
+				continue
+			}
+			for i, e := range acct.Entries {
+				if e.Kind == portfolio.StatementBuy && e.Security == security && e.Units > 0 && (first == nil || e.Date.Before(first.Date)) {
+					first, currency = &acct.Entries[i], acct.Currency
+				}
+			}
+		}
+		if first == nil || security.String() == "" {
+			if next != nil {
+				return next(account, security)
+			}
+			return portfolio.StatementRule{Account: account, Security: security.String(), Ignore: true}, nil
+		}
+
+		name := portfolio.DefaultString(security.Name, security.String())
+		if err := p.AddInvestmentWithQuantity(name, first.Units.Float64(), first.Price.Float64(), 0, portfolio.FormatDate(first.Date)); err != nil {
+			return portfolio.StatementRule{}, err
+		}
+		ids := portfolio.InvestmentIdentifiers{Ticker: strings.ReplaceAll(security.Ticker, " ", "")}
+		if portfolio.ValidISIN(strings.ToUpper(security.ID)) {
+			ids.ISIN = security.ID
+		}
+		if err := p.SetIdentifiers(name, ids); err != nil {
+			return portfolio.StatementRule{}, err
+		}
+		if currency != "" {
+			if err := p.SetInvestmentCurrency(name, currency); err != nil {
+				return portfolio.StatementRule{}, err
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Investissement créé: %s (%s parts au %s)\n", name, first.Units, portfolio.FormatDate(first.Date))
+		return portfolio.StatementRule{Account: account, Security: security.String(), Investment: name}, nil
+	}
+}
+
+// promptStatementRule demande sur le terminal l'investissement d'un compte ou d'un titre
+// inconnu, désigné par son nom ou l'un de ses identifiants, jusqu'à une réponse valide
+func promptStatementRule(p *portfolio.Portfolio, in *bufio.Reader, out io.Writer) func(string, portfolio.StatementSecurity) (portfolio.StatementRule, error) {
+	return func(account string, security portfolio.StatementSecurity) (portfolio.StatementRule, error) {
+		rule := portfolio.StatementRule{Account: account, Security: security.String()}
+		label := "compte " + account
+		if security.String() != "" {
+			label = fmt.Sprintf("titre %s du compte %s", security.String(), account)
+			if security.Name != "" && security.Name != security.String() {
+				label += " (" + security.Name + ")"
+			}
+		}
+		for {
+			fmt.Fprintf(out, "Investissement pour le %s (vide pour ignorer) : ", label)
+			answer, err := in.ReadString('\n')
+			if err != nil && answer == "" {
+				return portfolio.StatementRule{}, fmt.Errorf("saisie interrompue: %w", err)
+			}
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				rule.Ignore = true
+				return rule, nil
+			}
+			inv, err := p.Lookup(answer)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			rule.Investment = inv.Name
+			return rule, nil
+		}
+	}
+}
+
+func runImportStatement(args []string) error {
+	registerPluginImporters()
+	fs, file := newFlagSet("import-statement")
+	input := fs.String("input", "", "relevé OFX, QIF, export de courtier ou de logiciel de suivi")
+	format := fs.String("format", "", "format du relevé ("+strings.Join(portfolio.Importers(), ", ")+"), détecté d'après le contenu si vide")
+	account := fs.String("account", "", "compte des opérations dont le relevé ne précise pas le compte (par défaut le format)")
+	dates := fs.String("dates", "dmy", "ordre des dates ambiguës (dmy, mdy)")
+	interactive := fs.Bool("interactive", false, "demander l'investissement des comptes et titres inconnus")
+	save := fs.Bool("save-rules", false, "conserver dans le portefeuille les réponses du mode interactif")
+	create := fs.Bool("create", false, "créer les investissements des titres inconnus à partir de leur premier achat")
+	dryRun := fs.Bool("dry-run", false, "afficher le bilan sans rien enregistrer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return fmt.Errorf("--input est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if *dates != "dmy" && *dates != "mdy" {
+		return portfolio.InvalidField("dates", *dates, "ordre des dates inconnu: %s (dmy, mdy)", *dates)
+	}
+	st, err := portfolio.ParseStatement(f, *format, portfolio.StatementOptions{Account: *account, MonthFirst: *dates == "mdy"})
+	if err != nil {
+		return err
+	}
+
+	opts := portfolio.StatementImportOptions{DryRun: *dryRun}
+	var answers []portfolio.StatementRule
+	if *interactive {
+		prompt := promptStatementRule(p, bufio.NewReader(os.Stdin), os.Stderr)
+		opts.Resolve = func(account string, security portfolio.StatementSecurity) (portfolio.StatementRule, error) {
+			r, err := prompt(account, security)
+			if err == nil {
+				answers = append(answers, r)
+			}
+			return r, err
+		}
+	}
+	if *create {
+		if *dryRun {
+			return fmt.Errorf("--create et --dry-run sont incompatibles")
+		}
+		opts.Resolve = createStatementInvestment(p, st, opts.Resolve)
+	}
+	report, err := p.ImportStatement(st, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d transaction(s), %d flux, %d distribution(s), %d NAV importés\n", report.Transactions, report.CashFlows, report.Distributions, report.NAVs)
+	if report.Duplicates > 0 {
+		fmt.Printf("%d opération(s) déjà présente(s)\n", report.Duplicates)
+	}
+	if report.Ignored > 0 {
+		fmt.Printf("%d opération(s) ignorée(s) par les règles\n", report.Ignored)
+	}
+	for _, key := range report.Unmapped {
+		fmt.Printf("Non rattaché (ajouter une règle ou utiliser --interactive): %s\n", key)
+	}
+	for _, e := range report.Errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	if *save && len(answers) > 0 {
+		if err := p.AddStatementRules(answers...); err != nil {
+			return err
+		}
+	}
+	return p.SaveJSON(*file)
+}
+
+func runAddStatementRule(args []string) error {
+	fs, file := newFlagSet("add-statement-rule")
+	account := fs.String("account", "", "compte du relevé (motif, ex. FR76*), tous si vide")
+	security := fs.String("security", "", "identifiant, ticker ou nom du titre (motif), tous si vide")
+	investment := fs.String("investment", "", "investissement destinataire")
+	ignore := fs.Bool("ignore", false, "ne pas importer les opérations concernées")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *account == "" && *security == "" {
+		return fmt.Errorf("--account ou --security est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddStatementRules(portfolio.StatementRule{Account: *account, Security: *security, Investment: *investment, Ignore: *ignore}); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runTag(args []string) error {
+	fs, file := newFlagSet("tag")
+	name := fs.String("name", "", "nom de l'investissement")
+	tag := fs.String("tag", portfolio.TagAssetClass, "étiquette (asset_class, region ou label libre)")
+	value := fs.String("value", "", "valeur de l'étiquette (vide pour la supprimer)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetTag(*name, *tag, *value); err != nil {
+		return err
+	}
+
+	return p.SaveJSON(*file)
+}
+
+func runAllocation(args []string) error {
+	fs, file := newFlagSet("allocation")
+	tag := fs.String("tag", portfolio.TagAssetClass, "étiquette de regroupement")
+	date := fs.String("date", "", "date de valorisation (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" {
+		return fmt.Errorf("--date est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	allocation, err := p.AllocationByTag(*tag, *date)
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(allocation))
+	for label := range allocation {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	fmt.Printf("=== RÉPARTITION PAR %s AU %s ===\n\n", *tag, *date)
+	for _, label := range labels {
+		fmt.Printf("%s: %.2f%%\n", label, allocation[label])
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// printNetOfTaxProjection affiche la projection avant et après impôts
+func printNetOfTaxProjection(p *portfolio.Portfolio, date string) error {
+	lines, total, err := p.NetOfTaxProjection(date)
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("\n=== APRÈS IMPÔTS (retrait total au %s) ===\n", date)
+	for _, l := range lines {
+		fmt.Printf("%-20s %-14s %5.1f ans  brut %s  gain %s  impôts %s  net %s\n",
+			l.Name, l.Wrapper, l.Years, amount(l.Gross), amount(l.Gain), amount(l.Tax), amount(l.Net))
+	}
+	fmt.Printf("Total: brut %s, impôts %s, net %s\n", amount(total.Gross), amount(total.Tax), amount(total.Net))
+	return nil
+}
+
+func runSetTaxWrapper(args []string) error {
+	fs, file := newFlagSet("set-tax-wrapper")
+	name := fs.String("name", "", "nom de l'investissement (vide : enveloppe par défaut du compte)")
+	wrapper := fs.String("wrapper", "", "enveloppe (cto, pea, assurance-vie, exonere)")
+	opened := fs.String("opened", "", "date d'ouverture de l'enveloppe du compte (AAAA-MM-JJ, sans --name)")
+	couple := fs.Bool("couple", false, "imposition commune, abattement de l'assurance-vie doublé (sans --name)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if *name != "" {
+		err = p.SetTaxWrapper(*name, portfolio.TaxWrapper(*wrapper))
+	} else {
+		err = p.SetTaxSettings(portfolio.TaxSettings{Wrapper: portfolio.TaxWrapper(*wrapper), Opened: *opened, Couple: *couple})
+	}
+	if err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runGains(args []string) error {
+	fs, file := newFlagSet("gains")
+	year := fs.Int("year", portfolio.ReportingYear().YearOf(portfolio.Today())-1, "année des cessions (année de début d'un exercice décalé, voir year_start)")
+	method := fs.String("method", string(portfolio.CostAverage), "méthode de prix de revient (average, fifo)")
+	format := fs.String("format", "text", "format de sortie (text, csv)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	r, err := p.RealizedGainsReport(*year, portfolio.CostMethod(*method))
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "csv":
+		return r.WriteCSV(os.Stdout)
+	case "text":
+	default:
+		return fmt.Errorf("format de sortie inconnu: %s", *format)
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("=== PLUS-VALUES %s (%s) ===\n", r.Period.Label(r.Year), r.Method)
+	for _, s := range r.Sales {
+		fmt.Printf("%s  %-20s %10s parts  cession %s  revient %s  %s\n",
+			portfolio.FormatDate(s.Date), s.Investment, s.Units, amount(s.Proceeds.Float64()), amount(s.Cost.Float64()), amount(s.Gain.Float64()))
+	}
+	fmt.Printf("\nPrix de cession: %s\nPrix de revient: %s\n", amount(r.Proceeds.Float64()), amount(r.Cost.Float64()))
+	fmt.Printf("Plus-values: %s, moins-values: %s, solde: %s\n", amount(r.Gains.Float64()), amount(r.Losses.Float64()), amount(r.Net.Float64()))
+	return nil
+}
+
+func runLots(args []string) error {
+	fs, file := newFlagSet("lots")
+	name := fs.String("name", "", "nom de l'investissement")
+	method := fs.String("method", string(portfolio.CostFIFO), "méthode de prix de revient (average, fifo)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+	lots, _, err := inv.TaxLots(portfolio.CostMethod(*method))
+	if err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		acquired := "PMP"
+		if !lot.Acquired.IsZero() {
+			acquired = portfolio.FormatDate(lot.Acquired)
+		}
+		fmt.Printf("%-10s %10s parts  revient %s (%s/part)\n", acquired, lot.Units, lot.Cost, portfolio.NewPrice(lot.Cost.Float64()/lot.Units.Float64()))
+	}
+	return nil
+}
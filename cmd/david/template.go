@@ -0,0 +1,19 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// renderTemplateFile écrit le rapport mis en forme par le gabarit d'un fichier
+func renderTemplateFile(p *portfolio.Portfolio, w io.Writer, path string, opts report.ReportOptions) error {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return report.RenderTemplate(p, w, filepath.Base(path), string(text), opts)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+)
+
+func runTrend(args []string) error {
+	fs, file := newFlagSet("trend")
+	name := fs.String("name", "", "nom de l'investissement")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+	fit, err := inv.TrendRate()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Taux de tendance: %.2f%% (R² %.3f, erreur type %.2f pts, %d NAV)\n", fit.Rate, fit.RSquared, fit.SlopeError, fit.Points)
+	if rate, err := inv.CalculatePerformanceRate(); err == nil {
+		fmt.Printf("Taux entre première et dernière NAV: %.2f%%\n", rate)
+	}
+	return nil
+}
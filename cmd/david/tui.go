@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// Touches reconnues par le tableau de bord
+const (
+	keyUp = iota + utf8.MaxRune + 1
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyInterrupt // Ctrl-C
+)
+
+// sparkBlocks sont les niveaux des graphiques miniatures, du plus bas au plus haut
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline résume une série en au plus width caractères : les dernières valeurs,
+// chacune représentée par un bloc proportionnel à sa position entre le minimum et le
+// maximum affichés
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := len(sparkBlocks) / 2
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// dashboard est l'état du tableau de bord interactif
+type dashboard struct {
+	p        *portfolio.Portfolio
+	file     string
+	in       *bufio.Reader
+	out      io.Writer
+	names    []string
+	selected int
+	date     time.Time // Date de projection
+	message  string    // Dernier message affiché en bas d'écran
+}
+
+// run affiche le tableau de bord et traite les touches jusqu'à 'q'
+func (d *dashboard) run() error {
+	for {
+		d.names = d.p.InvestmentNames()
+		d.selected = min(d.selected, max(len(d.names)-1, 0))
+		d.render()
+
+		key, err := d.readKey()
+		if err != nil {
+			return err
+		}
+		d.message = ""
+		switch key {
+		case 'q', keyEscape, keyInterrupt:
+			return nil
+		case keyUp, 'k':
+			d.selected = max(d.selected-1, 0)
+		case keyDown, 'j':
+			d.selected = min(d.selected+1, max(len(d.names)-1, 0))
+		case keyLeft, 'h':
+			d.date = d.date.AddDate(-1, 0, 0)
+		case keyRight, 'l':
+			d.date = d.date.AddDate(1, 0, 0)
+		case 'd':
+			d.promptDate()
+		case 'n':
+			d.promptNAV()
+		}
+	}
+}
+
+// render redessine l'écran : tableau des investissements, puis projection
+func (d *dashboard) render() {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\r\n", args...)
+	}
+
+	amount := report.AmountFormatter(d.p).Format
+	summary, err := d.p.Summary()
+	if err != nil {
+		line("Erreur: %v", err)
+	} else {
+		line("\x1b[1mdavid — %s\x1b[0m    investi %s, valeur %s", d.file, amount(summary.TotalInvested), amount(summary.TotalValue))
+		line("")
+		line("\x1b[1m  %-20s %14s %-10s %9s %14s  %s\x1b[0m", "Investissement", "Dernière NAV", "Date", "Perf.", "Valeur", "Historique")
+		for i, s := range summary.Investments {
+			nav, date, perf := "-", "-", "-"
+			if s.LatestNAV != nil {
+				nav = portfolio.AmountFormatter{Currency: s.Currency, Locale: report.AmountFormatter(d.p).Locale}.Format(s.LatestNAV.Value.Float64())
+				date = portfolio.FormatDate(s.LatestNAV.Date.Time)
+			}
+			if s.PerformanceRate != nil {
+				perf = fmt.Sprintf("%.2f%%", *s.PerformanceRate)
+			}
+			var history []float64
+			if inv, err := d.p.Investment(s.Name); err == nil {
+				for _, n := range inv.NAVHistory {
+					history = append(history, n.Value.Float64())
+				}
+			}
+			row := fmt.Sprintf("  %-20s %14s %-10s %9s %14s  %s", report.Truncate(s.Name, 20), nav, date, perf, amount(s.Value), sparkline(history, 24))
+			if s.Closed {
+				row += " (clôturé)"
+			}
+			if i == d.selected {
+				row = "\x1b[7m" + row + "\x1b[0m"
+			}
+			line("%s", row)
+		}
+	}
+
+	line("")
+	line("\x1b[1mProjection au %s\x1b[0m", portfolio.FormatDate(d.date))
+	values, total, err := d.p.GetPortfolioValue(portfolio.FormatDate(d.date))
+	if err != nil {
+		line("  %v", err)
+	} else {
+		for _, name := range d.names {
+			if v, ok := values[name]; ok {
+				line("  %-20s %14s", report.Truncate(name, 20), amount(v))
+			}
+		}
+		line("  %-20s %14s", "Total", amount(total))
+	}
+
+	line("")
+	if d.message != "" {
+		line("%s", d.message)
+	}
+	b.WriteString("↑/↓ sélection  ←/→ projection ∓1 an  d date de projection  n ajouter une NAV  q quitter")
+	fmt.Fprint(d.out, b.String())
+}
+
+// promptDate demande une nouvelle date de projection
+func (d *dashboard) promptDate() {
+	input, ok := d.readLine("Date de projection (AAAA-MM-JJ): ")
+	if !ok || input == "" {
+		return
+	}
+	t, err := portfolio.ParseDate(input)
+	if err != nil {
+		d.message = err.Error()
+		return
+	}
+	d.date = t
+}
+
+// promptNAV demande une NAV pour l'investissement sélectionné, l'enregistre puis
+// sauvegarde le portefeuille
+func (d *dashboard) promptNAV() {
+	if len(d.names) == 0 {
+		return
+	}
+	name := d.names[d.selected]
+	date, ok := d.readLine(fmt.Sprintf("NAV de %s — date (AAAA-MM-JJ, vide pour aujourd'hui): ", name))
+	if !ok {
+		return
+	}
+	if date == "" {
+		date = portfolio.FormatDate(time.Now())
+	}
+	input, ok := d.readLine(fmt.Sprintf("NAV de %s au %s — valeur: ", name, date))
+	if !ok {
+		return
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(input, ",", "."), 64)
+	if err != nil {
+		d.message = fmt.Sprintf("valeur invalide: %s", input)
+		return
+	}
+	if err := d.p.AddNAV(name, date, value); err != nil {
+		d.message = err.Error()
+		return
+	}
+	if err := d.p.SaveJSON(d.file); err != nil {
+		d.message = err.Error()
+		return
+	}
+	d.message = fmt.Sprintf("NAV de %s au %s enregistrée", name, date)
+}
+
+// readLine lit une saisie en bas d'écran ; Échap l'annule
+func (d *dashboard) readLine(prompt string) (string, bool) {
+	var input []rune
+	for {
+		fmt.Fprintf(d.out, "\r\x1b[K%s%s", prompt, string(input))
+		key, err := d.readKey()
+		if err != nil {
+			return "", false
+		}
+		switch key {
+		case keyEnter:
+			return strings.TrimSpace(string(input)), true
+		case keyEscape, keyInterrupt:
+			return "", false
+		case keyBackspace:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		default:
+			if key >= ' ' && key <= utf8.MaxRune {
+				input = append(input, rune(key))
+			}
+		}
+	}
+}
+
+// readKey lit une touche du tableau de bord
+func (d *dashboard) readKey() (int, error) {
+	return readKey(d.in)
+}
+
+// readKey lit une touche, en décodant les séquences d'échappement des flèches
+func readKey(in *bufio.Reader) (int, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch r {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 0x7f, '\b':
+		return keyBackspace, nil
+	case 0x03:
+		return keyInterrupt, nil
+	case 0x1b:
+		if in.Buffered() < 2 {
+			return keyEscape, nil
+		}
+		if next, _ := in.ReadByte(); next != '[' && next != 'O' {
+			return keyEscape, nil
+		}
+		code, _ := in.ReadByte()
+		switch code {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		}
+		return keyEscape, nil
+	}
+	return int(r), nil
+}
+
+func runTUI(args []string) error {
+	fs, file := newFlagSet("tui")
+	date := fs.String("date", "", "date de projection initiale (AAAA-MM-JJ, dans un an si vide)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	projection := time.Now().AddDate(1, 0, 0)
+	if *date != "" {
+		if projection, err = portfolio.ParseDate(*date); err != nil {
+			return err
+		}
+	}
+
+	// Mode brut (touche par touche, sans écho) le temps de la session ; stty est
+	// disponible sur les systèmes de type Unix
+	previous, err := stty("raw", "-echo")
+	if err != nil {
+		return err
+	}
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		stty(previous)
+	}()
+
+	d := &dashboard{p: p, file: *file, in: bufio.NewReader(os.Stdin), out: os.Stdout, date: projection}
+	return d.run()
+}
+
+// stty applique des réglages au terminal de l'entrée standard et retourne les
+// réglages précédents
+func stty(args ...string) (string, error) {
+	saved := exec.Command("stty", "-g")
+	saved.Stdin = os.Stdin
+	previous, err := saved.Output()
+	if err != nil {
+		return "", fmt.Errorf("l'entrée standard n'est pas un terminal: %w", err)
+	}
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(previous)), nil
+}
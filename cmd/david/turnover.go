@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runTurnover(args []string) error {
+	fs, file := newFlagSet("turnover")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	r, err := p.Turnover()
+	if err != nil {
+		return err
+	}
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Println("=== ROTATION DU PORTEFEUILLE ===")
+	fmt.Printf("%-8s %16s %16s %8s %14s %16s %9s\n", "Année", "Achats", "Ventes", "Ordres", "Frais", "Valeur moyenne", "Rotation")
+	var trades int
+	var costs portfolio.Money
+	for _, y := range r.Years {
+		fmt.Printf("%-8s %16s %16s %8d %14s %16s %8.1f%%\n", r.Period.Label(y.Year), amount(y.Buys.Float64()), amount(y.Sells.Float64()),
+			y.Trades, amount(y.Costs.Float64()), amount(y.AverageValue), y.Turnover)
+		trades += y.Trades
+		costs += y.Costs
+	}
+	fmt.Printf("\n%d ordre(s), %s de frais de transaction\n", trades, amount(costs.Float64()))
+	if n := len(r.Years); n > 0 && r.Years[n-1].Turnover > 100 {
+		fmt.Println("Plus de 100 % de rotation cette année : le portefeuille est entièrement renouvelé en moins d'un an")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runValidate(args []string) error {
+	fs, file := newFlagSet("validate")
+	maxJump := fs.Float64("max-jump", portfolio.DefaultMaxNAVJump, "variation maximale entre deux NAV successives (%)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	issues := p.Validate(*maxJump)
+	if len(issues) == 0 {
+		fmt.Println("Aucune incohérence détectée")
+		return nil
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	return fmt.Errorf("%d incohérence(s) détectée(s)", len(issues))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func runVaR(args []string) error {
+	fs, file := newFlagSet("var")
+	confidence := fs.Float64("confidence", 0.95, "niveau de confiance (ex. 0.95)")
+	days := fs.Int("horizon", 30, "horizon (jours)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== VALEUR EN RISQUE À %.0f%% SUR %d JOURS ===\n\n", *confidence*100, *days)
+	result, err := p.VaR(*confidence, time.Duration(*days)*24*time.Hour)
+	if err != nil {
+		fmt.Printf("VaR non calculable: %v\n", err)
+	} else {
+		fmt.Printf("Valeur actuelle: %.2f€\n", result.Value)
+		fmt.Printf("VaR historique: %.2f€\n", result.Historical)
+		fmt.Printf("VaR paramétrique: %.2f€\n", result.Parametric)
+	}
+
+	results, err := p.StressTests()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("=== TESTS DE RÉSISTANCE ===")
+	fmt.Println()
+	for _, r := range results {
+		fmt.Printf("%s: perte %.2f€ (%.2f%%)\n", r.Scenario, r.Loss, r.LossPercent)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runSetVesting(args []string) error {
+	fs, file := newFlagSet("set-vesting")
+	name := fs.String("name", "", "nom de l'investissement")
+	grant := fs.String("grant", "", "date d'attribution (AAAA-MM-JJ)")
+	cliff := fs.Int("cliff", 12, "période de blocage (mois)")
+	every := fs.Int("every", 3, "périodicité des acquisitions (mois)")
+	months := fs.Int("months", 48, "durée totale d'acquisition (mois, 0 pour supprimer le calendrier)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || (*grant == "" && *months != 0) {
+		return fmt.Errorf("--name et --grant sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.SetVesting(*name, *grant, *cliff, *every, *months); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runVesting(args []string) error {
+	fs, file := newFlagSet("vesting")
+	name := fs.String("name", "", "nom de l'investissement")
+	date := fs.String("date", portfolio.FormatDate(time.Now()), "date de la situation (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	inv, err := p.Investment(*name)
+	if err != nil {
+		return err
+	}
+	t, err := portfolio.ParseDate(*date)
+	if err != nil {
+		return err
+	}
+	events, err := inv.VestEvents(t)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Acquis au %s: %.1f%%\n", *date, math.Round(inv.VestedFraction(t)*1000)/10)
+	if len(events) == 0 {
+		return nil
+	}
+	fmt.Println("Acquisitions à venir:")
+	for _, e := range events {
+		fmt.Printf("  %s  %5.1f%%  %s\n", portfolio.FormatDate(e.Date), e.Fraction*100, e.Value)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runWatch(args []string) error {
+	fs, file := newFlagSet("watch")
+	every := fs.Duration("every", 24*time.Hour, "intervalle entre deux mises à jour")
+	timeout := fs.Duration("timeout", time.Minute, "délai maximal d'une mise à jour")
+	baseURL := fs.String("provider-url", "", "adresse de l'API Yahoo Finance (par défaut l'adresse publique)")
+	metricsAddr := fs.String("metrics-addr", "", "adresse d'écoute exposant /metrics pour Prometheus (désactivé si vide)")
+	mail := smtpFlags(fs)
+	notifiers := alertNotifierFlags(fs, mail)
+	reportFormat := fs.String("mail-report", "", "envoie chaque mois le rapport par courriel (html, pdf), désactivé si vide")
+	reportDay := fs.Int("report-day", 1, "jour du mois à partir duquel le rapport mensuel est envoyé (1 à 28)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var mailer *report.ReportMailer
+	if *reportFormat != "" {
+		if mailer, err = newReportMailer(mail(), *reportFormat, *reportDay, *file); err != nil {
+			return err
+		}
+	}
+	p.SetQuoteProvider(portfolio.YahooQuoteProvider{BaseURL: *baseURL})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := &report.Watcher{
+		Portfolio: p,
+		Every:     *every,
+		Timeout:   *timeout,
+		Save:      func() error { return p.SaveJSON(*file) },
+		Notifiers: notifiers(),
+		Report:    mailer,
+	}
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", portfolio.MetricsHandler(p))
+		srv := &http.Server{Addr: *metricsAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			log.Printf("métriques disponibles sur %s/metrics", *metricsAddr)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("serveur de métriques: %v", err)
+				stop()
+			}
+		}()
+		defer srv.Close()
+	}
+	log.Printf("surveillance de %s toutes les %s", *file, *every)
+	return w.Run(ctx)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runWatchAdd(args []string) error {
+	fs, file := newFlagSet("watch-add")
+	name := fs.String("name", "", "nom du titre suivi")
+	identifier := fs.String("identifier", "", "ISIN ou ticker pour la mise à jour des cours (refresh)")
+	currency := fs.String("currency", "", "devise de cotation (EUR par défaut)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.Watch(*name, *identifier, portfolio.Currency(strings.ToUpper(*currency))); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runWatchRemove(args []string) error {
+	fs, file := newFlagSet("watch-remove")
+	name := fs.String("name", "", "nom du titre suivi")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.Unwatch(*name); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runWatchPrice(args []string) error {
+	fs, file := newFlagSet("watch-price")
+	name := fs.String("name", "", "nom du titre suivi")
+	date := fs.String("date", "", "date du cours (AAAA-MM-JJ)")
+	price := fs.Float64("price", 0, "cours unitaire")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *date == "" {
+		return fmt.Errorf("--name et --date sont obligatoires")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	if err := p.AddWatchPrice(*name, *date, *price); err != nil {
+		return err
+	}
+	return p.SaveJSON(*file)
+}
+
+func runWatchlist(args []string) error {
+	fs, file := newFlagSet("watchlist")
+	query := queryFlags(fs)
+	held := fs.Bool("held", false, "inclut les investissements détenus dans le classement")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts, err := query()
+	if err != nil {
+		return err
+	}
+	opts.IncludeWatchlist = true
+	if !*held {
+		opts.Filters = append(opts.Filters, func(r portfolio.QueryResult) bool { return r.Watched })
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	results, err := p.Query(opts)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		marker := " "
+		if r.Watched {
+			marker = "*"
+		}
+		fmt.Printf("%s %-30s perf %s  drawdown %s", marker, r.Name,
+			portfolio.FormatPercentCell(deref(r.Return)), portfolio.FormatPercentCell(deref(r.Drawdown)))
+		for _, m := range opts.Metrics {
+			value, ok := r.Metrics[m.Name]
+			fmt.Printf("  %s %s", m.Name, portfolio.FormatMetric(value, ok))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("\n%d titre(s) ; * titre suivi non détenu\n", len(results))
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// configWebhook retourne le webhook de la table [webhook] de la configuration, nil sans
+// adresse. Le secret peut aussi venir de DAVID_WEBHOOK_SECRET.
+func configWebhook() *portfolio.Webhook {
+	c := portfolio.ActiveConfig().Webhook
+	if c.URL == "" {
+		return nil
+	}
+	w := &portfolio.Webhook{URL: c.URL, Secret: portfolio.EnvOrConfig("DAVID_WEBHOOK_SECRET", c.Secret), Events: c.Events, Retries: c.Retries, Backoff: c.Backoff}
+	if w.Backoff <= 0 {
+		w.Backoff = time.Second
+	}
+	return w
+}
+
+// webhookSendTimeout borne un envoi, nouvelles tentatives comprises
+const webhookSendTimeout = 30 * time.Second
+
+// webhookFlushTimeout est le délai laissé aux envois en attente à la fin d'une commande,
+// au-delà duquel ils sont abandonnés
+const webhookFlushTimeout = 10 * time.Second
+
+// webhookRelay transmet au webhook les événements d'un portefeuille, dans l'ordre, depuis
+// une goroutine : les modifications n'attendent pas les envois
+type webhookRelay struct {
+	done        chan struct{}
+	cancel      context.CancelFunc // Abandonne les envois en cours et en attente
+	unsubscribe func()
+}
+
+// sendWebhook publie un événement dans la limite de webhookSendTimeout et journalise
+// l'échec éventuel
+func sendWebhook(ctx context.Context, w *portfolio.Webhook, kind portfolio.EventKind, data any) {
+	ctx, cancel := context.WithTimeout(ctx, webhookSendTimeout)
+	defer cancel()
+	if err := w.Send(ctx, kind, data); err != nil {
+		portfolio.Logger().Warn("webhook", "url", w.URL, "event", kind, "err", err)
+	}
+}
+
+// relays sont les relais ouverts par les commandes, vidés par flushWebhooks
+var (
+	relaysMu sync.Mutex
+	relays   []*webhookRelay
+)
+
+// relayWebhook abonne le webhook de la configuration aux événements du portefeuille
+func relayWebhook(p *portfolio.Portfolio) {
+	w := configWebhook()
+	var kinds []portfolio.EventKind
+	for _, kind := range []portfolio.EventKind{portfolio.EventInvestmentAdded, portfolio.EventNAVAdded, portfolio.EventValueRecomputed, portfolio.EventModified} {
+		if w.Wants(kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return
+	}
+
+	events, unsubscribe := p.Subscribe(kinds...)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &webhookRelay{done: make(chan struct{}), cancel: cancel, unsubscribe: unsubscribe}
+	go func() {
+		defer close(r.done)
+		for event := range events {
+			sendWebhook(ctx, w, event.Kind(), event)
+		}
+	}()
+
+	relaysMu.Lock()
+	relays = append(relays, r)
+	relaysMu.Unlock()
+}
+
+// flushWebhooks ferme les relais et attend la fin de leurs envois, avant la fin de la
+// commande ; les envois encore en cours après webhookFlushTimeout sont abandonnés
+func flushWebhooks() {
+	relaysMu.Lock()
+	pending := relays
+	relays = nil
+	relaysMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookFlushTimeout)
+	defer cancel()
+	for _, r := range pending {
+		r.unsubscribe()
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			r.cancel()
+			<-r.done
+		}
+		r.cancel()
+	}
+}
+
+// reportWritten publie l'écriture d'un rapport sur le webhook de la configuration
+func reportWritten(path, format string) {
+	w := configWebhook()
+	if !w.Wants(portfolio.EventReport) {
+		return
+	}
+	sendWebhook(context.Background(), w, portfolio.EventReport, map[string]string{"path": path, "format": format})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+// parseChange lit une modification "action=contribute,investment=X,amount=10000[,rate=5][,date=AAAA-MM-JJ]"
+func parseChange(s string) (portfolio.Change, error) {
+	var ch portfolio.Change
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ch, fmt.Errorf("modification invalide %q (attendu clé=valeur)", part)
+		}
+		switch key {
+		case "action":
+			ch.Action = portfolio.ChangeAction(value)
+		case "investment":
+			ch.Investment = value
+		case "date":
+			ch.Date = value
+		case "amount", "rate":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return ch, fmt.Errorf("valeur invalide pour %s: %w", key, err)
+			}
+			if key == "amount" {
+				ch.Amount = number
+			} else {
+				ch.Rate = number
+			}
+		default:
+			return ch, fmt.Errorf("clé de modification inconnue: %s", key)
+		}
+	}
+	return ch, nil
+}
+
+func runWhatIf(args []string) error {
+	fs, file := newFlagSet("what-if")
+	var changes stringList
+	fs.Var(&changes, "change", "modification \"action=add|remove|contribute|withdraw,investment=X,amount=10000,rate=5,date=AAAA-MM-JJ\" (répétable)")
+	date := fs.String("date", "", "date de projection (AAAA-MM-JJ)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *date == "" || len(changes) == 0 {
+		return fmt.Errorf("--date et au moins une --change sont obligatoires")
+	}
+	t, err := portfolio.ParseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	var parsed []portfolio.Change
+	for _, s := range changes {
+		ch, err := parseChange(s)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, ch)
+	}
+	alt, err := p.WhatIf(parsed...)
+	if err != nil {
+		return err
+	}
+
+	current, currentTotal, err := p.GetPortfolioValue(*date)
+	if err != nil {
+		return err
+	}
+	hypothetical, altTotal, err := alt.GetPortfolioValue(*date)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for name := range current {
+		names[name] = true
+	}
+	for name := range hypothetical {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	amount := report.AmountFormatter(p).Format
+	fmt.Printf("=== SIMULATION AU %s ===\n", portfolio.FormatDate(t))
+	fmt.Printf("%-20s %16s %16s %16s\n", "", "Actuel", "Simulé", "Écart")
+	for _, name := range sorted {
+		fmt.Printf("%-20s %16s %16s %16s\n", name, amount(current[name]), amount(hypothetical[name]), amount(hypothetical[name]-current[name]))
+	}
+	fmt.Printf("%-20s %16s %16s %16s\n", "Total", amount(currentTotal), amount(altTotal), amount(altTotal-currentTotal))
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+func runWithdrawals(args []string) error {
+	fs, file := newFlagSet("withdrawals")
+	start := fs.String("start", "", "date du début des retraits (AAAA-MM-JJ)")
+	monthly := fs.Float64("monthly", 0, "retrait mensuel initial")
+	indexation := fs.Float64("indexation", 0, "revalorisation annuelle des retraits (%)")
+	paths := fs.Int("paths", 0, "nombre de trajectoires Monte-Carlo pour la probabilité de succès (0 : aucune)")
+	years := fs.Int("years", 30, "durée de retraits visée pour la probabilité de succès (ans)")
+	seed := fs.Uint64("seed", 0, "graine du générateur (0 : aléatoire)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *start == "" {
+		return fmt.Errorf("--start est obligatoire")
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	sim, err := p.SimulateWithdrawals(*start, *monthly, *indexation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== SIMULATION DE RETRAITS À PARTIR DU %s ===\n\n", *start)
+	fmt.Printf("Capital de départ: %.2f€, taux moyen: %.2f%%\n\n", sim.StartValue, sim.Rate)
+	for _, y := range sim.Years {
+		fmt.Printf("%s: retiré %.2f€, restant %.2f€\n", portfolio.FormatDate(y.Date.Time), y.Withdrawn, y.Value)
+	}
+	if sim.Depleted() {
+		fmt.Printf("\nCapital épuisé le %s\n", portfolio.FormatDate(sim.Depletion))
+	} else {
+		fmt.Printf("\nCapital non épuisé après %d ans\n", portfolio.WithdrawalHorizonYears)
+	}
+
+	if *paths > 0 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		rate, err := p.WithdrawalSuccessRateContext(ctx, *start, *monthly, *indexation, *years, *paths, portfolio.MonteCarloOptions{Seed: *seed})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Probabilité de tenir %d ans: %.1f%%\n", *years, rate)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+	"github.com/davidsportes-ship-it/david/report"
+)
+
+func runExportXLSX(args []string) error {
+	fs, file := newFlagSet("export-xlsx")
+	output := fs.String("output", "portefeuille.xlsx", "classeur Excel à écrire")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadPortfolioFile(*file, fs.Name())
+	if err != nil {
+		return err
+	}
+	// La langue imposée par DAVID_LANG ne vaut que pour cet export : le portefeuille n'est pas enregistré
+	if locale := portfolio.EnvLocale(); locale != "" {
+		if err := p.SetLocale(locale); err != nil {
+			return err
+		}
+	}
+	if err := report.ExportXLSX(p, *output); err != nil {
+		return err
+	}
+	fmt.Printf("Classeur écrit dans %s\n", *output)
+	return nil
+}
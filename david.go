@@ -0,0 +1,44 @@
+// Package david conserve l'API d'origine du gestionnaire de portefeuille, quand tout
+// tenait dans un seul paquet : ses types sont des alias de ceux du paquet portfolio, si
+// bien que les valorisations et projections existantes donnent les mêmes résultats. Le
+// nouveau code importe directement portfolio, analytics, report ou store.
+package david
+
+import (
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+type (
+	NAV        = portfolio.NAV
+	Investment = portfolio.Investment
+	Portfolio  = portfolio.Portfolio
+)
+
+// Erreurs sentinelles, identiques à celles du paquet portfolio
+var (
+	ErrInvestmentNotFound  = portfolio.ErrInvestmentNotFound
+	ErrNAVNotFound         = portfolio.ErrNAVNotFound
+	ErrInvalidAmount       = portfolio.ErrInvalidAmount
+	ErrInsufficientHistory = portfolio.ErrInsufficientHistory
+	ErrInvalidDate         = portfolio.ErrInvalidDate
+	ErrInvestmentExists    = portfolio.ErrInvestmentExists
+	ErrDuplicateNAV        = portfolio.ErrDuplicateNAV
+	ErrNoNAV               = portfolio.ErrNoNAV
+)
+
+// NewPortfolio crée un nouveau portefeuille vide
+func NewPortfolio() *Portfolio {
+	return portfolio.NewPortfolio()
+}
+
+// LoadPortfolioJSON charge un portefeuille depuis un fichier JSON
+func LoadPortfolioJSON(path string) (*Portfolio, error) {
+	return portfolio.LoadPortfolioJSON(path)
+}
+
+// ParseDate lit une date au format AAAA-MM-JJ
+func ParseDate(s string) (time.Time, error) {
+	return portfolio.ParseDate(s)
+}
@@ -0,0 +1,266 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// AlertKind est le type de condition surveillée par une règle d'alerte
+type AlertKind string
+
+const (
+	AlertValueBelow AlertKind = "value-below" // Valeur du portefeuille sous le seuil
+	AlertDrawdown   AlertKind = "drawdown"    // Baisse d'un investissement depuis son sommet au-delà du seuil (%)
+	AlertDrift      AlertKind = "drift"       // Écart d'un poids à l'allocation cible au-delà du seuil (points de %)
+)
+
+// AlertRule est une règle d'alerte. Investment restreint une règle de baisse ou d'écart à
+// un investissement ; vide, elle s'applique à chacun.
+type AlertRule struct {
+	Kind       AlertKind `json:"kind"`
+	Investment string    `json:"investment,omitempty"`
+	Threshold  float64   `json:"threshold"`
+}
+
+// String décrit la règle, par exemple "drawdown > 15 (ETF Monde)"
+func (r AlertRule) String() string {
+	op := ">"
+	if r.Kind == AlertValueBelow {
+		op = "<"
+	}
+	s := fmt.Sprintf("%s %s %s", r.Kind, op, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+	if r.Investment != "" {
+		s += " (" + r.Investment + ")"
+	}
+	return s
+}
+
+// validate vérifie le type et le seuil de la règle
+func (r AlertRule) validate() error {
+	switch r.Kind {
+	case AlertValueBelow, AlertDrawdown, AlertDrift:
+	default:
+		return InvalidField("kind", r.Kind, "type d'alerte inconnu: %s", r.Kind)
+	}
+	if r.Threshold < 0 {
+		return fmt.Errorf("le seuil d'alerte doit être positif: %w", ErrInvalidAmount)
+	}
+	return nil
+}
+
+// Alert est une règle déclenchée
+type Alert struct {
+	Rule       AlertRule `json:"rule"`
+	Investment string    `json:"investment,omitempty"` // Investissement concerné, vide pour le portefeuille
+	Value      float64   `json:"value"`                // Valeur observée
+	Message    string    `json:"message"`
+}
+
+// AddAlertRule ajoute une règle d'alerte au portefeuille
+func (p *Portfolio) AddAlertRule(rule AlertRule) error {
+	if err := rule.validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if rule.Investment != "" {
+		if _, exists := p.Investments[rule.Investment]; !exists {
+			return fmt.Errorf("l'investissement '%s' n'existe pas: %w", rule.Investment, ErrInvestmentNotFound)
+		}
+	}
+	p.AlertRules = append(p.AlertRules, rule)
+	p.changed("add-alert", rule.Investment)
+	return nil
+}
+
+// RemoveAlertRule supprime la règle d'alerte d'index donné (à partir de 0)
+func (p *Portfolio) RemoveAlertRule(index int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if index < 0 || index >= len(p.AlertRules) {
+		return fmt.Errorf("aucune règle d'alerte d'index %d: %w", index, ErrNotFound)
+	}
+	p.AlertRules = append(p.AlertRules[:index], p.AlertRules[index+1:]...)
+	p.changed("remove-alert", "")
+	return nil
+}
+
+// EvaluateAlerts évalue les règles d'alerte sur les dernières NAV connues et retourne
+// celles qui sont déclenchées. La baisse est mesurée sur l'indice de performance corrigé
+// des flux ; l'écart compare les poids à la dernière date de NAV à l'allocation cible.
+func (p *Portfolio) EvaluateAlerts() ([]Alert, error) {
+	p.mu.RLock()
+	rules := append([]AlertRule(nil), p.AlertRules...)
+	_, last := p.historyBounds()
+	p.mu.RUnlock()
+
+	var alerts []Alert
+	var summary *PortfolioSummary
+	var plan []RebalanceTrade
+	for _, rule := range rules {
+		switch rule.Kind {
+		case AlertValueBelow:
+			if summary == nil {
+				s, err := p.Summary()
+				if err != nil {
+					return nil, err
+				}
+				summary = s
+			}
+			if summary.TotalValue < rule.Threshold {
+				alerts = append(alerts, Alert{Rule: rule, Value: summary.TotalValue,
+					Message: fmt.Sprintf("valeur du portefeuille %.2f %s sous le seuil de %.2f", summary.TotalValue, summary.BaseCurrency, rule.Threshold)})
+			}
+
+		case AlertDrawdown:
+			names := []string{rule.Investment}
+			if rule.Investment == "" {
+				names = p.InvestmentNames()
+			}
+			for _, name := range names {
+				inv, err := p.Investment(name)
+				if err != nil {
+					return nil, err
+				}
+				if inv.Closed {
+					continue
+				}
+				if depth := currentDrawdown(inv.periodReturns()); depth > rule.Threshold {
+					alerts = append(alerts, Alert{Rule: rule, Investment: name, Value: depth,
+						Message: fmt.Sprintf("%s en baisse de %.2f%% depuis son sommet (seuil %.2f%%)", name, depth, rule.Threshold)})
+				}
+			}
+
+		case AlertDrift:
+			if last.IsZero() {
+				continue
+			}
+			if plan == nil {
+				trades, err := p.RebalancePlan(FormatDate(last))
+				if err != nil {
+					return nil, err
+				}
+				plan = trades
+			}
+			for _, trade := range plan {
+				if rule.Investment != "" && trade.Name != rule.Investment {
+					continue
+				}
+				if math.Abs(trade.Drift) > rule.Threshold {
+					alerts = append(alerts, Alert{Rule: rule, Investment: trade.Name, Value: trade.Drift,
+						Message: fmt.Sprintf("%s pèse %.2f%% pour une cible de %.2f%% (écart %+.2f pts, seuil %.2f)",
+							trade.Name, trade.CurrentWeight, trade.TargetWeight, trade.Drift, rule.Threshold)})
+				}
+			}
+		}
+	}
+	return alerts, nil
+}
+
+// currentDrawdown retourne la baisse (%) du dernier niveau de l'indice de performance
+// par rapport à son plus haut, 0 sans historique
+func currentDrawdown(returns []analytics.PeriodReturn) float64 {
+	level, peak := 0.0, 0.0
+	for _, r := range returns {
+		level += r.LogReturn
+		peak = max(peak, level)
+	}
+	return max(0, -math.Expm1(level-peak)*100)
+}
+
+// Notifier transmet les alertes déclenchées
+type Notifier interface {
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// WriterNotifier écrit les alertes, une par ligne, par exemple sur la sortie standard
+type WriterNotifier struct {
+	W io.Writer
+}
+
+// Notify écrit chaque alerte
+func (n WriterNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	for _, a := range alerts {
+		if _, err := fmt.Fprintf(n.W, "ALERTE: %s\n", a.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookNotifier envoie les alertes en JSON ({"alerts": [...]}) par une requête POST
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // http.DefaultClient si nil
+}
+
+// Notify publie les alertes sur le webhook
+func (n WebhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	body, err := json.Marshal(map[string][]Alert{"alerts": alerts})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: HTTP %d", n.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier envoie les alertes par courriel via un serveur SMTP
+type EmailNotifier struct {
+	Addr string    // Serveur SMTP, "hôte:port"
+	Auth smtp.Auth // Authentification, nil si le serveur n'en demande pas
+	From string
+	To   []string
+}
+
+// Notify envoie un courriel récapitulant les alertes
+func (n EmailNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\nTo: %s\r\n", n.From, strings.Join(n.To, ", "))
+	fmt.Fprintf(&b, "Subject: david: %d alerte(s) sur le portefeuille\r\n", len(alerts))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	for _, a := range alerts {
+		b.WriteString("- " + a.Message + "\r\n")
+	}
+	return smtp.SendMail(n.Addr, n.Auth, n.From, n.To, []byte(b.String()))
+}
+
+// NotifyAll transmet les alertes à chaque notificateur et retourne la première erreur
+func NotifyAll(ctx context.Context, notifiers []Notifier, alerts []Alert) error {
+	var first error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, alerts); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
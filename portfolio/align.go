@@ -0,0 +1,167 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// Alignment est la grille commune des analyses qui combinent plusieurs investissements
+// (risque et VaR du portefeuille, pire baisse, performances annuelles, corrélations,
+// backtest). Les NAV n'étant pas publiées aux mêmes dates, mensuelles pour les unes et
+// trimestrielles pour les autres, chaque historique est ramené aux dates de la grille :
+// interpolé entre les NAV encadrantes (InterpolateLinear) ou prolongé par la dernière
+// NAV connue (InterpolateLastKnown). Sans grille, une NAV trimestrielle resterait figée
+// trois mois puis rattraperait d'un coup, ce qui fausse volatilités et corrélations.
+type Alignment struct {
+	Step SeriesStep        // Pas de la grille, mensuel par défaut
+	Mode InterpolationMode // InterpolateLinear (par défaut) ou InterpolateLastKnown
+}
+
+// validate vérifie le pas et le mode de la grille
+func (a Alignment) validate() error {
+	if _, err := a.Step.add(time.Time{}, 0); err != nil {
+		return err
+	}
+	if a.Mode != InterpolateLinear && a.Mode != InterpolateLastKnown {
+		return InvalidField("mode", a.Mode, "mode d'alignement inconnu: %s (linear, last-known)", a.Mode)
+	}
+	return nil
+}
+
+// ConfiguredAlignment retourne la grille de la table [alignment] de la configuration
+func ConfiguredAlignment() Alignment {
+	return ActiveConfig().Alignment.withDefaults()
+}
+
+// withDefaults complète la grille des valeurs par défaut
+func (a Alignment) withDefaults() Alignment {
+	if a.Step == "" {
+		a.Step = StepMonthly
+	}
+	if a.Mode == "" {
+		a.Mode = InterpolateLinear
+	}
+	return a
+}
+
+// at retourne la valeur d'une série à une date de la grille ; ok est faux pour une série
+// vide
+func (a Alignment) at(s timeseries.Series[float64], date time.Time) (float64, bool) {
+	if a.Mode == InterpolateLastKnown {
+		return timeseries.LastKnown(s, date)
+	}
+	return timeseries.Interpolate(s, date)
+}
+
+// grid retourne les dates start, start+pas, ... jusqu'à end, end étant ajoutée si elle ne
+// tombe pas sur un pas
+func (a Alignment) grid(start, end time.Time) ([]time.Time, error) {
+	if end.Before(start) {
+		return nil, nil
+	}
+	return projectionDates(start, end, a.Step)
+}
+
+// alignSeries ramène des séries sur la grille de leur période commune, restreinte à
+// [start, end] si ces bornes ne sont pas nulles. Les séries retournées ont toutes les
+// mêmes dates ; elles sont vides si la période commune est vide.
+func (a Alignment) alignSeries(series []timeseries.Series[float64], start, end time.Time) ([]timeseries.Series[float64], error) {
+	first, last := start, end
+	for _, s := range series {
+		if len(s) == 0 {
+			return make([]timeseries.Series[float64], len(series)), nil
+		}
+		first, last = latest(first, s.First()), earliest(last, s.Last())
+	}
+	dates, err := a.grid(first, last)
+	if err != nil {
+		return nil, err
+	}
+	aligned := make([]timeseries.Series[float64], len(series))
+	for i, s := range series {
+		for _, date := range dates {
+			value, _ := a.at(s, date)
+			aligned[i] = append(aligned[i], timeseries.Point[float64]{Date: date, Value: value})
+		}
+	}
+	return aligned, nil
+}
+
+// alignedValue retourne la valeur des investissements ouverts à une date, chaque
+// historique étant lu selon mode ; complete est faux si l'un d'eux n'a pas encore de
+// NAV. L'appelant doit détenir p.mu.
+func (p *Portfolio) alignedValue(date time.Time, mode InterpolationMode) (total float64, complete bool, err error) {
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		value, err := navAt(inv.NAVHistory, date, mode)
+		if err != nil {
+			return 0, false, nil
+		}
+		converted, err := p.positionValue(inv, value.Float64(), date)
+		if err != nil {
+			return 0, false, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		total += converted
+	}
+	return total, true, nil
+}
+
+// alignedReturns calcule les rendements du portefeuille (corrigés des flux) entre les
+// dates successives de la grille de la configuration, de la première date où tous les
+// investissements ouverts ont une NAV jusqu'à la dernière NAV, dans [start, end] si ces
+// bornes ne sont pas nulles. L'appelant doit détenir p.mu.
+func (p *Portfolio) alignedReturns(start, end time.Time) ([]analytics.PeriodReturn, error) {
+	a := ConfiguredAlignment()
+	first, last := start, end
+	for _, inv := range p.Investments {
+		if inv.Closed || len(inv.NAVHistory) == 0 {
+			continue
+		}
+		first = latest(first, inv.NAVHistory[0].Date.Time)
+	}
+	if _, navEnd := p.historyBounds(); last.IsZero() || navEnd.Before(last) {
+		last = navEnd
+	}
+	if first.IsZero() {
+		return nil, nil
+	}
+	dates, err := a.grid(first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	var returns []analytics.PeriodReturn
+	var prev NAV
+	for _, date := range dates {
+		value, complete, err := p.alignedValue(date, a.Mode)
+		if err != nil {
+			return nil, err
+		}
+		if !complete {
+			continue
+		}
+		current := NAV{Date: Date{date}, Value: NewMoney(value)}
+		if !prev.Date.IsZero() && prev.Value > 0 {
+			flows, err := p.externalFlowsBetween(prev.Date.Time, date)
+			if err != nil {
+				return nil, err
+			}
+			if r := dietzReturn(prev, current, flows); r > -1 {
+				returns = append(returns, analytics.PeriodReturn{
+					Start:     prev.Date.Time,
+					End:       date,
+					Years:     p.rateConventions().YearsBetween(prev.Date.Time, date),
+					LogReturn: math.Log1p(r),
+				})
+			}
+		}
+		prev = current
+	}
+	return returns, nil
+}
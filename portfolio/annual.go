@@ -0,0 +1,124 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// PerformanceRow regroupe les rendements par année d'un investissement ou du total
+type PerformanceRow struct {
+	Name   string          `json:"name"`
+	Annual map[int]float64 `json:"annual"`        // Rendement de chaque année close (%), sur la partie couverte par l'historique
+	YTD    *float64        `json:"ytd,omitempty"` // Rendement depuis le début de l'année en cours (%), nil sans historique
+}
+
+// PerformanceTable est le tableau des performances annuelles, à la manière d'une fiche fonds
+type PerformanceTable struct {
+	AsOf      time.Time        `json:"-"`                    // Date de la dernière NAV du portefeuille
+	YearStart YearStart        `json:"year_start,omitempty"` // Début des années du tableau (civiles si vide)
+	Years     []int            `json:"years"`                // Années closes, triées, désignées par leur année de début
+	Rows      []PerformanceRow `json:"rows"`                 // Investissements triés par nom, puis le total
+}
+
+// YearLabel retourne le libellé d'une colonne du tableau
+func (t *PerformanceTable) YearLabel(year int) string {
+	return t.YearStart.Label(year)
+}
+
+// YTDLabel retourne le libellé (à traduire) de la colonne de l'année en cours
+func (t *PerformanceTable) YTDLabel() string {
+	if t.YearStart.IsCalendar() {
+		return "Depuis le 1er janvier"
+	}
+	return "Depuis le début de l'exercice"
+}
+
+// AnnualReturns découpe les rendements corrigés des flux par année de référence (civile
+// sauf configuration year_start) pour chaque investissement ouvert et pour le
+// portefeuille. L'année de la dernière NAV du portefeuille est présentée à part comme
+// rendement depuis le début de l'année.
+func (p *Portfolio) AnnualReturns() (*PerformanceTable, error) {
+	return p.AnnualReturnsFrom(ReportingYear())
+}
+
+// AnnualReturnsFrom découpe les rendements comme AnnualReturns, en années commençant à ys
+func (p *Portfolio) AnnualReturnsFrom(ys YearStart) (*PerformanceTable, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, asOf := p.historyBounds()
+	if asOf.IsZero() {
+		return nil, fmt.Errorf("aucune NAV dans le portefeuille: %w", ErrInsufficientHistory)
+	}
+	table := &PerformanceTable{AsOf: asOf, YearStart: ys}
+	years := make(map[int]bool)
+
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed {
+			continue
+		}
+		row := calendarReturns(name, analytics.PerformanceIndex(inv.periodReturns()), asOf, ys, years)
+		table.Rows = append(table.Rows, row)
+	}
+
+	returns, err := p.alignedReturns(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	table.Rows = append(table.Rows, calendarReturns("Total", analytics.PerformanceIndex(returns), asOf, ys, years))
+
+	for year := range years {
+		table.Years = append(table.Years, year)
+	}
+	sort.Ints(table.Years)
+	return table, nil
+}
+
+// calendarReturns calcule les rendements par année de référence d'un indice de
+// performance et note dans years les années closes rencontrées
+func calendarReturns(name string, index timeseries.Series[float64], asOf time.Time, ys YearStart, years map[int]bool) PerformanceRow {
+	row := PerformanceRow{Name: name, Annual: make(map[int]float64)}
+	if len(index) < 2 {
+		return row
+	}
+	first, last := index.First(), index.Last()
+
+	for year := ys.YearOf(first); year <= ys.YearOf(last); year++ {
+		start := ys.Start(year)
+		end := ys.Start(year + 1)
+		if start.Before(first) {
+			start = first
+		}
+		if end.After(last) {
+			end = last
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		growth, _ := timeseries.Change(index, start, end)
+		r := math.Expm1(growth) * 100
+		if year == ys.YearOf(asOf) {
+			row.YTD = &r
+			continue
+		}
+		row.Annual[year] = r
+		years[year] = true
+	}
+	return row
+}
+
+// FormatPercentCell formate une cellule de tableau, vide en l'absence de valeur
+func FormatPercentCell(value float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64) + "%"
+}
@@ -0,0 +1,69 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValueAsOf valorise le portefeuille à une date passée avec les seules données connues à
+// cette date : NAV, apports et retraits, transactions, distributions et rachats datés au
+// plus tard de ce jour. Chaque investissement est projeté depuis sa dernière NAV connue,
+// au taux que sa règle en déduisait alors ; un investissement ouvert après la date en est
+// exclu, un investissement clôturé après la date est valorisé comme encore détenu et un
+// investissement sans NAV connue suit MissingNAVPolicy. À la différence de ValueSeries,
+// qui interpole entre les NAV encadrantes, une NAV ajoutée depuis ne modifie pas le
+// résultat.
+func (p *Portfolio) ValueAsOf(date string) (map[string]float64, float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, 0, err
+	}
+	view, err := p.KnownAt(t)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(view.Investments) == 0 {
+		return nil, 0, fmt.Errorf("aucun investissement détenu au %s", FormatDate(t))
+	}
+
+	view.mu.RLock()
+	defer view.mu.RUnlock()
+
+	return view.portfolioValue(t)
+}
+
+// KnownAt retourne une copie du portefeuille réduite aux données datées au plus tard de
+// date (voir ValueAsOf)
+func (p *Portfolio) KnownAt(date time.Time) (*Portfolio, error) {
+	c, err := p.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for name, inv := range c.Investments {
+		if inv.InvestmentDate.After(date) {
+			delete(c.Investments, name)
+			continue
+		}
+		if inv.Closed && inv.ClosedDate.After(date) {
+			inv.Closed, inv.ClosedDate = false, Date{}
+		}
+		inv.NAVHistory = knownUntil(inv.NAVHistory, date, func(n NAV) time.Time { return n.Date.Time })
+		inv.CashFlows = knownUntil(inv.CashFlows, date, func(cf CashFlow) time.Time { return cf.Date.Time })
+		inv.Transactions = knownUntil(inv.Transactions, date, func(tx Transaction) time.Time { return tx.Date.Time })
+		inv.Distributions = knownUntil(inv.Distributions, date, func(d Distribution) time.Time { return d.Date.Time })
+		inv.Redemptions = knownUntil(inv.Redemptions, date, func(r Redemption) time.Time { return r.Date.Time })
+		inv.invalidate()
+	}
+	return c, nil
+}
+
+// knownUntil retourne les éléments datés au plus tard de date, dans leur ordre
+func knownUntil[T any](items []T, date time.Time, dateOf func(T) time.Time) []T {
+	var kept []T
+	for _, item := range items {
+		if !dateOf(item).After(date) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,122 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AttributionLine est la contribution d'un investissement au rendement du portefeuille
+type AttributionLine struct {
+	Name         string  `json:"name"`
+	AssetClass   string  `json:"asset_class"`
+	Weight       float64 `json:"weight"`       // Part du capital moyen engagé (%)
+	Return       float64 `json:"return"`       // Rendement de l'investissement sur la période (%)
+	Contribution float64 `json:"contribution"` // Poids × rendement (points de %)
+}
+
+// Attribution décompose le rendement du portefeuille sur une période
+type Attribution struct {
+	From         Date               `json:"from"`
+	To           Date               `json:"to"`
+	TotalReturn  float64            `json:"total_return"`   // Rendement du portefeuille sur la période (%), somme des contributions
+	Investments  []AttributionLine  `json:"investments"`    // Triées par contribution décroissante
+	ByAssetClass map[string]float64 `json:"by_asset_class"` // Contribution cumulée par classe d'actifs (points de %)
+}
+
+// PerformanceAttribution décompose le rendement du portefeuille entre from et to en
+// contributions de chaque investissement (poids × rendement) et de chaque classe
+// d'actifs. Rendements et poids suivent la méthode de Dietz modifiée, les valeurs aux
+// bornes étant interpolées entre NAV ; la somme des contributions vaut exactement le
+// rendement du portefeuille. Un investissement ouvert pendant la période y entre par
+// son montant initial, un investissement clôturé en sort avec une valeur nulle.
+func (p *Portfolio) PerformanceAttribution(from, to string) (*Attribution, error) {
+	start, err := ParseDate(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseDate(to)
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("la fin de la période doit être après son début: %w", ErrInvalidDate)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	type component struct {
+		line          AttributionLine
+		gain, capital float64
+	}
+	var components []component
+	var totalCapital, totalGain float64
+	for name, inv := range p.Investments {
+		startValue, _ := inv.historicalValue(start)
+		endValue, _ := inv.historicalValue(end)
+		startValue, err := p.toBase(startValue, inv.Currency, start)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		endValue, err = p.toBase(endValue, inv.Currency, end)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+
+		flows := append(inv.paidDistributionFlows(), inv.CashFlows...)
+		if inv.InvestmentDate.After(start) {
+			flows = append(flows, CashFlow{Date: inv.InvestmentDate, Amount: inv.AmountInvested, Type: Contribution})
+		}
+		for i, cf := range flows {
+			amount, err := p.toBase(cf.Amount.Float64(), inv.Currency, cf.Date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			flows[i].Amount = NewMoney(amount)
+		}
+
+		gain, capital := dietzComponents(NAV{Date: Date{start}, Value: NewMoney(startValue)}, NAV{Date: Date{end}, Value: NewMoney(endValue)}, flows)
+		// Une vente à découvert engage le capital de la vente et gagne quand ses NAV
+		// baissent ; un dérivé signé apporte son gain sans capital engagé
+		switch inv.Exposure {
+		case ExposureShort:
+			gain = -gain
+		case ExposureSigned:
+			capital = 0
+		}
+		if capital == 0 && gain == 0 {
+			continue
+		}
+		class := inv.Tags[TagAssetClass]
+		if class == "" {
+			class = UntaggedLabel
+		}
+		components = append(components, component{line: AttributionLine{Name: name, AssetClass: class}, gain: gain, capital: capital})
+		totalCapital += capital
+		totalGain += gain
+	}
+	if totalCapital <= 0 {
+		return nil, fmt.Errorf("aucun capital engagé sur la période: %w", ErrInsufficientHistory)
+	}
+
+	attribution := &Attribution{
+		From:         Date{start},
+		To:           Date{end},
+		TotalReturn:  totalGain / totalCapital * 100,
+		ByAssetClass: make(map[string]float64),
+	}
+	for _, c := range components {
+		line := c.line
+		line.Weight = c.capital / totalCapital * 100
+		if c.capital != 0 {
+			line.Return = c.gain / c.capital * 100
+		}
+		line.Contribution = c.gain / totalCapital * 100
+		attribution.Investments = append(attribution.Investments, line)
+		attribution.ByAssetClass[line.AssetClass] += line.Contribution
+	}
+	sort.Slice(attribution.Investments, func(i, j int) bool {
+		return attribution.Investments[i].Contribution > attribution.Investments[j].Contribution
+	})
+	return attribution, nil
+}
@@ -0,0 +1,323 @@
+package portfolio
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+)
+
+// auditSuffix est l'extension du journal d'audit, enregistré à côté du portefeuille
+const auditSuffix = ".audit"
+
+// AuditAction est la nature d'une modification auditée
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditChange est la modification d'un champ. Les chemins suivent la forme JSON du
+// portefeuille ; les éléments datés d'une liste sont désignés par leur date
+// ("nav_history[2025-03-01].value"). Old et New sont vides pour un champ ajouté ou
+// supprimé, et pour un portefeuille chiffré, dont le journal ne contient aucune valeur.
+type AuditChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// AuditEntry est une modification enregistrée : qui, quand, par quelle commande, sur
+// quel investissement (vide pour les réglages du portefeuille) et avec quelles valeurs
+type AuditEntry struct {
+	Time       time.Time     `json:"time"`
+	User       string        `json:"user"`
+	Command    string        `json:"command,omitempty"`
+	Investment string        `json:"investment,omitempty"`
+	Renamed    string        `json:"renamed_from,omitempty"` // Ancien nom d'un investissement renommé
+	Action     AuditAction   `json:"action"`
+	Changes    []AuditChange `json:"changes"`
+}
+
+// auditScope est l'état aplati d'un investissement, ou des réglages du portefeuille
+type auditScope struct {
+	name   string
+	fields map[string]string
+}
+
+// AuditActor est l'auteur des modifications inscrit dans le journal d'audit :
+// l'utilisateur, ou le jeton d'API de la requête, et la commande qui les a faites
+type AuditActor struct {
+	User    string
+	Command string
+}
+
+// CommandActor retourne l'auteur des modifications faites par une commande : DAVID_USER,
+// sinon l'utilisateur du système
+func CommandActor(command string) AuditActor {
+	return AuditActor{User: auditUser(), Command: command}
+}
+
+// SetAuditActor définit l'auteur inscrit dans les entrées d'audit des enregistrements
+// suivants. Sans auteur, les entrées portent l'utilisateur du système et aucune commande.
+func (p *Portfolio) SetAuditActor(actor AuditActor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.actor = actor
+}
+
+// auditUser retourne l'auteur des modifications : DAVID_USER, sinon l'utilisateur du
+// système
+func auditUser() string {
+	if name := os.Getenv("DAVID_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// auditState aplatit la forme sérialisée du portefeuille : une portée par investissement,
+// identifiée par son identifiant interne pour suivre les renommages, et une portée vide
+// pour les réglages. Le journal d'annulation, qui est lui-même un historique, est ignoré.
+func (p *Portfolio) auditState() (map[string]auditScope, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	state := map[string]auditScope{"": {fields: make(map[string]string)}}
+	investments, _ := raw["investments"].(map[string]any)
+	for name, inv := range investments {
+		scope := auditScope{name: name, fields: make(map[string]string)}
+		flattenAudit("", inv, scope.fields)
+		key := name
+		if id, ok := scope.fields["id"]; ok {
+			key = id
+		}
+		state[key] = scope
+	}
+	delete(raw, "investments")
+	delete(raw, "journal")
+	flattenAudit("", raw, state[""].fields)
+	return state, nil
+}
+
+// flattenAudit aplatit une valeur JSON en chemins → valeurs
+func flattenAudit(prefix string, v any, out map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenAudit(path, child, out)
+		}
+	case []any:
+		seen := make(map[string]int)
+		for i, child := range v {
+			key := fmt.Sprint(i)
+			if m, ok := child.(map[string]any); ok {
+				if date, ok := m["date"].(string); ok {
+					// Plusieurs éléments de même date sont numérotés dans leur ordre
+					if seen[date]++; seen[date] > 1 {
+						date = fmt.Sprintf("%s#%d", date, seen[date])
+					}
+					key = date
+				}
+			}
+			flattenAudit(fmt.Sprintf("%s[%s]", prefix, key), child, out)
+		}
+	default:
+		data, _ := json.Marshal(v)
+		out[prefix] = string(data)
+	}
+}
+
+// auditDiff compare deux états et retourne les entrées d'audit correspondantes, les
+// réglages du portefeuille en premier puis les investissements par nom
+func auditDiff(before, after map[string]auditScope, redact bool) []AuditEntry {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var entries []AuditEntry
+	for key := range keys {
+		old, existed := before[key]
+		cur, exists := after[key]
+		entry := AuditEntry{Investment: cur.name, Action: AuditUpdate}
+		switch {
+		case !existed:
+			entry.Action = AuditCreate
+		case !exists:
+			entry.Action, entry.Investment = AuditDelete, old.name
+		case old.name != cur.name:
+			entry.Renamed = old.name
+		}
+
+		fields := make(map[string]bool)
+		for field := range old.fields {
+			fields[field] = true
+		}
+		for field := range cur.fields {
+			fields[field] = true
+		}
+		for field := range fields {
+			o, n := old.fields[field], cur.fields[field]
+			if o == n {
+				continue
+			}
+			change := AuditChange{Field: field}
+			if !redact {
+				change.Old, change.New = o, n
+			}
+			entry.Changes = append(entry.Changes, change)
+		}
+		if len(entry.Changes) == 0 {
+			continue
+		}
+		sort.Slice(entry.Changes, func(i, j int) bool { return entry.Changes[i].Field < entry.Changes[j].Field })
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Investment < entries[j].Investment })
+	return entries
+}
+
+// recordAudit ajoute au journal d'audit de path les modifications faites depuis le
+// chargement ou le dernier enregistrement, puis prend l'état courant comme référence. Le
+// journal n'est jamais réécrit : chaque enregistrement y ajoute des lignes JSON.
+func (p *Portfolio) recordAudit(path string) error {
+	state, err := p.auditState()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	before := p.audited
+	p.audited = state
+	redact := p.key != nil
+	actor := p.actor
+	p.mu.Unlock()
+	if before == nil {
+		before = map[string]auditScope{"": {fields: map[string]string{}}}
+	}
+
+	entries := auditDiff(before, state, redact)
+	if len(entries) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path+auditSuffix, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if actor.User == "" {
+		actor.User = auditUser()
+	}
+	now := time.Now().UTC()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		entry.Time, entry.User, entry.Command = now, actor.User, actor.Command
+		if err := enc.Encode(entry); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// resetAudit prend l'état courant comme référence du prochain enregistrement
+func (p *Portfolio) resetAudit() {
+	state, err := p.auditState()
+	if err != nil {
+		return
+	}
+	p.mu.Lock()
+	p.audited = state
+	p.mu.Unlock()
+}
+
+// AuditQuery sélectionne des entrées du journal d'audit ; les critères vides sont ignorés
+type AuditQuery struct {
+	Investment string // Nom actuel ou ancien (sans distinction de casse)
+	User       string
+	Field      string // Préfixe du chemin d'un champ modifié
+	From, To   time.Time
+}
+
+// matches indique si l'entrée satisfait les critères ; les modifications sont réduites
+// aux champs demandés
+func (q AuditQuery) matches(e *AuditEntry) bool {
+	if q.Investment != "" && !strings.EqualFold(e.Investment, q.Investment) && !strings.EqualFold(e.Renamed, q.Investment) {
+		return false
+	}
+	if q.User != "" && e.User != q.User {
+		return false
+	}
+	if !q.From.IsZero() && e.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !e.Time.Before(q.To.AddDate(0, 0, 1)) {
+		return false
+	}
+	if q.Field != "" {
+		var kept []AuditChange
+		for _, c := range e.Changes {
+			if strings.HasPrefix(c.Field, q.Field) {
+				kept = append(kept, c)
+			}
+		}
+		if e.Changes = kept; len(kept) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadAuditLog lit le journal d'audit du portefeuille enregistré dans path et retourne
+// les entrées retenues, de la plus ancienne à la plus récente. Un journal absent est vide.
+func ReadAuditLog(path string, q AuditQuery) ([]AuditEntry, error) {
+	f, err := os.Open(path + auditSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s%s ligne %d: %w", path, auditSuffix, line, err)
+		}
+		if q.matches(&e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
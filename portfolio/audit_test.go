@@ -0,0 +1,84 @@
+package portfolio
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditActor(t *testing.T) {
+	t.Setenv("DAVID_USER", "alice")
+	tests := []struct {
+		name  string
+		actor *AuditActor
+		want  AuditActor
+	}{
+		{name: "sans auteur", want: AuditActor{User: "alice"}},
+		{name: "commande", actor: &AuditActor{User: "alice", Command: "add-nav"}, want: AuditActor{User: "alice", Command: "add-nav"}},
+		{name: "jeton d'API", actor: &AuditActor{User: "tableau-de-bord", Command: "serve"}, want: AuditActor{User: "tableau-de-bord", Command: "serve"}},
+		{name: "commande sans utilisateur", actor: &AuditActor{Command: "import"}, want: AuditActor{User: "alice", Command: "import"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "portfolio.json")
+			p := NewPortfolio()
+			if tt.actor != nil {
+				p.SetAuditActor(*tt.actor)
+			}
+			if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SaveJSON(path); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := ReadAuditLog(path, AuditQuery{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) == 0 {
+				t.Fatal("aucune entrée d'audit")
+			}
+			for _, e := range entries {
+				if got := (AuditActor{User: e.User, Command: e.Command}); got != tt.want {
+					t.Errorf("entrée %s/%s: auteur %+v, %+v attendu", e.Investment, e.Action, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditActorPerSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	p := NewPortfolio()
+	steps := []struct {
+		actor AuditActor
+		nav   string
+	}{
+		{actor: AuditActor{User: "alice", Command: "add-nav"}, nav: "2024-02-01"},
+		{actor: AuditActor{User: "tableau-de-bord", Command: "serve"}, nav: "2024-03-01"},
+	}
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range steps {
+		if err := p.AddNAV("A", step.nav, 1010); err != nil {
+			t.Fatal(err)
+		}
+		p.SetAuditActor(step.actor)
+		if err := p.SaveJSON(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, step := range steps {
+		entries, err := ReadAuditLog(path, AuditQuery{Field: "nav_history[" + step.nav + "]"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("NAV du %s: %d entrées, 1 attendue", step.nav, len(entries))
+		}
+		if e := entries[0]; e.User != step.actor.User || e.Command != step.actor.Command {
+			t.Errorf("NAV du %s enregistrée par %s (%s), %s (%s) attendu", step.nav, e.User, e.Command, step.actor.User, step.actor.Command)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package portfolio
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TokenScope est la portée d'un jeton d'API
+type TokenScope string
+
+const (
+	ScopeRead  TokenScope = "read"  // Consultation seulement (GET, méthodes gRPC Value et Project)
+	ScopeWrite TokenScope = "write" // Consultation et modifications
+)
+
+// ErrUnauthenticated et ErrForbidden signalent une requête sans jeton valide, ou dont le
+// jeton n'a pas la portée requise
+var (
+	ErrUnauthenticated = errors.New("jeton d'API absent ou invalide")
+	ErrForbidden       = errors.New("portée du jeton insuffisante")
+)
+
+// APIToken est un jeton d'accès à l'API de serve. Seule l'empreinte SHA-256 du secret
+// est conservée : le secret n'est affiché qu'à la création.
+type APIToken struct {
+	Name    string     `json:"name"`
+	Scope   TokenScope `json:"scope"`
+	Hash    string     `json:"hash"`
+	Created time.Time  `json:"created"`
+}
+
+// allows indique si la portée du jeton couvre scope
+func (t APIToken) allows(scope TokenScope) bool {
+	return t.Scope == ScopeWrite || scope == ScopeRead
+}
+
+// ParseTokenScope lit une portée de jeton
+func ParseTokenScope(s string) (TokenScope, error) {
+	switch scope := TokenScope(s); scope {
+	case ScopeRead, ScopeWrite:
+		return scope, nil
+	}
+	return "", InvalidField("scope", s, "portée de jeton inconnue: %s (read, write)", s)
+}
+
+// tokenHash retourne l'empreinte d'un secret
+func tokenHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenStore est l'ensemble des jetons d'API, enregistré dans un fichier JSON. Sans
+// jeton, l'API est ouverte.
+type TokenStore struct {
+	path   string
+	Tokens []APIToken
+}
+
+// LoadTokens lit le fichier des jetons ; un fichier absent ne contient aucun jeton
+func LoadTokens(path string) (*TokenStore, error) {
+	s := &TokenStore{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.Tokens); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save enregistre les jetons, lisibles du seul propriétaire
+func (s *TokenStore) Save() error {
+	data, err := json.MarshalIndent(s.Tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(s.path, append(data, '\n'), 0o600)
+}
+
+// Add crée un jeton et retourne son secret
+func (s *TokenStore) Add(name string, scope TokenScope) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("le nom du jeton ne peut pas être vide")
+	}
+	for _, t := range s.Tokens {
+		if t.Name == name {
+			return "", fmt.Errorf("le jeton '%s' existe déjà: %w", name, ErrAlreadyExists)
+		}
+	}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	secret := "david_" + hex.EncodeToString(raw)
+	s.Tokens = append(s.Tokens, APIToken{Name: name, Scope: scope, Hash: tokenHash(secret), Created: time.Now().UTC()})
+	sort.Slice(s.Tokens, func(i, j int) bool { return s.Tokens[i].Name < s.Tokens[j].Name })
+	return secret, nil
+}
+
+// Remove révoque un jeton
+func (s *TokenStore) Remove(name string) error {
+	for i, t := range s.Tokens {
+		if t.Name == name {
+			s.Tokens = append(s.Tokens[:i], s.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("le jeton '%s' n'existe pas: %w", name, ErrNotFound)
+}
+
+// Authorize vérifie l'en-tête Authorization ("Bearer <secret>") d'une requête qui
+// demande la portée scope
+func (s *TokenStore) Authorize(header string, scope TokenScope) (*APIToken, error) {
+	secret, found := strings.CutPrefix(header, "Bearer ")
+	if !found || secret == "" {
+		return nil, ErrUnauthenticated
+	}
+	hash := tokenHash(strings.TrimSpace(secret))
+	for i := range s.Tokens {
+		t := &s.Tokens[i]
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) != 1 {
+			continue
+		}
+		if !t.allows(scope) {
+			return t, fmt.Errorf("le jeton '%s' est en lecture seule: %w", t.Name, ErrForbidden)
+		}
+		return t, nil
+	}
+	return nil, ErrUnauthenticated
+}
+
+// Enabled indique si l'API exige un jeton
+func (s *TokenStore) Enabled() bool {
+	return s != nil && len(s.Tokens) > 0
+}
@@ -0,0 +1,137 @@
+package portfolio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenStoreAuthorize(t *testing.T) {
+	s, err := LoadTokens(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Enabled() {
+		t.Error("un fichier absent ne devrait contenir aucun jeton")
+	}
+	read, err := s.Add("tableau-de-bord", ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write, err := s.Add("saisie", ScopeWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		scope   TokenScope
+		token   string // Nom du jeton retourné
+		wantErr error
+	}{
+		{name: "sans en-tête", scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "autre schéma", header: "Basic " + read, scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "secret vide", header: "Bearer ", scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "secret inconnu", header: "Bearer david_0000", scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "lecture", header: "Bearer " + read, scope: ScopeRead, token: "tableau-de-bord"},
+		{name: "espaces autour du secret", header: "Bearer " + read + " ", scope: ScopeRead, token: "tableau-de-bord"},
+		{name: "écriture avec un jeton read", header: "Bearer " + read, scope: ScopeWrite, token: "tableau-de-bord", wantErr: ErrForbidden},
+		{name: "lecture avec un jeton write", header: "Bearer " + write, scope: ScopeRead, token: "saisie"},
+		{name: "écriture", header: "Bearer " + write, scope: ScopeWrite, token: "saisie"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := s.Authorize(tt.header, tt.scope)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("erreur %v, %v attendue", err, tt.wantErr)
+			}
+			name := ""
+			if token != nil {
+				name = token.Name
+			}
+			if name != tt.token {
+				t.Errorf("jeton %q, %q attendu", name, tt.token)
+			}
+		})
+	}
+}
+
+func TestTokenStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	s, err := LoadTokens(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := s.Add("saisie", ScopeWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, "david_") {
+		t.Errorf("secret %q sans préfixe david_", secret)
+	}
+	if _, err := s.Add(" saisie ", ScopeRead); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("jeton en double: %v, ErrAlreadyExists attendue", err)
+	}
+	if _, err := s.Add(" ", ScopeRead); err == nil {
+		t.Error("jeton sans nom accepté")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Error("le secret est enregistré en clair")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("fichier des jetons en %v (%v), 0600 attendu", info.Mode().Perm(), err)
+	}
+
+	reloaded, err := LoadTokens(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.Authorize("Bearer "+secret, ScopeWrite); err != nil {
+		t.Errorf("jeton relu refusé: %v", err)
+	}
+	if err := reloaded.Remove("saisie"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.Authorize("Bearer "+secret, ScopeRead); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("jeton révoqué: %v, ErrUnauthenticated attendue", err)
+	}
+	if err := reloaded.Remove("saisie"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("révocation d'un jeton absent: %v, ErrNotFound attendue", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTokens(path); err == nil {
+		t.Error("fichier des jetons invalide accepté")
+	}
+}
+
+func TestParseTokenScope(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    TokenScope
+		wantErr bool
+	}{
+		{in: "read", want: ScopeRead},
+		{in: "write", want: ScopeWrite},
+		{in: "admin", wantErr: true},
+		{in: "", wantErr: true},
+	} {
+		got, err := ParseTokenScope(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseTokenScope(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
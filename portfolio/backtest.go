@@ -0,0 +1,177 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/backtest"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// BacktestStrategy est une stratégie d'allocation rejouée sur l'historique : des poids
+// fixes, rétablis à chaque échéance de rééquilibrage, et des versements réguliers
+// répartis selon ces poids
+type BacktestStrategy struct {
+	Weights          map[string]float64 // Poids cibles par investissement (%, total 100)
+	Rebalance        SeriesStep         // Périodicité du rééquilibrage, vide pour ne jamais rééquilibrer
+	Initial          Money              // Capital investi au début de la période
+	Contribution     Money              // Montant de chaque versement, nul sans versements
+	ContributionStep SeriesStep         // Périodicité des versements
+}
+
+// BacktestResult décrit la simulation d'une stratégie
+type BacktestResult struct {
+	Final       Money        // Valeur finale
+	Invested    Money        // Capital initial et versements
+	Return      float64      // Rendement annualisé corrigé des versements (%)
+	Volatility  float64      // Volatilité annualisée (%)
+	MaxDrawdown Drawdown     // Pire baisse de l'indice de performance
+	Series      []ValuePoint // Valeur de chaque ligne à chaque pas
+}
+
+// BacktestReport compare la stratégie à la même allocation jamais rééquilibrée
+type BacktestReport struct {
+	From, To    time.Time
+	Strategy    BacktestResult
+	BuyAndHold  BacktestResult
+	Rebalancing int // Nombre de rééquilibrages effectués
+}
+
+// Backtest rejoue une stratégie entre deux dates, par pas de step, sur les rendements
+// historiques corrigés des flux de chaque investissement (en devise de l'investissement,
+// NAV interpolées entre deux dates). Chaque investissement doit être valorisé sur toute
+// la période.
+func (p *Portfolio) Backtest(s BacktestStrategy, from, to time.Time, step SeriesStep) (*BacktestReport, error) {
+	return p.BacktestContext(context.Background(), s, from, to, step)
+}
+
+// BacktestContext est Backtest interrompu si ctx est annulé
+func (p *Portfolio) BacktestContext(ctx context.Context, s BacktestStrategy, from, to time.Time, step SeriesStep) (*BacktestReport, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("la fin de la période doit être après son début: %w", ErrInvalidDate)
+	}
+	if s.Initial <= 0 && s.Contribution <= 0 {
+		return nil, fmt.Errorf("un capital initial ou des versements sont nécessaires: %w", ErrInvalidAmount)
+	}
+	total := 0.0
+	for name, w := range s.Weights {
+		if w < 0 {
+			return nil, fmt.Errorf("poids négatif pour %s: %w", name, ErrInvalidAmount)
+		}
+		total += w
+	}
+	if math.Abs(total-100) > 0.01 {
+		return nil, fmt.Errorf("les poids totalisent %.2f%% au lieu de 100%%", total)
+	}
+
+	p.mu.RLock()
+	indexes := make(map[string]timeseries.Series[float64])
+	for name := range s.Weights {
+		inv, exists := p.Investments[name]
+		if !exists {
+			p.mu.RUnlock()
+			return nil, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+		}
+		index := analytics.PerformanceIndex(inv.periodReturns())
+		if len(index) == 0 || index.First().After(from) || index.Last().Before(to) {
+			p.mu.RUnlock()
+			return nil, fmt.Errorf("l'historique de %s ne couvre pas la période: %w", name, ErrInsufficientHistory)
+		}
+		indexes[name] = index
+	}
+	conv := p.rateConventions()
+	p.mu.RUnlock()
+
+	var dates []time.Time
+	for i := 0; ; i++ {
+		date, err := step.add(from, i)
+		if err != nil {
+			return nil, err
+		}
+		if !date.Before(to) {
+			break
+		}
+		dates = append(dates, date)
+	}
+	dates = append(dates, to)
+
+	strategy, rebalancing, err := simulate(ctx, conv, s, indexes, dates)
+	if err != nil {
+		return nil, err
+	}
+	hold := s
+	hold.Rebalance = ""
+	buyAndHold, _, err := simulate(ctx, conv, hold, indexes, dates)
+	if err != nil {
+		return nil, err
+	}
+	return &BacktestReport{From: from, To: to, Strategy: *strategy, BuyAndHold: *buyAndHold, Rebalancing: rebalancing}, nil
+}
+
+// dueDates retourne les échéances d'une périodicité comptées depuis from, dans ]from, to]
+func dueDates(step SeriesStep, from, to time.Time) ([]time.Time, error) {
+	var due []time.Time
+	if step == "" {
+		return due, nil
+	}
+	for i := 1; ; i++ {
+		date, err := step.add(from, i)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(to) {
+			return due, nil
+		}
+		due = append(due, date)
+	}
+}
+
+// simulate rejoue la stratégie avec le paquet backtest, les indices étant lus selon
+// l'alignement du portefeuille, puis en mesure le rendement, la volatilité et la pire
+// baisse ; durées et taux suivent conv
+func simulate(ctx context.Context, conv analytics.RateConventions, s BacktestStrategy, indexes map[string]timeseries.Series[float64], dates []time.Time) (*BacktestResult, int, error) {
+	from, to := dates[0], dates[len(dates)-1]
+	contributions, err := dueDates(s.ContributionStep, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+	rebalances, err := dueDates(s.Rebalance, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+	run, err := backtest.Run(ctx, backtest.Strategy{
+		Weights: s.Weights, Initial: s.Initial.Float64(), Contribution: s.Contribution.Float64(),
+		Contributions: contributions, Rebalances: rebalances,
+	}, indexes, dates, ConfiguredAlignment().at)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := &BacktestResult{Final: NewMoney(run.Final).RoundCents(), Invested: NewMoney(run.Invested)}
+	for _, pt := range run.Values {
+		vp := ValuePoint{Date: Date{pt.Date}, Values: make(map[string]float64, len(pt.Value))}
+		total := 0.0
+		for name, v := range pt.Value {
+			vp.Values[name] = NewMoney(v).RoundCents().Float64()
+			total += v
+		}
+		vp.Total = NewMoney(total).RoundCents().Float64()
+		result.Series = append(result.Series, vp)
+	}
+	returns := make([]analytics.PeriodReturn, len(run.Periods))
+	for i, r := range run.Periods {
+		returns[i] = analytics.PeriodReturn{Start: r.Start, End: r.End, Years: conv.YearsBetween(r.Start, r.End), LogReturn: r.LogReturn}
+	}
+	if metrics, err := analytics.Risk(conv, returns, 0); err == nil {
+		result.Return, result.Volatility = metrics.Return, metrics.Volatility
+	} else if len(returns) == 1 {
+		result.Return = conv.RateFromLog(returns[0].LogReturn / returns[0].Years)
+	}
+	if dd, err := maxDrawdown(returns); err == nil {
+		result.MaxDrawdown = dd
+	}
+	return result, run.Rebalancing, nil
+}
@@ -0,0 +1,213 @@
+package portfolio
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupTimeLayout est l'horodatage UTC des noms d'archive, qui se trient chronologiquement
+const backupTimeLayout = "20060102T150405Z"
+
+// backupChecksums est le nom du fichier de sommes de contrôle dans l'archive
+const backupChecksums = "SHA256SUMS"
+
+// Backup décrit une archive de sauvegarde
+type Backup struct {
+	Path    string
+	Created time.Time
+	Size    int64
+	seq     int // Rang parmi les archives créées dans la même seconde
+}
+
+// BackupFile archive le fichier du portefeuille dans dir (créé si besoin), puis ne
+// conserve que les keep archives les plus récentes de ce fichier (toutes si keep <= 0).
+// Retourne le chemin de l'archive créée.
+func BackupFile(path, dir string, keep int) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("lecture de %s: %w", path, err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("création de %s: %w", dir, err)
+	}
+
+	base := filepath.Base(path)
+	now := time.Now().UTC()
+	// Plusieurs archives dans la même seconde sont numérotées à la suite de la dernière
+	existing, err := ListBackups(path, dir)
+	if err != nil {
+		return "", err
+	}
+	seq := 1
+	for _, b := range existing {
+		if b.Created.Equal(now.Truncate(time.Second)) {
+			seq = max(seq, b.seq+1)
+		}
+	}
+	archive := filepath.Join(dir, base+"-"+now.Format(backupTimeLayout)+".tar.gz")
+	if seq > 1 {
+		archive = filepath.Join(dir, fmt.Sprintf("%s-%s-%d.tar.gz", base, now.Format(backupTimeLayout), seq))
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	sum := sha256.Sum256(data)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  " + base + "\n")
+	for _, member := range []struct {
+		name string
+		data []byte
+	}{{base, data}, {backupChecksums, checksums}} {
+		header := &tar.Header{Name: member.name, Mode: 0o600, Size: int64(len(member.data)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write(member.data); err != nil {
+			return "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+	if err := WriteFileAtomic(archive, buf.Bytes(), 0o600); err != nil {
+		return "", err
+	}
+
+	if keep > 0 {
+		backups, err := ListBackups(path, dir)
+		if err != nil {
+			return archive, err
+		}
+		for _, old := range backups[:max(len(backups)-keep, 0)] {
+			if err := os.Remove(old.Path); err != nil {
+				return archive, fmt.Errorf("suppression de %s: %w", old.Path, err)
+			}
+		}
+	}
+	return archive, nil
+}
+
+// ListBackups retourne les archives du fichier présentes dans dir, de la plus ancienne
+// à la plus récente
+func ListBackups(path, dir string) ([]Backup, error) {
+	prefix := filepath.Base(path) + "-"
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".tar.gz") {
+			continue
+		}
+		stamp, suffix, numbered := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".tar.gz"), "-")
+		created, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		seq := 1
+		if numbered {
+			if seq, err = strconv.Atoi(suffix); err != nil {
+				continue
+			}
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, Backup{Path: filepath.Join(dir, name), Created: created, Size: info.Size(), seq: seq})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Created.Equal(backups[j].Created) {
+			return backups[i].Created.Before(backups[j].Created)
+		}
+		return backups[i].seq < backups[j].seq
+	})
+	return backups, nil
+}
+
+// VerifyBackup lit une archive, contrôle la somme SHA-256 du fichier sauvegardé et, pour
+// un fichier JSON, sa syntaxe. Retourne le nom et le contenu du fichier.
+func VerifyBackup(archive string) (string, []byte, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("archive %s illisible: %w", archive, err)
+	}
+
+	members := make(map[string][]byte)
+	var name string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("archive %s illisible: %w", archive, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return "", nil, fmt.Errorf("archive %s illisible: %w", archive, err)
+		}
+		members[header.Name] = data
+		if header.Name != backupChecksums {
+			name = header.Name
+		}
+	}
+
+	sums, ok := members[backupChecksums]
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("archive %s incomplète", archive)
+	}
+	expected, _, _ := strings.Cut(string(sums), " ")
+	sum := sha256.Sum256(members[name])
+	if hex.EncodeToString(sum[:]) != expected {
+		return "", nil, fmt.Errorf("archive %s corrompue: somme de contrôle de %s incorrecte", archive, name)
+	}
+	if strings.HasSuffix(name, ".json") && !json.Valid(members[name]) {
+		return "", nil, fmt.Errorf("archive %s: %s n'est pas un JSON valide", archive, name)
+	}
+	return name, members[name], nil
+}
+
+// RestoreBackup vérifie une archive puis remplace le fichier du portefeuille par son
+// contenu. Le fichier actuel, s'il existe, est d'abord sauvegardé dans dir.
+func RestoreBackup(archive, path, dir string) error {
+	_, data, err := VerifyBackup(archive)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if _, err := BackupFile(path, dir, 0); err != nil {
+			return fmt.Errorf("sauvegarde du fichier actuel: %w", err)
+		}
+	}
+	return WriteFileAtomic(path, data, 0o600)
+}
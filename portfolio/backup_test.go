@@ -0,0 +1,177 @@
+package portfolio
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeArchive écrit une archive tar.gz des membres donnés par paires (nom, contenu)
+func writeArchive(t *testing.T, path string, members ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for i := 0; i < len(members); i += 2 {
+		if err := tw.WriteHeader(&tar.Header{Name: members[i], Mode: 0o600, Size: int64(len(members[i+1]))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(members[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portfolio.json")
+	backups := filepath.Join(dir, "backups")
+	original := []byte(`{"investments":{}}`)
+	if err := os.WriteFile(path, original, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := BackupFile(path, backups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, data, err := VerifyBackup(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "portfolio.json" || !bytes.Equal(data, original) {
+		t.Errorf("archive de %s: %s, %s attendu", name, data, original)
+	}
+
+	modified := []byte(`{"investments":{"A":{}}}`)
+	if err := os.WriteFile(path, modified, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreBackup(archive, path, backups); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, original) {
+		t.Errorf("fichier restauré %s (%v), %s attendu", data, err, original)
+	}
+
+	// Le fichier remplacé a été sauvegardé avant la restauration
+	list, err := ListBackups(path, backups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("%d archives, 2 attendues", len(list))
+	}
+	if _, data, err := VerifyBackup(list[1].Path); err != nil || !bytes.Equal(data, modified) {
+		t.Errorf("sauvegarde avant restauration %s (%v), %s attendu", data, err, modified)
+	}
+}
+
+func TestBackupRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portfolio.json")
+	backups := filepath.Join(dir, "backups")
+	// Archive d'un autre fichier du même répertoire, jamais supprimée
+	other := filepath.Join(dir, "autre.json")
+	for _, file := range []string{path, other} {
+		if err := os.WriteFile(file, []byte(`{}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := BackupFile(other, backups, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var created []string
+	for i := range 5 {
+		if err := os.WriteFile(path, []byte(strings.Repeat(" ", i)+`{}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		archive, err := BackupFile(path, backups, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, archive)
+	}
+
+	list, err := ListBackups(path, backups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("%d archives conservées, 3 attendues", len(list))
+	}
+	for i, b := range list {
+		if want := created[len(created)-3+i]; b.Path != want {
+			t.Errorf("archive %d: %s, %s attendue", i, b.Path, want)
+		}
+	}
+	if others, err := ListBackups(other, backups); err != nil || len(others) != 1 {
+		t.Errorf("archives de %s: %d (%v), 1 attendue", other, len(others), err)
+	}
+	if list, err := ListBackups(path, filepath.Join(dir, "absent")); err != nil || list != nil {
+		t.Errorf("répertoire absent: %v (%v), aucune archive attendue", list, err)
+	}
+}
+
+func TestVerifyBackupInvalid(t *testing.T) {
+	const sum = "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a" // SHA-256 de "{}"
+	tests := []struct {
+		name    string
+		members []string
+		raw     string // Contenu brut du fichier, à la place d'une archive
+		want    string
+	}{
+		{name: "valide", members: []string{"p.json", "{}", "SHA256SUMS", sum + "  p.json\n"}},
+		{name: "pas une archive", raw: "{}", want: "illisible"},
+		{name: "sans sommes", members: []string{"p.json", "{}"}, want: "incomplète"},
+		{name: "sans fichier", members: []string{"SHA256SUMS", sum + "  p.json\n"}, want: "incomplète"},
+		{name: "contenu altéré", members: []string{"p.json", "[]", "SHA256SUMS", sum + "  p.json\n"}, want: "somme de contrôle de p.json incorrecte"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "p.json-20240101T000000Z.tar.gz")
+			if tt.raw != "" {
+				if err := os.WriteFile(archive, []byte(tt.raw), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				writeArchive(t, archive, tt.members...)
+			}
+			_, _, err := VerifyBackup(archive)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("erreur %v, %q attendu", err, tt.want)
+			}
+		})
+	}
+
+	// Une sauvegarde JSON dont la somme est juste mais le contenu invalide est refusée
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portfolio.json")
+	if err := os.WriteFile(path, []byte(`{"investments":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := BackupFile(path, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyBackup(archive); err == nil || !strings.Contains(err.Error(), "n'est pas un JSON valide") {
+		t.Errorf("JSON tronqué: %v, refus attendu", err)
+	}
+	if err := RestoreBackup(archive, filepath.Join(dir, "restauré.json"), dir); err == nil {
+		t.Error("restauration d'une archive invalide acceptée")
+	}
+}
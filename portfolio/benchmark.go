@@ -0,0 +1,310 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// Benchmark est un indice de référence doté de son propre historique de valeurs
+type Benchmark struct {
+	Name    string `json:"name"`
+	History []NAV  `json:"history"` // Valeurs de l'indice, triées par date
+}
+
+// BenchmarkComparison compare les rendements d'un investissement (ou du portefeuille)
+// à ceux d'un indice sur les mêmes sous-périodes. Alpha, bêta et R² sont ceux de la
+// régression linéaire des rendements des sous-périodes sur ceux de l'indice.
+type BenchmarkComparison struct {
+	Benchmark       string  `json:"benchmark"`
+	Periods         int     `json:"periods"`           // Nombre de sous-périodes comparées
+	Return          float64 `json:"return"`            // Rendement annualisé (%)
+	BenchmarkReturn float64 `json:"benchmark_return"`  // Rendement annualisé de l'indice (%)
+	ExcessReturn    float64 `json:"excess_return"`     // Écart de rendement annualisé (points de %)
+	TrackingError   float64 `json:"tracking_error"`    // Écart-type annualisé des écarts de rendement (%)
+	InfoRatio       float64 `json:"information_ratio"` // Écart de rendement / tracking error, 0 si ce dernier est nul
+	Beta            float64 `json:"beta"`              // Sensibilité aux rendements de l'indice
+	Alpha           float64 `json:"alpha"`             // Rendement propre annualisé, hors sensibilité à l'indice (%)
+	RSquared        float64 `json:"r_squared"`         // Part de la variance expliquée par l'indice (0 à 1)
+}
+
+// valueAt retourne la dernière valeur de l'indice connue à une date
+func (b *Benchmark) valueAt(date time.Time) (float64, bool) {
+	i := sort.Search(len(b.History), func(i int) bool { return b.History[i].Date.After(date) })
+	if i == 0 {
+		return 0, false
+	}
+	return b.History[i-1].Value.Float64(), true
+}
+
+// AddBenchmarkValue ajoute une valeur à un indice, créé s'il n'existe pas
+func (p *Portfolio) AddBenchmarkValue(benchmarkName string, date string, value float64) error {
+	benchmarkName = strings.TrimSpace(benchmarkName)
+	if benchmarkName == "" {
+		return fmt.Errorf("le nom de l'indice ne peut pas être vide")
+	}
+	if NewMoney(value) <= 0 {
+		return fmt.Errorf("la valeur de l'indice doit être positive: %w", ErrInvalidAmount)
+	}
+	nav, err := NewNAV(date, value)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Benchmarks == nil {
+		p.Benchmarks = make(map[string]*Benchmark)
+	}
+	b, exists := p.Benchmarks[benchmarkName]
+	if !exists {
+		b = &Benchmark{Name: benchmarkName}
+		p.Benchmarks[benchmarkName] = b
+	}
+
+	i := sort.Search(len(b.History), func(i int) bool { return !b.History[i].Date.Before(nav.Date.Time) })
+	if i < len(b.History) && b.History[i].Date.Equal(nav.Date.Time) {
+		b.History[i].Value = nav.Value
+		p.changed("add-benchmark-value", "")
+		return nil
+	}
+	b.History = append(b.History, nav)
+	sortNAVs(b.History)
+	p.changed("add-benchmark-value", "")
+	return nil
+}
+
+// SetInvestmentBenchmark associe un indice de référence à un investissement ; un nom vide l'en dissocie
+func (p *Portfolio) SetInvestmentBenchmark(investmentName, benchmarkName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if _, exists := p.Benchmarks[benchmarkName]; benchmarkName != "" && !exists {
+		return fmt.Errorf("l'indice '%s' n'existe pas: %w", benchmarkName, ErrNotFound)
+	}
+	before := inv.clone()
+	inv.Benchmark = benchmarkName
+	p.record(OpSetBenchmark, investmentName, fmt.Sprintf("indice %q", benchmarkName), before)
+	return nil
+}
+
+// periodPair associe le rendement d'une sous-période à celui de l'indice
+type periodPair struct {
+	years     float64
+	r         float64
+	benchmark float64
+}
+
+// inWindow indique si la sous-période [start, end] est comprise dans la fenêtre
+// [from, to], une borne nulle n'imposant aucune limite
+func inWindow(start, end, from, to time.Time) bool {
+	return (from.IsZero() || !start.Before(from)) && (to.IsZero() || !end.After(to))
+}
+
+// CompareToBenchmark compare l'investissement à un indice sur les sous-périodes
+// séparant ses NAV successives, rendements corrigés des flux
+func (inv *Investment) CompareToBenchmark(b *Benchmark) (BenchmarkComparison, error) {
+	return inv.CompareToBenchmarkBetween(b, time.Time{}, time.Time{})
+}
+
+// CompareToBenchmarkBetween compare l'investissement à un indice sur les sous-périodes
+// comprises entre from et to (bornes nulles : tout l'historique)
+func (inv *Investment) CompareToBenchmarkBetween(b *Benchmark, from, to time.Time) (BenchmarkComparison, error) {
+	var pairs []periodPair
+	for i := 1; i < len(inv.NAVHistory); i++ {
+		start, end := inv.NAVHistory[i-1], inv.NAVHistory[i]
+		if !inWindow(start.Date.Time, end.Date.Time, from, to) {
+			continue
+		}
+		b0, ok0 := b.valueAt(start.Date.Time)
+		b1, ok1 := b.valueAt(end.Date.Time)
+		if !ok0 || !ok1 {
+			continue
+		}
+		pairs = append(pairs, periodPair{
+			years:     inv.conventions.YearsBetween(start.Date.Time, end.Date.Time),
+			r:         inv.flowAdjustedReturn(start, end),
+			benchmark: b1/b0 - 1,
+		})
+	}
+	return compareReturns(inv.conventions, b.Name, pairs)
+}
+
+// BenchmarkReport compare chaque investissement à l'indice qui lui est associé
+func (p *Portfolio) BenchmarkReport() (map[string]BenchmarkComparison, error) {
+	return p.BenchmarkReportBetween(time.Time{}, time.Time{})
+}
+
+// BenchmarkReportBetween compare chaque investissement à son indice sur la fenêtre
+// [from, to] (bornes nulles : tout l'historique). Sur une fenêtre bornée, les
+// investissements sans au moins deux sous-périodes dans la fenêtre sont omis.
+func (p *Portfolio) BenchmarkReportBetween(from, to time.Time) (map[string]BenchmarkComparison, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	report := make(map[string]BenchmarkComparison)
+	for name, inv := range p.Investments {
+		if inv.Benchmark == "" {
+			continue
+		}
+		b, exists := p.Benchmarks[inv.Benchmark]
+		if !exists {
+			return nil, fmt.Errorf("l'indice '%s' de %s n'existe pas: %w", inv.Benchmark, name, ErrNotFound)
+		}
+		comparison, err := inv.CompareToBenchmarkBetween(b, from, to)
+		if errors.Is(err, ErrInsufficientHistory) && !(from.IsZero() && to.IsZero()) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		report[name] = comparison
+	}
+	return report, nil
+}
+
+// CompareToBenchmark compare le portefeuille (investissements ouverts, en devise de
+// consolidation) à un indice, sur les sous-périodes séparant les valeurs de l'indice.
+// Chaque investissement est valorisé à sa dernière NAV connue ; la comparaison
+// commence dès que tous les investissements ont une NAV.
+func (p *Portfolio) CompareToBenchmark(benchmarkName string) (BenchmarkComparison, error) {
+	return p.CompareToBenchmarkBetween(benchmarkName, time.Time{}, time.Time{})
+}
+
+// CompareToBenchmarkBetween compare le portefeuille à un indice sur la fenêtre
+// [from, to] (bornes nulles : tout l'historique)
+func (p *Portfolio) CompareToBenchmarkBetween(benchmarkName string, from, to time.Time) (BenchmarkComparison, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	b, exists := p.Benchmarks[benchmarkName]
+	if !exists {
+		return BenchmarkComparison{}, fmt.Errorf("l'indice '%s' n'existe pas: %w", benchmarkName, ErrNotFound)
+	}
+
+	var pairs []periodPair
+	var prevDate time.Time
+	var prevValue float64
+	for _, point := range b.History {
+		if !inWindow(point.Date.Time, point.Date.Time, from, to) {
+			continue
+		}
+		value, complete, err := p.lastKnownValue(point.Date.Time)
+		if err != nil {
+			return BenchmarkComparison{}, err
+		}
+		if !complete {
+			continue
+		}
+		if !prevDate.IsZero() && prevValue > 0 {
+			flows, err := p.externalFlowsBetween(prevDate, point.Date.Time)
+			if err != nil {
+				return BenchmarkComparison{}, err
+			}
+			b0, _ := b.valueAt(prevDate)
+			pairs = append(pairs, periodPair{
+				years:     p.rateConventions().YearsBetween(prevDate, point.Date.Time),
+				r:         dietzReturn(NAV{Date: Date{prevDate}, Value: NewMoney(prevValue)}, NAV{Date: point.Date, Value: NewMoney(value)}, flows),
+				benchmark: point.Value.Float64()/b0 - 1,
+			})
+		}
+		prevDate, prevValue = point.Date.Time, value
+	}
+	return compareReturns(p.rateConventions(), b.Name, pairs)
+}
+
+// lastKnownValue somme la dernière NAV connue à une date de chaque investissement ouvert,
+// en devise de consolidation ; complete est faux si l'un d'eux n'a pas encore de NAV.
+// L'appelant doit détenir p.mu.
+func (p *Portfolio) lastKnownValue(date time.Time) (total float64, complete bool, err error) {
+	return p.alignedValue(date, InterpolateLastKnown)
+}
+
+// externalFlowsBetween rassemble, en devise de consolidation, les apports, retraits et
+// distributions versées des investissements ouverts sur ]start, end]. L'appelant doit détenir p.mu.
+func (p *Portfolio) externalFlowsBetween(start, end time.Time) ([]CashFlow, error) {
+	var flows []CashFlow
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		for _, cf := range append(inv.paidDistributionFlows(), inv.CashFlows...) {
+			if !cf.Date.After(start) || cf.Date.After(end) {
+				continue
+			}
+			cf = inv.signed(cf)
+			amount, err := p.toBase(cf.Amount.Float64(), inv.Currency, cf.Date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			flows = append(flows, CashFlow{Date: cf.Date, Amount: NewMoney(amount), Type: cf.Type})
+		}
+	}
+	return flows, nil
+}
+
+// compareReturns calcule les mesures de comparaison à partir des rendements appariés, les
+// taux suivant les conventions c
+func compareReturns(c analytics.RateConventions, benchmarkName string, pairs []periodPair) (BenchmarkComparison, error) {
+	if len(pairs) < 2 {
+		return BenchmarkComparison{}, fmt.Errorf("au moins 2 sous-périodes communes avec l'indice sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	var years, growth, benchmarkGrowth, meanR, meanB float64 = 0, 1, 1, 0, 0
+	for _, pair := range pairs {
+		years += pair.years
+		growth *= 1 + pair.r
+		benchmarkGrowth *= 1 + pair.benchmark
+		meanR += pair.r
+		meanB += pair.benchmark
+	}
+	if years <= 0 {
+		return BenchmarkComparison{}, fmt.Errorf("l'intervalle de temps doit être positif")
+	}
+	n := float64(len(pairs))
+	meanR /= n
+	meanB /= n
+
+	var covariance, varianceR, varianceB, varianceDiff, meanDiff float64
+	meanDiff = meanR - meanB
+	for _, pair := range pairs {
+		covariance += (pair.r - meanR) * (pair.benchmark - meanB)
+		varianceR += (pair.r - meanR) * (pair.r - meanR)
+		varianceB += (pair.benchmark - meanB) * (pair.benchmark - meanB)
+		diff := pair.r - pair.benchmark - meanDiff
+		varianceDiff += diff * diff
+	}
+
+	comparison := BenchmarkComparison{
+		Benchmark:       benchmarkName,
+		Periods:         len(pairs),
+		Return:          c.AnnualizedRate(growth, years),
+		BenchmarkReturn: c.AnnualizedRate(benchmarkGrowth, years),
+		// Écart-type par sous-période, annualisé selon le nombre moyen de sous-périodes par an
+		TrackingError: math.Sqrt(varianceDiff/(n-1)) * math.Sqrt(n/years) * 100,
+	}
+	comparison.ExcessReturn = comparison.Return - comparison.BenchmarkReturn
+	if comparison.TrackingError > 0 {
+		comparison.InfoRatio = comparison.ExcessReturn / comparison.TrackingError
+	}
+	if varianceB > 0 {
+		comparison.Beta = covariance / varianceB
+		if varianceR > 0 {
+			comparison.RSquared = covariance * covariance / (varianceR * varianceB)
+		}
+	}
+	// L'ordonnée à l'origine est un rendement par sous-période, annualisé comme le
+	// tracking error selon le nombre moyen de sous-périodes par an
+	comparison.Alpha = (meanR - comparison.Beta*meanB) * n / years * 100
+	return comparison, nil
+}
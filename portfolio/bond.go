@@ -0,0 +1,189 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// Bond décrit une obligation à coupon fixe remboursée in fine au pair. La valeur de la
+// ligne ne dépend pas de NAV saisies : elle est la valeur actuelle des flux restants au
+// taux actuariel d'achat (coût amorti), matérialisée en NAV de fin de mois, et les
+// coupons échus sont enregistrés comme distributions versées (voir AccrueInterest).
+type Bond struct {
+	FaceValue  Money    `json:"face_value"`  // Valeur nominale d'un titre
+	Quantity   Quantity `json:"quantity"`    // Nombre de titres
+	CouponRate float64  `json:"coupon_rate"` // Taux du coupon annuel (% du nominal)
+	Frequency  int      `json:"frequency"`   // Coupons par an (1, 2, 4 ou 12)
+	Maturity   Date     `json:"maturity"`    // Date de remboursement
+	Yield      float64  `json:"yield"`       // Taux actuariel à l'achat (%), calculé par AddBond
+
+	conventions analytics.RateConventions // Conventions du portefeuille détenteur (voir Portfolio.attach)
+	calendar    *Calendar                 // Calendrier du portefeuille détenteur (voir Portfolio.attach)
+}
+
+// coupon retourne le montant d'un coupon pour l'ensemble des titres
+func (b *Bond) coupon() Money {
+	return b.FaceValue.Mul(b.Quantity.Float64() * b.CouponRate / 100 / float64(b.Frequency))
+}
+
+// couponDates retourne les dates de coupon, de la dernière antérieure ou égale à from
+// jusqu'à l'échéance, en remontant depuis l'échéance
+func (b *Bond) couponDates(from time.Time) []time.Time {
+	months := 12 / b.Frequency
+	var dates []time.Time
+	for k := 0; ; k++ {
+		date := monthsBefore(b.Maturity.Time, k*months)
+		dates = append([]time.Time{date}, dates...)
+		if !date.After(from) {
+			break
+		}
+	}
+	return dates
+}
+
+// monthsBefore retourne la date n mois avant t, ramenée au dernier jour du mois si le
+// jour de t n'y existe pas (31 août - 6 mois = 28 ou 29 février, et non 2 ou 3 mars)
+func monthsBefore(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()-time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
+
+// AccruedInterest retourne le coupon couru à une date (jours exacts sur jours exacts de
+// la période de coupon)
+func (b *Bond) AccruedInterest(date time.Time) Money {
+	if !date.Before(b.Maturity.Time) {
+		return 0
+	}
+	dates := b.couponDates(date)
+	previous, next := dates[0], dates[1]
+	return b.coupon().Mul(date.Sub(previous).Hours() / next.Sub(previous).Hours()).RoundCents()
+}
+
+// CashFlows retourne les flux futurs postérieurs à une date : coupons et remboursement
+// du nominal à l'échéance, payés au jour ouvré selon le calendrier du portefeuille (les
+// intérêts courent jusqu'aux dates non reportées)
+func (b *Bond) CashFlows(after time.Time) []CashFlow {
+	var flows []CashFlow
+	for _, date := range b.couponDates(after)[1:] {
+		amount := b.coupon()
+		if date.Equal(b.Maturity.Time) {
+			amount += b.FaceValue.Mul(b.Quantity.Float64())
+		}
+		if paid := b.calendar.Roll(date); paid.After(after) {
+			flows = append(flows, CashFlow{Date: Date{paid}, Amount: amount, Type: Withdrawal})
+		}
+	}
+	return flows
+}
+
+// DirtyPrice convertit un prix pied de coupon (% du nominal) en prix coupon couru total
+func (b *Bond) DirtyPrice(cleanPercent float64, date time.Time) Money {
+	return b.FaceValue.Mul(b.Quantity.Float64()*cleanPercent/100) + b.AccruedInterest(date)
+}
+
+// YieldToMaturity calcule le taux actuariel annuel (%) d'un achat au prix coupon couru
+// dirty à une date, coupons supposés réinvestis à ce même taux
+func (b *Bond) YieldToMaturity(dirty Money, date time.Time) (float64, error) {
+	if !date.Before(b.Maturity.Time) {
+		return 0, fmt.Errorf("l'obligation est échue au %s", FormatDate(date))
+	}
+	flows := []analytics.Flow{{Date: date, Amount: -dirty.Float64()}}
+	for _, cf := range b.CashFlows(date) {
+		flows = append(flows, analytics.Flow{Date: cf.Date.Time, Amount: cf.Amount.Float64()})
+	}
+	return b.conventions.XIRR(flows)
+}
+
+// presentValue actualise au taux actuariel d'achat les flux postérieurs à une date
+func (b *Bond) presentValue(date time.Time) float64 {
+	value := 0.0
+	for _, cf := range b.CashFlows(date) {
+		value += cf.Amount.Float64() / math.Pow(1+b.Yield/100, b.conventions.YearsBetween(date, cf.Date.Time))
+	}
+	return value
+}
+
+// refreshBond recalcule les NAV (coût amorti en fin de mois et à until, nominal à
+// l'échéance) et les coupons versés jusqu'à until
+func (inv *Investment) refreshBond(until time.Time) {
+	b := inv.Bond
+	end := until
+	if b.Maturity.Before(end) {
+		end = b.Maturity.Time
+	}
+
+	var navs []NAV
+	for day := inv.InvestmentDate.AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		monthEnd := day.AddDate(0, 0, 1).Month() != day.Month()
+		switch {
+		case day.Equal(b.Maturity.Time):
+			navs = append(navs, NAV{Date: Date{day}, Value: b.FaceValue.Mul(b.Quantity.Float64()).RoundCents()})
+		case monthEnd || day.Equal(end):
+			navs = append(navs, NAV{Date: Date{day}, Value: NewMoney(b.presentValue(day)).RoundCents()})
+		}
+	}
+	inv.NAVHistory = navs
+
+	inv.Distributions = nil
+	for _, cf := range b.CashFlows(inv.InvestmentDate.Time) {
+		if cf.Date.After(until) {
+			break
+		}
+		inv.Distributions = append(inv.Distributions, Distribution{Date: cf.Date, Amount: b.coupon()})
+	}
+	inv.invalidate()
+}
+
+// AddBond ajoute une obligation achetée à une date au prix pied de coupon cleanPercent
+// (% du nominal) ; le montant investi est le prix coupon couru
+func (p *Portfolio) AddBond(name string, b Bond, cleanPercent float64, date string) error {
+	t, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+	switch b.Frequency {
+	case 1, 2, 4, 12:
+	default:
+		return InvalidField("frequency", b.Frequency, "fréquence de coupon invalide: %d (1, 2, 4 ou 12)", b.Frequency)
+	}
+	if b.FaceValue <= 0 || b.Quantity <= 0 || b.CouponRate < 0 || cleanPercent <= 0 {
+		return fmt.Errorf("nominal, quantité, coupon et prix doivent être positifs: %w", ErrInvalidAmount)
+	}
+	if !t.Before(b.Maturity.Time) {
+		return fmt.Errorf("l'échéance doit être postérieure à l'achat: %w", ErrInvalidDate)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// L'obligation est complète avant d'être ajoutée : aucun lecteur ne voit la ligne
+	// sans son échéancier
+	b.conventions = p.rateConventions()
+	b.calendar = p.Calendar
+	dirty := b.DirtyPrice(cleanPercent, t)
+	if b.Yield, err = b.YieldToMaturity(dirty, t); err != nil {
+		return err
+	}
+	inv := &Investment{
+		ID:             newInvestmentID(),
+		Name:           name,
+		AmountInvested: dirty,
+		ReferenceRate:  b.Yield,
+		NAVHistory:     make([]NAV, 0),
+		InvestmentDate: Date{t},
+		metrics:        newMetricsCache(),
+		Bond:           &b,
+	}
+
+	before := p.investmentState(name)
+	p.Investments[name] = inv
+	p.attach(inv)
+	inv.refreshBond(Today())
+	p.record(OpAddInvestment, name, fmt.Sprintf("obligation de %.2f au %s, taux actuariel %.2f%%", dirty.Float64(), date, b.Yield), before)
+	p.investmentAdded(name)
+	return nil
+}
@@ -0,0 +1,139 @@
+package portfolio
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCouponDatesMonthEnd(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := ParseDate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		name      string
+		maturity  string
+		frequency int
+		from      string
+		want      []string
+	}{
+		{name: "semestriel, février", maturity: "2030-08-31", frequency: 2, from: "2029-03-15", want: []string{"2029-02-28", "2029-08-31", "2030-02-28", "2030-08-31"}},
+		{name: "semestriel, année bissextile", maturity: "2028-08-31", frequency: 2, from: "2028-01-01", want: []string{"2027-08-31", "2028-02-29", "2028-08-31"}},
+		{name: "trimestriel", maturity: "2030-05-31", frequency: 4, from: "2029-09-01", want: []string{"2029-08-31", "2029-11-30", "2030-02-28", "2030-05-31"}},
+		{name: "mensuel", maturity: "2030-03-31", frequency: 12, from: "2030-01-15", want: []string{"2029-12-31", "2030-01-31", "2030-02-28", "2030-03-31"}},
+		{name: "annuel", maturity: "2030-02-28", frequency: 1, from: "2028-12-01", want: []string{"2028-02-28", "2029-02-28", "2030-02-28"}},
+		{name: "milieu de mois", maturity: "2030-06-15", frequency: 2, from: "2029-12-15", want: []string{"2029-12-15", "2030-06-15"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Bond{Frequency: tt.frequency, Maturity: Date{date(tt.maturity)}}
+			got := b.couponDates(date(tt.from))
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d dates de coupon, %d attendues: %v", len(got), len(tt.want), got)
+			}
+			for i, d := range got {
+				if FormatDate(d) != tt.want[i] {
+					t.Errorf("coupon %d au %s, %s attendu", i, FormatDate(d), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAddBond(t *testing.T) {
+	p := NewPortfolio()
+	maturity, err := ParseDate("2030-08-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := Bond{FaceValue: NewMoney(1000), Quantity: NewQuantity(10), CouponRate: 4, Frequency: 2, Maturity: Date{maturity}}
+	if err := p.AddBond("OAT", b, 100, "2025-02-28"); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := p.Investment("OAT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Bond == nil {
+		t.Fatal("obligation absente de l'investissement")
+	}
+	if inv.AmountInvested != NewMoney(10000) {
+		t.Errorf("montant investi %s, 10000.00 attendu (achat au pair le jour d'un coupon)", inv.AmountInvested)
+	}
+	if y := inv.Bond.Yield; y < 3.9 || y > 4.1 {
+		t.Errorf("taux actuariel %.3f%%, 4%% environ attendu", y)
+	}
+	if len(inv.NAVHistory) == 0 {
+		t.Error("aucune NAV calculée")
+	}
+	for _, d := range inv.Distributions {
+		if day := d.Date.Day(); day != 28 && day != 29 && day != 31 {
+			t.Errorf("coupon versé le %s, en fin de mois attendu", FormatDate(d.Date.Time))
+		}
+	}
+
+	if _, err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Investment("OAT"); err == nil {
+		t.Error("l'obligation devrait être retirée par l'annulation")
+	}
+
+	for _, tt := range []struct {
+		name  string
+		bond  Bond
+		price float64
+		date  string
+	}{
+		{name: "fréquence", bond: Bond{FaceValue: NewMoney(1000), Quantity: NewQuantity(1), Frequency: 3, Maturity: Date{maturity}}, price: 100, date: "2025-01-01"},
+		{name: "nominal", bond: Bond{Quantity: NewQuantity(1), Frequency: 1, Maturity: Date{maturity}}, price: 100, date: "2025-01-01"},
+		{name: "prix", bond: Bond{FaceValue: NewMoney(1000), Quantity: NewQuantity(1), Frequency: 1, Maturity: Date{maturity}}, date: "2025-01-01"},
+		{name: "échéance passée", bond: Bond{FaceValue: NewMoney(1000), Quantity: NewQuantity(1), Frequency: 1, Maturity: Date{maturity}}, price: 100, date: "2031-01-01"},
+	} {
+		if err := p.AddBond("X", tt.bond, tt.price, tt.date); err == nil {
+			t.Errorf("%s: erreur attendue", tt.name)
+		}
+	}
+}
+
+func TestAddBondConcurrentReaders(t *testing.T) {
+	p := NewPortfolio()
+	maturity, err := ParseDate("2030-06-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for _, name := range p.InvestmentNames() {
+					if inv, err := p.Investment(name); err == nil && inv.Bond == nil {
+						t.Errorf("%s visible sans son obligation", name)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		b := Bond{FaceValue: NewMoney(1000), Quantity: NewQuantity(1), CouponRate: 3, Frequency: 1, Maturity: Date{maturity}}
+		if err := p.AddBond(string(rune('A'+i)), b, 99, "2025-01-02"); err != nil {
+			t.Error(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,396 @@
+package portfolio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// degiroImporter lit l'export « Transactions » de Degiro, en français ou en anglais :
+// une ligne par exécution, quantité négative pour une vente, frais négatifs
+type degiroImporter struct{}
+
+func (degiroImporter) Name() string { return "degiro" }
+
+func (degiroImporter) Detect(head []byte) bool {
+	first, _, _ := bytes.Cut(head, []byte("\n"))
+	first = bytes.ToLower(first)
+	return bytes.Contains(first, []byte("isin")) &&
+		(bytes.Contains(first, []byte("id ordre")) || bytes.Contains(first, []byte("order id")))
+}
+
+func (degiroImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	t, err := readCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		date     = []string{"date"}
+		product  = []string{"produit", "product"}
+		isin     = []string{"code isin", "isin"}
+		quantity = []string{"quantité", "quantity"}
+		price    = []string{"cours", "price"}
+		fees     = []string{"frais de courtage", "transaction and/or third party fees", "transaction costs"}
+		total    = []string{"total", "montant négocié"}
+	)
+	if err := t.require("Degiro", date, isin, quantity, price); err != nil {
+		return nil, err
+	}
+
+	acct := StatementAccount{ID: DefaultString(opts.Account, "degiro")}
+	for i, row := range t.rows {
+		e, err := func() (e StatementEntry, err error) {
+			if e.Date, err = parseBrokerDate(t.get(row, date...), "02-01-2006", "02/01/2006", "2006-01-02"); err != nil {
+				return e, err
+			}
+			e.Security = StatementSecurity{ID: t.get(row, isin...), Name: t.get(row, product...)}
+			e.Description = e.Security.Name
+			units, err := parseStatementAmount(t.get(row, quantity...))
+			if err != nil {
+				return e, err
+			}
+			e.Kind = StatementBuy
+			if units < 0 {
+				e.Kind = StatementSell
+			}
+			if e.Units, err = parseStatementUnits(t.get(row, quantity...)); err != nil {
+				return e, err
+			}
+			if e.Price, err = parseStatementAmount(t.get(row, price...)); err != nil {
+				return e, err
+			}
+			fee, err := optionalAmount(t.get(row, fees...))
+			if err != nil {
+				return e, err
+			}
+			e.Fees = absMoney(fee)
+			amount, err := optionalAmount(t.get(row, total...))
+			e.Amount = absMoney(amount)
+			return e, err
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("export Degiro: %w", CSVLineError{Line: t.first + i, Err: err})
+		}
+		acct.Entries = append(acct.Entries, e)
+	}
+	return &Statement{Accounts: []StatementAccount{acct}}, nil
+}
+
+// boursoramaImporter lit les exports CSV de Boursorama : mouvements d'un compte
+// (dateOp;dateVal;label;...;amount;...;accountNum) ou historique des opérations d'un
+// compte titres ou d'un PEA (Date;Opération;Libellé;Code ISIN;Quantité;Cours;Montant;Frais)
+type boursoramaImporter struct{}
+
+func (boursoramaImporter) Name() string { return "boursorama" }
+
+func (boursoramaImporter) Detect(head []byte) bool {
+	first, _, _ := bytes.Cut(head, []byte("\n"))
+	first = bytes.ToLower(first)
+	return bytes.HasPrefix(bytes.TrimPrefix(first, []byte("\ufeff")), []byte("dateop;")) ||
+		(bytes.Contains(first, []byte(";")) && bytes.Contains(first, []byte("opération")) && bytes.Contains(first, []byte("code isin")))
+}
+
+// boursoramaOperations associe le début des libellés d'opérations sur titres à leur nature
+var boursoramaOperations = []struct {
+	prefix string
+	kind   StatementEntryKind
+}{
+	{"achat", StatementBuy},
+	{"souscription", StatementBuy},
+	{"vente", StatementSell},
+	{"rachat", StatementSell},
+	{"remboursement", StatementSell},
+	{"dividende", StatementIncome},
+	{"coupon", StatementIncome},
+	{"revenu", StatementIncome},
+	{"intérêts", StatementIncome},
+	{"réinvestissement", StatementReinvest},
+	{"versement", StatementCash},
+	{"virement", StatementCash},
+	{"retrait", StatementCash},
+}
+
+func (boursoramaImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	t, err := readCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+	layouts := []string{"02/01/2006", "2006-01-02", "02-01-2006"}
+
+	// Mouvements d'un compte : un compte par numéro
+	if t.column("dateop") >= 0 {
+		if err := t.require("Boursorama", []string{"amount"}); err != nil {
+			return nil, err
+		}
+		st := &Statement{}
+		index := make(map[string]int)
+		for i, row := range t.rows {
+			date, err := parseBrokerDate(t.get(row, "dateop"), layouts...)
+			if err != nil {
+				return nil, fmt.Errorf("export Boursorama: %w", CSVLineError{Line: t.first + i, Err: err})
+			}
+			amount, err := parseStatementAmount(t.get(row, "amount"))
+			if err != nil {
+				return nil, fmt.Errorf("export Boursorama: %w", CSVLineError{Line: t.first + i, Err: err})
+			}
+			id := DefaultString(t.get(row, "accountnum"), DefaultString(opts.Account, "boursorama"))
+			n, exists := index[id]
+			if !exists {
+				n = len(st.Accounts)
+				index[id] = n
+				st.Accounts = append(st.Accounts, StatementAccount{ID: id})
+			}
+			st.Accounts[n].Entries = append(st.Accounts[n].Entries,
+				StatementEntry{Date: date, Kind: StatementCash, Amount: amount, Description: t.get(row, "label")})
+		}
+		return st, nil
+	}
+
+	var (
+		date      = []string{"date opération", "date d'opération", "date"}
+		operation = []string{"opération", "type d'opération", "sens"}
+		label     = []string{"libellé", "valeur", "nom"}
+		isin      = []string{"code isin", "isin"}
+		quantity  = []string{"quantité"}
+		price     = []string{"cours", "cours d'exécution"}
+		amount    = []string{"montant net", "montant"}
+		fees      = []string{"frais", "courtage", "commission"}
+	)
+	if err := t.require("Boursorama", date, operation, amount); err != nil {
+		return nil, err
+	}
+	acct := StatementAccount{ID: DefaultString(opts.Account, "boursorama")}
+	for i, row := range t.rows {
+		e, ok, err := func() (e StatementEntry, ok bool, err error) {
+			op := strings.ToLower(t.get(row, operation...))
+			for _, o := range boursoramaOperations {
+				if strings.HasPrefix(op, o.prefix) {
+					e.Kind, ok = o.kind, true
+					break
+				}
+			}
+			if !ok {
+				return e, false, nil
+			}
+			if e.Date, err = parseBrokerDate(t.get(row, date...), layouts...); err != nil {
+				return e, false, err
+			}
+			e.Description = strings.TrimSpace(t.get(row, operation...) + " " + t.get(row, label...))
+			if e.Amount, err = parseStatementAmount(t.get(row, amount...)); err != nil {
+				return e, false, err
+			}
+			if e.Kind == StatementCash {
+				if strings.HasPrefix(op, "retrait") && e.Amount > 0 {
+					e.Amount = -e.Amount
+				}
+				return e, true, nil
+			}
+			e.Amount = absMoney(e.Amount)
+			e.Security = StatementSecurity{ID: t.get(row, isin...), Name: t.get(row, label...)}
+			if e.Kind != StatementBuy && e.Kind != StatementSell {
+				return e, true, nil
+			}
+			if e.Units, err = parseStatementUnits(t.get(row, quantity...)); err != nil {
+				return e, false, err
+			}
+			if e.Price, err = parseStatementAmount(t.get(row, price...)); err != nil {
+				return e, false, err
+			}
+			fee, err := optionalAmount(t.get(row, fees...))
+			e.Fees = absMoney(fee)
+			return e, true, err
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("export Boursorama: %w", CSVLineError{Line: t.first + i, Err: err})
+		}
+		if ok {
+			acct.Entries = append(acct.Entries, e)
+		}
+	}
+	return &Statement{Accounts: []StatementAccount{acct}}, nil
+}
+
+// ibkrImporter lit le relevé d'activité CSV d'Interactive Brokers : un fichier fait de
+// sections (Trades, Dividends, Deposits & Withdrawals, Open Positions...) dont chaque
+// ligne commence par le nom de la section et le type de ligne (Header, Data, Total)
+type ibkrImporter struct{}
+
+func (ibkrImporter) Name() string { return "ibkr" }
+
+func (ibkrImporter) Detect(head []byte) bool {
+	first, _, _ := bytes.Cut(head, []byte("\n"))
+	return bytes.HasPrefix(bytes.TrimPrefix(first, []byte("\ufeff")), []byte("Statement,Header,"))
+}
+
+// ibkrSecurity extrait le symbole et l'ISIN d'une description de dividende
+// (« AAPL(US0378331005) Cash Dividend USD 0.24 per Share »)
+func ibkrSecurity(description string) StatementSecurity {
+	symbol, rest, found := strings.Cut(description, "(")
+	if !found {
+		symbol, _, _ = strings.Cut(description, " ")
+		return StatementSecurity{Ticker: strings.TrimSpace(symbol)}
+	}
+	id, _, _ := strings.Cut(rest, ")")
+	return StatementSecurity{Ticker: strings.TrimSpace(symbol), ID: strings.TrimSpace(id)}
+}
+
+func (ibkrImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("relevé Interactive Brokers: %w", err)
+	}
+
+	acct := StatementAccount{ID: opts.Account}
+	var periodEnd time.Time
+	isins := make(map[string]string) // ISIN par symbole (Financial Instrument Information)
+	type position struct {
+		symbol string
+		pos    StatementPosition
+	}
+	var positions []position
+	headers := make(map[string]*csvTable) // En-tête courant de chaque section
+
+	for i, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		section, kind, fields := record[0], record[1], record[2:]
+		if kind == "Header" {
+			t := &csvTable{columns: make(map[string]int)}
+			t.setHeader(fields)
+			headers[section] = t
+			continue
+		}
+		t := headers[section]
+		if kind != "Data" || t == nil {
+			continue
+		}
+		line := i + 1
+		fail := func(err error) (*Statement, error) {
+			return nil, fmt.Errorf("relevé Interactive Brokers: %w", CSVLineError{Line: line, Err: fmt.Errorf("%s: %w", section, err)})
+		}
+
+		switch section {
+		case "Statement":
+			if t.get(fields, "Field Name") == "Period" {
+				period := t.get(fields, "Field Value")
+				if _, end, found := strings.Cut(period, " - "); found {
+					period = end
+				}
+				if end, err := time.Parse("January 2, 2006", strings.TrimSpace(period)); err == nil {
+					periodEnd = end
+				}
+			}
+		case "Account Information":
+			if t.get(fields, "Field Name") == "Account" && acct.ID == "" {
+				acct.ID = t.get(fields, "Field Value")
+			}
+			if t.get(fields, "Field Name") == "Base Currency" {
+				acct.Currency = Currency(t.get(fields, "Field Value"))
+			}
+		case "Financial Instrument Information":
+			if id := t.get(fields, "Security ID"); id != "" {
+				isins[t.get(fields, "Symbol")] = id
+			}
+		case "Trades":
+			if t.get(fields, "DataDiscriminator") != "Order" || t.get(fields, "Asset Category") == "Forex" {
+				continue
+			}
+			e := StatementEntry{Security: StatementSecurity{Ticker: t.get(fields, "Symbol")}, Description: t.get(fields, "Symbol")}
+			if e.Date, err = parseBrokerDate(t.get(fields, "Date/Time"), "2006-01-02"); err != nil {
+				return fail(err)
+			}
+			units, err := parseStatementAmount(t.get(fields, "Quantity"))
+			if err != nil {
+				return fail(err)
+			}
+			e.Kind = StatementBuy
+			if units < 0 {
+				e.Kind = StatementSell
+			}
+			if e.Units, err = parseStatementUnits(t.get(fields, "Quantity")); err != nil {
+				return fail(err)
+			}
+			if e.Price, err = parseStatementAmount(t.get(fields, "T. Price")); err != nil {
+				return fail(err)
+			}
+			fee, err := optionalAmount(t.get(fields, "Comm/Fee", "Comm in EUR", "Commission"))
+			if err != nil {
+				return fail(err)
+			}
+			proceeds, err := optionalAmount(t.get(fields, "Proceeds"))
+			if err != nil {
+				return fail(err)
+			}
+			e.Fees, e.Amount = absMoney(fee), absMoney(proceeds)
+			acct.Entries = append(acct.Entries, e)
+		case "Dividends", "Deposits & Withdrawals":
+			currency := t.get(fields, "Currency")
+			if strings.HasPrefix(currency, "Total") {
+				continue
+			}
+			e := StatementEntry{Kind: StatementIncome, Description: t.get(fields, "Description")}
+			if e.Date, err = parseBrokerDate(t.get(fields, "Date", "Settle Date"), "2006-01-02"); err != nil {
+				return fail(err)
+			}
+			if e.Amount, err = parseStatementAmount(t.get(fields, "Amount")); err != nil {
+				return fail(err)
+			}
+			if section == "Dividends" {
+				e.Security = ibkrSecurity(e.Description)
+				e.Amount = absMoney(e.Amount)
+			} else {
+				e.Kind = StatementCash
+			}
+			acct.Entries = append(acct.Entries, e)
+		case "Open Positions":
+			if t.get(fields, "DataDiscriminator") != "Summary" {
+				continue
+			}
+			p := StatementPosition{Security: StatementSecurity{Ticker: t.get(fields, "Symbol")}}
+			if p.Units, err = parseStatementUnits(t.get(fields, "Quantity")); err != nil {
+				return fail(err)
+			}
+			if p.Price, err = optionalAmount(t.get(fields, "Close Price")); err != nil {
+				return fail(err)
+			}
+			if p.Value, err = optionalAmount(t.get(fields, "Value")); err != nil {
+				return fail(err)
+			}
+			positions = append(positions, position{symbol: p.Security.Ticker, pos: p})
+		}
+	}
+
+	// Les positions sont datées de la fin de la période du relevé et les titres
+	// complétés par leur ISIN, connus seulement en fin de fichier
+	if !periodEnd.IsZero() {
+		for _, p := range positions {
+			p.pos.Date = periodEnd
+			p.pos.Security.ID = isins[p.symbol]
+			acct.Positions = append(acct.Positions, p.pos)
+		}
+	}
+	for i := range acct.Entries {
+		if s := &acct.Entries[i].Security; s.ID == "" && s.Ticker != "" {
+			s.ID = isins[s.Ticker]
+		}
+	}
+	if acct.ID == "" {
+		acct.ID = "ibkr"
+	}
+	return &Statement{Accounts: []StatementAccount{acct}}, nil
+}
+
+// DefaultString retourne s, ou fallback s'il est vide
+func DefaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,212 @@
+package portfolio
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventKind est le type d'un événement diffusé aux abonnés du portefeuille
+type EventKind string
+
+const (
+	EventInvestmentAdded EventKind = "investment-added"
+	EventNAVAdded        EventKind = "nav-added"
+	EventValueRecomputed EventKind = "value-recomputed"
+	EventModified        EventKind = "modified"
+)
+
+// eventBuffer est la capacité du canal de chaque abonné
+const eventBuffer = 64
+
+// Event est un événement diffusé après une modification du portefeuille : InvestmentAdded,
+// NAVAdded, ValueRecomputed ou Modified
+type Event interface {
+	Kind() EventKind
+}
+
+// InvestmentAdded signale l'ajout d'un investissement
+type InvestmentAdded struct {
+	Time       time.Time `json:"time"`
+	Investment string    `json:"investment"`
+}
+
+// NAVAdded signale une NAV ajoutée ou remplacée, quelle qu'en soit la source (saisie,
+// import, ingestion, cours)
+type NAVAdded struct {
+	Time       time.Time `json:"time"`
+	Investment string    `json:"investment"`
+	NAV        NAV       `json:"nav"`
+}
+
+// ValueRecomputed signale la nouvelle valeur d'un investissement après une modification,
+// calculée comme dans Summary : dernière NAV (montant investi à défaut), part acquise et
+// sens de la position compris, convertie dans la devise de consolidation
+type ValueRecomputed struct {
+	Time       time.Time `json:"time"`
+	Investment string    `json:"investment"`
+	Date       time.Time `json:"date"` // Date de la NAV (ou de l'investissement) valorisée
+	Value      float64   `json:"value"`
+	Currency   Currency  `json:"currency"` // Devise de consolidation
+}
+
+// Modified signale toute autre modification : opération journalisée, réglage, données
+// annexes (règles d'alerte, instantanés, plans…) ou source de taux de change
+type Modified struct {
+	Time       time.Time `json:"time"`
+	Change     string    `json:"change"`               // Opération du journal (voir JournalOp) ou nature de la modification
+	Investment string    `json:"investment,omitempty"` // Investissement modifié, vide pour le portefeuille
+}
+
+func (InvestmentAdded) Kind() EventKind { return EventInvestmentAdded }
+
+func (NAVAdded) Kind() EventKind { return EventNAVAdded }
+
+func (ValueRecomputed) Kind() EventKind { return EventValueRecomputed }
+
+func (Modified) Kind() EventKind { return EventModified }
+
+// subscription est l'abonnement d'un client aux événements de certains types (tous si vide)
+type subscription struct {
+	ch    chan Event
+	kinds []EventKind
+}
+
+// eventBus diffuse les événements du portefeuille à ses abonnés
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+// Subscribe abonne un client aux événements des types donnés (tous si aucun) et retourne
+// leur canal et la fonction de désabonnement, qui ferme le canal. Les événements sont
+// émis pendant la modification : un abonné ne doit pas modifier le portefeuille depuis la
+// réception, et un abonné trop lent pour vider son canal manque les événements suivants
+// plutôt que de bloquer le portefeuille.
+func (p *Portfolio) Subscribe(kinds ...EventKind) (<-chan Event, func()) {
+	p.mu.Lock()
+	if p.bus == nil {
+		p.bus = &eventBus{subs: make(map[*subscription]struct{})}
+	}
+	bus := p.bus
+	p.mu.Unlock()
+
+	sub := &subscription{ch: make(chan Event, eventBuffer), kinds: kinds}
+	bus.mu.Lock()
+	bus.subs[sub] = struct{}{}
+	bus.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			bus.mu.Lock()
+			defer bus.mu.Unlock()
+			delete(bus.subs, sub)
+			close(sub.ch)
+		})
+	}
+}
+
+// subscribed indique si des événements ont au moins un abonné ; l'appelant doit détenir p.mu
+func (p *Portfolio) subscribed() bool {
+	if p.bus == nil {
+		return false
+	}
+	p.bus.mu.Lock()
+	defer p.bus.mu.Unlock()
+	return len(p.bus.subs) > 0
+}
+
+// emit diffuse des événements aux abonnés intéressés ; l'appelant doit détenir p.mu
+func (p *Portfolio) emit(events ...Event) {
+	if p.bus == nil {
+		return
+	}
+	p.bus.mu.Lock()
+	defer p.bus.mu.Unlock()
+
+	for _, event := range events {
+		for sub := range p.bus.subs {
+			if len(sub.kinds) > 0 && !slices.Contains(sub.kinds, event.Kind()) {
+				continue
+			}
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		}
+	}
+}
+
+// Revision retourne le compteur de modifications du portefeuille : il augmente à chaque
+// modification, NAV ajoutée ou rechargement, ce qui permet d'invalider les résultats
+// calculés sur un état antérieur
+func (p *Portfolio) Revision() uint64 {
+	return p.revision.Load()
+}
+
+// changed compte une modification et la diffuse (voir Modified) ; l'appelant doit détenir
+// p.mu
+func (p *Portfolio) changed(change, investment string) {
+	p.revision.Add(1)
+	if !p.subscribed() {
+		return
+	}
+	p.emit(Modified{Time: time.Now().UTC(), Change: change, Investment: investment})
+}
+
+// investmentAdded diffuse l'ajout d'un investissement et sa valeur ; l'appelant doit
+// détenir p.mu
+func (p *Portfolio) investmentAdded(name string) {
+	p.revision.Add(1)
+	if !p.subscribed() {
+		return
+	}
+	p.emit(InvestmentAdded{Time: time.Now().UTC(), Investment: name})
+	p.valueChanged(name)
+}
+
+// navsAdded diffuse les NAV ajoutées à un investissement puis sa nouvelle valeur ;
+// l'appelant doit détenir p.mu
+func (p *Portfolio) navsAdded(name string, navs ...NAV) {
+	p.revision.Add(1)
+	if !p.subscribed() {
+		return
+	}
+	now := time.Now().UTC()
+	events := make([]Event, len(navs))
+	for i, nav := range navs {
+		events[i] = NAVAdded{Time: now, Investment: name, NAV: nav}
+	}
+	p.emit(events...)
+	p.valueChanged(name)
+}
+
+// valueChanged diffuse la valeur d'un investissement après une modification. Rien n'est
+// diffusé pour un investissement supprimé, clôturé ou écarté faute de NAV, ni si sa
+// devise n'a pas de taux de change. L'appelant doit détenir p.mu.
+func (p *Portfolio) valueChanged(name string) {
+	p.revision.Add(1)
+	if !p.subscribed() {
+		return
+	}
+	inv, exists := p.Investments[name]
+	if !exists || inv.Closed {
+		return
+	}
+	date := inv.InvestmentDate
+	if latest, err := inv.GetLatestNAV(); err == nil {
+		date = latest.Date
+	}
+	converted, held, err := p.investmentValueAt(inv, date.Time)
+	if err != nil || !held {
+		return
+	}
+	p.emit(ValueRecomputed{
+		Time:       time.Now().UTC(),
+		Investment: name,
+		Date:       date.Time,
+		Value:      NewMoney(converted).RoundCents().Float64(),
+		Currency:   p.baseCurrency(),
+	})
+}
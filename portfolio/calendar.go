@@ -0,0 +1,184 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HolidaySet est un jeu de jours fériés prédéfini
+type HolidaySet string
+
+const (
+	HolidaysTarget   HolidaySet = "target"   // Jours de fermeture de TARGET2 (règlements en euros)
+	HolidaysEuronext HolidaySet = "euronext" // Jours de fermeture d'Euronext, identiques à ceux de TARGET
+	HolidaysFrance   HolidaySet = "france"   // Jours fériés légaux en France (prélèvements bancaires)
+)
+
+// RollConvention est la règle de report d'une date tombant un jour non ouvré
+type RollConvention string
+
+const (
+	RollFollowing         RollConvention = "following"          // Jour ouvré suivant (convention par défaut)
+	RollModifiedFollowing RollConvention = "modified-following" // Jour ouvré suivant, ou précédent s'il change de mois
+	RollPreceding         RollConvention = "preceding"          // Jour ouvré précédent
+	RollModifiedPreceding RollConvention = "modified-preceding" // Jour ouvré précédent, ou suivant s'il change de mois
+	RollUnadjusted        RollConvention = "unadjusted"         // Date conservée
+)
+
+// Calendar est le calendrier des jours ouvrés du portefeuille : les week-ends, les jours
+// fériés du jeu Holidays et les jours Extra sont chômés. Les échéances des plans de
+// versements et les coupons des obligations sont reportés selon Rolling, et watch ne
+// met pas à jour les cours un jour chômé.
+type Calendar struct {
+	Holidays HolidaySet     `json:"holidays,omitempty"` // Jeu de jours fériés, week-ends seuls si vide
+	Extra    []Date         `json:"extra,omitempty"`    // Jours chômés supplémentaires
+	Rolling  RollConvention `json:"rolling,omitempty"`  // Report des dates, following si vide
+}
+
+// validate vérifie le jeu de jours fériés et la règle de report
+func (c *Calendar) validate() error {
+	switch c.Holidays {
+	case "", HolidaysTarget, HolidaysEuronext, HolidaysFrance:
+	default:
+		return InvalidField("holidays", c.Holidays, "jours fériés inconnus: %s (target, euronext, france)", c.Holidays)
+	}
+	switch c.Rolling {
+	case "", RollFollowing, RollModifiedFollowing, RollPreceding, RollModifiedPreceding, RollUnadjusted:
+	default:
+		return InvalidField("rolling", c.Rolling, "règle de report inconnue: %s (following, modified-following, preceding, modified-preceding, unadjusted)", c.Rolling)
+	}
+	return nil
+}
+
+// easter retourne le dimanche de Pâques d'une année (calendrier grégorien)
+func easter(year int) time.Time {
+	a, b, c := year%19, year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// isHoliday indique si une date est fériée dans le jeu
+func (h HolidaySet) isHoliday(t time.Time) bool {
+	month, day := t.Month(), t.Day()
+	sinceEaster := int(t.Sub(easter(t.Year())).Hours() / 24)
+	switch h {
+	case HolidaysTarget, HolidaysEuronext:
+		// Nouvel an, Vendredi saint, lundi de Pâques, fête du travail, Noël et lendemain
+		return (month == time.January && day == 1) || sinceEaster == -2 || sinceEaster == 1 ||
+			(month == time.May && day == 1) || (month == time.December && (day == 25 || day == 26))
+	case HolidaysFrance:
+		// Lundi de Pâques, Ascension et lundi de Pentecôte, puis les fêtes à date fixe
+		if sinceEaster == 1 || sinceEaster == 39 || sinceEaster == 50 {
+			return true
+		}
+		fixed := map[time.Month][]int{time.January: {1}, time.May: {1, 8}, time.July: {14},
+			time.August: {15}, time.November: {1, 11}, time.December: {25}}
+		for _, d := range fixed[month] {
+			if d == day {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsBusinessDay indique si une date est ouvrée ; sans calendrier, tous les jours le sont
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday || c.Holidays.isHoliday(t) {
+		return false
+	}
+	for _, extra := range c.Extra {
+		if extra.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// step avance jour par jour dans une direction jusqu'au premier jour ouvré
+func (c *Calendar) step(t time.Time, direction int) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, direction)
+	}
+	return t
+}
+
+// Roll reporte une date non ouvrée selon la règle du calendrier ; sans calendrier, la
+// date est conservée
+func (c *Calendar) Roll(t time.Time) time.Time {
+	if c == nil {
+		return t
+	}
+	switch c.Rolling {
+	case RollUnadjusted:
+		return t
+	case RollPreceding:
+		return c.step(t, -1)
+	case RollModifiedFollowing:
+		if rolled := c.step(t, 1); rolled.Month() == t.Month() {
+			return rolled
+		}
+		return c.step(t, -1)
+	case RollModifiedPreceding:
+		if rolled := c.step(t, -1); rolled.Month() == t.Month() {
+			return rolled
+		}
+		return c.step(t, 1)
+	default:
+		return c.step(t, 1)
+	}
+}
+
+// BusinessCalendar retourne le calendrier du portefeuille, nil s'il n'en définit pas
+func (p *Portfolio) BusinessCalendar() *Calendar {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.Calendar
+}
+
+// SetCalendar enregistre le calendrier des jours ouvrés du portefeuille ; nil le supprime. Les versements et coupons déjà enregistrés ne sont pas déplacés.
+func (p *Portfolio) SetCalendar(c *Calendar) error {
+	if c != nil {
+		if err := c.validate(); err != nil {
+			return err
+		}
+		copied := *c
+		copied.Extra = append([]Date(nil), c.Extra...)
+		sort.Slice(copied.Extra, func(i, j int) bool { return copied.Extra[i].Before(copied.Extra[j].Time) })
+		c = &copied
+	}
+
+	p.mu.Lock()
+	before, err := p.settingsState("calendar")
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	p.Calendar = c
+	p.attachAll()
+	detail := "aucun calendrier"
+	if c != nil {
+		detail = fmt.Sprintf("%s, %s", c.Holidays, c.Rolling)
+	}
+	err = p.recordChange(JournalEntry{Op: OpSetCalendar, Detail: detail, BeforeSettings: before})
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Les coupons des obligations sont recalculés aux dates reportées
+	p.AccrueInterest(Today())
+	return nil
+}
@@ -0,0 +1,49 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarIsPerPortfolio(t *testing.T) {
+	// Deux portefeuilles dont le plan mensuel a sa première échéance un samedi, seul b
+	// roulant les dates au jour ouvré suivant
+	a, b := NewPortfolio(), NewPortfolio()
+	for _, p := range []*Portfolio{a, b} {
+		if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.AddNAV("A", "2025-01-01", 1060); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.AddRecurringPlan("P", "A", 100, StepMonthly, "2025-03-01", "2025-03-31"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.SetCalendar(&Calendar{Rolling: RollFollowing}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		p    *Portfolio
+		want string
+	}{
+		{name: "sans calendrier", p: a, want: "2025-03-01"},
+		{name: "jours ouvrés", p: b, want: "2025-03-03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flows, err := tt.p.MaterializePlans(time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(flows) != 1 {
+				t.Fatalf("%d versements, 1 attendu", len(flows))
+			}
+			if got := FormatDate(flows[0].Date); got != tt.want {
+				t.Errorf("versement au %s, %s attendu", got, tt.want)
+			}
+		})
+	}
+}
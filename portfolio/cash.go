@@ -0,0 +1,170 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// CashRate est le taux nominal annuel d'un compte à partir d'une date
+type CashRate struct {
+	From Date    `json:"from"` // Date d'effet
+	Rate float64 `json:"rate"` // Taux nominal annuel (%)
+}
+
+// CashAccount décrit un compte rémunéré (livret, compte à terme, fonds monétaire à taux
+// annoncé). Sa valeur ne dépend pas de NAV saisies : elle résulte des versements, des
+// retraits et des intérêts courus, matérialisés en NAV de fin de mois par AccrueInterest.
+type CashAccount struct {
+	Compounding analytics.Compounding `json:"compounding"`
+	Rates       []CashRate            `json:"rates"` // Historique des taux, trié par date d'effet
+}
+
+// rateAt retourne le taux nominal en vigueur à une date
+func (c *CashAccount) rateAt(t time.Time) float64 {
+	rate := 0.0
+	for _, r := range c.Rates {
+		if r.From.After(t) {
+			break
+		}
+		rate = r.Rate
+	}
+	return rate
+}
+
+// effectiveRate convertit un taux nominal en taux annuel équivalent compte tenu de la
+// capitalisation (%)
+func (c *CashAccount) effectiveRate(nominal float64) float64 {
+	switch c.Compounding {
+	case analytics.CompoundDaily:
+		return (math.Pow(1+nominal/100/365, 365) - 1) * 100
+	case analytics.CompoundMonthly:
+		return (math.Pow(1+nominal/100/12, 12) - 1) * 100
+	default:
+		return nominal
+	}
+}
+
+// accrue calcule, jour après jour depuis la date d'investissement, le solde du compte
+// intérêts courus compris, et retourne sa valeur à chaque fin de mois et à until
+func (inv *Investment) accrue(until time.Time) []NAV {
+	c := inv.Cash
+	flows := make(map[time.Time]Money)
+	for _, cf := range inv.CashFlows {
+		flows[cf.Date.Time] += cf.SignedAmount()
+	}
+
+	balance := inv.AmountInvested.Float64()
+	pending := 0.0 // Intérêts courus non encore crédités
+	var navs []NAV
+	for day := inv.InvestmentDate.Time; !day.After(until); day = day.AddDate(0, 0, 1) {
+		balance += flows[day].Float64()
+		if day.After(inv.InvestmentDate.Time) {
+			interest := max(balance, 0) * c.rateAt(day) / 100 / 365
+			if c.Compounding == analytics.CompoundDaily {
+				balance += interest
+			} else {
+				pending += interest
+			}
+		}
+		next := day.AddDate(0, 0, 1)
+		monthEnd := next.Month() != day.Month()
+		if (c.Compounding == analytics.CompoundMonthly && monthEnd) || (c.Compounding == analytics.CompoundYearly && next.Year() != day.Year()) {
+			balance += pending
+			pending = 0
+		}
+		if (monthEnd || day.Equal(until)) && day.After(inv.InvestmentDate.Time) {
+			navs = append(navs, NAV{Date: Date{day}, Value: NewMoney(balance + pending).RoundCents()})
+		}
+	}
+	return navs
+}
+
+// AccrueInterest recalcule les NAV des comptes rémunérés jusqu'à une date : une par fin
+// de mois et une à la date elle-même, intérêts courus compris. Les obligations sont
+// réévaluées de la même façon, coupons versés compris.
+func (p *Portfolio) AccrueInterest(until time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, inv := range p.Investments {
+		if inv.Cash == nil && inv.Bond == nil {
+			continue
+		}
+		end := until
+		if inv.Closed && inv.ClosedDate.Before(end) {
+			end = inv.ClosedDate.Time
+		}
+		if inv.Bond != nil {
+			inv.refreshBond(end)
+			continue
+		}
+		inv.NAVHistory = inv.accrue(end)
+		inv.invalidate()
+	}
+	p.changed("accrue-interest", "")
+}
+
+// AddCashAccount ajoute un compte rémunéré au taux nominal annuel rate (%)
+func (p *Portfolio) AddCashAccount(name string, amount, rate float64, compounding analytics.Compounding, date string) error {
+	if compounding != analytics.CompoundDaily && compounding != analytics.CompoundMonthly && compounding != analytics.CompoundYearly {
+		return InvalidField("compounding", compounding, "capitalisation inconnue: %s", compounding)
+	}
+	if rate < 0 {
+		return fmt.Errorf("le taux ne peut pas être négatif: %w", ErrInvalidAmount)
+	}
+	if err := p.AddInvestment(name, amount, rate, date); err != nil {
+		return err
+	}
+	t, _ := ParseDate(date) // Déjà validée par AddInvestment
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv := p.Investments[name]
+	inv.Cash = &CashAccount{Compounding: compounding, Rates: []CashRate{{From: Date{t}, Rate: rate}}}
+	inv.NAVHistory = inv.accrue(Today())
+	inv.invalidate()
+	p.changed("add-cash-account", name)
+	p.valueChanged(name)
+	return nil
+}
+
+// SetCashRate enregistre un changement de taux d'un compte rémunéré à partir d'une date
+func (p *Portfolio) SetCashRate(name, from string, rate float64) error {
+	t, err := ParseDate(from)
+	if err != nil {
+		return err
+	}
+	if rate < 0 {
+		return fmt.Errorf("le taux ne peut pas être négatif: %w", ErrInvalidAmount)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	if inv.Cash == nil {
+		return fmt.Errorf("'%s' n'est pas un compte rémunéré", name)
+	}
+	before := inv.clone()
+	rates := inv.Cash.Rates[:0:0]
+	for _, r := range inv.Cash.Rates {
+		if !r.From.Equal(t) {
+			rates = append(rates, r)
+		}
+	}
+	rates = append(rates, CashRate{From: Date{t}, Rate: rate})
+	sort.Slice(rates, func(i, j int) bool { return rates[i].From.Before(rates[j].From.Time) })
+	inv.Cash.Rates = rates
+	inv.ReferenceRate = inv.Cash.rateAt(time.Now())
+	p.record(OpSetCashRate, name, fmt.Sprintf("%.2f%% au %s", rate, from), before)
+	p.valueChanged(name)
+	return nil
+}
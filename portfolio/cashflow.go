@@ -0,0 +1,122 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CashFlowType distingue les apports des retraits
+type CashFlowType string
+
+const (
+	Contribution CashFlowType = "contribution" // Versement complémentaire
+	Withdrawal   CashFlowType = "withdrawal"   // Rachat partiel
+)
+
+// CashFlow représente un mouvement de capital externe sur un investissement.
+// Par convention, une NAV datée du même jour qu'un flux inclut déjà ce flux.
+type CashFlow struct {
+	Date   Date         `json:"date"`   // Date du flux
+	Amount Money        `json:"amount"` // Montant du flux, toujours positif
+	Type   CashFlowType `json:"type"`   // Apport ou retrait
+}
+
+// SignedAmount retourne le montant du flux, positif pour un apport et négatif pour un retrait
+func (cf CashFlow) SignedAmount() Money {
+	if cf.Type == Withdrawal {
+		return -cf.Amount
+	}
+	return cf.Amount
+}
+
+// AddCashFlow enregistre un apport ou un retrait sur un investissement
+func (p *Portfolio) AddCashFlow(investmentName string, date string, amount float64, flowType CashFlowType) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+
+	if NewMoney(amount) <= 0 {
+		return &ValidationError{Field: "amount", Value: amount, Message: "le montant du flux doit être positif", Err: ErrInvalidAmount}
+	}
+	if flowType != Contribution && flowType != Withdrawal {
+		return InvalidField("type", flowType, "type de flux inconnu: %s", flowType)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+
+	before := inv.clone()
+	inv.addCashFlow(CashFlow{Date: Date{t}, Amount: NewMoney(amount), Type: flowType})
+
+	p.record(OpAddCashFlow, investmentName, fmt.Sprintf("%s %.2f au %s", flowType, amount, date), before)
+	p.valueChanged(investmentName)
+	return nil
+}
+
+// addCashFlow insère un flux validé à sa date ; les intérêts d'un compte rémunéré sont
+// recalculés en conséquence
+func (inv *Investment) addCashFlow(flow CashFlow) {
+	inv.CashFlows = append(inv.CashFlows, flow)
+
+	// Trier par date
+	sort.SliceStable(inv.CashFlows, func(i, j int) bool {
+		return inv.CashFlows[i].Date.Before(inv.CashFlows[j].Date.Time)
+	})
+
+	if inv.Cash != nil && len(inv.NAVHistory) > 0 {
+		inv.NAVHistory = inv.accrue(inv.NAVHistory[len(inv.NAVHistory)-1].Date.Time)
+	}
+	inv.invalidate()
+}
+
+// NetInvested retourne le capital net investi : montant initial plus apports moins retraits
+func (inv *Investment) NetInvested() Money {
+	total := inv.AmountInvested
+	for _, cf := range inv.CashFlows {
+		total += cf.SignedAmount()
+	}
+	return total
+}
+
+// flowAdjustedReturn calcule le rendement (non annualisé) entre deux NAV en neutralisant
+// les flux intervenus sur la période, selon la méthode de Dietz modifiée :
+// R = (VF - VI - F) / (VI + Σ wᵢFᵢ), wᵢ étant la fraction de période restant après le flux.
+// Sans flux, on retrouve R = VF/VI - 1. Les distributions versées en numéraire
+// comptent comme des retraits, de sorte que R mesure le rendement global.
+func (inv *Investment) flowAdjustedReturn(start, end NAV) float64 {
+	return dietzReturn(start, end, append(inv.paidDistributionFlows(), inv.CashFlows...))
+}
+
+// dietzReturn applique la méthode de Dietz modifiée aux flux datés dans ]start, end]
+func dietzReturn(start, end NAV, flows []CashFlow) float64 {
+	gain, capital := dietzComponents(start, end, flows)
+	return gain / capital
+}
+
+// dietzComponents retourne le numérateur (gain hors flux) et le dénominateur (capital
+// moyen engagé) de la méthode de Dietz modifiée
+func dietzComponents(start, end NAV, flows []CashFlow) (gain, capital float64) {
+	period := end.Date.Sub(start.Date.Time).Hours()
+
+	netFlows := 0.0
+	weightedFlows := 0.0
+	for _, cf := range flows {
+		// Les flux du jour de la NAV de départ sont déjà inclus dans VI
+		if !cf.Date.After(start.Date.Time) || cf.Date.After(end.Date.Time) {
+			continue
+		}
+		weight := 0.0
+		if period > 0 {
+			weight = end.Date.Sub(cf.Date.Time).Hours() / period
+		}
+		netFlows += cf.SignedAmount().Float64()
+		weightedFlows += weight * cf.SignedAmount().Float64()
+	}
+
+	return end.Value.Float64() - start.Value.Float64() - netFlows, start.Value.Float64() + weightedFlows
+}
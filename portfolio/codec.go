@@ -0,0 +1,155 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StorageFormat est le format d'enregistrement du fichier du portefeuille
+type StorageFormat string
+
+const (
+	FormatJSON   StorageFormat = "json"   // JSON indenté, lisible et modifiable à la main (par défaut)
+	FormatBinary StorageFormat = "binary" // Binaire compact, pour les portefeuilles volumineux
+)
+
+// binaryMagic ouvre les fichiers au format binaire ; il est suivi de l'octet de version
+const binaryMagic = "DAVIDBIN"
+
+// binaryVersion est la version courante du format binaire. Un fichier d'une version
+// plus récente est refusé plutôt que lu partiellement.
+const binaryVersion byte = 1
+
+// binaryPortfolio est le contenu d'un fichier binaire : le portefeuille en JSON compact
+// sans les historiques de NAV, qui représentent l'essentiel du volume et sont stockés
+// en colonnes
+type binaryPortfolio struct {
+	Meta []byte
+	NAVs map[string]navColumns
+}
+
+// navColumns est un historique de NAV en colonnes : écarts en jours entre dates
+// successives (depuis le 1er janvier 1970 pour la première) et valeurs en Money. Les
+// petits entiers sont codés sur peu d'octets par gob.
+type navColumns struct {
+	Days   []int32
+	Values []int64
+}
+
+// encode sérialise le portefeuille dans le format demandé
+func (p *Portfolio) encode(format StorageFormat) ([]byte, error) {
+	switch format {
+	case FormatBinary:
+		return p.encodeBinary()
+	case FormatJSON, "":
+		return json.MarshalIndent(p, "", "  ")
+	default:
+		return nil, InvalidField("format", format, "format d'enregistrement inconnu: %s (json, binary)", format)
+	}
+}
+
+// encodeBinary sérialise le portefeuille au format binaire
+func (p *Portfolio) encodeBinary() ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	alias := p.alias()
+	alias.Investments = make(map[string]*Investment, len(p.Investments))
+	bin := binaryPortfolio{NAVs: make(map[string]navColumns, len(p.Investments))}
+	for name, inv := range p.Investments {
+		bare := *inv
+		bare.NAVHistory = nil
+		alias.Investments[name] = &bare
+
+		cols := navColumns{Days: make([]int32, len(inv.NAVHistory)), Values: make([]int64, len(inv.NAVHistory))}
+		var prev int64
+		for i, nav := range inv.NAVHistory {
+			day := nav.Date.Unix() / 86400
+			cols.Days[i] = int32(day - prev)
+			cols.Values[i] = int64(nav.Value)
+			prev = day
+		}
+		bin.NAVs[name] = cols
+	}
+
+	meta, err := json.Marshal(alias)
+	if err != nil {
+		return nil, err
+	}
+	bin.Meta = meta
+
+	var buf bytes.Buffer
+	buf.WriteString(binaryMagic)
+	buf.WriteByte(binaryVersion)
+	if err := gob.NewEncoder(&buf).Encode(bin); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodePortfolio lit un portefeuille JSON ou binaire, détecté d'après son contenu
+func decodePortfolio(data []byte, p *Portfolio) (StorageFormat, error) {
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return FormatJSON, json.Unmarshal(data, p)
+	}
+
+	data = data[len(binaryMagic):]
+	if len(data) == 0 {
+		return "", fmt.Errorf("fichier binaire tronqué")
+	}
+	if version := data[0]; version != binaryVersion {
+		return "", fmt.Errorf("version %d du format binaire non prise en charge (version %d attendue)", version, binaryVersion)
+	}
+	var bin binaryPortfolio
+	if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&bin); err != nil {
+		return "", fmt.Errorf("format binaire: %w", err)
+	}
+	if err := json.Unmarshal(bin.Meta, p); err != nil {
+		return "", err
+	}
+
+	for name, cols := range bin.NAVs {
+		inv, exists := p.Investments[name]
+		if !exists || inv == nil {
+			continue
+		}
+		if len(cols.Days) != len(cols.Values) {
+			return "", fmt.Errorf("format binaire: NAV de '%s' incohérentes", name)
+		}
+		inv.NAVHistory = make([]NAV, len(cols.Days))
+		var day int64
+		for i := range cols.Days {
+			day += int64(cols.Days[i])
+			inv.NAVHistory[i] = NAV{Date: Date{time.Unix(day*86400, 0).UTC()}, Value: Money(cols.Values[i])}
+		}
+	}
+	return FormatBinary, nil
+}
+
+// SetStorageFormat choisit le format des enregistrements suivants du portefeuille ; un
+// portefeuille chargé conserve sinon le format de son fichier
+func (p *Portfolio) SetStorageFormat(format StorageFormat) error {
+	switch format {
+	case FormatJSON, FormatBinary:
+	default:
+		return InvalidField("format", format, "format d'enregistrement inconnu: %s (json, binary)", format)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.format = format
+	p.changed("set-storage-format", "")
+	return nil
+}
+
+// storageFormat retourne le format d'enregistrement du portefeuille
+func (p *Portfolio) storageFormat() StorageFormat {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.format
+}
@@ -0,0 +1,195 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// Commitment est l'engagement de souscription d'un fonds de capital-investissement : le
+// capital est appelé progressivement (appels de fonds enregistrés comme apports) et
+// restitué par des distributions. La part non encore appelée est supposée appelée à
+// parts égales d'ici la fin de la période d'investissement.
+type Commitment struct {
+	Amount    Money      `json:"amount"`    // Montant souscrit
+	Date      Date       `json:"date"`      // Date de souscription
+	CallsEnd  Date       `json:"calls_end"` // Fin de la période d'investissement (derniers appels attendus)
+	Frequency SeriesStep `json:"frequency"` // Périodicité attendue des appels
+}
+
+// CommitmentStatus récapitule la situation d'un engagement à une date
+type CommitmentStatus struct {
+	Investment  string
+	Currency    Currency
+	Committed   Money
+	Called      Money   // Capital appelé (montant initial et appels de fonds)
+	Unfunded    Money   // Engagement restant à appeler
+	Distributed Money   // Distributions versées et remboursements
+	Value       Money   // Valeur résiduelle (dernière NAV)
+	DPI         float64 // Distributions / capital appelé
+	RVPI        float64 // Valeur résiduelle / capital appelé
+	TVPI        float64 // DPI + RVPI
+}
+
+// called retourne le capital appelé jusqu'à une date incluse
+func (inv *Investment) called(date time.Time) Money {
+	total := Money(0)
+	if !inv.InvestmentDate.After(date) {
+		total = inv.AmountInvested
+	}
+	for _, cf := range inv.CashFlows {
+		if cf.Type == Contribution && !cf.Date.After(date) {
+			total += cf.Amount
+		}
+	}
+	return total
+}
+
+// totalCalled retourne le capital appelé par tous les appels enregistrés, quelle que soit
+// leur date
+func (inv *Investment) totalCalled() Money {
+	total := inv.AmountInvested
+	for _, cf := range inv.CashFlows {
+		if cf.Type == Contribution {
+			total += cf.Amount
+		}
+	}
+	return total
+}
+
+// expectedCalls retourne les appels de fonds attendus datés dans ]from, to] : la part non
+// encore appelée est répartie également entre les échéances restantes de la période
+// d'investissement
+func (inv *Investment) expectedCalls(from, to time.Time) []CashFlow {
+	c := inv.Commitment
+	if c == nil || inv.Closed {
+		return nil
+	}
+	// Les appels déjà enregistrés après from sont projetés comme apports à leur date
+	unfunded := c.Amount - inv.totalCalled()
+	if unfunded <= 0 {
+		return nil
+	}
+
+	var dates []time.Time
+	for i := 1; ; i++ {
+		// Une périodicité invalide est refusée par AddCommitment
+		date, err := c.Frequency.add(c.Date.Time, i)
+		if err != nil || date.After(c.CallsEnd.Time) {
+			break
+		}
+		if date.After(from) {
+			dates = append(dates, date)
+		}
+	}
+	if len(dates) == 0 {
+		// Période d'investissement échue : le solde reste appelable à tout moment
+		return nil
+	}
+
+	var flows []CashFlow
+	call := unfunded.Mul(1 / float64(len(dates))).RoundCents()
+	for i, date := range dates {
+		if date.After(to) {
+			break
+		}
+		amount := call
+		if i == len(dates)-1 {
+			amount = unfunded - call.Mul(float64(len(dates)-1))
+		}
+		flows = append(flows, CashFlow{Date: Date{date}, Amount: amount, Type: Contribution})
+	}
+	return flows
+}
+
+// AddCommitment ajoute un fonds de capital-investissement souscrit pour amount à une
+// date, dont le premier appel firstCall est versé à la souscription. Les appels suivants
+// sont attendus à la périodicité frequency jusqu'à callsEnd.
+func (p *Portfolio) AddCommitment(name string, amount, firstCall, referenceRate float64, date, callsEnd string, frequency SeriesStep) error {
+	start, end, err := ParsePeriod(date, callsEnd)
+	if err != nil {
+		return err
+	}
+	if start.IsZero() || end.IsZero() {
+		return fmt.Errorf("les dates de souscription et de fin des appels sont obligatoires: %w", ErrInvalidDate)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("la fin des appels doit être après la souscription: %w", ErrInvalidDate)
+	}
+	if _, err := frequency.add(start, 1); err != nil {
+		return err
+	}
+	if NewMoney(firstCall) > NewMoney(amount) {
+		return fmt.Errorf("le premier appel dépasse l'engagement: %w", ErrInvalidAmount)
+	}
+	if err := p.AddInvestment(name, firstCall, referenceRate, date); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Investments[name].Commitment = &Commitment{Amount: NewMoney(amount), Date: Date{start}, CallsEnd: Date{end}, Frequency: frequency}
+	p.changed("add-commitment", name)
+	return nil
+}
+
+// CapitalCall enregistre un appel de fonds, refusé s'il dépasse l'engagement restant
+func (p *Portfolio) CapitalCall(name, date string, amount float64) error {
+	inv, err := p.Investment(name)
+	if err != nil {
+		return err
+	}
+	if inv.Commitment == nil {
+		return fmt.Errorf("'%s' n'a pas d'engagement de souscription", name)
+	}
+	unfunded := inv.Commitment.Amount - inv.totalCalled()
+	if NewMoney(amount) > unfunded {
+		return fmt.Errorf("l'appel de %.2f dépasse l'engagement restant de %s: %w", amount, unfunded, ErrInvalidAmount)
+	}
+	return p.AddCashFlow(name, date, amount, Contribution)
+}
+
+// CommitmentStatus calcule les multiples d'un engagement à une date
+func (inv *Investment) CommitmentStatus(date time.Time) (*CommitmentStatus, error) {
+	if inv.Commitment == nil {
+		return nil, fmt.Errorf("'%s' n'a pas d'engagement de souscription", inv.Name)
+	}
+
+	s := &CommitmentStatus{Investment: inv.Name, Currency: inv.EffectiveCurrency(), Committed: inv.Commitment.Amount}
+	s.Called = inv.called(date)
+	s.Unfunded = max(s.Committed-s.Called, 0)
+	for _, d := range inv.Distributions {
+		if !d.Reinvested && !d.Date.After(date) {
+			s.Distributed += d.Amount
+		}
+	}
+	for _, cf := range inv.CashFlows {
+		if cf.Type == Withdrawal && !cf.Date.After(date) {
+			s.Distributed += cf.Amount
+		}
+	}
+	if value, ok := inv.historicalValue(date); ok {
+		s.Value = NewMoney(value).RoundCents()
+	}
+	if s.Called > 0 {
+		s.DPI = s.Distributed.Float64() / s.Called.Float64()
+		s.RVPI = s.Value.Float64() / s.Called.Float64()
+		s.TVPI = s.DPI + s.RVPI
+	}
+	return s, nil
+}
+
+// ExpectedCapitalCalls retourne, pour chaque engagement, les appels attendus dans ]from, to],
+// afin d'anticiper les besoins de trésorerie
+func (p *Portfolio) ExpectedCapitalCalls(from, to time.Time) map[string][]CashFlow {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	calls := make(map[string][]CashFlow)
+	for _, name := range p.sortedInvestmentNames() {
+		if flows := p.Investments[name].expectedCalls(from, to); len(flows) > 0 {
+			calls[name] = flows
+		}
+	}
+	return calls
+}
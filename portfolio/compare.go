@@ -0,0 +1,104 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// PortfolioProfile est la situation d'un portefeuille dans une comparaison, dans sa
+// devise de consolidation
+type PortfolioProfile struct {
+	Currency   Currency
+	Invested   float64
+	Value      float64                // Valeur aux dernières NAV
+	Allocation map[string]float64     // Répartition actuelle par classe d'actifs (%)
+	Risk       *analytics.RiskMetrics // Mesures de risque du portefeuille, nil sans historique suffisant
+	Projection float64                // Valeur projetée à la date de comparaison
+}
+
+// PortfolioComparison met deux portefeuilles côte à côte
+type PortfolioComparison struct {
+	Date   time.Time
+	A, B   PortfolioProfile
+	Labels []string // Classes d'actifs de l'un ou l'autre portefeuille, triées
+}
+
+// ComparePortfolios met côte à côte la valeur, la répartition par classe d'actifs, les
+// mesures de risque et la valeur projetée à date de deux portefeuilles : celui d'un
+// conjoint, ou une répartition envisagée (voir WhatIf). Chaque portefeuille reste dans
+// sa devise de consolidation.
+func ComparePortfolios(a, b *Portfolio, date string) (*PortfolioComparison, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	c := &PortfolioComparison{Date: t}
+	if c.A, err = a.profile(t); err != nil {
+		return nil, fmt.Errorf("premier portefeuille: %w", err)
+	}
+	if c.B, err = b.profile(t); err != nil {
+		return nil, fmt.Errorf("second portefeuille: %w", err)
+	}
+
+	labels := make(map[string]bool)
+	for label := range c.A.Allocation {
+		labels[label] = true
+	}
+	for label := range c.B.Allocation {
+		labels[label] = true
+	}
+	for label := range labels {
+		c.Labels = append(c.Labels, label)
+	}
+	sort.Strings(c.Labels)
+	return c, nil
+}
+
+// profile calcule la situation du portefeuille présentée par ComparePortfolios
+func (p *Portfolio) profile(t time.Time) (PortfolioProfile, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pr := PortfolioProfile{Currency: p.baseCurrency(), Allocation: make(map[string]float64)}
+	results, err := p.query(QueryOptions{})
+	if err != nil {
+		return pr, err
+	}
+	for _, res := range results {
+		pr.Invested += res.Invested
+		pr.Value += res.Value
+	}
+	for _, res := range results {
+		if pr.Value == 0 {
+			break
+		}
+		labels, err := p.Investments[res.Name].tagLabels(TagAssetClass, Today())
+		if err != nil {
+			return pr, fmt.Errorf("erreur pour %s: %w", res.Name, err)
+		}
+		for label, share := range labels {
+			pr.Allocation[label] += share * res.Value / pr.Value * 100
+		}
+	}
+
+	returns, err := p.alignedReturns(time.Time{}, time.Time{})
+	if err != nil {
+		return pr, err
+	}
+	risk, err := analytics.Risk(p.rateConventions(), returns, p.RiskFreeRate)
+	switch {
+	case err == nil:
+		pr.Risk = &risk
+	case !errors.Is(err, ErrInsufficientHistory):
+		return pr, err
+	}
+
+	if _, pr.Projection, err = p.portfolioValue(t); err != nil {
+		return pr, err
+	}
+	return pr, nil
+}
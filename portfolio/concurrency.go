@@ -0,0 +1,225 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// portfolioAlias permet de sérialiser Portfolio sans rappeler MarshalJSON
+type portfolioAlias struct {
+	Investments        map[string]*Investment        `json:"investments"`
+	BaseCurrency       Currency                      `json:"base_currency,omitempty"`
+	DuplicateNAVPolicy DuplicateNAVPolicy            `json:"duplicate_nav_policy,omitempty"`
+	MissingNAVPolicy   MissingNAVPolicy              `json:"missing_nav_policy,omitempty"`
+	TargetAllocation   *TargetAllocation             `json:"target_allocation,omitempty"`
+	Scenarios          map[string]*Scenario          `json:"scenarios,omitempty"`
+	Benchmarks         map[string]*Benchmark         `json:"benchmarks,omitempty"`
+	RiskFreeRate       float64                       `json:"risk_free_rate,omitempty"`
+	Inflation          *Inflation                    `json:"inflation,omitempty"`
+	Locale             Locale                        `json:"locale,omitempty"`
+	AlertRules         []AlertRule                   `json:"alert_rules,omitempty"`
+	Journal            *Journal                      `json:"journal,omitempty"`
+	Snapshots          map[string]*Snapshot          `json:"snapshots,omitempty"`
+	Tax                *TaxSettings                  `json:"tax,omitempty"`
+	Liabilities        map[string]*Liability         `json:"liabilities,omitempty"`
+	RecurringPlans     map[string]*RecurringPlan     `json:"recurring_plans,omitempty"`
+	Conventions        *analytics.RateConventions    `json:"conventions,omitempty"`
+	Calendar           *Calendar                     `json:"calendar,omitempty"`
+	StatementRules     []StatementRule               `json:"statement_rules,omitempty"`
+	Watchlist          map[string]*WatchedInstrument `json:"watchlist,omitempty"`
+	Owner              string                        `json:"owner,omitempty"`
+	Forecasts          *ForecastStore                `json:"forecasts,omitempty"`
+}
+
+// MarshalJSON sérialise le portefeuille sous verrou de lecture
+func (p *Portfolio) MarshalJSON() ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return json.Marshal(p.alias())
+}
+
+// alias retourne les champs sérialisés du portefeuille ; l'appelant doit détenir p.mu
+func (p *Portfolio) alias() portfolioAlias {
+	return portfolioAlias{
+		Investments:        p.Investments,
+		BaseCurrency:       p.BaseCurrency,
+		DuplicateNAVPolicy: p.DuplicateNAVPolicy,
+		MissingNAVPolicy:   p.MissingNAVPolicy,
+		TargetAllocation:   p.TargetAllocation,
+		Scenarios:          p.Scenarios,
+		Benchmarks:         p.Benchmarks,
+		RiskFreeRate:       p.RiskFreeRate,
+		Inflation:          p.Inflation,
+		Locale:             p.Locale,
+		AlertRules:         p.AlertRules,
+		Journal:            p.Journal,
+		Snapshots:          p.Snapshots,
+		Tax:                p.Tax,
+		Liabilities:        p.Liabilities,
+		RecurringPlans:     p.RecurringPlans,
+		Conventions:        p.Conventions,
+		Calendar:           p.Calendar,
+		StatementRules:     p.StatementRules,
+		Watchlist:          p.Watchlist,
+		Owner:              p.Owner,
+		Forecasts:          p.Forecasts,
+	}
+}
+
+// UnmarshalJSON remplace le contenu du portefeuille sous verrou d'écriture
+func (p *Portfolio) UnmarshalJSON(data []byte) error {
+	var raw portfolioAlias
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Investments = raw.Investments
+	if raw.BaseCurrency != "" {
+		p.BaseCurrency = raw.BaseCurrency
+	}
+	p.DuplicateNAVPolicy = raw.DuplicateNAVPolicy
+	p.MissingNAVPolicy = raw.MissingNAVPolicy
+	p.TargetAllocation = raw.TargetAllocation
+	p.Scenarios = raw.Scenarios
+	p.Benchmarks = raw.Benchmarks
+	p.RiskFreeRate = raw.RiskFreeRate
+	p.Inflation = raw.Inflation
+	p.Locale = raw.Locale
+	p.AlertRules = raw.AlertRules
+	p.Journal = raw.Journal
+	p.Snapshots = raw.Snapshots
+	p.Tax = raw.Tax
+	p.Liabilities = raw.Liabilities
+	p.RecurringPlans = raw.RecurringPlans
+	p.linkRecurringPlans()
+	p.Conventions = raw.Conventions
+	p.Calendar = raw.Calendar
+	p.attachAll()
+	p.StatementRules = raw.StatementRules
+	p.Watchlist = raw.Watchlist
+	p.Owner = raw.Owner
+	p.Forecasts = raw.Forecasts
+	p.revision.Add(1)
+	return nil
+}
+
+// SetRates définit la source de taux de change utilisée pour la consolidation
+func (p *Portfolio) SetRates(rates Rates) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Rates = rates
+	p.changed("set-rates", "")
+}
+
+// Investment retourne une copie indépendante d'un investissement, utilisable
+// sans synchronisation même si le portefeuille est modifié en parallèle
+func (p *Portfolio) Investment(name string) (*Investment, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return nil, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	return inv.clone(), nil
+}
+
+// InvestmentNames retourne les noms des investissements, triés
+func (p *Portfolio) InvestmentNames() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.Investments))
+	for name := range p.Investments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clone retourne une copie profonde de l'investissement
+func (inv *Investment) clone() *Investment {
+	c := *inv
+	c.metrics = newMetricsCache()
+	c.NAVHistory = append(make([]NAV, 0, len(inv.NAVHistory)), inv.NAVHistory...)
+	if inv.CashFlows != nil {
+		c.CashFlows = append([]CashFlow(nil), inv.CashFlows...)
+	}
+	if inv.Distributions != nil {
+		c.Distributions = append([]Distribution(nil), inv.Distributions...)
+	}
+	if inv.Redemptions != nil {
+		c.Redemptions = append([]Redemption(nil), inv.Redemptions...)
+	}
+	if inv.Fees != nil {
+		fees := *inv.Fees
+		c.Fees = &fees
+	}
+	if inv.Plan != nil {
+		plan := *inv.Plan
+		c.Plan = &plan
+	}
+	if inv.RatePolicy != nil {
+		policy := *inv.RatePolicy
+		c.RatePolicy = &policy
+	}
+	if inv.Projection != nil {
+		projection := *inv.Projection
+		projection.Params = maps.Clone(inv.Projection.Params)
+		c.Projection = &projection
+	}
+	if inv.Tags != nil {
+		c.Tags = make(map[string]string, len(inv.Tags))
+		for k, v := range inv.Tags {
+			c.Tags[k] = v
+		}
+	}
+	if inv.Transactions != nil {
+		c.Transactions = append([]Transaction(nil), inv.Transactions...)
+		for i := range c.Transactions {
+			c.Transactions[i].Notes = append([]Note(nil), c.Transactions[i].Notes...)
+		}
+	}
+	if inv.Notes != nil {
+		c.Notes = append([]Note(nil), inv.Notes...)
+	}
+	if inv.Holdings != nil {
+		// Une composition qui ne se sérialise pas ne peut pas non plus être enregistrée :
+		// la copie la partage plutôt que de la perdre
+		c.Holdings = inv.Holdings
+		if holdings, err := inv.Holdings.Clone(); err == nil {
+			c.Holdings = holdings
+		}
+	}
+	if inv.Cash != nil {
+		cash := *inv.Cash
+		cash.Rates = append([]CashRate(nil), inv.Cash.Rates...)
+		c.Cash = &cash
+	}
+	if inv.Bond != nil {
+		bond := *inv.Bond
+		c.Bond = &bond
+	}
+	if inv.Commitment != nil {
+		commitment := *inv.Commitment
+		c.Commitment = &commitment
+	}
+	if inv.Vesting != nil {
+		vesting := *inv.Vesting
+		c.Vesting = &vesting
+	}
+	if inv.Liquidity != nil {
+		liquidity := *inv.Liquidity
+		c.Liquidity = &liquidity
+	}
+	return &c
+}
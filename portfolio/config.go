@@ -0,0 +1,592 @@
+package portfolio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// configFileNames sont les fichiers de configuration cherchés, dans cet ordre, dans le
+// répertoire de configuration de l'utilisateur (~/.config/david sous Linux)
+var configFileNames = []string{"config.toml", "config.yaml", "config.yml"}
+
+// Config est la configuration de l'utilisateur : des valeurs par défaut qui évitent de
+// répéter les mêmes options à chaque commande. Les options de la ligne de commande
+// l'emportent sur les variables d'environnement, qui l'emportent sur la configuration.
+//
+// Le fichier est au format TOML, restreint aux tables, aux chaînes et aux nombres :
+//
+//	portfolio = "~/finances/portfolio.json"
+//	base_currency = "EUR"
+//	locale = "en"
+//	log = "info"
+//	year_start = "04-06"
+//	fx_rates = "~/finances/fx.csv"
+//
+//	[conventions]
+//	day_count = "act/365"
+//	compounding = "monthly"
+//
+//	[rate_policy]
+//	mode = "blend"
+//	realized_weight = 0.5
+//
+//	[alignment]
+//	step = "monthly"
+//	mode = "last-known"
+//
+//	[guardrails]
+//	max_rate = 25
+//	max_horizon_years = 60
+//	min_rate_window_days = 365
+//
+//	[quotes]
+//	provider_url = "https://query1.finance.yahoo.com"
+//
+//	[smtp]
+//	server = "smtp.example.com:587"
+//	from = "david@example.com"
+//	to = "moi@example.com"
+//	password = "..."
+//
+//	[serve]
+//	addr = ":9090"
+//	refresh_every = "15m"
+//	cache_entries = 256
+//
+//	[webhook]
+//	url = "https://hooks.example.com/david"
+//	secret = "..."
+//	events = "nav-added,alert,report"
+//	retries = 3
+//	backoff = "2s"
+//
+//	[metrics]
+//	gain = "value - invested"
+//	fee_drag = "value * ter"
+//
+//	[projectors]
+//	plateau = "value * (1 + min(rate, 0.04)) ^ years"
+//
+//	[plugins]
+//	bourse = "~/.local/bin/david-bourse"
+//
+// Un fichier d'extension .yaml ou .yml est lu au format YAML, avec les mêmes clés : les
+// tables deviennent des correspondances imbriquées (voir ParseYAMLConfig).
+//
+//	portfolio: ~/finances/portfolio.json
+//	conventions:
+//	  day_count: act/365
+//	serve:
+//	  refresh_every: 15m
+type Config struct {
+	Path         string                    // Fichier lu, vide sans configuration
+	Portfolio    string                    // Fichier du portefeuille (DAVID_PORTFOLIO)
+	BaseCurrency Currency                  // Devise de consolidation des nouveaux portefeuilles
+	Locale       Locale                    // Langue des résumés et rapports (DAVID_LANG)
+	Log          string                    // Niveau du journal de diagnostic (DAVID_LOG)
+	YearStart    YearStart                 // Début de l'année des rapports annuels et fiscaux (civile si vide)
+	FXRates      string                    // Fichier CSV des taux de change historiques (DAVID_FX_RATES, voir LoadRateTable)
+	Conventions  analytics.RateConventions // Conventions de taux des nouveaux portefeuilles
+	RatePolicy   *RatePolicy               // Règle de taux des investissements qui n'en ont pas (RateMin si nil)
+	Alignment    Alignment                 // Grille des analyses multi-actifs (voir Alignment)
+	Guardrails   Guardrails                // Bornes des entrées des projections (voir Guardrails)
+	Quotes       QuotesConfig
+	SMTP         SMTPConfig
+	Serve        ServeConfig
+	Webhook      WebhookConfig
+	Metrics      []Metric    // Table [metrics] : indicateurs personnalisés, dans l'ordre du fichier
+	Projectors   []Projector // Table [projectors] : modèles de projection définis par une expression
+	Plugins      []Plugin    // Table [plugins] : extensions, dans l'ordre du fichier (voir Plugin)
+}
+
+// QuotesConfig est la table [quotes] : les fournisseurs de cours
+type QuotesConfig struct {
+	ProviderURL string // Adresse de l'API Yahoo Finance
+	CryptoURL   string // Adresse de l'API CoinGecko
+}
+
+// SMTPConfig est la table [smtp] : l'envoi des alertes par courriel
+type SMTPConfig struct {
+	Server   string // hôte:port
+	From     string
+	To       string // Destinataires séparés par des virgules
+	Password string // DAVID_SMTP_PASSWORD
+}
+
+// ServeConfig est la table [serve] : les options de la commande serve
+type ServeConfig struct {
+	Addr         string
+	GRPCAddr     string
+	RefreshEvery time.Duration
+	Timeout      time.Duration
+	Tokens       string // Fichier des jetons d'API, par défaut celui du portefeuille suivi de .tokens
+	CacheEntries int    // Réponses de calcul conservées en cache (voir resultCache), désactivé si négatif
+}
+
+// WebhookConfig est la table [webhook] : la publication des événements (voir Webhook)
+type WebhookConfig struct {
+	URL     string
+	Secret  string // Clé de signature HMAC (DAVID_WEBHOOK_SECRET)
+	Events  []EventKind
+	Retries int
+	Backoff time.Duration
+}
+
+// configKeys associe chaque clé du fichier (préfixée de sa table) à son champ
+var configKeys = map[string]func(c *Config, v configValue) error{
+	"portfolio":     func(c *Config, v configValue) error { return v.str(&c.Portfolio) },
+	"base_currency": func(c *Config, v configValue) error { return v.str((*string)(&c.BaseCurrency)) },
+	"locale":        func(c *Config, v configValue) error { return v.str((*string)(&c.Locale)) },
+	"log":           func(c *Config, v configValue) error { return v.str(&c.Log) },
+	"fx_rates":      func(c *Config, v configValue) error { return v.str(&c.FXRates) },
+	"year_start": func(c *Config, v configValue) error {
+		var s string
+		if err := v.str(&s); err != nil {
+			return err
+		}
+		return c.YearStart.UnmarshalText([]byte(s))
+	},
+	"conventions.day_count":              func(c *Config, v configValue) error { return v.str((*string)(&c.Conventions.DayCount)) },
+	"conventions.compounding":            func(c *Config, v configValue) error { return v.str((*string)(&c.Conventions.Compounding)) },
+	"conventions.min_annualization_days": func(c *Config, v configValue) error { return v.int(&c.Conventions.MinAnnualizationDays) },
+	"rate_policy.mode": func(c *Config, v configValue) error {
+		return v.str((*string)(&c.ratePolicy().Mode))
+	},
+	"rate_policy.realized_weight": func(c *Config, v configValue) error {
+		return v.float(&c.ratePolicy().RealizedWeight)
+	},
+	"alignment.step":                  func(c *Config, v configValue) error { return v.str((*string)(&c.Alignment.Step)) },
+	"alignment.mode":                  func(c *Config, v configValue) error { return v.str((*string)(&c.Alignment.Mode)) },
+	"guardrails.max_rate":             func(c *Config, v configValue) error { return v.float(&c.Guardrails.MaxRate) },
+	"guardrails.max_horizon_years":    func(c *Config, v configValue) error { return v.int(&c.Guardrails.MaxHorizon) },
+	"guardrails.min_rate_window_days": func(c *Config, v configValue) error { return v.int(&c.Guardrails.MinRateWindow) },
+	"quotes.provider_url":             func(c *Config, v configValue) error { return v.str(&c.Quotes.ProviderURL) },
+	"quotes.crypto_url":               func(c *Config, v configValue) error { return v.str(&c.Quotes.CryptoURL) },
+	"smtp.server":                     func(c *Config, v configValue) error { return v.str(&c.SMTP.Server) },
+	"smtp.from":                       func(c *Config, v configValue) error { return v.str(&c.SMTP.From) },
+	"smtp.to":                         func(c *Config, v configValue) error { return v.str(&c.SMTP.To) },
+	"smtp.password":                   func(c *Config, v configValue) error { return v.str(&c.SMTP.Password) },
+	"serve.addr":                      func(c *Config, v configValue) error { return v.str(&c.Serve.Addr) },
+	"serve.grpc_addr":                 func(c *Config, v configValue) error { return v.str(&c.Serve.GRPCAddr) },
+	"serve.refresh_every":             func(c *Config, v configValue) error { return v.duration(&c.Serve.RefreshEvery) },
+	"serve.timeout":                   func(c *Config, v configValue) error { return v.duration(&c.Serve.Timeout) },
+	"serve.tokens":                    func(c *Config, v configValue) error { return v.str(&c.Serve.Tokens) },
+	"serve.cache_entries":             func(c *Config, v configValue) error { return v.int(&c.Serve.CacheEntries) },
+	"webhook.url":                     func(c *Config, v configValue) error { return v.str(&c.Webhook.URL) },
+	"webhook.secret":                  func(c *Config, v configValue) error { return v.str(&c.Webhook.Secret) },
+	"webhook.events": func(c *Config, v configValue) error {
+		var s string
+		if err := v.str(&s); err != nil {
+			return err
+		}
+		var err error
+		c.Webhook.Events, err = ParseWebhookEvents(s)
+		return err
+	},
+	"webhook.retries": func(c *Config, v configValue) error { return v.int(&c.Webhook.Retries) },
+	"webhook.backoff": func(c *Config, v configValue) error { return v.duration(&c.Webhook.Backoff) },
+}
+
+// configMetric retourne le champ d'un indicateur de la table [metrics]
+func configMetric(name string) func(c *Config, v configValue) error {
+	return func(c *Config, v configValue) error {
+		var source string
+		if err := v.str(&source); err != nil {
+			return err
+		}
+		m, err := ParseMetric(name, source)
+		if err != nil {
+			return err
+		}
+		c.Metrics = append(c.Metrics, m)
+		return nil
+	}
+}
+
+// configProjector retourne le champ d'un modèle de la table [projectors]
+func configProjector(name string) func(c *Config, v configValue) error {
+	return func(c *Config, v configValue) error {
+		var source string
+		if err := v.str(&source); err != nil {
+			return err
+		}
+		proj, err := ParseProjector(name, source)
+		if err != nil {
+			return err
+		}
+		c.Projectors = append(c.Projectors, proj)
+		return nil
+	}
+}
+
+// configPlugin retourne le champ d'une extension de la table [plugins]
+func configPlugin(name string) func(c *Config, v configValue) error {
+	return func(c *Config, v configValue) error {
+		pl := Plugin{Name: name}
+		if err := v.str(&pl.Path); err != nil {
+			return err
+		}
+		c.Plugins = append(c.Plugins, pl)
+		return nil
+	}
+}
+
+// ratePolicy retourne la règle de taux en cours de lecture, créée au premier accès
+func (c *Config) ratePolicy() *RatePolicy {
+	if c.RatePolicy == nil {
+		c.RatePolicy = &RatePolicy{}
+	}
+	return c.RatePolicy
+}
+
+// configValue est une valeur lue dans le fichier : chaîne (déjà décodée) ou littéral
+type configValue struct {
+	raw    string
+	quoted bool
+}
+
+func (v configValue) str(dst *string) error {
+	if !v.quoted {
+		return fmt.Errorf("chaîne attendue: %s", v.raw)
+	}
+	*dst = v.raw
+	return nil
+}
+
+func (v configValue) int(dst *int) error {
+	n, err := strconv.Atoi(strings.ReplaceAll(v.raw, "_", ""))
+	if v.quoted || err != nil {
+		return fmt.Errorf("entier attendu: %s", v.raw)
+	}
+	*dst = n
+	return nil
+}
+
+func (v configValue) float(dst *float64) error {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(v.raw, "_", ""), 64)
+	if v.quoted || err != nil {
+		return fmt.Errorf("nombre attendu: %s", v.raw)
+	}
+	*dst = f
+	return nil
+}
+
+func (v configValue) duration(dst *time.Duration) error {
+	d, err := time.ParseDuration(v.raw)
+	if !v.quoted || err != nil {
+		return fmt.Errorf("durée attendue (ex. \"15m\"): %s", v.raw)
+	}
+	*dst = d
+	return nil
+}
+
+// ParseConfig lit une configuration au format TOML. Les clés inconnues sont refusées,
+// pour qu'une faute de frappe ne passe pas inaperçue.
+func ParseConfig(data []byte) (*Config, error) {
+	c := &Config{}
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(stripConfigComment(scanner.Text()))
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") || strings.HasPrefix(text, "[[") {
+				return nil, fmt.Errorf("ligne %d: table invalide: %s", line, text)
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+
+		key, raw, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("ligne %d: « clé = valeur » attendu: %s", line, text)
+		}
+		key = strings.TrimSpace(key)
+		if section != "" {
+			key = section + "." + key
+		}
+		value, err := parseConfigValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("ligne %d: %s: %w", line, key, err)
+		}
+		if err := c.set(key, value); err != nil {
+			return nil, fmt.Errorf("ligne %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, c.validate()
+}
+
+// ParseYAMLConfig lit une configuration au format YAML : une correspondance de clés de
+// premier niveau et de tables, chaque table étant une correspondance de clés à des
+// valeurs scalaires. Les clés sont celles de ParseConfig et les clés inconnues sont
+// refusées de même.
+func ParseYAMLConfig(data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if len(doc.Content) == 0 {
+		return c, c.validate()
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("ligne %d: correspondance de clés attendue", root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, node := root.Content[i].Value, root.Content[i+1]
+		if node.Kind != yaml.MappingNode {
+			if err := c.setYAML(key, node); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if err := c.setYAML(key+"."+node.Content[j].Value, node.Content[j+1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, c.validate()
+}
+
+// setYAML renseigne une clé avec une valeur YAML, qui doit être scalaire : une chaîne
+// tient lieu de chaîne TOML, un nombre de littéral
+func (c *Config) setYAML(key string, node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("ligne %d: %s: valeur scalaire attendue", node.Line, key)
+	}
+	value := configValue{raw: node.Value, quoted: node.Tag == "!!str"}
+	if err := c.set(key, value); err != nil {
+		return fmt.Errorf("ligne %d: %w", node.Line, err)
+	}
+	return nil
+}
+
+// set renseigne le champ d'une clé préfixée de sa table
+func (c *Config) set(key string, value configValue) error {
+	set, known := configKeys[key]
+	if name, isMetric := strings.CutPrefix(key, "metrics."); isMetric {
+		set, known = configMetric(name), true
+	}
+	if name, isProjector := strings.CutPrefix(key, "projectors."); isProjector {
+		set, known = configProjector(name), true
+	}
+	if name, isPlugin := strings.CutPrefix(key, "plugins."); isPlugin {
+		set, known = configPlugin(name), true
+	}
+	if !known {
+		return fmt.Errorf("clé inconnue: %s", key)
+	}
+	if err := set(c, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// stripConfigComment retire le commentaire d'une ligne, en dehors des chaînes
+func stripConfigComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseConfigValue décode une chaîne TOML ("..." avec échappements, '...' littérale) ou
+// retourne tel quel un autre littéral
+func parseConfigValue(raw string) (configValue, error) {
+	switch {
+	case raw == "":
+		return configValue{}, fmt.Errorf("valeur manquante")
+	case strings.HasPrefix(raw, `"`):
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return configValue{}, fmt.Errorf("chaîne invalide: %s", raw)
+		}
+		return configValue{raw: s, quoted: true}, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") || strings.Contains(raw[1:len(raw)-1], "'") {
+			return configValue{}, fmt.Errorf("chaîne invalide: %s", raw)
+		}
+		return configValue{raw: raw[1 : len(raw)-1], quoted: true}, nil
+	case strings.HasPrefix(raw, "["), strings.HasPrefix(raw, "{"):
+		return configValue{}, fmt.Errorf("tableaux et tables en ligne non pris en charge")
+	}
+	return configValue{raw: raw}, nil
+}
+
+// validate vérifie les valeurs qui ont un ensemble fermé de possibilités
+func (c *Config) validate() error {
+	if cur := string(c.BaseCurrency); cur != "" && (len(cur) != 3 || strings.ToUpper(cur) != cur) {
+		return InvalidField("base_currency", cur, "code de devise invalide: %s", cur)
+	}
+	if c.Locale != "" {
+		if err := c.Locale.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateConventions(c.Conventions); err != nil {
+		return err
+	}
+	if c.RatePolicy != nil {
+		if err := c.RatePolicy.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Alignment != (Alignment{}) {
+		if err := c.Alignment.withDefaults().validate(); err != nil {
+			return err
+		}
+	}
+	if c.Guardrails != (Guardrails{}) {
+		if err := c.Guardrails.withDefaults().validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateProjectors(c.Projectors); err != nil {
+		return err
+	}
+	if err := validatePlugins(c.Plugins); err != nil {
+		return err
+	}
+	return ValidateMetrics(c.Metrics)
+}
+
+// ConfigPath retourne le fichier de configuration : DAVID_CONFIG s'il est défini, sinon
+// le premier de configFileNames présent dans le répertoire de configuration de
+// l'utilisateur (config.toml si aucun). explicit indique que le fichier a été désigné
+// et doit donc exister.
+func ConfigPath() (path string, explicit bool) {
+	if path := os.Getenv("DAVID_CONFIG"); path != "" {
+		return path, true
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, "david", name)
+		if _, err := os.Stat(path); err == nil {
+			return path, false
+		}
+	}
+	return filepath.Join(dir, "david", configFileNames[0]), false
+}
+
+// LoadConfig lit un fichier de configuration, au format YAML si son extension est .yaml
+// ou .yml, TOML sinon
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parse := ParseConfig
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		parse = ParseYAMLConfig
+	}
+	c, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	c.Path = path
+	paths := []*string{&c.Portfolio, &c.FXRates}
+	for i := range c.Plugins {
+		paths = append(paths, &c.Plugins[i].Path)
+	}
+	for _, path := range paths {
+		if strings.HasPrefix(*path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				*path = filepath.Join(home, (*path)[2:])
+			}
+		}
+	}
+	return c, nil
+}
+
+// activeConfig est la configuration chargée au lancement de la commande
+var activeConfig atomic.Pointer[Config]
+
+// LoadActiveConfig charge la configuration de l'utilisateur pour le processus. Un
+// fichier absent équivaut à une configuration vide, sauf s'il a été désigné par
+// DAVID_CONFIG.
+func LoadActiveConfig() error {
+	path, explicit := ConfigPath()
+	if path == "" {
+		return nil
+	}
+	c, err := LoadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("configuration: %w", err)
+	}
+	activeConfig.Store(c)
+	return nil
+}
+
+// ActiveConfig retourne la configuration active, vide si aucune n'a été chargée
+func ActiveConfig() *Config {
+	if c := activeConfig.Load(); c != nil {
+		return c
+	}
+	return &Config{}
+}
+
+// EnvOrConfig retourne la variable d'environnement si elle est définie, sinon la
+// valeur de la configuration
+func EnvOrConfig(name, configured string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return configured
+}
+
+// ApplyDefaults renseigne un nouveau portefeuille avec la devise de consolidation et
+// les conventions de taux de la configuration
+func (c *Config) ApplyDefaults(p *Portfolio) {
+	if c.BaseCurrency != "" {
+		p.BaseCurrency = c.BaseCurrency
+	}
+	if c.Conventions != (analytics.RateConventions{}) {
+		conventions := c.Conventions
+		p.Conventions = &conventions
+		p.attachAll()
+	}
+}
@@ -0,0 +1,80 @@
+package portfolio
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// validateConventions vérifie que les conventions sont connues
+func validateConventions(c analytics.RateConventions) error {
+	if c.MinAnnualizationDays < 0 {
+		return InvalidField("min_annualization_days", c.MinAnnualizationDays, "la durée minimale d'annualisation ne peut pas être négative")
+	}
+	switch c.DayCount {
+	case "", analytics.DayCountActual36525, analytics.DayCountActual365, analytics.DayCountActual360, analytics.DayCount30360:
+	default:
+		return InvalidField("day_count", c.DayCount, "convention de décompte des jours inconnue: %s (act/365.25, act/365, act/360, 30/360)", c.DayCount)
+	}
+	switch c.Compounding {
+	case "", analytics.CompoundYearly, analytics.CompoundMonthly, analytics.CompoundDaily, analytics.CompoundContinuous:
+	default:
+		return InvalidField("compounding", c.Compounding, "capitalisation inconnue: %s (yearly, monthly, daily, continuous)", c.Compounding)
+	}
+	return nil
+}
+
+// rateConventions retourne les conventions du portefeuille, celles par défaut s'il n'en
+// définit pas ; l'appelant doit détenir p.mu
+func (p *Portfolio) rateConventions() analytics.RateConventions {
+	if p.Conventions == nil {
+		return analytics.RateConventions{}
+	}
+	return *p.Conventions
+}
+
+// attach rattache un investissement aux conventions et au calendrier du portefeuille, que
+// ses calculs de durées, de taux et d'échéances lisent ; l'appelant doit détenir p.mu. Tout
+// investissement placé dans p.Investments doit être rattaché, et tous le sont de nouveau
+// quand ils changent.
+func (p *Portfolio) attach(inv *Investment) {
+	inv.conventions = p.rateConventions()
+	inv.calendar = p.Calendar
+	if inv.Bond != nil {
+		inv.Bond.conventions = inv.conventions
+		inv.Bond.calendar = inv.calendar
+	}
+}
+
+// attachAll rattache tous les investissements au portefeuille ; l'appelant doit détenir p.mu
+func (p *Portfolio) attachAll() {
+	for _, inv := range p.Investments {
+		p.attach(inv)
+	}
+}
+
+// SetConventions enregistre les conventions de calcul des taux du portefeuille ; elles ne
+// s'appliquent qu'à ses investissements
+func (p *Portfolio) SetConventions(c analytics.RateConventions) error {
+	if err := validateConventions(c); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("conventions")
+	if err != nil {
+		return err
+	}
+
+	p.Conventions = nil
+	if c != (analytics.RateConventions{}) {
+		p.Conventions = &c
+	}
+	p.attachAll()
+	detail := fmt.Sprintf("%s, capitalisation %s, annualisation au-delà de %d jours",
+		cmp.Or(c.DayCount, analytics.DayCountActual36525), cmp.Or(c.Compounding, analytics.CompoundYearly), cmp.Or(c.MinAnnualizationDays, analytics.DefaultMinAnnualizationDays))
+	return p.recordChange(JournalEntry{Op: OpSetConventions, Detail: detail, BeforeSettings: before})
+}
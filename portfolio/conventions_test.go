@@ -0,0 +1,68 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+func TestConventionsArePerPortfolio(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, b *Portfolio)
+	}{
+		{name: "SetConventions", change: func(t *testing.T, b *Portfolio) {
+			c := analytics.RateConventions{DayCount: analytics.DayCountActual360, Compounding: analytics.CompoundMonthly}
+			if err := b.SetConventions(c); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "UnmarshalJSON", change: func(t *testing.T, b *Portfolio) {
+			c := analytics.RateConventions{DayCount: analytics.DayCount30360, Compounding: analytics.CompoundContinuous}
+			data, err := json.Marshal(map[string]any{"investments": map[string]any{}, "conventions": c})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, NewPortfolio()); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.SetConventions(c); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Deux portefeuilles identiques, projetés au-delà de leur dernière NAV
+			a, b := NewPortfolio(), NewPortfolio()
+			for _, p := range []*Portfolio{a, b} {
+				if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+					t.Fatal(err)
+				}
+				if err := p.AddNAV("A", "2024-01-01", 1000); err != nil {
+					t.Fatal(err)
+				}
+				if err := p.AddNAV("A", "2025-01-01", 1060); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, want, err := a.GetPortfolioValue("2030-01-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, got, err := b.GetPortfolioValue("2030-01-01"); err != nil || got != want {
+				t.Fatalf("portefeuilles identiques valorisés %.2f et %.2f (%v)", want, got, err)
+			}
+
+			tt.change(t, b)
+			if _, got, err := a.GetPortfolioValue("2030-01-01"); err != nil || got != want {
+				t.Errorf("les conventions de B ont changé la valeur de A: %.2f (%v), %.2f attendu", got, err, want)
+			}
+			if _, got, err := b.GetPortfolioValue("2030-01-01"); err != nil || got == want {
+				t.Errorf("les conventions de B n'ont pas changé sa valeur: %.2f (%v)", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// minCorrelationObservations est le nombre minimal de rendements communs pour une corrélation
+const minCorrelationObservations = 3
+
+// CorrelationMatrix contient les corrélations des rendements entre investissements
+type CorrelationMatrix struct {
+	Names        []string    `json:"names"`        // Investissements, triés par nom
+	Values       [][]float64 `json:"values"`       // Values[i][j] : corrélation entre Names[i] et Names[j]
+	Defined      [][]bool    `json:"defined"`      // Faux si la corrélation est incalculable (Values vaut alors 0)
+	Observations [][]int     `json:"observations"` // Nombre de rendements communs utilisés
+}
+
+// CorrelationMatrix calcule la corrélation des rendements (corrigés des flux) entre
+// investissements ouverts sur la période [from, to] (bornes vides : non bornée). Les NAV
+// n'étant pas publiées aux mêmes dates, chaque paire est ramenée sur la grille de la
+// configuration (mensuelle par défaut, voir Alignment) couvrant la période commune aux
+// deux historiques. Une paire sans au moins minCorrelationObservations
+// rendements communs, ou dont l'un des rendements est constant, n'a pas de corrélation définie.
+func (p *Portfolio) CorrelationMatrix(from, to string) (*CorrelationMatrix, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	align := ConfiguredAlignment()
+	var indexes []timeseries.Series[float64]
+	m := &CorrelationMatrix{}
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed {
+			continue
+		}
+		m.Names = append(m.Names, name)
+		indexes = append(indexes, analytics.PerformanceIndex(inv.periodReturns()))
+	}
+	if len(m.Names) == 0 {
+		return nil, fmt.Errorf("aucun investissement ouvert")
+	}
+
+	n := len(m.Names)
+	m.Values = make([][]float64, n)
+	m.Defined = make([][]bool, n)
+	m.Observations = make([][]int, n)
+	for i := range m.Values {
+		m.Values[i] = make([]float64, n)
+		m.Defined[i] = make([]bool, n)
+		m.Observations[i] = make([]int, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			a, b, err := align.alignedReturns(indexes[i], indexes[j], start, end)
+			if err != nil {
+				return nil, err
+			}
+			corr, defined := 0.0, false
+			if len(a) >= minCorrelationObservations {
+				corr, defined = analytics.Pearson(a, b)
+			}
+			m.Values[i][j], m.Values[j][i] = corr, corr
+			m.Defined[i][j], m.Defined[j][i] = defined, defined
+			m.Observations[i][j], m.Observations[j][i] = len(a), len(a)
+		}
+	}
+	return m, nil
+}
+
+// alignedReturns retourne les rendements logarithmiques de deux indices entre les dates
+// de la grille, sur leur période commune restreinte à [start, end] si ces bornes ne sont
+// pas nulles
+func (a Alignment) alignedReturns(x, y timeseries.Series[float64], start, end time.Time) ([]float64, []float64, error) {
+	if len(x) < 2 || len(y) < 2 {
+		return nil, nil, nil
+	}
+	aligned, err := a.alignSeries([]timeseries.Series[float64]{x, y}, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	return timeseries.Values(timeseries.Diff(aligned[0])), timeseries.Values(timeseries.Diff(aligned[1])), nil
+}
+
+// latest retourne la plus tardive des dates non nulles
+func latest(dates ...time.Time) time.Time {
+	var result time.Time
+	for _, d := range dates {
+		if d.After(result) {
+			result = d
+		}
+	}
+	return result
+}
+
+// earliest retourne la plus précoce des dates non nulles
+func earliest(dates ...time.Time) time.Time {
+	var result time.Time
+	for _, d := range dates {
+		if !d.IsZero() && (result.IsZero() || d.Before(result)) {
+			result = d
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CryptoPrefix préfixe les identifiants de cryptoactifs (ex. "crypto:bitcoin"), orientés
+// par QuoteRouter vers le fournisseur de cours dédié
+const CryptoPrefix = "crypto"
+
+// QuoteRouter oriente chaque identifiant vers un fournisseur selon son préfixe
+// ("crypto:bitcoin" vers Providers["crypto"] avec l'identifiant "bitcoin") ; les
+// identifiants sans préfixe connu sont confiés au fournisseur par défaut.
+type QuoteRouter struct {
+	Default   QuoteProvider
+	Providers map[string]QuoteProvider
+}
+
+// Quote retourne le cours obtenu auprès du fournisseur correspondant à l'identifiant
+func (r QuoteRouter) Quote(ctx context.Context, identifier string) (Quote, error) {
+	if prefix, id, found := strings.Cut(identifier, ":"); found {
+		if provider, ok := r.Providers[prefix]; ok {
+			return provider.Quote(ctx, id)
+		}
+	}
+	if r.Default == nil {
+		return Quote{}, fmt.Errorf("aucun fournisseur de cours pour %s", identifier)
+	}
+	return r.Default.Quote(ctx, identifier)
+}
+
+// CoinGeckoQuoteProvider interroge l'API publique de CoinGecko par identifiant de
+// cryptoactif ("bitcoin", "ethereum"). Les cours sont cotés en continu : la NAV est
+// datée du jour de la dernière mise à jour du cours.
+type CoinGeckoQuoteProvider struct {
+	BaseURL  string       // "https://api.coingecko.com" si vide
+	Client   *http.Client // http.DefaultClient si nil
+	Currency Currency     // Devise de cotation (EUR si vide)
+}
+
+// Quote retourne le dernier cours du cryptoactif
+func (c CoinGeckoQuoteProvider) Quote(ctx context.Context, identifier string) (Quote, error) {
+	base, client, currency := c.BaseURL, c.Client, c.Currency
+	if base == "" {
+		base = "https://api.coingecko.com"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	vs := strings.ToLower(string(currency))
+	query := url.Values{"ids": {identifier}, "vs_currencies": {vs}, "include_last_updated_at": {"true"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/v3/simple/price?"+query.Encode(), nil)
+	if err != nil {
+		return Quote{}, err
+	}
+	req.Header.Set("User-Agent", "david")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Quote{}, err
+	}
+	defer resp.Body.Close()
+
+	var prices map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return Quote{}, fmt.Errorf("réponse illisible pour %s (HTTP %d): %w", identifier, resp.StatusCode, err)
+	}
+	price, ok := prices[identifier][vs]
+	if resp.StatusCode != http.StatusOK || !ok {
+		return Quote{}, fmt.Errorf("aucun cours pour %s en %s (HTTP %d)", identifier, currency, resp.StatusCode)
+	}
+
+	t := time.Now().UTC()
+	if updated := int64(prices[identifier]["last_updated_at"]); updated > 0 {
+		t = time.Unix(updated, 0).UTC()
+	}
+	return Quote{
+		Date:     time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC),
+		Price:    price,
+		Currency: currency,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout est le format des dates échangées avec l'extérieur (API, fichiers, affichage)
+const DateLayout = "2006-01-02"
+
+// ParseDate lit une date au format AAAA-MM-JJ et retourne ErrInvalidDate si elle est mal formée
+func ParseDate(s string) (time.Time, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date '%s' invalide: %w", s, ErrInvalidDate)
+	}
+	return t, nil
+}
+
+// Today retourne la date du jour, à minuit UTC comme les dates lues par ParseDate
+func Today() time.Time {
+	t, _ := ParseDate(FormatDate(time.Now()))
+	return t
+}
+
+// FormatDate formate une date au format AAAA-MM-JJ
+func FormatDate(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// Date est une date du modèle, à minuit UTC comme celles lues par ParseDate, qui
+// s'affiche et se sérialise au format AAAA-MM-JJ. Elle porte les méthodes de time.Time ;
+// une date nulle est omise des champs marqués omitzero.
+type Date struct {
+	time.Time
+}
+
+// String formate la date au format AAAA-MM-JJ
+func (d Date) String() string {
+	return FormatDate(d.Time)
+}
+
+// MarshalJSON écrit la date au format AAAA-MM-JJ
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON lit une date au format AAAA-MM-JJ ; null laisse la date nulle
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// NewNAV construit une NAV à partir d'une date au format AAAA-MM-JJ
+func NewNAV(date string, value float64) (NAV, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return NAV{}, err
+	}
+	return NAV{Date: Date{t}, Value: NewMoney(value)}, nil
+}
+
+// investmentAlias permet de lire Investment sans rappeler sa propre méthode UnmarshalJSON
+type investmentAlias Investment
+
+// UnmarshalJSON lit un investissement et prépare son cache de mesures
+func (inv *Investment) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*investmentAlias)(inv)); err != nil {
+		return err
+	}
+	inv.metrics = newMetricsCache()
+	return nil
+}
@@ -0,0 +1,135 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// nearDuplicateTolerance est l'écart relatif en dessous duquel deux NAV de même date
+// sont un simple doublon (même valeur saisie deux fois, arrondis d'export) plutôt
+// qu'un conflit
+const nearDuplicateTolerance = 1e-4
+
+// NAVMerge décrit des NAV d'une même date réduites à une seule par la politique de doublon
+type NAVMerge struct {
+	Investment string
+	Date       time.Time
+	Values     []Money // Valeurs en présence, dans l'ordre d'arrivée (l'enregistrée d'abord)
+	Kept       Money   // Valeur retenue
+	Conflict   bool    // Faux pour un simple doublon (valeurs égales à nearDuplicateTolerance près)
+}
+
+func (m NAVMerge) String() string {
+	kind := "doublon"
+	if m.Conflict {
+		kind = "conflit"
+	}
+	values := make([]string, len(m.Values))
+	for i, v := range m.Values {
+		values[i] = fmt.Sprintf("%.2f", v.Float64())
+	}
+	return fmt.Sprintf("%s au %s: %s %v -> %.2f", m.Investment, FormatDate(m.Date), kind, values, m.Kept.Float64())
+}
+
+// ParseDuplicateNAVPolicy lit une politique de doublon ; keep-first et keep-last sont
+// acceptés pour keep-existing et replace
+func ParseDuplicateNAVPolicy(s string) (DuplicateNAVPolicy, error) {
+	switch policy := DuplicateNAVPolicy(s); policy {
+	case "keep-first":
+		return DuplicateNAVKeepExisting, nil
+	case "keep-last":
+		return DuplicateNAVReplace, nil
+	case DuplicateNAVError, DuplicateNAVReplace, DuplicateNAVKeepExisting, DuplicateNAVAverage:
+		return policy, nil
+	}
+	return "", InvalidField("duplicate_nav_policy", s, "politique de doublon inconnue: %s (error, keep-first, keep-last, average)", s)
+}
+
+// resolveDuplicates réduit les valeurs d'une même date à une seule. Un simple doublon
+// garde la première valeur quelle que soit la politique ; un conflit suit la politique,
+// DuplicateNAVError (ou vide) le refusant par ErrDuplicateNAV.
+func resolveDuplicates(policy DuplicateNAVPolicy, values []Money) (NAVMerge, error) {
+	m := NAVMerge{Values: values, Kept: values[0]}
+	low, high := values[0], values[0]
+	var sum Money
+	for _, v := range values {
+		low, high = min(low, v), max(high, v)
+		sum += v
+	}
+	scale := math.Max(math.Abs(low.Float64()), math.Abs(high.Float64()))
+	if (high - low).Float64() <= scale*nearDuplicateTolerance {
+		return m, nil
+	}
+
+	m.Conflict = true
+	switch policy {
+	case DuplicateNAVKeepExisting:
+	case DuplicateNAVReplace:
+		m.Kept = values[len(values)-1]
+	case DuplicateNAVAverage:
+		m.Kept = NewMoney(sum.Float64() / float64(len(values))).RoundCents()
+	default:
+		return m, ErrDuplicateNAV
+	}
+	return m, nil
+}
+
+// DeduplicateNAVs réduit à une seule les NAV de même date des historiques, présentes
+// par exemple dans un fichier modifié à la main, selon la politique donnée (celle du
+// portefeuille si vide). Les historiques ne sont modifiés que si dryRun est faux et
+// qu'aucun conflit n'est refusé par la politique.
+func (p *Portfolio) DeduplicateNAVs(policy DuplicateNAVPolicy, dryRun bool) ([]NAVMerge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if policy == "" {
+		policy = p.DuplicateNAVPolicy
+	}
+	var merges []NAVMerge
+	deduped := make(map[string][]NAV)
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		var navs []NAV
+		changed := false
+		for i := 0; i < len(inv.NAVHistory); {
+			j := i + 1
+			for j < len(inv.NAVHistory) && inv.NAVHistory[j].Date.Equal(inv.NAVHistory[i].Date.Time) {
+				j++
+			}
+			nav := inv.NAVHistory[i]
+			if j-i > 1 {
+				values := make([]Money, 0, j-i)
+				for _, n := range inv.NAVHistory[i:j] {
+					values = append(values, n.Value)
+				}
+				m, err := resolveDuplicates(policy, values)
+				m.Investment, m.Date = name, nav.Date.Time
+				if err != nil {
+					return nil, fmt.Errorf("NAV de %s au %s: %w", name, FormatDate(nav.Date.Time), err)
+				}
+				merges = append(merges, m)
+				nav.Value = m.Kept
+				changed = true
+			}
+			navs = append(navs, nav)
+			i = j
+		}
+		if changed {
+			deduped[name] = navs
+		}
+	}
+	if dryRun || len(deduped) == 0 {
+		return merges, nil
+	}
+
+	for name, navs := range deduped {
+		inv := p.Investments[name]
+		before := inv.clone()
+		inv.NAVHistory = navs
+		inv.invalidate()
+		p.record(OpUpdateNAV, name, fmt.Sprintf("%d NAV en double fusionnées", len(before.NAVHistory)-len(navs)), before)
+		p.valueChanged(name)
+	}
+	return merges, nil
+}
@@ -0,0 +1,134 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Distribution représente un dividende ou une distribution versée par un investissement.
+// Une distribution versée en numéraire sort de la valeur de l'investissement et est
+// traitée comme un revenu de l'investisseur dans les mesures de performance ; une
+// distribution réinvestie reste incluse dans les NAV et n'est donc pas un flux.
+type Distribution struct {
+	Date       Date  `json:"date"`                 // Date de versement
+	Amount     Money `json:"amount"`               // Montant distribué, toujours positif
+	Reinvested bool  `json:"reinvested,omitempty"` // Distribution réinvestie dans l'investissement
+}
+
+// AddDistribution enregistre une distribution versée par un investissement. Une
+// distribution réinvestie dans un investissement suivi en parts donne lieu à l'achat des
+// parts correspondantes au prix du jour (voir reinvest).
+func (p *Portfolio) AddDistribution(investmentName string, date string, amount float64, reinvested bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if NewMoney(amount) <= 0 {
+		return fmt.Errorf("le montant de la distribution doit être positif: %w", ErrInvalidAmount)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+
+	before := inv.clone()
+	inv.Distributions = append(inv.Distributions, Distribution{Date: Date{t}, Amount: NewMoney(amount), Reinvested: reinvested})
+	inv.invalidate()
+	if reinvested {
+		inv.reinvest(t, NewMoney(amount))
+	}
+
+	// Trier par date
+	sort.SliceStable(inv.Distributions, func(i, j int) bool {
+		return inv.Distributions[i].Date.Before(inv.Distributions[j].Date.Time)
+	})
+
+	detail := fmt.Sprintf("%.2f au %s", amount, date)
+	if reinvested {
+		detail += ", réinvestie"
+	}
+	p.record(OpAddDistribution, investmentName, detail, before)
+	p.valueChanged(investmentName)
+	return nil
+}
+
+// TotalDistributions retourne le cumul des distributions versées en numéraire
+// et réinvesties sur la période [from, to] (bornes vides : non bornée)
+func (inv *Investment) TotalDistributions(from, to string) (paid, reinvested Money, err error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, d := range inv.Distributions {
+		if d.Date.Before(start) || (!end.IsZero() && d.Date.After(end)) {
+			continue
+		}
+		if d.Reinvested {
+			reinvested += d.Amount
+		} else {
+			paid += d.Amount
+		}
+	}
+	return paid, reinvested, nil
+}
+
+// reinvest enregistre l'achat de parts financé par une distribution réinvestie, au prix
+// unitaire déduit de la valeur de la ligne à cette date hors réinvestissement. Cet achat
+// augmente les parts détenues et le prix de revient mais n'est pas un apport : aucun flux
+// n'est ajouté. Sans parts suivies, la distribution reste seulement incluse dans les NAV.
+func (inv *Investment) reinvest(date time.Time, amount Money) {
+	units := inv.unitsAt(date)
+	if units <= 0 {
+		return
+	}
+	value, held := inv.historicalValue(date)
+	if !held {
+		return
+	}
+	if _, found := inv.navIndex(date); found {
+		// Une NAV du jour inclut déjà la distribution réinvestie
+		value -= amount.Float64()
+	}
+	if value <= 0 {
+		return
+	}
+
+	price := NewPrice(value / units.Float64())
+	tx := Transaction{Date: Date{date}, Type: Buy, Units: NewQuantity(amount.Float64() / price.Float64()), Price: price, Reinvested: true}
+	inv.Transactions = append(inv.Transactions, tx)
+	sort.SliceStable(inv.Transactions, func(i, j int) bool {
+		return inv.Transactions[i].Date.Before(inv.Transactions[j].Date.Time)
+	})
+}
+
+// ParsePeriod lit les bornes d'une période, une borne vide restant la date zéro
+func ParsePeriod(from, to string) (start, end time.Time, err error) {
+	if from != "" {
+		if start, err = ParseDate(from); err != nil {
+			return start, end, err
+		}
+	}
+	if to != "" {
+		if end, err = ParseDate(to); err != nil {
+			return start, end, err
+		}
+	}
+	return start, end, nil
+}
+
+// paidDistributionFlows retourne les distributions versées en numéraire sous forme de
+// retraits, pour les intégrer aux calculs de rendement corrigés des flux
+func (inv *Investment) paidDistributionFlows() []CashFlow {
+	var flows []CashFlow
+	for _, d := range inv.Distributions {
+		if !d.Reinvested {
+			flows = append(flows, CashFlow{Date: d.Date, Amount: d.Amount, Type: Withdrawal})
+		}
+	}
+	return flows
+}
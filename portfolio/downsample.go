@@ -0,0 +1,116 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// bucket retourne la période de la granularité contenant une date (semaine ISO, mois,
+// trimestre ou année), sous une forme comparable
+func (s SeriesStep) bucket(t time.Time) (int, error) {
+	switch s {
+	case StepWeekly:
+		year, week := t.ISOWeek()
+		return year*100 + week, nil
+	case StepMonthly:
+		return t.Year()*100 + int(t.Month()), nil
+	case StepQuarterly:
+		return t.Year()*100 + (int(t.Month())-1)/3, nil
+	case StepYearly:
+		return t.Year(), nil
+	default:
+		return 0, InvalidField("granularity", s, "granularité inconnue: %s (weekly, monthly, quarterly, yearly)", s)
+	}
+}
+
+// DownsampleNAVs réduit un historique trié à la dernière NAV de chaque période, plus la
+// plus basse et la plus haute de la période : les sommets et les creux sont conservés,
+// de sorte que la pire baisse reste exacte. La première NAV est toujours conservée, ainsi
+// que toutes celles datées de until ou après (until nul : aucune).
+func DownsampleNAVs(navs []NAV, granularity SeriesStep, until time.Time) ([]NAV, error) {
+	if _, err := granularity.bucket(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	kept := make([]NAV, 0, len(navs))
+	for start := 0; start < len(navs); {
+		if !until.IsZero() && !navs[start].Date.Before(until) {
+			kept = append(kept, navs[start:]...)
+			break
+		}
+		key, _ := granularity.bucket(navs[start].Date.Time)
+		end, low, high := start+1, start, start
+		for ; end < len(navs); end++ {
+			if !until.IsZero() && !navs[end].Date.Before(until) {
+				break
+			}
+			if k, _ := granularity.bucket(navs[end].Date.Time); k != key {
+				break
+			}
+			if navs[end].Value < navs[low].Value {
+				low = end
+			}
+			if navs[end].Value > navs[high].Value {
+				high = end
+			}
+		}
+		for i := start; i < end; i++ {
+			if i == 0 || i == low || i == high || i == end-1 {
+				kept = append(kept, navs[i])
+			}
+		}
+		start = end
+	}
+	return kept, nil
+}
+
+// Downsample retourne l'historique de NAV réduit à la granularité (weekly, monthly,
+// quarterly ou yearly) : dernière NAV de chaque période, extrêmes de la période et
+// première NAV. L'investissement n'est pas modifié (voir Portfolio.CompactNAVs).
+func (inv *Investment) Downsample(granularity SeriesStep) ([]NAV, error) {
+	return DownsampleNAVs(inv.NAVHistory, granularity, time.Time{})
+}
+
+// CompactNAVs réduit l'historique de NAV d'un investissement à la granularité pour les
+// dates antérieures à cutoff (vide : tout l'historique), l'historique récent restant
+// intact. Les NAV des comptes rémunérés, obligations et investissements composés, calculées
+// à chaque chargement, ne sont pas compactables. Retourne le nombre de NAV supprimées.
+func (p *Portfolio) CompactNAVs(name string, granularity SeriesStep, cutoff string) (int, error) {
+	until, err := ParseCutoff(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return 0, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	if inv.Cash != nil || inv.Bond != nil || inv.Holdings != nil {
+		return 0, fmt.Errorf("les NAV de '%s' sont calculées, elles ne peuvent pas être compactées", name)
+	}
+	navs, err := DownsampleNAVs(inv.NAVHistory, granularity, until)
+	if err != nil {
+		return 0, err
+	}
+	removed := len(inv.NAVHistory) - len(navs)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	before := inv.clone()
+	inv.NAVHistory = navs
+	inv.invalidate()
+	p.record(OpCompactNAVs, name, fmt.Sprintf("%s: %d NAV supprimées", granularity, removed), before)
+	return removed, nil
+}
+
+// ParseCutoff lit la date limite d'un compactage, nulle si vide
+func ParseCutoff(cutoff string) (time.Time, error) {
+	if cutoff == "" {
+		return time.Time{}, nil
+	}
+	return ParseDate(cutoff)
+}
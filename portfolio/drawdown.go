@@ -0,0 +1,67 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// Drawdown décrit la pire baisse d'une série de valeurs, mesurée sur l'indice de
+// performance corrigé des flux afin que les retraits ne soient pas vus comme des pertes
+type Drawdown struct {
+	Depth    float64 `json:"depth"`             // Baisse entre le sommet et le creux (%, positive)
+	Peak     Date    `json:"peak"`              // Date du sommet précédant la baisse
+	Trough   Date    `json:"trough"`            // Date du creux
+	Recovery Date    `json:"recovery,omitzero"` // Date à laquelle le sommet est de nouveau atteint (zéro si jamais)
+}
+
+// Recovered indique si la valeur est revenue à son sommet après la baisse
+func (d Drawdown) Recovered() bool {
+	return !d.Recovery.IsZero()
+}
+
+// MaxDrawdown retourne la pire baisse du portefeuille (investissements ouverts) entre
+// from et to ; une borne vide signifie « sans limite »
+func (p *Portfolio) MaxDrawdown(from, to string) (Drawdown, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return Drawdown{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	returns, err := p.alignedReturns(start, end)
+	if err != nil {
+		return Drawdown{}, err
+	}
+	return maxDrawdown(returns)
+}
+
+// maxDrawdown parcourt l'indice de performance construit en chaînant les rendements
+func maxDrawdown(returns []analytics.PeriodReturn) (Drawdown, error) {
+	if len(returns) == 0 {
+		return Drawdown{}, fmt.Errorf("au moins 2 valeurs sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	// Les niveaux sont en logarithme : la baisse vaut 1 - exp(niveau - sommet)
+	level, peakLevel := 0.0, 0.0
+	peakDate := returns[0].Start
+	var worst Drawdown
+	for _, r := range returns {
+		level += r.LogReturn
+		if level >= peakLevel {
+			// Premier retour au sommet de la pire baisse en cours
+			if worst.Depth > 0 && !worst.Recovered() && worst.Peak.Equal(peakDate) {
+				worst.Recovery = Date{r.End}
+			}
+			peakLevel, peakDate = level, r.End
+			continue
+		}
+		if depth := -math.Expm1(level-peakLevel) * 100; depth > worst.Depth {
+			worst = Drawdown{Depth: depth, Peak: Date{peakDate}, Trough: Date{r.End}}
+		}
+	}
+	return worst, nil
+}
@@ -0,0 +1,216 @@
+package portfolio
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Paramètres du chiffrement des fichiers de portefeuille : AES-256-GCM, clé dérivée de
+// la phrase secrète par Argon2id avec les paramètres recommandés par la RFC 9106 lorsque
+// la mémoire est comptée (3 passes, 64 Mio, 4 fils)
+const (
+	encryptedFormat = "david-aes-256-gcm"
+	encryptionKDF   = "argon2id"
+	argon2Time      = 3
+	argon2Memory    = 64 * 1024 // Kio
+	argon2Threads   = 4
+	encryptionSalt  = 16 // Octets
+)
+
+// Bornes des paramètres lus dans une enveloppe : en dessous, la phrase secrète serait
+// trop facile à attaquer (un fichier altéré pourrait réduire la dérivation à une passe) ;
+// au-dessus, l'ouverture épuiserait le temps ou la mémoire disponibles. Les minimums
+// d'Argon2id sont ceux de l'OWASP (2 passes, 19 Mio).
+const (
+	argon2MinTime    = 2
+	argon2MaxTime    = 16
+	argon2MinMemory  = 19 * 1024
+	argon2MaxMemory  = 1024 * 1024
+	argon2MaxThreads = 64
+)
+
+// encryptedFile est l'enveloppe JSON d'un portefeuille chiffré ; les champs binaires
+// sont encodés en base64
+type encryptedFile struct {
+	Format     string `json:"format"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"` // Passes d'Argon2id
+	Memory     int    `json:"memory"`     // Mémoire d'Argon2id (Kio)
+	Threads    int    `json:"threads"`    // Parallélisme d'Argon2id
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// portfolioKey est la clé dérivée d'un portefeuille chiffré, conservée en mémoire pour
+// ne pas refaire la dérivation à chaque enregistrement
+type portfolioKey struct {
+	salt []byte
+	key  []byte
+}
+
+// newPortfolioKey dérive une clé avec un sel aléatoire
+func newPortfolioKey(passphrase string) (*portfolioKey, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("la phrase secrète ne peut pas être vide")
+	}
+	salt := make([]byte, encryptionSalt)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, 32)
+	return &portfolioKey{salt: salt, key: key}, nil
+}
+
+// deriveKey dérive la clé AES-256 de l'enveloppe après avoir vérifié ses paramètres
+func (f *encryptedFile) deriveKey(passphrase string) ([]byte, error) {
+	if len(f.Salt) < encryptionSalt {
+		return nil, fmt.Errorf("sel de %d octets, au moins %d attendus: %w", len(f.Salt), encryptionSalt, ErrWrongPassphrase)
+	}
+	if f.KDF != encryptionKDF {
+		return nil, fmt.Errorf("fonction de dérivation non prise en charge: %s", f.KDF)
+	}
+	if f.Iterations < argon2MinTime || f.Iterations > argon2MaxTime ||
+		f.Memory < argon2MinMemory || f.Memory > argon2MaxMemory ||
+		f.Threads < 1 || f.Threads > argon2MaxThreads {
+		return nil, fmt.Errorf("paramètres Argon2id hors bornes (passes %d, mémoire %d Kio, fils %d): %w",
+			f.Iterations, f.Memory, f.Threads, ErrWrongPassphrase)
+	}
+	return argon2.IDKey([]byte(passphrase), f.Salt, uint32(f.Iterations), uint32(f.Memory), uint8(f.Threads), 32), nil
+}
+
+// additionalData lie les paramètres de l'enveloppe au texte chiffré
+func (f *encryptedFile) additionalData() []byte {
+	return []byte(f.Format + "|" + f.KDF + "|" + strconv.Itoa(f.Iterations) + "|" + strconv.Itoa(f.Memory) + "|" + strconv.Itoa(f.Threads))
+}
+
+// seal chiffre le JSON d'un portefeuille et retourne l'enveloppe sérialisée
+func (k *portfolioKey) seal(plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	f := encryptedFile{Format: encryptedFormat, KDF: encryptionKDF, Iterations: argon2Time, Memory: argon2Memory,
+		Threads: argon2Threads, Salt: k.salt, Nonce: make([]byte, gcm.NonceSize())}
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return nil, err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plaintext, f.additionalData())
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// encryptedEnvelope retourne l'enveloppe si data est un portefeuille chiffré, nil sinon
+func encryptedEnvelope(data []byte) *encryptedFile {
+	var f encryptedFile
+	if json.Unmarshal(data, &f) != nil || f.Format != encryptedFormat {
+		return nil
+	}
+	return &f
+}
+
+// open déchiffre l'enveloppe et retourne le JSON du portefeuille et la clé des
+// enregistrements suivants : celle de l'enveloppe, ou une clé dérivée à nouveau si
+// l'enveloppe n'a pas les paramètres Argon2id courants
+func (f *encryptedFile) open(passphrase string) ([]byte, *portfolioKey, error) {
+	key, err := f.deriveKey(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(f.Nonce) != gcm.NonceSize() {
+		return nil, nil, ErrWrongPassphrase
+	}
+	plaintext, err := gcm.Open(nil, f.Nonce, f.Data, f.additionalData())
+	if err != nil {
+		return nil, nil, ErrWrongPassphrase
+	}
+	if f.Iterations != argon2Time || f.Memory != argon2Memory || f.Threads != argon2Threads {
+		k, err := newPortfolioKey(passphrase)
+		return plaintext, k, err
+	}
+	return plaintext, &portfolioKey{salt: f.Salt, key: key}, nil
+}
+
+// SetPassphrase active le chiffrement des enregistrements du portefeuille avec une
+// phrase secrète ; une phrase vide le désactive
+func (p *Portfolio) SetPassphrase(passphrase string) error {
+	var key *portfolioKey
+	if passphrase != "" {
+		var err error
+		if key, err = newPortfolioKey(passphrase); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.key = key
+	p.changed("set-passphrase", "")
+	return nil
+}
+
+// Encrypted indique si le portefeuille est enregistré chiffré
+func (p *Portfolio) Encrypted() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.key != nil
+}
+
+// encryptionKey retourne la clé de chiffrement, nil si le portefeuille est en clair
+func (p *Portfolio) encryptionKey() *portfolioKey {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.key
+}
+
+// PassphrasePrompt saisit une phrase secrète après avoir affiché prompt
+type PassphrasePrompt func(prompt string) (string, error)
+
+// activePrompt saisit les phrases secrètes absentes de DAVID_PASSPHRASE ; aucune saisie
+// n'est possible tant que SetPassphrasePrompt n'a pas été appelé
+var activePrompt atomic.Pointer[PassphrasePrompt]
+
+// SetPassphrasePrompt définit la saisie des phrases secrètes, par exemple masquée sur le
+// terminal ; nil la désactive
+func SetPassphrasePrompt(prompt PassphrasePrompt) {
+	if prompt == nil {
+		activePrompt.Store(nil)
+		return
+	}
+	activePrompt.Store(&prompt)
+}
+
+// ReadPassphrase obtient la phrase secrète d'un portefeuille chiffré : variable
+// DAVID_PASSPHRASE, sinon saisie définie par SetPassphrasePrompt
+func ReadPassphrase(prompt string) (string, error) {
+	if passphrase := os.Getenv("DAVID_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	read := activePrompt.Load()
+	if read == nil {
+		return "", fmt.Errorf("phrase secrète requise (variable DAVID_PASSPHRASE)")
+	}
+	return (*read)(prompt)
+}
@@ -0,0 +1,140 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encryptedPortfolio enregistre un portefeuille chiffré avec passphrase et retourne le
+// chemin du fichier
+func encryptedPortfolio(t *testing.T, passphrase string) string {
+	t.Helper()
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-06-01", 1042.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetPassphrase(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	if err := p.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	path := encryptedPortfolio(t, "correct horse battery staple")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "nav_history") || strings.Contains(string(data), "1042.5") {
+		t.Fatal("le fichier chiffré contient le portefeuille en clair")
+	}
+	envelope := encryptedEnvelope(data)
+	if envelope == nil {
+		t.Fatal("enveloppe chiffrée non reconnue")
+	}
+	if envelope.KDF != encryptionKDF || envelope.Iterations != argon2Time || envelope.Memory != argon2Memory || envelope.Threads != argon2Threads {
+		t.Errorf("paramètres %s %d/%d/%d, %s %d/%d/%d attendus", envelope.KDF, envelope.Iterations, envelope.Memory, envelope.Threads,
+			encryptionKDF, argon2Time, argon2Memory, argon2Threads)
+	}
+
+	t.Setenv("DAVID_PASSPHRASE", "correct horse battery staple")
+	p, err := LoadPortfolioJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Encrypted() {
+		t.Error("le portefeuille rechargé devrait rester chiffré")
+	}
+	inv, err := p.Investment("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.NAVHistory) != 1 || inv.NAVHistory[0].Value != NewMoney(1042.5) {
+		t.Errorf("NAV rechargées %+v, 1042.50 au 2024-06-01 attendu", inv.NAVHistory)
+	}
+
+	// Réenregistré avec la clé conservée, puis relu
+	if err := p.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPortfolioJSON(path); err != nil {
+		t.Fatalf("relecture après réenregistrement: %v", err)
+	}
+}
+
+func TestEncryptionWrongPassphrase(t *testing.T) {
+	path := encryptedPortfolio(t, "correct horse battery staple")
+	t.Setenv("DAVID_PASSPHRASE", "Tr0ub4dor&3")
+	if _, err := LoadPortfolioJSON(path); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("phrase secrète erronée: ErrWrongPassphrase attendue, %v obtenue", err)
+	}
+}
+
+func TestEncryptionTamperedEnvelope(t *testing.T) {
+	path := encryptedPortfolio(t, "correct horse battery staple")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		tamper func(f *encryptedFile)
+	}{
+		{name: "passes réduites", tamper: func(f *encryptedFile) { f.Iterations = 1 }},
+		{name: "mémoire réduite", tamper: func(f *encryptedFile) { f.Memory = 1024 }},
+		{name: "mémoire excessive", tamper: func(f *encryptedFile) { f.Memory = 4 * 1024 * 1024 }},
+		{name: "aucun fil", tamper: func(f *encryptedFile) { f.Threads = 0 }},
+		{name: "passes modifiées dans les bornes", tamper: func(f *encryptedFile) { f.Iterations = argon2Time + 1 }},
+		{name: "sel court", tamper: func(f *encryptedFile) { f.Salt = f.Salt[:8] }},
+		{name: "nonce tronqué", tamper: func(f *encryptedFile) { f.Nonce = f.Nonce[:4] }},
+		{name: "données altérées", tamper: func(f *encryptedFile) { f.Data[0] ^= 1 }},
+		{name: "dérivation PBKDF2", tamper: func(f *encryptedFile) { f.KDF, f.Iterations, f.Memory, f.Threads = "pbkdf2-sha256", 600_000, 0, 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f encryptedFile
+			if err := json.Unmarshal(data, &f); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(&f)
+			if _, _, err := f.open("correct horse battery staple"); err == nil {
+				t.Error("enveloppe altérée acceptée")
+			}
+		})
+	}
+}
+
+func TestSetPassphraseEmptyDisablesEncryption(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.SetPassphrase("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetPassphrase(""); err != nil {
+		t.Fatal(err)
+	}
+	if p.Encrypted() {
+		t.Error("une phrase vide devrait désactiver le chiffrement")
+	}
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	if err := p.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encryptedEnvelope(data) != nil {
+		t.Error("portefeuille enregistré chiffré")
+	}
+}
@@ -0,0 +1,517 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// metricVariables décrit les variables disponibles dans les expressions des indicateurs
+// personnalisés, pour chaque investissement. Les montants sont en devise de
+// consolidation, les taux en fraction (0.05 pour 5 %).
+var metricVariables = map[string]string{
+	"value":          "valeur (dernière NAV, sinon montant investi)",
+	"invested":       "capital net investi, flux inclus",
+	"share":          "part de la valeur dans le portefeuille",
+	"return":         "taux de performance annualisé",
+	"drawdown":       "perte maximale (positive)",
+	"ter":            "frais courants annuels",
+	"reference_rate": "taux de référence",
+	"distributions":  "distributions versées",
+	"units":          "parts détenues",
+	"years":          "durée de détention en années, jusqu'à la dernière NAV",
+}
+
+// exprFunctions associe les fonctions des expressions à leur nombre d'arguments
+// (minimum, maximum ; -1 sans maximum)
+var exprFunctions = map[string][2]int{
+	"abs": {1, 1}, "sqrt": {1, 1}, "log": {1, 1}, "exp": {1, 1},
+	"round": {1, 2}, "min": {1, -1}, "max": {1, -1}, "if": {3, 3}, "coalesce": {1, -1},
+}
+
+// Metric est un indicateur personnalisé : une colonne calculée pour chaque investissement
+// à partir d'une expression, par exemple gain = value - invested.
+//
+// Les expressions combinent nombres, variables (voir metricVariables, et les indicateurs
+// définis plus tôt), opérateurs + - * / ^, comparaisons (< <= > >= == !=, qui valent 1
+// ou 0), opérateurs logiques && || ! et fonctions abs, sqrt, log, exp,
+// round(x[, décimales]), min, max, if(condition, alors, sinon) et coalesce (premier
+// argument défini). Une variable sans
+// valeur (performance sans historique...) rend le résultat indéfini (NaN), comme une
+// division par zéro.
+type Metric struct {
+	Name   string
+	Source string // Expression telle que saisie
+
+	eval exprNode
+	refs []string // Variables référencées
+}
+
+// ParseMetric analyse l'expression d'un indicateur
+func ParseMetric(name, source string) (Metric, error) {
+	name = strings.TrimSpace(name)
+	if !IsExprIdent(name) {
+		return Metric{}, InvalidField("metric", name, "nom d'indicateur invalide: '%s'", name)
+	}
+	p := &exprParser{src: source}
+	p.next()
+	node, err := p.parseExpr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("'%s' inattendu", p.tok.text)
+	}
+	if err != nil {
+		return Metric{}, fmt.Errorf("indicateur %s: %w", name, err)
+	}
+	return Metric{Name: name, Source: source, eval: node, refs: p.refs}, nil
+}
+
+// Eval calcule l'indicateur ; les variables absentes de vars sont indéfinies
+func (m Metric) Eval(vars map[string]float64) float64 {
+	if m.eval == nil {
+		return math.NaN()
+	}
+	return m.eval(vars)
+}
+
+func (m Metric) String() string {
+	return m.Name + " = " + m.Source
+}
+
+// ValidateMetrics vérifie qu'une liste d'indicateurs a des noms uniques, distincts des
+// variables, et ne référence que des variables ou des indicateurs définis avant
+func ValidateMetrics(metrics []Metric) error {
+	defined := make(map[string]bool)
+	for _, m := range metrics {
+		if _, builtin := metricVariables[m.Name]; builtin || defined[m.Name] {
+			return InvalidField("metric", m.Name, "indicateur '%s' déjà défini", m.Name)
+		}
+		for _, ref := range m.refs {
+			if _, builtin := metricVariables[ref]; !builtin && !defined[ref] {
+				return InvalidField("metric", m.Name, "indicateur %s: variable inconnue: %s", m.Name, ref)
+			}
+		}
+		defined[m.Name] = true
+	}
+	return nil
+}
+
+// evalMetrics calcule les indicateurs dans l'ordre, chacun pouvant utiliser les
+// précédents ; vars est complété et les résultats définis sont retournés
+func evalMetrics(metrics []Metric, vars map[string]float64) map[string]float64 {
+	if len(metrics) == 0 {
+		return nil
+	}
+	results := make(map[string]float64, len(metrics))
+	for _, m := range metrics {
+		v := m.Eval(vars)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		vars[m.Name] = v
+		results[m.Name] = v
+	}
+	return results
+}
+
+// exprNode est une expression compilée
+type exprNode func(vars map[string]float64) float64
+
+type exprTokenKind int
+
+const (
+	tokEOF exprTokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+	pos  int
+}
+
+// exprParser analyse une expression par descente récursive
+type exprParser struct {
+	src  string
+	pos  int
+	tok  exprToken
+	refs []string
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("position %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next lit le jeton suivant
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = exprToken{kind: tokEOF, pos: start}
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.' || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = exprToken{kind: tokNumber, text: p.src[start:p.pos], pos: start}
+	case isIdentByte(c, false):
+		for p.pos < len(p.src) && isIdentByte(p.src[p.pos], true) {
+			p.pos++
+		}
+		p.tok = exprToken{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	default:
+		p.pos++
+		if p.pos < len(p.src) {
+			next := p.src[p.pos]
+			if next == '=' && strings.IndexByte("<>=!", c) >= 0 || next == c && (c == '&' || c == '|') {
+				p.pos++
+			}
+		}
+		p.tok = exprToken{kind: tokOp, text: p.src[start:p.pos], pos: start}
+	}
+}
+
+// accept consomme l'opérateur s'il est le jeton courant
+func (p *exprParser) accept(op string) bool {
+	if p.tok.kind == tokOp && p.tok.text == op {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// parseExpr lit une disjonction, de priorité la plus faible
+func (p *exprParser) parseExpr() (exprNode, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+// parseAnd lit une conjonction
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseLogical("&&", p.parseComparison)
+}
+
+// parseLogical lit une suite d'opérandes reliés par && ou || ; une opérande indéfinie
+// rend le résultat indéfini
+func (p *exprParser) parseLogical(op string, operand func() (exprNode, error)) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(op) {
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(vars map[string]float64) float64 {
+			a, b := l(vars), right(vars)
+			switch {
+			case math.IsNaN(a) || math.IsNaN(b):
+				return math.NaN()
+			case op == "&&" && a != 0 && b != 0, op == "||" && (a != 0 || b != 0):
+				return 1
+			}
+			return 0
+		}
+	}
+	return left, nil
+}
+
+// parseComparison lit une comparaison
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op := p.tok.text
+	if p.tok.kind != tokOp || !slices.Contains([]string{"<", "<=", ">", ">=", "==", "!="}, op) {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	compare := map[string]func(a, b float64) bool{
+		"<": func(a, b float64) bool { return a < b }, "<=": func(a, b float64) bool { return a <= b },
+		">": func(a, b float64) bool { return a > b }, ">=": func(a, b float64) bool { return a >= b },
+		"==": func(a, b float64) bool { return a == b }, "!=": func(a, b float64) bool { return a != b },
+	}[op]
+	return func(vars map[string]float64) float64 {
+		a, b := left(vars), right(vars)
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return math.NaN()
+		}
+		if compare(a, b) {
+			return 1
+		}
+		return 0
+	}, nil
+}
+
+// parseAdditive lit une somme ou une différence
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("+"):
+			right, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(vars map[string]float64) float64 { return l(vars) + right(vars) }
+		case p.accept("-"):
+			right, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(vars map[string]float64) float64 { return l(vars) - right(vars) }
+		default:
+			return left, nil
+		}
+	}
+}
+
+// parseTerm lit un produit ou un quotient
+func (p *exprParser) parseTerm() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("*"):
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(vars map[string]float64) float64 { return l(vars) * right(vars) }
+		case p.accept("/"):
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(vars map[string]float64) float64 {
+				d := right(vars)
+				if d == 0 {
+					return math.NaN()
+				}
+				return l(vars) / d
+			}
+		default:
+			return left, nil
+		}
+	}
+}
+
+// parseUnary lit un signe moins ou une négation suivis d'une puissance
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) float64 {
+			switch v := operand(vars); {
+			case math.IsNaN(v):
+				return v
+			case v == 0:
+				return 1
+			}
+			return 0
+		}, nil
+	}
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) float64 { return -operand(vars) }, nil
+	}
+	p.accept("+")
+	return p.parsePower()
+}
+
+// parsePower lit une puissance, associative à droite
+func (p *exprParser) parsePower() (exprNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("^") {
+		return base, nil
+	}
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return func(vars map[string]float64) float64 { return math.Pow(base(vars), exponent(vars)) }, nil
+}
+
+// parsePrimary lit un nombre, une variable, un appel de fonction ou une parenthèse
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(strings.ReplaceAll(tok.text, "_", ""), 64)
+		if err != nil {
+			return nil, p.errorf("nombre invalide: %s", tok.text)
+		}
+		p.next()
+		return func(map[string]float64) float64 { return v }, nil
+	case tokIdent:
+		p.next()
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		if !slices.Contains(p.refs, tok.text) {
+			p.refs = append(p.refs, tok.text)
+		}
+		name := tok.text
+		return func(vars map[string]float64) float64 {
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			return math.NaN()
+		}, nil
+	case tokOp:
+		if p.accept("(") {
+			node, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, p.errorf("')' attendue")
+			}
+			return node, nil
+		}
+		return nil, p.errorf("'%s' inattendu", tok.text)
+	}
+	return nil, p.errorf("expression incomplète")
+}
+
+// parseCall lit les arguments d'un appel de fonction, la parenthèse ouvrante consommée
+func (p *exprParser) parseCall(fn exprToken) (exprNode, error) {
+	arity, known := exprFunctions[fn.text]
+	if !known {
+		return nil, fmt.Errorf("position %d: fonction inconnue: %s", fn.pos+1, fn.text)
+	}
+	var args []exprNode
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				return nil, p.errorf("',' ou ')' attendue")
+			}
+		}
+	}
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return nil, fmt.Errorf("position %d: %s: nombre d'arguments invalide (%d)", fn.pos+1, fn.text, len(args))
+	}
+
+	unary := func(f func(float64) float64) exprNode {
+		return func(vars map[string]float64) float64 { return f(args[0](vars)) }
+	}
+	switch fn.text {
+	case "abs":
+		return unary(math.Abs), nil
+	case "sqrt":
+		return unary(math.Sqrt), nil
+	case "log":
+		return unary(math.Log), nil
+	case "exp":
+		return unary(math.Exp), nil
+	case "round":
+		return func(vars map[string]float64) float64 {
+			scale := 1.0
+			if len(args) == 2 {
+				scale = math.Pow(10, math.Round(args[1](vars)))
+			}
+			return math.Round(args[0](vars)*scale) / scale
+		}, nil
+	case "min", "max":
+		pick := math.Min
+		if fn.text == "max" {
+			pick = math.Max
+		}
+		return func(vars map[string]float64) float64 {
+			result := args[0](vars)
+			for _, arg := range args[1:] {
+				result = pick(result, arg(vars))
+			}
+			return result
+		}, nil
+	case "if":
+		return func(vars map[string]float64) float64 {
+			cond := args[0](vars)
+			switch {
+			case math.IsNaN(cond):
+				return math.NaN()
+			case cond != 0:
+				return args[1](vars)
+			default:
+				return args[2](vars)
+			}
+		}, nil
+	default: // coalesce
+		return func(vars map[string]float64) float64 {
+			for _, arg := range args {
+				if v := arg(vars); !math.IsNaN(v) {
+					return v
+				}
+			}
+			return math.NaN()
+		}, nil
+	}
+}
+
+// isIdentByte indique si c peut figurer dans un nom de variable (lettre ASCII ou « _ »,
+// chiffre sauf en tête)
+func isIdentByte(c byte, digits bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || digits && c >= '0' && c <= '9'
+}
+
+// IsExprIdent indique si s peut nommer une variable d'expression
+func IsExprIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := range len(s) {
+		if !isIdentByte(s[i], i > 0) {
+			return false
+		}
+	}
+	_, isFunc := exprFunctions[s]
+	return !isFunc
+}
+
+// FormatMetric formate la valeur d'un indicateur à quatre décimales au plus, "-" si elle
+// est indéfinie
+func FormatMetric(v float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
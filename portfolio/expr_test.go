@@ -0,0 +1,174 @@
+package portfolio
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMetricEval(t *testing.T) {
+	vars := map[string]float64{"value": 1200, "invested": 1000, "return": 0.05, "ter": 0.002, "units": 0}
+	nan := math.NaN()
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{source: "value - invested", want: 200},
+		{source: "(value - invested) / invested * 100", want: 20},
+		{source: "1 + 2 * 3", want: 7},
+		{source: "(1 + 2) * 3", want: 9},
+		{source: "2 ^ 3 ^ 2", want: 512},
+		{source: "-2 ^ 2", want: -4},
+		{source: "+value", want: 1200},
+		{source: "1_000 + 0.5", want: 1000.5},
+		{source: "value > invested", want: 1},
+		{source: "value <= invested", want: 0},
+		{source: "value == 1200 && return != 0", want: 1},
+		{source: "units > 0 || ter < 0.01", want: 1},
+		{source: "!units", want: 1},
+		{source: "!(value > 0)", want: 0},
+		{source: "abs(invested - value)", want: 200},
+		{source: "sqrt(16) + log(exp(2))", want: 6},
+		{source: "round(2 / 3, 2)", want: 0.67},
+		{source: "round(2.5)", want: 3},
+		{source: "min(3, 1, 2) + max(3, 1, 2)", want: 4},
+		{source: "if(return > 0.04, 1, 2)", want: 1},
+		{source: "if(units, 1 / units, 0)", want: 0},
+		{source: "coalesce(drawdown, units / units, 7)", want: 7},
+		{source: "drawdown", want: nan},
+		{source: "value / units", want: nan},
+		{source: "drawdown > 0", want: nan},
+		{source: "if(drawdown, 1, 2)", want: nan},
+		{source: "drawdown && 1", want: nan},
+		{source: "!drawdown", want: nan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			m, err := ParseMetric("m", tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := m.Eval(vars)
+			if math.IsNaN(tt.want) {
+				if !math.IsNaN(got) {
+					t.Errorf("%s = %v, indéfini attendu", tt.source, got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%s = %v, %v attendu", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMetricInvalid(t *testing.T) {
+	tests := []struct {
+		name, source string
+		want         string // Extrait du message d'erreur
+	}{
+		{name: "gain", source: "", want: "expression incomplète"},
+		{name: "gain", source: "value -", want: "expression incomplète"},
+		{name: "gain", source: "(value", want: "')' attendue"},
+		{name: "gain", source: "value invested", want: "position 7: 'invested' inattendu"},
+		{name: "gain", source: "value $ 2", want: "'$' inattendu"},
+		{name: "gain", source: "1.2.3", want: "nombre invalide"},
+		{name: "gain", source: "median(value)", want: "fonction inconnue: median"},
+		{name: "gain", source: "if(value, 1)", want: "nombre d'arguments invalide (2)"},
+		{name: "gain", source: "abs()", want: "nombre d'arguments invalide (0)"},
+		{name: "gain", source: "max(1 2)", want: "',' ou ')' attendue"},
+		{name: "", source: "1", want: "nom d'indicateur invalide"},
+		{name: "2x", source: "1", want: "nom d'indicateur invalide"},
+		{name: "abs", source: "1", want: "nom d'indicateur invalide"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" = "+tt.source, func(t *testing.T) {
+			_, err := ParseMetric(tt.name, tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("erreur %v, %q attendu", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMetrics(t *testing.T) {
+	parse := func(defs ...string) []Metric {
+		var metrics []Metric
+		for _, def := range defs {
+			name, source, _ := strings.Cut(def, "=")
+			m, err := ParseMetric(name, source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			metrics = append(metrics, m)
+		}
+		return metrics
+	}
+	tests := []struct {
+		name    string
+		metrics []Metric
+		wantErr string
+	}{
+		{name: "aucun"},
+		{name: "indicateurs chaînés", metrics: parse("gain=value - invested", "gain_pct=gain / invested")},
+		{name: "ordre inversé", metrics: parse("gain_pct=gain / invested", "gain=value - invested"), wantErr: "variable inconnue: gain"},
+		{name: "variable inconnue", metrics: parse("x=valeur * 2"), wantErr: "variable inconnue: valeur"},
+		{name: "doublon", metrics: parse("x=1", "x=2"), wantErr: "'x' déjà défini"},
+		{name: "nom réservé", metrics: parse("value=1"), wantErr: "'value' déjà défini"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetrics(tt.metrics)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("erreur %v, %q attendu", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Les indicateurs sont calculés dans l'ordre, un résultat indéfini n'étant pas retenu
+	vars := map[string]float64{"value": 1200, "invested": 1000}
+	results := evalMetrics(parse("gain=value - invested", "gain_pct=gain / invested * 100", "ratio=gain / units"), vars)
+	if len(results) != 2 || results["gain"] != 200 || results["gain_pct"] != 20 {
+		t.Errorf("résultats %v, gain 200 et gain_pct 20 attendus", results)
+	}
+	if vars["gain_pct"] != 20 {
+		t.Error("les résultats devraient compléter les variables")
+	}
+}
+
+func TestFormatMetric(t *testing.T) {
+	tests := []struct {
+		v    float64
+		ok   bool
+		want string
+	}{
+		{v: 0, ok: false, want: "-"},
+		{v: 20, ok: true, want: "20"},
+		{v: 2.0 / 3, ok: true, want: "0.6667"},
+	}
+	for _, tt := range tests {
+		if got := FormatMetric(tt.v, tt.ok); got != tt.want {
+			t.Errorf("FormatMetric(%v, %v) = %q, %q attendu", tt.v, tt.ok, got, tt.want)
+		}
+	}
+}
+
+func FuzzParseMetric(f *testing.F) {
+	for _, seed := range []string{
+		"value - invested", "if(return > 0.04, round(value, 2), coalesce(drawdown, 0))",
+		"!(a && b) || -c ^ 2 <= 3", "((((", "max(,)", "1_0.5e", "a==b!=c",
+	} {
+		f.Add(seed)
+	}
+	vars := map[string]float64{"value": 1200, "invested": 1000, "a": 1, "b": 0, "c": 2}
+	f.Fuzz(func(t *testing.T, source string) {
+		m, err := ParseMetric("m", source)
+		if err != nil {
+			return
+		}
+		m.Eval(vars)
+		if m.Source != source {
+			t.Errorf("source %q conservée en %q", source, m.Source)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// FeeSchedule décrit les frais d'un investissement. Les taux de projection (taux de
+// référence ou taux calculé) sont considérés bruts : les frais courants en sont déduits
+// par ProjectNAV, les frais d'entrée s'appliquent aux versements projetés et les frais
+// de sortie à la valeur de rachat calculée par FeeImpactReport.
+type FeeSchedule struct {
+	TER        float64 `json:"ter,omitempty"`         // Frais courants annuels (% de l'encours)
+	CustodyFee Money   `json:"custody_fee,omitempty"` // Droits de garde fixes annuels
+	EntryFee   float64 `json:"entry_fee,omitempty"`   // Frais d'entrée (% de chaque versement)
+	ExitFee    float64 `json:"exit_fee,omitempty"`    // Frais de sortie (% de la valeur rachetée)
+}
+
+// FeeImpact détaille l'effet des frais sur la valeur projetée d'un investissement
+type FeeImpact struct {
+	Name             string  `json:"name"`
+	GrossValue       float64 `json:"gross_value"`       // Valeur projetée sans aucun frais
+	NetValue         float64 `json:"net_value"`         // Valeur projetée après frais courants, droits de garde et frais d'entrée
+	OngoingFees      float64 `json:"ongoing_fees"`      // Frais courants, droits de garde et frais d'entrée des versements programmés
+	ExitFees         float64 `json:"exit_fees"`         // Frais de sortie en cas de rachat à l'horizon
+	LiquidationValue float64 `json:"liquidation_value"` // Valeur nette après frais de sortie
+	Drag             float64 `json:"drag"`              // Part de la valeur brute absorbée par les frais (%)
+}
+
+// FeeImpactReport regroupe l'effet des frais par investissement et pour le portefeuille
+type FeeImpactReport struct {
+	Date        time.Time   `json:"-"`
+	Investments []FeeImpact `json:"investments"` // Triés par nom
+	Total       FeeImpact   `json:"total"`       // Somme en devise de consolidation
+}
+
+// SetFeeSchedule définit les frais d'un investissement ; nil supprime les frais
+func (p *Portfolio) SetFeeSchedule(investmentName string, fees *FeeSchedule) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if fees != nil {
+		if fees.TER < 0 || fees.TER >= 100 || fees.EntryFee < 0 || fees.EntryFee >= 100 ||
+			fees.ExitFee < 0 || fees.ExitFee >= 100 || fees.CustodyFee < 0 {
+			return fmt.Errorf("les frais doivent être compris entre 0 et 100%%: %w", ErrInvalidAmount)
+		}
+		copied := *fees
+		fees = &copied
+	}
+
+	before := inv.clone()
+	inv.Fees = fees
+	detail := "aucuns frais"
+	if fees != nil {
+		detail = fmt.Sprintf("TER %.2f%%, entrée %.2f%%, sortie %.2f%%", fees.TER, fees.EntryFee, fees.ExitFee)
+	}
+	p.record(OpSetFeeSchedule, investmentName, detail, before)
+	return nil
+}
+
+// grow capitalise value sur years années au taux annuel rate (%), déduction faite des
+// frais courants. Les frais sont prélevés en continu : dV/dt = (ln g + ln(1-TER))·V - garde,
+// g étant la croissance annuelle au taux rate selon la capitalisation des conventions c.
+func (f *FeeSchedule) grow(c analytics.RateConventions, value, years, rate float64) float64 {
+	if f == nil {
+		return value * c.GrowthFactor(rate, years)
+	}
+
+	logGrowth := c.RateLog(rate) + math.Log(1-(f.TER/100))
+	growth := math.Exp(logGrowth * years)
+	custody := f.CustodyFee.Float64()
+	if logGrowth == 0 {
+		return value - custody*years
+	}
+	return value*growth - custody*(growth-1)/logGrowth
+}
+
+// netContribution retourne la part d'un versement effectivement investie après frais d'entrée
+func (f *FeeSchedule) netContribution(amount float64) float64 {
+	if f == nil {
+		return amount
+	}
+	return amount * (1 - f.EntryFee/100)
+}
+
+// exitFee retourne les frais de sortie prélevés sur le rachat de value
+func (f *FeeSchedule) exitFee(value float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return value * f.ExitFee / 100
+}
+
+// feeImpactAt calcule l'effet des frais sur la projection de l'investissement à une date
+func (inv *Investment) feeImpactAt(date time.Time) (FeeImpact, error) {
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return FeeImpact{}, err
+	}
+	rate, err := inv.EffectiveRate()
+	if err != nil {
+		return FeeImpact{}, err
+	}
+	years := inv.conventions.YearsBetween(latestNAV.Date.Time, date)
+	if years < 0 {
+		return FeeImpact{}, fmt.Errorf("la date de projection doit être après la dernière NAV")
+	}
+
+	contributions := inv.PlannedContributions(latestNAV.Date.Time, date)
+	impact := FeeImpact{
+		Name:       inv.Name,
+		GrossValue: compound(inv.conventions, nil, latestNAV, date, rate, contributions),
+		NetValue:   compound(inv.conventions, inv.Fees, latestNAV, date, rate, contributions),
+	}
+	impact.OngoingFees = impact.GrossValue - impact.NetValue
+	impact.ExitFees = inv.Fees.exitFee(impact.NetValue)
+	impact.LiquidationValue = impact.NetValue - impact.ExitFees
+	return impact, nil
+}
+
+// FeeImpactReport mesure, pour chaque investissement ouvert, l'écart cumulé entre la
+// valeur projetée à une date sans frais et la valeur nette de tous les frais
+func (p *Portfolio) FeeImpactReport(date string) (*FeeImpactReport, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	report := &FeeImpactReport{Date: t, Total: FeeImpact{Name: "Total"}}
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		impact, err := inv.feeImpactAt(t)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		impact.Name = name
+		impact.Drag = dragPercent(impact)
+		report.Investments = append(report.Investments, impact)
+
+		// Totaux dans la devise de consolidation
+		for _, pair := range []struct{ src, dst *float64 }{
+			{&impact.GrossValue, &report.Total.GrossValue},
+			{&impact.NetValue, &report.Total.NetValue},
+			{&impact.OngoingFees, &report.Total.OngoingFees},
+			{&impact.ExitFees, &report.Total.ExitFees},
+			{&impact.LiquidationValue, &report.Total.LiquidationValue},
+		} {
+			converted, err := p.toBase(*pair.src, inv.Currency, t)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			*pair.dst += converted
+		}
+	}
+	report.Total.Drag = dragPercent(report.Total)
+
+	sort.Slice(report.Investments, func(i, j int) bool {
+		return report.Investments[i].Name < report.Investments[j].Name
+	})
+	return report, nil
+}
+
+// dragPercent exprime l'ensemble des frais en pourcentage de la valeur brute
+func dragPercent(impact FeeImpact) float64 {
+	if impact.GrossValue == 0 {
+		return 0
+	}
+	return (impact.GrossValue - impact.LiquidationValue) / impact.GrossValue * 100
+}
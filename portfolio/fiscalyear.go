@@ -0,0 +1,85 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// YearStart est le premier jour de l'année de référence des rapports : le 1er janvier
+// pour l'année civile (valeur zéro), le 6 avril pour l'année fiscale britannique, ou le
+// début de l'exercice d'un employeur. Une année de référence porte le millésime de
+// l'année où elle commence.
+type YearStart struct {
+	Month time.Month
+	Day   int
+}
+
+// ParseYearStart lit un début d'année au format MM-JJ ("04-06"), vide pour l'année civile
+func ParseYearStart(s string) (YearStart, error) {
+	if s == "" {
+		return YearStart{}, nil
+	}
+	t, err := time.Parse("01-02", s)
+	if err != nil || t.Day() > 28 {
+		return YearStart{}, InvalidField("year_start", s, "début d'année invalide: %s (MM-JJ, jour 28 au plus)", s)
+	}
+	return YearStart{Month: t.Month(), Day: t.Day()}, nil
+}
+
+// IsCalendar indique si l'année de référence est l'année civile
+func (y YearStart) IsCalendar() bool {
+	return y == YearStart{} || y == YearStart{Month: time.January, Day: 1}
+}
+
+// Start retourne le premier jour de l'année de référence year
+func (y YearStart) Start(year int) time.Time {
+	if y.IsCalendar() {
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, y.Month, y.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// YearOf retourne l'année de référence qui contient t
+func (y YearStart) YearOf(t time.Time) int {
+	if t.Before(y.Start(t.Year())) {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// Label retourne le libellé d'une année de référence : "2024" pour l'année civile,
+// "2024-25" sinon
+func (y YearStart) Label(year int) string {
+	if y.IsCalendar() {
+		return fmt.Sprint(year)
+	}
+	return fmt.Sprintf("%d-%02d", year, (year+1)%100)
+}
+
+func (y YearStart) String() string {
+	if y.IsCalendar() {
+		return ""
+	}
+	return fmt.Sprintf("%02d-%02d", int(y.Month), y.Day)
+}
+
+// MarshalText conserve le format MM-JJ
+func (y YearStart) MarshalText() ([]byte, error) {
+	return []byte(y.String()), nil
+}
+
+// UnmarshalText lit un début d'année au format MM-JJ
+func (y *YearStart) UnmarshalText(data []byte) error {
+	parsed, err := ParseYearStart(string(data))
+	if err != nil {
+		return err
+	}
+	*y = parsed
+	return nil
+}
+
+// ReportingYear retourne le début d'année de référence de la configuration, l'année
+// civile sinon
+func ReportingYear() YearStart {
+	return ActiveConfig().YearStart
+}
@@ -0,0 +1,239 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ForecastStore conserve les projections enregistrées, confrontées aux valeurs réalisées
+// une fois leur date passée (voir ForecastAccuracy)
+type ForecastStore struct {
+	Record    bool       `json:"record,omitempty"` // Enregistrer chaque projection de la commande project
+	Forecasts []Forecast `json:"forecasts,omitempty"`
+}
+
+// Forecast est une projection enregistrée : ses entrées et la valeur projetée, dans la
+// devise de l'investissement
+type Forecast struct {
+	Investment    string
+	Recorded      Date    // Jour de la projection
+	Target        Date    // Date projetée
+	Start         NAV     // Dernière NAV, point de départ de la projection
+	Rate          float64 // Taux annuel retenu (%)
+	Model         string  // Modèle de projection
+	Contributions Money   // Versements programmés inclus d'ici la date projetée
+	Projected     Money   // Valeur projetée
+}
+
+// forecastJSON est la forme sérialisée d'une projection enregistrée
+type forecastJSON struct {
+	Investment    string  `json:"investment"`
+	Recorded      Date    `json:"recorded"`
+	Target        Date    `json:"target"`
+	StartDate     Date    `json:"start_date"`
+	StartValue    Money   `json:"start_value"`
+	Rate          float64 `json:"rate"`
+	Model         string  `json:"model"`
+	Contributions Money   `json:"contributions,omitempty"`
+	Projected     Money   `json:"projected"`
+}
+
+// MarshalJSON aplatit la NAV de départ en start_date et start_value
+func (f Forecast) MarshalJSON() ([]byte, error) {
+	return json.Marshal(forecastJSON{
+		Investment: f.Investment, Recorded: f.Recorded, Target: f.Target,
+		StartDate: f.Start.Date, StartValue: f.Start.Value, Rate: f.Rate, Model: f.Model,
+		Contributions: f.Contributions, Projected: f.Projected,
+	})
+}
+
+// UnmarshalJSON lit une projection enregistrée
+func (f *Forecast) UnmarshalJSON(data []byte) error {
+	var raw forecastJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = Forecast{
+		Investment: raw.Investment, Recorded: raw.Recorded, Target: raw.Target, Start: NAV{Date: raw.StartDate, Value: raw.StartValue},
+		Rate: raw.Rate, Model: raw.Model, Contributions: raw.Contributions, Projected: raw.Projected,
+	}
+	return nil
+}
+
+// forecast projette l'investissement à une date en conservant les entrées de la projection
+func (inv *Investment) forecast(target time.Time) (Forecast, error) {
+	latest, err := inv.GetLatestNAV()
+	if err != nil {
+		return Forecast{}, err
+	}
+	rate, err := inv.ProjectionRate(nil)
+	if err != nil {
+		return Forecast{}, err
+	}
+	value, err := inv.projectNAVAtRate(target, rate)
+	if err != nil {
+		return Forecast{}, err
+	}
+	f := Forecast{
+		Investment: inv.Name,
+		Recorded:   Date{Today()},
+		Target:     Date{target},
+		Start:      latest,
+		Rate:       inv.navRate(rate),
+		Model:      ProjectorCompound,
+		Projected:  NewMoney(value).RoundCents(),
+	}
+	if inv.Projection != nil {
+		f.Model = inv.Projection.Model
+	}
+	for _, c := range inv.PlannedContributions(latest.Date.Time, target) {
+		f.Contributions += c.Amount
+	}
+	return f, nil
+}
+
+// RecordForecasts enregistre la projection de chaque investissement ouvert à une date
+// postérieure à sa dernière NAV ; une projection du même jour vers la même date est
+// remplacée. Retourne les projections enregistrées.
+func (p *Portfolio) RecordForecasts(date string) ([]Forecast, error) {
+	target, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var recorded []Forecast
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed || len(inv.NAVHistory) == 0 || !target.After(inv.NAVHistory[len(inv.NAVHistory)-1].Date.Time) {
+			continue
+		}
+		f, err := inv.forecast(target)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		recorded = append(recorded, f)
+	}
+	if len(recorded) == 0 {
+		return nil, nil
+	}
+
+	if p.Forecasts == nil {
+		p.Forecasts = &ForecastStore{}
+	}
+	kept := p.Forecasts.Forecasts[:0]
+	for _, f := range p.Forecasts.Forecasts {
+		replaced := false
+		for _, r := range recorded {
+			replaced = replaced || (f.Investment == r.Investment && f.Recorded.Equal(r.Recorded.Time) && f.Target.Equal(r.Target.Time))
+		}
+		if !replaced {
+			kept = append(kept, f)
+		}
+	}
+	p.Forecasts.Forecasts = append(kept, recorded...)
+	p.changed("record-forecasts", "")
+	return recorded, nil
+}
+
+// SetForecastRecording active ou désactive l'enregistrement des projections de la
+// commande project
+func (p *Portfolio) SetForecastRecording(on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Forecasts == nil {
+		p.Forecasts = &ForecastStore{}
+	}
+	// Un booléen est toujours sérialisable
+	before, _ := p.settingsState("forecast_recording")
+	p.Forecasts.Record = on
+	_ = p.recordChange(JournalEntry{Op: OpSetForecastRecording, Detail: fmt.Sprintf("%t", on), BeforeSettings: before})
+}
+
+// RecordsForecasts indique si les projections de la commande project sont enregistrées
+func (p *Portfolio) RecordsForecasts() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.Forecasts != nil && p.Forecasts.Record
+}
+
+// ForecastOutcome confronte une projection enregistrée à la valeur réalisée
+type ForecastOutcome struct {
+	Forecast
+	Realized float64 // Valeur à la date projetée, interpolée entre les NAV
+	Error    float64 // Écart relatif de la projection à la valeur réalisée (%), positif si surestimée
+}
+
+// ForecastAccuracy est la précision des projections d'un investissement
+type ForecastAccuracy struct {
+	Investment string
+	Evaluated  int               // Projections dont la date est couverte par les NAV
+	Pending    int               // Projections dont la date n'est pas encore couverte
+	Bias       float64           // Écart relatif moyen (%) : positif si les projections surestiment
+	MAPE       float64           // Écart relatif absolu moyen (%)
+	Worst      *ForecastOutcome  // Projection au plus grand écart absolu
+	Outcomes   []ForecastOutcome // Projections évaluées, par date projetée
+}
+
+// ForecastAccuracy confronte les projections enregistrées aux valeurs réalisées. Une
+// projection est évaluée dès qu'une NAV est datée de sa date projetée ou après ; la
+// valeur réalisée comprend les apports et retraits effectifs, là où la projection ne
+// comptait que les versements programmés.
+func (p *Portfolio) ForecastAccuracy() []ForecastAccuracy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.Forecasts == nil {
+		return nil
+	}
+	byName := make(map[string]*ForecastAccuracy)
+	for _, f := range p.Forecasts.Forecasts {
+		a, exists := byName[f.Investment]
+		if !exists {
+			a = &ForecastAccuracy{Investment: f.Investment}
+			byName[f.Investment] = a
+		}
+		inv, exists := p.Investments[f.Investment]
+		if !exists || len(inv.NAVHistory) == 0 || inv.NAVHistory[len(inv.NAVHistory)-1].Date.Before(f.Target.Time) {
+			a.Pending++
+			continue
+		}
+		realized, held := inv.historicalValue(f.Target.Time)
+		if !held || realized <= 0 {
+			a.Pending++
+			continue
+		}
+		a.Outcomes = append(a.Outcomes, ForecastOutcome{
+			Forecast: f,
+			Realized: realized,
+			Error:    (f.Projected.Float64() - realized) / realized * 100,
+		})
+	}
+
+	report := make([]ForecastAccuracy, 0, len(byName))
+	for _, a := range byName {
+		sort.SliceStable(a.Outcomes, func(i, j int) bool { return a.Outcomes[i].Target.Before(a.Outcomes[j].Target.Time) })
+		a.Evaluated = len(a.Outcomes)
+		for i, o := range a.Outcomes {
+			a.Bias += o.Error
+			a.MAPE += math.Abs(o.Error)
+			if a.Worst == nil || math.Abs(o.Error) > math.Abs(a.Worst.Error) {
+				a.Worst = &a.Outcomes[i]
+			}
+		}
+		if a.Evaluated > 0 {
+			a.Bias /= float64(a.Evaluated)
+			a.MAPE /= float64(a.Evaluated)
+		}
+		report = append(report, *a)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Investment < report[j].Investment })
+	return report
+}
@@ -0,0 +1,98 @@
+package portfolio
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// currencyFormat décrit l'affichage d'une devise : symbole et nombre de décimales
+type currencyFormat struct {
+	Symbol     string
+	MinorUnits int
+}
+
+// currencyFormats sont les devises connues ; les autres s'affichent avec leur code ISO et deux décimales
+var currencyFormats = map[Currency]currencyFormat{
+	EUR:   {"€", 2},
+	USD:   {"$", 2},
+	CHF:   {"CHF", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+}
+
+// numberFormat décrit les conventions d'écriture des montants d'une langue
+type numberFormat struct {
+	Decimal     string // Séparateur décimal
+	Group       string // Séparateur des milliers
+	SymbolFirst bool   // Symbole avant le nombre
+}
+
+// numberFormats sont les conventions de chaque langue ; une langue absente suit DefaultLocale
+var numberFormats = map[Locale]numberFormat{
+	LocaleFR: {Decimal: ",", Group: "\u202f"}, // Espace fine insécable, comme en typographie française
+	LocaleEN: {Decimal: ".", Group: ",", SymbolFirst: true},
+}
+
+// AmountFormatter formate des montants dans une devise selon les conventions d'une langue
+type AmountFormatter struct {
+	Currency Currency
+	Locale   Locale
+}
+
+// Format rend un montant avec séparateur des milliers, décimales de la devise et symbole,
+// par exemple "1 234,56 €" en français et "€1,234.56" en anglais
+func (f AmountFormatter) Format(amount float64) string {
+	return FormatAmount(amount, f.Currency, f.Locale)
+}
+
+// FormatAmount rend un montant dans une devise selon les conventions d'une langue
+func FormatAmount(amount float64, currency Currency, locale Locale) string {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	cf, ok := currencyFormats[currency]
+	if !ok {
+		cf = currencyFormat{Symbol: string(currency), MinorUnits: 2}
+	}
+	nf, ok := numberFormats[locale]
+	if !ok {
+		nf = numberFormats[DefaultLocale]
+	}
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', cf.MinorUnits, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+	var b strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(nf.Group)
+		}
+		b.WriteRune(r)
+	}
+	number := b.String()
+	if fraction != "" {
+		number += nf.Decimal + fraction
+	}
+
+	sign := ""
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+	if nf.SymbolFirst {
+		// Un code de plusieurs lettres est séparé du nombre, un symbole lui est accolé
+		if len([]rune(cf.Symbol)) > 1 {
+			return sign + cf.Symbol + "\u00a0" + number
+		}
+		return sign + cf.Symbol + number
+	}
+	return sign + number + "\u00a0" + cf.Symbol
+}
+
+// AmountFormatter retourne le formateur des montants consolidés du portefeuille :
+// devise de consolidation et langue du portefeuille
+func (p *Portfolio) AmountFormatter() AmountFormatter {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return AmountFormatter{Currency: p.baseCurrency(), Locale: p.locale()}
+}
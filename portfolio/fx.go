@@ -0,0 +1,219 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Currency est un code devise ISO 4217
+type Currency string
+
+const (
+	EUR Currency = "EUR"
+	USD Currency = "USD"
+	CHF Currency = "CHF"
+)
+
+// DefaultCurrency est la devise implicite des montants lorsqu'aucune n'est précisée
+const DefaultCurrency = EUR
+
+// Rates fournit des taux de change historiques
+type Rates interface {
+	// Rate retourne le nombre d'unités de to pour une unité de from à la date donnée
+	Rate(from, to Currency, date time.Time) (float64, error)
+}
+
+// FXRate est un taux de change observé à une date
+type FXRate struct {
+	Date time.Time // Date d'observation
+	Rate float64   // Unités de la devise cotée pour une unité de la devise de base
+}
+
+type currencyPair struct {
+	from, to Currency
+}
+
+// RateTable est une implémentation en mémoire de Rates. Pour une date donnée, elle
+// retient le dernier taux connu à cette date (ou avant), ce qui permet de valoriser
+// le passé avec les taux de l'époque et de projeter avec le dernier taux connu.
+// La paire inverse est déduite automatiquement.
+type RateTable struct {
+	rates map[currencyPair][]FXRate
+}
+
+// NewRateTable crée une table de taux vide
+func NewRateTable() *RateTable {
+	return &RateTable{rates: make(map[currencyPair][]FXRate)}
+}
+
+// AddRate enregistre le taux from→to observé à une date
+func (t *RateTable) AddRate(from, to Currency, date string, rate float64) error {
+	if rate <= 0 {
+		return fmt.Errorf("le taux de change doit être positif: %w", ErrInvalidAmount)
+	}
+	d, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+
+	pair := currencyPair{from, to}
+	history := append(t.rates[pair], FXRate{Date: d, Rate: rate})
+
+	// Trier par date
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	t.rates[pair] = history
+
+	return nil
+}
+
+// Rate retourne le dernier taux from→to connu à la date donnée
+func (t *RateTable) Rate(from, to Currency, date time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if rate, ok := lastRateAt(t.rates[currencyPair{from, to}], date); ok {
+		return rate, nil
+	}
+	if rate, ok := lastRateAt(t.rates[currencyPair{to, from}], date); ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("aucun taux %s/%s au %s: %w", from, to, FormatDate(date), ErrRateNotFound)
+}
+
+// LoadRateTable lit une table de taux d'un fichier CSV de lignes date,from,to,rate
+// (2024-01-31,USD,EUR,0.92), avec ou sans ligne d'en-tête
+func LoadRateTable(path string) (*RateTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 4
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t := NewRateTable()
+	for i, record := range records {
+		if i == 0 && record[0] == "date" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s ligne %d: taux invalide: %s", path, i+1, record[3])
+		}
+		from, to := Currency(strings.ToUpper(record[1])), Currency(strings.ToUpper(record[2]))
+		if err := t.AddRate(from, to, record[0], rate); err != nil {
+			return nil, fmt.Errorf("%s ligne %d: %w", path, i+1, err)
+		}
+	}
+	return t, nil
+}
+
+// lastRateAt retourne le dernier taux daté au plus tard à date dans un historique trié
+func lastRateAt(history []FXRate, date time.Time) (float64, bool) {
+	i := sort.Search(len(history), func(i int) bool { return history[i].Date.After(date) })
+	if i == 0 {
+		return 0, false
+	}
+	return history[i-1].Rate, true
+}
+
+// EffectiveCurrency retourne la devise de l'investissement, DefaultCurrency si elle n'est pas renseignée
+func (inv *Investment) EffectiveCurrency() Currency {
+	if inv.Currency == "" {
+		return DefaultCurrency
+	}
+	return inv.Currency
+}
+
+// SetInvestmentCurrency définit la devise d'un investissement (DefaultCurrency si vide),
+// qui doit être convertible dans la devise de consolidation (voir CheckCurrency)
+func (p *Portfolio) SetInvestmentCurrency(name string, currency Currency) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	if err := p.checkCurrency(currency); err != nil {
+		return err
+	}
+	before := inv.clone()
+	inv.Currency = currency
+	p.record(OpSetCurrency, name, string(currency), before)
+	p.valueChanged(name)
+	return nil
+}
+
+// CheckCurrency vérifie qu'une devise d'investissement est un code de trois lettres
+// majuscules que le portefeuille sait convertir : la devise de consolidation,
+// DefaultCurrency ou une devise de la table des taux. Une devise vide désigne
+// DefaultCurrency.
+func (p *Portfolio) CheckCurrency(currency Currency) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.checkCurrency(currency)
+}
+
+// checkCurrency vérifie une devise (voir CheckCurrency) ; l'appelant doit détenir p.mu
+func (p *Portfolio) checkCurrency(currency Currency) error {
+	if currency == "" || currency == DefaultCurrency || currency == p.baseCurrency() {
+		return nil
+	}
+	if len(currency) != 3 || strings.Trim(string(currency), "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return InvalidField("currency", currency, "devise invalide: %q (code ISO à trois lettres majuscules, ex. USD)", currency)
+	}
+	if _, err := p.toBase(1, currency, Today()); err != nil {
+		return InvalidField("currency", currency, "devise inconnue: %s (aucun taux vers %s dans la table des taux)", currency, p.baseCurrency())
+	}
+	return nil
+}
+
+// baseCurrency retourne la devise de consolidation du portefeuille
+func (p *Portfolio) baseCurrency() Currency {
+	if p.BaseCurrency == "" {
+		return DefaultCurrency
+	}
+	return p.BaseCurrency
+}
+
+// ConsolidationCurrency retourne la devise de consolidation du portefeuille,
+// DefaultCurrency si elle n'est pas renseignée
+func (p *Portfolio) ConsolidationCurrency() Currency {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.baseCurrency()
+}
+
+// toBase convertit un montant exprimé en devise currency dans la devise de consolidation
+func (p *Portfolio) toBase(amount float64, currency Currency, date time.Time) (float64, error) {
+	base := p.baseCurrency()
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if currency == base {
+		return amount, nil
+	}
+	if p.Rates == nil {
+		return 0, fmt.Errorf("aucune source de taux pour convertir %s en %s: %w", currency, base, ErrRateNotFound)
+	}
+
+	rate, err := p.Rates.Rate(currency, base, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// CurrencyReturn compare le rendement d'un investissement en devise étrangère dans sa
+// devise et dans la devise de consolidation. Les rendements sont en pourcentage, sur la
+// période ou annualisés.
+type CurrencyReturn struct {
+	Investment string
+	Currency   Currency
+	From, To   time.Time
+	Local      float64 // Rendement corrigé des flux en devise de l'investissement
+	Base       float64 // Même rendement en devise de consolidation, flux convertis au taux de leur date
+	FXChange   float64 // Variation du cours de la devise contre la devise de consolidation
+	FXEffect   float64 // Base - Local : part du rendement due au change, effet des flux compris
+}
+
+// CurrencyReturns décompose le rendement des investissements ouverts libellés dans une
+// autre devise que celle de consolidation : rendement local, rendement converti, et
+// l'écart entre les deux qui revient au change. Les périodes se résolvent comme pour
+// Investment.Return ; un investissement sans NAV ou non détenu sur la période est omis.
+func (p *Portfolio) CurrencyReturns(from, to string, annualize bool) ([]CurrencyReturn, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var returns []CurrencyReturn
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed || inv.EffectiveCurrency() == p.baseCurrency() || len(inv.NAVHistory) == 0 {
+			continue
+		}
+		r, err := p.currencyReturn(inv, from, to, annualize)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		returns = append(returns, r)
+	}
+	return returns, nil
+}
+
+// CurrencyReturn calcule la décomposition d'un investissement comme CurrencyReturns
+func (p *Portfolio) CurrencyReturn(name, from, to string, annualize bool) (CurrencyReturn, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return CurrencyReturn{}, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	return p.currencyReturn(inv, from, to, annualize)
+}
+
+// currencyReturn calcule la décomposition d'un investissement ; l'appelant doit détenir
+// p.mu
+func (p *Portfolio) currencyReturn(inv *Investment, from, to string, annualize bool) (CurrencyReturn, error) {
+	r := CurrencyReturn{Investment: inv.Name, Currency: inv.EffectiveCurrency()}
+	start, end, err := inv.returnPeriod(from, to)
+	if err != nil {
+		return r, err
+	}
+	r.From, r.To = start, end
+	startValue, endValue, err := inv.periodValues(start, end)
+	if err != nil {
+		return r, err
+	}
+
+	flows := append(inv.paidDistributionFlows(), inv.CashFlows...)
+	local := dietzReturn(NAV{Date: Date{start}, Value: NewMoney(startValue)}, NAV{Date: Date{end}, Value: NewMoney(endValue)}, flows)
+
+	// Seuls les flux de la période sont convertis : les taux antérieurs peuvent manquer
+	var baseFlows []CashFlow
+	for _, cf := range flows {
+		if !cf.Date.After(start) || cf.Date.After(end) {
+			continue
+		}
+		amount, err := p.toBase(cf.Amount.Float64(), inv.Currency, cf.Date.Time)
+		if err != nil {
+			return r, err
+		}
+		cf.Amount = NewMoney(amount)
+		baseFlows = append(baseFlows, cf)
+	}
+	startRate, err := p.toBase(1, inv.Currency, start)
+	if err != nil {
+		return r, err
+	}
+	endRate, err := p.toBase(1, inv.Currency, end)
+	if err != nil {
+		return r, err
+	}
+	base := dietzReturn(NAV{Date: Date{start}, Value: NewMoney(startValue * startRate)}, NAV{Date: Date{end}, Value: NewMoney(endValue * endRate)}, baseFlows)
+
+	if local, err = inv.exposureReturn(local); err != nil {
+		return r, err
+	}
+	if base, err = inv.exposureReturn(base); err != nil {
+		return r, err
+	}
+	if r.Local, err = inv.conventions.PeriodRate(local, start, end, annualize); err != nil {
+		return r, err
+	}
+	if r.Base, err = inv.conventions.PeriodRate(base, start, end, annualize); err != nil {
+		return r, err
+	}
+	if r.FXChange, err = inv.conventions.PeriodRate(endRate/startRate-1, start, end, annualize); err != nil {
+		return r, err
+	}
+	r.FXEffect = r.Base - r.Local
+	return r, nil
+}
@@ -0,0 +1,167 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ghostfolioDateLayout est le format des dates des activités Ghostfolio
+const ghostfolioDateLayout = "2006-01-02T15:04:05.000Z"
+
+// ghostfolioActivity est une activité de l'export JSON de Ghostfolio
+type ghostfolioActivity struct {
+	AccountID  string  `json:"accountId,omitempty"`
+	Comment    string  `json:"comment,omitempty"`
+	Currency   string  `json:"currency"`
+	DataSource string  `json:"dataSource"`
+	Date       string  `json:"date"`
+	Fee        float64 `json:"fee"`
+	Quantity   float64 `json:"quantity"`
+	Symbol     string  `json:"symbol"`
+	Type       string  `json:"type"`
+	UnitPrice  float64 `json:"unitPrice"`
+}
+
+// ghostfolioAccount est un compte de l'export JSON de Ghostfolio
+type ghostfolioAccount struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Currency string  `json:"currency"`
+	Balance  float64 `json:"balance"`
+}
+
+// ghostfolioExport est le document d'import et d'export de Ghostfolio
+type ghostfolioExport struct {
+	Meta struct {
+		Date    string `json:"date"`
+		Version string `json:"version"`
+	} `json:"meta"`
+	Accounts   []ghostfolioAccount  `json:"accounts"`
+	Activities []ghostfolioActivity `json:"activities"`
+}
+
+// ghostfolioKinds associe les types d'activités Ghostfolio aux opérations d'un relevé ;
+// les frais isolés, passifs et biens ne sont pas importés
+var ghostfolioKinds = map[string]StatementEntryKind{
+	"BUY":      StatementBuy,
+	"SELL":     StatementSell,
+	"DIVIDEND": StatementIncome,
+	"INTEREST": StatementIncome,
+}
+
+// ghostfolioImporter lit l'export JSON de Ghostfolio : une opération par activité,
+// rattachée au compte Ghostfolio qui la porte
+type ghostfolioImporter struct{}
+
+func (ghostfolioImporter) Name() string { return "ghostfolio" }
+
+func (ghostfolioImporter) Detect(head []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) && bytes.Contains(head, []byte(`"activities"`))
+}
+
+func (ghostfolioImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	var doc ghostfolioExport
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("export Ghostfolio: %w", err)
+	}
+	names := make(map[string]string, len(doc.Accounts))
+	for _, a := range doc.Accounts {
+		names[a.ID] = a.Name
+	}
+
+	st := &Statement{}
+	accounts := make(map[string]int)
+	for i, a := range doc.Activities {
+		kind, known := ghostfolioKinds[strings.ToUpper(a.Type)]
+		if !known {
+			continue
+		}
+		date, _, _ := strings.Cut(a.Date, "T")
+		t, err := ParseDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("export Ghostfolio, activité %d: %w", i+1, err)
+		}
+		if a.Quantity < 0 || a.UnitPrice < 0 || a.Fee < 0 {
+			return nil, fmt.Errorf("export Ghostfolio, activité %d: %w", i+1, InvalidField("quantity", a.Quantity, "quantité, prix et frais doivent être positifs"))
+		}
+		e := StatementEntry{Date: t, Kind: kind, Fees: NewMoney(a.Fee), Description: DefaultString(a.Comment, a.Symbol)}
+		if ValidISIN(strings.ToUpper(a.Symbol)) {
+			e.Security.ID = strings.ToUpper(a.Symbol)
+		} else {
+			e.Security.Ticker = a.Symbol
+		}
+		if kind == StatementIncome {
+			e.Amount = NewMoney(a.Quantity * a.UnitPrice)
+		} else {
+			if a.Quantity == 0 {
+				return nil, fmt.Errorf("export Ghostfolio, activité %d: %w", i+1, InvalidField("quantity", a.Quantity, "nombre de parts absent"))
+			}
+			e.Units, e.Price = NewQuantity(a.Quantity), NewMoney(a.UnitPrice)
+			e.Amount = e.Price.Mul(a.Quantity)
+		}
+
+		id := DefaultString(names[a.AccountID], DefaultString(opts.Account, "ghostfolio"))
+		k, seen := accounts[id]
+		if !seen {
+			k = len(st.Accounts)
+			accounts[id] = k
+			st.Accounts = append(st.Accounts, StatementAccount{ID: id, Currency: Currency(a.Currency)})
+		}
+		st.Accounts[k].Entries = append(st.Accounts[k].Entries, e)
+	}
+	return st, nil
+}
+
+// ghostfolioTypes sont les types d'activités exportés vers Ghostfolio
+var ghostfolioTypes = map[StatementEntryKind]string{
+	StatementBuy:    "BUY",
+	StatementSell:   "SELL",
+	StatementIncome: "DIVIDEND",
+}
+
+// ExportGhostfolio écrit les opérations du portefeuille au format JSON d'import de
+// Ghostfolio, sur un compte unique : mêmes opérations que l'export Portfolio Performance
+// (voir trackerEntries). Un titre sans ticker est exporté en source manuelle, sous son
+// ISIN ou à défaut son nom.
+func (p *Portfolio) ExportGhostfolio(w io.Writer) error {
+	entries := p.trackerEntries()
+	p.mu.RLock()
+	base := p.baseCurrency()
+	p.mu.RUnlock()
+
+	const accountID = "david"
+	var doc ghostfolioExport
+	doc.Meta.Date = time.Now().UTC().Format(ghostfolioDateLayout)
+	doc.Meta.Version = "david"
+	doc.Accounts = []ghostfolioAccount{{ID: accountID, Name: "david", Currency: string(base)}}
+	doc.Activities = make([]ghostfolioActivity, 0, len(entries))
+	for _, e := range entries {
+		a := ghostfolioActivity{
+			AccountID:  accountID,
+			Comment:    e.Description,
+			Currency:   string(e.currency),
+			DataSource: "YAHOO",
+			Date:       e.Date.UTC().Format(ghostfolioDateLayout),
+			Fee:        e.Fees.Float64(),
+			Quantity:   e.Units.Float64(),
+			Symbol:     e.Security.Ticker,
+			Type:       ghostfolioTypes[e.Kind],
+			UnitPrice:  e.Price.Float64(),
+		}
+		if a.Symbol == "" {
+			a.DataSource, a.Symbol = "MANUAL", DefaultString(e.Security.ID, e.Security.Name)
+		}
+		if e.Kind == StatementIncome {
+			a.Quantity, a.UnitPrice = 1, e.Amount.Float64()
+		}
+		doc.Activities = append(doc.Activities, a)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,237 @@
+package portfolio
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// GlidePath fait évoluer l'allocation cible dans le temps : la part de la poche
+// dynamique (actions) suit une trajectoire, en général décroissante à l'approche de la
+// retraite. Les poids cibles de chaque poche sont conservés en proportion ; seule la
+// répartition entre les deux poches change.
+type GlidePath struct {
+	Growth []string     `json:"growth"` // Investissements de la poche dynamique, les autres investissements de la cible formant la poche défensive
+	Points []GlidePoint `json:"points"` // Part dynamique visée à des dates, par date croissante
+}
+
+// GlidePoint est la part (%) de la poche dynamique visée à une date. Entre deux points
+// la part est interpolée linéairement ; elle est constante avant le premier et après le
+// dernier.
+type GlidePoint struct {
+	Date   Date    `json:"date"`
+	Growth float64 `json:"growth"`
+}
+
+// growthAt retourne la part dynamique (%) visée à une date
+func (g *GlidePath) growthAt(date time.Time) float64 {
+	points := g.Points
+	i := sort.Search(len(points), func(i int) bool { return points[i].Date.After(date) })
+	switch {
+	case i == 0:
+		return points[0].Growth
+	case i == len(points):
+		return points[i-1].Growth
+	}
+	before, after := points[i-1], points[i]
+	frac := date.Sub(before.Date.Time).Hours() / after.Date.Sub(before.Date.Time).Hours()
+	return before.Growth + frac*(after.Growth-before.Growth)
+}
+
+// isGrowth indique si un investissement appartient à la poche dynamique
+func (g *GlidePath) isGrowth(name string) bool {
+	return slices.Contains(g.Growth, name)
+}
+
+// weightsAt retourne les poids cibles (%) à une date : ceux de la cible, répartis entre
+// les poches selon la trajectoire s'il y en a une. Une poche dont les poids cibles sont
+// tous nuls est répartie à parts égales entre ses investissements.
+func (a *TargetAllocation) weightsAt(date time.Time) map[string]float64 {
+	weights := make(map[string]float64, len(a.Weights))
+	if a.Glide == nil {
+		for name, weight := range a.Weights {
+			weights[name] = weight
+		}
+		return weights
+	}
+
+	growth := a.Glide.growthAt(date)
+	type pocket struct {
+		names  []string
+		sum    float64
+		target float64
+	}
+	pockets := [2]*pocket{{target: growth}, {target: 100 - growth}}
+	for name, weight := range a.Weights {
+		pk := pockets[1]
+		if a.Glide.isGrowth(name) {
+			pk = pockets[0]
+		}
+		pk.names = append(pk.names, name)
+		pk.sum += weight
+	}
+	for _, name := range a.Glide.Growth {
+		if _, inTarget := a.Weights[name]; !inTarget {
+			pockets[0].names = append(pockets[0].names, name)
+		}
+	}
+	for _, pk := range pockets {
+		for _, name := range pk.names {
+			if pk.sum > 0 {
+				weights[name] = a.Weights[name] / pk.sum * pk.target
+			} else {
+				weights[name] = pk.target / float64(len(pk.names))
+			}
+		}
+	}
+	return weights
+}
+
+// SetGlidePath associe une trajectoire à l'allocation cible, qui doit exister. Une
+// trajectoire sans point la supprime.
+func (p *Portfolio) SetGlidePath(growth []string, points []GlidePoint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.TargetAllocation == nil {
+		return fmt.Errorf("aucune allocation cible définie (commande set-target)")
+	}
+	before, err := p.settingsState("target_allocation")
+	if err != nil {
+		return err
+	}
+
+	if len(points) == 0 {
+		p.TargetAllocation.Glide = nil
+		return p.recordChange(JournalEntry{Op: OpSetGlidePath, Detail: "aucune trajectoire", BeforeSettings: before})
+	}
+	if len(growth) == 0 {
+		return InvalidField("growth", "", "la poche dynamique doit contenir au moins un investissement")
+	}
+	for _, name := range growth {
+		if _, exists := p.Investments[name]; !exists {
+			return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+		}
+	}
+	sorted := slices.Clone(points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date.Time) })
+	for i, point := range sorted {
+		if point.Growth < 0 || point.Growth > 100 {
+			return InvalidField("growth", point.Growth, "part dynamique hors de [0, 100]: %.2f%%", point.Growth)
+		}
+		if i > 0 && point.Date.Equal(sorted[i-1].Date.Time) {
+			return InvalidField("date", FormatDate(point.Date.Time), "deux points au %s", FormatDate(point.Date.Time))
+		}
+	}
+	p.TargetAllocation.Glide = &GlidePath{Growth: slices.Clone(growth), Points: sorted}
+	return p.recordChange(JournalEntry{Op: OpSetGlidePath, Detail: fmt.Sprintf("%d points", len(sorted)), BeforeSettings: before})
+}
+
+// GlideStep est un pas de la projection le long de la trajectoire
+type GlideStep struct {
+	Date   time.Time
+	Growth float64            // Part dynamique visée (%)
+	Total  float64            // Valeur projetée du portefeuille (devise de consolidation)
+	Values map[string]float64 // Valeur projetée de chaque investissement après rééquilibrage
+}
+
+// GlidePathProjection projette le portefeuille d'aujourd'hui (ou de la dernière NAV si
+// elle est postérieure) jusqu'à to en suivant la trajectoire : chaque investissement
+// croît à son taux de projection, un versement mensuel est réparti selon les poids cibles
+// du moment, et le portefeuille est rééquilibré à chaque pas.
+func (p *Portfolio) GlidePathProjection(to string, step SeriesStep, monthly float64) ([]GlideStep, error) {
+	end, err := ParseDate(to)
+	if err != nil {
+		return nil, err
+	}
+	if monthly < 0 {
+		return nil, fmt.Errorf("le versement mensuel ne peut pas être négatif: %w", ErrInvalidAmount)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.TargetAllocation == nil || p.TargetAllocation.Glide == nil {
+		return nil, fmt.Errorf("aucune trajectoire d'allocation définie (commande set-glide-path)")
+	}
+	return p.glideProjection(end, step, monthly, nil)
+}
+
+// glideProjection simule le portefeuille le long de la trajectoire, au taux imposé s'il
+// est fourni ; l'appelant doit détenir p.mu et vérifier que la trajectoire existe
+func (p *Portfolio) glideProjection(end time.Time, step SeriesStep, monthly float64, rate *float64) ([]GlideStep, error) {
+	start := Today()
+	for _, inv := range p.Investments {
+		if n := len(inv.NAVHistory); n > 0 && !inv.Closed {
+			start = later(start, inv.NAVHistory[n-1].Date.Time)
+		}
+	}
+	dates, err := projectionDates(start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	values, _, err := p.portfolioValue(start)
+	if err != nil {
+		return nil, err
+	}
+	target := p.TargetAllocation
+	rates := make(map[string]float64, len(values))
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if _, held := values[name]; !held && !(target.Weights[name] > 0 || target.Glide.isGrowth(name)) {
+			continue
+		}
+		r, err := inv.ProjectionRate(nil)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		if rate != nil {
+			r = *rate
+		}
+		rates[name] = inv.navRate(r)
+	}
+
+	// Les investissements hors de la cible sont vendus au premier rééquilibrage
+	rebalance := func(date time.Time) GlideStep {
+		var total float64
+		for _, value := range values {
+			total += value
+		}
+		values = make(map[string]float64, len(target.Weights))
+		for name, weight := range target.weightsAt(date) {
+			values[name] = total * weight / 100
+		}
+		return GlideStep{Date: date, Growth: target.Glide.growthAt(date), Total: total, Values: maps.Clone(values)}
+	}
+
+	conv := p.rateConventions()
+	steps := []GlideStep{rebalance(start)}
+	current, month := start, 1
+	for _, date := range dates[1:] {
+		// Croissance et versements mois par mois jusqu'au pas suivant
+		for {
+			next := start.AddDate(0, month, 0)
+			if next.After(date) {
+				next = date
+			}
+			years := conv.YearsBetween(current, next)
+			for name, value := range values {
+				values[name] = p.Investments[name].Fees.grow(conv, value, years, rates[name])
+			}
+			if next.Equal(start.AddDate(0, month, 0)) {
+				for name, weight := range target.weightsAt(next) {
+					values[name] += p.Investments[name].Fees.netContribution(monthly * weight / 100)
+				}
+				month++
+			}
+			current = next
+			if !current.Before(date) {
+				break
+			}
+		}
+		steps = append(steps, rebalance(date))
+	}
+	return steps, nil
+}
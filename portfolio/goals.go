@@ -0,0 +1,156 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// RequiredContribution calcule le versement mensuel à ajouter au portefeuille pour
+// atteindre targetValue (devise de consolidation) à une date, aux taux effectifs
+// actuels. Le versement est réparti selon l'allocation cible si elle existe, sinon
+// au prorata des valeurs projetées. Retourne 0 si l'objectif est atteint sans versement.
+func (p *Portfolio) RequiredContribution(targetValue float64, date string) (float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	project := func(monthly float64) (float64, error) {
+		return p.projectGoal(t, monthly, nil)
+	}
+
+	// La valeur projetée est affine en fonction du versement : deux points suffisent
+	withoutContribution, err := project(0)
+	if err != nil {
+		return 0, err
+	}
+	if withoutContribution >= targetValue {
+		return 0, nil
+	}
+	withUnit, err := project(1)
+	if err != nil {
+		return 0, err
+	}
+	perUnit := withUnit - withoutContribution
+	if perUnit <= 0 {
+		return 0, fmt.Errorf("aucun versement ne peut avoir lieu avant le %s", date)
+	}
+
+	return NewMoney((targetValue - withoutContribution) / perUnit).RoundCents().Float64(), nil
+}
+
+// RequiredRate calcule le taux annuel (%) que tous les investissements devraient
+// obtenir pour que le portefeuille atteigne targetValue à une date, compte tenu d'un
+// versement mensuel prévu, réparti comme dans RequiredContribution
+func (p *Portfolio) RequiredRate(targetValue float64, date string, monthlyContribution float64) (float64, error) {
+	if monthlyContribution < 0 {
+		return 0, fmt.Errorf("le versement mensuel ne peut pas être négatif: %w", ErrInvalidAmount)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return analytics.SolveRate(targetValue, func(rate float64) (float64, error) {
+		return p.projectGoal(t, monthlyContribution, &rate)
+	})
+}
+
+// ImpliedRate calcule le taux annuel constant (%) qui mène l'investissement de sa
+// dernière NAV à targetValue à une date : l'inverse de ProjectNAVAtRate, frais et
+// versements programmés compris
+func (inv *Investment) ImpliedRate(targetValue float64, date string) (float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return 0, err
+	}
+	latest, err := inv.GetLatestNAV()
+	if err != nil {
+		return 0, err
+	}
+	if !t.After(latest.Date.Time) {
+		return 0, fmt.Errorf("la date de l'objectif doit être après la dernière NAV: %w", ErrInvalidDate)
+	}
+	return analytics.SolveRate(targetValue, func(rate float64) (float64, error) {
+		return inv.projectNAVAtRate(t, rate)
+	})
+}
+
+// projectGoal projette la valeur totale des investissements ouverts à une date avec un
+// versement mensuel global, au taux imposé s'il est fourni ou au taux effectif de chacun.
+// Avec une trajectoire d'allocation, le portefeuille la suit et est rééquilibré chaque
+// année. L'appelant doit détenir p.mu.
+func (p *Portfolio) projectGoal(date time.Time, monthly float64, rate *float64) (float64, error) {
+	if p.TargetAllocation != nil && p.TargetAllocation.Glide != nil {
+		steps, err := p.glideProjection(date, StepYearly, monthly, rate)
+		if err != nil {
+			return 0, err
+		}
+		return steps[len(steps)-1].Total, nil
+	}
+
+	shares, err := p.contributionShares(date)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		invRate, err := inv.EffectiveRate()
+		if err != nil {
+			return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		if rate != nil {
+			invRate = *rate
+		}
+
+		// Le versement est exprimé en devise de consolidation
+		fx, err := p.toBase(1, inv.Currency, date)
+		if err != nil {
+			return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		value, err := inv.projectWithContributionsAt(date, monthly*shares[name]/fx, invRate)
+		if err != nil {
+			return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		total += value * fx
+	}
+	return total, nil
+}
+
+// contributionShares retourne la part de chaque investissement ouvert dans les versements.
+// L'appelant doit détenir p.mu.
+func (p *Portfolio) contributionShares(date time.Time) (map[string]float64, error) {
+	if p.TargetAllocation != nil {
+		weights := p.TargetAllocation.weightsAt(date)
+		shares := make(map[string]float64, len(weights))
+		for name, weight := range weights {
+			shares[name] = weight / 100
+		}
+		return shares, nil
+	}
+
+	values, total, err := p.portfolioValue(date)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("la valeur totale du portefeuille est nulle")
+	}
+	shares := make(map[string]float64, len(values))
+	for name, value := range values {
+		shares[name] = value / total
+	}
+	return shares, nil
+}
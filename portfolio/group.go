@@ -0,0 +1,349 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// PortfolioGroup regroupe les portefeuilles de plusieurs comptes (PEA, assurance-vie,
+// compte-titres…) pour une vue consolidée dans une devise commune
+type PortfolioGroup struct {
+	mu sync.RWMutex
+
+	BaseCurrency Currency // Devise de consolidation du groupe (EUR si vide)
+	Rates        Rates    // Taux de change entre les devises de consolidation des comptes et celle du groupe
+
+	accounts map[string]*Portfolio
+}
+
+// AccountSummary est la ligne d'un compte dans la vue consolidée
+type AccountSummary struct {
+	Name     string   `json:"name"`
+	Currency Currency `json:"currency"` // Devise de consolidation du compte
+	Value    float64  `json:"value"`    // En devise du groupe
+	Invested float64  `json:"invested"` // Capital net investi, en devise du groupe
+	Weight   float64  `json:"weight"`   // Part de la valeur du groupe (%)
+	XIRR     *float64 `json:"xirr,omitempty"`
+}
+
+// GroupSummary est la vue consolidée des comptes d'un groupe à une date
+type GroupSummary struct {
+	Date          time.Time        `json:"date"`
+	BaseCurrency  Currency         `json:"base_currency"`
+	TotalValue    float64          `json:"total_value"`
+	TotalInvested float64          `json:"total_invested"`
+	XIRR          *float64         `json:"xirr,omitempty"` // TRI de l'ensemble des comptes (%), nil s'il ne peut être calculé
+	Accounts      []AccountSummary `json:"accounts"`       // Triés par nom
+}
+
+// NewPortfolioGroup crée un groupe vide consolidé dans la devise base (EUR si vide)
+func NewPortfolioGroup(base Currency) *PortfolioGroup {
+	if base == "" {
+		base = DefaultCurrency
+	}
+	return &PortfolioGroup{BaseCurrency: base, accounts: make(map[string]*Portfolio)}
+}
+
+// AddAccount ajoute le portefeuille d'un compte au groupe
+func (g *PortfolioGroup) AddAccount(name string, p *Portfolio) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("le nom du compte ne peut pas être vide")
+	}
+	if _, exists := g.accounts[name]; exists {
+		return fmt.Errorf("le compte '%s' existe déjà: %w", name, ErrAlreadyExists)
+	}
+	g.accounts[name] = p
+	return nil
+}
+
+// RemoveAccount retire un compte du groupe
+func (g *PortfolioGroup) RemoveAccount(name string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.accounts[name]; !exists {
+		return fmt.Errorf("aucun compte '%s': %w", name, ErrNotFound)
+	}
+	delete(g.accounts, name)
+	return nil
+}
+
+// Account retourne le portefeuille d'un compte
+func (g *PortfolioGroup) Account(name string) (*Portfolio, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	p, exists := g.accounts[name]
+	if !exists {
+		return nil, fmt.Errorf("aucun compte '%s': %w", name, ErrNotFound)
+	}
+	return p, nil
+}
+
+// AccountNames retourne les noms des comptes, triés
+func (g *PortfolioGroup) AccountNames() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	names := make([]string, 0, len(g.accounts))
+	for name := range g.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toGroupBase convertit un montant de la devise de consolidation d'un compte dans
+// celle du groupe
+func (g *PortfolioGroup) toGroupBase(amount float64, currency Currency, date time.Time) (float64, error) {
+	if currency == g.BaseCurrency {
+		return amount, nil
+	}
+	if g.Rates == nil {
+		return 0, fmt.Errorf("aucune source de taux pour convertir %s en %s: %w", currency, g.BaseCurrency, ErrRateNotFound)
+	}
+	rate, err := g.Rates.Rate(currency, g.BaseCurrency, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// ConsolidatedValue valorise chaque investissement de chaque compte à une date, dans la
+// devise du groupe. Les clés sont de la forme "compte/investissement".
+func (g *PortfolioGroup) ConsolidatedValue(date string) (map[string]float64, float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	values := make(map[string]float64)
+	var total Money
+	for account, p := range g.accounts {
+		p.mu.RLock()
+		accountValues, _, err := p.portfolioValue(t)
+		base := p.baseCurrency()
+		p.mu.RUnlock()
+		if err != nil {
+			return nil, 0, fmt.Errorf("compte %s: %w", account, err)
+		}
+		for name, value := range accountValues {
+			converted, err := g.toGroupBase(value, base, t)
+			if err != nil {
+				return nil, 0, fmt.Errorf("compte %s: %w", account, err)
+			}
+			rounded := NewMoney(converted).RoundCents()
+			values[account+"/"+name] = rounded.Float64()
+			total += rounded
+		}
+	}
+	return values, total.Float64(), nil
+}
+
+// Summary construit la vue consolidée du groupe à une date : valeur et capital investi
+// par compte convertis dans la devise du groupe, TRI par compte et global
+func (g *PortfolioGroup) Summary(date string) (*GroupSummary, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	s := &GroupSummary{Date: t, BaseCurrency: g.BaseCurrency, Accounts: []AccountSummary{}}
+	var totalValue, totalInvested Money
+	var allFlows []analytics.Flow
+	complete := true // Le TRI global n'est calculé que si celui de chaque compte l'est
+	names := make([]string, 0, len(g.accounts))
+	for name := range g.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line, flows, err := g.accountSummary(name, t)
+		if err != nil {
+			return nil, fmt.Errorf("compte %s: %w", name, err)
+		}
+		complete = complete && flows != nil
+		s.Accounts = append(s.Accounts, line)
+		totalValue += NewMoney(line.Value)
+		totalInvested += NewMoney(line.Invested)
+		allFlows = append(allFlows, flows...)
+	}
+	s.TotalValue = totalValue.RoundCents().Float64()
+	s.TotalInvested = totalInvested.RoundCents().Float64()
+	if s.TotalValue != 0 {
+		for i := range s.Accounts {
+			s.Accounts[i].Weight = s.Accounts[i].Value / s.TotalValue * 100
+		}
+	}
+	// Les comptes peuvent avoir des conventions différentes : le TRI global suit celles par défaut
+	if rate, err := (analytics.RateConventions{}).XIRR(allFlows); complete && err == nil && !math.IsNaN(rate) {
+		s.XIRR = &rate
+	}
+	return s, nil
+}
+
+// accountSummary résume un compte à une date et retourne ses flux convertis dans la
+// devise du groupe, nil s'ils ne peuvent être établis ; l'appelant doit détenir g.mu
+func (g *PortfolioGroup) accountSummary(name string, t time.Time) (AccountSummary, []analytics.Flow, error) {
+	p := g.accounts[name]
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	base := p.baseCurrency()
+	line := AccountSummary{Name: name, Currency: base}
+	_, value, err := p.portfolioValue(t)
+	if err != nil {
+		return line, nil, err
+	}
+	var invested Money
+	for invName, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		amount, err := p.toBase(inv.NetInvested().Float64()*inv.Sign(), inv.Currency, t)
+		if err != nil {
+			return line, nil, fmt.Errorf("erreur pour %s: %w", invName, err)
+		}
+		invested += NewMoney(amount)
+	}
+	if line.Value, err = g.toGroupBase(value, base, t); err != nil {
+		return line, nil, err
+	}
+	if line.Invested, err = g.toGroupBase(invested.Float64(), base, t); err != nil {
+		return line, nil, err
+	}
+	line.Value = NewMoney(line.Value).RoundCents().Float64()
+	line.Invested = NewMoney(line.Invested).RoundCents().Float64()
+
+	// Un investissement sans NAV n'a pas de valeur de sortie : le TRI du compte est omis
+	flows, err := p.xirrFlows()
+	if err != nil {
+		return line, nil, nil
+	}
+	if flows == nil {
+		flows = []analytics.Flow{}
+	}
+	for i := range flows {
+		if flows[i].Amount, err = g.toGroupBase(flows[i].Amount, base, flows[i].Date); err != nil {
+			return line, nil, err
+		}
+	}
+	if rate, err := p.rateConventions().XIRR(flows); err == nil && !math.IsNaN(rate) {
+		line.XIRR = &rate
+	}
+	return line, flows, nil
+}
+
+// AllocationByTag répartit la valeur consolidée des comptes à une date selon les valeurs
+// d'une étiquette ; l'étiquette "account" répartit par compte
+func (g *PortfolioGroup) AllocationByTag(tag, date string) (map[string]float64, error) {
+	values, total, err := g.ConsolidatedValue(date)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("la valeur totale du groupe est nulle")
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	allocation := make(map[string]float64)
+	for key, value := range values {
+		account, name, _ := strings.Cut(key, "/")
+		label := account
+		if tag != "account" {
+			p := g.accounts[account]
+			p.mu.RLock()
+			if tag == TagOwner {
+				label = p.ownerOf(p.Investments[name])
+			} else {
+				label = p.Investments[name].Tags[tag]
+			}
+			p.mu.RUnlock()
+			if label == "" {
+				label = UntaggedLabel
+			}
+		}
+		allocation[label] += value / total * 100
+	}
+	return allocation, nil
+}
+
+// GroupManifest est le fichier d'un groupe : la devise de consolidation et le fichier de
+// portefeuille de chaque compte (relatif au répertoire du fichier de groupe)
+type GroupManifest struct {
+	BaseCurrency Currency          `json:"base_currency,omitempty"`
+	Accounts     map[string]string `json:"accounts"`
+}
+
+// ReadGroupManifest lit le fichier d'un groupe, ou retourne un groupe vide s'il n'existe pas
+func ReadGroupManifest(path string) (*GroupManifest, error) {
+	m := &GroupManifest{Accounts: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lecture de %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("lecture de %s: %w", path, err)
+	}
+	if m.Accounts == nil {
+		m.Accounts = make(map[string]string)
+	}
+	return m, nil
+}
+
+// Save enregistre le fichier du groupe
+func (m *GroupManifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, append(data, '\n'), 0o600)
+}
+
+// LoadPortfolioGroup charge un groupe et les portefeuilles de tous ses comptes
+func LoadPortfolioGroup(path string) (*PortfolioGroup, error) {
+	m, err := ReadGroupManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	g := NewPortfolioGroup(m.BaseCurrency)
+	for name, file := range m.Accounts {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+		p, err := LoadPortfolioJSON(file)
+		if err != nil {
+			return nil, fmt.Errorf("compte %s: %w", name, err)
+		}
+		if err := g.AddAccount(name, p); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,175 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GrowthLine décompose la variation de valeur d'un investissement sur une période, en
+// devise de consolidation : End = Start + Contributed - Withdrawn + Growth - Distributed
+type GrowthLine struct {
+	Name        string  `json:"name"`
+	Start       float64 `json:"start"`       // Valeur en début de période
+	End         float64 `json:"end"`         // Valeur en fin de période
+	Contributed float64 `json:"contributed"` // Argent frais : montant initial et apports
+	Withdrawn   float64 `json:"withdrawn"`   // Retraits, et valeur de clôture d'une position soldée
+	Distributed float64 `json:"distributed"` // Distributions versées en numéraire, comptées dans Growth
+	Growth      float64 `json:"growth"`      // Croissance due au marché, distributions versées comprises
+}
+
+// Change retourne la variation de valeur sur la période
+func (l GrowthLine) Change() float64 {
+	return l.End - l.Start
+}
+
+// NetContributed retourne l'argent frais net des retraits
+func (l GrowthLine) NetContributed() float64 {
+	return l.Contributed - l.Withdrawn
+}
+
+func (l *GrowthLine) add(o GrowthLine) {
+	l.Start += o.Start
+	l.End += o.End
+	l.Contributed += o.Contributed
+	l.Withdrawn += o.Withdrawn
+	l.Distributed += o.Distributed
+	l.Growth += o.Growth
+}
+
+// GrowthDecomposition sépare, sur une période, l'épargne versée de la croissance due au
+// marché, par investissement et pour le portefeuille
+type GrowthDecomposition struct {
+	From        time.Time
+	To          time.Time
+	Investments []GrowthLine // Triées par nom
+	Total       GrowthLine
+}
+
+// DecomposeGrowth sépare la variation de valeur du portefeuille entre from et to en argent
+// frais versé (montant initial d'un investissement ouvert pendant la période, apports,
+// moins les retraits) et croissance due au marché, distributions versées comprises. Les
+// valeurs aux bornes sont interpolées entre NAV comme pour ValueSeries ; un investissement
+// clôturé pendant la période est retiré à sa valeur de clôture.
+func (p *Portfolio) DecomposeGrowth(from, to string) (*GrowthDecomposition, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() || end.IsZero() {
+		return nil, fmt.Errorf("les dates de début et de fin sont obligatoires: %w", ErrInvalidDate)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("la fin de la période doit être après son début: %w", ErrInvalidDate)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.decomposeGrowth(start, end)
+}
+
+// decomposeGrowth calcule la décomposition ; l'appelant doit détenir p.mu
+func (p *Portfolio) decomposeGrowth(start, end time.Time) (*GrowthDecomposition, error) {
+	d := &GrowthDecomposition{From: start, To: end, Total: GrowthLine{Name: "Total"}}
+	inPeriod := func(t time.Time) bool { return t.After(start) && !t.After(end) }
+	for name, inv := range p.Investments {
+		base := func(amount float64, date time.Time) (float64, error) {
+			v, err := p.toBase(amount, inv.Currency, date)
+			if err != nil {
+				return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			return v, nil
+		}
+
+		line := GrowthLine{Name: name}
+		var err error
+		if line.Start, _, err = p.investmentValueAt(inv, start); err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		if line.End, _, err = p.investmentValueAt(inv, end); err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+
+		// Les flux sont vus du portefeuille : le produit d'une vente à découvert en sort,
+		// son rachat et les distributions qu'elle doit y entrent
+		flows := append([]CashFlow{{Date: inv.InvestmentDate, Amount: inv.AmountInvested, Type: Contribution}}, inv.CashFlows...)
+		for _, cf := range flows {
+			if !inPeriod(cf.Date.Time) {
+				continue
+			}
+			cf = inv.signed(cf)
+			amount, err := base(cf.Amount.Float64(), cf.Date.Time)
+			if err != nil {
+				return nil, err
+			}
+			if cf.Type == Withdrawal {
+				line.Withdrawn += amount
+			} else {
+				line.Contributed += amount
+			}
+		}
+		for _, cf := range inv.paidDistributionFlows() {
+			if !inPeriod(cf.Date.Time) {
+				continue
+			}
+			amount, err := base(cf.Amount.Float64(), cf.Date.Time)
+			if err != nil {
+				return nil, err
+			}
+			if inv.Exposure == ExposureShort {
+				line.Contributed += amount
+			} else {
+				line.Distributed += amount
+			}
+		}
+		if inv.Closed && inPeriod(inv.ClosedDate.Time) {
+			closing, _ := inv.historicalValue(inv.ClosedDate.Time)
+			amount, err := base(closing, inv.ClosedDate.Time)
+			if err != nil {
+				return nil, err
+			}
+			if inv.Exposure == ExposureShort {
+				line.Contributed += amount
+			} else {
+				line.Withdrawn += amount
+			}
+		}
+
+		if line == (GrowthLine{Name: name}) {
+			continue
+		}
+		line.Growth = line.Change() - line.NetContributed() + line.Distributed
+		d.Investments = append(d.Investments, line)
+		d.Total.add(line)
+	}
+	sort.Slice(d.Investments, func(i, j int) bool { return d.Investments[i].Name < d.Investments[j].Name })
+	return d, nil
+}
+
+// GrowthSeries découpe la période en sous-périodes d'un pas et décompose chacune
+func (p *Portfolio) GrowthSeries(from, to string, step SeriesStep) ([]*GrowthDecomposition, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() || end.IsZero() {
+		return nil, fmt.Errorf("les dates de début et de fin sont obligatoires: %w", ErrInvalidDate)
+	}
+	dates, err := projectionDates(start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	series := make([]*GrowthDecomposition, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		d, err := p.decomposeGrowth(dates[i-1], dates[i])
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, d)
+	}
+	return series, nil
+}
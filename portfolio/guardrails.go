@@ -0,0 +1,122 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// defaultMaxRate est le taux annuel (%) au-delà duquel un taux de projection n'est
+	// plus plausible : un taux réalisé sur une courte hausse se projette sinon sur des
+	// décennies
+	defaultMaxRate = 25.0
+	// defaultMaxHorizon est l'horizon de projection maximal, en années
+	defaultMaxHorizon = 60
+	// defaultMinRateWindow est la durée (jours) en deçà de laquelle un taux réalisé,
+	// annualisé sur trop peu d'historique, est signalé
+	defaultMinRateWindow = 365
+)
+
+// Guardrails bornent les entrées des projections (table [guardrails] de la configuration)
+type Guardrails struct {
+	MaxRate       float64 // Taux annuel maximal (%), en valeur absolue ; le taux retenu y est ramené
+	MaxHorizon    int     // Horizon maximal (années) ; une projection plus lointaine est refusée
+	MinRateWindow int     // Historique (jours) en deçà duquel un taux réalisé est signalé
+}
+
+// validate vérifie que les bornes sont positives
+func (g Guardrails) validate() error {
+	if g.MaxRate <= 0 || math.IsNaN(g.MaxRate) {
+		return InvalidField("max_rate", g.MaxRate, "le taux maximal doit être positif")
+	}
+	if g.MaxHorizon <= 0 {
+		return InvalidField("max_horizon_years", g.MaxHorizon, "l'horizon maximal doit être positif")
+	}
+	if g.MinRateWindow < 0 {
+		return InvalidField("min_rate_window_days", g.MinRateWindow, "la durée minimale d'historique ne peut pas être négative")
+	}
+	return nil
+}
+
+// withDefaults complète les bornes des valeurs par défaut
+func (g Guardrails) withDefaults() Guardrails {
+	if g.MaxRate == 0 {
+		g.MaxRate = defaultMaxRate
+	}
+	if g.MaxHorizon == 0 {
+		g.MaxHorizon = defaultMaxHorizon
+	}
+	if g.MinRateWindow == 0 {
+		g.MinRateWindow = defaultMinRateWindow
+	}
+	return g
+}
+
+// guardrails retourne les bornes de la table [guardrails] de la configuration
+func guardrails() Guardrails {
+	return ActiveConfig().Guardrails.withDefaults()
+}
+
+// clampRate ramène un taux annuel (%) dans [-MaxRate, MaxRate]
+func (g Guardrails) clampRate(rate float64) float64 {
+	return min(max(rate, -g.MaxRate), g.MaxRate)
+}
+
+// checkHorizon refuse une projection de start à end au-delà de l'horizon maximal
+func (g Guardrails) checkHorizon(start, end time.Time) error {
+	if limit := start.AddDate(g.MaxHorizon, 0, 0); end.After(limit) {
+		return fmt.Errorf("%s au-delà du %s (%d ans): %w", FormatDate(end), FormatDate(limit), g.MaxHorizon, ErrHorizonTooLong)
+	}
+	return nil
+}
+
+// projectionWarnings signale ce qui fragilise la projection de l'investissement : une
+// dernière NAV périmée, un taux réalisé annualisé sur un historique court, un taux
+// ramené à la borne
+func (inv *Investment) projectionWarnings(g Guardrails) []string {
+	var warnings []string
+	if q, err := inv.dataQuality(StepMonthly, Today()); err == nil && q.Stale() {
+		warnings = append(warnings, fmt.Sprintf("projeté depuis une NAV du %s, vieille de %d jours", FormatDate(q.Latest), q.Staleness))
+	}
+	if inv.Cash != nil || inv.Bond != nil || inv.Exposure == ExposureSigned {
+		return warnings
+	}
+
+	// Un taux réalisé court n'est signalé que s'il a pesé sur le taux retenu
+	rate, _ := inv.selectRate(inv.ratePolicy())
+	if rate != inv.ReferenceRate && len(inv.NAVHistory) >= 2 {
+		first, last := inv.NAVHistory[0].Date, inv.NAVHistory[len(inv.NAVHistory)-1].Date
+		if days := daysBetween(first.Time, last.Time); days < g.MinRateWindow {
+			warnings = append(warnings, fmt.Sprintf("taux de %.2f%% tiré d'un historique de %d jours seulement", rate, days))
+		}
+	}
+	if rate != g.clampRate(rate) {
+		warnings = append(warnings, fmt.Sprintf("taux de %.2f%% ramené à %.2f%%", rate, g.clampRate(rate)))
+	}
+	return warnings
+}
+
+// ProjectionWarnings retourne les avertissements des projections des investissements
+// détenus, chacun précédé du nom de l'investissement, dont ceux des investissements sans
+// NAV valorisés ou écartés selon MissingNAVPolicy
+func (p *Portfolio) ProjectionWarnings() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	g := guardrails()
+	var warnings []string
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed {
+			continue
+		}
+		if w := p.missingNAVWarning(inv); w != "" {
+			warnings = append(warnings, name+": "+w)
+		}
+		for _, w := range inv.projectionWarnings(g) {
+			warnings = append(warnings, name+": "+w)
+		}
+	}
+	return warnings
+}
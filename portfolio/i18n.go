@@ -0,0 +1,248 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Locale est la langue des résumés, rapports et messages d'erreur
+type Locale string
+
+const (
+	LocaleFR Locale = "fr"
+	LocaleEN Locale = "en"
+
+	// DefaultLocale est la langue source des messages
+	DefaultLocale = LocaleFR
+)
+
+// catalog associe à chaque langue la traduction des messages, indexés par leur texte
+// français d'origine (à la manière de gettext). Un message absent du catalogue d'une
+// langue reste en français.
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[Locale]map[string]string{
+		LocaleFR: {},
+		LocaleEN: englishMessages,
+	}
+)
+
+// RegisterMessages ajoute ou complète le catalogue d'une langue. Les clés sont les
+// messages français d'origine, formats compris (ex. "Dernière NAV: %s (date: %s)").
+func RegisterMessages(locale Locale, messages map[string]string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if catalog[locale] == nil {
+		catalog[locale] = make(map[string]string)
+	}
+	for source, translation := range messages {
+		catalog[locale][source] = translation
+	}
+}
+
+// Locales retourne les langues disponibles, triées
+func Locales() []Locale {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	locales := make([]Locale, 0, len(catalog))
+	for locale := range catalog {
+		locales = append(locales, locale)
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i] < locales[j] })
+	return locales
+}
+
+// Validate vérifie que la langue dispose d'un catalogue
+func (l Locale) Validate() error {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if _, ok := catalog[l]; !ok {
+		return InvalidField("locale", l, "langue inconnue: %s", l)
+	}
+	return nil
+}
+
+// T traduit un message dans la langue, ou le retourne tel quel s'il n'est pas traduit
+func (l Locale) T(message string) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if translation, ok := catalog[l][message]; ok {
+		return translation
+	}
+	return message
+}
+
+// Tf traduit un format puis l'applique aux arguments
+func (l Locale) Tf(format string, args ...any) string {
+	return fmt.Sprintf(l.T(format), args...)
+}
+
+// sentinelErrors sont les erreurs dont le texte est traduit par LocalizeError, la plus
+// précise d'abord (ErrNoNAV correspond aussi à ErrNAVNotFound)
+var sentinelErrors = []error{
+	ErrInvestmentNotFound, ErrNoNAV, ErrNAVNotFound, ErrInvalidAmount, ErrInsufficientHistory,
+	ErrRateNotFound, ErrInvalidDate, ErrInvestmentExists, ErrDuplicateNAV, ErrWrongPassphrase,
+	ErrPeriodTooShort, ErrNotFound, ErrAlreadyExists, ErrHorizonTooLong,
+}
+
+// LocalizeError rend une erreur dans la langue demandée. Un message entièrement
+// traduit est rendu tel quel ; sinon la cause identifiée par son erreur sentinelle est
+// traduite et le détail d'origine est conservé entre parenthèses.
+func LocalizeError(err error, locale Locale) string {
+	message := err.Error()
+	if translated := locale.T(message); translated != message || locale == DefaultLocale {
+		return translated
+	}
+	for _, sentinel := range sentinelErrors {
+		if errors.Is(err, sentinel) {
+			if cause := locale.T(sentinel.Error()); cause != sentinel.Error() {
+				return fmt.Sprintf("%s (%s)", cause, message)
+			}
+		}
+	}
+	return message
+}
+
+// SetLocale définit la langue par défaut des résumés et rapports du portefeuille
+func (p *Portfolio) SetLocale(locale Locale) error {
+	if err := locale.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("locale")
+	if err != nil {
+		return err
+	}
+
+	p.Locale = locale
+	return p.recordChange(JournalEntry{Op: OpSetLocale, Detail: string(locale), BeforeSettings: before})
+}
+
+// locale retourne la langue du portefeuille, DefaultLocale si elle n'est pas renseignée
+func (p *Portfolio) locale() Locale {
+	if p.Locale == "" {
+		return DefaultLocale
+	}
+	return p.Locale
+}
+
+// DisplayLocale retourne la langue du portefeuille, DefaultLocale si elle n'est pas
+// renseignée
+func (p *Portfolio) DisplayLocale() Locale {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.locale()
+}
+
+// EnvLocale retourne la langue imposée par la variable DAVID_LANG ou la clé locale de la
+// configuration, vide si aucune ne l'est
+func EnvLocale() Locale {
+	return Locale(EnvOrConfig("DAVID_LANG", string(ActiveConfig().Locale)))
+}
+
+// englishMessages est le catalogue anglais
+var englishMessages = map[string]string{
+	// Erreurs
+	"investissement introuvable":                  "investment not found",
+	"NAV introuvable":                             "NAV not found",
+	"montant invalide":                            "invalid amount",
+	"historique insuffisant":                      "insufficient history",
+	"taux de change introuvable":                  "exchange rate not found",
+	"date invalide":                               "invalid date",
+	"investissement déjà existant":                "investment already exists",
+	"NAV déjà enregistrée à cette date":           "NAV already recorded on this date",
+	"phrase secrète incorrecte ou fichier altéré": "wrong passphrase or tampered file",
+	"période trop courte pour annualiser":         "period too short to annualize",
+	"aucune NAV enregistrée":                      "no NAV recorded",
+	"élément introuvable":                         "not found",
+	"élément déjà existant":                       "already exists",
+	"horizon de projection trop lointain":         "projection horizon too far",
+	"Erreur: %s\n":                                "Error: %s\n",
+
+	// Résumé texte
+	"=== RÉSUMÉ DU PORTEFEUILLE ===":                        "=== PORTFOLIO SUMMARY ===",
+	"Investissement: %s\n":                                  "Investment: %s\n",
+	"  Clôturé le %s\n":                                     "  Closed on %s\n",
+	"  Montant investi: %s\n":                               "  Amount invested: %s\n",
+	"  Devise: %s\n":                                        "  Currency: %s\n",
+	"  Sens de la position: %s\n":                           "  Exposure: %s\n",
+	"  Modèle de projection: %s\n":                          "  Projection model: %s\n",
+	"  Quantité: %s actions\n":                              "  Quantity: %s shares\n",
+	"  Prix unitaire initial: %s\n":                         "  Initial unit price: %s\n",
+	"  Flux: %d mouvement(s), capital net investi: %s\n":    "  Flows: %d movement(s), net invested capital: %s\n",
+	"  Distributions: %s versées, %s réinvesties\n":         "  Distributions: %s paid, %s reinvested\n",
+	"  Parts détenues: %s (prix de revient unitaire: %s)\n": "  Units held: %s (average unit cost: %s)\n",
+	"  Plus-value réalisée: %s, latente: %s\n":              "  Realized gain: %s, unrealized: %s\n",
+	"  Taux de référence: %.2f%%\n":                         "  Reference rate: %.2f%%\n",
+	"  Date d'investissement: %s\n":                         "  Investment date: %s\n",
+	"  Dernière NAV: %s (date: %s)\n":                       "  Latest NAV: %s (date: %s)\n",
+	"  Taux de performance annuel: %.2f%%\n":                "  Annual performance rate: %.2f%%\n",
+	"  Aucune NAV enregistrée":                              "  No NAV recorded",
+	"  Note du %s: %s\n":                                    "  Note of %s: %s\n",
+	"achat du %s":                                           "buy on %s",
+	"vente du %s":                                           "sell on %s",
+	"document : %s":                                         "document: %s",
+
+	// Rapports
+	"Rapport de portefeuille":                     "Portfolio report",
+	"Généré le %s — devise de consolidation : %s": "Generated on %s — base currency: %s",
+	"Synthèse":                             "Overview",
+	"Investissement":                       "Investment",
+	"Devise":                               "Currency",
+	"Investi":                              "Invested",
+	"Dernière NAV":                         "Latest NAV",
+	"Date":                                 "Date",
+	"Performance annuelle":                 "Annual performance",
+	"Perf. ann.":                           "Ann. perf.",
+	"Valeur (%s)":                          "Value (%s)",
+	"Total":                                "Total",
+	"clôturé":                              "closed",
+	"clôturé le %s":                        "closed on %s",
+	"Évolution de la valeur":               "Value over time",
+	"Évolution et projection de la valeur": "Value over time and projection",
+	"Répartition (%s)":                     "Allocation (%s)",
+	"Répartition":                          "Allocation",
+	"Valeur":                               "Value",
+	"Part":                                 "Share",
+	"Performances annuelles":               "Annual returns",
+	"Depuis le 1er janvier":                "Year to date",
+	"Depuis le début de l'exercice":        "Fiscal year to date",
+	"Année en cours":                       "Year to date",
+	"Projections":                          "Projections",
+	"Valeur projetée (%s)":                 "Projected value (%s)",
+	"Écartés faute de NAV au %s : %s":      "Excluded for lack of NAV on %s: %s",
+	"Caractéristiques":                     "Details",
+	"Notes":                                "Notes",
+	"Date d'investissement":                "Investment date",
+	"Clôturé le":                           "Closed on",
+	"Montant investi":                      "Amount invested",
+	"Capital net investi":                  "Net invested capital",
+	"Taux de référence":                    "Reference rate",
+	"Dernière NAV (%s)":                    "Latest NAV (%s)",
+	"Taux de performance annuel":           "Annual performance rate",
+	"Distributions versées":                "Distributions paid",
+	"Distributions réinvesties":            "Distributions reinvested",
+	"Parts détenues":                       "Units held",
+	"Prix de revient unitaire":             "Average unit cost",
+	"Plus-value réalisée":                  "Realized gain",
+	"Plus-value latente":                   "Unrealized gain",
+	"Clôture":                              "Closing date",
+	"Taux de référence (%)":                "Reference rate (%)",
+	"Date dernière NAV":                    "Latest NAV date",
+	"Performance annuelle (%)":             "Annual performance (%)",
+	"Total investi (%s)":                   "Total invested (%s)",
+	"Valeur totale (%s)":                   "Total value (%s)",
+	"NAV":                                  "NAV",
+	"Date du flux":                         "Flow date",
+	"Type":                                 "Type",
+	"Montant":                              "Amount",
+}
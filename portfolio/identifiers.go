@@ -0,0 +1,163 @@
+package portfolio
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// InvestmentIdentifiers sont les codes de marché d'un investissement
+type InvestmentIdentifiers struct {
+	ISIN   string // Code ISIN (12 caractères, clé de contrôle vérifiée)
+	Ticker string // Symbole de cotation
+	MIC    string // Code ISO 10383 de la place de cotation du ticker (ex. XPAR)
+}
+
+// newInvestmentID retourne un identifiant interne aléatoire, stable même si
+// l'investissement est renommé
+func newInvestmentID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "inv_" + hex.EncodeToString(b)
+}
+
+// ValidISIN vérifie le format d'un ISIN et sa clé de contrôle (Luhn sur les chiffres
+// obtenus en remplaçant chaque lettre par son rang + 9)
+func ValidISIN(isin string) bool {
+	if len(isin) != 12 {
+		return false
+	}
+	var digits strings.Builder
+	for i, c := range isin {
+		switch {
+		case c >= 'A' && c <= 'Z' && i < 11:
+			fmt.Fprintf(&digits, "%d", c-'A'+10)
+		case c >= '0' && c <= '9' && i >= 2:
+			digits.WriteRune(c)
+		default:
+			return false
+		}
+	}
+	s := digits.String()
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if (len(s)-1-i)%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validate normalise les codes en majuscules et vérifie leur format
+func (ids *InvestmentIdentifiers) validate() error {
+	ids.ISIN = strings.ToUpper(strings.TrimSpace(ids.ISIN))
+	ids.Ticker = strings.ToUpper(strings.TrimSpace(ids.Ticker))
+	ids.MIC = strings.ToUpper(strings.TrimSpace(ids.MIC))
+	if ids.ISIN != "" && !ValidISIN(ids.ISIN) {
+		return InvalidField("isin", ids.ISIN, "ISIN invalide: %s", ids.ISIN)
+	}
+	if strings.ContainsAny(ids.Ticker, " \t") {
+		return InvalidField("ticker", ids.Ticker, "ticker invalide: %s", ids.Ticker)
+	}
+	if ids.MIC != "" {
+		if ids.Ticker == "" {
+			return InvalidField("mic", ids.MIC, "la place de cotation précise un ticker")
+		}
+		if len(ids.MIC) != 4 || strings.Trim(ids.MIC, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" {
+			return InvalidField("mic", ids.MIC, "code de place de cotation invalide: %s", ids.MIC)
+		}
+	}
+	return nil
+}
+
+// SetIdentifiers enregistre l'ISIN, le ticker et la place de cotation d'un investissement
+// (vides pour les retirer). Un ISIN ne peut désigner qu'un investissement, de même qu'un
+// ticker sur une place donnée.
+func (p *Portfolio) SetIdentifiers(name string, ids InvestmentIdentifiers) error {
+	if err := ids.validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	for otherName, other := range p.Investments {
+		if otherName == name {
+			continue
+		}
+		if ids.ISIN != "" && other.ISIN == ids.ISIN {
+			return fmt.Errorf("l'ISIN %s est déjà celui de '%s': %w", ids.ISIN, otherName, ErrAlreadyExists)
+		}
+		if ids.Ticker != "" && other.Ticker == ids.Ticker && other.MIC == ids.MIC {
+			return fmt.Errorf("le ticker %s est déjà celui de '%s': %w", ids.Ticker, otherName, ErrAlreadyExists)
+		}
+	}
+	before := inv.clone()
+	inv.ISIN, inv.Ticker, inv.MIC = ids.ISIN, ids.Ticker, ids.MIC
+	p.record(OpSetIdentifiers, name, fmt.Sprintf("ISIN %q, ticker %q, MIC %q", ids.ISIN, ids.Ticker, ids.MIC), before)
+	return nil
+}
+
+// resolve retrouve le nom d'un investissement à partir de son nom, de son identifiant
+// interne, de son ISIN, de son ticker (éventuellement suffixé de sa place, « AIR@XPAR »)
+// ou de son identifiant de cotation. L'appelant doit détenir p.mu.
+func (p *Portfolio) resolve(key string) (string, error) {
+	if _, exists := p.Investments[key]; exists {
+		return key, nil
+	}
+	upper := strings.ToUpper(strings.TrimSpace(key))
+	ticker, mic, _ := strings.Cut(upper, "@")
+
+	var matches []string
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		switch {
+		case inv.ID == key, inv.ISIN != "" && inv.ISIN == upper, inv.Identifier != "" && inv.Identifier == key,
+			inv.Ticker != "" && inv.Ticker == ticker && (mic == "" || inv.MIC == mic):
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("aucun investissement ne correspond à '%s': %w", key, ErrInvestmentNotFound)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("'%s' désigne plusieurs investissements (%s), préciser la place de cotation (TICKER@MIC)", key, strings.Join(matches, ", "))
+	}
+}
+
+// Lookup retourne une copie de l'investissement désigné par son nom ou l'un de ses
+// identifiants (voir resolve)
+func (p *Portfolio) Lookup(key string) (*Investment, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	name, err := p.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	return p.Investments[name].clone(), nil
+}
+
+// quoteIdentifier retourne le code interrogé par RefreshNAVs : l'identifiant de
+// cotation s'il est renseigné, sinon le ticker, sinon l'ISIN
+func (inv *Investment) quoteIdentifier() string {
+	switch {
+	case inv.Identifier != "":
+		return inv.Identifier
+	case inv.Ticker != "":
+		return inv.Ticker
+	default:
+		return inv.ISIN
+	}
+}
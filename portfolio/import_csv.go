@@ -0,0 +1,222 @@
+package portfolio
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats de date acceptés à l'import, le premier étant le format interne
+var csvDateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006"}
+
+// CSVLineError décrit une ligne rejetée lors d'un import CSV
+type CSVLineError struct {
+	Line int   // Numéro de ligne dans le fichier (à partir de 1)
+	Err  error // Raison du rejet
+}
+
+func (e CSVLineError) Error() string {
+	return fmt.Sprintf("ligne %d: %v", e.Line, e.Err)
+}
+
+func (e CSVLineError) Unwrap() error {
+	return e.Err
+}
+
+// CSVImportError regroupe toutes les lignes rejetées d'un import
+type CSVImportError struct {
+	Lines []CSVLineError
+}
+
+func (e *CSVImportError) Error() string {
+	msgs := make([]string, len(e.Lines))
+	for i, l := range e.Lines {
+		msgs[i] = l.Error()
+	}
+	return fmt.Sprintf("%d ligne(s) invalide(s): %s", len(e.Lines), strings.Join(msgs, "; "))
+}
+
+func (e *CSVImportError) Unwrap() []error {
+	errs := make([]error, len(e.Lines))
+	for i, l := range e.Lines {
+		errs[i] = l
+	}
+	return errs
+}
+
+// ImportNAVsFromCSV importe un historique de NAV au format CSV (colonnes date et valeur)
+// pour un investissement existant. Le séparateur (virgule ou point-virgule), la virgule
+// décimale et une éventuelle ligne d'en-tête sont détectés automatiquement.
+// L'import est tout ou rien : si une ligne est invalide, aucune NAV n'est ajoutée et
+// l'erreur retournée (*CSVImportError) liste toutes les lignes fautives.
+// Retourne le nombre de NAV importées. Le portefeuille est verrouillé en écriture
+// pendant toute la lecture.
+func (p *Portfolio) ImportNAVsFromCSV(r io.Reader, investmentName string) (int, error) {
+	return p.ImportNAVsFromCSVContext(context.Background(), r, investmentName)
+}
+
+// ImportNAVsFromCSVContext est ImportNAVsFromCSV interrompue sans rien importer si ctx
+// est annulé
+func (p *Portfolio) ImportNAVsFromCSVContext(ctx context.Context, r io.Reader, investmentName string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return 0, fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if err := inv.acceptsNAVs(investmentName); err != nil {
+		return 0, err
+	}
+
+	br := bufio.NewReader(r)
+	reader := csv.NewReader(br)
+	reader.Comma = sniffCSVDelimiter(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	dateCol, valueCol := 0, 1
+	existing := make(map[string]bool, len(inv.NAVHistory))
+	for _, nav := range inv.NAVHistory {
+		existing[FormatDate(nav.Date.Time)] = true
+	}
+
+	var navs []NAV
+	importErr := &CSVImportError{}
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("import interrompu à la ligne %d: %w", line, err)
+		}
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				importErr.Lines = append(importErr.Lines, CSVLineError{Line: parseErr.Line, Err: parseErr.Err})
+				continue
+			}
+			return 0, fmt.Errorf("lecture du CSV: %w", err)
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		// Ligne d'en-tête : repérer les colonnes par leur nom
+		if line == 1 && !looksLikeDate(record[0]) {
+			dateCol, valueCol = csvHeaderColumns(record)
+			continue
+		}
+
+		if len(record) <= dateCol || len(record) <= valueCol {
+			importErr.Lines = append(importErr.Lines, CSVLineError{Line: line, Err: fmt.Errorf("%d colonne(s), au moins %d attendues", len(record), max(dateCol, valueCol)+1)})
+			continue
+		}
+
+		nav, err := parseCSVNAV(record[dateCol], record[valueCol])
+		if err != nil {
+			importErr.Lines = append(importErr.Lines, CSVLineError{Line: line, Err: err})
+			continue
+		}
+		key := FormatDate(nav.Date.Time)
+		if existing[key] {
+			importErr.Lines = append(importErr.Lines, CSVLineError{Line: line, Err: fmt.Errorf("une NAV existe déjà au %s", key)})
+			continue
+		}
+		existing[key] = true
+		navs = append(navs, nav)
+	}
+
+	if len(importErr.Lines) > 0 {
+		return 0, importErr
+	}
+
+	// Insertion groupée : seules les NAV importées sont triées, puis fusionnées avec
+	// l'historique en un seul parcours
+	sortNAVs(navs)
+	before := inv.clone()
+	inv.NAVHistory = mergeNAVs(inv.NAVHistory, navs)
+	inv.invalidate()
+	if len(navs) > 0 {
+		p.record(OpImportNAVs, investmentName, fmt.Sprintf("%d NAV importées", len(navs)), before)
+	}
+	p.navsAdded(investmentName, navs...)
+
+	return len(navs), nil
+}
+
+// sniffCSVDelimiter choisit le point-virgule s'il apparaît dans la première ligne
+func sniffCSVDelimiter(br *bufio.Reader) rune {
+	peek, _ := br.Peek(4096)
+	firstLine, _, _ := strings.Cut(string(peek), "\n")
+	if strings.Contains(firstLine, ";") {
+		return ';'
+	}
+	return ','
+}
+
+// csvHeaderColumns retrouve les colonnes date et valeur à partir de l'en-tête
+func csvHeaderColumns(header []string) (dateCol, valueCol int) {
+	dateCol, valueCol = 0, 1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "date":
+			dateCol = i
+		case "value", "valeur", "nav", "vl":
+			valueCol = i
+		}
+	}
+	return dateCol, valueCol
+}
+
+// parseCSVNAV valide et convertit une paire date/valeur lue dans le CSV
+func parseCSVNAV(rawDate, rawValue string) (NAV, error) {
+	date, err := parseFlexibleDate(strings.TrimSpace(rawDate))
+	if err != nil {
+		return NAV{}, err
+	}
+
+	cleaned := strings.ReplaceAll(strings.TrimSpace(rawValue), " ", "")
+	cleaned = strings.TrimSuffix(cleaned, "€")
+	cleaned = strings.Replace(cleaned, ",", ".", 1)
+	value, err := ParseMoney(cleaned)
+	if err != nil {
+		return NAV{}, fmt.Errorf("valeur '%s' invalide", rawValue)
+	}
+	if value <= 0 {
+		return NAV{}, fmt.Errorf("la NAV doit être positive: %w", ErrInvalidAmount)
+	}
+
+	return NAV{Date: Date{date}, Value: value}, nil
+}
+
+// parseFlexibleDate accepte les formats de date courants des exports bancaires
+func parseFlexibleDate(raw string) (time.Time, error) {
+	for _, layout := range csvDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+}
+
+func looksLikeDate(raw string) bool {
+	_, err := parseFlexibleDate(strings.TrimSpace(raw))
+	return err == nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
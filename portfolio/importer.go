@@ -0,0 +1,232 @@
+package portfolio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// importerSniffSize est la taille du début de fichier examiné par Detect
+const importerSniffSize = 4096
+
+// Importer lit un relevé ou un export de courtier et le convertit en Statement, importé
+// ensuite par ImportStatement. Les adaptateurs sont déclarés par RegisterImporter.
+type Importer interface {
+	// Name est le nom de l'adaptateur, utilisé par import-statement --format
+	Name() string
+	// Detect indique si le début du fichier (au plus importerSniffSize octets) est au
+	// format de l'adaptateur
+	Detect(head []byte) bool
+	// Parse lit le relevé complet
+	Parse(r io.Reader, opts StatementOptions) (*Statement, error)
+}
+
+var (
+	importersMu sync.RWMutex
+	importers   []Importer
+)
+
+// RegisterImporter ajoute un adaptateur ; un adaptateur de même nom est remplacé
+func RegisterImporter(imp Importer) {
+	importersMu.Lock()
+	defer importersMu.Unlock()
+
+	for i, existing := range importers {
+		if existing.Name() == imp.Name() {
+			importers[i] = imp
+			return
+		}
+	}
+	importers = append(importers, imp)
+}
+
+// Importers retourne les noms des adaptateurs déclarés, dans l'ordre de détection
+func Importers() []string {
+	importersMu.RLock()
+	defer importersMu.RUnlock()
+
+	names := make([]string, len(importers))
+	for i, imp := range importers {
+		names[i] = imp.Name()
+	}
+	return names
+}
+
+func init() {
+	RegisterImporter(ofxImporter{})
+	RegisterImporter(qifImporter{})
+	RegisterImporter(degiroImporter{})
+	RegisterImporter(boursoramaImporter{})
+	RegisterImporter(ibkrImporter{})
+	RegisterImporter(ppCSVImporter{})
+	RegisterImporter(ppXMLImporter{})
+	RegisterImporter(ghostfolioImporter{})
+}
+
+// ParseStatement lit un relevé avec l'adaptateur désigné par format, ou celui qui
+// reconnaît le début du fichier si format est vide
+func ParseStatement(r io.Reader, format string, opts StatementOptions) (*Statement, error) {
+	br := bufio.NewReaderSize(r, importerSniffSize)
+	head, _ := br.Peek(importerSniffSize)
+	head = bytes.TrimPrefix(head, []byte("\ufeff"))
+
+	importersMu.RLock()
+	candidates := slices.Clone(importers)
+	importersMu.RUnlock()
+
+	for _, imp := range candidates {
+		if format == imp.Name() || (format == "" && imp.Detect(head)) {
+			return imp.Parse(br, opts)
+		}
+	}
+	if format != "" {
+		return nil, InvalidField("format", format, "format de relevé inconnu: %s (%s)", format, strings.Join(Importers(), ", "))
+	}
+	return nil, fmt.Errorf("format de relevé non reconnu, préciser --format (%s)", strings.Join(Importers(), ", "))
+}
+
+// ofxImporter lit les relevés OFX et QFX (voir ParseOFX)
+type ofxImporter struct{}
+
+func (ofxImporter) Name() string { return "ofx" }
+
+func (ofxImporter) Detect(head []byte) bool {
+	upper := bytes.ToUpper(head)
+	return bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>"))
+}
+
+func (ofxImporter) Parse(r io.Reader, _ StatementOptions) (*Statement, error) {
+	return ParseOFX(r)
+}
+
+// qifImporter lit les relevés QIF (voir ParseQIF)
+type qifImporter struct{}
+
+func (qifImporter) Name() string { return "qif" }
+
+func (qifImporter) Detect(head []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(head), []byte("!"))
+}
+
+func (qifImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	opts.Account = DefaultString(opts.Account, "qif")
+	return ParseQIF(r, opts)
+}
+
+// csvTable est un export CSV lu avec son en-tête : les colonnes sont retrouvées par
+// leur nom, quelle que soit leur position
+type csvTable struct {
+	columns map[string]int // Indice de chaque colonne, par nom en minuscules
+	rows    [][]string
+	first   int // Numéro de ligne de la première ligne de données
+}
+
+// readCSVTable lit un CSV dont la première ligne non vide est l'en-tête ; le séparateur
+// est détecté (virgule, point-virgule ou tabulation)
+func readCSVTable(r io.Reader) (*csvTable, error) {
+	br := bufio.NewReader(r)
+	reader := csv.NewReader(br)
+	reader.Comma = sniffCSVDelimiter(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("lecture du CSV: %w", err)
+	}
+
+	t := &csvTable{columns: make(map[string]int)}
+	for i, record := range records {
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(t.columns) == 0 {
+			t.setHeader(record)
+			t.first = i + 2
+			continue
+		}
+		t.rows = append(t.rows, record)
+	}
+	if len(t.columns) == 0 {
+		return nil, fmt.Errorf("CSV vide")
+	}
+	return t, nil
+}
+
+// setHeader enregistre les colonnes d'un en-tête ; une colonne sans nom est ignorée
+func (t *csvTable) setHeader(header []string) {
+	clear(t.columns)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, exists := t.columns[name]; name != "" && !exists {
+			t.columns[name] = i
+		}
+	}
+}
+
+// column retourne l'indice de la première colonne présente parmi names, -1 sinon
+func (t *csvTable) column(names ...string) int {
+	for _, name := range names {
+		if i, ok := t.columns[strings.ToLower(name)]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// get retourne la valeur d'une ligne dans la première colonne présente parmi names
+func (t *csvTable) get(row []string, names ...string) string {
+	if i := t.column(names...); i >= 0 && i < len(row) {
+		return strings.TrimSpace(row[i])
+	}
+	return ""
+}
+
+// require vérifie que l'en-tête contient chacune des colonnes, données par noms possibles
+func (t *csvTable) require(format string, columns ...[]string) error {
+	for _, names := range columns {
+		if t.column(names...) < 0 {
+			return fmt.Errorf("export %s: colonne %s absente", format, names[0])
+		}
+	}
+	return nil
+}
+
+// parseBrokerDate lit une date d'export de courtier dans l'un des formats donnés ; les
+// heures qui suivent la date sont ignorées
+func parseBrokerDate(raw string, layouts ...string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if date, _, found := strings.Cut(raw, ","); found {
+		raw = date
+	}
+	if date, _, found := strings.Cut(raw, " "); found {
+		raw = date
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+}
+
+// absMoney retourne la valeur absolue d'un montant
+func absMoney(m Money) Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// optionalAmount lit un montant facultatif, nul s'il est vide
+func optionalAmount(raw string) (Money, error) {
+	if raw == "" || raw == "-" {
+		return 0, nil
+	}
+	return parseStatementAmount(raw)
+}
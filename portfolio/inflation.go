@@ -0,0 +1,189 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// Inflation décrit l'hypothèse d'inflation du portefeuille : un indice des prix observé
+// et/ou un taux annuel constant, qui prolonge l'indice au-delà de ses bornes (ou le
+// remplace s'il est vide)
+type Inflation struct {
+	Rate  float64 `json:"rate,omitempty"`  // Taux d'inflation annuel constant (%)
+	Index []NAV   `json:"index,omitempty"` // Valeurs de l'indice des prix, triées par date
+}
+
+// SetInflationRate définit le taux d'inflation annuel constant (%)
+func (p *Portfolio) SetInflationRate(rate float64) error {
+	if rate <= -100 {
+		return fmt.Errorf("taux d'inflation invalide: %w", ErrInvalidAmount)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("inflation")
+	if err != nil {
+		return err
+	}
+
+	if p.Inflation == nil {
+		p.Inflation = &Inflation{}
+	}
+	p.Inflation.Rate = rate
+	return p.recordChange(JournalEntry{Op: OpSetInflation, Detail: fmt.Sprintf("%.2f%%", rate), BeforeSettings: before})
+}
+
+// AddInflationIndex ajoute ou remplace une valeur de l'indice des prix
+func (p *Portfolio) AddInflationIndex(date string, value float64) error {
+	if NewMoney(value) <= 0 {
+		return fmt.Errorf("la valeur de l'indice doit être positive: %w", ErrInvalidAmount)
+	}
+	point, err := NewNAV(date, value)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Inflation == nil {
+		p.Inflation = &Inflation{}
+	}
+	index := p.Inflation.Index
+	i := sort.Search(len(index), func(i int) bool { return !index[i].Date.Before(point.Date.Time) })
+	if i < len(index) && index[i].Date.Equal(point.Date.Time) {
+		index[i].Value = point.Value
+		p.changed("add-inflation-index", "")
+		return nil
+	}
+	p.Inflation.Index = append(index, point)
+	sortNAVs(p.Inflation.Index)
+	p.changed("add-inflation-index", "")
+	return nil
+}
+
+// logPriceLevel retourne le logarithme du niveau des prix à une date, à une constante près,
+// le taux constant étant appliqué selon les conventions c
+func (inf *Inflation) logPriceLevel(c analytics.RateConventions, date time.Time) float64 {
+	drift := c.RateLog(inf.Rate)
+	if len(inf.Index) == 0 {
+		// Années depuis l'époque Unix, sans passer par time.Duration (limitée à 292 ans)
+		return drift * float64(date.Unix()) / (365.25 * 24 * 3600)
+	}
+
+	first, last := inf.Index[0], inf.Index[len(inf.Index)-1]
+	switch {
+	case date.Before(first.Date.Time):
+		return math.Log(first.Value.Float64()) - drift*c.YearsBetween(date, first.Date.Time)
+	case date.After(last.Date.Time):
+		return math.Log(last.Value.Float64()) + drift*c.YearsBetween(last.Date.Time, date)
+	default:
+		// La date est dans l'indice : navAt ne peut pas échouer
+		value, _ := navAt(inf.Index, date, InterpolateLinear)
+		return math.Log(value.Float64())
+	}
+}
+
+// factor retourne la hausse cumulée des prix entre deux dates (1,05 pour +5 %)
+func (inf *Inflation) factor(c analytics.RateConventions, from, to time.Time) float64 {
+	return math.Exp(inf.logPriceLevel(c, to) - inf.logPriceLevel(c, from))
+}
+
+// inflation retourne l'hypothèse d'inflation ; l'appelant doit détenir p.mu
+func (p *Portfolio) inflation() (*Inflation, error) {
+	if p.Inflation == nil {
+		return nil, fmt.Errorf("aucune hypothèse d'inflation définie")
+	}
+	return p.Inflation, nil
+}
+
+// RealPerformanceRate retourne le taux annuel de performance (%) d'un investissement
+// corrigé de l'inflation observée sur la même période
+func (p *Portfolio) RealPerformanceRate(investmentName string) (float64, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return 0, fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	inf, err := p.inflation()
+	if err != nil {
+		return 0, err
+	}
+	nominal, err := inv.CalculatePerformanceRate()
+	if err != nil {
+		return 0, err
+	}
+
+	first, last := inv.NAVHistory[0].Date, inv.NAVHistory[len(inv.NAVHistory)-1].Date
+	c := inv.conventions
+	years := c.YearsBetween(first.Time, last.Time)
+	return c.RateFromLog(c.RateLog(nominal) - math.Log(inf.factor(c, first.Time, last.Time))/years), nil
+}
+
+// RealProjectNAV projette la valeur d'un investissement à une date, exprimée en
+// monnaie constante de la date de sa dernière NAV
+func (p *Portfolio) RealProjectNAV(investmentName string, projectionDate string) (float64, error) {
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return 0, fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	inf, err := p.inflation()
+	if err != nil {
+		return 0, err
+	}
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return 0, err
+	}
+	nominal, err := inv.projectNAVAt(t)
+	if err != nil {
+		return 0, err
+	}
+	return nominal / inf.factor(inv.conventions, latestNAV.Date.Time, t), nil
+}
+
+// RealPortfolioValue projette la valeur du portefeuille à une date, exprimée en monnaie
+// constante de la date de la dernière NAV du portefeuille
+func (p *Portfolio) RealPortfolioValue(date string) (map[string]float64, float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	inf, err := p.inflation()
+	if err != nil {
+		return nil, 0, err
+	}
+	values, _, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, asOf := p.historyBounds()
+	deflator := inf.factor(p.rateConventions(), asOf, t)
+	var total Money
+	for name, value := range values {
+		real := NewMoney(value / deflator).RoundCents()
+		values[name] = real.Float64()
+		total += real
+	}
+	return values, total.Float64(), nil
+}
@@ -0,0 +1,328 @@
+package portfolio
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// IngestFormat est le format des enregistrements lus par IngestNAVs
+type IngestFormat string
+
+const (
+	IngestCSV    IngestFormat = "csv"    // Colonnes investissement (facultative), date et valeur
+	IngestNDJSON IngestFormat = "ndjson" // Un objet {"investment", "date", "value"} par ligne
+)
+
+const (
+	DefaultIngestBatch     = 5000 // NAV accumulées avant insertion
+	defaultIngestMaxErrors = 100  // Rejets détaillés conservés dans le rapport
+	maxIngestLine          = 1 << 20
+)
+
+// IngestOptions paramètre une ingestion de NAV
+type IngestOptions struct {
+	Format     IngestFormat         // csv si vide
+	Investment string               // Investissement des enregistrements qui n'en précisent pas
+	BatchSize  int                  // NAV insérées à la fois, DefaultIngestBatch si nul
+	MaxErrors  int                  // Rejets détaillés dans le rapport, defaultIngestMaxErrors si nul ; les suivants sont seulement comptés
+	Progress   func(IngestProgress) // Appelée après chaque lot inséré (facultative)
+}
+
+// IngestProgress est l'avancement d'une ingestion
+type IngestProgress struct {
+	Records  int // Enregistrements lus
+	Ingested int // NAV insérées ou remplacées
+	Rejected int // Enregistrements rejetés
+	Merged   int // Dates dont les NAV en double ont été réduites à une seule
+}
+
+// IngestReport est le bilan d'une ingestion : avancement final et premiers rejets
+type IngestReport struct {
+	IngestProgress
+	Errors []CSVLineError // Au plus MaxErrors rejets, lot par lot
+	Merges []NAVMerge     // Au plus MaxErrors fusions de NAV d'une même date, doublons et conflits résolus
+}
+
+// ingestRecord est une NAV lue et sa ligne d'origine
+type ingestRecord struct {
+	line       int
+	investment string
+	nav        NAV
+}
+
+// ingester accumule les enregistrements valides par lots et tient le bilan
+type ingester struct {
+	p      *Portfolio
+	opts   IngestOptions
+	batch  []ingestRecord
+	report IngestReport
+}
+
+// IngestNAVs lit en flux des NAV au format CSV ou NDJSON et les insère par lots de
+// BatchSize : la mémoire utilisée ne dépend pas de la taille de l'entrée, et le
+// portefeuille n'est verrouillé que le temps d'insérer chaque lot. Contrairement à
+// ImportNAVsFromCSV, les enregistrements invalides (date ou valeur incorrecte,
+// investissement inconnu, doublon refusé par DuplicateNAVPolicy) sont rejetés un à un et
+// listés dans le rapport, les autres étant insérés. Si ctx est annulé, la lecture
+// s'arrête : les lots déjà insérés sont conservés.
+func (p *Portfolio) IngestNAVs(ctx context.Context, r io.Reader, opts IngestOptions) (IngestReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultIngestBatch
+	}
+	if opts.MaxErrors <= 0 {
+		opts.MaxErrors = defaultIngestMaxErrors
+	}
+	in := &ingester{p: p, opts: opts}
+
+	var err error
+	switch opts.Format {
+	case IngestCSV, "":
+		err = in.readCSV(ctx, r)
+	case IngestNDJSON:
+		err = in.readNDJSON(ctx, r)
+	default:
+		return IngestReport{}, InvalidField("format", opts.Format, "format d'ingestion inconnu: %s (csv, ndjson)", opts.Format)
+	}
+	if err == nil {
+		in.flush()
+	}
+	return in.report, err
+}
+
+// reject compte un enregistrement rejeté et le détaille tant que MaxErrors n'est pas atteint
+func (in *ingester) reject(line int, err error) {
+	in.report.Rejected++
+	if len(in.report.Errors) < in.opts.MaxErrors {
+		in.report.Errors = append(in.report.Errors, CSVLineError{Line: line, Err: err})
+	}
+}
+
+// add ajoute un enregistrement valide au lot, inséré dès qu'il est plein
+func (in *ingester) add(line int, investment string, nav NAV) {
+	in.report.Records++
+	if investment == "" {
+		investment = in.opts.Investment
+	}
+	if investment == "" {
+		in.reject(line, fmt.Errorf("investissement non précisé"))
+		return
+	}
+	in.batch = append(in.batch, ingestRecord{line: line, investment: investment, nav: nav})
+	if len(in.batch) >= in.opts.BatchSize {
+		in.flush()
+	}
+}
+
+// flush insère le lot courant, investissement par investissement, en fusionnant les NAV
+// triées avec l'historique
+func (in *ingester) flush() {
+	if len(in.batch) > 0 {
+		// Regrouper par investissement puis par date, en gardant l'ordre de lecture
+		slices.SortStableFunc(in.batch, func(a, b ingestRecord) int {
+			if c := strings.Compare(a.investment, b.investment); c != 0 {
+				return c
+			}
+			return a.nav.Date.Compare(b.nav.Date.Time)
+		})
+
+		in.p.mu.Lock()
+		for start := 0; start < len(in.batch); {
+			end := start + 1
+			for end < len(in.batch) && in.batch[end].investment == in.batch[start].investment {
+				end++
+			}
+			in.insert(in.batch[start:end])
+			start = end
+		}
+		in.p.mu.Unlock()
+		in.batch = in.batch[:0]
+	}
+
+	if in.opts.Progress != nil {
+		in.opts.Progress(in.report.IngestProgress)
+	}
+}
+
+// insert ajoute les NAV d'un même investissement, triées par date. Les NAV d'une même
+// date, lues ou déjà enregistrées, sont réduites à une seule par resolveDuplicates selon
+// la politique de doublon du portefeuille. L'appelant doit détenir p.mu.
+func (in *ingester) insert(records []ingestRecord) {
+	name := records[0].investment
+	inv, exists := in.p.Investments[name]
+	if !exists {
+		for _, rec := range records {
+			in.reject(rec.line, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound))
+		}
+		return
+	}
+
+	navs := make([]NAV, 0, len(records))
+	var replaced []NAV
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].nav.Date.Equal(records[start].nav.Date.Time) {
+			end++
+		}
+		group := records[start:end]
+		start = end
+
+		date := group[0].nav.Date
+		i, found := inv.navIndex(date.Time)
+		if !found && len(group) == 1 {
+			navs = append(navs, group[0].nav)
+			continue
+		}
+		var values []Money
+		if found {
+			values = append(values, inv.NAVHistory[i].Value)
+		}
+		for _, rec := range group {
+			values = append(values, rec.nav.Value)
+		}
+		m, err := resolveDuplicates(in.p.DuplicateNAVPolicy, values)
+		m.Investment, m.Date = name, date.Time
+		if err != nil {
+			for _, rec := range group {
+				in.reject(rec.line, fmt.Errorf("NAV de %s au %s: %w", name, FormatDate(date.Time), err))
+			}
+			continue
+		}
+		in.report.Merged++
+		if len(in.report.Merges) < in.opts.MaxErrors {
+			in.report.Merges = append(in.report.Merges, m)
+		}
+		nav := NAV{Date: date, Value: m.Kept}
+		switch {
+		case !found:
+			navs = append(navs, nav)
+		case inv.NAVHistory[i].Value != m.Kept:
+			inv.NAVHistory[i].Value = m.Kept
+			replaced = append(replaced, nav)
+		}
+	}
+	in.report.Ingested += len(navs) + len(replaced)
+	inv.NAVHistory = mergeNAVs(inv.NAVHistory, navs)
+	inv.invalidate()
+	if len(navs) > 0 || len(replaced) > 0 {
+		in.p.navsAdded(name, append(replaced, navs...)...)
+	}
+}
+
+// readCSV lit des lignes investissement (facultatif), date et valeur. Sans en-tête, une
+// ligne de deux colonnes porte la date et la valeur, une ligne de trois colonnes
+// l'investissement, la date et la valeur.
+func (in *ingester) readCSV(ctx context.Context, r io.Reader) error {
+	br := bufio.NewReader(r)
+	reader := csv.NewReader(br)
+	reader.Comma = sniffCSVDelimiter(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	nameCol, dateCol, valueCol := -1, 0, 1
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("ingestion interrompue à la ligne %d: %w", line, err)
+		}
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				in.report.Records++
+				in.reject(parseErr.Line, parseErr.Err)
+				continue
+			}
+			return fmt.Errorf("lecture du CSV: %w", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		if line == 1 && !looksLikeDate(record[0]) && (len(record) < 3 || !looksLikeDate(record[1])) {
+			dateCol, valueCol = csvHeaderColumns(record)
+			nameCol = csvNameColumn(record)
+			continue
+		}
+		if line == 1 && len(record) >= 3 {
+			nameCol, dateCol, valueCol = 0, 1, 2
+		}
+
+		if len(record) <= dateCol || len(record) <= valueCol || len(record) <= nameCol {
+			in.report.Records++
+			in.reject(line, fmt.Errorf("%d colonne(s), au moins %d attendues", len(record), max(nameCol, dateCol, valueCol)+1))
+			continue
+		}
+		nav, err := parseCSVNAV(record[dateCol], record[valueCol])
+		if err != nil {
+			in.report.Records++
+			in.reject(line, err)
+			continue
+		}
+		var name string
+		if nameCol >= 0 {
+			name = strings.TrimSpace(record[nameCol])
+		}
+		in.add(line, name, nav)
+	}
+}
+
+// csvNameColumn retrouve la colonne de l'investissement dans l'en-tête, -1 si absente
+func csvNameColumn(header []string) int {
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "investment", "investissement", "name", "nom":
+			return i
+		}
+	}
+	return -1
+}
+
+// ndjsonNAV est un enregistrement NDJSON
+type ndjsonNAV struct {
+	Investment string          `json:"investment"`
+	Date       string          `json:"date"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// readNDJSON lit un objet JSON par ligne ; les lignes vides sont ignorées
+func (in *ingester) readNDJSON(ctx context.Context, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxIngestLine)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("ingestion interrompue à la ligne %d: %w", line, err)
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec ndjsonNAV
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			in.report.Records++
+			in.reject(line, fmt.Errorf("JSON invalide: %v", err))
+			continue
+		}
+		// Les valeurs sont acceptées en nombre ou en chaîne, comme dans un CSV
+		nav, err := parseCSVNAV(rec.Date, strings.Trim(string(rec.Value), `"`))
+		if err != nil {
+			in.report.Records++
+			in.reject(line, err)
+			continue
+		}
+		in.add(line, rec.Investment, nav)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("lecture du NDJSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// InterpolationMode détermine comment NAVAt valorise une date sans NAV
+type InterpolationMode string
+
+const (
+	// InterpolateLastKnown retient la dernière NAV connue à la date
+	InterpolateLastKnown InterpolationMode = "last-known"
+	// InterpolateLinear interpole linéairement entre les NAV encadrantes ; au-delà de
+	// la dernière NAV, sa valeur est retenue (pas d'extrapolation)
+	InterpolateLinear InterpolationMode = "linear"
+	// InterpolateStrict exige une NAV datée exactement du jour demandé
+	InterpolateStrict InterpolationMode = "strict"
+)
+
+// NAVAt retourne la valorisation de l'investissement à une date passée selon le mode
+// d'interpolation. Une date antérieure à la première NAV renvoie ErrNAVNotFound.
+func (inv *Investment) NAVAt(date string, mode InterpolationMode) (NAV, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return NAV{}, err
+	}
+	value, err := navAt(inv.NAVHistory, t, mode)
+	if err != nil {
+		return NAV{}, fmt.Errorf("%s au %s: %w", inv.Name, date, err)
+	}
+	return NAV{Date: Date{t}, Value: value}, nil
+}
+
+// navAt valorise une date à partir d'une série de NAV triée
+func navAt(navs []NAV, date time.Time, mode InterpolationMode) (Money, error) {
+	i := sort.Search(len(navs), func(i int) bool { return navs[i].Date.After(date) })
+	if i == 0 {
+		return 0, fmt.Errorf("aucune NAV à cette date ou avant: %w", ErrNAVNotFound)
+	}
+	before := navs[i-1]
+
+	switch mode {
+	case InterpolateStrict:
+		if !before.Date.Equal(date) {
+			return 0, fmt.Errorf("aucune NAV datée de ce jour: %w", ErrNAVNotFound)
+		}
+		return before.Value, nil
+
+	case InterpolateLastKnown:
+		return before.Value, nil
+
+	case InterpolateLinear:
+		if i == len(navs) || before.Date.Equal(date) {
+			return before.Value, nil
+		}
+		after := navs[i]
+		weight := date.Sub(before.Date.Time).Hours() / after.Date.Sub(before.Date.Time).Hours()
+		return before.Value + NewMoney(weight*(after.Value-before.Value).Float64()), nil
+
+	default:
+		return 0, InvalidField("interpolation", mode, "mode d'interpolation inconnu: %s", mode)
+	}
+}
@@ -0,0 +1,106 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// ProjectionInterval est une valeur projetée accompagnée de son intervalle de confiance
+type ProjectionInterval struct {
+	Value float64 `json:"value"` // Projection centrale (médiane de la loi log-normale)
+	Low   float64 `json:"low"`   // Borne basse
+	High  float64 `json:"high"`  // Borne haute
+}
+
+// logNormalInterval encadre value selon une loi log-normale de volatilité annuelle
+// volatility (logarithmique) sur years années, au niveau de confiance bilatéral confidence
+func logNormalInterval(value, volatility, years, confidence float64) ProjectionInterval {
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	spread := z * volatility * math.Sqrt(math.Max(years, 0))
+	return ProjectionInterval{Value: value, Low: value * math.Exp(-spread), High: value * math.Exp(spread)}
+}
+
+// validateConfidence vérifie qu'un niveau de confiance est strictement compris entre 0 et 1
+func validateConfidence(confidence float64) error {
+	if !(confidence > 0 && confidence < 1) {
+		return InvalidField("confidence", confidence, "le niveau de confiance doit être compris entre 0 et 1 (ex. 0.9)")
+	}
+	return nil
+}
+
+// ProjectNAVInterval projette la valeur à une date avec un intervalle de confiance
+// (ex. 0.9 pour 90 %) déduit de la volatilité historique des rendements
+func (inv *Investment) ProjectNAVInterval(projectionDate string, confidence float64) (ProjectionInterval, error) {
+	if err := validateConfidence(confidence); err != nil {
+		return ProjectionInterval{}, err
+	}
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return ProjectionInterval{}, err
+	}
+	return inv.projectNAVIntervalAt(t, confidence)
+}
+
+func (inv *Investment) projectNAVIntervalAt(t time.Time, confidence float64) (ProjectionInterval, error) {
+	returns := inv.periodReturns()
+	if len(returns) < 2 {
+		return ProjectionInterval{}, fmt.Errorf("au moins 3 NAV sont nécessaires pour estimer la dispersion: %w", ErrInsufficientHistory)
+	}
+	value, err := inv.projectNAVAt(t)
+	if err != nil {
+		return ProjectionInterval{}, err
+	}
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return ProjectionInterval{}, err
+	}
+	return logNormalInterval(value, inv.volatility(), inv.conventions.YearsBetween(latestNAV.Date.Time, t), confidence), nil
+}
+
+// GetPortfolioValueInterval calcule, comme GetPortfolioValue, la valeur projetée de
+// chaque investissement et du total, avec un intervalle de confiance. Un investissement
+// à l'historique trop court pour estimer sa volatilité a un intervalle réduit à sa valeur.
+// L'intervalle du total repose sur la volatilité du portefeuille, ce qui tient compte de
+// la diversification ; à défaut, il somme les bornes des investissements.
+func (p *Portfolio) GetPortfolioValueInterval(date string, confidence float64) (map[string]ProjectionInterval, ProjectionInterval, error) {
+	if err := validateConfidence(confidence); err != nil {
+		return nil, ProjectionInterval{}, err
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, ProjectionInterval{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values, totalValue, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, ProjectionInterval{}, err
+	}
+
+	intervals := make(map[string]ProjectionInterval, len(values))
+	summed := ProjectionInterval{Value: totalValue}
+	for name, value := range values {
+		inv := p.Investments[name]
+		interval := ProjectionInterval{Value: value, Low: value, High: value}
+		if raw, err := inv.projectNAVIntervalAt(t, confidence); err == nil && raw.Value > 0 {
+			// Les bornes suivent la conversion en devise de consolidation de la valeur centrale
+			interval.Low = value * raw.Low / raw.Value
+			interval.High = value * raw.High / raw.Value
+		}
+		intervals[name] = interval
+		summed.Low += interval.Low
+		summed.High += interval.High
+	}
+
+	returns, err := p.alignedReturns(time.Time{}, time.Time{})
+	if err != nil || len(returns) < 2 {
+		return intervals, summed, nil
+	}
+	_, asOf := p.historyBounds()
+	return intervals, logNormalInterval(totalValue, analytics.AnnualVolatility(returns), p.rateConventions().YearsBetween(asOf, t), confidence), nil
+}
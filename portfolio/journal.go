@@ -0,0 +1,431 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// JournalOp est le type d'une modification enregistrée dans le journal
+type JournalOp string
+
+const (
+	OpAddInvestment JournalOp = "add-investment"
+	OpAddNAV        JournalOp = "add-nav"
+	OpUpdateNAV     JournalOp = "update-nav"
+	OpDeleteNAV     JournalOp = "delete-nav"
+	OpAddCashFlow   JournalOp = "add-cash-flow"
+	OpCompactNAVs   JournalOp = "compact-navs"
+	OpSell          JournalOp = "sell"
+	OpSetOwner      JournalOp = "set-owner"
+
+	OpRemoveInvestment JournalOp = "remove-investment"
+	OpRenameInvestment JournalOp = "rename-investment"
+	OpCloseInvestment  JournalOp = "close-investment"
+	OpReopenInvestment JournalOp = "reopen-investment"
+	OpAddTransaction   JournalOp = "add-transaction"
+	OpAddDistribution  JournalOp = "add-distribution"
+	OpSetTag           JournalOp = "set-tag"
+	OpSetRatePolicy    JournalOp = "set-rate-policy"
+	OpSetFeeSchedule   JournalOp = "set-fees"
+	OpSetProjection    JournalOp = "set-projection"
+	OpSetCurrency      JournalOp = "set-currency"
+	OpSetIdentifiers   JournalOp = "set-identifiers"
+	OpSetLiquidity     JournalOp = "set-liquidity"
+	OpSetPlan          JournalOp = "set-plan"
+	OpSetTaxWrapper    JournalOp = "set-tax-wrapper"
+	OpSetVesting       JournalOp = "set-vesting"
+	OpSetBenchmark     JournalOp = "set-benchmark"
+	OpSetCashRate      JournalOp = "set-cash-rate"
+	OpSetExposure      JournalOp = "set-exposure"
+	OpImportNAVs       JournalOp = "import-navs"
+	OpAddHolding       JournalOp = "add-holding"
+	OpAddHoldingNAV    JournalOp = "add-holding-nav"
+	OpRollUp           JournalOp = "roll-up"
+
+	// Réglages du portefeuille (voir portfolioSettings)
+	OpSetConventions        JournalOp = "set-conventions"
+	OpSetCalendar           JournalOp = "set-calendar"
+	OpSetTarget             JournalOp = "set-target"
+	OpSetGlidePath          JournalOp = "set-glide-path"
+	OpSetLocale             JournalOp = "set-locale"
+	OpSetInflation          JournalOp = "set-inflation"
+	OpSetRiskFreeRate       JournalOp = "set-risk-free-rate"
+	OpSetMissingNAVPolicy   JournalOp = "set-missing-nav-policy"
+	OpSetDuplicateNAVPolicy JournalOp = "set-duplicate-nav-policy"
+	OpSetScenario           JournalOp = "set-scenario"
+	OpSetStatementRules     JournalOp = "set-statement-rules"
+	OpSetTaxSettings        JournalOp = "set-tax"
+	OpSetForecastRecording  JournalOp = "set-forecast-recording"
+	OpWatch                 JournalOp = "watch"
+	OpUnwatch               JournalOp = "unwatch"
+	OpAddWatchPrice         JournalOp = "add-watch-price"
+)
+
+// journalUndoDepth est le nombre de modifications récentes dont les états sont conservés
+// pour Undo et Redo ; les plus anciennes ne gardent que leur description
+const journalUndoDepth = 100
+
+// JournalEntry est une modification du portefeuille. Change décrit celle de
+// l'investissement (nil s'il n'a pas changé ou si ses états ne sont plus conservés) ;
+// BeforeSettings et AfterSettings sont les états des réglages du portefeuille qu'elle a
+// modifiés. Investment est vide pour une modification des seuls réglages.
+type JournalEntry struct {
+	Time           time.Time                  `json:"time"`
+	Op             JournalOp                  `json:"op"`
+	Investment     string                     `json:"investment"`
+	Renamed        string                     `json:"renamed,omitempty"` // Nouveau nom d'un investissement renommé, sous lequel il est enregistré après la modification
+	Detail         string                     `json:"detail,omitempty"`
+	UndoneAt       *time.Time                 `json:"undone_at,omitempty"` // Date d'annulation, nil si la modification est en vigueur
+	Discarded      bool                       `json:"discarded,omitempty"` // Annulée puis remplacée par une autre modification : ne peut plus être rétablie
+	Before         *Investment                `json:"-"`                   // État précédent de l'investissement, remplacé par Change à l'inscription (nil s'il n'existait pas)
+	Change         *InvestmentChange          `json:"change,omitempty"`
+	BeforeSettings map[string]json.RawMessage `json:"before_settings,omitempty"` // Par clé de portfolioSettings
+	AfterSettings  map[string]json.RawMessage `json:"after_settings,omitempty"`
+}
+
+// journalEntryAlias permet de lire JournalEntry sans rappeler sa propre méthode UnmarshalJSON
+type journalEntryAlias JournalEntry
+
+// UnmarshalJSON lit une entrée du journal. Les fichiers antérieurs à InvestmentChange
+// enregistraient les deux états complets de l'investissement, convertis ici en
+// modification.
+func (e *JournalEntry) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		journalEntryAlias
+		Before *Investment `json:"before"`
+		After  *Investment `json:"after"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = JournalEntry(raw.journalEntryAlias)
+	if e.Change == nil && (raw.Before != nil || raw.After != nil) {
+		change, err := diffInvestments(raw.Before, raw.After)
+		if err != nil {
+			return fmt.Errorf("journal %s %s: %w", e.Op, e.Investment, err)
+		}
+		e.Change = change
+	}
+	return nil
+}
+
+// restorable indique si les états de l'entrée sont encore conservés pour Undo et Redo
+func (e *JournalEntry) restorable() bool {
+	return e.Change != nil || e.BeforeSettings != nil || e.AfterSettings != nil
+}
+
+// dropStates retire les états de l'entrée, dont seule la description reste
+func (e *JournalEntry) dropStates() {
+	e.Before, e.Change = nil, nil
+	e.BeforeSettings, e.AfterSettings = nil, nil
+}
+
+// portfolioSettings associe à chaque réglage journalisé du portefeuille le champ qui le
+// porte, sous la clé JSON du champ (forecast_recording : l'indicateur Record de forecasts)
+var portfolioSettings = map[string]func(p *Portfolio) any{
+	"duplicate_nav_policy": func(p *Portfolio) any { return &p.DuplicateNAVPolicy },
+	"missing_nav_policy":   func(p *Portfolio) any { return &p.MissingNAVPolicy },
+	"target_allocation":    func(p *Portfolio) any { return &p.TargetAllocation },
+	"scenarios":            func(p *Portfolio) any { return &p.Scenarios },
+	"risk_free_rate":       func(p *Portfolio) any { return &p.RiskFreeRate },
+	"inflation":            func(p *Portfolio) any { return &p.Inflation },
+	"locale":               func(p *Portfolio) any { return &p.Locale },
+	"alert_rules":          func(p *Portfolio) any { return &p.AlertRules },
+	"tax":                  func(p *Portfolio) any { return &p.Tax },
+	"conventions":          func(p *Portfolio) any { return &p.Conventions },
+	"calendar":             func(p *Portfolio) any { return &p.Calendar },
+	"statement_rules":      func(p *Portfolio) any { return &p.StatementRules },
+	"owner":                func(p *Portfolio) any { return &p.Owner },
+	"watchlist":            func(p *Portfolio) any { return &p.Watchlist },
+	"forecast_recording": func(p *Portfolio) any {
+		if p.Forecasts == nil {
+			p.Forecasts = &ForecastStore{}
+		}
+		return &p.Forecasts.Record
+	},
+}
+
+// settingsState retourne la forme sérialisée des réglages keys (voir portfolioSettings) ;
+// l'appelant doit détenir p.mu
+func (p *Portfolio) settingsState(keys ...string) (map[string]json.RawMessage, error) {
+	state := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		raw, err := json.Marshal(portfolioSettings[key](p))
+		if err != nil {
+			return nil, fmt.Errorf("réglage %s: %w", key, err)
+		}
+		state[key] = raw
+	}
+	return state, nil
+}
+
+// applySettings remplace les réglages par leur forme sérialisée puis rattache les
+// investissements aux conventions et au calendrier ; l'appelant doit détenir p.mu
+func (p *Portfolio) applySettings(state map[string]json.RawMessage) error {
+	for _, key := range slices.Sorted(maps.Keys(state)) {
+		setting, known := portfolioSettings[key]
+		if !known {
+			return fmt.Errorf("réglage inconnu: %s", key)
+		}
+		field := reflect.ValueOf(setting(p)).Elem()
+		field.SetZero()
+		if err := json.Unmarshal(state[key], field.Addr().Interface()); err != nil {
+			return fmt.Errorf("réglage %s: %w", key, err)
+		}
+	}
+	p.attachAll()
+	return nil
+}
+
+// sameSettings indique si les réglages sont dans l'état sérialisé donné ; l'appelant doit
+// détenir p.mu
+func (p *Portfolio) sameSettings(state map[string]json.RawMessage) bool {
+	for key, raw := range state {
+		if _, known := portfolioSettings[key]; !known {
+			return false
+		}
+		current, err := p.settingsState(key)
+		if err != nil {
+			return false
+		}
+		var a, b bytes.Buffer
+		if json.Compact(&a, current[key]) != nil || json.Compact(&b, raw) != nil || !bytes.Equal(a.Bytes(), b.Bytes()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Journal est l'historique des modifications du portefeuille, du plus ancien au plus récent.
+// Les modifications annulées restent inscrites pour l'audit.
+type Journal struct {
+	Entries []JournalEntry `json:"entries"`
+}
+
+// investmentState retourne une copie de l'investissement, nil s'il n'existe pas ;
+// l'appelant doit détenir p.mu
+func (p *Portfolio) investmentState(name string) *Investment {
+	if inv, exists := p.Investments[name]; exists {
+		return inv.clone()
+	}
+	return nil
+}
+
+// record inscrit une modification au journal à partir de l'état précédent de
+// l'investissement. Les modifications annulées ne peuvent plus être rétablies ensuite.
+// L'appelant doit détenir p.mu.
+func (p *Portfolio) record(op JournalOp, name, detail string, before *Investment) {
+	p.journal(JournalEntry{Op: op, Investment: name, Detail: detail, Before: before})
+}
+
+// recordChange inscrit au journal une modification décrite par son opération, son
+// investissement (éventuellement renommé en Renamed) et ses états précédents Before et
+// BeforeSettings, en relevant les états suivants des réglages. Si les réglages ne peuvent être
+// sérialisés, la modification est défaite et l'erreur retournée. L'appelant doit
+// détenir p.mu.
+func (p *Portfolio) recordChange(entry JournalEntry) error {
+	current := entry.Investment
+	if entry.Renamed != "" {
+		current = entry.Renamed
+	}
+	after, err := p.settingsState(slices.Sorted(maps.Keys(entry.BeforeSettings))...)
+	if err != nil {
+		if entry.Investment != "" {
+			delete(p.Investments, current)
+			if entry.Before != nil {
+				p.Investments[entry.Investment] = entry.Before
+			}
+		}
+		if undoErr := p.applySettings(entry.BeforeSettings); undoErr != nil {
+			return fmt.Errorf("%w (réglages non rétablis: %v)", err, undoErr)
+		}
+		p.linkRecurringPlans()
+		return err
+	}
+	if entry.BeforeSettings != nil {
+		entry.AfterSettings = after
+	}
+	p.journal(entry)
+	return nil
+}
+
+// journal ajoute une entrée datée au journal et diffuse la modification. L'état précédent
+// Before de l'investissement est remplacé par sa modification jusqu'à l'état courant ; si
+// elle ne peut être calculée, l'entrée est inscrite sans pouvoir être annulée. L'appelant
+// doit détenir p.mu.
+func (p *Portfolio) journal(entry JournalEntry) {
+	if p.Journal == nil {
+		p.Journal = &Journal{}
+	}
+	if entry.Investment != "" {
+		change, err := diffInvestments(entry.Before, p.Investments[entry.current()])
+		if err != nil {
+			Logger().Warn("modification non annulable", "op", entry.Op, "investment", entry.Investment, "error", err)
+		}
+		entry.Change = change
+	}
+	entry.Before = nil
+	entries := p.Journal.Entries
+	for i := range entries {
+		if entries[i].UndoneAt != nil {
+			entries[i].Discarded = true
+			entries[i].dropStates()
+		}
+	}
+	entry.Time = time.Now().UTC()
+	p.Journal.Entries = append(entries, entry)
+
+	kept := 0
+	for i := len(p.Journal.Entries) - 1; i >= 0; i-- {
+		entry := &p.Journal.Entries[i]
+		if !entry.restorable() {
+			continue
+		}
+		if kept++; kept > journalUndoDepth {
+			entry.dropStates()
+		}
+	}
+	p.changed(string(entry.Op), p.Journal.Entries[len(p.Journal.Entries)-1].current())
+}
+
+// current retourne le nom de l'investissement de l'entrée après la modification
+func (e *JournalEntry) current() string {
+	if e.Renamed != "" {
+		return e.Renamed
+	}
+	return e.Investment
+}
+
+// Undo annule la dernière modification en vigueur et la retourne
+func (p *Portfolio) Undo() (JournalEntry, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Journal != nil {
+		for i := len(p.Journal.Entries) - 1; i >= 0; i-- {
+			entry := &p.Journal.Entries[i]
+			if entry.UndoneAt != nil {
+				continue
+			}
+			if err := p.restore(entry, true); err != nil {
+				return JournalEntry{}, err
+			}
+			now := time.Now().UTC()
+			entry.UndoneAt = &now
+			p.changed("undo", entry.Investment)
+			p.valueChanged(entry.Investment)
+			return *entry, nil
+		}
+	}
+	return JournalEntry{}, fmt.Errorf("aucune modification à annuler")
+}
+
+// Redo rétablit la dernière modification annulée et la retourne
+func (p *Portfolio) Redo() (JournalEntry, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Journal != nil {
+		// Les annulations se font de la plus récente à la plus ancienne : la dernière
+		// annulée est donc la plus ancienne des modifications encore rétablissables
+		for i := range p.Journal.Entries {
+			entry := &p.Journal.Entries[i]
+			if entry.UndoneAt == nil || entry.Discarded {
+				continue
+			}
+			if err := p.restore(entry, false); err != nil {
+				return JournalEntry{}, err
+			}
+			entry.UndoneAt = nil
+			p.changed("redo", entry.current())
+			p.valueChanged(entry.current())
+			return *entry, nil
+		}
+	}
+	return JournalEntry{}, fmt.Errorf("aucune modification à rétablir")
+}
+
+// restore ramène l'investissement et les réglages de l'entrée de leur état après la
+// modification à leur état avant (undo), ou l'inverse pour la rétablir, en appliquant
+// Change à l'investissement courant. Un investissement ou un réglage modifié depuis par
+// une opération non journalisée n'est pas écrasé. L'appelant doit détenir p.mu.
+func (p *Portfolio) restore(entry *JournalEntry, undo bool) error {
+	if !entry.restorable() {
+		return fmt.Errorf("la modification du %s est trop ancienne pour être annulée ou rétablie", entry.Time.Format(time.DateTime))
+	}
+	from, to := entry.Investment, entry.Investment
+	if entry.Renamed != "" {
+		to = entry.Renamed
+	}
+	expectedSettings, targetSettings := entry.BeforeSettings, entry.AfterSettings
+	if undo {
+		from, to = to, from
+		expectedSettings, targetSettings = targetSettings, expectedSettings
+	}
+
+	var target *Investment
+	if entry.Investment != "" {
+		if entry.Change == nil {
+			return fmt.Errorf("la modification du %s est trop ancienne pour être annulée ou rétablie", entry.Time.Format(time.DateTime))
+		}
+		var err error
+		if target, err = entry.Change.apply(p.Investments[from], undo); errors.Is(err, errInvestmentModified) {
+			return fmt.Errorf("l'investissement '%s' a été modifié depuis (%s %s)", from, entry.Op, entry.Detail)
+		} else if err != nil {
+			return fmt.Errorf("%s %s: %w", entry.Op, entry.Detail, err)
+		}
+		if _, taken := p.Investments[to]; taken && to != from {
+			return fmt.Errorf("l'investissement '%s' existe de nouveau (%s %s): %w", to, entry.Op, entry.Detail, ErrInvestmentExists)
+		}
+	}
+	if !p.sameSettings(expectedSettings) {
+		return fmt.Errorf("les réglages du portefeuille ont été modifiés depuis (%s %s)", entry.Op, entry.Detail)
+	}
+
+	p.revision.Add(1)
+	if err := p.applySettings(targetSettings); err != nil {
+		return err
+	}
+	if entry.Investment == "" {
+		return nil
+	}
+	delete(p.Investments, from)
+	if target != nil {
+		p.attach(target)
+		p.Investments[to] = target
+	}
+	p.linkRecurringPlans()
+	return nil
+}
+
+// sameInvestment compare deux états d'investissement par leur forme sérialisée
+func sameInvestment(a, b *Investment) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// JournalEntries retourne une copie du journal, sans les états des investissements
+func (p *Portfolio) JournalEntries() []JournalEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.Journal == nil {
+		return nil
+	}
+	entries := make([]JournalEntry, len(p.Journal.Entries))
+	for i, entry := range p.Journal.Entries {
+		entry.dropStates()
+		entries[i] = entry
+	}
+	return entries
+}
@@ -0,0 +1,196 @@
+package portfolio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// InvestmentChange est la modification d'un investissement inscrite au journal : les
+// champs de sa forme sérialisée qui diffèrent entre ses états avant et après, et
+// l'empreinte de chacun de ces états. Une NAV ajoutée n'enregistre ainsi que cette NAV,
+// et non deux copies de l'historique.
+type InvestmentChange struct {
+	BeforeSum string                 `json:"before_sum,omitempty"` // Empreinte SHA-256 de l'état avant, vide si l'investissement n'existait pas
+	AfterSum  string                 `json:"after_sum,omitempty"`  // Empreinte SHA-256 de l'état après, vide s'il a été retiré
+	Fields    map[string]FieldChange `json:"fields,omitempty"`     // Par clé JSON du champ modifié
+}
+
+// FieldChange est la modification d'un champ d'un investissement. Pour un tableau
+// modifié en place, At est l'indice à partir duquel les éléments Before ont été remplacés
+// par les éléments After ; sinon Before et After sont les valeurs du champ, absentes s'il
+// était omis.
+type FieldChange struct {
+	At     *int            `json:"at,omitempty"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// errInvestmentModified signale un investissement qui n'est plus dans l'état laissé par
+// une modification du journal
+var errInvestmentModified = errors.New("investissement modifié depuis")
+
+// investmentFields retourne les champs sérialisés de l'investissement et l'empreinte de
+// sa forme sérialisée, nil et une empreinte vide pour un investissement absent
+func investmentFields(inv *Investment) (map[string]json.RawMessage, string, error) {
+	if inv == nil {
+		return nil, "", nil
+	}
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return nil, "", err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	return fields, hex.EncodeToString(sum[:]), nil
+}
+
+// diffInvestments retourne la modification qui fait passer l'investissement de before à
+// after, l'un ou l'autre pouvant être nil
+func diffInvestments(before, after *Investment) (*InvestmentChange, error) {
+	b, beforeSum, err := investmentFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, afterSum, err := investmentFields(after)
+	if err != nil {
+		return nil, err
+	}
+	change := &InvestmentChange{BeforeSum: beforeSum, AfterSum: afterSum}
+	keys := slices.Collect(maps.Keys(b))
+	for key := range a {
+		if _, exists := b[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if bytes.Equal(b[key], a[key]) {
+			continue
+		}
+		field, err := diffField(b[key], a[key])
+		if err != nil {
+			return nil, fmt.Errorf("champ %s: %w", key, err)
+		}
+		if change.Fields == nil {
+			change.Fields = make(map[string]FieldChange)
+		}
+		change.Fields[key] = field
+	}
+	return change, nil
+}
+
+// diffField retourne la modification d'un champ : les seuls éléments remplacés si le
+// champ est un tableau avant et après, sa valeur entière sinon
+func diffField(before, after json.RawMessage) (FieldChange, error) {
+	b, okB := jsonArray(before)
+	a, okA := jsonArray(after)
+	if !okB || !okA {
+		return FieldChange{Before: before, After: after}, nil
+	}
+	start := 0
+	for start < len(b) && start < len(a) && bytes.Equal(b[start], a[start]) {
+		start++
+	}
+	endB, endA := len(b), len(a)
+	for endB > start && endA > start && bytes.Equal(b[endB-1], a[endA-1]) {
+		endB--
+		endA--
+	}
+	removed, err := json.Marshal(b[start:endB])
+	if err != nil {
+		return FieldChange{}, err
+	}
+	inserted, err := json.Marshal(a[start:endA])
+	if err != nil {
+		return FieldChange{}, err
+	}
+	return FieldChange{At: &start, Before: removed, After: inserted}, nil
+}
+
+// jsonArray retourne les éléments d'un tableau JSON, faux si raw n'en est pas un
+func jsonArray(raw json.RawMessage) ([]json.RawMessage, bool) {
+	if len(raw) == 0 || raw[0] != '[' {
+		return nil, false
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		return nil, false
+	}
+	return elems, true
+}
+
+// apply retourne l'investissement current ramené de son état après la modification à son
+// état avant (undo), ou l'inverse, nil si l'investissement n'existe pas dans cet état.
+// errInvestmentModified est retournée si current n'est pas dans l'état attendu.
+func (c *InvestmentChange) apply(current *Investment, undo bool) (*Investment, error) {
+	fields, sum, err := investmentFields(current)
+	if err != nil {
+		return nil, err
+	}
+	expected, target := c.BeforeSum, c.AfterSum
+	if undo {
+		expected, target = target, expected
+	}
+	if sum != expected {
+		return nil, errInvestmentModified
+	}
+	if target == "" {
+		return nil, nil
+	}
+
+	if fields == nil {
+		fields = make(map[string]json.RawMessage, len(c.Fields))
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Fields)) {
+		field := c.Fields[key]
+		from, to := field.Before, field.After
+		if undo {
+			from, to = to, from
+		}
+		if field.At == nil {
+			if to == nil {
+				delete(fields, key)
+			} else {
+				fields[key] = to
+			}
+			continue
+		}
+		elems, ok := jsonArray(fields[key])
+		removed, okFrom := jsonArray(from)
+		inserted, okTo := jsonArray(to)
+		at := *field.At
+		if !ok || !okFrom || !okTo || at < 0 || at+len(removed) > len(elems) {
+			return nil, fmt.Errorf("champ %s: modification inapplicable", key)
+		}
+		raw, err := json.Marshal(slices.Replace(elems, at, at+len(removed), inserted...))
+		if err != nil {
+			return nil, fmt.Errorf("champ %s: %w", key, err)
+		}
+		fields[key] = raw
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	restored := &Investment{}
+	if raw := fields["holdings"]; raw != nil && string(raw) != "null" {
+		restored.Holdings = NewPortfolio()
+	}
+	if err := json.Unmarshal(data, restored); err != nil {
+		return nil, err
+	}
+	if _, sum, err := investmentFields(restored); err != nil || sum != target {
+		return nil, fmt.Errorf("état restauré différent de l'état enregistré")
+	}
+	return restored, nil
+}
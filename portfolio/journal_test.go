@@ -0,0 +1,301 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// portfolioState retourne la forme sérialisée du portefeuille, hors journal
+func portfolioState(t *testing.T, p *Portfolio) string {
+	t.Helper()
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	delete(fields, "journal")
+	data, err = json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUndoRedo(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(p *Portfolio) error
+		mutate func(p *Portfolio) error
+	}{
+		{name: "AddInvestment", mutate: func(p *Portfolio) error { return p.AddInvestment("C", 500, 4, "2024-02-01") }},
+		{name: "AddNAV", mutate: func(p *Portfolio) error { return p.AddNAV("A", "2024-09-01", 1080) }},
+		{name: "UpdateNAV", mutate: func(p *Portfolio) error { return p.UpdateNAV("A", "2024-06-01", 1060) }},
+		{name: "DeleteNAV", mutate: func(p *Portfolio) error { return p.DeleteNAV("B", "2024-06-01") }},
+		{name: "AddCashFlow", mutate: func(p *Portfolio) error { return p.AddCashFlow("B", "2024-03-01", 300, Contribution) }},
+		{name: "AddTransaction", mutate: func(p *Portfolio) error { return p.AddTransaction("A", "2024-03-01", Buy, 10, 10.2, 1) }},
+		{name: "AddDistribution", mutate: func(p *Portfolio) error { return p.AddDistribution("A", "2024-04-01", 20, false) }},
+		{name: "SetTag", mutate: func(p *Portfolio) error { return p.SetTag("A", TagRegion, "Europe") }},
+		{name: "SetRatePolicy", mutate: func(p *Portfolio) error { return p.SetRatePolicy("A", &RatePolicy{Mode: RateReference}) }},
+		{name: "SetFeeSchedule", mutate: func(p *Portfolio) error { return p.SetFeeSchedule("A", &FeeSchedule{TER: 1}) }},
+		{name: "SetConventions", mutate: func(p *Portfolio) error {
+			return p.SetConventions(analytics.RateConventions{DayCount: analytics.DayCountActual360})
+		}},
+		{name: "SetCalendar", mutate: func(p *Portfolio) error { return p.SetCalendar(&Calendar{Holidays: HolidaysFrance}) }},
+		{name: "SetLocale", mutate: func(p *Portfolio) error { return p.SetLocale("en") }},
+		{name: "SetMissingNAVPolicy", mutate: func(p *Portfolio) error { return p.SetMissingNAVPolicy(MissingNAVSkip) }},
+		{name: "RemoveInvestment", mutate: func(p *Portfolio) error { return p.RemoveInvestment("B") }},
+		{name: "RenameInvestment", mutate: func(p *Portfolio) error { return p.RenameInvestment("B", "C") }},
+		{name: "CloseInvestment", mutate: func(p *Portfolio) error { return p.CloseInvestment("B", "2024-06-01") }},
+		{
+			name:   "ReopenInvestment",
+			setup:  func(p *Portfolio) error { return p.CloseInvestment("B", "2024-06-01") },
+			mutate: func(p *Portfolio) error { return p.ReopenInvestment("B") },
+		},
+		{name: "SetExposure", mutate: func(p *Portfolio) error { return p.SetExposure("B", ExposureShort) }},
+		{name: "ImportNAVsFromCSV", mutate: func(p *Portfolio) error {
+			_, err := p.ImportNAVsFromCSV(strings.NewReader("2024-09-01;1100\n2024-12-01;1120\n"), "A")
+			return err
+		}},
+		{name: "AddHolding", mutate: func(p *Portfolio) error { return p.AddHolding("B", "L", 500, 3, "2024-01-01") }},
+		{
+			name:   "AddHoldingNAV",
+			setup:  func(p *Portfolio) error { return p.AddHolding("B", "L", 500, 3, "2024-01-01") },
+			mutate: func(p *Portfolio) error { return p.AddHoldingNAV("B", "L", "2024-06-01", 520) },
+		},
+		{
+			name: "RollUp",
+			setup: func(p *Portfolio) error {
+				if err := p.AddHolding("B", "L", 500, 3, "2024-01-01"); err != nil {
+					return err
+				}
+				// Une NAV ajoutée directement à la ligne n'est répercutée qu'au recalcul
+				return p.Investments["B"].Holdings.AddNAV("L", "2024-06-01", 520)
+			},
+			mutate: func(p *Portfolio) error { return p.RollUp() },
+		},
+		{name: "Watch", mutate: func(p *Portfolio) error { return p.Watch("W", "FR0000000000", "") }},
+		{
+			name:   "Unwatch",
+			setup:  func(p *Portfolio) error { return p.Watch("W", "", "") },
+			mutate: func(p *Portfolio) error { return p.Unwatch("W") },
+		},
+		{
+			name:   "AddWatchPrice",
+			setup:  func(p *Portfolio) error { return p.Watch("W", "", "") },
+			mutate: func(p *Portfolio) error { return p.AddWatchPrice("W", "2024-06-01", 42) },
+		},
+		{name: "SetStatementRules", mutate: func(p *Portfolio) error {
+			return p.SetStatementRules([]StatementRule{{Security: "FR0000000000", Investment: "A"}})
+		}},
+		{
+			name: "AddStatementRules",
+			setup: func(p *Portfolio) error {
+				return p.SetStatementRules([]StatementRule{{Security: "FR0000000000", Investment: "A"}})
+			},
+			mutate: func(p *Portfolio) error { return p.AddStatementRules(StatementRule{Account: "PEA*", Ignore: true}) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPortfolio()
+			if err := p.AddInvestmentWithQuantity("A", 100, 10, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddNAV("A", "2024-06-01", 1050); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddInvestment("B", 2000, 3, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddNAV("B", "2024-06-01", 2030); err != nil {
+				t.Fatal(err)
+			}
+			if tt.setup != nil {
+				if err := tt.setup(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			before := portfolioState(t, p)
+			if err := tt.mutate(p); err != nil {
+				t.Fatal(err)
+			}
+			after := portfolioState(t, p)
+			if after == before {
+				t.Fatal("la modification n'a pas changé le portefeuille")
+			}
+
+			if _, err := p.Undo(); err != nil {
+				t.Fatalf("Undo: %v", err)
+			}
+			if got := portfolioState(t, p); got != before {
+				t.Errorf("après Undo:\n%s\nattendu:\n%s", got, before)
+			}
+			if _, err := p.Redo(); err != nil {
+				t.Fatalf("Redo: %v", err)
+			}
+			if got := portfolioState(t, p); got != after {
+				t.Errorf("après Redo:\n%s\nattendu:\n%s", got, after)
+			}
+		})
+	}
+}
+
+func TestUndoRedoLimits(t *testing.T) {
+	p := NewPortfolio()
+	if _, err := p.Undo(); err == nil {
+		t.Error("Undo sans modification: erreur attendue")
+	}
+	if _, err := p.Redo(); err == nil {
+		t.Error("Redo sans annulation: erreur attendue")
+	}
+
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-06-01", 1050); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-09-01", 1080); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Redo(); err == nil {
+		t.Error("Redo après une nouvelle modification: erreur attendue")
+	}
+}
+
+func TestJournalRecordsChangesOnly(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2000-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	start, err := ParseDate("2000-01-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var csv strings.Builder
+	for i := range 5000 {
+		fmt.Fprintf(&csv, "%s;%.1f\n", FormatDate(start.AddDate(0, 0, i)), 1000+float64(i)/10)
+	}
+	if _, err := p.ImportNAVsFromCSV(strings.NewReader(csv.String()), "A"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	if err := p.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	before := portfolioState(t, p)
+
+	tests := []struct {
+		name   string
+		mutate func(p *Portfolio) error
+	}{
+		{name: "AddNAV", mutate: func(p *Portfolio) error { return p.AddNAV("A", "2030-01-01", 2000) }},
+		{name: "UpdateNAV", mutate: func(p *Portfolio) error { return p.UpdateNAV("A", "2005-06-01", 1500) }},
+		{name: "DeleteNAV", mutate: func(p *Portfolio) error { return p.DeleteNAV("A", "2005-06-01") }},
+		{name: "SetTag", mutate: func(p *Portfolio) error { return p.SetTag("A", TagRegion, "Europe") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := LoadPortfolioJSON(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.mutate(p); err != nil {
+				t.Fatal(err)
+			}
+			entries := p.Journal.Entries
+			data, err := json.Marshal(entries[len(entries)-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) > 1024 {
+				t.Errorf("entrée de %d octets pour un historique de 5000 NAV: %.200s…", len(data), data)
+			}
+
+			// L'annulation a lieu dans un autre processus, à partir du fichier
+			changed := filepath.Join(t.TempDir(), "portfolio.json")
+			if err := p.SaveJSON(changed); err != nil {
+				t.Fatal(err)
+			}
+			reloaded, err := LoadPortfolioJSON(changed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after := portfolioState(t, reloaded)
+			if _, err := reloaded.Undo(); err != nil {
+				t.Fatalf("Undo: %v", err)
+			}
+			if got := portfolioState(t, reloaded); got != before {
+				t.Error("après Undo, portefeuille différent de l'état initial")
+			}
+			if _, err := reloaded.Redo(); err != nil {
+				t.Fatalf("Redo: %v", err)
+			}
+			if got := portfolioState(t, reloaded); got != after {
+				t.Error("après Redo, portefeuille différent de l'état modifié")
+			}
+		})
+	}
+}
+
+func TestUndoModifiedSince(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-06-01", 1050); err != nil {
+		t.Fatal(err)
+	}
+	// Modification directe, hors journal
+	p.Investments["A"].NAVHistory[0].Value = NewMoney(1060)
+	if _, err := p.Undo(); err == nil || !strings.Contains(err.Error(), "modifié depuis") {
+		t.Errorf("Undo après une modification hors journal: %v, refus attendu", err)
+	}
+	if nav := p.Investments["A"].NAVHistory; len(nav) != 1 || nav[0].Value != NewMoney(1060) {
+		t.Errorf("NAV %+v, modification hors journal écrasée", nav)
+	}
+}
+
+func TestJournalLegacyStates(t *testing.T) {
+	// Journal enregistré avant InvestmentChange : les deux états complets
+	data := `{
+		"investments": {"A": {"id": "a1", "name": "A", "amount_invested": 1000, "reference_rate": 5, "investment_date": "2024-01-01",
+			"nav_history": [{"date": "2024-06-01", "value": 1050}]}},
+		"journal": {"entries": [{"time": "2024-06-01T10:00:00Z", "op": "add-nav", "investment": "A", "detail": "2024-06-01",
+			"before": {"id": "a1", "name": "A", "amount_invested": 1000, "reference_rate": 5, "investment_date": "2024-01-01", "nav_history": []},
+			"after": {"id": "a1", "name": "A", "amount_invested": 1000, "reference_rate": 5, "investment_date": "2024-01-01",
+				"nav_history": [{"date": "2024-06-01", "value": 1050}]}}]}
+	}`
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPortfolioJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change := p.Journal.Entries[0].Change
+	if change == nil || len(change.Fields) != 1 {
+		t.Fatalf("modification convertie %+v, le seul champ nav_history attendu", change)
+	}
+	if _, err := p.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if nav := p.Investments["A"].NAVHistory; len(nav) != 0 {
+		t.Errorf("NAV après Undo: %+v, aucune attendue", nav)
+	}
+}
@@ -0,0 +1,179 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TransactionType distingue les achats des ventes de parts
+type TransactionType string
+
+const (
+	Buy  TransactionType = "buy"  // Achat de parts
+	Sell TransactionType = "sell" // Vente de parts
+)
+
+// Transaction représente un achat ou une vente de parts à un prix unitaire donné
+type Transaction struct {
+	Date       Date            `json:"date"`                 // Date d'exécution
+	Type       TransactionType `json:"type"`                 // Achat ou vente
+	Units      Quantity        `json:"units"`                // Nombre de parts, toujours positif
+	Price      Price           `json:"price"`                // Prix unitaire d'exécution
+	Fees       Money           `json:"fees,omitempty"`       // Frais de transaction
+	Reinvested bool            `json:"reinvested,omitempty"` // Achat financé par une distribution réinvestie, sans apport
+	Notes      []Note          `json:"notes,omitempty"`      // Notes, avis d'opéré et liens
+}
+
+// Amount retourne le montant brut de la transaction (parts × prix)
+func (tx Transaction) Amount() Money {
+	return tx.Price.Total(tx.Units)
+}
+
+// Position décrit la ligne détenue selon le registre des transactions,
+// valorisée au prix moyen pondéré (PMP)
+type Position struct {
+	Units          Quantity `json:"units"`           // Parts détenues
+	CostBasis      Money    `json:"cost_basis"`      // Prix de revient des parts détenues, frais d'achat inclus
+	AverageCost    Price    `json:"average_cost"`    // Prix de revient unitaire
+	MarketValue    Money    `json:"market_value"`    // Valeur de marché d'après la dernière NAV
+	RealizedGain   Money    `json:"realized_gain"`   // Plus-values réalisées sur les ventes, nettes de frais
+	UnrealizedGain Money    `json:"unrealized_gain"` // Plus-value latente : valeur de marché moins prix de revient
+}
+
+// AddTransaction enregistre un achat ou une vente de parts. Le flux correspondant
+// (apport pour un achat, retrait pour une vente) est ajouté automatiquement afin que
+// les calculs de rendement en tiennent compte.
+func (p *Portfolio) AddTransaction(investmentName string, date string, txType TransactionType, units float64, price float64, fees float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+
+	if units <= 0 {
+		return fmt.Errorf("le nombre de parts doit être positif: %w", ErrInvalidAmount)
+	}
+	if NewPrice(price) <= 0 {
+		return fmt.Errorf("le prix unitaire doit être positif et d'au moins 0.00000001: %w", ErrInvalidAmount)
+	}
+	if fees < 0 {
+		return fmt.Errorf("les frais ne peuvent pas être négatifs: %w", ErrInvalidAmount)
+	}
+	if txType != Buy && txType != Sell {
+		return InvalidField("type", txType, "type de transaction inconnu: %s", txType)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+
+	tx := Transaction{Date: Date{t}, Type: txType, Units: NewQuantity(units), Price: NewPrice(price), Fees: NewMoney(fees)}
+	if txType == Sell {
+		if held := inv.unitsAt(t); tx.Units > held {
+			return fmt.Errorf("vente de %s parts pour %s détenues au %s: %w", tx.Units, held, date, ErrInvalidAmount)
+		}
+	}
+
+	before := inv.clone()
+	inv.addTransaction(tx)
+	p.record(OpAddTransaction, investmentName, fmt.Sprintf("%s %s × %s au %s", txType, tx.Units, tx.Price, date), before)
+	p.valueChanged(investmentName)
+	return nil
+}
+
+// addTransaction insère une transaction validée à sa date, avec l'apport ou le retrait
+// correspondant
+func (inv *Investment) addTransaction(tx Transaction) {
+	inv.Transactions = append(inv.Transactions, tx)
+	sort.SliceStable(inv.Transactions, func(i, j int) bool {
+		return inv.Transactions[i].Date.Before(inv.Transactions[j].Date.Time)
+	})
+
+	flow := CashFlow{Date: tx.Date, Amount: tx.Amount() + tx.Fees, Type: Contribution}
+	if tx.Type == Sell {
+		flow = CashFlow{Date: tx.Date, Amount: tx.Amount() - tx.Fees, Type: Withdrawal}
+	}
+	if flow.Amount > 0 {
+		inv.addCashFlow(flow)
+	}
+}
+
+// ledger retourne le registre complet : la position initiale (quantité et prix
+// unitaire renseignés à la création) suivie des transactions, triés par date
+func (inv *Investment) ledger() []Transaction {
+	var txs []Transaction
+	if inv.Quantity > 0 && inv.UnitPrice > 0 {
+		txs = append(txs, Transaction{Date: inv.InvestmentDate, Type: Buy, Units: inv.Quantity, Price: inv.UnitPrice})
+	}
+	return append(txs, inv.Transactions...)
+}
+
+// unitsAt retourne le nombre de parts détenues à une date (transactions du jour incluses)
+func (inv *Investment) unitsAt(date time.Time) Quantity {
+	var units Quantity
+	for _, tx := range inv.ledger() {
+		if tx.Date.After(date) {
+			break
+		}
+		if tx.Type == Sell {
+			units -= tx.Units
+		} else {
+			units += tx.Units
+		}
+	}
+	return units
+}
+
+// Units retourne le nombre de parts actuellement détenues
+func (inv *Investment) Units() Quantity {
+	var units Quantity
+	for _, tx := range inv.ledger() {
+		if tx.Type == Sell {
+			units -= tx.Units
+		} else {
+			units += tx.Units
+		}
+	}
+	return units
+}
+
+// Position calcule les parts détenues, le prix de revient et les plus-values
+// réalisées et latentes selon la méthode du prix moyen pondéré
+func (inv *Investment) Position() (Position, error) {
+	txs := inv.ledger()
+	if len(txs) == 0 {
+		return Position{}, fmt.Errorf("aucune transaction enregistrée pour %s: %w", inv.Name, ErrInsufficientHistory)
+	}
+
+	var pos Position
+	for _, tx := range txs {
+		switch tx.Type {
+		case Buy:
+			pos.Units += tx.Units
+			pos.CostBasis += tx.Amount() + tx.Fees
+		case Sell:
+			// Le prix de revient sorti est proportionnel aux parts vendues
+			soldCost := pos.CostBasis.Mul(tx.Units.Float64() / pos.Units.Float64())
+			pos.RealizedGain += tx.Amount() - tx.Fees - soldCost
+			pos.CostBasis -= soldCost
+			pos.Units -= tx.Units
+		}
+	}
+	if pos.Units > 0 {
+		pos.AverageCost = NewPrice(pos.CostBasis.Float64() / pos.Units.Float64())
+	}
+
+	// La NAV représente la valeur de la ligne entière : en déduire un prix unitaire
+	// à sa date, puis l'appliquer aux parts actuellement détenues
+	if latestNAV, err := inv.GetLatestNAV(); err == nil {
+		if heldAtNAV := inv.unitsAt(latestNAV.Date.Time); heldAtNAV > 0 {
+			pos.MarketValue = latestNAV.Value.Mul(pos.Units.Float64() / heldAtNAV.Float64())
+			pos.UnrealizedGain = pos.MarketValue - pos.CostBasis
+		}
+	}
+
+	return pos, nil
+}
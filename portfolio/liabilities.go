@@ -0,0 +1,254 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LiabilityKind est la nature d'un emprunt
+type LiabilityKind string
+
+const (
+	LiabilityMortgage LiabilityKind = "mortgage" // Crédit immobilier
+	LiabilityLoan     LiabilityKind = "loan"     // Crédit à la consommation, prêt personnel
+)
+
+// Liability est un emprunt amortissable à mensualités constantes
+type Liability struct {
+	Name       string        `json:"name"`
+	Kind       LiabilityKind `json:"kind"`
+	Principal  Money         `json:"principal"`          // Capital emprunté
+	AnnualRate float64       `json:"annual_rate"`        // Taux nominal annuel (%)
+	Start      Date          `json:"start"`              // Date de déblocage ; la première mensualité tombe un mois plus tard
+	Months     int           `json:"months"`             // Durée en mois
+	Currency   Currency      `json:"currency,omitempty"` // Devise de l'emprunt (EUR si vide)
+}
+
+// AmortizationRow est une échéance du tableau d'amortissement
+type AmortizationRow struct {
+	Date      time.Time
+	Payment   Money // Mensualité
+	Interest  Money // Part d'intérêts
+	Principal Money // Capital remboursé
+	Balance   Money // Capital restant dû après l'échéance
+}
+
+// NetWorth est le patrimoine net à une date, en devise de consolidation
+type NetWorth struct {
+	Date        time.Time
+	Assets      float64 // Valeur des investissements ouverts
+	Liabilities float64 // Capital restant dû des emprunts
+	Net         float64
+}
+
+// MonthlyPayment calcule la mensualité constante : C·i / (1 - (1+i)^-n), i taux mensuel
+func (l *Liability) MonthlyPayment() Money {
+	i := l.AnnualRate / 100 / 12
+	if i == 0 {
+		return l.Principal.Mul(1 / float64(l.Months)).RoundCents()
+	}
+	return l.Principal.Mul(i / (1 - math.Pow(1+i, -float64(l.Months)))).RoundCents()
+}
+
+// Schedule retourne le tableau d'amortissement ; la dernière échéance solde le capital
+func (l *Liability) Schedule() []AmortizationRow {
+	payment := l.MonthlyPayment()
+	i := l.AnnualRate / 100 / 12
+	balance := l.Principal
+	rows := make([]AmortizationRow, 0, l.Months)
+	for k := 1; k <= l.Months; k++ {
+		interest := balance.Mul(i).RoundCents()
+		principal := payment - interest
+		if k == l.Months || principal > balance {
+			principal = balance
+		}
+		balance -= principal
+		rows = append(rows, AmortizationRow{
+			Date:      l.Start.AddDate(0, k, 0),
+			Payment:   principal + interest,
+			Interest:  interest,
+			Principal: principal,
+			Balance:   balance,
+		})
+	}
+	return rows
+}
+
+// OutstandingAt retourne le capital restant dû à une date (échéances du jour incluses),
+// nul avant le déblocage des fonds
+func (l *Liability) OutstandingAt(date time.Time) Money {
+	if date.Before(l.Start.Time) {
+		return 0
+	}
+	outstanding := l.Principal
+	for _, row := range l.Schedule() {
+		if row.Date.After(date) {
+			break
+		}
+		outstanding = row.Balance
+	}
+	return outstanding
+}
+
+// AddLiability enregistre un emprunt
+func (p *Portfolio) AddLiability(l Liability) error {
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" {
+		return fmt.Errorf("le nom de l'emprunt ne peut pas être vide")
+	}
+	if l.Principal <= 0 || l.Months <= 0 || l.AnnualRate < 0 {
+		return fmt.Errorf("capital, durée et taux doivent être positifs: %w", ErrInvalidAmount)
+	}
+	if l.Kind != LiabilityMortgage && l.Kind != LiabilityLoan {
+		return InvalidField("kind", l.Kind, "type d'emprunt inconnu: %s", l.Kind)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Liabilities[l.Name]; exists {
+		return fmt.Errorf("un emprunt '%s' existe déjà: %w", l.Name, ErrAlreadyExists)
+	}
+	if p.Liabilities == nil {
+		p.Liabilities = make(map[string]*Liability)
+	}
+	p.Liabilities[l.Name] = &l
+	p.changed("add-liability", "")
+	return nil
+}
+
+// RemoveLiability supprime un emprunt
+func (p *Portfolio) RemoveLiability(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Liabilities[name]; !exists {
+		return fmt.Errorf("aucun emprunt '%s': %w", name, ErrNotFound)
+	}
+	delete(p.Liabilities, name)
+	p.changed("remove-liability", "")
+	return nil
+}
+
+// Liability retourne une copie d'un emprunt
+func (p *Portfolio) Liability(name string) (Liability, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	l, exists := p.Liabilities[name]
+	if !exists {
+		return Liability{}, fmt.Errorf("aucun emprunt '%s': %w", name, ErrNotFound)
+	}
+	return *l, nil
+}
+
+// valueAt valorise les investissements ouverts à une date quelconque : par interpolation
+// entre les NAV jusqu'à la dernière NAV (voir historicalValue), par projection au-delà.
+// L'appelant doit détenir p.mu.
+func (p *Portfolio) valueAt(t time.Time) (float64, error) {
+	var total Money
+	for name, inv := range p.Investments {
+		var value Money
+		if latest, err := inv.GetLatestNAV(); err == nil && t.After(latest.Date.Time) {
+			if inv.Closed {
+				continue
+			}
+			rate, err := inv.ProjectionRate(nil)
+			if err != nil {
+				return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			if value, _, err = p.valueAtRate(inv, t, rate); err != nil {
+				return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+		} else {
+			historical, held, err := p.investmentValueAt(inv, t)
+			if err != nil {
+				return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			if !held {
+				continue
+			}
+			value = NewMoney(historical).RoundCents()
+		}
+		total += value
+	}
+	return total.Float64(), nil
+}
+
+// netWorth calcule le patrimoine net à une date ; l'appelant doit détenir p.mu
+func (p *Portfolio) netWorth(t time.Time) (NetWorth, error) {
+	assets, err := p.valueAt(t)
+	if err != nil {
+		return NetWorth{}, err
+	}
+	var debt Money
+	for name, l := range p.Liabilities {
+		outstanding, err := p.toBase(l.OutstandingAt(t).Float64(), l.Currency, t)
+		if err != nil {
+			return NetWorth{}, fmt.Errorf("emprunt %s: %w", name, err)
+		}
+		debt += NewMoney(outstanding).RoundCents()
+	}
+	return NetWorth{Date: t, Assets: assets, Liabilities: debt.Float64(), Net: assets - debt.Float64()}, nil
+}
+
+// NetWorth calcule le patrimoine net à une date : valeur des investissements (projetée
+// au-delà de leur dernière NAV) moins le capital restant dû des emprunts
+func (p *Portfolio) NetWorth(date string) (NetWorth, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return NetWorth{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.netWorth(t)
+}
+
+// NetWorthSeries calcule le patrimoine net de from à to, un point par pas
+func (p *Portfolio) NetWorthSeries(from, to string, step SeriesStep) ([]NetWorth, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() || end.Before(start) {
+		return nil, fmt.Errorf("période vide: %w", ErrInvalidDate)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var series []NetWorth
+	for i := 0; ; i++ {
+		date, err := step.add(start, i)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(end) {
+			break
+		}
+		point, err := p.netWorth(date)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, point)
+	}
+	return series, nil
+}
+
+// LiabilityNames retourne les noms des emprunts, triés
+func (p *Portfolio) LiabilityNames() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.Liabilities))
+	for name := range p.Liabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
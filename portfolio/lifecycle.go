@@ -0,0 +1,133 @@
+package portfolio
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RemoveInvestment supprime définitivement un investissement et tout son historique.
+// Pour conserver l'historique d'une position soldée, utiliser CloseInvestment.
+func (p *Portfolio) RemoveInvestment(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	settings, err := p.settingsState("target_allocation", "alert_rules")
+	if err != nil {
+		return err
+	}
+	entry := JournalEntry{Op: OpRemoveInvestment, Investment: name, Before: inv.clone(), BeforeSettings: settings}
+
+	delete(p.Investments, name)
+	if p.TargetAllocation != nil {
+		delete(p.TargetAllocation.Weights, name)
+		if glide := p.TargetAllocation.Glide; glide != nil {
+			glide.Growth = slices.DeleteFunc(glide.Growth, func(n string) bool { return n == name })
+		}
+	}
+	rules := p.AlertRules[:0]
+	for _, rule := range p.AlertRules {
+		if rule.Investment != name {
+			rules = append(rules, rule)
+		}
+	}
+	p.AlertRules = rules
+	return p.recordChange(entry)
+}
+
+// RenameInvestment renomme un investissement en conservant son historique
+func (p *Portfolio) RenameInvestment(oldName, newName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[oldName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", oldName, ErrInvestmentNotFound)
+	}
+	if newName == "" {
+		return fmt.Errorf("le nouveau nom ne peut pas être vide")
+	}
+	if _, taken := p.Investments[newName]; taken && newName != oldName {
+		return fmt.Errorf("l'investissement '%s' existe déjà: %w", newName, ErrInvestmentExists)
+	}
+
+	settings, err := p.settingsState("target_allocation", "scenarios", "alert_rules")
+	if err != nil {
+		return err
+	}
+	entry := JournalEntry{Op: OpRenameInvestment, Investment: oldName, Renamed: newName, Detail: "en " + newName, Before: inv.clone(), BeforeSettings: settings}
+
+	delete(p.Investments, oldName)
+	inv.Name = newName
+	p.Investments[newName] = inv
+	if p.TargetAllocation != nil {
+		if weight, ok := p.TargetAllocation.Weights[oldName]; ok {
+			delete(p.TargetAllocation.Weights, oldName)
+			p.TargetAllocation.Weights[newName] = weight
+		}
+		if glide := p.TargetAllocation.Glide; glide != nil {
+			if i := slices.Index(glide.Growth, oldName); i >= 0 {
+				glide.Growth[i] = newName
+			}
+		}
+	}
+	for _, scenario := range p.Scenarios {
+		for i := range scenario.Rules {
+			if scenario.Rules[i].Investment == oldName {
+				scenario.Rules[i].Investment = newName
+			}
+		}
+	}
+	for i := range p.AlertRules {
+		if p.AlertRules[i].Investment == oldName {
+			p.AlertRules[i].Investment = newName
+		}
+	}
+	return p.recordChange(entry)
+}
+
+// CloseInvestment marque un investissement comme clôturé à une date, typiquement après
+// un rachat total. Il est alors exclu des valorisations et projections, mais son
+// historique reste pris en compte dans les mesures de performance passée.
+func (p *Portfolio) CloseInvestment(name string, date string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+	if t.Before(inv.InvestmentDate.Time) {
+		return fmt.Errorf("la date de clôture doit être après la date d'investissement: %w", ErrInvalidDate)
+	}
+
+	before := inv.clone()
+	inv.Closed = true
+	inv.ClosedDate = Date{t}
+	p.record(OpCloseInvestment, name, "au "+date, before)
+	return nil
+}
+
+// ReopenInvestment annule la clôture d'un investissement
+func (p *Portfolio) ReopenInvestment(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	inv.Closed = false
+	inv.ClosedDate = Date{}
+	p.record(OpReopenInvestment, name, "", before)
+	p.valueChanged(name)
+	return nil
+}
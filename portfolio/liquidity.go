@@ -0,0 +1,209 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LiquidityTier est le délai de disponibilité d'un investissement : fréquence à laquelle
+// il peut être vendu ou racheté, ou blocage jusqu'à une date
+type LiquidityTier string
+
+const (
+	LiquidityDaily     LiquidityTier = "daily"     // Cotation ou rachat quotidien
+	LiquidityMonthly   LiquidityTier = "monthly"   // Rachat mensuel
+	LiquidityQuarterly LiquidityTier = "quarterly" // Rachat trimestriel
+	LiquidityLocked    LiquidityTier = "locked"    // Bloqué jusqu'à une date
+)
+
+// liquiditySettlementDays est le délai de règlement d'une vente, ajouté au délai du niveau
+const liquiditySettlementDays = 3
+
+// Liquidity est la classification de liquidité d'un investissement
+type Liquidity struct {
+	Tier        LiquidityTier `json:"tier"`
+	LockedUntil Date          `json:"locked_until,omitzero"` // Fin du blocage (LiquidityLocked)
+}
+
+// liquidityAlias permet de lire Liquidity sans rappeler sa propre méthode UnmarshalJSON
+type liquidityAlias Liquidity
+
+// UnmarshalJSON lit une liquidité et la valide
+func (l *Liquidity) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*liquidityAlias)(l)); err != nil {
+		return err
+	}
+	return l.validate()
+}
+
+func (l Liquidity) validate() error {
+	switch l.Tier {
+	case LiquidityDaily, LiquidityMonthly, LiquidityQuarterly:
+		return nil
+	case LiquidityLocked:
+		if l.LockedUntil.IsZero() {
+			return InvalidField("locked_until", "", "la date de fin de blocage est obligatoire")
+		}
+		return nil
+	}
+	return InvalidField("tier", l.Tier, "niveau de liquidité inconnu: %s (daily, monthly, quarterly, locked)", l.Tier)
+}
+
+// availableAt retourne la date à laquelle le produit d'une vente décidée à asOf est
+// disponible
+func (l Liquidity) availableAt(asOf time.Time) time.Time {
+	switch l.Tier {
+	case LiquidityMonthly:
+		asOf = asOf.AddDate(0, 1, 0)
+	case LiquidityQuarterly:
+		asOf = asOf.AddDate(0, 3, 0)
+	case LiquidityLocked:
+		if l.LockedUntil.After(asOf) {
+			asOf = l.LockedUntil.Time
+		}
+	}
+	return asOf.AddDate(0, 0, liquiditySettlementDays)
+}
+
+func (l Liquidity) String() string {
+	if l.Tier == LiquidityLocked {
+		return fmt.Sprintf("%s jusqu'au %s", l.Tier, FormatDate(l.LockedUntil.Time))
+	}
+	return string(l.Tier)
+}
+
+// liquidity retourne la classification de l'investissement : la sienne, quotidienne pour
+// un compte rémunéré, nil sinon
+func (inv *Investment) liquidity() *Liquidity {
+	if inv.Liquidity != nil {
+		return inv.Liquidity
+	}
+	if inv.Cash != nil {
+		return &Liquidity{Tier: LiquidityDaily}
+	}
+	return nil
+}
+
+// SetLiquidity classe un investissement selon sa liquidité ; un niveau vide supprime la
+// classification
+func (p *Portfolio) SetLiquidity(name string, tier LiquidityTier, lockedUntil string) error {
+	var l Liquidity
+	if tier != "" {
+		until, _, err := ParsePeriod(lockedUntil, "")
+		if err != nil {
+			return err
+		}
+		if tier != LiquidityLocked && !until.IsZero() {
+			return InvalidField("locked_until", lockedUntil, "une date de fin de blocage ne s'applique qu'au niveau locked")
+		}
+		l = Liquidity{Tier: tier, LockedUntil: Date{until}}
+		if err := l.validate(); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	inv.Liquidity = nil
+	if tier != "" {
+		inv.Liquidity = &l
+	}
+	p.record(OpSetLiquidity, name, fmt.Sprintf("niveau %q", tier), before)
+	return nil
+}
+
+// defaultLiquidityHorizons sont les horizons du rapport de liquidité
+var defaultLiquidityHorizons = []LiquidityHorizon{
+	{Label: "1 semaine", Days: 7},
+	{Label: "1 mois", Months: 1},
+	{Label: "1 an", Years: 1},
+}
+
+// LiquidityHorizon est un délai au bout duquel mesurer les sommes disponibles
+type LiquidityHorizon struct {
+	Label               string
+	Years, Months, Days int
+}
+
+// LiquidityBucket est la somme disponible à un horizon
+type LiquidityBucket struct {
+	Horizon string
+	Date    time.Time
+	Amount  float64 // En devise de consolidation
+	Share   float64 // Part de la valeur du portefeuille (%)
+}
+
+// LockedHolding est un investissement bloqué au-delà de la date du rapport
+type LockedHolding struct {
+	Investment string
+	Until      time.Time
+	Value      float64
+}
+
+// LiquidityReport répartit la valeur du portefeuille selon sa liquidité
+type LiquidityReport struct {
+	AsOf         time.Time
+	Total        float64                   // Valeur des investissements ouverts
+	ByTier       map[LiquidityTier]float64 // Valeur par niveau
+	Unclassified float64                   // Valeur des investissements sans niveau, jamais comptée disponible
+	Horizons     []LiquidityBucket
+	Locked       []LockedHolding // Triés par date de fin de blocage
+	Missing      []string        // Investissements sans niveau de liquidité
+}
+
+// LiquidityReport calcule les sommes que le portefeuille peut rendre disponibles dans une
+// semaine, un mois et un an à partir de asOf, en vendant chaque investissement ouvert à sa
+// valeur actuelle (dernière NAV, sinon montant investi) et en tenant compte de son délai
+// de rachat, de blocage et de règlement. Les investissements non classés sont signalés
+// et, par prudence, jamais comptés comme disponibles.
+func (p *Portfolio) LiquidityReport(asOf time.Time) (*LiquidityReport, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	results, err := p.query(QueryOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LiquidityReport{AsOf: asOf, ByTier: make(map[LiquidityTier]float64)}
+	available := make([]Money, len(defaultLiquidityHorizons))
+	var total Money
+	for _, res := range results {
+		total += NewMoney(res.Value)
+		l := p.Investments[res.Name].liquidity()
+		if l == nil {
+			r.Unclassified += res.Value
+			r.Missing = append(r.Missing, res.Name)
+			continue
+		}
+		r.ByTier[l.Tier] += res.Value
+		if l.Tier == LiquidityLocked && l.LockedUntil.After(asOf) {
+			r.Locked = append(r.Locked, LockedHolding{Investment: res.Name, Until: l.LockedUntil.Time, Value: res.Value})
+		}
+		ready := l.availableAt(asOf)
+		for i, h := range defaultLiquidityHorizons {
+			if !ready.After(asOf.AddDate(h.Years, h.Months, h.Days)) {
+				available[i] += NewMoney(res.Value)
+			}
+		}
+	}
+	r.Total = total.RoundCents().Float64()
+
+	for i, h := range defaultLiquidityHorizons {
+		bucket := LiquidityBucket{Horizon: h.Label, Date: asOf.AddDate(h.Years, h.Months, h.Days), Amount: available[i].RoundCents().Float64()}
+		if total != 0 {
+			bucket.Share = available[i].Float64() / total.Float64() * 100
+		}
+		r.Horizons = append(r.Horizons, bucket)
+	}
+	sort.SliceStable(r.Locked, func(i, j int) bool { return r.Locked[i].Until.Before(r.Locked[j].Until) })
+	return r, nil
+}
@@ -0,0 +1,27 @@
+package portfolio
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// activeLogger reçoit les journaux de diagnostic (choix des taux de projection, mises à
+// jour des cours, lectures et écritures du portefeuille). Rien n'est journalisé tant
+// que SetLogger n'a pas été appelé.
+var activeLogger atomic.Pointer[slog.Logger]
+
+// discardLogger est le journal par défaut, qui ignore tous les messages
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// SetLogger définit le journal de diagnostic ; nil rétablit le journal muet
+func SetLogger(l *slog.Logger) {
+	activeLogger.Store(l)
+}
+
+// Logger retourne le journal de diagnostic actif
+func Logger() *slog.Logger {
+	if l := activeLogger.Load(); l != nil {
+		return l
+	}
+	return discardLogger
+}
@@ -0,0 +1,156 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MetricsContentType est le type du format texte d'exposition de Prometheus
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Gauge est une jauge et ses échantillons, un par jeu d'étiquettes
+type Gauge struct {
+	Name, Help string
+	samples    []metricSample
+}
+
+// Counter est un compteur : sa valeur ne fait que croître depuis le lancement du
+// processus et son nom se termine par _total
+type Counter Gauge
+
+// Add ajoute un échantillon au compteur
+func (m *Counter) Add(value float64, labels ...string) {
+	(*Gauge)(m).Add(value, labels...)
+}
+
+// WriteText écrit le compteur au format texte de Prometheus
+func (m *Counter) WriteText(w io.Writer) error {
+	return (*Gauge)(m).writeText(w, "counter")
+}
+
+// metricSample est une valeur de jauge et ses étiquettes, sous forme de paires nom, valeur
+type metricSample struct {
+	labels []string
+	value  float64
+}
+
+// Add ajoute un échantillon à la jauge
+func (m *Gauge) Add(value float64, labels ...string) {
+	m.samples = append(m.samples, metricSample{labels: labels, value: value})
+}
+
+// WriteText écrit la jauge au format texte de Prometheus
+func (m *Gauge) WriteText(w io.Writer) error {
+	return m.writeText(w, "gauge")
+}
+
+// writeText écrit les échantillons sous le type Prometheus kind
+func (m *Gauge) writeText(w io.Writer, kind string) error {
+	if len(m.samples) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.Name, m.Help, m.Name, kind); err != nil {
+		return err
+	}
+	for _, s := range m.samples {
+		var labels []string
+		for i := 0; i+1 < len(s.labels); i += 2 {
+			labels = append(labels, fmt.Sprintf("%s=%q", s.labels[i], escapeLabel(s.labels[i+1])))
+		}
+		line := m.Name
+		if len(labels) > 0 {
+			line += "{" + strings.Join(labels, ",") + "}"
+		}
+		if _, err := fmt.Fprintf(w, "%s %s\n", line, strconv.FormatFloat(s.value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeLabel prépare une valeur d'étiquette pour %q : seuls les sauts de ligne sont
+// remplacés, %q échappant déjà les guillemets et barres obliques inverses
+func escapeLabel(v string) string {
+	return strings.ReplaceAll(v, "\n", " ")
+}
+
+// WriteMetrics écrit les jauges du portefeuille au format texte de Prometheus : valeur
+// totale et capital investi, puis pour chaque investissement ouvert sa dernière NAV, sa
+// valeur consolidée, sa performance annualisée et ses baisses (en cours et maximale).
+func (p *Portfolio) WriteMetrics(w io.Writer) error {
+	summary, err := p.Summary()
+	if err != nil {
+		return err
+	}
+	base := string(summary.BaseCurrency)
+
+	total := &Gauge{Name: "david_portfolio_value", Help: "Valeur totale du portefeuille dans la devise de consolidation."}
+	invested := &Gauge{Name: "david_portfolio_invested", Help: "Montant total investi dans la devise de consolidation."}
+	portfolioDrawdown := &Gauge{Name: "david_portfolio_max_drawdown_percent", Help: "Pire baisse du portefeuille depuis un sommet (%)."}
+	nav := &Gauge{Name: "david_investment_nav", Help: "Dernière NAV de l'investissement dans sa devise."}
+	value := &Gauge{Name: "david_investment_value", Help: "Valeur de l'investissement dans la devise de consolidation."}
+	rate := &Gauge{Name: "david_investment_annualized_return_percent", Help: "Performance annualisée de l'investissement (%)."}
+	drawdown := &Gauge{Name: "david_investment_drawdown_percent", Help: "Baisse en cours de l'investissement depuis son sommet (%)."}
+	maxDepth := &Gauge{Name: "david_investment_max_drawdown_percent", Help: "Pire baisse de l'investissement depuis un sommet (%)."}
+
+	total.Add(summary.TotalValue, "currency", base)
+	invested.Add(summary.TotalInvested, "currency", base)
+	if dd, err := p.MaxDrawdown("", ""); err == nil {
+		portfolioDrawdown.Add(dd.Depth)
+	} else if !errors.Is(err, ErrInsufficientHistory) {
+		return err
+	}
+
+	for _, line := range summary.Investments {
+		if line.Closed {
+			continue
+		}
+		if line.LatestNAV != nil {
+			nav.Add(line.LatestNAV.Value.Float64(), "investment", line.Name, "currency", string(line.Currency))
+		}
+		value.Add(line.Value, "investment", line.Name, "currency", base)
+		if line.PerformanceRate != nil {
+			rate.Add(*line.PerformanceRate, "investment", line.Name)
+		}
+
+		inv, err := p.Investment(line.Name)
+		if err != nil {
+			return err
+		}
+		returns := inv.periodReturns()
+		if len(returns) == 0 {
+			continue
+		}
+		drawdown.Add(currentDrawdown(returns), "investment", line.Name)
+		if dd, err := maxDrawdown(returns); err == nil {
+			maxDepth.Add(dd.Depth, "investment", line.Name)
+		}
+	}
+
+	for _, m := range []*Gauge{total, invested, portfolioDrawdown, nav, value, rate, drawdown, maxDepth} {
+		if err := m.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MetricsHandler expose les jauges du portefeuille sur GET /metrics
+func MetricsHandler(p *Portfolio) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		if err := p.WriteMetrics(&b); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", MetricsContentType)
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			log.Printf("écriture des métriques: %v", err)
+		}
+	})
+}
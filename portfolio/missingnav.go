@@ -0,0 +1,81 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MissingNAVPolicy détermine la valorisation d'un investissement qui n'a encore aucune NAV
+type MissingNAVPolicy string
+
+const (
+	// MissingNAVError fait échouer la valorisation du portefeuille (ErrNoNAV) ; c'est le
+	// comportement par défaut
+	MissingNAVError MissingNAVPolicy = "error"
+	// MissingNAVInvested valorise l'investissement à son montant investi et à ses flux,
+	// capitalisés au taux de référence depuis leur date
+	MissingNAVInvested MissingNAVPolicy = "invested"
+	// MissingNAVSkip écarte l'investissement de la valorisation, avec un avertissement
+	MissingNAVSkip MissingNAVPolicy = "skip"
+)
+
+// SetMissingNAVPolicy définit la valorisation des investissements sans NAV
+func (p *Portfolio) SetMissingNAVPolicy(policy MissingNAVPolicy) error {
+	switch policy {
+	case MissingNAVError, MissingNAVInvested, MissingNAVSkip:
+	default:
+		return InvalidField("missing_nav_policy", policy, "politique inconnue: %s (error, invested, skip)", policy)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("missing_nav_policy")
+	if err != nil {
+		return err
+	}
+
+	p.MissingNAVPolicy = policy
+	return p.recordChange(JournalEntry{Op: OpSetMissingNAVPolicy, Detail: string(policy), BeforeSettings: before})
+}
+
+// lacksNAV indique si l'investissement n'a aucune NAV dont partir
+func (inv *Investment) lacksNAV() bool {
+	_, err := inv.GetLatestNAV()
+	return errors.Is(err, ErrNoNAV)
+}
+
+// projectFromInvested projette l'investissement depuis son montant investi : le montant
+// et les flux enregistrés (retraits en négatif), puis les versements programmés, sont
+// capitalisés au taux de référence jusqu'à date
+func (inv *Investment) projectFromInvested(date time.Time) (float64, error) {
+	if date.Before(inv.InvestmentDate.Time) {
+		return 0, fmt.Errorf("la date de valorisation précède l'investissement du %s", FormatDate(inv.InvestmentDate.Time))
+	}
+	flows := make([]CashFlow, 0, len(inv.CashFlows))
+	for _, cf := range inv.CashFlows {
+		if !cf.Date.After(date) {
+			flows = append(flows, CashFlow{Date: cf.Date, Amount: cf.SignedAmount(), Type: Contribution})
+		}
+	}
+	flows = append(flows, inv.PlannedContributions(inv.InvestmentDate.Time, date)...)
+	start := NAV{Date: inv.InvestmentDate, Value: inv.AmountInvested}
+	return inv.project(start, date, inv.navRate(inv.ReferenceRate), flows)
+}
+
+// missingNAVWarning retourne l'avertissement d'un investissement sans NAV selon la
+// politique du portefeuille, vide s'il a des NAV ; l'appelant doit détenir p.mu
+func (p *Portfolio) missingNAVWarning(inv *Investment) string {
+	if !inv.lacksNAV() {
+		return ""
+	}
+	switch p.MissingNAVPolicy {
+	case MissingNAVInvested:
+		return fmt.Sprintf("aucune NAV, valorisé au montant investi capitalisé à %.2f%%", inv.ReferenceRate)
+	case MissingNAVSkip:
+		return "aucune NAV, écarté de la valorisation"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,170 @@
+package portfolio
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money est un montant en virgule fixe exprimé en dix-millièmes d'unité monétaire.
+// Les additions et soustractions sont exactes (opérateurs entiers) ; seules les
+// multiplications par un facteur réel, comme la capitalisation, sont arrondies.
+type Money int64
+
+// moneyDecimals est le nombre de décimales conservées par Money
+const (
+	moneyDecimals = 4
+	moneyScale    = 10000
+)
+
+// NewMoney convertit un flottant en Money, arrondi au dix-millième le plus proche
+func NewMoney(v float64) Money {
+	return Money(math.Round(v * moneyScale))
+}
+
+// ParseMoney lit un montant décimal ("1234.56", "-0.5") sans passer par un flottant.
+// Les décimales au-delà du dix-millième sont arrondies.
+func ParseMoney(s string) (Money, error) {
+	v, ok := parseFixed(s, moneyDecimals)
+	if !ok {
+		return 0, fmt.Errorf("montant '%s' invalide", s)
+	}
+	return Money(v), nil
+}
+
+// parseFixed lit un nombre décimal en entier à decimals décimales, sans passer par un
+// flottant ; les décimales excédentaires sont arrondies
+func parseFixed(s string, decimals int) (int64, bool) {
+	raw := strings.TrimSpace(s)
+	negative := strings.HasPrefix(raw, "-")
+	if negative || strings.HasPrefix(raw, "+") {
+		raw = raw[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, false
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, c := range intPart {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+
+	scale := int64(math.Pow10(decimals))
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units > math.MaxInt64/scale {
+		return 0, false
+	}
+
+	var frac int64
+	for i, c := range fracPart {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		digit := int64(c - '0')
+		if i < decimals {
+			frac = frac*10 + digit
+		} else if i == decimals && digit >= 5 {
+			// Arrondi au plus proche sur la première décimale excédentaire
+			frac++
+		}
+	}
+	for i := len(fracPart); i < decimals; i++ {
+		frac *= 10
+	}
+
+	v := units*scale + frac
+	if negative {
+		v = -v
+	}
+	return v, true
+}
+
+// Float64 retourne le montant sous forme de flottant, pour les calculs de taux
+func (m Money) Float64() float64 {
+	return float64(m) / moneyScale
+}
+
+// Mul multiplie le montant par un facteur réel et arrondit le résultat
+func (m Money) Mul(factor float64) Money {
+	return NewMoney(m.Float64() * factor)
+}
+
+// RoundCents arrondit le montant au centime le plus proche (demi-unité éloignée de zéro)
+func (m Money) RoundCents() Money {
+	const step = moneyScale / 100
+	if m < 0 {
+		return -(-m).RoundCents()
+	}
+	return (m + step/2) / step * step
+}
+
+// String retourne le montant en notation décimale, avec au moins deux décimales
+func (m Money) String() string {
+	sign := ""
+	abs := int64(m)
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	frac := fmt.Sprintf("%0*d", moneyDecimals, abs%moneyScale)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, abs/moneyScale, frac)
+}
+
+// MarshalJSON encode le montant comme un nombre JSON décimal exact
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepte un nombre JSON ou une chaîne décimale
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "null" {
+		return nil
+	}
+
+	// Notation exponentielle : repli sur le flottant
+	if strings.ContainsAny(raw, "eE") {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("montant '%s' invalide", raw)
+		}
+		*m = NewMoney(v)
+		return nil
+	}
+
+	parsed, err := ParseMoney(raw)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implémente driver.Valuer pour le stockage SQL : un entier en dix-millièmes
+// d'unité, relu sans arrondi par Scan
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan implémente sql.Scanner pour le stockage SQL, d'un entier en dix-millièmes d'unité
+// (voir Value)
+func (m *Money) Scan(src any) error {
+	v, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("type %T non convertible en montant: entier en dix-millièmes attendu", src)
+	}
+	*m = Money(v)
+	return nil
+}
@@ -0,0 +1,153 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{in: "1234.56", want: 12345600},
+		{in: "-0.5", want: -5000},
+		{in: "+.25", want: 2500},
+		{in: "7", want: 70000},
+		{in: "0.00004", want: 0},
+		{in: "0.00005", want: 1},
+		{in: "1.23456", want: 12346},
+		{in: "-1.23456", want: -12346},
+		{in: " 10.10 ", want: 101000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, %d attendu", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{"", ".", "abc", "1.2x", "1,5", "--5", "+-5", "-+5", "++5", "- 5", "1_000"} {
+		if _, err := ParseMoney(in); err == nil {
+			t.Errorf("ParseMoney(%q): erreur attendue", in)
+		}
+	}
+}
+
+func TestMoneyRoundCents(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "1.2349", want: "1.23"},
+		{in: "1.235", want: "1.24"},
+		{in: "-1.235", want: "-1.24"},
+		{in: "-1.2349", want: "-1.23"},
+		{in: "0.005", want: "0.01"},
+		{in: "2.5", want: "2.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m, err := ParseMoney(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.RoundCents().String(); got != tt.want {
+				t.Errorf("%s arrondi à %s, %s attendu", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{in: 0, want: "0.00"},
+		{in: -5000, want: "-0.50"},
+		{in: 12345, want: "1.2345"},
+		{in: 12340, want: "1.234"},
+		{in: NewMoney(1000), want: "1000.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Money(%d) = %s, %s attendu", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+// Les additions de montants sont exactes, là où 0.1 + 0.2 ne l'est pas en flottant
+func TestMoneyExactSums(t *testing.T) {
+	var sum Money
+	for range 10 {
+		sum += NewMoney(0.1)
+	}
+	if sum != NewMoney(1) {
+		t.Errorf("dix fois 0.10 = %s, 1.00 attendu", sum)
+	}
+	if got := NewMoney(0.1) + NewMoney(0.2); got != NewMoney(0.3) {
+		t.Errorf("0.10 + 0.20 = %s, 0.30 attendu", got)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{in: `1234.5678`, want: 12345678},
+		{in: `"19.99"`, want: 199900},
+		{in: `1e3`, want: 10000000},
+		{in: `-2.5E-1`, want: -2500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var m Money
+			if err := json.Unmarshal([]byte(tt.in), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m != tt.want {
+				t.Errorf("%s lu %d, %d attendu", tt.in, m, tt.want)
+			}
+			data, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back Money
+			if err := json.Unmarshal(data, &back); err != nil || back != m {
+				t.Errorf("aller-retour %s: %d (%v), %d attendu", data, back, err, m)
+			}
+		})
+	}
+}
+
+func TestMoneySQL(t *testing.T) {
+	for _, m := range []Money{0, 1, -1, NewMoney(1050.1234), NewMoney(-99999999.9999)} {
+		v, err := m.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := v.(int64); !ok {
+			t.Fatalf("Value(%s) de type %T, int64 attendu", m, v)
+		}
+		var got Money
+		if err := got.Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if got != m {
+			t.Errorf("%s relu %s", m, got)
+		}
+	}
+
+	for _, src := range []any{1050.12, "1050.12", []byte("1050.12"), nil} {
+		var m Money
+		if err := m.Scan(src); err == nil {
+			t.Errorf("Scan(%#v): erreur attendue", src)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// MonteCarloMethod détermine comment les rendements futurs sont tirés
+type MonteCarloMethod string
+
+const (
+	// MonteCarloNormal tire des rendements log-normaux centrés sur le taux effectif,
+	// avec la volatilité observée dans l'historique des NAV
+	MonteCarloNormal MonteCarloMethod = "normal"
+	// MonteCarloBootstrap rejoue au hasard les rendements observés entre NAV successives
+	MonteCarloBootstrap MonteCarloMethod = "bootstrap"
+	// MonteCarloBlockBootstrap rejoue des blocs de rendements observés consécutifs : les
+	// queues épaisses et l'autocorrélation de l'historique sont conservées dans chaque bloc
+	MonteCarloBlockBootstrap MonteCarloMethod = "block-bootstrap"
+)
+
+// MonteCarloOptions paramètre une simulation ; la valeur zéro choisit la méthode
+// normale et une graine aléatoire
+type MonteCarloOptions struct {
+	Method MonteCarloMethod
+	Seed   uint64 // Graine du générateur (0 : aléatoire) pour des résultats reproductibles
+	Block  int    // Longueur des blocs de MonteCarloBlockBootstrap, en rendements (0 : racine cubique de leur nombre)
+}
+
+// MonteCarloResult résume la distribution des valeurs simulées à la date de projection
+type MonteCarloResult struct {
+	Paths int     `json:"paths"`
+	Mean  float64 `json:"mean"`
+	P5    float64 `json:"p5"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+}
+
+// monteCarloStep est le pas de simulation de la méthode normale (un mois)
+const monteCarloStep = 1.0 / 12
+
+// computePeriodReturns calcule les rendements entre NAV successives, corrigés des flux
+func (inv *Investment) computePeriodReturns() []analytics.PeriodReturn {
+	var returns []analytics.PeriodReturn
+	for i := 1; i < len(inv.NAVHistory); i++ {
+		start, end := inv.NAVHistory[i-1], inv.NAVHistory[i]
+		years := inv.conventions.YearsBetween(start.Date.Time, end.Date.Time)
+		r := inv.flowAdjustedReturn(start, end)
+		if years <= 0 || r <= -1 {
+			continue
+		}
+		returns = append(returns, analytics.PeriodReturn{Start: start.Date.Time, End: end.Date.Time, Years: years, LogReturn: math.Log1p(r)})
+	}
+	return returns
+}
+
+// MaxMonteCarloPaths borne le nombre de trajectoires d'une simulation, dont les valeurs
+// finales sont toutes conservées pour les percentiles, et la longueur des blocs
+const MaxMonteCarloPaths = 1_000_000
+
+// checkPaths vérifie le nombre de trajectoires demandé
+func checkPaths(n int) error {
+	if n <= 0 {
+		return InvalidField("paths", n, "le nombre de trajectoires doit être positif")
+	}
+	if n > MaxMonteCarloPaths {
+		return InvalidField("paths", n, "au plus %d trajectoires", MaxMonteCarloPaths)
+	}
+	return nil
+}
+
+// monteCarloCheckEvery est le nombre de trajectoires simulées entre deux vérifications
+// de l'annulation du contexte
+const monteCarloCheckEvery = 1000
+
+// MonteCarloProject simule n trajectoires de la valeur de l'investissement jusqu'à une
+// date avec les options par défaut et retourne les percentiles de la valeur finale
+func (inv *Investment) MonteCarloProject(projectionDate string, n int) (MonteCarloResult, error) {
+	return inv.MonteCarloProjectWithContext(context.Background(), projectionDate, n, MonteCarloOptions{})
+}
+
+// MonteCarloProjectContext est MonteCarloProject interrompue si ctx est annulé
+func (inv *Investment) MonteCarloProjectContext(ctx context.Context, projectionDate string, n int) (MonteCarloResult, error) {
+	return inv.MonteCarloProjectWithContext(ctx, projectionDate, n, MonteCarloOptions{})
+}
+
+// MonteCarloProjectWith simule n trajectoires avec les options données
+func (inv *Investment) MonteCarloProjectWith(projectionDate string, n int, opts MonteCarloOptions) (MonteCarloResult, error) {
+	return inv.MonteCarloProjectWithContext(context.Background(), projectionDate, n, opts)
+}
+
+// MonteCarloProjectWithContext est MonteCarloProjectWith interrompue si ctx est annulé
+func (inv *Investment) MonteCarloProjectWithContext(ctx context.Context, projectionDate string, n int, opts MonteCarloOptions) (MonteCarloResult, error) {
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return MonteCarloResult{}, err
+	}
+	if err := checkPaths(n); err != nil {
+		return MonteCarloResult{}, err
+	}
+
+	simulate, err := inv.simulator(t, opts)
+	if err != nil {
+		return MonteCarloResult{}, err
+	}
+	rng := newMonteCarloRand(opts.Seed)
+	values := make([]float64, n)
+	for i := range values {
+		if i%monteCarloCheckEvery == 0 && ctx.Err() != nil {
+			return MonteCarloResult{}, fmt.Errorf("simulation interrompue après %d trajectoires: %w", i, ctx.Err())
+		}
+		values[i] = simulate(rng)
+	}
+	return summarizeSimulation(values), nil
+}
+
+// MonteCarloProject simule n trajectoires de la valeur du portefeuille (investissements
+// ouverts, en devise de consolidation au taux de la date), chaque investissement y
+// contribuant comme dans GetPortfolioValue : part acquise, sens de la position et
+// MissingNAVPolicy compris. Les investissements sont simulés indépendamment les uns des
+// autres.
+func (p *Portfolio) MonteCarloProject(projectionDate string, n int, opts MonteCarloOptions) (MonteCarloResult, error) {
+	return p.MonteCarloProjectContext(context.Background(), projectionDate, n, opts)
+}
+
+// MonteCarloProjectContext est MonteCarloProject interrompue si ctx est annulé
+func (p *Portfolio) MonteCarloProjectContext(ctx context.Context, projectionDate string, n int, opts MonteCarloOptions) (MonteCarloResult, error) {
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return MonteCarloResult{}, err
+	}
+	if err := checkPaths(n); err != nil {
+		return MonteCarloResult{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// Un investissement sans NAV (valorisé selon MissingNAVPolicy) ou à valeur signée n'a
+	// pas de rendements à tirer : sa valeur projetée s'ajoute telle quelle à chaque trajectoire
+	type simulation struct {
+		run    func(*rand.Rand) float64
+		factor float64 // Part acquise, sens de la position et conversion vers la devise de consolidation
+	}
+	var simulations []simulation
+	var fixed Money
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed {
+			continue
+		}
+		if inv.lacksNAV() || inv.Exposure == ExposureSigned {
+			rate, err := inv.ProjectionRate(nil)
+			if err != nil {
+				return MonteCarloResult{}, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			value, _, err := p.valueAtRate(inv, t, rate)
+			if err != nil {
+				return MonteCarloResult{}, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			fixed += value
+			continue
+		}
+		run, err := inv.simulator(t, opts)
+		if err != nil {
+			return MonteCarloResult{}, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		factor, err := p.positionValue(inv, 1, t)
+		if err != nil {
+			return MonteCarloResult{}, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		simulations = append(simulations, simulation{run: run, factor: factor})
+	}
+
+	rng := newMonteCarloRand(opts.Seed)
+	values := make([]float64, n)
+	for i := range values {
+		if i%monteCarloCheckEvery == 0 && ctx.Err() != nil {
+			return MonteCarloResult{}, fmt.Errorf("simulation interrompue après %d trajectoires: %w", i, ctx.Err())
+		}
+		values[i] = fixed.Float64()
+		for _, s := range simulations {
+			values[i] += s.run(rng) * s.factor
+		}
+	}
+	return summarizeSimulation(values), nil
+}
+
+// sortedInvestmentNames retourne les noms triés ; l'appelant doit détenir p.mu
+func (p *Portfolio) sortedInvestmentNames() []string {
+	names := make([]string, 0, len(p.Investments))
+	for name := range p.Investments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// simulator prépare le tirage d'une trajectoire de la dernière NAV jusqu'à date
+func (inv *Investment) simulator(date time.Time, opts MonteCarloOptions) (func(*rand.Rand) float64, error) {
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return nil, err
+	}
+	conv := inv.conventions
+	horizon := conv.YearsBetween(latestNAV.Date.Time, date)
+	if horizon < 0 {
+		return nil, fmt.Errorf("la date de projection doit être après la dernière NAV")
+	}
+	returns := inv.periodReturns()
+	if len(returns) < 2 {
+		return nil, fmt.Errorf("au moins 3 NAV sont nécessaires pour estimer la dispersion: %w", ErrInsufficientHistory)
+	}
+	start := latestNAV.Value.Float64()
+
+	// grow applique un rendement logarithmique sur une durée, frais déduits
+	grow := func(value, years, logReturn float64) float64 {
+		return inv.Fees.grow(conv, value, years, conv.RateFromLog(logReturn/years))
+	}
+
+	switch method := opts.Method; method {
+	case MonteCarloBlockBootstrap:
+		block, err := blockLength(opts.Block, len(returns))
+		if err != nil {
+			return nil, err
+		}
+		return func(rng *rand.Rand) float64 {
+			return analytics.BlockBootstrapPath(rng, start, horizon, returns, block, grow)
+		}, nil
+
+	case MonteCarloBootstrap:
+		return func(rng *rand.Rand) float64 {
+			return analytics.BootstrapPath(rng, start, horizon, returns, grow)
+		}, nil
+
+	case MonteCarloNormal, "":
+		rate, err := inv.EffectiveRate()
+		if err != nil {
+			return nil, err
+		}
+		// Les titres d'une vente à découvert évoluent à l'opposé de la position
+		drift := conv.RateLog(inv.navRate(rate))
+		volatility := inv.volatility()
+		return func(rng *rand.Rand) float64 {
+			return analytics.NormalPath(rng, start, horizon, monteCarloStep, drift, volatility, grow)
+		}, nil
+
+	default:
+		return nil, InvalidField("method", method, "méthode de simulation inconnue: %s", method)
+	}
+}
+
+// blockLength retourne la longueur des blocs pour n rendements observés : celle demandée,
+// sinon analytics.DefaultBlockLength
+func blockLength(requested, n int) (int, error) {
+	if requested < 0 {
+		return 0, InvalidField("block", requested, "la longueur des blocs doit être positive")
+	}
+	if requested > MaxMonteCarloPaths {
+		return 0, InvalidField("block", requested, "blocs d'au plus %d rendements", MaxMonteCarloPaths)
+	}
+	if requested == 0 {
+		requested = analytics.DefaultBlockLength(n)
+	}
+	return min(requested, n), nil
+}
+
+// newMonteCarloRand crée le générateur d'une simulation, aléatoire si seed vaut 0
+func newMonteCarloRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// summarizeSimulation calcule la moyenne et les percentiles des valeurs simulées
+func summarizeSimulation(values []float64) MonteCarloResult {
+	sort.Float64s(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return MonteCarloResult{
+		Paths: len(values),
+		Mean:  sum / float64(len(values)),
+		P5:    analytics.Percentile(values, 5),
+		P50:   analytics.Percentile(values, 50),
+		P95:   analytics.Percentile(values, 95),
+	}
+}
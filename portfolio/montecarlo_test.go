@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPortfolioMonteCarloExposureAndVesting(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *Portfolio) error
+		check func(t *testing.T, r MonteCarloResult, value float64)
+	}{
+		{
+			name:  "vente à découvert",
+			setup: func(p *Portfolio) error { return p.SetExposure("A", ExposureShort) },
+			check: func(t *testing.T, r MonteCarloResult, value float64) {
+				if value >= 0 || r.P5 >= 0 || r.P50 >= 0 || r.P95 >= 0 {
+					t.Errorf("valeur %.2f, percentiles %.2f / %.2f / %.2f : valeurs négatives attendues", value, r.P5, r.P50, r.P95)
+				}
+			},
+		},
+		{
+			name:  "titres non acquis",
+			setup: func(p *Portfolio) error { return p.SetVesting("A", "2024-01-01", 36, 12, 48) },
+			check: func(t *testing.T, r MonteCarloResult, value float64) {
+				if value != 0 || r.P5 != 0 || r.P50 != 0 || r.P95 != 0 {
+					t.Errorf("valeur %.2f, percentiles %.2f / %.2f / %.2f : 0 attendu", value, r.P5, r.P50, r.P95)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPortfolio()
+			if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			for _, nav := range []struct {
+				date  string
+				value float64
+			}{{"2024-03-01", 1010}, {"2024-06-01", 1060}, {"2024-09-01", 1040}, {"2024-12-01", 1100}} {
+				if err := p.AddNAV("A", nav.date, nav.value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tt.setup(p); err != nil {
+				t.Fatal(err)
+			}
+
+			_, value, err := p.GetPortfolioValue("2025-06-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := p.MonteCarloProject("2025-06-01", 2000, MonteCarloOptions{Seed: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, r, value)
+		})
+	}
+}
+
+func TestPortfolioMonteCarloMissingNAV(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetMissingNAVPolicy(MissingNAVInvested); err != nil {
+		t.Fatal(err)
+	}
+
+	_, value, err := p.GetPortfolioValue("2025-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := p.MonteCarloProject("2025-06-01", 100, MonteCarloOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.P50-value) > 1e-9 {
+		t.Errorf("P50 %.2f, %.2f attendu", r.P50, value)
+	}
+}
+
+func TestMonteCarloLimits(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	for _, nav := range []struct {
+		date  string
+		value float64
+	}{{"2024-03-01", 1010}, {"2024-06-01", 1060}, {"2024-09-01", 1040}} {
+		if err := p.AddNAV("A", nav.date, nav.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		paths int
+		opts  MonteCarloOptions
+	}{
+		{name: "aucune trajectoire", paths: 0},
+		{name: "trop de trajectoires", paths: MaxMonteCarloPaths + 1},
+		{name: "blocs trop longs", paths: 10, opts: MonteCarloOptions{Method: MonteCarloBlockBootstrap, Block: MaxMonteCarloPaths + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ve *ValidationError
+			if _, err := p.MonteCarloProject("2025-06-01", tt.paths, tt.opts); !errors.As(err, &ve) {
+				t.Errorf("erreur %v, ValidationError attendue", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DuplicateNAVPolicy détermine le comportement d'AddNAV quand une NAV existe déjà à la même date
+type DuplicateNAVPolicy string
+
+const (
+	// DuplicateNAVError refuse la nouvelle NAV (ErrDuplicateNAV) ; c'est le comportement par défaut
+	DuplicateNAVError DuplicateNAVPolicy = "error"
+	// DuplicateNAVReplace remplace la valeur existante par la nouvelle
+	DuplicateNAVReplace DuplicateNAVPolicy = "replace"
+	// DuplicateNAVKeepExisting conserve la valeur existante et ignore la nouvelle sans erreur
+	DuplicateNAVKeepExisting DuplicateNAVPolicy = "keep-existing"
+	// DuplicateNAVAverage retient la moyenne des valeurs en présence
+	DuplicateNAVAverage DuplicateNAVPolicy = "average"
+)
+
+// SetDuplicateNAVPolicy définit la politique appliquée par AddNAV aux dates déjà valorisées
+func (p *Portfolio) SetDuplicateNAVPolicy(policy DuplicateNAVPolicy) error {
+	switch policy {
+	case DuplicateNAVError, DuplicateNAVReplace, DuplicateNAVKeepExisting, DuplicateNAVAverage:
+	default:
+		return InvalidField("duplicate_nav_policy", policy, "politique de doublon inconnue: %s", policy)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("duplicate_nav_policy")
+	if err != nil {
+		return err
+	}
+
+	p.DuplicateNAVPolicy = policy
+	return p.recordChange(JournalEntry{Op: OpSetDuplicateNAVPolicy, Detail: string(policy), BeforeSettings: before})
+}
+
+// UpdateNAV corrige la valeur de la NAV enregistrée à une date
+func (p *Portfolio) UpdateNAV(investmentName string, date string, newValue float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, i, err := p.findNAV(investmentName, date)
+	if err != nil {
+		return err
+	}
+	if err := inv.acceptsValue("value", newValue); err != nil {
+		return err
+	}
+
+	before := inv.clone()
+	inv.NAVHistory[i].Value = NewMoney(newValue)
+	inv.invalidate()
+	p.record(OpUpdateNAV, investmentName, fmt.Sprintf("%s: %.2f -> %.2f", date, before.NAVHistory[i].Value.Float64(), newValue), before)
+	p.valueChanged(investmentName)
+	return nil
+}
+
+// DeleteNAV supprime la NAV enregistrée à une date
+func (p *Portfolio) DeleteNAV(investmentName string, date string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, i, err := p.findNAV(investmentName, date)
+	if err != nil {
+		return err
+	}
+
+	before := inv.clone()
+	inv.NAVHistory = append(inv.NAVHistory[:i], inv.NAVHistory[i+1:]...)
+	inv.invalidate()
+	p.record(OpDeleteNAV, investmentName, fmt.Sprintf("%s: %.2f", date, before.NAVHistory[i].Value.Float64()), before)
+	p.valueChanged(investmentName)
+	return nil
+}
+
+// findNAV localise la NAV d'un investissement à une date ; l'appelant doit détenir p.mu
+func (p *Portfolio) findNAV(investmentName string, date string) (*Investment, int, error) {
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return nil, 0, fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	i, found := inv.navIndex(t)
+	if !found {
+		return nil, 0, fmt.Errorf("aucune NAV pour %s au %s: %w", investmentName, date, ErrNAVNotFound)
+	}
+	return inv, i, nil
+}
+
+// navIndex recherche par dichotomie la NAV datée exactement de date dans l'historique trié
+func (inv *Investment) navIndex(date time.Time) (int, bool) {
+	i := sort.Search(len(inv.NAVHistory), func(i int) bool {
+		return !inv.NAVHistory[i].Date.Before(date)
+	})
+	return i, i < len(inv.NAVHistory) && inv.NAVHistory[i].Date.Equal(date)
+}
+
+// mergeNAVs fusionne deux historiques triés par date en un nouvel historique trié
+func mergeNAVs(a, b []NAV) []NAV {
+	merged := make([]NAV, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].Date.Before(a[0].Date.Time) {
+			merged, b = append(merged, b[0]), b[1:]
+		} else {
+			merged, a = append(merged, a[0]), a[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
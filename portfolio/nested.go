@@ -0,0 +1,223 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// holdingsAt retourne le sous-portefeuille de l'investissement désigné par path, créé
+// dans la devise de l'investissement si create est vrai ; l'appelant doit détenir p.mu
+func (p *Portfolio) holdingsAt(path string, create bool) (*Portfolio, error) {
+	current := p
+	for _, name := range strings.Split(path, "/") {
+		inv, exists := current.Investments[name]
+		if !exists {
+			return nil, fmt.Errorf("l'investissement '%s' n'existe pas: %w", path, ErrInvestmentNotFound)
+		}
+		if inv.Holdings == nil {
+			if !create {
+				return nil, fmt.Errorf("l'investissement '%s' n'a pas de lignes", path)
+			}
+			inv.Holdings = NewPortfolio()
+			inv.Holdings.BaseCurrency = inv.EffectiveCurrency()
+		}
+		current = inv.Holdings
+	}
+	return current, nil
+}
+
+// AddHolding ajoute une ligne au sous-portefeuille de l'investissement path (créé au
+// besoin) puis recalcule les NAV de la hiérarchie
+func (p *Portfolio) AddHolding(path, name string, amount, referenceRate float64, date string) error {
+	return p.changeHoldings(path, true, OpAddHolding, fmt.Sprintf("%s: %.2f au %s", name, amount, date), func(holdings *Portfolio) error {
+		return holdings.AddInvestment(name, amount, referenceRate, date)
+	})
+}
+
+// AddHoldingNAV ajoute une NAV à une ligne du sous-portefeuille de l'investissement path
+// puis recalcule les NAV de la hiérarchie
+func (p *Portfolio) AddHoldingNAV(path, name, date string, value float64) error {
+	return p.changeHoldings(path, false, OpAddHoldingNAV, fmt.Sprintf("%s: %.2f au %s", name, value, date), func(holdings *Portfolio) error {
+		return holdings.AddNAV(name, date, value)
+	})
+}
+
+// changeHoldings modifie le sous-portefeuille de l'investissement path, recalcule les NAV
+// de l'investissement de premier niveau qui le contient et journalise ce dernier ; la
+// modification est défaite si elle ou le recalcul échoue
+func (p *Portfolio) changeHoldings(path string, create bool, op JournalOp, detail string, change func(holdings *Portfolio) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	top, _, _ := strings.Cut(path, "/")
+	before := p.investmentState(top)
+	holdings, err := p.holdingsAt(path, create)
+	if err == nil {
+		err = change(holdings)
+	}
+	if err == nil {
+		err = p.Investments[top].rollUp(p.Rates)
+	}
+	if err != nil {
+		if before != nil {
+			p.attach(before)
+			p.Investments[top] = before
+		}
+		return err
+	}
+	p.record(op, top, detail, before)
+	p.valueChanged(top)
+	return nil
+}
+
+// RollUp recalcule les NAV des investissements composés de lignes, des plus profonds
+// aux plus hauts niveaux ; chaque investissement dont les NAV changent est journalisé
+func (p *Portfolio) RollUp() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before := make(map[string]*Investment)
+	for name, inv := range p.Investments {
+		if inv.Holdings != nil {
+			before[name] = inv.clone()
+		}
+	}
+	if err := p.rollUp(); err != nil {
+		return err
+	}
+	for _, name := range p.sortedInvestmentNames() {
+		if state, composed := before[name]; composed && !sameInvestment(state, p.Investments[name]) {
+			p.record(OpRollUp, name, fmt.Sprintf("%d NAV", len(p.Investments[name].NAVHistory)), state)
+			p.valueChanged(name)
+		}
+	}
+	return nil
+}
+
+// rollUp recalcule les NAV des investissements composés ; l'appelant doit détenir p.mu
+func (p *Portfolio) rollUp() error {
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Holdings == nil {
+			continue
+		}
+		if err := inv.rollUp(p.Rates); err != nil {
+			return fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+	}
+	p.changed("roll-up", "")
+	return nil
+}
+
+// rollUp remplace les NAV de l'investissement par la valeur de ses lignes à chacune de
+// leurs dates d'investissement et de NAV, chaque ligne étant interpolée entre ses propres NAV (voir
+// historicalValue). Les lignes en devise étrangère sont converties avec rates.
+func (inv *Investment) rollUp(rates Rates) error {
+	h := inv.Holdings
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Rates == nil {
+		h.Rates = rates
+	}
+	if err := h.rollUp(); err != nil {
+		return err
+	}
+
+	seen := make(map[time.Time]bool)
+	var dates []time.Time
+	for _, line := range h.Investments {
+		// La date d'investissement de chaque ligne, valorisée au montant investi, sert de point de départ
+		lineDates := []time.Time{line.InvestmentDate.Time}
+		for _, nav := range line.NAVHistory {
+			lineDates = append(lineDates, nav.Date.Time)
+		}
+		for _, date := range lineDates {
+			if !seen[date] {
+				seen[date] = true
+				dates = append(dates, date)
+			}
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	navs := make([]NAV, 0, len(dates))
+	for _, date := range dates {
+		var total Money
+		for name, line := range h.Investments {
+			value, held := line.historicalValue(date)
+			if !held {
+				continue
+			}
+			value, err := h.toBase(value*line.Sign(), line.Currency, date)
+			if err != nil {
+				return fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			total += NewMoney(value)
+		}
+		if total > 0 {
+			navs = append(navs, NAV{Date: Date{date}, Value: total.RoundCents()})
+		}
+	}
+	inv.NAVHistory = navs
+	inv.invalidate()
+	return nil
+}
+
+// tagShares répartit la valeur du sous-portefeuille à une date selon une étiquette, en
+// parts dont la somme vaut 1. Les lignes non étiquetées mais elles-mêmes composées sont
+// décomposées récursivement.
+func (p *Portfolio) tagShares(tag string, t time.Time) (map[string]float64, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values, total, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("la valeur totale des lignes est nulle")
+	}
+	shares := make(map[string]float64)
+	for name, value := range values {
+		labels, err := p.investmentLabels(p.Investments[name], tag, t)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		for label, share := range labels {
+			shares[label] += share * value / total
+		}
+	}
+	return shares, nil
+}
+
+// tagLabels retourne les parts de l'investissement par valeur d'étiquette : son
+// étiquette propre si elle est définie, sinon celle de ses lignes, sinon UntaggedLabel
+func (inv *Investment) tagLabels(tag string, t time.Time) (map[string]float64, error) {
+	if label := inv.Tags[tag]; label != "" {
+		return map[string]float64{label: 1}, nil
+	}
+	if inv.Holdings != nil {
+		return inv.Holdings.tagShares(tag, t)
+	}
+	return map[string]float64{UntaggedLabel: 1}, nil
+}
+
+// Clone retourne une copie indépendante du portefeuille, par sa forme sérialisée
+func (p *Portfolio) Clone() (*Portfolio, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("copie du portefeuille: %w", err)
+	}
+	c := NewPortfolio()
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("copie du portefeuille: %w", err)
+	}
+	p.mu.RLock()
+	c.Rates, c.Quotes = p.Rates, p.Quotes
+	p.mu.RUnlock()
+	return c, nil
+}
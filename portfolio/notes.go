@@ -0,0 +1,126 @@
+package portfolio
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Note est une annotation libre attachée à un investissement ou à une transaction : un
+// texte, la référence d'un document (contrat, DICI, avis d'opéré) et un lien
+type Note struct {
+	Date     Date   `json:"date"`               // Date de la note
+	Text     string `json:"text,omitempty"`     // Texte libre
+	Document string `json:"document,omitempty"` // Chemin ou référence d'un document, non lu par david
+	URL      string `json:"url,omitempty"`      // Lien absolu (fiche produit, espace client)
+}
+
+// validate vérifie qu'une note a un contenu et que son lien est absolu
+func (n Note) validate() error {
+	if strings.TrimSpace(n.Text) == "" && n.Document == "" && n.URL == "" {
+		return InvalidField("note", "", "la note doit avoir un texte, un document ou un lien")
+	}
+	if n.URL != "" {
+		u, err := url.Parse(n.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return InvalidField("url", n.URL, "lien invalide: %s (adresse absolue attendue)", n.URL)
+		}
+	}
+	return nil
+}
+
+// NoteEntry est une note replacée dans son contexte : l'investissement lui-même, ou la
+// transaction de date TransactionDate si Transaction est renseigné
+type NoteEntry struct {
+	Note
+	Transaction     TransactionType `json:"transaction,omitempty"`
+	TransactionDate time.Time       `json:"-"`
+}
+
+// Label retourne la note sur une ligne : contexte, texte, document et lien
+func (e NoteEntry) Label(l Locale) string {
+	var parts []string
+	if e.Text != "" {
+		parts = append(parts, e.Text)
+	}
+	if e.Document != "" {
+		parts = append(parts, l.Tf("document : %s", e.Document))
+	}
+	if e.URL != "" {
+		parts = append(parts, e.URL)
+	}
+	label := strings.Join(parts, " — ")
+	switch e.Transaction {
+	case Buy:
+		label = "[" + l.Tf("achat du %s", FormatDate(e.TransactionDate)) + "] " + label
+	case Sell:
+		label = "[" + l.Tf("vente du %s", FormatDate(e.TransactionDate)) + "] " + label
+	}
+	return label
+}
+
+// NoteEntries retourne les notes de l'investissement et de ses transactions, par date
+func (inv *Investment) NoteEntries() []NoteEntry {
+	var entries []NoteEntry
+	for _, n := range inv.Notes {
+		entries = append(entries, NoteEntry{Note: n})
+	}
+	for _, tx := range inv.Transactions {
+		for _, n := range tx.Notes {
+			entries = append(entries, NoteEntry{Note: n, Transaction: tx.Type, TransactionDate: tx.Date.Time})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date.Time) })
+	return entries
+}
+
+// AddNote attache une note à un investissement, datée du jour par défaut. Avec une date
+// de transaction, la note est attachée à la transaction de ce jour, et datée comme elle
+// par défaut ; txType la désigne s'il y en a plusieurs.
+func (p *Portfolio) AddNote(name string, note Note, transactionDate string, txType TransactionType) error {
+	if err := note.validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	if transactionDate == "" {
+		if note.Date.IsZero() {
+			note.Date = Date{Today()}
+		}
+		inv.Notes = append(inv.Notes, note)
+		p.changed("add-note", name)
+		return nil
+	}
+
+	t, err := ParseDate(transactionDate)
+	if err != nil {
+		return err
+	}
+	var matches []int
+	for i, tx := range inv.Transactions {
+		if tx.Date.Equal(t) && (txType == "" || tx.Type == txType) {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("aucune transaction de %s au %s: %w", name, transactionDate, ErrNotFound)
+	case 1:
+		tx := &inv.Transactions[matches[0]]
+		if note.Date.IsZero() {
+			note.Date = tx.Date
+		}
+		tx.Notes = append(tx.Notes, note)
+		p.changed("add-note", name)
+		return nil
+	}
+	return fmt.Errorf("%d transactions de %s au %s : préciser le type (buy ou sell)", len(matches), name, transactionDate)
+}
@@ -0,0 +1,311 @@
+package portfolio
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ofxNode est un élément d'un document OFX : un agrégat (enfants) ou une feuille (valeur)
+type ofxNode struct {
+	name     string
+	value    string
+	children []*ofxNode
+}
+
+// child retourne le premier enfant de ce nom, nil s'il est absent
+func (n *ofxNode) child(name string) *ofxNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// path suit une suite d'enfants, nil si l'un manque
+func (n *ofxNode) path(names ...string) *ofxNode {
+	for _, name := range names {
+		n = n.child(name)
+	}
+	return n
+}
+
+// list retourne les enfants du nœud désigné par le chemin, aucun si l'un manque
+func (n *ofxNode) list(names ...string) []*ofxNode {
+	if c := n.path(names...); c != nil {
+		return c.children
+	}
+	return nil
+}
+
+// text retourne la valeur de l'enfant désigné par le chemin, vide s'il est absent
+func (n *ofxNode) text(names ...string) string {
+	if c := n.path(names...); c != nil {
+		return c.value
+	}
+	return ""
+}
+
+// walk appelle fn pour chaque descendant de ce nom
+func (n *ofxNode) walk(name string, fn func(*ofxNode)) {
+	for _, c := range n.children {
+		if c.name == name {
+			fn(c)
+		}
+		c.walk(name, fn)
+	}
+}
+
+// parseOFXTree construit l'arbre d'un document OFX 1.x (SGML, feuilles sans balise
+// fermante) ou 2.x (XML). L'en-tête et les instructions de traitement sont ignorés.
+func parseOFXTree(data string) (*ofxNode, error) {
+	start := strings.Index(strings.ToUpper(data), "<OFX>")
+	if start < 0 {
+		return nil, fmt.Errorf("document OFX invalide: balise <OFX> absente")
+	}
+	data = data[start:]
+
+	root := &ofxNode{}
+	stack := []*ofxNode{root}
+	var leaf *ofxNode // Dernière feuille ouverte, qui peut être fermée en XML
+	for len(data) > 0 {
+		open := strings.IndexByte(data, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(data[open:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("document OFX invalide: balise non terminée")
+		}
+		tag := strings.ToUpper(strings.TrimSpace(data[open+1 : open+end]))
+		data = data[open+end+1:]
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue
+		}
+
+		if name, closing := strings.CutPrefix(tag, "/"); closing {
+			if leaf != nil && leaf.name == name {
+				leaf = nil
+				continue
+			}
+			leaf = nil
+			// Les feuilles SGML vides restées ouvertes sont refermées avec leur parent
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		next := strings.IndexByte(data, '<')
+		if next < 0 {
+			next = len(data)
+		}
+		node := &ofxNode{name: tag}
+		top := stack[len(stack)-1]
+		top.children = append(top.children, node)
+		if value := strings.TrimSpace(data[:next]); value != "" {
+			node.value = html.UnescapeString(value)
+			leaf = node
+		} else {
+			stack = append(stack, node)
+			leaf = nil
+		}
+		data = data[next:]
+	}
+	return root, nil
+}
+
+// parseOFXDate lit une date OFX (AAAAMMJJ, suivie éventuellement de l'heure et du fuseau)
+func parseOFXDate(raw string) (time.Time, error) {
+	if len(raw) < 8 {
+		return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+	}
+	t, err := time.Parse("20060102", raw[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+	}
+	return t, nil
+}
+
+// ParseOFX lit un relevé OFX ou QFX : relevés bancaires et de cartes (mouvements
+// d'espèces) et relevés de titres (achats, ventes, revenus, réinvestissements,
+// mouvements d'espèces et positions). Les titres sont complétés par leur ticker et leur
+// nom d'après la liste des valeurs du document.
+func ParseOFX(r io.Reader) (*Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseOFXTree(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	securities := make(map[string]StatementSecurity)
+	root.walk("SECINFO", func(n *ofxNode) {
+		id := n.text("SECID", "UNIQUEID")
+		securities[id] = StatementSecurity{ID: id, Ticker: n.text("TICKER"), Name: n.text("SECNAME")}
+	})
+	security := func(n *ofxNode) StatementSecurity {
+		id := n.text("SECID", "UNIQUEID")
+		if s, ok := securities[id]; ok {
+			return s
+		}
+		return StatementSecurity{ID: id}
+	}
+
+	st := &Statement{}
+	var errs []error
+	for _, kind := range []string{"STMTRS", "CCSTMTRS"} {
+		root.walk(kind, func(n *ofxNode) {
+			acct := StatementAccount{ID: n.text("BANKACCTFROM", "ACCTID") + n.text("CCACCTFROM", "ACCTID"), Currency: Currency(n.text("CURDEF"))}
+			for _, trn := range n.list("BANKTRANLIST") {
+				if trn.name != "STMTTRN" {
+					continue
+				}
+				e, err := parseOFXBankTransaction(trn)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("compte %s: %w", acct.ID, err))
+					continue
+				}
+				acct.Entries = append(acct.Entries, e)
+			}
+			st.Accounts = append(st.Accounts, acct)
+		})
+	}
+
+	root.walk("INVSTMTRS", func(n *ofxNode) {
+		acct := StatementAccount{ID: n.text("INVACCTFROM", "ACCTID"), Currency: Currency(n.text("CURDEF"))}
+		for _, trn := range n.list("INVTRANLIST") {
+			e, ok, err := parseOFXInvestmentTransaction(trn, security)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("compte %s: %s: %w", acct.ID, trn.name, err))
+				continue
+			}
+			if ok {
+				acct.Entries = append(acct.Entries, e)
+			}
+		}
+		for _, pos := range n.list("INVPOSLIST") {
+			inv := pos.child("INVPOS")
+			if inv == nil {
+				continue
+			}
+			p, err := parseOFXPosition(inv, security)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("compte %s: position: %w", acct.ID, err))
+				continue
+			}
+			acct.Positions = append(acct.Positions, p)
+		}
+		st.Accounts = append(st.Accounts, acct)
+	})
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("relevé OFX: %w", errs[0])
+	}
+	if len(st.Accounts) == 0 {
+		return nil, fmt.Errorf("relevé OFX: aucun compte")
+	}
+	return st, nil
+}
+
+// parseOFXBankTransaction lit un mouvement STMTTRN
+func parseOFXBankTransaction(trn *ofxNode) (StatementEntry, error) {
+	date, err := parseOFXDate(trn.text("DTPOSTED"))
+	if err != nil {
+		return StatementEntry{}, err
+	}
+	amount, err := parseStatementAmount(trn.text("TRNAMT"))
+	if err != nil {
+		return StatementEntry{}, err
+	}
+	desc := strings.TrimSpace(trn.text("NAME") + " " + trn.text("MEMO"))
+	return StatementEntry{Date: date, Kind: StatementCash, Amount: amount, Description: desc}, nil
+}
+
+// parseOFXInvestmentTransaction lit une opération de la liste INVTRANLIST ; ok est faux
+// pour les opérations sans effet sur le portefeuille (transferts de titres, fractionnements)
+func parseOFXInvestmentTransaction(trn *ofxNode, security func(*ofxNode) StatementSecurity) (e StatementEntry, ok bool, err error) {
+	if trn.name == "INVBANKTRAN" {
+		e, err = parseOFXBankTransaction(trn.child("STMTTRN"))
+		return e, err == nil, err
+	}
+
+	body := trn
+	switch {
+	case strings.HasPrefix(trn.name, "BUY"):
+		e.Kind, body = StatementBuy, trn.child("INVBUY")
+	case strings.HasPrefix(trn.name, "SELL"):
+		e.Kind, body = StatementSell, trn.child("INVSELL")
+	case trn.name == "INCOME":
+		e.Kind = StatementIncome
+	case trn.name == "REINVEST":
+		e.Kind = StatementReinvest
+	default:
+		return StatementEntry{}, false, nil
+	}
+	if body == nil {
+		return StatementEntry{}, false, fmt.Errorf("opération incomplète")
+	}
+
+	if e.Date, err = parseOFXDate(body.text("INVTRAN", "DTTRADE")); err != nil {
+		return StatementEntry{}, false, err
+	}
+	e.Description = body.text("INVTRAN", "MEMO")
+	e.Security = security(body)
+	if e.Amount, err = parseStatementAmount(body.text("TOTAL")); err != nil {
+		return StatementEntry{}, false, err
+	}
+	if e.Amount < 0 {
+		e.Amount = -e.Amount
+	}
+	if e.Kind == StatementBuy || e.Kind == StatementSell {
+		if e.Units, err = parseStatementUnits(body.text("UNITS")); err != nil {
+			return StatementEntry{}, false, err
+		}
+		if e.Price, err = parseStatementAmount(body.text("UNITPRICE")); err != nil {
+			return StatementEntry{}, false, err
+		}
+		for _, field := range []string{"COMMISSION", "FEES"} {
+			if raw := body.text(field); raw != "" {
+				fee, err := parseStatementAmount(raw)
+				if err != nil {
+					return StatementEntry{}, false, err
+				}
+				e.Fees += fee
+			}
+		}
+	}
+	return e, true, nil
+}
+
+// parseOFXPosition lit une position INVPOS
+func parseOFXPosition(pos *ofxNode, security func(*ofxNode) StatementSecurity) (StatementPosition, error) {
+	date, err := parseOFXDate(pos.text("DTPRICEASOF"))
+	if err != nil {
+		return StatementPosition{}, err
+	}
+	p := StatementPosition{Date: date, Security: security(pos)}
+	if p.Units, err = parseStatementUnits(pos.text("UNITS")); err != nil {
+		return StatementPosition{}, err
+	}
+	if p.Price, err = parseStatementAmount(pos.text("UNITPRICE")); err != nil {
+		return StatementPosition{}, err
+	}
+	if raw := pos.text("MKTVAL"); raw != "" {
+		if p.Value, err = parseStatementAmount(raw); err != nil {
+			return StatementPosition{}, err
+		}
+	}
+	return p, nil
+}
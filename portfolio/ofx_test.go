@@ -0,0 +1,150 @@
+package portfolio
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const ofxBankStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>EUR
+<BANKACCTFROM><BANKID>30004<ACCTID>FR7630004000031234567890143<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST><DTSTART>20240101<DTEND>20240131
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240105120000<TRNAMT>1500.00<NAME>SALAIRE</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240110<TRNAMT>-42.50<NAME>LIBRAIRIE<MEMO>CB 0901</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+const ofxInvestmentStatement = `<?xml version="1.0"?>
+<OFX>
+<INVSTMTMSGSRSV1><INVSTMTTRNRS><INVSTMTRS>
+<CURDEF>USD</CURDEF>
+<INVACCTFROM><BROKERID>broker.example</BROKERID><ACCTID>U123</ACCTID></INVACCTFROM>
+<INVTRANLIST>
+<BUYSTOCK><INVBUY><INVTRAN><FITID>1</FITID><DTTRADE>20240301</DTTRADE><MEMO>Achat</MEMO></INVTRAN>
+<SECID><UNIQUEID>US0378331005</UNIQUEID><UNIQUEIDTYPE>ISIN</UNIQUEIDTYPE></SECID>
+<UNITS>10</UNITS><UNITPRICE>170.25</UNITPRICE><COMMISSION>1.00</COMMISSION><TOTAL>-1703.50</TOTAL></INVBUY></BUYSTOCK>
+<INCOME><INVTRAN><FITID>2</FITID><DTTRADE>20240315</DTTRADE></INVTRAN>
+<SECID><UNIQUEID>US0378331005</UNIQUEID></SECID><TOTAL>2.40</TOTAL></INCOME>
+<TRANSFER><INVTRAN><FITID>3</FITID><DTTRADE>20240320</DTTRADE></INVTRAN></TRANSFER>
+</INVTRANLIST>
+<INVPOSLIST><POSSTOCK><INVPOS><SECID><UNIQUEID>US0378331005</UNIQUEID></SECID>
+<UNITS>10</UNITS><UNITPRICE>171.00</UNITPRICE><MKTVAL>1710.00</MKTVAL><DTPRICEASOF>20240329</DTPRICEASOF></INVPOS></POSSTOCK></INVPOSLIST>
+</INVSTMTRS></INVSTMTTRNRS></INVSTMTMSGSRSV1>
+<SECLISTMSGSRSV1><SECLIST><STOCKINFO><SECINFO><SECID><UNIQUEID>US0378331005</UNIQUEID></SECID>
+<SECNAME>Apple Inc.</SECNAME><TICKER>AAPL</TICKER></SECINFO></STOCKINFO></SECLIST></SECLISTMSGSRSV1>
+</OFX>`
+
+func TestParseOFX(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	apple := StatementSecurity{ID: "US0378331005", Ticker: "AAPL", Name: "Apple Inc."}
+	tests := []struct {
+		name      string
+		in        string
+		account   string
+		currency  Currency
+		entries   []StatementEntry
+		positions []StatementPosition
+	}{
+		{
+			name:     "banque SGML",
+			in:       ofxBankStatement,
+			account:  "FR7630004000031234567890143",
+			currency: "EUR",
+			entries: []StatementEntry{
+				{Date: day(2024, time.January, 5), Kind: StatementCash, Amount: 15000000, Description: "SALAIRE"},
+				{Date: day(2024, time.January, 10), Kind: StatementCash, Amount: -425000, Description: "LIBRAIRIE CB 0901"},
+			},
+		},
+		{
+			name:     "titres XML",
+			in:       ofxInvestmentStatement,
+			account:  "U123",
+			currency: "USD",
+			entries: []StatementEntry{
+				{Date: day(2024, time.March, 1), Kind: StatementBuy, Amount: 17035000, Units: 1000000000, Price: 1702500, Fees: 10000, Security: apple, Description: "Achat"},
+				{Date: day(2024, time.March, 15), Kind: StatementIncome, Amount: 24000, Security: apple},
+			},
+			positions: []StatementPosition{
+				{Date: day(2024, time.March, 29), Security: apple, Units: 1000000000, Price: 1710000, Value: 17100000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ParseOFX(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(st.Accounts) != 1 {
+				t.Fatalf("%d comptes lus, 1 attendu", len(st.Accounts))
+			}
+			acct := st.Accounts[0]
+			if acct.ID != tt.account || acct.Currency != tt.currency {
+				t.Errorf("compte %s en %s, %s en %s attendu", acct.ID, acct.Currency, tt.account, tt.currency)
+			}
+			if len(acct.Entries) != len(tt.entries) {
+				t.Fatalf("%d opérations lues, %d attendues: %+v", len(acct.Entries), len(tt.entries), acct.Entries)
+			}
+			for i, want := range tt.entries {
+				if got := acct.Entries[i]; !got.Date.Equal(want.Date) || got.Kind != want.Kind || got.Amount != want.Amount ||
+					got.Units != want.Units || got.Price != want.Price || got.Fees != want.Fees ||
+					got.Security != want.Security || got.Description != want.Description {
+					t.Errorf("opération %d = %+v, %+v attendue", i, got, want)
+				}
+			}
+			if len(acct.Positions) != len(tt.positions) {
+				t.Fatalf("%d positions lues, %d attendues", len(acct.Positions), len(tt.positions))
+			}
+			for i, want := range tt.positions {
+				if got := acct.Positions[i]; !got.Date.Equal(want.Date) || got.Security != want.Security ||
+					got.Units != want.Units || got.Price != want.Price || got.Value != want.Value {
+					t.Errorf("position %d = %+v, %+v attendue", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOFXMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "sans balise OFX", in: "OFXHEADER:100\n"},
+		{name: "balise non terminée", in: "<OFX><STMTRS"},
+		{name: "relevé sans liste", in: "<OFX><STMTRS >"},
+		{name: "titres sans listes", in: "<OFX><INVSTMTRS></INVSTMTRS></OFX>"},
+		{name: "aucun compte", in: "<OFX></OFX>"},
+		{name: "date invalide", in: "<OFX><STMTRS><BANKTRANLIST><STMTTRN><DTPOSTED>2024<TRNAMT>1</STMTTRN></BANKTRANLIST></STMTRS></OFX>"},
+		{name: "montant invalide", in: "<OFX><STMTRS><BANKTRANLIST><STMTTRN><DTPOSTED>20240101<TRNAMT>abc</STMTTRN></BANKTRANLIST></STMTRS></OFX>"},
+		{name: "achat incomplet", in: "<OFX><INVSTMTRS><INVTRANLIST><BUYSTOCK></BUYSTOCK></INVTRANLIST></INVSTMTRS></OFX>"},
+		{name: "virement sans mouvement", in: "<OFX><INVSTMTRS><INVTRANLIST><INVBANKTRAN></INVBANKTRAN></INVTRANLIST></INVSTMTRS></OFX>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ParseOFX(strings.NewReader(tt.in))
+			if err == nil && (len(st.Accounts) != 1 || len(st.Accounts[0].Entries) != 0) {
+				t.Errorf("ParseOFX(%q) = %+v: erreur ou compte vide attendu", tt.in, st)
+			}
+		})
+	}
+}
+
+func FuzzParseOFX(f *testing.F) {
+	for _, seed := range []string{ofxBankStatement, ofxInvestmentStatement, "<OFX><STMTRS >", "<OFX><INVSTMTRS>", "<OFX><CCSTMTRS><BANKTRANLIST>", "<OFX><INVBANKTRAN>"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		st, err := ParseOFX(strings.NewReader(in))
+		if err == nil && len(st.Accounts) == 0 {
+			t.Errorf("ParseOFX(%q): relevé sans compte accepté", in)
+		}
+	})
+}
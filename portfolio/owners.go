@@ -0,0 +1,185 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TagOwner désigne le titulaire dans les répartitions par étiquette (AllocationByTag) :
+// le titulaire de l'investissement, à défaut celui du portefeuille
+const TagOwner = "owner"
+
+// Titulaires usuels ; tout autre nom (un enfant par son prénom…) est accepté
+const (
+	OwnerMe     = "me"     // Le titulaire principal
+	OwnerSpouse = "spouse" // Le conjoint
+	OwnerJoint  = "joint"  // Détention commune, comptée à part
+)
+
+// UnassignedOwner regroupe les investissements sans titulaire, ni le leur ni celui du portefeuille
+const UnassignedOwner = "non attribué"
+
+// ownerOf retourne le titulaire d'un investissement ; l'appelant doit détenir p.mu
+func (p *Portfolio) ownerOf(inv *Investment) string {
+	switch {
+	case inv.Owner != "":
+		return inv.Owner
+	case p.Owner != "":
+		return p.Owner
+	default:
+		return UnassignedOwner
+	}
+}
+
+// investmentLabels retourne les parts d'un investissement par valeur d'étiquette,
+// TagOwner désignant son titulaire ; l'appelant doit détenir p.mu
+func (p *Portfolio) investmentLabels(inv *Investment, tag string, t time.Time) (map[string]float64, error) {
+	if tag == TagOwner {
+		return map[string]float64{p.ownerOf(inv): 1}, nil
+	}
+	return inv.tagLabels(tag, t)
+}
+
+// SetOwner définit le titulaire d'un investissement, ou celui du portefeuille (le compte,
+// titulaire par défaut de ses investissements) si name est vide ; un titulaire vide
+// rétablit celui du portefeuille
+func (p *Portfolio) SetOwner(name, owner string) error {
+	owner = strings.TrimSpace(owner)
+	if owner == UnassignedOwner {
+		return InvalidField("owner", owner, "titulaire réservé: %s", owner)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if name == "" {
+		before, err := p.settingsState("owner")
+		if err != nil {
+			return err
+		}
+		p.Owner = owner
+		return p.recordChange(JournalEntry{Op: OpSetOwner, Detail: fmt.Sprintf("titulaire %q", owner), BeforeSettings: before})
+	}
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	inv.Owner = owner
+	p.record(OpSetOwner, name, fmt.Sprintf("titulaire %q", owner), before)
+	return nil
+}
+
+// Owners retourne les titulaires des investissements, triés
+func (p *Portfolio) Owners() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, inv := range p.Investments {
+		seen[p.ownerOf(inv)] = true
+	}
+	owners := make([]string, 0, len(seen))
+	for owner := range seen {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// ForOwner retourne une copie du portefeuille restreinte aux investissements d'un
+// titulaire, pour en tirer valorisations, répartitions et projections. La copie ne
+// peut pas être enregistrée.
+func (p *Portfolio) ForOwner(owner string) (*Portfolio, error) {
+	c, err := p.Clone()
+	if err != nil {
+		return nil, err
+	}
+	c.ownerView = owner
+
+	p.mu.RLock()
+	for name, inv := range p.Investments {
+		if !strings.EqualFold(p.ownerOf(inv), owner) {
+			delete(c.Investments, name)
+		}
+	}
+	held := len(p.Investments) > 0
+	p.mu.RUnlock()
+	if len(c.Investments) == 0 && held {
+		return nil, fmt.Errorf("aucun investissement pour le titulaire '%s' (%s): %w", owner, strings.Join(p.Owners(), ", "), ErrNotFound)
+	}
+	return c, nil
+}
+
+// OwnerSummary est la part d'un titulaire dans la vue du foyer
+type OwnerSummary struct {
+	Owner     string   `json:"owner"`
+	Value     float64  `json:"value"`               // En devise de consolidation
+	Invested  float64  `json:"invested"`            // Capital net investi, en devise de consolidation
+	Weight    float64  `json:"weight"`              // Part de la valeur du foyer (%)
+	Projected *float64 `json:"projected,omitempty"` // Valeur projetée à l'horizon demandé
+}
+
+// Household répartit la valeur du portefeuille à une date, et sa projection à l'horizon
+// to s'il est renseigné, entre ses titulaires ; la détention commune est comptée à part
+func (p *Portfolio) Household(date, to string) ([]OwnerSummary, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	var horizon time.Time
+	if to != "" {
+		if horizon, err = ParseDate(to); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values, total, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, err
+	}
+	var projected map[string]float64
+	if !horizon.IsZero() {
+		if projected, _, err = p.portfolioValue(horizon); err != nil {
+			return nil, err
+		}
+	}
+
+	byOwner := make(map[string]*OwnerSummary)
+	for name, value := range values {
+		inv := p.Investments[name]
+		owner := p.ownerOf(inv)
+		s, exists := byOwner[owner]
+		if !exists {
+			s = &OwnerSummary{Owner: owner}
+			if projected != nil {
+				s.Projected = new(float64)
+			}
+			byOwner[owner] = s
+		}
+		invested, err := p.toBase(inv.NetInvested().Float64()*inv.Sign(), inv.Currency, t)
+		if err != nil {
+			return nil, err
+		}
+		s.Value += value
+		s.Invested += invested
+		if projected != nil {
+			*s.Projected += projected[name]
+		}
+	}
+
+	summaries := make([]OwnerSummary, 0, len(byOwner))
+	for _, s := range byOwner {
+		if total != 0 {
+			s.Weight = s.Value / total * 100
+		}
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Owner < summaries[j].Owner })
+	return summaries, nil
+}
@@ -0,0 +1,67 @@
+package portfolio
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelEach appelle fn(i) pour i dans [0, n) avec au plus workers goroutines et
+// attend la fin de tous les appels. Chaque appel écrit son résultat à l'indice i, ce qui
+// garantit un ordre des résultats indépendant de l'ordonnancement.
+func parallelEach(n, workers int, fn func(i int)) {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// SetValuationWorkers borne le nombre de goroutines valorisant les investissements en
+// parallèle (GOMAXPROCS si 0, valorisation séquentielle si 1). La source de taux de
+// change doit alors supporter les appels concurrents.
+func (p *Portfolio) SetValuationWorkers(n int) error {
+	if n < 0 {
+		return InvalidField("workers", n, "le nombre de goroutines ne peut pas être négatif")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.workers = n
+	p.changed("set-valuation-workers", "")
+	return nil
+}
+
+// ValuationWorkers retourne le nombre de goroutines utilisées par les valorisations
+func (p *Portfolio) ValuationWorkers() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.valuationWorkers()
+}
+
+// valuationWorkers retourne le nombre de goroutines de valorisation ; l'appelant doit
+// détenir p.mu
+func (p *Portfolio) valuationWorkers() int {
+	if p.workers == 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return p.workers
+}
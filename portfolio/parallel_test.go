@@ -0,0 +1,106 @@
+package portfolio
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// syntheticPortfolio construit un portefeuille de n investissements dotés de NAV
+// mensuelles sur years années
+func syntheticPortfolio(tb testing.TB, n, years int) *Portfolio {
+	tb.Helper()
+	p := NewPortfolio()
+	start := Today().AddDate(-years, 0, 0)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("INV%04d", i)
+		if err := p.AddInvestment(name, 10000, 3+float64(i%5), FormatDate(start)); err != nil {
+			tb.Fatal(err)
+		}
+		value := 10000.0
+		for m := 1; m <= years*12; m++ {
+			// Rendements mensuels alternés pour un historique non trivial
+			value *= 1 + float64((i+m)%7-2)/100
+			if err := p.AddNAV(name, FormatDate(start.AddDate(0, m, 0)), value); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	return p
+}
+
+func TestParallelEach(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			var calls atomic.Int32
+			got := make([]int, 50)
+			parallelEach(len(got), workers, func(i int) {
+				calls.Add(1)
+				got[i] = i * i
+			})
+			if calls.Load() != int32(len(got)) {
+				t.Errorf("%d appels, %d attendus", calls.Load(), len(got))
+			}
+			for i, v := range got {
+				if v != i*i {
+					t.Errorf("résultat %d = %d, %d attendu", i, v, i*i)
+				}
+			}
+		})
+	}
+}
+
+func TestParallelValuationMatchesSerial(t *testing.T) {
+	p := syntheticPortfolio(t, 40, 3)
+	date := FormatDate(Today().AddDate(2, 0, 0))
+	value := func(workers int) (map[string]float64, float64) {
+		if err := p.SetValuationWorkers(workers); err != nil {
+			t.Fatal(err)
+		}
+		values, total, err := p.GetPortfolioValue(date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return values, total
+	}
+
+	serial, serialTotal := value(1)
+	for _, workers := range []int{0, 2, 8} {
+		values, total := value(workers)
+		if total != serialTotal {
+			t.Errorf("%d workers: total %.4f, %.4f en séquentiel", workers, total, serialTotal)
+		}
+		for name, v := range serial {
+			if values[name] != v {
+				t.Errorf("%d workers: %s vaut %.4f, %.4f en séquentiel", workers, name, values[name], v)
+			}
+		}
+	}
+
+	if err := p.SetValuationWorkers(-1); err == nil {
+		t.Error("SetValuationWorkers(-1): erreur attendue")
+	}
+}
+
+func BenchmarkGetPortfolioValue(b *testing.B) {
+	p := syntheticPortfolio(b, 200, 10)
+	date := FormatDate(Today().AddDate(10, 0, 0))
+	for _, bm := range []struct {
+		name    string
+		workers int
+	}{
+		{name: "serial", workers: 1},
+		{name: "parallel", workers: 0},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			if err := p.SetValuationWorkers(bm.workers); err != nil {
+				b.Fatal(err)
+			}
+			for b.Loop() {
+				if _, _, err := p.GetPortfolioValue(date); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// Return calcule le rendement corrigé des flux (Dietz modifié) entre deux dates (%), les
+// valeurs étant interpolées entre les NAV encadrantes. from vide désigne la date
+// d'investissement et to vide la dernière NAV. Avec annualize, le rendement est converti
+// en taux annuel, sauf sur une période plus courte que la durée minimale des conventions
+// (ErrPeriodTooShort).
+func (inv *Investment) Return(from, to string, annualize bool) (float64, error) {
+	start, end, err := inv.returnPeriod(from, to)
+	if err != nil {
+		return 0, err
+	}
+	startValue, endValue, err := inv.periodValues(start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := inv.exposureReturn(dietzReturn(NAV{Date: Date{start}, Value: NewMoney(startValue)}, NAV{Date: Date{end}, Value: NewMoney(endValue)},
+		append(inv.paidDistributionFlows(), inv.CashFlows...)))
+	if err != nil {
+		return 0, err
+	}
+	return inv.conventions.PeriodRate(r, start, end, annualize)
+}
+
+// returnPeriod résout la période d'un rendement : from vide désigne la date
+// d'investissement et to vide la dernière NAV, qui borne la période
+func (inv *Investment) returnPeriod(from, to string) (start, end time.Time, err error) {
+	start, end, err = ParsePeriod(from, to)
+	if err != nil {
+		return start, end, err
+	}
+	latest, err := inv.GetLatestNAV()
+	if err != nil {
+		return start, end, err
+	}
+	if start.IsZero() {
+		start = inv.InvestmentDate.Time
+	}
+	if end.IsZero() {
+		end = latest.Date.Time
+	}
+	if end.After(latest.Date.Time) {
+		return start, end, fmt.Errorf("aucune NAV après le %s: %w", FormatDate(latest.Date.Time), ErrNAVNotFound)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("la fin de la période doit être après son début: %w", ErrInvalidDate)
+	}
+	return start, end, nil
+}
+
+// periodValues retourne les valeurs interpolées en début et fin de période
+func (inv *Investment) periodValues(start, end time.Time) (startValue, endValue float64, err error) {
+	startValue, held := inv.historicalValue(start)
+	endValue, heldEnd := inv.historicalValue(end)
+	if !held || !heldEnd {
+		return 0, 0, fmt.Errorf("'%s' n'est pas détenu sur toute la période", inv.Name)
+	}
+	return startValue, endValue, nil
+}
+
+// Return calcule le rendement corrigé des flux du portefeuille entre deux dates (%), en
+// devise de consolidation, à partir de la dernière NAV connue de chaque investissement
+// ouvert. Avec annualize, le rendement est converti en taux annuel comme pour
+// Investment.Return.
+func (p *Portfolio) Return(from, to string, annualize bool) (float64, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return 0, err
+	}
+	if start.IsZero() {
+		return 0, fmt.Errorf("la date de début est obligatoire: %w", ErrInvalidDate)
+	}
+	if end.IsZero() {
+		end = Today()
+	}
+	if !end.After(start) {
+		return 0, fmt.Errorf("la fin de la période doit être après son début: %w", ErrInvalidDate)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	startValue, complete, err := p.lastKnownValue(start)
+	if err != nil {
+		return 0, err
+	}
+	if !complete || startValue <= 0 {
+		return 0, fmt.Errorf("tous les investissements n'ont pas de NAV au %s: %w", FormatDate(start), ErrInsufficientHistory)
+	}
+	endValue, _, err := p.lastKnownValue(end)
+	if err != nil {
+		return 0, err
+	}
+	flows, err := p.externalFlowsBetween(start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	r := dietzReturn(NAV{Date: Date{start}, Value: NewMoney(startValue)}, NAV{Date: Date{end}, Value: NewMoney(endValue)}, flows)
+	return p.rateConventions().PeriodRate(r, start, end, annualize)
+}
@@ -0,0 +1,123 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SaveJSON enregistre le portefeuille complet (investissements et historiques de NAV)
+// dans un fichier JSON, ou au format binaire si SetStorageFormat l'a choisi ou si le
+// portefeuille a été chargé depuis un fichier binaire. L'écriture passe par un fichier
+// temporaire renommé ensuite, pour ne jamais laisser un fichier à moitié écrit en cas
+// d'interruption. Un portefeuille doté d'une phrase secrète (SetPassphrase) est
+// enregistré chiffré.
+func (p *Portfolio) SaveJSON(path string) error {
+	if p.ownerView != "" {
+		return fmt.Errorf("vue restreinte au titulaire '%s': enregistrement refusé, relancer sans --owner", p.ownerView)
+	}
+	format := p.storageFormat()
+	data, err := p.encode(format)
+	if err != nil {
+		return fmt.Errorf("sérialisation du portefeuille: %w", err)
+	}
+	key := p.encryptionKey()
+	if key != nil {
+		if data, err = key.seal(data); err != nil {
+			return fmt.Errorf("chiffrement du portefeuille: %w", err)
+		}
+	}
+	if key != nil || format != FormatBinary {
+		data = append(data, '\n')
+	}
+
+	if err := WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+	Logger().Debug("portefeuille enregistré", "path", path, "bytes", len(data), "format", format, "encrypted", key != nil)
+	if err := p.recordAudit(path); err != nil {
+		return fmt.Errorf("journal d'audit: %w", err)
+	}
+	return nil
+}
+
+// WriteFileAtomic écrit un fichier via un fichier temporaire renommé ensuite
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("création du fichier temporaire: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("écriture de %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("écriture de %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("écriture de %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("écriture de %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPortfolioJSON charge un portefeuille précédemment enregistré avec SaveJSON, au
+// format JSON ou binaire (détecté d'après le contenu, et conservé aux enregistrements
+// suivants). Un fichier chiffré est déchiffré avec la phrase secrète de DAVID_PASSPHRASE,
+// ou saisie sur le terminal, et reste chiffré aux enregistrements suivants.
+func LoadPortfolioJSON(path string) (*Portfolio, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("lecture de %s: %w", path, err)
+	}
+
+	var key *portfolioKey
+	if envelope := encryptedEnvelope(data); envelope != nil {
+		passphrase, err := ReadPassphrase(fmt.Sprintf("Phrase secrète de %s: ", path))
+		if err != nil {
+			return nil, err
+		}
+		if data, key, err = envelope.open(passphrase); err != nil {
+			return nil, fmt.Errorf("lecture de %s: %w", path, err)
+		}
+	}
+
+	p := NewPortfolio()
+	p.key = key
+	format, err := decodePortfolio(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("lecture de %s: %w", path, err)
+	}
+	p.format = format
+	if p.Investments == nil {
+		p.Investments = make(map[string]*Investment)
+	}
+
+	for name, inv := range p.Investments {
+		if inv == nil {
+			return nil, fmt.Errorf("lecture de %s: investissement '%s' vide", path, name)
+		}
+		// La clé de la map fait foi pour le nom ; les fichiers antérieurs aux identifiants
+		// internes en reçoivent un, enregistré à la prochaine sauvegarde
+		inv.Name = name
+		if inv.ID == "" {
+			inv.ID = newInvestmentID()
+		}
+		if inv.NAVHistory == nil {
+			inv.NAVHistory = make([]NAV, 0)
+		}
+		sortNAVs(inv.NAVHistory)
+	}
+	// Les comptes rémunérés et les obligations sont valorisés à la date du chargement
+	p.AccrueInterest(Today())
+	// Les intérêts courus au chargement ne sont pas attribués à l'utilisateur
+	p.resetAudit()
+
+	Logger().Debug("portefeuille chargé", "path", path, "bytes", len(data), "investments", len(p.Investments), "format", format, "encrypted", key != nil)
+	return p, nil
+}
@@ -0,0 +1,139 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// ContributionPlan est un plan de versements programmés (investissement progressif).
+// Celui d'un investissement (Investment.Plan) est une simple hypothèse de projection ;
+// celui d'un RecurringPlan est exécuté. Les projections comptent dans les deux cas les
+// échéances postérieures à la dernière NAV (voir PlannedContributions).
+type ContributionPlan struct {
+	Amount    Money      `json:"amount"`       // Montant de chaque versement
+	Frequency SeriesStep `json:"frequency"`    // Périodicité des versements
+	Start     Date       `json:"start"`        // Date du premier versement non encore enregistré, avant report au jour ouvré
+	End       Date       `json:"end,omitzero"` // Date au-delà de laquelle le plan s'arrête (zéro : sans fin)
+}
+
+// newContributionPlan valide un plan de versements de amount à la périodicité frequency
+// dès start ; end peut être vide pour un plan sans fin
+func newContributionPlan(amount float64, frequency SeriesStep, start, end string) (ContributionPlan, error) {
+	if NewMoney(amount) <= 0 {
+		return ContributionPlan{}, fmt.Errorf("le montant des versements doit être positif: %w", ErrInvalidAmount)
+	}
+	startDate, endDate, err := ParsePeriod(start, end)
+	if err != nil {
+		return ContributionPlan{}, err
+	}
+	if startDate.IsZero() {
+		return ContributionPlan{}, fmt.Errorf("la date de premier versement est obligatoire: %w", ErrInvalidDate)
+	}
+	if !endDate.IsZero() && endDate.Before(startDate) {
+		return ContributionPlan{}, fmt.Errorf("la fin du plan doit être après son début: %w", ErrInvalidDate)
+	}
+	if _, err := frequency.add(startDate, 1); err != nil {
+		return ContributionPlan{}, err
+	}
+	return ContributionPlan{Amount: NewMoney(amount), Frequency: frequency, Start: Date{startDate}, End: Date{endDate}}, nil
+}
+
+// schedule retourne les échéances du plan, reportées au jour ouvré selon le calendrier
+// cal (nil : aucun report), datées dans ]from, to]
+func (c *ContributionPlan) schedule(from, to time.Time, cal *Calendar) []time.Time {
+	var dates []time.Time
+	for i := 0; ; i++ {
+		// Une périodicité invalide est refusée par newContributionPlan
+		due, err := c.Frequency.add(c.Start.Time, i)
+		if err != nil || (!c.End.IsZero() && due.After(c.End.Time)) {
+			break
+		}
+		date := cal.Roll(due)
+		if date.After(to) {
+			break
+		}
+		if date.After(from) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// SetContributionPlan définit le plan de versements programmés d'un investissement ;
+// un montant nul supprime le plan. end peut être vide pour un plan sans fin.
+func (p *Portfolio) SetContributionPlan(investmentName string, amount float64, frequency SeriesStep, start, end string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if amount == 0 {
+		before := inv.clone()
+		inv.Plan = nil
+		p.record(OpSetPlan, investmentName, "aucun plan", before)
+		return nil
+	}
+	plan, err := newContributionPlan(amount, frequency, start, end)
+	if err != nil {
+		return err
+	}
+
+	before := inv.clone()
+	inv.Plan = &plan
+	p.record(OpSetPlan, investmentName, fmt.Sprintf("%.2f %s dès le %s", amount, frequency, start), before)
+	return nil
+}
+
+// plans retourne le plan de versements de l'investissement puis ceux des plans
+// récurrents qui l'alimentent
+func (inv *Investment) plans() []*ContributionPlan {
+	plans := make([]*ContributionPlan, 0, len(inv.recurring)+1)
+	if inv.Plan != nil {
+		plans = append(plans, inv.Plan)
+	}
+	for _, r := range inv.recurring {
+		plans = append(plans, &r.ContributionPlan)
+	}
+	return plans
+}
+
+// PlannedContributions retourne les échéances de ses plans de versements (voir plans) et
+// les appels de fonds attendus datés dans ]from, to], ainsi que les apports déjà
+// enregistrés dans cet intervalle, qu'aucune NAV antérieure n'inclut
+func (inv *Investment) PlannedContributions(from, to time.Time) []CashFlow {
+	flows := inv.expectedCalls(from, to)
+	for _, cf := range inv.CashFlows {
+		if cf.Type == Contribution && cf.Date.After(from) && !cf.Date.After(to) {
+			flows = append(flows, cf)
+		}
+	}
+	for _, plan := range inv.plans() {
+		for _, date := range plan.schedule(from, to, inv.calendar) {
+			flows = append(flows, CashFlow{Date: Date{date}, Amount: plan.Amount, Type: Contribution})
+		}
+	}
+	return flows
+}
+
+// compound capitalise start jusqu'à end au taux annuel rate (%), en ajoutant chaque
+// apport (net des frais d'entrée) à sa date ; les frais courants sont déduits si fees
+// n'est pas nil. Les apports doivent être datés dans ]start.Date, end] ; durées et
+// capitalisation suivent les conventions c.
+func compound(c analytics.RateConventions, fees *FeeSchedule, start NAV, end time.Time, rate float64, contributions []CashFlow) float64 {
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].Date.Before(contributions[j].Date.Time)
+	})
+
+	value := start.Value.Float64()
+	current := start.Date
+	for _, cf := range contributions {
+		value = fees.grow(c, value, c.YearsBetween(current.Time, cf.Date.Time), rate) + fees.netContribution(cf.Amount.Float64())
+		current = cf.Date
+	}
+	return fees.grow(c, value, c.YearsBetween(current.Time, end), rate)
+}
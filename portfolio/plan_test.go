@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPlannedContributions(t *testing.T) {
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.April, 30, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		setup    func(p *Portfolio) error
+		calendar *Calendar
+		want     []string
+	}{
+		{name: "sans plan"},
+		{
+			name:  "plan de l'investissement",
+			setup: func(p *Portfolio) error { return p.SetContributionPlan("A", 100, StepMonthly, "2025-02-01", "") },
+			want:  []string{"2025-02-01 100.00", "2025-03-01 100.00", "2025-04-01 100.00"},
+		},
+		{
+			name:  "plan récurrent",
+			setup: func(p *Portfolio) error { return p.AddRecurringPlan("P", "A", 50, StepQuarterly, "2025-03-15", "") },
+			want:  []string{"2025-03-15 50.00"},
+		},
+		{
+			name: "plans cumulés",
+			setup: func(p *Portfolio) error {
+				if err := p.SetContributionPlan("A", 100, StepMonthly, "2025-03-01", "2025-03-31"); err != nil {
+					return err
+				}
+				return p.AddRecurringPlan("P", "A", 50, StepMonthly, "2025-04-05", "")
+			},
+			want: []string{"2025-03-01 100.00", "2025-04-05 50.00"},
+		},
+		{
+			name: "report au jour ouvré",
+			setup: func(p *Portfolio) error {
+				return p.SetContributionPlan("A", 100, StepMonthly, "2025-03-01", "2025-03-31")
+			},
+			calendar: &Calendar{Rolling: RollFollowing},
+			want:     []string{"2025-03-03 100.00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPortfolio()
+			if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if tt.calendar != nil {
+				if err := p.SetCalendar(tt.calendar); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.setup != nil {
+				if err := tt.setup(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []string
+			for _, cf := range p.Investments["A"].PlannedContributions(from, to) {
+				got = append(got, fmt.Sprintf("%s %s", FormatDate(cf.Date.Time), cf.Amount))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("versements %v, %v attendus", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContributionPlanInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     float64
+		frequency  SeriesStep
+		start, end string
+	}{
+		{name: "montant négatif", amount: -1, frequency: StepMonthly, start: "2025-01-01"},
+		{name: "sans début", amount: 100, frequency: StepMonthly},
+		{name: "fin avant le début", amount: 100, frequency: StepMonthly, start: "2025-01-01", end: "2024-12-01"},
+		{name: "périodicité inconnue", amount: 100, frequency: "fortnightly", start: "2025-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPortfolio()
+			if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SetContributionPlan("A", tt.amount, tt.frequency, tt.start, tt.end); err == nil {
+				t.Error("SetContributionPlan: erreur attendue")
+			}
+			if err := p.AddRecurringPlan("P", "A", tt.amount, tt.frequency, tt.start, tt.end); err == nil {
+				t.Error("AddRecurringPlan: erreur attendue")
+			}
+		})
+	}
+}
+
+func TestRecurringPlanLegacyNext(t *testing.T) {
+	var r RecurringPlan
+	if err := json.Unmarshal([]byte(`{"investment":"A","amount":100,"frequency":"monthly","next":"2025-03-01"}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if got := FormatDate(r.Start.Time); got != "2025-03-01" || r.Amount != NewMoney(100) {
+		t.Errorf("plan lu %+v, 100.00 dès le 2025-03-01 attendu", r)
+	}
+	data, err := json.Marshal(&r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"investment":"A","amount":100.00,"frequency":"monthly","start":"2025-03-01"}`; string(data) != want {
+		t.Errorf("plan enregistré %s, %s attendu", data, want)
+	}
+}
@@ -0,0 +1,173 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Protocole des extensions : une extension est un exécutable déclaré dans la table
+// [plugins] de la configuration (nom = "chemin"). Chaque appel lance l'exécutable, lui
+// écrit une requête JSON sur l'entrée standard et lit une réponse JSON sur sa sortie
+// standard ; sa sortie d'erreur est relayée telle quelle.
+//
+//	requête : {"protocol": 1, "method": "quote", "params": {"identifier": "AIR.PA"}}
+//	réponse : {"result": {"date": "2025-03-01", "price": 172.4, "currency": "EUR"}}
+//	échec :   {"error": "symbole inconnu"}
+//
+// Méthodes :
+//
+//	describe  {}                                    → {"name", "version", "capabilities": ["quotes", "importer", "renderer"], "formats": [...]}
+//	quote     {"identifier"}                        → {"date", "price", "currency"}
+//	detect    {"head"}                              → {"match": bool}
+//	parse     {"data", "account", "month_first"}    → {"accounts": [{"id", "currency", "entries": [...], "positions": [...]}]}
+//	render    {"format", "report"}                  → {"content"}
+//
+// head et data sont le contenu du fichier encodé en base64 (champ JSON []byte), les
+// dates sont au format AAAA-MM-JJ et les montants en nombres décimaux. Une extension
+// de cours est interrogée pour les identifiants préfixés de son nom (« bourse:AIR »).
+const pluginProtocol = 1
+
+// Capacités qu'une extension déclare en réponse à describe
+const (
+	PluginQuotes   = "quotes"
+	PluginImporter = "importer"
+	PluginRenderer = "renderer"
+)
+
+// defaultPluginTimeout borne la durée d'un appel d'extension
+const defaultPluginTimeout = 30 * time.Second
+
+// Plugin est une extension déclarée dans la table [plugins] de la configuration
+type Plugin struct {
+	Name    string
+	Path    string        // Exécutable, lancé sans argument
+	Timeout time.Duration // Durée maximale d'un appel, defaultPluginTimeout si nulle
+}
+
+// PluginInfo est la description retournée par la méthode describe
+type PluginInfo struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version,omitempty"`
+	Capabilities []string `json:"capabilities"`
+	Formats      []string `json:"formats,omitempty"` // Formats de rendu (capacité renderer)
+}
+
+// pluginRequest et pluginResponse sont les messages échangés avec une extension
+type pluginRequest struct {
+	Protocol int    `json:"protocol"`
+	Method   string `json:"method"`
+	Params   any    `json:"params"`
+}
+
+type pluginResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// Call lance l'extension pour une méthode et décode le résultat dans result
+func (pl Plugin) Call(ctx context.Context, method string, params, result any) error {
+	timeout := pl.Timeout
+	if timeout <= 0 {
+		timeout = defaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := json.Marshal(pluginRequest{Protocol: pluginProtocol, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, pl.Path)
+	cmd.Stdin = bytes.NewReader(append(req, '\n'))
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	start := time.Now()
+	runErr := cmd.Run()
+	Logger().Debug("appel d'extension", "plugin", pl.Name, "method", method, "duration", time.Since(start), "error", runErr)
+
+	var resp pluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("extension %s (%s): %w", pl.Name, method, runErr)
+		}
+		return fmt.Errorf("extension %s (%s): réponse illisible: %w", pl.Name, method, err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("extension %s (%s): %s", pl.Name, method, resp.Error)
+	}
+	if runErr != nil {
+		return fmt.Errorf("extension %s (%s): %w", pl.Name, method, runErr)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("extension %s (%s): résultat illisible: %w", pl.Name, method, err)
+	}
+	return nil
+}
+
+// Describe interroge l'extension sur ses capacités
+func (pl Plugin) Describe(ctx context.Context) (PluginInfo, error) {
+	var info PluginInfo
+	if err := pl.Call(ctx, "describe", struct{}{}, &info); err != nil {
+		return PluginInfo{}, err
+	}
+	return info, nil
+}
+
+// Has indique si l'extension déclare une capacité
+func (info PluginInfo) Has(capability string) bool {
+	return slices.Contains(info.Capabilities, capability)
+}
+
+// validatePlugins vérifie les noms et chemins des extensions
+func validatePlugins(list []Plugin) error {
+	seen := make(map[string]bool)
+	for _, pl := range list {
+		if pl.Name == "" || strings.ContainsAny(pl.Name, ": \t") {
+			return InvalidField("plugins", pl.Name, "nom d'extension invalide: %q", pl.Name)
+		}
+		if seen[pl.Name] || pl.Name == CryptoPrefix {
+			return InvalidField("plugins", pl.Name, "nom d'extension déjà pris: %s", pl.Name)
+		}
+		seen[pl.Name] = true
+		if pl.Path == "" {
+			return InvalidField("plugins."+pl.Name, pl.Path, "chemin d'extension vide")
+		}
+	}
+	return nil
+}
+
+// pluginQuote est la forme échangée d'un cours
+type pluginQuote struct {
+	Date     string   `json:"date"`
+	Price    float64  `json:"price"`
+	Currency Currency `json:"currency,omitempty"`
+}
+
+// PluginQuoteProvider obtient les cours auprès d'une extension (méthode quote)
+type PluginQuoteProvider struct {
+	Plugin Plugin
+}
+
+// Quote retourne le cours que l'extension donne pour l'identifiant
+func (q PluginQuoteProvider) Quote(ctx context.Context, identifier string) (Quote, error) {
+	var raw pluginQuote
+	if err := q.Plugin.Call(ctx, "quote", map[string]string{"identifier": identifier}, &raw); err != nil {
+		return Quote{}, err
+	}
+	t, err := ParseDate(raw.Date)
+	if err != nil {
+		return Quote{}, fmt.Errorf("extension %s: cours de %s: %w", q.Plugin.Name, identifier, err)
+	}
+	return Quote{Date: t, Price: raw.Price, Currency: raw.Currency}, nil
+}
@@ -0,0 +1,881 @@
+// Package portfolio suit un portefeuille d'investissements : NAV, apports, transactions,
+// devises, conventions de taux et calendrier, valorisation, performance et projections.
+// Les méthodes exportées de Portfolio prennent elles-mêmes son verrou et peuvent être
+// appelées depuis plusieurs goroutines.
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// Erreurs sentinelles permettant aux appelants de distinguer les cas via errors.Is
+var (
+	ErrInvestmentNotFound = errors.New("investissement introuvable")
+	ErrNAVNotFound        = errors.New("NAV introuvable")
+	ErrInvalidAmount      = errors.New("montant invalide")
+	ErrRateNotFound       = errors.New("taux de change introuvable")
+	ErrInvalidDate        = errors.New("date invalide")
+	ErrInvestmentExists   = errors.New("investissement déjà existant")
+	ErrDuplicateNAV       = errors.New("NAV déjà enregistrée à cette date")
+	ErrWrongPassphrase    = errors.New("phrase secrète incorrecte ou fichier altéré")
+	ErrNoNAV              = noNAVError{}
+	ErrNotFound           = errors.New("élément introuvable")
+	ErrAlreadyExists      = errors.New("élément déjà existant")
+	ErrHorizonTooLong     = errors.New("horizon de projection trop lointain")
+
+	ErrInsufficientHistory = analytics.ErrInsufficientHistory
+	ErrPeriodTooShort      = analytics.ErrPeriodTooShort
+)
+
+// noNAVError est le type d'ErrNoNAV : l'absence de toute NAV est un cas particulier de
+// NAV introuvable, si bien qu'errors.Is(err, ErrNAVNotFound) reste vrai pour les appelants
+// antérieurs à ErrNoNAV.
+type noNAVError struct{}
+
+func (noNAVError) Error() string { return "aucune NAV enregistrée" }
+
+// Is fait correspondre ErrNoNAV à ErrNAVNotFound
+func (noNAVError) Is(target error) bool { return target == ErrNAVNotFound }
+
+// ValidationError signale un paramètre refusé ; les appelants l'identifient avec
+// errors.As pour connaître le champ en cause. Err est l'erreur sentinelle éventuellement
+// associée (ErrInvalidAmount pour un montant), accessible par errors.Is.
+type ValidationError struct {
+	Field   string // Nom du paramètre (champ JSON)
+	Value   any    // Valeur refusée
+	Message string // Explication
+	Err     error  // Erreur sentinelle associée, nil sinon
+}
+
+func (e *ValidationError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// InvalidField construit une ValidationError sans erreur sentinelle
+func InvalidField(field string, value any, format string, args ...any) *ValidationError {
+	return &ValidationError{Field: field, Value: value, Message: fmt.Sprintf(format, args...)}
+}
+
+// NAV représente une valorisation (Net Asset Value) à une date donnée
+type NAV struct {
+	Date  Date  `json:"date"`  // Date de valorisation
+	Value Money `json:"value"` // Valeur de la NAV
+}
+
+// Investment représente un investissement dans le portefeuille
+type Investment struct {
+	ID             string            `json:"id,omitempty"`            // Identifiant interne stable, conservé aux renommages (voir Lookup)
+	Name           string            `json:"name"`                    // Nom de l'investissement
+	AmountInvested Money             `json:"amount_invested"`         // Montant initial investi
+	ReferenceRate  float64           `json:"reference_rate"`          // Taux de référence annuel (%)
+	NAVHistory     []NAV             `json:"nav_history"`             // Historique des NAV
+	InvestmentDate Date              `json:"investment_date"`         // Date d'investissement initial
+	Quantity       Quantity          `json:"quantity,omitempty"`      // Quantité d'actions ou d'unités de cryptoactif (si défini)
+	UnitPrice      Price             `json:"unit_price,omitempty"`    // Prix unitaire de l'action (si défini)
+	CashFlows      []CashFlow        `json:"cash_flows,omitempty"`    // Apports et retraits postérieurs à l'investissement initial
+	Currency       Currency          `json:"currency,omitempty"`      // Devise des montants et NAV (EUR si vide)
+	Transactions   []Transaction     `json:"transactions,omitempty"`  // Achats et ventes de parts postérieurs à la position initiale
+	Closed         bool              `json:"closed,omitempty"`        // Position soldée : exclue des valorisations, conservée pour l'historique
+	ClosedDate     Date              `json:"closed_date,omitzero"`    // Date de clôture
+	Distributions  []Distribution    `json:"distributions,omitempty"` // Dividendes et distributions versés
+	Fees           *FeeSchedule      `json:"fees,omitempty"`          // Frais courants, de garde, d'entrée et de sortie
+	Benchmark      string            `json:"benchmark,omitempty"`     // Indice de référence associé (voir Portfolio.Benchmarks)
+	Plan           *ContributionPlan `json:"plan,omitempty"`          // Versements programmés intégrés aux projections
+	RatePolicy     *RatePolicy       `json:"rate_policy,omitempty"`   // Règle de choix du taux de projection (min par défaut)
+	Identifier     string            `json:"identifier,omitempty"`    // Code interrogé par RefreshNAVs, ticker ou ISIN si vide
+	ISIN           string            `json:"isin,omitempty"`          // Code ISIN, unique dans le portefeuille (voir SetIdentifiers)
+	Ticker         string            `json:"ticker,omitempty"`        // Symbole de cotation
+	MIC            string            `json:"mic,omitempty"`           // Place de cotation du ticker (ISO 10383)
+	Tags           map[string]string `json:"tags,omitempty"`          // Étiquettes libres : classe d'actifs, région, labels personnalisés
+	Holdings       *Portfolio        `json:"holdings,omitempty"`      // Lignes dont l'investissement est composé : ses NAV en sont déduites (voir RollUp)
+	TaxWrapper     TaxWrapper        `json:"tax_wrapper,omitempty"`   // Enveloppe fiscale (celle du compte si vide)
+	Cash           *CashAccount      `json:"cash,omitempty"`          // Compte rémunéré : NAV déduites des intérêts courus (voir AccrueInterest)
+	Bond           *Bond             `json:"bond,omitempty"`          // Obligation : NAV au coût amorti et coupons déduits de l'échéancier
+	Commitment     *Commitment       `json:"commitment,omitempty"`    // Engagement de capital-investissement : appels de fonds attendus
+	Vesting        *VestingSchedule  `json:"vesting,omitempty"`       // Calendrier d'acquisition : seule la part acquise est valorisée
+	Liquidity      *Liquidity        `json:"liquidity,omitempty"`     // Délai de disponibilité en cas de vente (voir LiquidityReport)
+	Exposure       Exposure          `json:"exposure,omitempty"`      // Sens de la position : acheteuse, vente à découvert ou valeur signée
+	Notes          []Note            `json:"notes,omitempty"`         // Notes, documents et liens (voir NoteEntries pour ceux des transactions)
+	Projection     *ProjectionModel  `json:"projection,omitempty"`    // Modèle de projection (capitalisation au taux effectif si nil, voir Projector)
+	Redemptions    []Redemption      `json:"redemptions,omitempty"`   // Rachats partiels en montant, avec leur plus-value (voir Sell)
+	Owner          string            `json:"owner,omitempty"`         // Titulaire (me, spouse, joint…), celui du portefeuille si vide
+
+	recurring   []*RecurringPlan          // Plans de versements du portefeuille alimentant l'investissement (voir linkRecurringPlans)
+	conventions analytics.RateConventions // Conventions de taux du portefeuille (voir Portfolio.attach)
+	calendar    *Calendar                 // Calendrier des jours ouvrés du portefeuille (voir Portfolio.attach)
+	metrics     *metricsCache             // Mesures dérivées mémorisées (voir invalidate)
+}
+
+// Portfolio représente un portefeuille d'investissements.
+//
+// Les méthodes de Portfolio peuvent être appelées depuis plusieurs goroutines :
+// les lectures (valorisations, projections, sérialisation) s'exécutent en parallèle
+// et les modifications (ajout d'investissement, de NAV ou de flux) sont exclusives.
+// L'accès direct à la map Investments, ou aux méthodes d'un *Investment qui en est
+// extrait, n'est en revanche pas synchronisé : en contexte concurrent, utiliser
+// Investment(name), qui retourne une copie indépendante.
+type Portfolio struct {
+	mu sync.RWMutex
+
+	Investments        map[string]*Investment        `json:"investments"`
+	BaseCurrency       Currency                      `json:"base_currency,omitempty"`        // Devise de consolidation (EUR si vide)
+	DuplicateNAVPolicy DuplicateNAVPolicy            `json:"duplicate_nav_policy,omitempty"` // Traitement des NAV de même date (erreur si vide)
+	MissingNAVPolicy   MissingNAVPolicy              `json:"missing_nav_policy,omitempty"`   // Valorisation des investissements sans NAV (erreur si vide)
+	TargetAllocation   *TargetAllocation             `json:"target_allocation,omitempty"`    // Répartition cible utilisée par RebalancePlan
+	Scenarios          map[string]*Scenario          `json:"scenarios,omitempty"`            // Scénarios de projection nommés
+	Benchmarks         map[string]*Benchmark         `json:"benchmarks,omitempty"`           // Indices de référence
+	RiskFreeRate       float64                       `json:"risk_free_rate,omitempty"`       // Taux sans risque annuel (%) des ratios de Sharpe et Sortino
+	Inflation          *Inflation                    `json:"inflation,omitempty"`            // Hypothèse d'inflation des mesures réelles
+	Locale             Locale                        `json:"locale,omitempty"`               // Langue des résumés et rapports (français si vide)
+	Snapshots          map[string]*Snapshot          `json:"snapshots,omitempty"`            // Instantanés comparés par DiffSnapshots
+	Journal            *Journal                      `json:"journal,omitempty"`              // Historique des modifications (Undo, Redo)
+	AlertRules         []AlertRule                   `json:"alert_rules,omitempty"`          // Règles d'alerte évaluées par EvaluateAlerts
+	Tax                *TaxSettings                  `json:"tax,omitempty"`                  // Situation fiscale du compte (projections après impôts)
+	Liabilities        map[string]*Liability         `json:"liabilities,omitempty"`          // Emprunts déduits du patrimoine net
+	RecurringPlans     map[string]*RecurringPlan     `json:"recurring_plans,omitempty"`      // Versements programmés exécutés par MaterializePlans
+	Conventions        *analytics.RateConventions    `json:"conventions,omitempty"`          // Décompte des jours et capitalisation des taux (ACT/365.25, annuelle si nil)
+	Calendar           *Calendar                     `json:"calendar,omitempty"`             // Jours ouvrés et report des échéances (aucun report si nil)
+	StatementRules     []StatementRule               `json:"statement_rules,omitempty"`      // Rattachement des relevés importés par ImportStatement
+	Watchlist          map[string]*WatchedInstrument `json:"watchlist,omitempty"`            // Titres suivis sans être détenus, hors valorisations
+	Owner              string                        `json:"owner,omitempty"`                // Titulaire du compte, par défaut celui de ses investissements (voir SetOwner)
+	Forecasts          *ForecastStore                `json:"forecasts,omitempty"`            // Projections enregistrées, confrontées aux valeurs réalisées
+	Rates              Rates                         `json:"-"`                              // Taux de change pour les investissements en devise étrangère
+	Quotes             QuoteProvider                 `json:"-"`                              // Fournisseur de cours utilisé par RefreshNAVs
+
+	key       *portfolioKey         // Clé de chiffrement des enregistrements, nil pour un fichier en clair
+	format    StorageFormat         // Format des enregistrements, celui du fichier chargé (voir SetStorageFormat)
+	workers   int                   // Goroutines de valorisation (voir SetValuationWorkers)
+	bus       *eventBus             // Abonnés aux événements (voir Subscribe), nil sans abonné
+	audited   map[string]auditScope // État au chargement ou au dernier enregistrement (voir recordAudit)
+	actor     AuditActor            // Auteur des enregistrements suivants (voir SetAuditActor)
+	ownerView string                // Titulaire d'une copie restreinte par ForOwner, qui ne peut être enregistrée
+	revision  atomic.Uint64         // Compteur de modifications (voir Revision)
+}
+
+// NewPortfolio crée un nouveau portefeuille vide
+func NewPortfolio() *Portfolio {
+	return &Portfolio{
+		Investments:  make(map[string]*Investment),
+		BaseCurrency: DefaultCurrency,
+	}
+}
+
+// NewPortfolioFrom crée un portefeuille à partir d'investissements déjà construits, par
+// exemple relus d'un stockage, rattachés à ses conventions et à son calendrier
+func NewPortfolioFrom(investments []*Investment) *Portfolio {
+	p := NewPortfolio()
+	for _, inv := range investments {
+		p.Investments[inv.Name] = inv
+		p.attach(inv)
+	}
+	return p
+}
+
+// AddInvestment ajoute un nouvel investissement au portefeuille avec montant investi
+func (p *Portfolio) AddInvestment(name string, amount float64, referenceRate float64, investmentDate string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if amount <= 0 {
+		return &ValidationError{Field: "amount", Value: amount, Message: "le montant doit être positif", Err: ErrInvalidAmount}
+	}
+	t, err := ParseDate(investmentDate)
+	if err != nil {
+		return err
+	}
+
+	inv := &Investment{
+		ID:             newInvestmentID(),
+		Name:           name,
+		AmountInvested: NewMoney(amount),
+		ReferenceRate:  referenceRate,
+		NAVHistory:     make([]NAV, 0),
+		InvestmentDate: Date{t},
+		metrics:        newMetricsCache(),
+	}
+
+	before := p.investmentState(name)
+	p.Investments[name] = inv
+	p.attach(inv)
+	p.record(OpAddInvestment, name, fmt.Sprintf("%.2f au %s, taux %.2f%%", amount, investmentDate, referenceRate), before)
+	p.investmentAdded(name)
+	return nil
+}
+
+// AddInvestmentWithQuantity ajoute un nouvel investissement au portefeuille avec quantité et prix unitaire
+func (p *Portfolio) AddInvestmentWithQuantity(name string, quantity float64, unitPrice float64, referenceRate float64, investmentDate string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if quantity <= 0 {
+		return &ValidationError{Field: "quantity", Value: quantity, Message: "la quantité doit être positive", Err: ErrInvalidAmount}
+	}
+	if NewPrice(unitPrice) <= 0 {
+		return &ValidationError{Field: "unit_price", Value: unitPrice, Message: "le prix unitaire doit être positif et d'au moins 0.00000001", Err: ErrInvalidAmount}
+	}
+	t, err := ParseDate(investmentDate)
+	if err != nil {
+		return err
+	}
+
+	amountInvested := NewMoney(quantity * unitPrice)
+
+	inv := &Investment{
+		ID:             newInvestmentID(),
+		Name:           name,
+		AmountInvested: amountInvested,
+		ReferenceRate:  referenceRate,
+		NAVHistory:     make([]NAV, 0),
+		InvestmentDate: Date{t},
+		metrics:        newMetricsCache(),
+		Quantity:       NewQuantity(quantity),
+		UnitPrice:      NewPrice(unitPrice),
+	}
+
+	before := p.investmentState(name)
+	p.Investments[name] = inv
+	p.attach(inv)
+	p.record(OpAddInvestment, name, fmt.Sprintf("%.4f × %.2f au %s, taux %.2f%%", quantity, unitPrice, investmentDate, referenceRate), before)
+	p.investmentAdded(name)
+	return nil
+}
+
+// acceptsNAVs vérifie que les NAV de l'investissement sont saisies et non calculées à
+// partir de ses lignes, de son taux ou de son échéancier
+func (inv *Investment) acceptsNAVs(name string) error {
+	switch {
+	case inv.Holdings != nil:
+		return fmt.Errorf("les NAV de '%s' sont calculées à partir de ses lignes", name)
+	case inv.Cash != nil:
+		return fmt.Errorf("les NAV du compte rémunéré '%s' sont calculées à partir de son taux", name)
+	case inv.Bond != nil:
+		return fmt.Errorf("les NAV de l'obligation '%s' sont calculées à partir de son échéancier", name)
+	}
+	return nil
+}
+
+// AddNAV ajoute une valorisation à un investissement. Si une NAV existe déjà à
+// cette date, la politique DuplicateNAVPolicy du portefeuille s'applique.
+func (p *Portfolio) AddNAV(investmentName string, date string, value float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if err := inv.acceptsNAVs(investmentName); err != nil {
+		return err
+	}
+
+	if err := inv.acceptsValue("value", value); err != nil {
+		return err
+	}
+
+	nav, err := NewNAV(date, value)
+	if err != nil {
+		return err
+	}
+
+	before := inv.clone()
+	i, found := inv.navIndex(nav.Date.Time)
+	if found {
+		switch p.DuplicateNAVPolicy {
+		case DuplicateNAVReplace:
+			inv.NAVHistory[i].Value = nav.Value
+			inv.invalidate()
+			p.record(OpUpdateNAV, investmentName, fmt.Sprintf("%s: %.2f -> %.2f", date, before.NAVHistory[i].Value.Float64(), value), before)
+			p.navsAdded(investmentName, nav)
+			return nil
+		case DuplicateNAVAverage:
+			value = NewMoney((inv.NAVHistory[i].Value.Float64() + value) / 2).RoundCents().Float64()
+			inv.NAVHistory[i].Value = NewMoney(value)
+			inv.invalidate()
+			p.record(OpUpdateNAV, investmentName, fmt.Sprintf("%s: %.2f -> %.2f", date, before.NAVHistory[i].Value.Float64(), value), before)
+			p.navsAdded(investmentName, inv.NAVHistory[i])
+			return nil
+		case DuplicateNAVKeepExisting:
+			return nil
+		default:
+			return fmt.Errorf("NAV de %s au %s: %w", investmentName, date, ErrDuplicateNAV)
+		}
+	}
+	// Insertion à sa place : l'historique reste trié sans nouveau tri
+	inv.NAVHistory = slices.Insert(inv.NAVHistory, i, nav)
+	inv.invalidate()
+
+	p.record(OpAddNAV, investmentName, fmt.Sprintf("%s: %.2f", date, value), before)
+	p.navsAdded(investmentName, nav)
+	return nil
+}
+
+// GetLatestNAV retourne la dernière NAV connue pour un investissement
+func (inv *Investment) GetLatestNAV() (NAV, error) {
+	if len(inv.NAVHistory) == 0 {
+		return NAV{}, fmt.Errorf("aucune NAV disponible: %w", ErrNoNAV)
+	}
+	return inv.NAVHistory[len(inv.NAVHistory)-1], nil
+}
+
+// sortNAVs trie un historique de NAV par date croissante ; un historique déjà trié, cas
+// courant au chargement, est parcouru une seule fois
+func sortNAVs(navs []NAV) {
+	if slices.IsSortedFunc(navs, compareNAVDates) {
+		return
+	}
+	slices.SortStableFunc(navs, compareNAVDates)
+}
+
+// compareNAVDates ordonne deux NAV par date
+func compareNAVDates(a, b NAV) int {
+	return a.Date.Compare(b.Date.Time)
+}
+
+// String retourne une représentation lisible de la NAV, par exemple "2024-07-01: 5300.00€"
+func (n NAV) String() string {
+	return fmt.Sprintf("%s: %.2f€", FormatDate(n.Date.Time), n.Value.Float64())
+}
+
+// String résume l'investissement : nom, montant investi, dernière NAV et performance
+func (inv *Investment) String() string {
+	summary := fmt.Sprintf("%s (investi: %.2f€", inv.Name, inv.AmountInvested.Float64())
+
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return summary + ", aucune NAV)"
+	}
+	summary += fmt.Sprintf(", dernière NAV: %s", latestNAV)
+
+	if performanceRate, err := inv.CalculatePerformanceRate(); err == nil {
+		summary += fmt.Sprintf(", performance: %.2f%%", performanceRate)
+	}
+
+	return summary + ")"
+}
+
+// performanceRate calcule le taux annuel de performance basé sur les données réelles (voir
+// CalculatePerformanceRate, qui le mémorise)
+func (inv *Investment) performanceRate() (float64, error) {
+	if len(inv.NAVHistory) < 2 {
+		return 0, fmt.Errorf("au moins 2 NAV sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	firstNAV := inv.NAVHistory[0]
+	lastNAV := inv.NAVHistory[len(inv.NAVHistory)-1]
+
+	years := inv.conventions.YearsBetween(firstNAV.Date.Time, lastNAV.Date.Time)
+	if years <= 0 {
+		return 0, fmt.Errorf("l'intervalle de temps doit être positif")
+	}
+	if err := inv.conventions.CheckAnnualization(firstNAV.Date.Time, lastNAV.Date.Time); err != nil {
+		return 0, err
+	}
+
+	// Formule: r = (1 + R)^(1/n) - 1 en capitalisation annuelle, R étant le rendement
+	// corrigé des flux (R = VF/VI - 1 en l'absence d'apports ou de retraits)
+	periodReturn, err := inv.exposureReturn(inv.flowAdjustedReturn(firstNAV, lastNAV))
+	if err != nil {
+		return 0, err
+	}
+	if periodReturn <= -1 {
+		return 0, fmt.Errorf("perte totale sur la période, taux non calculable")
+	}
+	return inv.conventions.AnnualizedRate(1+periodReturn, years), nil
+}
+
+// DetectOutliers signale les NAV dont le rendement sur la période précédente
+// s'écarte de plus de threshold écarts-types du rendement moyen
+func (inv *Investment) DetectOutliers(threshold float64) ([]NAV, error) {
+	if threshold <= 0 {
+		return nil, fmt.Errorf("le seuil doit être positif")
+	}
+	if len(inv.NAVHistory) < 3 {
+		return nil, fmt.Errorf("au moins 3 NAV sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	// Rendements périodiques entre NAV consécutives, corrigés des flux
+	returns := make([]float64, len(inv.NAVHistory)-1)
+	mean := 0.0
+	for i := 1; i < len(inv.NAVHistory); i++ {
+		returns[i-1] = inv.flowAdjustedReturn(inv.NAVHistory[i-1], inv.NAVHistory[i])
+		mean += returns[i-1]
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)))
+	if stdDev == 0 {
+		return nil, nil
+	}
+
+	var outliers []NAV
+	for i, r := range returns {
+		if math.Abs(r-mean) > threshold*stdDev {
+			outliers = append(outliers, inv.NAVHistory[i+1])
+		}
+	}
+
+	return outliers, nil
+}
+
+// EffectiveRate retourne le taux annuel (%) appliqué par ProjectNAV selon la règle de
+// taux de l'investissement : par défaut, le plus défavorable entre le taux de référence
+// et le taux calculé
+func (inv *Investment) EffectiveRate() (float64, error) {
+	return inv.RateWithPolicy(inv.ratePolicy())
+}
+
+// ProjectNAV projette la valeur future à une date donnée. Le taux calculé intègre
+// les distributions versées (rendement global) : la projection suppose leur réinvestissement.
+func (inv *Investment) ProjectNAV(projectionDate string) (float64, error) {
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return 0, err
+	}
+	return inv.projectNAVAt(t)
+}
+
+// projectNAVAt projette la valeur future à une date donnée au taux effectif
+func (inv *Investment) projectNAVAt(date time.Time) (float64, error) {
+	// Calculer le taux de performance
+	performanceRate, err := inv.EffectiveRate()
+	if err != nil {
+		return 0, err
+	}
+
+	return inv.projectNAVAtRate(date, performanceRate)
+}
+
+// ProjectNAVAtRate projette la valeur future à une date donnée avec un taux annuel (%) imposé
+func (inv *Investment) ProjectNAVAtRate(projectionDate string, rate float64) (float64, error) {
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return 0, err
+	}
+	return inv.projectNAVAtRate(t, rate)
+}
+
+func (inv *Investment) projectNAVAtRate(date time.Time, rate float64) (float64, error) {
+	// Récupérer la dernière NAV connue
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return 0, err
+	}
+
+	years := inv.conventions.YearsBetween(latestNAV.Date.Time, date)
+	if years < 0 {
+		return 0, fmt.Errorf("la date de projection doit être après la dernière NAV")
+	}
+
+	// Formule: VF = VI * (1 + r)^n, diminuée des frais courants s'il y en a,
+	// plus les versements programmés capitalisés depuis leur date, sauf autre modèle
+	return inv.project(latestNAV, date, inv.navRate(rate), inv.PlannedContributions(latestNAV.Date.Time, date))
+}
+
+// ProjectWithContributions projette la valeur future en ajoutant un versement
+// mensuel aux versements programmés, chaque versement étant capitalisé au taux effectif jusqu'à la date de projection
+// (frais d'entrée et frais courants déduits)
+func (inv *Investment) ProjectWithContributions(projectionDate string, monthlyAmount float64) (float64, error) {
+	if monthlyAmount < 0 {
+		return 0, &ValidationError{Field: "monthly_amount", Value: monthlyAmount, Message: "le versement mensuel ne peut pas être négatif", Err: ErrInvalidAmount}
+	}
+
+	performanceRate, err := inv.EffectiveRate()
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := ParseDate(projectionDate)
+	if err != nil {
+		return 0, err
+	}
+	return inv.projectWithContributionsAt(end, monthlyAmount, performanceRate)
+}
+
+// projectWithContributionsAt projette avec versements mensuels à un taux annuel (%) imposé
+func (inv *Investment) projectWithContributionsAt(end time.Time, monthlyAmount float64, performanceRate float64) (float64, error) {
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return 0, err
+	}
+
+	start := latestNAV.Date
+	if end.Before(start.Time) {
+		return 0, fmt.Errorf("la date de projection doit être après la dernière NAV")
+	}
+
+	// Le versement (net des frais d'entrée) intervient en fin de mois, en plus du plan programmé
+	contributions := inv.PlannedContributions(start.Time, end)
+	for month := 1; ; month++ {
+		next := start.AddDate(0, month, 0)
+		if next.After(end) {
+			break
+		}
+		contributions = append(contributions, CashFlow{Date: Date{next}, Amount: NewMoney(monthlyAmount), Type: Contribution})
+	}
+
+	return inv.project(latestNAV, end, inv.navRate(performanceRate), contributions)
+}
+
+// breakEvenHorizonYears borne la recherche du point de rattrapage dans BreakEvenDate
+const breakEvenHorizonYears = 50
+
+// BreakEvenDate retourne la date à laquelle la trajectoire réelle (dernière NAV
+// capitalisée au taux calculé) rattrape la trajectoire de référence (montant
+// investi capitalisé au taux de référence depuis la date d'investissement)
+func (inv *Investment) BreakEvenDate() (string, error) {
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return "", err
+	}
+
+	actualRate, err := inv.CalculatePerformanceRate()
+	if err != nil {
+		return "", err
+	}
+
+	tLatest := latestNAV.Date
+	elapsed := inv.conventions.YearsBetween(inv.InvestmentDate.Time, tLatest.Time)
+
+	// Valeur qu'aurait l'investissement s'il avait suivi le taux de référence,
+	// chaque apport ou retrait étant capitalisé depuis sa propre date
+	referenceValue := inv.AmountInvested.Float64() * inv.conventions.GrowthFactor(inv.ReferenceRate, elapsed)
+	for _, cf := range inv.CashFlows {
+		if cf.Date.After(tLatest.Time) {
+			continue
+		}
+		flowYears := inv.conventions.YearsBetween(cf.Date.Time, tLatest.Time)
+		referenceValue += cf.SignedAmount().Float64() * inv.conventions.GrowthFactor(inv.ReferenceRate, flowYears)
+	}
+	if latestNAV.Value.Float64() >= referenceValue {
+		return FormatDate(tLatest.Time), nil
+	}
+
+	// Résoudre VL * (1 + a)^n = VR * (1 + r)^n pour n années après la dernière NAV
+	growthGap := inv.conventions.RateLog(actualRate) - inv.conventions.RateLog(inv.ReferenceRate)
+	if growthGap <= 0 {
+		return "", fmt.Errorf("le taux réel ne dépasse pas le taux de référence, aucun rattrapage possible")
+	}
+
+	years := math.Log(referenceValue/latestNAV.Value.Float64()) / growthGap
+	if years > breakEvenHorizonYears {
+		return "", fmt.Errorf("aucun rattrapage dans les %d prochaines années", breakEvenHorizonYears)
+	}
+
+	return FormatDate(inv.conventions.AddYears(tLatest.Time, years)), nil
+}
+
+// GetPortfolioValue calcule la valeur totale du portefeuille à une date donnée,
+// chaque valeur étant convertie dans la devise de consolidation au taux de cette date.
+// Les valeurs sont arrondies au centime et le total est leur somme exacte. Les
+// investissements écartés faute de NAV (MissingNAVSkip) en sont absents ;
+// GetPortfolioValuation les nomme.
+func (p *Portfolio) GetPortfolioValue(date string) (map[string]float64, float64, error) {
+	v, err := p.GetPortfolioValuation(date)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v.Values, v.Total, nil
+}
+
+// PortfolioValuation est la valeur du portefeuille à une date
+type PortfolioValuation struct {
+	Values  map[string]float64 `json:"values"`            // Valeur de chaque investissement valorisé
+	Total   float64            `json:"total"`             // Somme des valeurs
+	Skipped []string           `json:"skipped,omitempty"` // Investissements écartés faute de NAV (MissingNAVSkip), absents de Values et du total
+}
+
+// GetPortfolioValuation valorise le portefeuille comme GetPortfolioValue en nommant les
+// investissements écartés de la valorisation
+func (p *Portfolio) GetPortfolioValuation(date string) (*PortfolioValuation, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.portfolioValuation(t, nil)
+}
+
+// portfolioValue calcule la valeur du portefeuille ; l'appelant doit détenir p.mu
+func (p *Portfolio) portfolioValue(t time.Time) (map[string]float64, float64, error) {
+	return p.portfolioValueWithPolicy(t, nil)
+}
+
+// portfolioValueWithPolicy valorise le portefeuille avec une règle de taux imposée à tous
+// les investissements (voir portfolioValuation) ; l'appelant doit détenir p.mu
+func (p *Portfolio) portfolioValueWithPolicy(t time.Time, policy *RatePolicy) (map[string]float64, float64, error) {
+	v, err := p.portfolioValuation(t, policy)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v.Values, v.Total, nil
+}
+
+// portfolioValuation valorise le portefeuille avec une règle de taux imposée à tous les
+// investissements, ou la règle de chacun si policy est nil. Les investissements sont
+// valorisés en parallèle ; en cas d'échec, l'erreur retournée est celle du premier
+// investissement en erreur dans l'ordre alphabétique. Un investissement sans NAV suit la
+// politique MissingNAVPolicy du portefeuille.
+func (p *Portfolio) portfolioValuation(t time.Time, policy *RatePolicy) (*PortfolioValuation, error) {
+	var names []string
+	for _, name := range p.sortedInvestmentNames() {
+		if !p.Investments[name].Closed {
+			names = append(names, name)
+		}
+	}
+
+	type valuation struct {
+		value   Money
+		skipped bool // Écarté faute de NAV (MissingNAVSkip)
+		err     error
+	}
+	results := make([]valuation, len(names))
+	parallelEach(len(names), p.valuationWorkers(), func(i int) {
+		inv := p.Investments[names[i]]
+		rate, err := inv.ProjectionRate(policy)
+		if err != nil {
+			results[i].err = err
+			return
+		}
+		results[i].value, results[i].skipped, results[i].err = p.valueAtRate(inv, t, rate)
+	})
+
+	v := &PortfolioValuation{Values: make(map[string]float64, len(names))}
+	var totalValue Money
+	for i, name := range names {
+		if results[i].err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, results[i].err)
+		}
+		if results[i].skipped {
+			v.Skipped = append(v.Skipped, name)
+			continue
+		}
+		v.Values[name] = results[i].value.Float64()
+		totalValue += results[i].value
+	}
+	v.Total = totalValue.Float64()
+	return v, nil
+}
+
+// valueAtRate valorise un investissement à une date, projeté depuis sa dernière NAV au
+// taux rate, en devise de consolidation : part acquise, sens de la position et
+// MissingNAVPolicy compris. skipped indique un investissement écarté faute de NAV.
+// L'appelant doit détenir p.mu.
+func (p *Portfolio) valueAtRate(inv *Investment, t time.Time, rate float64) (value Money, skipped bool, err error) {
+	projected, err := inv.projectNAVAtRate(t, rate)
+	if errors.Is(err, ErrNoNAV) {
+		switch p.MissingNAVPolicy {
+		case MissingNAVSkip:
+			return 0, true, nil
+		case MissingNAVInvested:
+			projected, err = inv.projectFromInvested(t)
+		}
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	converted, err := p.positionValue(inv, projected, t)
+	return NewMoney(converted).RoundCents(), false, err
+}
+
+// investmentValueAt retourne la contribution d'un investissement à la valeur du
+// portefeuille à une date d'après ses NAV connues (voir historicalValue), convertie comme
+// dans positionValue. Sans NAV, l'investissement suit MissingNAVPolicy : écarté (held faux)
+// avec MissingNAVSkip, valorisé à son montant investi capitalisé avec MissingNAVInvested,
+// à son montant investi sinon. held est aussi faux avant l'investissement ou après sa
+// clôture. L'appelant doit détenir p.mu.
+func (p *Portfolio) investmentValueAt(inv *Investment, t time.Time) (value float64, held bool, err error) {
+	value, held = inv.historicalValue(t)
+	if !held {
+		return 0, false, nil
+	}
+	if inv.lacksNAV() {
+		switch p.MissingNAVPolicy {
+		case MissingNAVSkip:
+			return 0, false, nil
+		case MissingNAVInvested:
+			if value, err = inv.projectFromInvested(t); err != nil {
+				return 0, false, err
+			}
+		}
+	}
+	value, err = p.positionValue(inv, value, t)
+	return value, err == nil, err
+}
+
+// positionValue convertit la valeur des titres d'un investissement à une date en sa
+// contribution au portefeuille : part acquise, sens de la position et conversion dans la
+// devise de consolidation au taux de la date. L'appelant doit détenir p.mu.
+func (p *Portfolio) positionValue(inv *Investment, value float64, t time.Time) (float64, error) {
+	return p.toBase(value*inv.VestedFraction(t)*inv.Sign(), inv.Currency, t)
+}
+
+// CompareScenarios projette chaque investissement avec deux taux annuels (%)
+// et retourne les deux valeurs par investissement ainsi que les deux totaux,
+// valorisés comme GetPortfolioValue hormis le taux
+func (p *Portfolio) CompareScenarios(date string, rateA, rateB float64) (map[string][2]float64, [2]float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, [2]float64{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values := make(map[string][2]float64)
+	var totals [2]Money
+
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		var pair [2]Money
+		var skipped bool
+		for i, rate := range [2]float64{rateA, rateB} {
+			if pair[i], skipped, err = p.valueAtRate(inv, t, rate); err != nil {
+				return nil, [2]float64{}, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+		}
+		if skipped {
+			continue
+		}
+		totals[0] += pair[0]
+		totals[1] += pair[1]
+		values[name] = [2]float64{pair[0].Float64(), pair[1].Float64()}
+	}
+
+	return values, [2]float64{totals[0].Float64(), totals[1].Float64()}, nil
+}
+
+// Weights calcule la part de chaque investissement dans la valeur totale
+// projetée du portefeuille à une date donnée (la somme des poids vaut 1)
+func (p *Portfolio) Weights(date string) (map[string]float64, error) {
+	values, totalValue, err := p.GetPortfolioValue(date)
+	if err != nil {
+		return nil, err
+	}
+	if totalValue == 0 {
+		return nil, fmt.Errorf("la valeur totale du portefeuille est nulle")
+	}
+
+	weights := make(map[string]float64, len(values))
+	for name, value := range values {
+		weights[name] = value / totalValue
+	}
+
+	return weights, nil
+}
+
+// PrintPortfolioSummary affiche un résumé du portefeuille dans sa langue
+func (p *Portfolio) PrintPortfolioSummary() {
+	p.PrintLocalizedSummary("")
+}
+
+// PrintLocalizedSummary affiche le résumé du portefeuille dans la langue demandée,
+// celle du portefeuille si locale est vide, les investissements étant triés par nom
+func (p *Portfolio) PrintLocalizedSummary(locale Locale) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var results QueryResults
+	for _, name := range p.sortedInvestmentNames() {
+		results = append(results, QueryResult{Name: name})
+	}
+	p.printSummary(locale, results, nil)
+}
+
+// printSummary affiche le résumé des investissements retenus, dans leur ordre, avec
+// leurs indicateurs personnalisés ; l'appelant détient le verrou
+func (p *Portfolio) printSummary(locale Locale, results QueryResults, metrics []Metric) {
+	l := locale
+	if l == "" {
+		l = p.locale()
+	}
+
+	fmt.Println(l.T("=== RÉSUMÉ DU PORTEFEUILLE ==="))
+	fmt.Println()
+
+	for _, r := range results {
+		name, inv := r.Name, p.Investments[r.Name]
+		fmt.Print(l.Tf("Investissement: %s\n", name))
+		if inv.Exposure != ExposureLong {
+			fmt.Print(l.Tf("  Sens de la position: %s\n", inv.Exposure))
+		}
+		if inv.Projection != nil {
+			fmt.Print(l.Tf("  Modèle de projection: %s\n", inv.Projection))
+		}
+		if inv.Closed {
+			fmt.Print(l.Tf("  Clôturé le %s\n", FormatDate(inv.ClosedDate.Time)))
+		}
+		amount := AmountFormatter{Currency: inv.EffectiveCurrency(), Locale: l}.Format
+		fmt.Print(l.Tf("  Montant investi: %s\n", amount(inv.AmountInvested.Float64())))
+		if inv.EffectiveCurrency() != p.baseCurrency() {
+			fmt.Print(l.Tf("  Devise: %s\n", inv.EffectiveCurrency()))
+		}
+
+		// Afficher la quantité et le prix unitaire si disponibles
+		if inv.Quantity > 0 && inv.UnitPrice > 0 {
+			fmt.Print(l.Tf("  Quantité: %s actions\n", inv.Quantity))
+			fmt.Print(l.Tf("  Prix unitaire initial: %s\n", amount(inv.UnitPrice.Float64())))
+		}
+
+		if len(inv.CashFlows) > 0 {
+			fmt.Print(l.Tf("  Flux: %d mouvement(s), capital net investi: %s\n", len(inv.CashFlows), amount(inv.NetInvested().Float64())))
+		}
+
+		if len(inv.Distributions) > 0 {
+			if paid, reinvested, err := inv.TotalDistributions("", ""); err == nil {
+				fmt.Print(l.Tf("  Distributions: %s versées, %s réinvesties\n", amount(paid.Float64()), amount(reinvested.Float64())))
+			}
+		}
+
+		if len(inv.Transactions) > 0 {
+			if pos, err := inv.Position(); err == nil {
+				fmt.Print(l.Tf("  Parts détenues: %s (prix de revient unitaire: %s)\n", pos.Units, amount(pos.AverageCost.Float64())))
+				fmt.Print(l.Tf("  Plus-value réalisée: %s, latente: %s\n", amount(pos.RealizedGain.Float64()), amount(pos.UnrealizedGain.Float64())))
+			}
+		}
+
+		fmt.Print(l.Tf("  Taux de référence: %.2f%%\n", inv.ReferenceRate))
+		fmt.Print(l.Tf("  Date d'investissement: %s\n", FormatDate(inv.InvestmentDate.Time)))
+
+		if len(inv.NAVHistory) > 0 {
+			latestNAV, _ := inv.GetLatestNAV()
+			fmt.Print(l.Tf("  Dernière NAV: %s (date: %s)\n", amount(latestNAV.Value.Float64()), FormatDate(latestNAV.Date.Time)))
+
+			if len(inv.NAVHistory) >= 2 {
+				performanceRate, _ := inv.CalculatePerformanceRate()
+				fmt.Print(l.Tf("  Taux de performance annuel: %.2f%%\n", performanceRate))
+			}
+		} else {
+			fmt.Println(l.T("  Aucune NAV enregistrée"))
+		}
+		for _, m := range metrics {
+			value, ok := r.Metrics[m.Name]
+			fmt.Printf("  %s: %s\n", m.Name, FormatMetric(value, ok))
+		}
+		for _, e := range inv.NoteEntries() {
+			fmt.Print(l.Tf("  Note du %s: %s\n", FormatDate(e.Date.Time), e.Label(l)))
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,524 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Facteurs de Portfolio Performance : les montants du fichier XML sont en centièmes,
+// les parts et les cours en cent-millionièmes
+const (
+	ppAmountFactor = 100
+	ppSharesFactor = 1e8
+	ppQuoteFactor  = 1e8
+)
+
+// ppTransactionKinds associe les types d'opérations de Portfolio Performance, tels
+// qu'exportés en CSV (anglais ou allemand) ou enregistrés dans le fichier XML, aux
+// opérations d'un relevé
+var ppTransactionKinds = map[string]StatementEntryKind{
+	"buy":                 StatementBuy,
+	"kauf":                StatementBuy,
+	"delivery (inbound)":  StatementBuy,
+	"delivery_inbound":    StatementBuy,
+	"einlieferung":        StatementBuy,
+	"sell":                StatementSell,
+	"verkauf":             StatementSell,
+	"delivery (outbound)": StatementSell,
+	"delivery_outbound":   StatementSell,
+	"auslieferung":        StatementSell,
+	"dividend":            StatementIncome,
+	"dividends":           StatementIncome,
+	"dividende":           StatementIncome,
+	"interest":            StatementIncome,
+	"zinsen":              StatementIncome,
+	"deposit":             StatementCash,
+	"einlage":             StatementCash,
+	"removal":             StatementCash,
+	"entnahme":            StatementCash,
+}
+
+// ppWithdrawal indique si une opération d'espèces de Portfolio Performance est un retrait
+func ppWithdrawal(kind string) bool {
+	return kind == "removal" || kind == "entnahme"
+}
+
+// ppCSVImporter lit l'export CSV des opérations de Portfolio Performance (« Toutes les
+// opérations » ou opérations d'un portefeuille), en-têtes anglais ou allemands. Les
+// opérations sans équivalent (transferts, impôts, frais isolés) sont ignorées.
+type ppCSVImporter struct{}
+
+func (ppCSVImporter) Name() string { return "pp-csv" }
+
+func (ppCSVImporter) Detect(head []byte) bool {
+	first, _, _ := bytes.Cut(head, []byte("\n"))
+	first = bytes.ToLower(first)
+	return (bytes.Contains(first, []byte("shares")) || bytes.Contains(first, []byte("stück"))) &&
+		(bytes.Contains(first, []byte("security name")) || bytes.Contains(first, []byte("wertpapiername")))
+}
+
+func (ppCSVImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	t, err := readCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		date   = []string{"date", "datum"}
+		kind   = []string{"type", "typ"}
+		value  = []string{"value", "wert"}
+		gross  = []string{"gross amount", "bruttobetrag"}
+		shares = []string{"shares", "stück"}
+		fees   = []string{"fees", "gebühren"}
+		taxes  = []string{"taxes", "steuern"}
+		isin   = []string{"isin"}
+		ticker = []string{"ticker symbol", "ticker-symbol"}
+		name   = []string{"security name", "wertpapiername"}
+		note   = []string{"note", "notiz"}
+	)
+	if err := t.require("Portfolio Performance", date, kind, value); err != nil {
+		return nil, err
+	}
+
+	acct := StatementAccount{ID: DefaultString(opts.Account, "portfolio-performance")}
+	for i, row := range t.rows {
+		raw := strings.ToLower(t.get(row, kind...))
+		k, known := ppTransactionKinds[raw]
+		if !known {
+			continue
+		}
+		e, err := func() (e StatementEntry, err error) {
+			e.Kind = k
+			if e.Date, err = parseBrokerDate(strings.Replace(t.get(row, date...), "T", " ", 1), "2006-01-02", "02.01.2006", "01/02/2006"); err != nil {
+				return e, err
+			}
+			e.Security = StatementSecurity{ID: t.get(row, isin...), Ticker: t.get(row, ticker...), Name: t.get(row, name...)}
+			e.Description = DefaultString(t.get(row, note...), e.Security.Name)
+			amount, err := parseStatementAmount(t.get(row, value...))
+			if err != nil {
+				return e, err
+			}
+			amount = absMoney(amount)
+			fee, err := optionalAmount(t.get(row, fees...))
+			if err != nil {
+				return e, err
+			}
+			tax, err := optionalAmount(t.get(row, taxes...))
+			if err != nil {
+				return e, err
+			}
+			e.Fees = absMoney(fee) + absMoney(tax)
+
+			switch k {
+			case StatementCash:
+				e.Amount = amount
+				if ppWithdrawal(raw) {
+					e.Amount = -amount
+				}
+				e.Security = StatementSecurity{}
+			case StatementIncome:
+				// La valeur d'un dividende est nette d'impôts : le montant brut est versé
+				e.Amount = amount + absMoney(tax)
+			default:
+				if e.Units, err = parseStatementUnits(t.get(row, shares...)); err != nil {
+					return e, err
+				}
+				if e.Units <= 0 {
+					return e, fmt.Errorf("nombre de parts absent")
+				}
+				// La valeur inclut les frais à l'achat et les déduit à la vente
+				e.Amount, err = optionalAmount(t.get(row, gross...))
+				switch {
+				case err != nil:
+					return e, err
+				case e.Amount == 0 && k == StatementBuy:
+					e.Amount = amount - e.Fees
+				case e.Amount == 0:
+					e.Amount = amount + e.Fees
+				}
+				e.Price = e.Amount.Mul(1 / e.Units.Float64())
+			}
+			return e, nil
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("export Portfolio Performance: %w", CSVLineError{Line: t.first + i, Err: err})
+		}
+		acct.Entries = append(acct.Entries, e)
+	}
+	return &Statement{Accounts: []StatementAccount{acct}}, nil
+}
+
+// xmlNode est un élément d'un document XML lu sans schéma
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string
+	Children []*xmlNode
+}
+
+// child retourne le premier enfant d'un nom donné, nil s'il n'y en a pas
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// text retourne le texte du premier enfant d'un nom donné
+func (n *xmlNode) text(name string) string {
+	if c := n.child(name); c != nil {
+		return strings.TrimSpace(c.Text)
+	}
+	return ""
+}
+
+// walk appelle fn pour chaque élément de l'arbre, en profondeur
+func (n *xmlNode) walk(fn func(*xmlNode)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.walk(fn)
+	}
+}
+
+// parseXMLTree lit un document XML en arbre d'éléments
+func parseXMLTree(r io.Reader) (*xmlNode, error) {
+	dec := xml.NewDecoder(r)
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("lecture du XML: %w", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{Name: tok.Name.Local, Attrs: make(map[string]string, len(tok.Attr))}
+			for _, a := range tok.Attr {
+				n.Attrs[a.Name.Local] = a.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].Text += string(tok)
+		}
+	}
+	if len(root.Children) == 0 {
+		return nil, fmt.Errorf("document XML vide")
+	}
+	return root.Children[0], nil
+}
+
+// ppXMLImporter lit le fichier XML de Portfolio Performance (non chiffré) : les titres,
+// les opérations des portefeuilles et des comptes, et l'historique des cours, dont sont
+// déduites les valeurs des lignes détenues
+type ppXMLImporter struct{}
+
+func (ppXMLImporter) Name() string { return "pp-xml" }
+
+func (ppXMLImporter) Detect(head []byte) bool {
+	return bytes.Contains(head, []byte("<client")) && bytes.Contains(head, []byte("<securities"))
+}
+
+// ppSecurityIndex retourne l'indice (à partir de 0) du titre désigné par une référence
+// XStream (« ../../../../securities/security[3] »)
+func ppSecurityIndex(ref string) (int, bool) {
+	_, last, _ := strings.Cut(ref, "securities/security")
+	if last == "" {
+		return 0, strings.HasSuffix(ref, "securities/security")
+	}
+	n, err := strconv.Atoi(strings.Trim(last, "[]"))
+	return n - 1, err == nil && n > 0
+}
+
+func (ppXMLImporter) Parse(r io.Reader, opts StatementOptions) (*Statement, error) {
+	client, err := parseXMLTree(r)
+	if err != nil {
+		return nil, err
+	}
+	if client.Name != "client" {
+		return nil, fmt.Errorf("fichier Portfolio Performance: élément racine <%s> inattendu", client.Name)
+	}
+
+	type ppSecurity struct {
+		security StatementSecurity
+		currency Currency
+		prices   *xmlNode
+	}
+	var securities []ppSecurity
+	if list := client.child("securities"); list != nil {
+		for _, s := range list.Children {
+			securities = append(securities, ppSecurity{
+				security: StatementSecurity{ID: s.text("isin"), Ticker: s.text("tickerSymbol"), Name: s.text("name")},
+				currency: Currency(s.text("currencyCode")),
+				prices:   s.child("prices"),
+			})
+		}
+	}
+	// Titre d'une opération : élément complet à sa première occurrence, référence ensuite
+	securityOf := func(tx *xmlNode) (int, bool) {
+		s := tx.child("security")
+		if s == nil {
+			return 0, false
+		}
+		if ref, isRef := s.Attrs["reference"]; isRef {
+			return ppSecurityIndex(ref)
+		}
+		for i, known := range securities {
+			if known.security.Name == s.text("name") && known.security.ID == s.text("isin") {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	acct := StatementAccount{ID: DefaultString(opts.Account, "portfolio-performance")}
+	held := make(map[int][]StatementEntry) // Achats et ventes de chaque titre, pour les positions
+	var parseErr error
+	client.walk(func(n *xmlNode) {
+		if parseErr != nil || (n.Name != "portfolio-transaction" && n.Name != "account-transaction") || n.Attrs["reference"] != "" {
+			return
+		}
+		raw := strings.ToLower(n.text("type"))
+		kind, known := ppTransactionKinds[raw]
+		// Les achats et ventes figurent aussi, côté espèces, dans les opérations du compte
+		if !known || (n.Name == "account-transaction" && (kind == StatementBuy || kind == StatementSell)) ||
+			(n.Name == "portfolio-transaction" && kind != StatementBuy && kind != StatementSell) {
+			return
+		}
+		e := StatementEntry{Kind: kind, Description: n.text("note")}
+		date, _, _ := strings.Cut(n.text("date"), "T")
+		if e.Date, parseErr = ParseDate(date); parseErr != nil {
+			return
+		}
+		amount, err := strconv.ParseInt(n.text("amount"), 10, 64)
+		if err != nil {
+			parseErr = fmt.Errorf("montant '%s' invalide", n.text("amount"))
+			return
+		}
+		e.Amount = NewMoney(float64(amount) / ppAmountFactor)
+		if units := n.child("units"); units != nil {
+			for _, u := range units.Children {
+				if t := u.Attrs["type"]; t == "FEE" || t == "TAX" {
+					if a := u.child("amount"); a != nil {
+						v, _ := strconv.ParseInt(a.Attrs["amount"], 10, 64)
+						e.Fees += NewMoney(float64(v) / ppAmountFactor)
+					}
+				}
+			}
+		}
+		i, hasSecurity := securityOf(n)
+		if hasSecurity && i < len(securities) {
+			e.Security = securities[i].security
+			if e.Description == "" {
+				e.Description = e.Security.Name
+			}
+		}
+
+		switch kind {
+		case StatementCash:
+			if ppWithdrawal(raw) {
+				e.Amount = -e.Amount
+			}
+		case StatementIncome:
+			e.Amount += e.Fees
+		default:
+			shares, err := strconv.ParseInt(n.text("shares"), 10, 64)
+			if err != nil || shares <= 0 || !hasSecurity {
+				parseErr = fmt.Errorf("opération %s du %s sans titre ni parts", raw, date)
+				return
+			}
+			e.Units = NewQuantity(float64(shares) / ppSharesFactor)
+			// Le montant inclut les frais à l'achat et les déduit à la vente
+			gross := e.Amount - e.Fees
+			if kind == StatementSell {
+				gross = e.Amount + e.Fees
+			}
+			e.Price = gross.Mul(1 / e.Units.Float64())
+			held[i] = append(held[i], e)
+		}
+		acct.Entries = append(acct.Entries, e)
+	})
+	if parseErr != nil {
+		return nil, fmt.Errorf("fichier Portfolio Performance: %w", parseErr)
+	}
+
+	// Valeur des lignes aux dates des cours, selon les parts détenues à chaque date
+	for i, s := range securities {
+		trades := held[i]
+		if s.prices == nil || len(trades) == 0 {
+			continue
+		}
+		sort.SliceStable(trades, func(a, b int) bool { return trades[a].Date.Before(trades[b].Date) })
+		var units Quantity
+		next := 0
+		for _, price := range s.prices.Children {
+			date, err := ParseDate(price.Attrs["t"])
+			if err != nil {
+				return nil, fmt.Errorf("cours de %s: %w", s.security, err)
+			}
+			for ; next < len(trades) && !trades[next].Date.After(date); next++ {
+				if trades[next].Kind == StatementSell {
+					units -= trades[next].Units
+				} else {
+					units += trades[next].Units
+				}
+			}
+			v, err := strconv.ParseInt(price.Attrs["v"], 10, 64)
+			if err != nil || units <= 0 {
+				continue
+			}
+			quote := NewMoney(float64(v) / ppQuoteFactor)
+			acct.Positions = append(acct.Positions, StatementPosition{
+				Date: date, Security: s.security, Units: units, Price: quote, Value: quote.Mul(units.Float64()),
+			})
+		}
+	}
+	return &Statement{Accounts: []StatementAccount{acct}}, nil
+}
+
+// ppExportTypes sont les libellés des opérations dans l'export CSV pour Portfolio Performance
+var ppExportTypes = map[StatementEntryKind]string{
+	StatementBuy:    "Buy",
+	StatementSell:   "Sell",
+	StatementIncome: "Dividend",
+}
+
+// ExportPortfolioPerformanceCSV écrit les opérations du portefeuille au format CSV
+// qu'importe Portfolio Performance (Fichier > Importer > CSV, opérations du
+// portefeuille) : achats et ventes de parts, parts fictives pour les investissements
+// suivis en montant (voir trackerEntries), et dividendes
+func (p *Portfolio) ExportPortfolioPerformanceCSV(w io.Writer) error {
+	entries := p.trackerEntries()
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"Date", "Type", "Security Name", "ISIN", "Ticker Symbol", "Shares", "Value", "Gross Amount", "Fees", "Transaction Currency", "Note"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		value := e.Amount + e.Fees
+		if e.Kind == StatementSell {
+			value = e.Amount - e.Fees
+		}
+		shares := ""
+		if e.Units > 0 {
+			shares = e.Units.String()
+		}
+		record := []string{
+			FormatDate(e.Date), ppExportTypes[e.Kind], e.Security.Name, e.Security.ID, e.Security.Ticker,
+			shares, value.String(), e.Amount.String(), e.Fees.String(), string(e.currency), e.Description,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// trackerEntry est une opération exportée vers un autre logiciel de suivi
+type trackerEntry struct {
+	StatementEntry
+	currency Currency
+}
+
+// trackerEntries retourne les opérations de tous les investissements, triées par date,
+// sous la forme attendue par les logiciels qui suivent des parts : le registre de parts
+// s'il existe, sinon des parts fictives valant 100 à l'investissement, achetées ou
+// vendues à chaque apport ou retrait au prix déduit de la valeur à cette date ; les
+// distributions versées suivent
+func (p *Portfolio) trackerEntries() []trackerEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var entries []trackerEntry
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		security := StatementSecurity{ID: inv.ISIN, Ticker: inv.Ticker, Name: name}
+		add := func(e StatementEntry) {
+			e.Security = security
+			entries = append(entries, trackerEntry{StatementEntry: e, currency: inv.EffectiveCurrency()})
+		}
+		for _, tx := range inv.unitLedger() {
+			kind := StatementBuy
+			if tx.Type == Sell {
+				kind = StatementSell
+			}
+			add(StatementEntry{Date: tx.Date.Time, Kind: kind, Units: tx.Units, Price: NewMoney(tx.Price.Float64()), Amount: tx.Amount(), Fees: tx.Fees})
+		}
+		for _, d := range inv.Distributions {
+			if !d.Reinvested {
+				add(StatementEntry{Date: d.Date.Time, Kind: StatementIncome, Amount: d.Amount})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+	return entries
+}
+
+// trackerUnitPrice est la valeur d'une part fictive à la date d'investissement
+const trackerUnitPrice = 100
+
+// unitLedger retourne le registre en parts de l'investissement : le sien s'il en a un,
+// sinon celui de parts fictives (voir trackerEntries), clôture comprise
+func (inv *Investment) unitLedger() []Transaction {
+	if txs := inv.ledger(); len(txs) > 0 {
+		return txs
+	}
+	if inv.AmountInvested <= 0 {
+		return nil
+	}
+	units := NewQuantity(inv.AmountInvested.Float64() / trackerUnitPrice)
+	txs := []Transaction{{Date: inv.InvestmentDate, Type: Buy, Units: units, Price: NewPrice(trackerUnitPrice)}}
+
+	// Les flux d'une même date sont exécutés au prix précédant le premier d'entre eux ;
+	// une NAV du jour inclut déjà ces flux (voir CashFlow)
+	for i := 0; i < len(inv.CashFlows); {
+		date := inv.CashFlows[i].Date
+		j := i
+		var net Money
+		for ; j < len(inv.CashFlows) && inv.CashFlows[j].Date.Equal(date.Time); j++ {
+			net += inv.CashFlows[j].SignedAmount()
+		}
+		value, held := inv.historicalValue(date.Time)
+		if _, onNAV := inv.navIndex(date.Time); onNAV {
+			value -= net.Float64()
+		}
+		if held && value > 0 && units > 0 {
+			price := NewPrice(value / units.Float64())
+			for _, cf := range inv.CashFlows[i:j] {
+				n := NewQuantity(cf.Amount.Float64() / price.Float64())
+				tx := Transaction{Date: date, Type: Buy, Units: n, Price: price}
+				if cf.Type == Withdrawal {
+					n = min(n, units)
+					tx.Type, tx.Units = Sell, n
+					units -= n
+				} else {
+					units += n
+				}
+				if n > 0 {
+					txs = append(txs, tx)
+				}
+			}
+		}
+		i = j
+	}
+
+	if inv.Closed && units > 0 {
+		if value, held := inv.historicalValue(inv.ClosedDate.Time); held && value > 0 {
+			txs = append(txs, Transaction{Date: inv.ClosedDate, Type: Sell, Units: units, Price: NewPrice(value / units.Float64())})
+		}
+	}
+	return txs
+}
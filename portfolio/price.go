@@ -0,0 +1,90 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Price est un prix unitaire en virgule fixe exprimé en cent-millionièmes, comme Quantity.
+// Le cours d'un cryptoactif ou d'un jeton bon marché se compte en fractions de centime
+// que Money, au dix-millième, arrondirait à zéro.
+type Price int64
+
+// priceDecimals est le nombre de décimales conservées par Price
+const (
+	priceDecimals = 8
+	priceScale    = 100000000
+)
+
+// NewPrice convertit un flottant en Price, arrondi au cent-millionième le plus proche
+func NewPrice(v float64) Price {
+	return Price(math.Round(v * priceScale))
+}
+
+// ParsePrice lit un prix décimal ("0.00001234") sans passer par un flottant
+func ParsePrice(s string) (Price, error) {
+	v, ok := parseFixed(s, priceDecimals)
+	if !ok {
+		return 0, fmt.Errorf("prix '%s' invalide", s)
+	}
+	return Price(v), nil
+}
+
+// Float64 retourne le prix sous forme de flottant, pour les valorisations
+func (p Price) Float64() float64 {
+	return float64(p) / priceScale
+}
+
+// Total retourne le montant de units parts à ce prix, arrondi au dix-millième
+func (p Price) Total(units Quantity) Money {
+	return NewMoney(p.Float64() * units.Float64())
+}
+
+// String retourne le prix en notation décimale, avec au moins deux décimales
+func (p Price) String() string {
+	sign := ""
+	abs := int64(p)
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	frac := strings.TrimRight(fmt.Sprintf("%0*d", priceDecimals, abs%priceScale), "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, abs/priceScale, frac)
+}
+
+// MarshalJSON encode le prix comme un nombre JSON décimal exact
+func (p Price) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON accepte un nombre JSON ou une chaîne décimale, dont les prix enregistrés
+// au dix-millième par les versions antérieures
+func (p *Price) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "null" {
+		return nil
+	}
+
+	// Notation exponentielle : repli sur le flottant
+	if strings.ContainsAny(raw, "eE") {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("prix '%s' invalide", raw)
+		}
+		*p = NewPrice(v)
+		return nil
+	}
+
+	parsed, err := ParsePrice(raw)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,32 @@
+package portfolio
+
+import "testing"
+
+func TestPriceTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		price string
+		units string
+		want  string
+	}{
+		{name: "prix entier", price: "16", units: "50", want: "800.00"},
+		{name: "jeton sous le centime", price: "0.000024", units: "100000000", want: "2400.00"},
+		{name: "arrondi au dix-millième", price: "0.00001234", units: "1", want: "0.00"},
+		{name: "fraction de bitcoin", price: "60000", units: "0.00012345", want: "7.407"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := ParsePrice(tt.price)
+			if err != nil {
+				t.Fatal(err)
+			}
+			units, err := ParseQuantity(tt.units)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := price.Total(units).String(); got != tt.want {
+				t.Errorf("%s × %s = %s, %s attendu", tt.units, tt.price, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// Projector est un modèle de projection : il prolonge la dernière NAV d'un investissement
+// jusqu'à une date. Les modèles sont déclarés par RegisterProjector ou définis dans la
+// table [projectors] de la configuration, et choisis investissement par investissement
+// (voir SetProjection) ; sans choix, ProjectNAV capitalise au taux effectif (compound).
+type Projector interface {
+	// Name est le nom du modèle, référencé par Investment.Projection
+	Name() string
+	// Project retourne la valeur projetée à in.End
+	Project(in ProjectionInput) (float64, error)
+}
+
+// ProjectionInput est ce qu'un modèle reçoit pour projeter un investissement
+type ProjectionInput struct {
+	Investment    *Investment
+	Start         NAV                // Dernière NAV connue
+	End           time.Time          // Date de projection, postérieure à Start.Date
+	Rate          float64            // Taux annuel (%) retenu par la règle de taux, signé selon le sens de la position
+	Contributions []CashFlow         // Versements bruts datés dans ]Start.Date, End], triés par date
+	Params        map[string]float64 // Paramètres du modèle propres à l'investissement
+}
+
+// param retourne un paramètre du modèle, fallback s'il n'est pas renseigné
+func (in ProjectionInput) param(name string, fallback float64) float64 {
+	if v, ok := in.Params[name]; ok {
+		return v
+	}
+	return fallback
+}
+
+// ProjectionModel désigne le modèle de projection d'un investissement et ses paramètres
+type ProjectionModel struct {
+	Model  string             `json:"model"`
+	Params map[string]float64 `json:"params,omitempty"`
+}
+
+func (m ProjectionModel) String() string {
+	keys := make([]string, 0, len(m.Params))
+	for k := range m.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		params = append(params, k+"="+strconv.FormatFloat(m.Params[k], 'f', -1, 64))
+	}
+	if len(params) == 0 {
+		return m.Model
+	}
+	return m.Model + " (" + strings.Join(params, ", ") + ")"
+}
+
+// Modèles fournis
+const (
+	ProjectorCompound      = "compound"       // Capitalisation au taux effectif
+	ProjectorMeanReverting = "mean-reverting" // Taux revenant vers un taux de long terme
+	ProjectorMonteCarlo    = "monte-carlo"    // Médiane de trajectoires simulées
+)
+
+var (
+	projectorsMu sync.RWMutex
+	projectors   []Projector
+)
+
+// RegisterProjector ajoute un modèle ; un modèle de même nom est remplacé
+func RegisterProjector(proj Projector) {
+	projectorsMu.Lock()
+	defer projectorsMu.Unlock()
+
+	for i, existing := range projectors {
+		if existing.Name() == proj.Name() {
+			projectors[i] = proj
+			return
+		}
+	}
+	projectors = append(projectors, proj)
+}
+
+// Projectors retourne les noms des modèles disponibles : déclarés, puis ceux de la
+// configuration
+func Projectors() []string {
+	projectorsMu.RLock()
+	defer projectorsMu.RUnlock()
+
+	names := make([]string, 0, len(projectors))
+	for _, proj := range projectors {
+		names = append(names, proj.Name())
+	}
+	for _, proj := range ActiveConfig().Projectors {
+		names = append(names, proj.Name())
+	}
+	return names
+}
+
+func init() {
+	RegisterProjector(compoundProjector{})
+	RegisterProjector(meanRevertingProjector{})
+	RegisterProjector(monteCarloProjector{})
+}
+
+// lookupProjector retourne le modèle déclaré ou configuré sous ce nom
+func lookupProjector(name string) (Projector, error) {
+	projectorsMu.RLock()
+	defer projectorsMu.RUnlock()
+
+	for _, proj := range projectors {
+		if proj.Name() == name {
+			return proj, nil
+		}
+	}
+	for _, proj := range ActiveConfig().Projectors {
+		if proj.Name() == name {
+			return proj, nil
+		}
+	}
+	return nil, fmt.Errorf("modèle de projection inconnu: %s: %w", name, ErrNotFound)
+}
+
+// project prolonge start jusqu'à end avec le modèle de l'investissement, au taux annuel
+// rate (%) déjà signé
+func (inv *Investment) project(start NAV, end time.Time, rate float64, contributions []CashFlow) (float64, error) {
+	if err := guardrails().checkHorizon(start.Date.Time, end); err != nil {
+		return 0, err
+	}
+	if inv.Projection == nil || inv.Projection.Model == ProjectorCompound {
+		return compound(inv.conventions, inv.Fees, start, end, rate, contributions), nil
+	}
+	proj, err := lookupProjector(inv.Projection.Model)
+	if err != nil {
+		return 0, err
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].Date.Before(contributions[j].Date.Time)
+	})
+	value, err := proj.Project(ProjectionInput{
+		Investment:    inv,
+		Start:         start,
+		End:           end,
+		Rate:          rate,
+		Contributions: contributions,
+		Params:        inv.Projection.Params,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("modèle %s: %w", proj.Name(), err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("modèle %s: projection indéfinie", proj.Name())
+	}
+	return value, nil
+}
+
+// SetProjection choisit le modèle de projection d'un investissement ; un modèle vide
+// rétablit la capitalisation au taux effectif
+func (p *Portfolio) SetProjection(name string, model ProjectionModel) error {
+	if model.Model != "" {
+		if _, err := lookupProjector(model.Model); err != nil {
+			return InvalidField("model", model.Model, "modèle de projection inconnu: %s (%s)", model.Model, strings.Join(Projectors(), ", "))
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	if model.Model == "" {
+		inv.Projection = nil
+	} else {
+		inv.Projection = &model
+	}
+	inv.invalidate()
+	p.record(OpSetProjection, name, fmt.Sprintf("modèle %q", model.Model), before)
+	return nil
+}
+
+// compoundProjector capitalise au taux effectif, frais déduits : le modèle par défaut
+type compoundProjector struct{}
+
+func (compoundProjector) Name() string { return ProjectorCompound }
+
+func (compoundProjector) Project(in ProjectionInput) (float64, error) {
+	return compound(in.Investment.conventions, in.Investment.Fees, in.Start, in.End, in.Rate, in.Contributions), nil
+}
+
+// meanRevertingProjector fait revenir le taux du taux effectif vers un taux de long
+// terme (paramètre long_rate, en %, taux de référence par défaut), l'écart étant divisé
+// par deux tous les half_life ans (3 par défaut). Adapté aux actifs dont le rendement
+// récent n'est pas reconductible (matières premières, immobilier coté).
+type meanRevertingProjector struct{}
+
+func (meanRevertingProjector) Name() string { return ProjectorMeanReverting }
+
+func (meanRevertingProjector) Project(in ProjectionInput) (float64, error) {
+	inv := in.Investment
+	longRate := inv.navRate(in.param("long_rate", inv.ReferenceRate))
+	halfLife := in.param("half_life", 3)
+	if halfLife <= 0 {
+		return 0, InvalidField("half_life", halfLife, "la demi-vie doit être positive")
+	}
+	speed := math.Ln2 / halfLife
+	rateAt := func(t time.Time) float64 {
+		return longRate + (in.Rate-longRate)*math.Exp(-speed*inv.conventions.YearsBetween(in.Start.Date.Time, t))
+	}
+	return stepPath(inv.Fees, in.Start, in.End, in.Contributions, func(value float64, from, to time.Time) float64 {
+		// Taux du milieu du pas
+		mid := from.Add(to.Sub(from) / 2)
+		return inv.Fees.grow(inv.conventions, value, inv.conventions.YearsBetween(from, to), rateAt(mid))
+	}), nil
+}
+
+// monteCarloProjector retourne la médiane (ou le percentile du paramètre percentile)
+// de trajectoires log-normales centrées sur le taux effectif, avec la volatilité
+// observée. Les paramètres paths (1000) et seed (1) fixent le tirage : la projection est
+// la même d'une commande à l'autre.
+type monteCarloProjector struct{}
+
+func (monteCarloProjector) Name() string { return ProjectorMonteCarlo }
+
+func (monteCarloProjector) Project(in ProjectionInput) (float64, error) {
+	inv := in.Investment
+	paths := int(in.param("paths", 1000))
+	if paths <= 0 {
+		return 0, InvalidField("paths", paths, "le nombre de trajectoires doit être positif")
+	}
+	level := in.param("percentile", 50)
+	if level < 0 || level > 100 {
+		return 0, InvalidField("percentile", level, "le percentile doit être compris entre 0 et 100")
+	}
+	conv := inv.conventions
+	drift := conv.RateLog(in.Rate)
+	volatility := inv.volatility()
+	rng := newMonteCarloRand(uint64(in.param("seed", 1)))
+
+	values := make([]float64, paths)
+	for i := range values {
+		values[i] = stepPath(inv.Fees, in.Start, in.End, in.Contributions, func(value float64, from, to time.Time) float64 {
+			years := conv.YearsBetween(from, to)
+			if years <= 0 {
+				return value
+			}
+			logReturn := drift*years + volatility*math.Sqrt(years)*rng.NormFloat64()
+			return inv.Fees.grow(conv, value, years, conv.RateFromLog(logReturn/years))
+		})
+	}
+	slices.Sort(values)
+	return analytics.Percentile(values, level), nil
+}
+
+// stepPath fait évoluer start jusqu'à end par pas d'au plus un mois, chaque versement
+// (net des frais d'entrée) étant ajouté à sa date
+func stepPath(fees *FeeSchedule, start NAV, end time.Time, contributions []CashFlow, step func(value float64, from, to time.Time) float64) float64 {
+	value := start.Value.Float64()
+	current := start.Date
+	advance := func(to time.Time) {
+		for current.Before(to) {
+			next := current.AddDate(0, 1, 0)
+			if next.After(to) {
+				next = to
+			}
+			value = step(value, current.Time, next)
+			current = Date{next}
+		}
+	}
+	for _, c := range contributions {
+		advance(c.Date.Time)
+		value += fees.netContribution(c.Amount.Float64())
+	}
+	advance(end)
+	return value
+}
+
+// projectorVariables décrit les variables des modèles de la table [projectors], en plus
+// des paramètres de l'investissement. Les taux sont en fraction (0.05 pour 5 %).
+var projectorVariables = map[string]string{
+	"value":          "valeur de départ : dernière NAV, ou versement net de frais",
+	"years":          "durée de la projection en années",
+	"rate":           "taux annuel retenu par la règle de taux",
+	"reference_rate": "taux de référence",
+	"ter":            "frais courants annuels",
+	"volatility":     "volatilité annualisée observée",
+}
+
+// ExprProjector est un modèle défini par une expression dans la configuration, par
+// exemple plateau = "value * (1 + min(rate, 0.04)) ^ years". L'expression projette une
+// valeur sur years années ; les versements programmés sont projetés un à un depuis leur
+// date, le modèle étant supposé proportionnel à la valeur de départ.
+type ExprProjector struct {
+	name, Source string
+	eval         exprNode
+}
+
+// ParseProjector analyse l'expression d'un modèle de projection
+func ParseProjector(name, source string) (Projector, error) {
+	name = strings.TrimSpace(name)
+	if !IsExprIdent(strings.ReplaceAll(name, "-", "_")) {
+		return nil, InvalidField("projector", name, "nom de modèle invalide: '%s'", name)
+	}
+	p := &exprParser{src: source}
+	p.next()
+	node, err := p.parseExpr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("'%s' inattendu", p.tok.text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("modèle %s: %w", name, err)
+	}
+	return ExprProjector{name: name, Source: source, eval: node}, nil
+}
+
+func (e ExprProjector) Name() string { return e.name }
+
+func (e ExprProjector) String() string { return e.name + " = " + e.Source }
+
+func (e ExprProjector) Project(in ProjectionInput) (float64, error) {
+	inv := in.Investment
+	vars := make(map[string]float64, len(in.Params)+len(projectorVariables))
+	for k, v := range in.Params {
+		vars[k] = v
+	}
+	vars["rate"] = in.Rate / 100
+	vars["reference_rate"] = inv.ReferenceRate / 100
+	vars["volatility"] = inv.volatility()
+	if inv.Fees != nil {
+		vars["ter"] = inv.Fees.TER / 100
+	} else {
+		vars["ter"] = 0
+	}
+
+	at := func(value float64, from time.Time) float64 {
+		vars["value"] = value
+		vars["years"] = inv.conventions.YearsBetween(from, in.End)
+		return e.eval(vars)
+	}
+	total := at(in.Start.Value.Float64(), in.Start.Date.Time)
+	for _, c := range in.Contributions {
+		total += at(inv.Fees.netContribution(c.Amount.Float64()), c.Date.Time)
+	}
+	return total, nil
+}
+
+// validateProjectors vérifie que les modèles de la configuration ont des noms uniques,
+// distincts des modèles fournis
+func validateProjectors(configured []Projector) error {
+	seen := make(map[string]bool)
+	for _, proj := range configured {
+		name := proj.Name()
+		if seen[name] || name == ProjectorCompound || name == ProjectorMeanReverting || name == ProjectorMonteCarlo {
+			return InvalidField("projector", name, "modèle de projection '%s' déjà défini", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package portfolio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// qifInvestmentActions associe les actions QIF des comptes de titres (sans le suffixe X
+// des opérations réglées sur un autre compte) aux opérations du relevé
+var qifInvestmentActions = map[string]StatementEntryKind{
+	"BUY":      StatementBuy,
+	"SELL":     StatementSell,
+	"DIV":      StatementIncome,
+	"INTINC":   StatementIncome,
+	"CGLONG":   StatementIncome,
+	"CGSHORT":  StatementIncome,
+	"CGMID":    StatementIncome,
+	"MISCINC":  StatementIncome,
+	"RTRNCAP":  StatementIncome,
+	"REINVDIV": StatementReinvest,
+	"REINVINT": StatementReinvest,
+	"REINVLG":  StatementReinvest,
+	"REINVSH":  StatementReinvest,
+	"REINVMD":  StatementReinvest,
+	"CASH":     StatementCash,
+	"XIN":      StatementCash,
+	"XOUT":     StatementCash,
+	"CONTRIB":  StatementCash,
+	"WITHDRWL": StatementCash,
+}
+
+// ParseQIF lit un relevé QIF : comptes bancaires, d'espèces et de cartes (mouvements
+// d'espèces) et comptes de titres (achats, ventes, revenus, réinvestissements, virements).
+// Les listes de catégories, de classes et d'opérations mémorisées sont ignorées, de même
+// que les actions sans effet sur le portefeuille (transferts de titres, fractionnements).
+func ParseQIF(r io.Reader, opts StatementOptions) (*Statement, error) {
+	st := &Statement{}
+	accounts := make(map[string]int) // Indice de chaque compte dans st.Accounts
+	account := opts.Account
+	section := ""
+	fields := make(map[byte]string)
+
+	flush := func(line int) error {
+		defer clear(fields)
+		if len(fields) == 0 {
+			return nil
+		}
+		var e StatementEntry
+		var ok bool
+		var err error
+		switch section {
+		case "BANK", "CASH", "CCARD", "OTH A", "OTH L":
+			e, ok, err = parseQIFCash(fields, opts)
+		case "INVST":
+			e, ok, err = parseQIFInvestment(fields, opts)
+		case "ACCOUNT":
+			if name := fields['N']; name != "" {
+				account = name
+			}
+			return nil
+		default:
+			return nil
+		}
+		if err != nil {
+			return CSVLineError{Line: line, Err: err}
+		}
+		if !ok {
+			return nil
+		}
+		i, exists := accounts[account]
+		if !exists {
+			i = len(st.Accounts)
+			accounts[account] = i
+			st.Accounts = append(st.Accounts, StatementAccount{ID: account})
+		}
+		st.Accounts[i].Entries = append(st.Accounts[i].Entries, e)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r ")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "!"):
+			if err := flush(line); err != nil {
+				return nil, fmt.Errorf("relevé QIF: %w", err)
+			}
+			header := strings.ToUpper(strings.TrimSpace(text[1:]))
+			switch {
+			case header == "ACCOUNT":
+				section = "ACCOUNT"
+			case strings.HasPrefix(header, "TYPE:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "TYPE:"))
+			case strings.HasPrefix(header, "OPTION:"), strings.HasPrefix(header, "CLEAR:"):
+				// Options d'export sans effet sur les opérations
+			default:
+				section = header
+			}
+		case text == "^":
+			if err := flush(line); err != nil {
+				return nil, fmt.Errorf("relevé QIF: %w", err)
+			}
+		default:
+			// Les répartitions (S, E, $) ne sont pas importées : seul le total compte
+			fields[text[0]] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(line); err != nil {
+		return nil, fmt.Errorf("relevé QIF: %w", err)
+	}
+	if len(st.Accounts) == 0 {
+		return nil, fmt.Errorf("relevé QIF: aucune opération")
+	}
+	return st, nil
+}
+
+// parseQIFCash lit une opération d'un compte bancaire ; ok est faux pour une opération nulle
+func parseQIFCash(fields map[byte]string, opts StatementOptions) (e StatementEntry, ok bool, err error) {
+	if e.Date, err = parseQIFDate(fields['D'], opts.MonthFirst); err != nil {
+		return StatementEntry{}, false, err
+	}
+	raw := fields['T']
+	if raw == "" {
+		raw = fields['U']
+	}
+	if e.Amount, err = parseStatementAmount(raw); err != nil {
+		return StatementEntry{}, false, err
+	}
+	e.Kind = StatementCash
+	e.Description = strings.TrimSpace(fields['P'] + " " + fields['M'])
+	return e, e.Amount != 0, nil
+}
+
+// parseQIFInvestment lit une opération d'un compte de titres
+func parseQIFInvestment(fields map[byte]string, opts StatementOptions) (e StatementEntry, ok bool, err error) {
+	action := strings.ToUpper(fields['N'])
+	kind, known := qifInvestmentActions[action]
+	if !known && strings.HasSuffix(action, "X") {
+		kind, known = qifInvestmentActions[strings.TrimSuffix(action, "X")]
+	}
+	if !known {
+		return StatementEntry{}, false, nil
+	}
+	if e.Date, err = parseQIFDate(fields['D'], opts.MonthFirst); err != nil {
+		return StatementEntry{}, false, err
+	}
+	e.Kind = kind
+	e.Security = StatementSecurity{Name: fields['Y']}
+	e.Description = strings.TrimSpace(fields['P'] + " " + fields['M'])
+
+	raw := fields['T']
+	if raw == "" {
+		raw = fields['U']
+	}
+	if raw == "" {
+		raw = fields['$']
+	}
+	if raw != "" {
+		if e.Amount, err = parseStatementAmount(raw); err != nil {
+			return StatementEntry{}, false, err
+		}
+	}
+
+	switch kind {
+	case StatementBuy, StatementSell:
+		if e.Units, err = parseStatementUnits(fields['Q']); err != nil {
+			return StatementEntry{}, false, err
+		}
+		if e.Price, err = parseStatementAmount(fields['I']); err != nil {
+			return StatementEntry{}, false, err
+		}
+		if raw := fields['O']; raw != "" {
+			if e.Fees, err = parseStatementAmount(raw); err != nil {
+				return StatementEntry{}, false, err
+			}
+		}
+	case StatementCash:
+		// Les montants des virements sont positifs : le sens vient de l'action
+		if e.Amount < 0 {
+			e.Amount = -e.Amount
+		}
+		if action == "XOUT" || action == "WITHDRWL" || (action == "CASH" && strings.HasPrefix(raw, "-")) {
+			e.Amount = -e.Amount
+		}
+		e.Security = StatementSecurity{}
+		return e, e.Amount != 0, nil
+	}
+	if e.Amount < 0 {
+		e.Amount = -e.Amount
+	}
+	return e, true, nil
+}
+
+// parseQIFDate lit une date QIF : jour, mois et année séparés par « / », « - » ou « . »,
+// avec l'apostrophe des exports Quicken pour les années 2000 (1/15'24) et les années
+// sur deux chiffres
+func parseQIFDate(raw string, monthFirst bool) (time.Time, error) {
+	s := strings.NewReplacer("'", "/", "-", "/", ".", "/", " ", "").Replace(strings.TrimSpace(raw))
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+	}
+	var n [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+		}
+		n[i] = v
+	}
+	day, month, year := n[0], n[1], n[2]
+	if len(parts[0]) == 4 {
+		year, month, day = n[0], n[1], n[2]
+	} else if monthFirst {
+		day, month = n[1], n[0]
+	}
+	if year < 100 {
+		year += 2000
+		if year > time.Now().Year()+10 {
+			year -= 100
+		}
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || int(t.Month()) != month {
+		return time.Time{}, fmt.Errorf("date '%s' invalide: %w", raw, ErrInvalidDate)
+	}
+	return t, nil
+}
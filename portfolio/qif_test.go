@@ -0,0 +1,123 @@
+package portfolio
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const qifStatement = "\ufeff!Type:Bank\r\n" +
+	"D15/01/2024\r\nT-42,50\r\nPLIBRAIRIE\r\nMCB 0901\r\n^\r\n" +
+	"D31/01/2024\r\nT1 500,00\r\nPSALAIRE\r\n^\r\n" +
+	"D01/02/2024\r\nT0\r\n^\r\n" +
+	"!Account\r\nNPEA\r\n^\r\n" +
+	"!Type:Invst\r\n" +
+	"D01/03/2024\r\nNBuyX\r\nYAmundi MSCI World\r\nI12.50\r\nQ10\r\nO1.00\r\nT126.00\r\n^\r\n" +
+	"D15/03/2024\r\nNDiv\r\nYAmundi MSCI World\r\nT3.20\r\n^\r\n" +
+	"D20/03/2024\r\nNXOut\r\nT50.00\r\n^\r\n" +
+	"D21/03/2024\r\nNShrsIn\r\nYAmundi MSCI World\r\nQ5\r\n^\r\n"
+
+func TestParseQIF(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	world := StatementSecurity{Name: "Amundi MSCI World"}
+	st, err := ParseQIF(strings.NewReader(qifStatement), StatementOptions{Account: "Courant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		account string
+		entries []StatementEntry
+	}{
+		{account: "Courant", entries: []StatementEntry{
+			{Date: day(2024, time.January, 15), Kind: StatementCash, Amount: -425000, Description: "LIBRAIRIE CB 0901"},
+			{Date: day(2024, time.January, 31), Kind: StatementCash, Amount: 15000000, Description: "SALAIRE"},
+		}},
+		{account: "PEA", entries: []StatementEntry{
+			{Date: day(2024, time.March, 1), Kind: StatementBuy, Amount: 1260000, Units: 1000000000, Price: 125000, Fees: 10000, Security: world},
+			{Date: day(2024, time.March, 15), Kind: StatementIncome, Amount: 32000, Security: world},
+			{Date: day(2024, time.March, 20), Kind: StatementCash, Amount: -500000},
+		}},
+	}
+	if len(st.Accounts) != len(want) {
+		t.Fatalf("%d comptes lus, %d attendus", len(st.Accounts), len(want))
+	}
+	for i, w := range want {
+		acct := st.Accounts[i]
+		if acct.ID != w.account {
+			t.Errorf("compte %d = %s, %s attendu", i, acct.ID, w.account)
+		}
+		if len(acct.Entries) != len(w.entries) {
+			t.Fatalf("compte %s: %d opérations lues, %d attendues: %+v", acct.ID, len(acct.Entries), len(w.entries), acct.Entries)
+		}
+		for j, e := range w.entries {
+			if got := acct.Entries[j]; !got.Date.Equal(e.Date) || got.Kind != e.Kind || got.Amount != e.Amount ||
+				got.Units != e.Units || got.Price != e.Price || got.Fees != e.Fees ||
+				got.Security != e.Security || got.Description != e.Description {
+				t.Errorf("compte %s, opération %d = %+v, %+v attendue", acct.ID, j, got, e)
+			}
+		}
+	}
+}
+
+func TestParseQIFDate(t *testing.T) {
+	tests := []struct {
+		in         string
+		monthFirst bool
+		want       time.Time
+	}{
+		{in: "15/01/2024", want: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{in: "1/15'24", monthFirst: true, want: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{in: "2024-01-15", monthFirst: true, want: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{in: "15.01.99", want: time.Date(1999, time.January, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseQIFDate(tt.in, tt.monthFirst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseQIFDate(%q) = %s, %s attendu", tt.in, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+
+	for _, in := range []string{"", "15/01", "31/02/2024", "a/b/c", "15/13/2024"} {
+		if _, err := parseQIFDate(in, false); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("parseQIFDate(%q): ErrInvalidDate attendue, %v obtenue", in, err)
+		}
+	}
+}
+
+func TestParseQIFInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "vide", in: ""},
+		{name: "aucune opération", in: "!Type:Cat\nNAlimentation\n^\n"},
+		{name: "date invalide", in: "!Type:Bank\nD31/02/2024\nT10\n^\n"},
+		{name: "montant invalide", in: "!Type:Bank\nD01/02/2024\nTdix\n^\n"},
+		{name: "parts invalides", in: "!Type:Invst\nD01/02/2024\nNBuy\nQx\nI1\n^\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseQIF(strings.NewReader(tt.in), StatementOptions{}); err == nil {
+				t.Errorf("ParseQIF(%q): erreur attendue", tt.in)
+			}
+		})
+	}
+}
+
+func FuzzParseQIF(f *testing.F) {
+	for _, seed := range []string{qifStatement, "!Type:Bank\n^\n", "!Account\n^\n!Type:Invst\nNBuyX\n", "D\nT\n^"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		st, err := ParseQIF(strings.NewReader(in), StatementOptions{})
+		if err == nil && len(st.Accounts) == 0 {
+			t.Errorf("ParseQIF(%q): relevé sans compte accepté", in)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package portfolio
+
+import (
+	"slices"
+	"time"
+)
+
+// staleNAVDays est l'ancienneté (en jours) au-delà de laquelle la dernière NAV d'un
+// investissement est périmée : les projections qui en partent le signalent
+const staleNAVDays = 90
+
+// DataQuality décrit la qualité de l'historique de NAV d'un investissement
+type DataQuality struct {
+	Investment string
+	NAVs       int
+	Latest     time.Time  // Date de la dernière NAV (zéro sans NAV)
+	Staleness  int        // Jours écoulés depuis la dernière NAV, jusqu'à aujourd'hui ou la clôture
+	LongestGap int        // Plus long intervalle entre deux NAV consécutives, en jours
+	GapStart   time.Time  // Début de ce plus long intervalle
+	GapEnd     time.Time  // Fin de ce plus long intervalle
+	Expected   SeriesStep // Périodicité attendue des NAV (déduite de l'écart médian si non imposée)
+	Periods    int        // Périodes attendues depuis la première NAV (jours ouvrés en quotidien)
+	Covered    int        // Périodes comptant au moins une NAV
+	Score      float64    // Note de 0 à 100 : couverture, réduite au-delà de staleNAVDays d'ancienneté
+}
+
+// Coverage retourne la part (%) des périodes attendues comptant au moins une NAV
+func (q DataQuality) Coverage() float64 {
+	if q.Periods == 0 {
+		return 0
+	}
+	return float64(q.Covered) / float64(q.Periods) * 100
+}
+
+// Stale indique si la dernière NAV a plus de staleNAVDays jours
+func (q DataQuality) Stale() bool {
+	return q.NAVs > 0 && q.Staleness > staleNAVDays
+}
+
+// inferNAVStep déduit la périodicité d'un historique de l'écart médian entre ses NAV
+// (mensuelle à défaut de deux NAV)
+func inferNAVStep(navs []NAV) SeriesStep {
+	if len(navs) < 2 {
+		return StepMonthly
+	}
+	gaps := make([]int, 0, len(navs)-1)
+	for i := 1; i < len(navs); i++ {
+		gaps = append(gaps, daysBetween(navs[i-1].Date.Time, navs[i].Date.Time))
+	}
+	slices.Sort(gaps)
+	switch median := gaps[len(gaps)/2]; {
+	case median <= 3:
+		return StepDaily
+	case median <= 10:
+		return StepWeekly
+	case median <= 45:
+		return StepMonthly
+	case median <= 135:
+		return StepQuarterly
+	default:
+		return StepYearly
+	}
+}
+
+// daysBetween retourne le nombre de jours calendaires de from à to
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// dataQuality évalue l'historique d'un investissement jusqu'à end, à la périodicité step
+// (déduite de l'historique si vide)
+func (inv *Investment) dataQuality(step SeriesStep, end time.Time) (DataQuality, error) {
+	q := DataQuality{Investment: inv.Name, NAVs: len(inv.NAVHistory), Expected: step}
+	if q.Expected == "" {
+		q.Expected = inferNAVStep(inv.NAVHistory)
+	}
+	if q.NAVs == 0 {
+		return q, nil
+	}
+	if inv.Closed && !inv.ClosedDate.IsZero() {
+		end = inv.ClosedDate.Time
+	}
+	first, latest := inv.NAVHistory[0], inv.NAVHistory[q.NAVs-1]
+	end = later(end, latest.Date.Time)
+	q.Latest = latest.Date.Time
+	q.Staleness = daysBetween(latest.Date.Time, end)
+	for i := 1; i < q.NAVs; i++ {
+		prev, next := inv.NAVHistory[i-1].Date, inv.NAVHistory[i].Date
+		if gap := daysBetween(prev.Time, next.Time); gap > q.LongestGap {
+			q.LongestGap, q.GapStart, q.GapEnd = gap, prev.Time, next.Time
+		}
+	}
+
+	// Périodes [début, début+pas[ depuis la première NAV ; le week-end n'est pas attendu
+	// d'une série quotidienne
+	j := 0
+	for k := 0; ; k++ {
+		start, err := q.Expected.add(first.Date.Time, k)
+		if err != nil {
+			return q, err
+		}
+		if start.After(end) {
+			break
+		}
+		next, _ := q.Expected.add(first.Date.Time, k+1)
+		covered := false
+		for ; j < q.NAVs && inv.NAVHistory[j].Date.Before(next); j++ {
+			covered = true
+		}
+		if q.Expected == StepDaily && !covered && (start.Weekday() == time.Saturday || start.Weekday() == time.Sunday) {
+			continue
+		}
+		q.Periods++
+		if covered {
+			q.Covered++
+		}
+	}
+
+	freshness := 1.0
+	if q.Stale() {
+		freshness = max(0, 1-float64(q.Staleness-staleNAVDays)/365)
+	}
+	q.Score = q.Coverage() * freshness
+	return q, nil
+}
+
+// DataQuality évalue l'historique de NAV de chaque investissement, à la périodicité
+// step (déduite de chaque historique si vide)
+func (p *Portfolio) DataQuality(step SeriesStep) ([]DataQuality, error) {
+	if step != "" {
+		if _, err := step.add(time.Time{}, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	end := Today()
+	var report []DataQuality
+	for _, name := range p.sortedInvestmentNames() {
+		q, err := p.Investments[name].dataQuality(step, end)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, q)
+	}
+	return report, nil
+}
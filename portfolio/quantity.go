@@ -0,0 +1,84 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Quantity est un nombre de parts en virgule fixe exprimé en cent-millionièmes, la plus
+// petite subdivision d'un bitcoin (satoshi). Les achats et ventes de cryptoactifs se
+// cumulent ainsi sans erreur d'arrondi, quel que soit le nombre de décimales saisies.
+type Quantity int64
+
+// quantityDecimals est le nombre de décimales conservées par Quantity
+const (
+	quantityDecimals = 8
+	quantityScale    = 100000000
+)
+
+// NewQuantity convertit un flottant en Quantity, arrondi au cent-millionième le plus proche
+func NewQuantity(v float64) Quantity {
+	return Quantity(math.Round(v * quantityScale))
+}
+
+// ParseQuantity lit une quantité décimale ("0.00012345") sans passer par un flottant
+func ParseQuantity(s string) (Quantity, error) {
+	v, ok := parseFixed(s, quantityDecimals)
+	if !ok {
+		return 0, fmt.Errorf("quantité '%s' invalide", s)
+	}
+	return Quantity(v), nil
+}
+
+// Float64 retourne la quantité sous forme de flottant, pour les valorisations
+func (q Quantity) Float64() float64 {
+	return float64(q) / quantityScale
+}
+
+// String retourne la quantité en notation décimale, sans zéros superflus
+func (q Quantity) String() string {
+	sign := ""
+	abs := int64(q)
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	frac := strings.TrimRight(fmt.Sprintf("%0*d", quantityDecimals, abs%quantityScale), "0")
+	if frac == "" {
+		return fmt.Sprintf("%s%d", sign, abs/quantityScale)
+	}
+	return fmt.Sprintf("%s%d.%s", sign, abs/quantityScale, frac)
+}
+
+// MarshalJSON encode la quantité comme un nombre JSON décimal exact
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON accepte un nombre JSON ou une chaîne décimale
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "null" {
+		return nil
+	}
+
+	// Notation exponentielle : repli sur le flottant
+	if strings.ContainsAny(raw, "eE") {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("quantité '%s' invalide", raw)
+		}
+		*q = NewQuantity(v)
+		return nil
+	}
+
+	parsed, err := ParseQuantity(raw)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
@@ -0,0 +1,97 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+	}{
+		{in: "0.00012345", want: 12345},
+		{in: "1", want: 100000000},
+		{in: "0.000000005", want: 1},
+		{in: "0.000000004", want: 0},
+		{in: "-2.5", want: -250000000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseQuantity(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuantity(%q) = %d, %d attendu", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "1.2.3", "1e-8", "--1", "+-1"} {
+		if _, err := ParseQuantity(in); err == nil {
+			t.Errorf("ParseQuantity(%q): erreur attendue", in)
+		}
+	}
+}
+
+func TestQuantityString(t *testing.T) {
+	tests := []struct {
+		in   Quantity
+		want string
+	}{
+		{in: 0, want: "0"},
+		{in: NewQuantity(30), want: "30"},
+		{in: 1, want: "0.00000001"},
+		{in: NewQuantity(0.5), want: "0.5"},
+		{in: -12345, want: "-0.00012345"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Quantity(%d) = %s, %s attendu", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+// Des achats répétés de fractions de part se cumulent sans dérive
+func TestQuantityExactSums(t *testing.T) {
+	var sum Quantity
+	for range 100000 {
+		sum += NewQuantity(0.00001)
+	}
+	if sum != NewQuantity(1) {
+		t.Errorf("cent mille fois 0.00001 = %s, 1 attendu", sum)
+	}
+}
+
+func TestQuantityJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+	}{
+		{in: `0.12345678`, want: 12345678},
+		{in: `"0.5"`, want: 50000000},
+		{in: `1e-8`, want: 1},
+		{in: `"2E3"`, want: 200000000000},
+		{in: `null`, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var q Quantity
+			if err := json.Unmarshal([]byte(tt.in), &q); err != nil {
+				t.Fatal(err)
+			}
+			if q != tt.want {
+				t.Errorf("%s lu %d, %d attendu", tt.in, q, tt.want)
+			}
+			data, err := json.Marshal(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back Quantity
+			if err := json.Unmarshal(data, &back); err != nil || back != q {
+				t.Errorf("aller-retour %s: %d (%v), %d attendu", data, back, err, q)
+			}
+		})
+	}
+}
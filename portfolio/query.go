@@ -0,0 +1,330 @@
+package portfolio
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// QueryResult est un investissement retenu par Query, avec les indicateurs servant aux
+// filtres et aux tris
+type QueryResult struct {
+	Name     string
+	Currency Currency
+	Tags     map[string]string
+	Closed   bool
+	Watched  bool               // Titre de la liste de suivi, non détenu : valeur et part nulles
+	Value    float64            // Valeur (dernière NAV, sinon montant investi) en devise de consolidation
+	Invested float64            // Capital net investi, en devise de consolidation
+	Share    float64            // Part de la valeur dans celle du portefeuille (%)
+	Return   *float64           // Taux de performance annualisé (%), nil sans au moins deux NAV
+	Drawdown *float64           // Perte maximale (%, positive), nil sans au moins deux NAV
+	Metrics  map[string]float64 // Indicateurs personnalisés demandés, hors valeurs indéfinies
+
+	vars map[string]float64 // Variables des expressions (voir metricVariables)
+}
+
+// QueryResults est le résultat d'une requête, dans l'ordre du tri demandé
+type QueryResults []QueryResult
+
+// Names retourne les noms des investissements retenus
+func (r QueryResults) Names() []string {
+	names := make([]string, len(r))
+	for i, res := range r {
+		names[i] = res.Name
+	}
+	return names
+}
+
+// TotalValue retourne la valeur cumulée des investissements retenus
+func (r QueryResults) TotalValue() float64 {
+	var total Money
+	for _, res := range r {
+		total += NewMoney(res.Value)
+	}
+	return total.RoundCents().Float64()
+}
+
+// QueryFilter retient ou écarte un investissement ; les filtres d'une requête se cumulent
+type QueryFilter func(QueryResult) bool
+
+// WithTag retient les investissements dont l'étiquette a la valeur donnée (sans
+// distinction de casse)
+func WithTag(tag, value string) QueryFilter {
+	return func(r QueryResult) bool {
+		return strings.EqualFold(r.Tags[tag], value)
+	}
+}
+
+// WithAssetClass retient les investissements de la classe d'actifs donnée
+func WithAssetClass(class string) QueryFilter {
+	return WithTag(TagAssetClass, class)
+}
+
+// WithCurrency retient les investissements libellés dans l'une des devises données
+func WithCurrency(currencies ...Currency) QueryFilter {
+	return func(r QueryResult) bool {
+		for _, cur := range currencies {
+			if strings.EqualFold(string(r.Currency), string(cur)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithReturnBetween retient les investissements dont le taux de performance est compris
+// entre min et max (%) ; ceux sans taux sont écartés
+func WithReturnBetween(min, max float64) QueryFilter {
+	return func(r QueryResult) bool {
+		return r.Return != nil && *r.Return >= min && *r.Return <= max
+	}
+}
+
+// WithMaxDrawdown retient les investissements dont la perte maximale ne dépasse pas
+// depth (%) ; ceux sans historique suffisant sont écartés
+func WithMaxDrawdown(depth float64) QueryFilter {
+	return func(r QueryResult) bool {
+		return r.Drawdown != nil && *r.Drawdown <= depth
+	}
+}
+
+// WithShareBetween retient les investissements pesant entre min et max % du portefeuille
+func WithShareBetween(min, max float64) QueryFilter {
+	return func(r QueryResult) bool {
+		return r.Share >= min && r.Share <= max
+	}
+}
+
+// WithCondition retient les investissements pour lesquels l'expression (voir Metric)
+// est vraie, par exemple "return > 0.05 && share < 10" ; une valeur indéfinie écarte
+// l'investissement
+func WithCondition(cond Metric) QueryFilter {
+	return func(r QueryResult) bool {
+		v := cond.Eval(r.vars)
+		return !math.IsNaN(v) && v != 0
+	}
+}
+
+// WithMetricBetween retient les investissements dont l'indicateur personnalisé est
+// compris entre min et max ; ceux pour lesquels il est indéfini sont écartés
+func WithMetricBetween(name string, min, max float64) QueryFilter {
+	return func(r QueryResult) bool {
+		v, ok := r.Metrics[name]
+		return ok && v >= min && v <= max
+	}
+}
+
+// QuerySort est le critère de tri d'une requête : l'un des critères prédéfinis ou le
+// nom d'un indicateur personnalisé de la requête
+type QuerySort string
+
+const (
+	SortByName     QuerySort = "name"
+	SortByValue    QuerySort = "value"
+	SortByShare    QuerySort = "share"
+	SortByReturn   QuerySort = "return"
+	SortByDrawdown QuerySort = "drawdown"
+)
+
+// QueryOptions décrit une requête sur les investissements
+type QueryOptions struct {
+	Filters          []QueryFilter
+	Metrics          []Metric  // Indicateurs personnalisés calculés pour chaque investissement
+	Sort             QuerySort // SortByName si vide
+	Descending       bool
+	Limit            int  // Nombre maximal de résultats, 0 pour tous
+	IncludeClosed    bool // Retient aussi les investissements clôturés (valeur nulle)
+	IncludeWatchlist bool // Retient aussi les titres suivis non détenus (valeur nulle)
+}
+
+// Query retourne les investissements qui satisfont tous les filtres, triés selon le
+// critère demandé. Les parts sont calculées sur la valeur totale du portefeuille, avant
+// filtrage ; les investissements sans taux ou sans drawdown sont classés en dernier
+// quel que soit le sens du tri.
+func (p *Portfolio) Query(opts QueryOptions) (QueryResults, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.query(opts)
+}
+
+// query exécute la requête ; l'appelant détient le verrou
+func (p *Portfolio) query(opts QueryOptions) (QueryResults, error) {
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = SortByName
+	}
+	if err := ValidateMetrics(opts.Metrics); err != nil {
+		return nil, err
+	}
+	if !validQuerySort(sortKey, opts.Metrics) {
+		return nil, InvalidField("sort", string(sortKey), "critère de tri inconnu: %s", sortKey)
+	}
+
+	// Les titres suivis sont mesurés comme une part achetée à leur premier cours
+	type entry struct {
+		name    string
+		inv     *Investment
+		watched bool
+	}
+	var entries []entry
+	for _, name := range p.sortedInvestmentNames() {
+		entries = append(entries, entry{name, p.Investments[name], false})
+	}
+	if opts.IncludeWatchlist {
+		for _, name := range p.watchlistNames() {
+			inv := p.Watchlist[name].investment()
+			p.attach(inv)
+			entries = append(entries, entry{name, inv, true})
+		}
+	}
+
+	var all QueryResults
+	var total Money
+	for _, e := range entries {
+		name, inv := e.name, e.inv
+		r := QueryResult{Name: name, Currency: inv.EffectiveCurrency(), Tags: maps.Clone(inv.Tags), Closed: inv.Closed, Watched: e.watched}
+
+		r.vars = map[string]float64{"reference_rate": inv.ReferenceRate / 100}
+		date := inv.InvestmentDate
+		if latest, err := inv.GetLatestNAV(); err == nil {
+			date = latest.Date
+			if len(inv.NAVHistory) >= 2 {
+				if rate, err := inv.CalculatePerformanceRate(); err == nil {
+					r.Return = &rate
+					r.vars["return"] = rate / 100
+				}
+				if dd, err := inv.MaxDrawdown(); err == nil {
+					r.Drawdown = &dd.Depth
+					r.vars["drawdown"] = dd.Depth / 100
+				}
+			}
+		}
+		r.vars["years"] = date.Sub(inv.InvestmentDate.Time).Hours() / 24 / 365.25
+		if inv.Fees != nil {
+			r.vars["ter"] = inv.Fees.TER / 100
+		}
+		if len(inv.Transactions) > 0 {
+			if pos, err := inv.Position(); err == nil {
+				r.vars["units"] = pos.Units.Float64()
+			}
+		} else if inv.Quantity > 0 {
+			r.vars["units"] = inv.Quantity.Float64()
+		}
+
+		if !inv.Closed && !e.watched {
+			converted, _, err := p.investmentValueAt(inv, date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			invested, err := p.toBase(inv.NetInvested().Float64()*inv.Sign(), inv.Currency, date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			r.Value = NewMoney(converted).RoundCents().Float64()
+			r.Invested = NewMoney(invested).RoundCents().Float64()
+			total += NewMoney(converted)
+		}
+		r.vars["value"], r.vars["invested"] = r.Value, r.Invested
+		if len(inv.Distributions) > 0 {
+			if paid, _, err := inv.TotalDistributions("", ""); err == nil {
+				converted, err := p.toBase(paid.Float64(), inv.Currency, date.Time)
+				if err != nil {
+					return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+				}
+				r.vars["distributions"] = converted
+			}
+		} else {
+			r.vars["distributions"] = 0
+		}
+		all = append(all, r)
+	}
+
+	results := QueryResults{}
+	for _, r := range all {
+		if r.Closed && !opts.IncludeClosed {
+			continue
+		}
+		if total != 0 {
+			r.Share = r.Value / total.Float64() * 100
+			r.vars["share"] = r.Share / 100
+		}
+		r.Metrics = evalMetrics(opts.Metrics, r.vars)
+		if matchesQuery(r, opts.Filters) {
+			results = append(results, r)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return lessQueryResult(results[i], results[j], sortKey, opts.Descending)
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// validQuerySort indique si le critère de tri est prédéfini ou désigne un indicateur
+func validQuerySort(s QuerySort, metrics []Metric) bool {
+	switch s {
+	case SortByName, SortByValue, SortByShare, SortByReturn, SortByDrawdown:
+		return true
+	}
+	return slices.ContainsFunc(metrics, func(m Metric) bool { return m.Name == string(s) })
+}
+
+// matchesQuery indique si le résultat satisfait tous les filtres
+func matchesQuery(r QueryResult, filters []QueryFilter) bool {
+	for _, keep := range filters {
+		if !keep(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// lessQueryResult compare deux résultats selon le critère de tri ; les valeurs absentes
+// sont placées en dernier et les égalités départagées par nom
+func lessQueryResult(a, b QueryResult, key QuerySort, descending bool) bool {
+	var x, y *float64
+	switch key {
+	case SortByValue:
+		x, y = &a.Value, &b.Value
+	case SortByShare:
+		x, y = &a.Share, &b.Share
+	case SortByReturn:
+		x, y = a.Return, b.Return
+	case SortByDrawdown:
+		x, y = a.Drawdown, b.Drawdown
+	case SortByName:
+		if descending {
+			return a.Name > b.Name
+		}
+		return a.Name < b.Name
+	default:
+		if v, ok := a.Metrics[string(key)]; ok {
+			x = &v
+		}
+		if v, ok := b.Metrics[string(key)]; ok {
+			y = &v
+		}
+	}
+
+	switch {
+	case x == nil && y == nil:
+		return a.Name < b.Name
+	case x == nil:
+		return false
+	case y == nil:
+		return true
+	case *x == *y:
+		return a.Name < b.Name
+	case descending:
+		return *x > *y
+	default:
+		return *x < *y
+	}
+}
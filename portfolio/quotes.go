@@ -0,0 +1,231 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Quote est le dernier cours connu d'un titre
+type Quote struct {
+	Date     time.Time // Date de cotation
+	Price    float64   // Prix unitaire
+	Currency Currency  // Devise de cotation, vide si le fournisseur ne la précise pas
+}
+
+// QuoteProvider fournit le dernier cours d'un titre identifié par son ISIN ou son ticker
+type QuoteProvider interface {
+	Quote(ctx context.Context, identifier string) (Quote, error)
+}
+
+// RefreshResult est le résultat de la mise à jour d'un investissement
+type RefreshResult struct {
+	Name       string
+	Identifier string
+	NAV        NAV   // NAV enregistrée : cours × parts détenues, cours seul pour un titre suivi
+	Err        error // Cause de l'échec, nil si la NAV a été enregistrée
+}
+
+// SetQuoteProvider définit le fournisseur de cours utilisé par RefreshNAVs
+func (p *Portfolio) SetQuoteProvider(provider QuoteProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Quotes = provider
+	p.changed("set-quote-provider", "")
+}
+
+// SetIdentifier associe un ISIN ou un ticker à un investissement, vide pour le retirer
+func (p *Portfolio) SetIdentifier(name, identifier string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	inv.Identifier = identifier
+	p.record(OpSetIdentifiers, name, fmt.Sprintf("identifiant %q", identifier), before)
+	return nil
+}
+
+// units retourne le nombre de parts détenues : celui du registre de transactions s'il
+// existe, sinon la quantité saisie à la création
+func (inv *Investment) units() (float64, bool) {
+	if len(inv.Transactions) > 0 {
+		pos, err := inv.Position()
+		return pos.Units.Float64(), err == nil && pos.Units > 0
+	}
+	return inv.Quantity.Float64(), inv.Quantity > 0
+}
+
+// RefreshNAVs interroge le fournisseur de cours pour chaque investissement ouvert doté
+// d'un identifiant de cotation, d'un ticker ou d'un ISIN (voir quoteIdentifier) et
+// enregistre la NAV correspondante (cours × parts détenues) à la date de cotation ; une
+// NAV déjà présente à cette date est remplacée. Les cours sont obtenus hors verrou.
+// Les cours des titres suivis dotés d'un identifiant sont ensuite mis à jour de même.
+// L'erreur retournée regroupe les échecs individuels, détaillés dans les résultats.
+func (p *Portfolio) RefreshNAVs(ctx context.Context) ([]RefreshResult, error) {
+	type target struct {
+		name, identifier string
+		currency         Currency
+		units            float64
+		ok               bool
+	}
+
+	p.mu.RLock()
+	provider := p.Quotes
+	var targets []target
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		identifier := inv.quoteIdentifier()
+		if identifier == "" || inv.Closed {
+			continue
+		}
+		units, ok := inv.units()
+		targets = append(targets, target{name, identifier, inv.EffectiveCurrency(), units, ok})
+	}
+	p.mu.RUnlock()
+
+	if provider == nil {
+		return nil, fmt.Errorf("aucun fournisseur de cours configuré")
+	}
+
+	results := make([]RefreshResult, 0, len(targets))
+	var errs []error
+	for _, t := range targets {
+		result := RefreshResult{Name: t.name, Identifier: t.identifier}
+		result.NAV, result.Err = p.refreshNAV(ctx, provider, t.name, t.identifier, t.currency, t.units, t.ok)
+		if result.Err != nil {
+			Logger().Debug("mise à jour du cours impossible", "investment", t.name, "identifier", t.identifier, "error", result.Err)
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, result.Err))
+		} else {
+			Logger().Debug("cours mis à jour", "investment", t.name, "identifier", t.identifier,
+				"date", FormatDate(result.NAV.Date.Time), "nav", result.NAV.Value.Float64())
+		}
+		results = append(results, result)
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			return results, errors.Join(errs...)
+		}
+	}
+
+	for _, result := range p.refreshWatched(ctx, provider) {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Name, result.Err))
+		}
+		results = append(results, result)
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return results, errors.Join(errs...)
+}
+
+// refreshNAV obtient le cours d'un investissement et enregistre la NAV correspondante
+func (p *Portfolio) refreshNAV(ctx context.Context, provider QuoteProvider, name, identifier string, currency Currency, units float64, hasUnits bool) (NAV, error) {
+	if !hasUnits {
+		return NAV{}, fmt.Errorf("nombre de parts inconnu, impossible de valoriser le cours: %w", ErrInvalidAmount)
+	}
+	quote, err := provider.Quote(ctx, identifier)
+	if err != nil {
+		return NAV{}, err
+	}
+	if quote.Currency != "" && quote.Currency != currency {
+		return NAV{}, fmt.Errorf("cours en %s pour un investissement en %s", quote.Currency, currency)
+	}
+	if quote.Price <= 0 {
+		return NAV{}, fmt.Errorf("cours %.4f: %w", quote.Price, ErrInvalidAmount)
+	}
+	nav := NAV{Date: Date{quote.Date}, Value: NewMoney(quote.Price * units).RoundCents()}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return NAV{}, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	i, found := inv.navIndex(nav.Date.Time)
+	if found {
+		inv.NAVHistory[i].Value = nav.Value
+		inv.invalidate()
+		p.navsAdded(name, nav)
+		return nav, nil
+	}
+	inv.NAVHistory = slices.Insert(inv.NAVHistory, i, nav)
+	inv.invalidate()
+	p.navsAdded(name, nav)
+	return nav, nil
+}
+
+// YahooQuoteProvider interroge l'API de graphiques de Yahoo Finance par ticker
+// (ex. "AIR.PA", "CW8.PA"). Les ISIN ne sont pas pris en charge par cette API.
+type YahooQuoteProvider struct {
+	BaseURL string       // "https://query1.finance.yahoo.com" si vide
+	Client  *http.Client // http.DefaultClient si nil
+}
+
+// yahooChart est la partie utile de la réponse de l'API de graphiques
+type yahooChart struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				Currency           string  `json:"currency"`
+				RegularMarketPrice float64 `json:"regularMarketPrice"`
+				RegularMarketTime  int64   `json:"regularMarketTime"`
+			} `json:"meta"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// Quote retourne le dernier cours de marché du ticker
+func (y YahooQuoteProvider) Quote(ctx context.Context, identifier string) (Quote, error) {
+	base, client := y.BaseURL, y.Client
+	if base == "" {
+		base = "https://query1.finance.yahoo.com"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/v8/finance/chart/"+url.PathEscape(identifier), nil)
+	if err != nil {
+		return Quote{}, err
+	}
+	req.Header.Set("User-Agent", "david")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Quote{}, err
+	}
+	defer resp.Body.Close()
+
+	var chart yahooChart
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return Quote{}, fmt.Errorf("réponse illisible pour %s (HTTP %d): %w", identifier, resp.StatusCode, err)
+	}
+	if e := chart.Chart.Error; e != nil {
+		return Quote{}, fmt.Errorf("cours de %s: %s (%s)", identifier, e.Description, e.Code)
+	}
+	if resp.StatusCode != http.StatusOK || len(chart.Chart.Result) == 0 {
+		return Quote{}, fmt.Errorf("aucun cours pour %s (HTTP %d)", identifier, resp.StatusCode)
+	}
+
+	meta := chart.Chart.Result[0].Meta
+	t := time.Unix(meta.RegularMarketTime, 0).UTC()
+	return Quote{
+		Date:     time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC),
+		Price:    meta.RegularMarketPrice,
+		Currency: Currency(meta.Currency),
+	}, nil
+}
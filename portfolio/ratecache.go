@@ -0,0 +1,115 @@
+package portfolio
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// metricsCache mémorise les mesures dérivées de l'historique d'un investissement (taux de
+// performance, rendements entre NAV, volatilité, pire baisse), recalculées à chaque
+// projection sinon. Toute modification des NAV, des flux ou des distributions doit appeler
+// Investment.invalidate ; un changement de conventions de taux périme aussi les mesures.
+type metricsCache struct {
+	mu         sync.Mutex
+	generation uint64 // Incrémenté à chaque invalidation
+	entry      *derivedMetrics
+}
+
+// derivedMetrics sont les mesures mémorisées d'une génération de l'historique
+type derivedMetrics struct {
+	conventions analytics.RateConventions // Conventions de l'investissement lors des calculs
+	rate        memo[float64]
+	returns     memo[[]analytics.PeriodReturn]
+	volatility  memo[float64]
+	drawdown    memo[Drawdown]
+}
+
+// memo est le résultat mémorisé d'un calcul, erreur comprise
+type memo[T any] struct {
+	done  bool
+	value T
+	err   error
+}
+
+// newMetricsCache retourne un cache vide ; un investissement sans cache recalcule ses
+// mesures à chaque appel
+func newMetricsCache() *metricsCache {
+	return &metricsCache{}
+}
+
+// invalidate périme les mesures mémorisées de l'investissement
+func (inv *Investment) invalidate() {
+	c := inv.metrics
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.generation++
+	c.entry = nil
+	c.mu.Unlock()
+}
+
+// memoize retourne la mesure mémorisée sélectionnée par field, ou la calcule et la
+// mémorise. Le calcul se fait hors verrou : il peut lui-même lire d'autres mesures.
+func memoize[T any](inv *Investment, field func(*derivedMetrics) *memo[T], compute func() (T, error)) (T, error) {
+	c := inv.metrics
+	if c == nil {
+		return compute()
+	}
+	conv := inv.conventions
+
+	c.mu.Lock()
+	if c.entry != nil && c.entry.conventions == conv {
+		if m := field(c.entry); m.done {
+			c.mu.Unlock()
+			return m.value, m.err
+		}
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err := compute()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Une invalidation pendant le calcul rend le résultat obsolète
+	if c.generation == generation {
+		if c.entry == nil || c.entry.conventions != conv {
+			c.entry = &derivedMetrics{conventions: conv}
+		}
+		*field(c.entry) = memo[T]{done: true, value: value, err: err}
+	}
+	return value, err
+}
+
+// CalculatePerformanceRate calcule le taux annuel de performance basé sur les données réelles
+func (inv *Investment) CalculatePerformanceRate() (float64, error) {
+	return memoize(inv, func(m *derivedMetrics) *memo[float64] { return &m.rate }, inv.performanceRate)
+}
+
+// periodReturns retourne les rendements logarithmiques entre NAV successives, corrigés des
+// flux. Le résultat est partagé par les appelants et ne doit pas être modifié.
+func (inv *Investment) periodReturns() []analytics.PeriodReturn {
+	returns, _ := memoize(inv, func(m *derivedMetrics) *memo[[]analytics.PeriodReturn] { return &m.returns }, func() ([]analytics.PeriodReturn, error) {
+		// Capacité ajustée : un append de l'appelant ne peut pas écrire dans le tableau partagé
+		return slices.Clip(inv.computePeriodReturns()), nil
+	})
+	return returns
+}
+
+// volatility retourne la volatilité annualisée des rendements entre NAV successives
+func (inv *Investment) volatility() float64 {
+	volatility, _ := memoize(inv, func(m *derivedMetrics) *memo[float64] { return &m.volatility }, func() (float64, error) {
+		return analytics.AnnualVolatility(inv.periodReturns()), nil
+	})
+	return volatility
+}
+
+// MaxDrawdown retourne la pire baisse de l'investissement sur tout son historique de NAV
+func (inv *Investment) MaxDrawdown() (Drawdown, error) {
+	return memoize(inv, func(m *derivedMetrics) *memo[Drawdown] { return &m.drawdown }, func() (Drawdown, error) {
+		return maxDrawdown(inv.periodReturns())
+	})
+}
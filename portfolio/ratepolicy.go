@@ -0,0 +1,186 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+)
+
+// RateMode désigne la règle de choix du taux de projection
+type RateMode string
+
+const (
+	RateMin       RateMode = "min"       // Le plus défavorable du taux de référence et du taux réalisé (par défaut)
+	RateMax       RateMode = "max"       // Le plus favorable des deux
+	RateRealized  RateMode = "realized"  // Le taux réalisé seul
+	RateReference RateMode = "reference" // Le taux de référence seul
+	RateBlend     RateMode = "blend"     // Moyenne pondérée des deux
+	RateTrend     RateMode = "trend"     // Le taux de tendance ajusté sur toutes les NAV (voir TrendRate)
+)
+
+// RatePolicy détermine le taux annuel utilisé par les projections. Sans historique
+// suffisant pour calculer le taux réalisé, toutes les règles retiennent le taux de référence.
+type RatePolicy struct {
+	Mode           RateMode `json:"mode"`
+	RealizedWeight float64  `json:"realized_weight,omitempty"` // Poids du taux réalisé pour RateBlend (0 à 1)
+}
+
+// validate vérifie la cohérence de la règle
+func (rp RatePolicy) validate() error {
+	switch rp.Mode {
+	case RateMin, RateMax, RateRealized, RateReference, RateTrend:
+		return nil
+	case RateBlend:
+		if rp.RealizedWeight < 0 || rp.RealizedWeight > 1 || math.IsNaN(rp.RealizedWeight) {
+			return InvalidField("realized_weight", rp.RealizedWeight, "le poids du taux réalisé doit être compris entre 0 et 1")
+		}
+		return nil
+	default:
+		return InvalidField("mode", rp.Mode, "règle de taux inconnue: %s", rp.Mode)
+	}
+}
+
+// SetRatePolicy définit la règle de choix du taux de projection d'un investissement ;
+// nil rétablit la règle par défaut (RateMin)
+func (p *Portfolio) SetRatePolicy(investmentName string, policy *RatePolicy) error {
+	if policy != nil {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		copied := *policy
+		policy = &copied
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	inv.RatePolicy = policy
+	detail := "taux de référence"
+	if policy != nil {
+		detail = fmt.Sprintf("règle %s", policy.Mode)
+	}
+	p.record(OpSetRatePolicy, investmentName, detail, before)
+	return nil
+}
+
+// RateWithPolicy retourne le taux annuel (%) retenu par une règle donnée
+func (inv *Investment) RateWithPolicy(policy RatePolicy) (float64, error) {
+	if err := policy.validate(); err != nil {
+		return 0, err
+	}
+
+	rate, reason := inv.selectRate(policy)
+	if clamped := guardrails().clampRate(rate); clamped != rate {
+		rate, reason = clamped, fmt.Sprintf("%s, ramené à la borne de %.2f%%", reason, clamped)
+	}
+	Logger().Debug("taux de projection", "investment", inv.Name, "policy", policy.Mode,
+		"reference", inv.ReferenceRate, "rate", rate, "reason", reason)
+	return rate, nil
+}
+
+// selectRate applique une règle validée et indique pourquoi le taux a été retenu
+func (inv *Investment) selectRate(policy RatePolicy) (rate float64, reason string) {
+	if policy.Mode == RateTrend {
+		fit, err := inv.TrendRate()
+		if err != nil {
+			return inv.ReferenceRate, "tendance non calculable, taux de référence: " + err.Error()
+		}
+		return fit.Rate, fmt.Sprintf("tendance sur %d NAV (R² %.2f)", fit.Points, fit.RSquared)
+	}
+
+	realized, err := inv.CalculatePerformanceRate()
+	if err != nil {
+		// Historique insuffisant : le taux de référence fait foi
+		return inv.ReferenceRate, "taux réalisé non calculable, taux de référence: " + err.Error()
+	}
+
+	switch policy.Mode {
+	case RateMax:
+		if realized > inv.ReferenceRate {
+			return realized, fmt.Sprintf("taux réalisé %.2f%% supérieur au taux de référence", realized)
+		}
+		return inv.ReferenceRate, fmt.Sprintf("taux réalisé %.2f%% inférieur au taux de référence", realized)
+	case RateRealized:
+		return realized, "taux réalisé"
+	case RateReference:
+		return inv.ReferenceRate, fmt.Sprintf("taux de référence imposé (réalisé %.2f%%)", realized)
+	case RateBlend:
+		return policy.RealizedWeight*realized + (1-policy.RealizedWeight)*inv.ReferenceRate,
+			fmt.Sprintf("%.0f%% du taux réalisé %.2f%%", policy.RealizedWeight*100, realized)
+	default:
+		if realized < inv.ReferenceRate {
+			return realized, fmt.Sprintf("taux réalisé %.2f%% inférieur au taux de référence", realized)
+		}
+		return inv.ReferenceRate, fmt.Sprintf("taux réalisé %.2f%% supérieur au taux de référence", realized)
+	}
+}
+
+// ratePolicy retourne la règle de l'investissement, à défaut celle de la configuration,
+// RateMin sinon
+func (inv *Investment) ratePolicy() RatePolicy {
+	if inv.RatePolicy == nil {
+		if policy := ActiveConfig().RatePolicy; policy != nil {
+			return *policy
+		}
+		return RatePolicy{Mode: RateMin}
+	}
+	return *inv.RatePolicy
+}
+
+// ProjectNAVWithPolicy projette la valeur future à une date avec une règle de taux
+// imposée, indépendamment de celle de l'investissement
+func (inv *Investment) ProjectNAVWithPolicy(projectionDate string, policy RatePolicy) (float64, error) {
+	t, err := ParseDate(projectionDate)
+	if err != nil {
+		return 0, err
+	}
+	rate, err := inv.RateWithPolicy(policy)
+	if err != nil {
+		return 0, err
+	}
+	return inv.projectNAVAtRate(t, rate)
+}
+
+// ProjectionRate retourne le taux d'une règle facultative, la règle de l'investissement sinon
+func (inv *Investment) ProjectionRate(policy *RatePolicy) (float64, error) {
+	if inv.Cash != nil {
+		// Un compte rémunéré se projette à son taux en vigueur, quelle que soit la règle
+		rate := inv.Cash.effectiveRate(inv.Cash.rateAt(Today()))
+		Logger().Debug("taux de projection", "investment", inv.Name, "rate", rate, "reason", "taux du compte rémunéré")
+		return rate, nil
+	}
+	if inv.Bond != nil {
+		// Une obligation détenue jusqu'à l'échéance rapporte son taux actuariel d'achat
+		Logger().Debug("taux de projection", "investment", inv.Name, "rate", inv.Bond.Yield, "reason", "taux actuariel de l'obligation")
+		return inv.Bond.Yield, nil
+	}
+	if inv.Exposure == ExposureSigned {
+		// La valeur d'un dérivé n'a pas de rendement : elle est reconduite telle quelle
+		return 0, nil
+	}
+	if policy == nil {
+		return inv.EffectiveRate()
+	}
+	return inv.RateWithPolicy(*policy)
+}
+
+// GetPortfolioValueWithPolicy calcule la valeur du portefeuille à une date en appliquant
+// la même règle de taux à tous les investissements
+func (p *Portfolio) GetPortfolioValueWithPolicy(date string, policy RatePolicy) (map[string]float64, float64, error) {
+	if err := policy.validate(); err != nil {
+		return nil, 0, err
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.portfolioValueWithPolicy(t, &policy)
+}
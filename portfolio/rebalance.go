@@ -0,0 +1,121 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TargetAllocation décrit la répartition cible du portefeuille par investissement
+type TargetAllocation struct {
+	Weights  map[string]float64 `json:"weights"`              // Poids cible de chaque investissement (%), de somme 100
+	MinTrade Money              `json:"min_trade,omitempty"`  // Montant en dessous duquel aucun arbitrage n'est proposé
+	Glide    *GlidePath         `json:"glide_path,omitempty"` // Trajectoire de la part dynamique (voir weightsAt)
+}
+
+// RebalanceTrade est l'arbitrage proposé pour un investissement
+type RebalanceTrade struct {
+	Name          string  `json:"name"`
+	CurrentValue  float64 `json:"current_value"`  // Valeur à la date du plan (devise de base)
+	TargetValue   float64 `json:"target_value"`   // Valeur correspondant au poids cible
+	CurrentWeight float64 `json:"current_weight"` // Poids actuel (%)
+	TargetWeight  float64 `json:"target_weight"`  // Poids cible (%)
+	Drift         float64 `json:"drift"`          // Écart au poids cible, en points de pourcentage
+	Amount        float64 `json:"amount"`         // Montant à acheter (>0) ou à vendre (<0), 0 sous le seuil
+}
+
+// weightSumTolerance absorbe les arrondis de saisie des poids cibles (en points de %)
+const weightSumTolerance = 0.01
+
+// SetTargetAllocation définit la répartition cible (poids en %, de somme 100) et le
+// montant minimal d'un arbitrage ; la trajectoire existante est conservée. Une map vide
+// supprime la cible.
+func (p *Portfolio) SetTargetAllocation(weights map[string]float64, minTrade float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("target_allocation")
+	if err != nil {
+		return err
+	}
+
+	if len(weights) == 0 {
+		p.TargetAllocation = nil
+		return p.recordChange(JournalEntry{Op: OpSetTarget, Detail: "aucune cible", BeforeSettings: before})
+	}
+	if minTrade < 0 {
+		return fmt.Errorf("le montant minimal d'arbitrage ne peut pas être négatif: %w", ErrInvalidAmount)
+	}
+
+	var sum float64
+	copied := make(map[string]float64, len(weights))
+	for name, weight := range weights {
+		if _, exists := p.Investments[name]; !exists {
+			return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+		}
+		if weight < 0 {
+			return fmt.Errorf("poids négatif pour %s: %w", name, ErrInvalidAmount)
+		}
+		copied[name] = weight
+		sum += weight
+	}
+	if math.Abs(sum-100) > weightSumTolerance {
+		return fmt.Errorf("la somme des poids cibles vaut %.2f%% au lieu de 100%%: %w", sum, ErrInvalidAmount)
+	}
+
+	var glide *GlidePath
+	if p.TargetAllocation != nil {
+		glide = p.TargetAllocation.Glide
+	}
+	p.TargetAllocation = &TargetAllocation{Weights: copied, MinTrade: NewMoney(minTrade), Glide: glide}
+	return p.recordChange(JournalEntry{Op: OpSetTarget, Detail: fmt.Sprintf("%d investissements", len(copied)), BeforeSettings: before})
+}
+
+// RebalancePlan compare la répartition à une date avec la cible et propose, pour chaque
+// investissement ouvert, le montant à acheter ou vendre pour revenir au poids cible.
+// Les investissements absents de la cible ont un poids cible nul ; avec une trajectoire,
+// les poids cibles sont ceux de la date du plan. Les arbitrages
+// inférieurs au seuil MinTrade sont ramenés à zéro. Le plan est trié par nom.
+func (p *Portfolio) RebalancePlan(date string) ([]RebalanceTrade, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.TargetAllocation == nil {
+		return nil, fmt.Errorf("aucune allocation cible définie")
+	}
+	values, totalValue, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, err
+	}
+	if totalValue == 0 {
+		return nil, fmt.Errorf("la valeur totale du portefeuille est nulle")
+	}
+
+	minTrade := p.TargetAllocation.MinTrade.Float64()
+	weights := p.TargetAllocation.weightsAt(t)
+	plan := make([]RebalanceTrade, 0, len(values))
+	for name, value := range values {
+		targetWeight := weights[name]
+		targetValue := totalValue * targetWeight / 100
+		trade := RebalanceTrade{
+			Name:          name,
+			CurrentValue:  value,
+			TargetValue:   NewMoney(targetValue).RoundCents().Float64(),
+			CurrentWeight: value / totalValue * 100,
+			TargetWeight:  targetWeight,
+		}
+		trade.Drift = trade.CurrentWeight - targetWeight
+		if amount := targetValue - value; math.Abs(amount) >= minTrade {
+			trade.Amount = NewMoney(amount).RoundCents().Float64()
+		}
+		plan = append(plan, trade)
+	}
+
+	sort.Slice(plan, func(i, j int) bool { return plan[i].Name < plan[j].Name })
+	return plan, nil
+}
@@ -0,0 +1,163 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RecurringPlan est un ordre de versement programmé sur un investissement. Contrairement
+// au plan de l'investissement, simple hypothèse de projection, il est exécuté : chaque
+// échéance atteinte devient un apport enregistré (MaterializePlans, appelé par watch et
+// serve), et Start avance à la prochaine échéance ; seules les échéances restantes
+// alimentent les projections.
+type RecurringPlan struct {
+	Name       string `json:"-"`          // Nom du plan (clé de Portfolio.RecurringPlans)
+	Investment string `json:"investment"` // Investissement alimenté
+	ContributionPlan
+}
+
+// recurringPlanAlias permet de lire RecurringPlan sans rappeler sa propre méthode UnmarshalJSON
+type recurringPlanAlias RecurringPlan
+
+// UnmarshalJSON lit un plan récurrent ; les fichiers antérieurs à son rapprochement de
+// ContributionPlan enregistraient la prochaine échéance sous next
+func (r *RecurringPlan) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		recurringPlanAlias
+		Next Date `json:"next,omitzero"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = RecurringPlan(raw.recurringPlanAlias)
+	if r.Start.IsZero() {
+		r.Start = raw.Next
+	}
+	return nil
+}
+
+// MaterializedFlow est un versement enregistré par MaterializePlans
+type MaterializedFlow struct {
+	Plan       string
+	Investment string
+	Date       time.Time
+	Amount     Money
+}
+
+// linkRecurringPlans rattache chaque plan à son investissement pour les projections.
+// Appelé sous verrou d'écriture.
+func (p *Portfolio) linkRecurringPlans() {
+	for _, inv := range p.Investments {
+		if inv != nil {
+			inv.recurring = nil
+		}
+	}
+	for name, r := range p.RecurringPlans {
+		r.Name = name
+		if inv := p.Investments[r.Investment]; inv != nil {
+			inv.recurring = append(inv.recurring, r)
+		}
+	}
+}
+
+// AddRecurringPlan enregistre un plan de versements dont la première échéance est next ;
+// end peut être vide pour un plan sans fin
+func (p *Portfolio) AddRecurringPlan(name, investment string, amount float64, frequency SeriesStep, next, end string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("le nom du plan ne peut pas être vide")
+	}
+	plan, err := newContributionPlan(amount, frequency, next, end)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Investments[investment]; !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investment, ErrInvestmentNotFound)
+	}
+	if _, exists := p.RecurringPlans[name]; exists {
+		return fmt.Errorf("un plan '%s' existe déjà: %w", name, ErrAlreadyExists)
+	}
+	if p.RecurringPlans == nil {
+		p.RecurringPlans = make(map[string]*RecurringPlan)
+	}
+	p.RecurringPlans[name] = &RecurringPlan{Name: name, Investment: investment, ContributionPlan: plan}
+	p.linkRecurringPlans()
+	p.changed("add-recurring-plan", investment)
+	return nil
+}
+
+// RemoveRecurringPlan supprime un plan ; les versements déjà enregistrés sont conservés
+func (p *Portfolio) RemoveRecurringPlan(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.RecurringPlans[name]; !exists {
+		return fmt.Errorf("le plan '%s' n'existe pas: %w", name, ErrNotFound)
+	}
+	delete(p.RecurringPlans, name)
+	p.linkRecurringPlans()
+	p.changed("remove-recurring-plan", "")
+	return nil
+}
+
+// recurringNames retourne les noms des plans, triés
+func (p *Portfolio) recurringNames() []string {
+	names := make([]string, 0, len(p.RecurringPlans))
+	for name := range p.RecurringPlans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RecurringPlanNames retourne les noms des plans récurrents, triés
+func (p *Portfolio) RecurringPlanNames() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.recurringNames()
+}
+
+// MaterializePlans enregistre comme apports les échéances des plans atteintes à until et
+// retourne celles enregistrées. La prochaine échéance d'un plan n'avance qu'après
+// l'enregistrement de chaque apport : après une erreur, les échéances restantes le seront
+// à l'appel suivant. Les plans d'un investissement soldé ou supprimé sont ignorés.
+func (p *Portfolio) MaterializePlans(until time.Time) ([]MaterializedFlow, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var done []MaterializedFlow
+	for _, name := range p.recurringNames() {
+		r := p.RecurringPlans[name]
+		inv := p.Investments[r.Investment]
+		if inv == nil || inv.Closed {
+			continue
+		}
+		start := r.Start
+		for i, date := range r.schedule(time.Time{}, until, p.Calendar) {
+			if r.Amount <= 0 {
+				return done, fmt.Errorf("plan %s: le montant des versements doit être positif: %w", name, ErrInvalidAmount)
+			}
+			// Chaque échéance est calculée depuis la première, comme dans schedule, pour
+			// que les fins de mois ne dérivent pas
+			next, err := r.Frequency.add(start.Time, i+1)
+			if err != nil {
+				return done, fmt.Errorf("plan %s: %w", name, err)
+			}
+
+			before := inv.clone()
+			inv.addCashFlow(CashFlow{Date: Date{date}, Amount: r.Amount, Type: Contribution})
+			p.record(OpAddCashFlow, r.Investment, fmt.Sprintf("%s %.2f au %s (plan %s)", Contribution, r.Amount.Float64(), FormatDate(date), name), before)
+			p.valueChanged(r.Investment)
+			r.Start = Date{next}
+			done = append(done, MaterializedFlow{Plan: name, Investment: r.Investment, Date: date, Amount: r.Amount})
+		}
+	}
+	return done, nil
+}
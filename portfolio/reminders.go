@@ -0,0 +1,163 @@
+package portfolio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReminderKind est la nature d'un rappel
+type ReminderKind string
+
+const (
+	ReminderRebalance  ReminderKind = "rebalance"   // Revue trimestrielle de la répartition cible
+	ReminderTaxHorizon ReminderKind = "tax_horizon" // Échéance fiscale d'une enveloppe (PEA 5 ans, assurance-vie 8 ans)
+	ReminderMaturity   ReminderKind = "maturity"    // Remboursement d'une obligation
+	ReminderVesting    ReminderKind = "vesting"     // Acquisition de titres attribués
+	ReminderUnlock     ReminderKind = "unlock"      // Fin du blocage d'un investissement
+)
+
+// Reminder est une date de revue tirée des données du portefeuille
+type Reminder struct {
+	Date        Date         `json:"date"`
+	Kind        ReminderKind `json:"kind"`
+	Investment  string       `json:"investment,omitempty"` // Investissement concerné, vide pour le portefeuille ou le compte
+	Summary     string       `json:"summary"`
+	Description string       `json:"description,omitempty"`
+}
+
+// Reminders retourne les rappels datés de [from, to], triés par date : revue de la
+// répartition le premier jour ouvré de chaque trimestre si une cible est définie,
+// échéances fiscales des PEA et assurances-vie, remboursements d'obligations,
+// acquisitions de titres attribués et fins de blocage des investissements ouverts
+func (p *Portfolio) Reminders(from, to time.Time) ([]Reminder, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("la fin de la période doit être après son début: %w", ErrInvalidDate)
+	}
+	inRange := func(t time.Time) bool { return !t.Before(from) && !t.After(to) }
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	reminders := []Reminder{}
+	if p.TargetAllocation != nil {
+		quarter := time.Date(from.Year(), (from.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		for ; !quarter.After(to); quarter = quarter.AddDate(0, 3, 0) {
+			if date := p.Calendar.Roll(quarter); inRange(date) {
+				reminders = append(reminders, Reminder{Date: Date{date}, Kind: ReminderRebalance,
+					Summary:     "Revue trimestrielle de la répartition",
+					Description: "Comparer la répartition à la cible (commande rebalance)"})
+			}
+		}
+	}
+
+	// Une enveloppe de compte est partagée : son échéance n'est rappelée qu'une fois
+	accountHorizons := make(map[time.Time]bool)
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed {
+			continue
+		}
+
+		wrapper, opened := p.taxWrapper(inv)
+		years := map[TaxWrapper]int{TaxWrapperPEA: peaTaxFreeYears, TaxWrapperLifeInsurance: lifeInsuranceTaxYears}[wrapper]
+		if date := opened.AddDate(years, 0, 0); years > 0 && inRange(date) {
+			r := Reminder{Date: Date{date}, Kind: ReminderTaxHorizon, Investment: name,
+				Summary:     fmt.Sprintf("%s : %d ans", wrapper, years),
+				Description: fmt.Sprintf("L'enveloppe %s de %s atteint %d ans : fiscalité des retraits allégée", wrapper, name, years)}
+			if inv.TaxWrapper == "" {
+				if accountHorizons[date] {
+					continue
+				}
+				accountHorizons[date] = true
+				r.Investment = ""
+				r.Description = fmt.Sprintf("L'enveloppe %s du compte atteint %d ans : fiscalité des retraits allégée", wrapper, years)
+			}
+			reminders = append(reminders, r)
+		}
+
+		if inv.Bond != nil && inRange(inv.Bond.Maturity.Time) {
+			reminders = append(reminders, Reminder{Date: inv.Bond.Maturity, Kind: ReminderMaturity, Investment: name,
+				Summary:     fmt.Sprintf("Échéance de %s", name),
+				Description: fmt.Sprintf("Remboursement de %s au pair : prévoir le réemploi", name)})
+		}
+		if inv.Vesting != nil {
+			for _, date := range inv.Vesting.events() {
+				if inRange(date) {
+					reminders = append(reminders, Reminder{Date: Date{date}, Kind: ReminderVesting, Investment: name,
+						Summary:     fmt.Sprintf("Acquisition de %s", name),
+						Description: fmt.Sprintf("%.0f %% de %s acquis", inv.Vesting.fractionAt(date)*100, name)})
+				}
+			}
+		}
+		if l := inv.liquidity(); l != nil && l.Tier == LiquidityLocked && inRange(l.LockedUntil.Time) {
+			reminders = append(reminders, Reminder{Date: l.LockedUntil, Kind: ReminderUnlock, Investment: name,
+				Summary:     fmt.Sprintf("Fin du blocage de %s", name),
+				Description: fmt.Sprintf("%s devient disponible", name)})
+		}
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].Date.Before(reminders[j].Date.Time) })
+	return reminders, nil
+}
+
+// WriteICS écrit les rappels au format iCalendar (RFC 5545), un événement d'une journée
+// par rappel, importable dans un agenda ou servi comme abonnement. Les identifiants des
+// événements sont stables d'un export à l'autre.
+func WriteICS(w io.Writer, reminders []Reminder, stamp time.Time) error {
+	b := bufio.NewWriter(w)
+	line := func(s string) {
+		// Lignes d'au plus 75 octets, repliées par une espace en début de ligne suivante
+		for len(s) > 75 {
+			cut := 75
+			for cut > 0 && s[cut]&0xC0 == 0x80 {
+				cut--
+			}
+			b.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//david//rappels//FR")
+	line("CALSCALE:GREGORIAN")
+	for _, r := range reminders {
+		uid := fmt.Sprintf("%s-%s-%s@david", r.Kind, r.Date.Format("20060102"), icsUID(r.Investment))
+		line("BEGIN:VEVENT")
+		line("UID:" + uid)
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + r.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + r.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icsEscape(r.Summary))
+		if r.Description != "" {
+			line("DESCRIPTION:" + icsEscape(r.Description))
+		}
+		line("CATEGORIES:" + string(r.Kind))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.Flush()
+}
+
+// icsEscape protège un texte iCalendar
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsUID réduit un nom d'investissement aux caractères sûrs d'un identifiant
+func icsUID(name string) string {
+	if name == "" {
+		return "portfolio"
+	}
+	return strings.Map(func(r rune) rune {
+		if r < 128 && (r == '-' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,76 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// SetRiskFreeRate définit le taux sans risque annuel (%) utilisé par RiskReport ; un
+// taux non fini, qui ne pourrait être enregistré, est ignoré
+func (p *Portfolio) SetRiskFreeRate(rate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("risk_free_rate")
+	if err != nil {
+		return
+	}
+	p.RiskFreeRate = rate
+	// Un taux non fini n'est pas sérialisable : recordChange rétablit alors le précédent
+	_ = p.recordChange(JournalEntry{Op: OpSetRiskFreeRate, Detail: fmt.Sprintf("%.2f%%", rate), BeforeSettings: before})
+}
+
+// RiskMetrics calcule volatilité, ratio de Sharpe et ratio de Sortino de l'investissement
+// à partir des rendements entre NAV successives, pour un taux sans risque annuel (%)
+func (inv *Investment) RiskMetrics(riskFreeRate float64) (analytics.RiskMetrics, error) {
+	return analytics.Risk(inv.conventions, inv.periodReturns(), riskFreeRate)
+}
+
+// RiskReport calcule les mesures de risque de chaque investissement ouvert et de
+// l'ensemble du portefeuille, au taux sans risque du portefeuille. Les investissements
+// associés à un indice sont complétés de leur tracking error et de leur ratio
+// d'information. Les rendements du portefeuille sont mesurés sur la grille commune de la
+// configuration (voir Alignment).
+func (p *Portfolio) RiskReport() (map[string]analytics.RiskMetrics, analytics.RiskMetrics, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	report := make(map[string]analytics.RiskMetrics)
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		metrics, err := inv.RiskMetrics(p.RiskFreeRate)
+		if err != nil {
+			return nil, analytics.RiskMetrics{}, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		if inv.Benchmark != "" {
+			b, exists := p.Benchmarks[inv.Benchmark]
+			if !exists {
+				return nil, analytics.RiskMetrics{}, fmt.Errorf("l'indice '%s' de %s n'existe pas: %w", inv.Benchmark, name, ErrNotFound)
+			}
+			comparison, err := inv.CompareToBenchmark(b)
+			switch {
+			case err == nil:
+				metrics.Benchmark = b.Name
+				metrics.TrackingError, metrics.InformationRatio = &comparison.TrackingError, &comparison.InfoRatio
+			case !errors.Is(err, ErrInsufficientHistory):
+				return nil, analytics.RiskMetrics{}, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+		}
+		report[name] = metrics
+	}
+
+	returns, err := p.alignedReturns(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, analytics.RiskMetrics{}, err
+	}
+	total, err := analytics.Risk(p.rateConventions(), returns, p.RiskFreeRate)
+	if err != nil {
+		return nil, analytics.RiskMetrics{}, err
+	}
+	return report, total, nil
+}
@@ -0,0 +1,102 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// RiskContribution est la part d'un investissement dans la volatilité du portefeuille
+type RiskContribution struct {
+	Name       string  `json:"name"`
+	Weight     float64 `json:"weight"`     // Part de la valeur actuelle du portefeuille (%)
+	Volatility float64 `json:"volatility"` // Volatilité annualisée propre (%)
+	Marginal   float64 `json:"marginal"`   // Hausse de la volatilité du portefeuille par point de poids supplémentaire (%)
+	Component  float64 `json:"component"`  // Poids × contribution marginale : les contributions somment à la volatilité (%)
+	Share      float64 `json:"share"`      // Part de la volatilité du portefeuille (%), négative pour une couverture
+}
+
+// RiskDecomposition décompose la volatilité du portefeuille par investissement
+type RiskDecomposition struct {
+	Volatility    float64            `json:"volatility"`   // Volatilité annualisée du portefeuille (%)
+	Observations  int                `json:"observations"` // Rendements communs utilisés
+	Contributions []RiskContribution `json:"contributions"`
+}
+
+// RiskDecomposition calcule la contribution de chaque investissement ouvert à la
+// volatilité du portefeuille sur la période [from, to] (bornes vides : non bornée). Les
+// rendements (corrigés des flux) sont ramenés sur la grille de la configuration (voir
+// Alignment) couvrant la période commune à tous les historiques, et leur matrice de
+// covariance Σ annualisée comme dans RiskMetrics. Pour les poids w de la valeur actuelle,
+// la volatilité du portefeuille est σ = √(wᵀΣw), la contribution marginale de i est
+// (Σw)ᵢ/σ et sa contribution wᵢ(Σw)ᵢ/σ ; les contributions somment à σ.
+func (p *Portfolio) RiskDecomposition(from, to string) (*RiskDecomposition, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var names []string
+	var indexes []timeseries.Series[float64]
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		if inv.Closed {
+			continue
+		}
+		names = append(names, name)
+		indexes = append(indexes, analytics.PerformanceIndex(inv.periodReturns()))
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("aucun investissement ouvert")
+	}
+
+	aligned, err := ConfiguredAlignment().alignSeries(indexes, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(aligned[0]) < minCorrelationObservations+1 {
+		return nil, fmt.Errorf("au moins %d rendements communs sont nécessaires: %w", minCorrelationObservations, ErrInsufficientHistory)
+	}
+	covariance, observations := analytics.AnnualCovariance(p.rateConventions(), aligned)
+
+	values, _, err := p.portfolioValue(Today())
+	if err != nil {
+		return nil, err
+	}
+	var total float64
+	weights := make([]float64, len(names))
+	for i, name := range names {
+		weights[i] = values[name]
+		total += weights[i]
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("la valeur totale du portefeuille est nulle")
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+
+	volatility, marginal, component := analytics.RiskContributions(covariance, weights)
+	d := &RiskDecomposition{Volatility: volatility * 100, Observations: observations}
+	for i, name := range names {
+		c := RiskContribution{
+			Name:       name,
+			Weight:     weights[i] * 100,
+			Volatility: math.Sqrt(covariance[i][i]) * 100,
+			Marginal:   marginal[i] * 100,
+			Component:  component[i] * 100,
+		}
+		if volatility > 0 {
+			c.Share = component[i] / volatility * 100
+		}
+		d.Contributions = append(d.Contributions, c)
+	}
+	sort.SliceStable(d.Contributions, func(i, j int) bool { return d.Contributions[i].Component > d.Contributions[j].Component })
+	return d, nil
+}
@@ -0,0 +1,38 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// RollingReturn est le rendement annualisé d'une fenêtre glissante
+type RollingReturn struct {
+	Start  Date    `json:"start"`
+	End    Date    `json:"end"`
+	Return float64 `json:"return"` // Rendement annualisé (%) corrigé des flux
+}
+
+// RollingReturns calcule le rendement annualisé sur des fenêtres glissantes de durée
+// window, décalées de step, de la première à la dernière NAV. Les rendements sont
+// corrigés des flux et l'indice est interpolé entre deux NAV.
+func (inv *Investment) RollingReturns(window time.Duration, step time.Duration) ([]RollingReturn, error) {
+	if window <= 0 || step <= 0 {
+		return nil, InvalidField("window", window, "la fenêtre et le pas doivent être positifs")
+	}
+	index := analytics.PerformanceIndex(inv.periodReturns())
+	if len(index) < 2 {
+		return nil, fmt.Errorf("au moins 2 NAV sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	if index.First().Add(window).After(index.Last()) {
+		return nil, fmt.Errorf("l'historique est plus court que la fenêtre: %w", ErrInsufficientHistory)
+	}
+
+	return timeseries.Rolling(index, window, step, func(start, end time.Time) RollingReturn {
+		growth, _ := timeseries.Change(index, start, end)
+		return RollingReturn{Start: Date{start}, End: Date{end}, Return: inv.conventions.RateFromLog(growth / inv.conventions.YearsBetween(start, end))}
+	}), nil
+}
@@ -0,0 +1,143 @@
+package portfolio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScenarioRule ajuste la projection des investissements qu'elle cible : un choc
+// instantané appliqué à la dernière NAV, puis éventuellement un taux annuel imposé.
+// Une règle cible un investissement, une classe d'actifs (étiquette TagAssetClass),
+// ou tout le portefeuille si les deux sont vides.
+type ScenarioRule struct {
+	Investment string   `json:"investment,omitempty"`
+	AssetClass string   `json:"asset_class,omitempty"`
+	Shock      float64  `json:"shock,omitempty"` // Variation immédiate de valeur (%), ex. -30
+	Rate       *float64 `json:"rate,omitempty"`  // Taux annuel (%) après le choc ; taux effectif si absent
+}
+
+// Scenario est un jeu nommé de règles de projection (ex. "bear", "base", "bull")
+type Scenario struct {
+	Name  string         `json:"name"`
+	Rules []ScenarioRule `json:"rules"`
+}
+
+// ruleFor retourne la règle la plus spécifique applicable à un investissement :
+// règle nominative, puis de classe d'actifs, puis générale
+func (s *Scenario) ruleFor(inv *Investment) (ScenarioRule, bool) {
+	var byClass, general *ScenarioRule
+	for i := range s.Rules {
+		rule := &s.Rules[i]
+		switch {
+		case rule.Investment != "":
+			if rule.Investment == inv.Name {
+				return *rule, true
+			}
+		case rule.AssetClass != "":
+			if byClass == nil && rule.AssetClass == inv.Tags[TagAssetClass] {
+				byClass = rule
+			}
+		default:
+			if general == nil {
+				general = rule
+			}
+		}
+	}
+	if byClass != nil {
+		return *byClass, true
+	}
+	if general != nil {
+		return *general, true
+	}
+	return ScenarioRule{}, false
+}
+
+// SetScenario enregistre un scénario, en remplaçant celui de même nom
+func (p *Portfolio) SetScenario(s Scenario) error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("le nom du scénario ne peut pas être vide")
+	}
+	for _, rule := range s.Rules {
+		if rule.Shock <= -100 {
+			return fmt.Errorf("un choc de %.2f%% ferait disparaître la valeur: %w", rule.Shock, ErrInvalidAmount)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("scenarios")
+	if err != nil {
+		return err
+	}
+
+	if p.Scenarios == nil {
+		p.Scenarios = make(map[string]*Scenario)
+	}
+	s.Rules = append([]ScenarioRule(nil), s.Rules...)
+	p.Scenarios[s.Name] = &s
+	return p.recordChange(JournalEntry{Op: OpSetScenario, Detail: s.Name, BeforeSettings: before})
+}
+
+// RemoveScenario supprime un scénario
+func (p *Portfolio) RemoveScenario(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Scenarios[name]; !exists {
+		return fmt.Errorf("le scénario '%s' n'existe pas: %w", name, ErrNotFound)
+	}
+	delete(p.Scenarios, name)
+	p.changed("remove-scenario", "")
+	return nil
+}
+
+// ProjectScenario projette chaque investissement ouvert à une date selon un scénario
+// et retourne les valeurs (devise de consolidation) et leur total. Les investissements
+// qu'aucune règle ne cible sont projetés normalement.
+func (p *Portfolio) ProjectScenario(name string, date string) (map[string]float64, float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	scenario, exists := p.Scenarios[name]
+	if !exists {
+		return nil, 0, fmt.Errorf("le scénario '%s' n'existe pas: %w", name, ErrNotFound)
+	}
+
+	values := make(map[string]float64)
+	var totalValue Money
+	for invName, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+
+		rule, _ := scenario.ruleFor(inv)
+		rate, err := inv.EffectiveRate()
+		if err != nil {
+			return nil, 0, fmt.Errorf("erreur pour %s: %w", invName, err)
+		}
+		if rule.Rate != nil {
+			rate = *rule.Rate
+		}
+		value, err := inv.projectNAVAtRate(t, rate)
+		if err != nil {
+			return nil, 0, fmt.Errorf("erreur pour %s: %w", invName, err)
+		}
+		value *= 1 + rule.Shock/100
+
+		value, err = p.toBase(value, inv.Currency, t)
+		if err != nil {
+			return nil, 0, fmt.Errorf("erreur pour %s: %w", invName, err)
+		}
+		rounded := NewMoney(value).RoundCents()
+		values[invName] = rounded.Float64()
+		totalValue += rounded
+	}
+
+	return values, totalValue.Float64(), nil
+}
@@ -0,0 +1,145 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Redemption est un rachat partiel d'un investissement suivi en montant, sans registre
+// de parts : le prix de revient sorti est proportionnel à la part de la valeur rachetée
+type Redemption struct {
+	Date      Date  `json:"date"`       // Date du rachat
+	Amount    Money `json:"amount"`     // Montant versé, frais déduits
+	CostBasis Money `json:"cost_basis"` // Prix de revient sorti
+	Gain      Money `json:"gain"`       // Plus-value réalisée, nette de frais
+}
+
+// Sale décrit une vente enregistrée par Sell
+type Sale struct {
+	Investment string
+	Date       time.Time
+	Units      Quantity // Parts vendues, nul pour un investissement suivi en montant
+	Amount     Money    // Produit de la vente, frais déduits
+	CostBasis  Money    // Prix de revient sorti
+	Gain       Money    // Plus-value réalisée
+}
+
+// costBasisAt retourne le prix de revient d'un investissement suivi en montant à une
+// date : montant initial et apports, moins le prix de revient sorti par les rachats.
+// Un retrait saisi sans rachat (AddCashFlow) est un remboursement de capital.
+func (inv *Investment) costBasisAt(date time.Time) Money {
+	cost := inv.AmountInvested
+	redeemed := make(map[CashFlow]int)
+	for _, r := range inv.Redemptions {
+		if !r.Date.After(date) {
+			cost -= r.CostBasis
+			redeemed[CashFlow{Date: r.Date, Amount: r.Amount, Type: Withdrawal}]++
+		}
+	}
+	for _, cf := range inv.CashFlows {
+		if cf.Date.After(date) {
+			continue
+		}
+		if cf.Type == Withdrawal && redeemed[cf] > 0 {
+			redeemed[cf]--
+			continue
+		}
+		cost += cf.SignedAmount()
+	}
+	return max(cost, 0)
+}
+
+// RealizedGain retourne les plus-values réalisées de l'investissement : celles du
+// registre de parts s'il en a un, celles des rachats en montant sinon
+func (inv *Investment) RealizedGain() Money {
+	if pos, err := inv.Position(); err == nil {
+		return pos.RealizedGain
+	}
+	var gain Money
+	for _, r := range inv.Redemptions {
+		gain += r.Gain
+	}
+	return gain
+}
+
+// Sell enregistre la vente partielle d'un investissement à une date, pour un montant
+// brut ou un nombre de parts (l'un des deux). La valeur de la ligne à cette date (NAV du
+// jour, à défaut interpolée ou dernière connue) fixe le prix : un investissement doté
+// d'un registre de parts reçoit une transaction de vente, les autres un rachat au
+// prorata de leur prix de revient. Le retrait correspondant est ajouté aux flux ;
+// l'historique de NAV est conservé tel quel pour les performances passées.
+func (p *Portfolio) Sell(investmentName, date string, amount, units, fees float64) (Sale, error) {
+	if (amount > 0) == (units > 0) {
+		return Sale{}, fmt.Errorf("un montant ou un nombre de parts positif est attendu, pas les deux: %w", ErrInvalidAmount)
+	}
+	if fees < 0 {
+		return Sale{}, fmt.Errorf("les frais ne peuvent pas être négatifs: %w", ErrInvalidAmount)
+	}
+	t, err := ParseDate(date)
+	if err != nil {
+		return Sale{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return Sale{}, fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	if inv.Closed {
+		return Sale{}, fmt.Errorf("l'investissement '%s' est clôturé", investmentName)
+	}
+	value, held := inv.historicalValue(t)
+	if !held || value <= 0 {
+		return Sale{}, fmt.Errorf("'%s' n'a pas de valeur au %s: %w", investmentName, date, ErrNoNAV)
+	}
+
+	before := inv.clone()
+	sale := Sale{Investment: investmentName, Date: t}
+	if len(inv.ledger()) > 0 {
+		heldUnits := inv.unitsAt(t)
+		if heldUnits <= 0 {
+			return Sale{}, fmt.Errorf("aucune part détenue au %s: %w", date, ErrInvalidAmount)
+		}
+		price := NewPrice(value / heldUnits.Float64())
+		sale.Units = NewQuantity(units)
+		if units == 0 {
+			sale.Units = NewQuantity(amount / price.Float64())
+		}
+		if sale.Units > heldUnits {
+			return Sale{}, fmt.Errorf("vente de %s parts pour %s détenues au %s: %w", sale.Units, heldUnits, date, ErrInvalidAmount)
+		}
+		sale.Amount = price.Total(sale.Units) - NewMoney(fees)
+		if sale.Amount <= 0 {
+			return Sale{}, fmt.Errorf("les frais absorbent le produit de la vente: %w", ErrInvalidAmount)
+		}
+		realized := inv.RealizedGain()
+		inv.addTransaction(Transaction{Date: Date{t}, Type: Sell, Units: sale.Units, Price: price, Fees: NewMoney(fees)})
+		sale.Gain = inv.RealizedGain() - realized
+		sale.CostBasis = sale.Amount - sale.Gain
+	} else {
+		if units > 0 {
+			return Sale{}, InvalidField("units", units, "'%s' est suivi en montant, sans parts", investmentName)
+		}
+		gross := NewMoney(amount)
+		if gross.Float64() > value {
+			return Sale{}, fmt.Errorf("rachat de %.2f pour une valeur de %.2f au %s: %w", amount, value, date, ErrInvalidAmount)
+		}
+		sale.Amount = gross - NewMoney(fees)
+		if sale.Amount <= 0 {
+			return Sale{}, fmt.Errorf("les frais absorbent le produit de la vente: %w", ErrInvalidAmount)
+		}
+		sale.CostBasis = inv.costBasisAt(t).Mul(gross.Float64() / value).RoundCents()
+		sale.Gain = sale.Amount - sale.CostBasis
+		inv.Redemptions = append(inv.Redemptions, Redemption{Date: Date{t}, Amount: sale.Amount, CostBasis: sale.CostBasis, Gain: sale.Gain})
+		sort.SliceStable(inv.Redemptions, func(i, j int) bool {
+			return inv.Redemptions[i].Date.Before(inv.Redemptions[j].Date.Time)
+		})
+		inv.addCashFlow(CashFlow{Date: Date{t}, Amount: sale.Amount, Type: Withdrawal})
+	}
+	p.record(OpSell, investmentName, fmt.Sprintf("vente de %.2f au %s, plus-value %.2f", sale.Amount.Float64(), date, sale.Gain.Float64()), before)
+	p.valueChanged(investmentName)
+	return sale, nil
+}
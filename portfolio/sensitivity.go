@@ -0,0 +1,49 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// SensitivityGrid croise des versements mensuels et des taux annuels : la valeur
+// projetée du portefeuille à la date visée pour chaque couple
+type SensitivityGrid struct {
+	Date          time.Time
+	Contributions []float64   // Versements mensuels (colonnes), en devise de consolidation
+	Rates         []float64   // Taux annuels (%) appliqués à tous les investissements (lignes)
+	Values        [][]float64 // Values[i][j] : valeur projetée au taux Rates[i] avec le versement Contributions[j]
+}
+
+// Sensitivity projette le portefeuille à une date pour chaque versement mensuel et
+// chaque taux, comme RequiredRate : le versement est réparti selon l'allocation cible
+// (ou sa trajectoire) si elle existe, sinon au prorata des valeurs.
+func (p *Portfolio) Sensitivity(date string, contributions, rates []float64) (*SensitivityGrid, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	if len(contributions) == 0 || len(rates) == 0 {
+		return nil, fmt.Errorf("au moins un versement et un taux sont nécessaires")
+	}
+	for _, monthly := range contributions {
+		if monthly < 0 {
+			return nil, fmt.Errorf("le versement mensuel ne peut pas être négatif: %w", ErrInvalidAmount)
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	g := &SensitivityGrid{Date: t, Contributions: contributions, Rates: rates, Values: make([][]float64, len(rates))}
+	for i, rate := range rates {
+		g.Values[i] = make([]float64, len(contributions))
+		for j, monthly := range contributions {
+			value, err := p.projectGoal(t, monthly, &rate)
+			if err != nil {
+				return nil, err
+			}
+			g.Values[i][j] = value
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,234 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeriesStep est l'intervalle entre deux points d'une série de valeurs
+type SeriesStep string
+
+const (
+	StepDaily     SeriesStep = "daily"
+	StepWeekly    SeriesStep = "weekly"
+	StepMonthly   SeriesStep = "monthly"
+	StepQuarterly SeriesStep = "quarterly"
+	StepYearly    SeriesStep = "yearly"
+)
+
+// add avance une date de n pas calendaires
+func (s SeriesStep) add(t time.Time, n int) (time.Time, error) {
+	switch s {
+	case StepDaily:
+		return t.AddDate(0, 0, n), nil
+	case StepWeekly:
+		return t.AddDate(0, 0, 7*n), nil
+	case StepMonthly:
+		return t.AddDate(0, n, 0), nil
+	case StepQuarterly:
+		return t.AddDate(0, 3*n, 0), nil
+	case StepYearly:
+		return t.AddDate(n, 0, 0), nil
+	default:
+		return time.Time{}, InvalidField("step", s, "pas de série inconnu: %s", s)
+	}
+}
+
+// ValuePoint est la valeur du portefeuille à une date de la série
+type ValuePoint struct {
+	Date   Date               `json:"date"`
+	Total  float64            `json:"total"`  // Valeur totale en devise de consolidation
+	Values map[string]float64 `json:"values"` // Valeur de chaque investissement détenu à cette date
+}
+
+// ValueSeries produit la valeur historique du portefeuille de from à to, un point par pas.
+// Chaque investissement est valorisé par interpolation linéaire entre ses NAV réelles
+// (le montant investi servant de point de départ à la date d'investissement), puis à sa
+// dernière NAV connue ; il n'est compté ni avant son investissement ni après sa clôture.
+// Une borne vide part de la première date d'investissement ou s'arrête à la dernière NAV.
+func (p *Portfolio) ValueSeries(from, to string, step SeriesStep) ([]ValuePoint, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if from == "" || to == "" {
+		first, last := p.historyBounds()
+		if from == "" {
+			start = first
+		}
+		if to == "" {
+			end = last
+		}
+	}
+	if start.IsZero() || end.Before(start) {
+		return nil, fmt.Errorf("période de série vide: %w", ErrInsufficientHistory)
+	}
+
+	var series []ValuePoint
+	for i := 0; ; i++ {
+		date, err := step.add(start, i)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(end) {
+			break
+		}
+
+		point := ValuePoint{Date: Date{date}, Values: make(map[string]float64)}
+		var total Money
+		for name, inv := range p.Investments {
+			value, held, err := p.investmentValueAt(inv, date)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			if !held {
+				continue
+			}
+			rounded := NewMoney(value).RoundCents()
+			point.Values[name] = rounded.Float64()
+			total += rounded
+		}
+		point.Total = total.Float64()
+		series = append(series, point)
+	}
+	return series, nil
+}
+
+// projectionDates retourne les dates d'une série projetée : start, puis un point par pas
+// jusqu'à end, end étant ajoutée si elle ne tombe pas sur un pas
+func projectionDates(start, end time.Time, step SeriesStep) ([]time.Time, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("l'horizon %s précède le début de la projection (%s)", FormatDate(end), FormatDate(start))
+	}
+	var dates []time.Time
+	for i := 0; ; i++ {
+		date, err := step.add(start, i)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(end) {
+			break
+		}
+		dates = append(dates, date)
+	}
+	if !dates[len(dates)-1].Equal(end) {
+		dates = append(dates, end)
+	}
+	return dates, nil
+}
+
+// ProjectSeries projette la valeur de l'investissement d'aujourd'hui (ou de sa dernière
+// NAV si elle est postérieure) jusqu'à l'horizon to, un point par pas, au taux de
+// projection de l'investissement, frais et versements programmés compris
+func (inv *Investment) ProjectSeries(to string, step SeriesStep) ([]NAV, error) {
+	end, err := ParseDate(to)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := inv.GetLatestNAV()
+	if err != nil {
+		return nil, err
+	}
+	dates, err := projectionDates(later(Today(), latest.Date.Time), end, step)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := inv.ProjectionRate(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]NAV, len(dates))
+	for i, date := range dates {
+		value, err := inv.projectNAVAtRate(date, rate)
+		if err != nil {
+			return nil, err
+		}
+		series[i] = NAV{Date: Date{date}, Value: NewMoney(value).RoundCents()}
+	}
+	return series, nil
+}
+
+// ProjectSeries projette la valeur du portefeuille jusqu'à l'horizon to, un point par
+// pas, comme GetPortfolioValue à chaque date. La série part d'aujourd'hui, ou de la
+// dernière NAV des investissements ouverts si elle est postérieure.
+func (p *Portfolio) ProjectSeries(to string, step SeriesStep) ([]ValuePoint, error) {
+	end, err := ParseDate(to)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	start := Today()
+	for _, inv := range p.Investments {
+		if n := len(inv.NAVHistory); n > 0 && !inv.Closed {
+			start = later(start, inv.NAVHistory[n-1].Date.Time)
+		}
+	}
+	dates, err := projectionDates(start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]ValuePoint, len(dates))
+	for i, date := range dates {
+		values, total, err := p.portfolioValue(date)
+		if err != nil {
+			return nil, err
+		}
+		series[i] = ValuePoint{Date: Date{date}, Total: total, Values: values}
+	}
+	return series, nil
+}
+
+// later retourne la plus tardive de deux dates
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// historyBounds retourne la première date d'investissement et la dernière date de NAV.
+// L'appelant doit détenir p.mu.
+func (p *Portfolio) historyBounds() (first, last time.Time) {
+	for _, inv := range p.Investments {
+		if first.IsZero() || inv.InvestmentDate.Before(first) {
+			first = inv.InvestmentDate.Time
+		}
+		if n := len(inv.NAVHistory); n > 0 && inv.NAVHistory[n-1].Date.After(last) {
+			last = inv.NAVHistory[n-1].Date.Time
+		}
+	}
+	return first, last
+}
+
+// historicalValue estime la valeur de l'investissement à une date passée par
+// interpolation linéaire entre les NAV encadrantes ; held est faux si l'investissement
+// n'était pas détenu à cette date
+func (inv *Investment) historicalValue(date time.Time) (value float64, held bool) {
+	if date.Before(inv.InvestmentDate.Time) || (inv.Closed && date.After(inv.ClosedDate.Time)) {
+		return 0, false
+	}
+
+	// Le montant investi tient lieu de NAV à la date d'investissement : avant la première
+	// NAV, la valeur est interpolée entre les deux, sans recopier l'historique
+	points := inv.NAVHistory
+	if len(points) == 0 || date.Before(points[0].Date.Time) {
+		origin := NAV{Date: inv.InvestmentDate, Value: inv.AmountInvested}
+		if len(points) == 0 || !points[0].Date.After(inv.InvestmentDate.Time) {
+			return origin.Value.Float64(), true
+		}
+		points = []NAV{origin, points[0]}
+	}
+
+	// date n'est jamais antérieure au premier point : navAt ne peut pas échouer
+	nav, _ := navAt(points, date, InterpolateLinear)
+	return nav.Float64(), true
+}
@@ -0,0 +1,186 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exposure est le sens d'une position dans le portefeuille
+type Exposure string
+
+const (
+	ExposureLong Exposure = "" // Position acheteuse (par défaut) : montants et NAV positifs
+	// ExposureShort est une vente à découvert : le montant investi est le produit de la
+	// vente et les NAV la valeur des titres à racheter, positifs comme pour une position
+	// acheteuse, mais comptés en négatif dans le portefeuille. La position gagne quand le
+	// cours baisse.
+	ExposureShort Exposure = "short"
+	// ExposureSigned est un dérivé dont la valeur de marché peut être nulle ou négative
+	// (swap, option vendue) : montant investi (prime payée, négative si reçue) et NAV
+	// sont signés et comptés tels quels. Faute de base positive, son rendement n'est pas
+	// défini et sa valeur n'est pas capitalisée dans les projections.
+	ExposureSigned Exposure = "signed"
+)
+
+// ErrUndefinedReturn signale un rendement non défini, celui d'une valeur signée
+var ErrUndefinedReturn = errors.New("rendement non défini pour une valeur signée")
+
+// ParseExposure lit un sens de position : long (ou vide), short ou signed
+func ParseExposure(s string) (Exposure, error) {
+	switch s {
+	case "", "long":
+		return ExposureLong, nil
+	case string(ExposureShort), string(ExposureSigned):
+		return Exposure(s), nil
+	}
+	return "", InvalidField("exposure", s, "sens de position inconnu: %s (long, short, signed)", s)
+}
+
+func (e Exposure) String() string {
+	if e == ExposureLong {
+		return "long"
+	}
+	return string(e)
+}
+
+// Sign retourne le signe de la contribution de l'investissement au portefeuille : -1
+// pour une vente à découvert, 1 sinon
+func (inv *Investment) Sign() float64 {
+	if inv.Exposure == ExposureShort {
+		return -1
+	}
+	return 1
+}
+
+// signed retourne un flux vu du portefeuille : un apport sur une vente à découvert
+// (titres vendus en plus) en diminue la valeur comme un retrait, et inversement
+func (inv *Investment) signed(cf CashFlow) CashFlow {
+	if inv.Exposure != ExposureShort {
+		return cf
+	}
+	if cf.Type == Withdrawal {
+		cf.Type = Contribution
+	} else {
+		cf.Type = Withdrawal
+	}
+	return cf
+}
+
+// acceptsValue vérifie une NAV ou un montant selon le sens de la position : positif,
+// sauf pour une valeur signée
+func (inv *Investment) acceptsValue(field string, value float64) error {
+	if inv.Exposure == ExposureSigned || NewMoney(value) > 0 {
+		return nil
+	}
+	return &ValidationError{Field: field, Value: value, Message: "la NAV doit être positive", Err: ErrInvalidAmount}
+}
+
+// AddDerivative ajoute une position à valeur signée (voir ExposureSigned) : premium est la
+// prime payée, négative si elle a été reçue, nulle pour un swap
+func (p *Portfolio) AddDerivative(name string, premium float64, investmentDate string) error {
+	t, err := ParseDate(investmentDate)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv := &Investment{
+		ID:             newInvestmentID(),
+		Name:           name,
+		AmountInvested: NewMoney(premium),
+		NAVHistory:     make([]NAV, 0),
+		InvestmentDate: Date{t},
+		Exposure:       ExposureSigned,
+		metrics:        newMetricsCache(),
+	}
+
+	before := p.investmentState(name)
+	p.Investments[name] = inv
+	p.attach(inv)
+	p.record(OpAddInvestment, name, fmt.Sprintf("dérivé, prime %.2f au %s", premium, investmentDate), before)
+	p.investmentAdded(name)
+	return nil
+}
+
+// SetExposure change le sens d'une position. Une position acheteuse ou vendeuse exige un
+// montant investi et des NAV positifs ; les comptes rémunérés et les obligations restent
+// acheteurs.
+func (p *Portfolio) SetExposure(name string, exposure Exposure) error {
+	if _, err := ParseExposure(string(exposure)); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	if exposure != ExposureLong && (inv.Cash != nil || inv.Bond != nil || inv.Holdings != nil) {
+		return fmt.Errorf("'%s' ne peut être qu'une position acheteuse", name)
+	}
+	if exposure != ExposureSigned {
+		if inv.AmountInvested <= 0 {
+			return fmt.Errorf("le montant investi de '%s' n'est pas positif: %w", name, ErrInvalidAmount)
+		}
+		for _, nav := range inv.NAVHistory {
+			if nav.Value <= 0 {
+				return fmt.Errorf("NAV de %s au %s non positive: %w", name, FormatDate(nav.Date.Time), ErrInvalidAmount)
+			}
+		}
+	}
+	before := inv.clone()
+	inv.Exposure = exposure
+	inv.invalidate()
+	p.record(OpSetExposure, name, fmt.Sprintf("%s -> %s", before.Exposure, exposure), before)
+	p.valueChanged(name)
+	return nil
+}
+
+// exposureReturn retourne le rendement d'une position à partir de celui de ses NAV :
+// opposé pour une vente à découvert, non défini pour une valeur signée
+func (inv *Investment) exposureReturn(r float64) (float64, error) {
+	switch inv.Exposure {
+	case ExposureShort:
+		return -r, nil
+	case ExposureSigned:
+		return 0, fmt.Errorf("'%s': %w", inv.Name, ErrUndefinedReturn)
+	}
+	return r, nil
+}
+
+// navRate retourne le taux auquel évoluent les NAV d'une position dont le rendement est
+// rate : les titres d'une vente à découvert évoluent à l'opposé de la position
+func (inv *Investment) navRate(rate float64) float64 {
+	return rate * inv.Sign()
+}
+
+// GrossExposure retourne, à une date, les valeurs acheteuses et vendeuses du
+// portefeuille en devise de consolidation : long additionne les valeurs positives, short
+// les valeurs négatives (ventes à découvert, dérivés de valeur négative). La valeur nette
+// est leur somme, l'exposition brute long - short.
+func (p *Portfolio) GrossExposure(date string) (long, short float64, err error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values, _, err := p.portfolioValue(t)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, value := range values {
+		if value < 0 {
+			short += value
+		} else {
+			long += value
+		}
+	}
+	return long, short, nil
+}
@@ -0,0 +1,243 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Snapshot est l'état résumé du portefeuille à un instant, conservé pour être comparé
+// à un état ultérieur (revue mensuelle par exemple)
+type Snapshot struct {
+	Label         string                  `json:"label"`
+	Taken         time.Time               `json:"taken"`
+	BaseCurrency  Currency                `json:"base_currency"`
+	TotalValue    float64                 `json:"total_value"`    // Valeur des investissements ouverts, en devise de consolidation
+	TotalInvested float64                 `json:"total_invested"` // Capital net investi des investissements ouverts, en devise de consolidation
+	Investments   map[string]SnapshotLine `json:"investments"`
+}
+
+// SnapshotLine est l'état d'un investissement dans un instantané
+type SnapshotLine struct {
+	Currency    Currency `json:"currency"`
+	Closed      bool     `json:"closed,omitempty"`
+	Value       float64  `json:"value"`        // En devise de consolidation
+	NetInvested float64  `json:"net_invested"` // Dans la devise de l'investissement
+	LatestNAV   float64  `json:"latest_nav,omitempty"`
+	NAVCount    int      `json:"nav_count"`
+	FirstNAV    string   `json:"first_nav,omitempty"` // Date de la première NAV (AAAA-MM-JJ)
+	LastNAV     string   `json:"last_nav,omitempty"`  // Date de la dernière NAV (AAAA-MM-JJ)
+}
+
+// SnapshotDiff décrit l'évolution du portefeuille entre deux instantanés
+type SnapshotDiff struct {
+	From, To    string           // Libellés comparés ("maintenant" pour l'état courant)
+	Currency    Currency         // Devise de consolidation
+	Added       []string         // Investissements apparus
+	Removed     []string         // Investissements disparus
+	Closed      []string         // Investissements clôturés entre les deux instantanés
+	ValueChange float64          // Variation de la valeur totale
+	Flows       float64          // Variation du capital net investi (apports moins retraits)
+	Return      *float64         // Rendement sur l'intervalle hors flux (%), nil si la valeur initiale est nulle
+	Investments []InvestmentDiff // Investissements présents dans les deux instantanés, triés par nom
+}
+
+// InvestmentDiff est l'évolution d'un investissement entre deux instantanés
+type InvestmentDiff struct {
+	Name        string
+	NewNAVs     int      // Nombre de NAV ajoutées
+	NewSince    string   // Dernière NAV du premier instantané, que suivent les NAV ajoutées (vide s'il n'en avait pas)
+	NewUntil    string   // Dernière NAV du second instantané
+	ValueChange float64  // En devise de consolidation
+	Flows       float64  // Variation du capital net investi, dans la devise de l'investissement
+	Return      *float64 // Rendement hors flux sur l'intervalle, mesuré sur les NAV (%)
+}
+
+// SnapshotNow est le libellé de l'état courant dans DiffSnapshots
+const SnapshotNow = "maintenant"
+
+// currentSnapshot résume l'état courant du portefeuille
+func (p *Portfolio) currentSnapshot(label string) (*Snapshot, error) {
+	summary, err := p.Summary()
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{
+		Label:         label,
+		Taken:         time.Now().UTC(),
+		BaseCurrency:  summary.BaseCurrency,
+		TotalValue:    summary.TotalValue,
+		TotalInvested: summary.TotalInvested,
+		Investments:   make(map[string]SnapshotLine, len(summary.Investments)),
+	}
+	for _, line := range summary.Investments {
+		inv, err := p.Investment(line.Name)
+		if err != nil {
+			return nil, err
+		}
+		sl := SnapshotLine{
+			Currency:    line.Currency,
+			Closed:      line.Closed,
+			Value:       line.Value,
+			NetInvested: line.NetInvested.Float64(),
+			NAVCount:    len(inv.NAVHistory),
+		}
+		if line.LatestNAV != nil {
+			sl.LatestNAV = line.LatestNAV.Value.Float64()
+		}
+		if n := len(inv.NAVHistory); n > 0 {
+			sl.FirstNAV, sl.LastNAV = FormatDate(inv.NAVHistory[0].Date.Time), FormatDate(inv.NAVHistory[n-1].Date.Time)
+		}
+		s.Investments[line.Name] = sl
+	}
+	return s, nil
+}
+
+// Snapshot enregistre l'état courant du portefeuille sous un libellé (la date du jour si
+// vide) et le retourne
+func (p *Portfolio) Snapshot(label string) (*Snapshot, error) {
+	if label == "" {
+		label = FormatDate(time.Now())
+	}
+	if label == SnapshotNow {
+		return nil, fmt.Errorf("le libellé '%s' est réservé à l'état courant", SnapshotNow)
+	}
+	s, err := p.currentSnapshot(label)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Snapshots[label]; exists {
+		return nil, fmt.Errorf("un instantané '%s' existe déjà: %w", label, ErrAlreadyExists)
+	}
+	if p.Snapshots == nil {
+		p.Snapshots = make(map[string]*Snapshot)
+	}
+	p.Snapshots[label] = s
+	p.changed("snapshot", "")
+	return s, nil
+}
+
+// DeleteSnapshot supprime un instantané
+func (p *Portfolio) DeleteSnapshot(label string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Snapshots[label]; !exists {
+		return fmt.Errorf("aucun instantané '%s': %w", label, ErrNotFound)
+	}
+	delete(p.Snapshots, label)
+	p.changed("delete-snapshot", "")
+	return nil
+}
+
+// SnapshotLabels retourne les libellés des instantanés, du plus ancien au plus récent
+func (p *Portfolio) SnapshotLabels() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	labels := make([]string, 0, len(p.Snapshots))
+	for label := range p.Snapshots {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := p.Snapshots[labels[i]], p.Snapshots[labels[j]]
+		if !a.Taken.Equal(b.Taken) {
+			return a.Taken.Before(b.Taken)
+		}
+		return labels[i] < labels[j]
+	})
+	return labels
+}
+
+// LookupSnapshot retourne l'instantané d'un libellé, ou l'état courant pour "maintenant" ou vide
+func (p *Portfolio) LookupSnapshot(label string) (*Snapshot, error) {
+	if label == "" || label == SnapshotNow {
+		return p.currentSnapshot(SnapshotNow)
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	s, exists := p.Snapshots[label]
+	if !exists {
+		return nil, fmt.Errorf("aucun instantané '%s': %w", label, ErrNotFound)
+	}
+	return s, nil
+}
+
+// DiffSnapshots compare deux instantanés ; un libellé vide ou "maintenant" désigne
+// l'état courant. Le rendement sur l'intervalle neutralise les flux : (variation de
+// valeur - flux) / valeur initiale.
+func (p *Portfolio) DiffSnapshots(a, b string) (*SnapshotDiff, error) {
+	from, err := p.LookupSnapshot(a)
+	if err != nil {
+		return nil, err
+	}
+	to, err := p.LookupSnapshot(b)
+	if err != nil {
+		return nil, err
+	}
+	if from.BaseCurrency != to.BaseCurrency {
+		return nil, fmt.Errorf("devises de consolidation différentes: %s et %s", from.BaseCurrency, to.BaseCurrency)
+	}
+
+	d := &SnapshotDiff{
+		From:        from.Label,
+		To:          to.Label,
+		Currency:    to.BaseCurrency,
+		ValueChange: to.TotalValue - from.TotalValue,
+		Flows:       to.TotalInvested - from.TotalInvested,
+	}
+	d.Return = intervalReturn(from.TotalValue, to.TotalValue, d.Flows)
+
+	names := make([]string, 0, len(to.Investments))
+	for name := range to.Investments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		after := to.Investments[name]
+		before, existed := from.Investments[name]
+		if !existed {
+			d.Added = append(d.Added, name)
+			continue
+		}
+		if after.Closed && !before.Closed {
+			d.Closed = append(d.Closed, name)
+		}
+
+		diff := InvestmentDiff{
+			Name:        name,
+			ValueChange: after.Value - before.Value,
+			Flows:       after.NetInvested - before.NetInvested,
+		}
+		if after.NAVCount > before.NAVCount {
+			diff.NewNAVs = after.NAVCount - before.NAVCount
+			diff.NewSince, diff.NewUntil = before.LastNAV, after.LastNAV
+		}
+		if before.LatestNAV > 0 && after.LatestNAV > 0 {
+			diff.Return = intervalReturn(before.LatestNAV, after.LatestNAV, diff.Flows)
+		}
+		d.Investments = append(d.Investments, diff)
+	}
+	for name := range from.Investments {
+		if _, exists := to.Investments[name]; !exists {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	sort.Strings(d.Removed)
+	return d, nil
+}
+
+// intervalReturn calcule le rendement hors flux entre deux valeurs (%), nil si la valeur
+// initiale est nulle
+func intervalReturn(start, end, flows float64) *float64 {
+	if start == 0 {
+		return nil
+	}
+	r := (end - start - flows) / start * 100
+	return &r
+}
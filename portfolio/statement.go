@@ -0,0 +1,439 @@
+package portfolio
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatementEntryKind est la nature d'une opération d'un relevé bancaire ou de titres
+type StatementEntryKind string
+
+const (
+	StatementCash     StatementEntryKind = "cash"     // Mouvement d'espèces : apport si crédit, retrait si débit
+	StatementBuy      StatementEntryKind = "buy"      // Achat de parts
+	StatementSell     StatementEntryKind = "sell"     // Vente de parts
+	StatementIncome   StatementEntryKind = "income"   // Dividende, coupon ou intérêts versés
+	StatementReinvest StatementEntryKind = "reinvest" // Distribution réinvestie en parts
+)
+
+// StatementSecurity désigne un titre dans un relevé
+type StatementSecurity struct {
+	ID     string // Identifiant du relevé (ISIN ou CUSIP en OFX)
+	Ticker string
+	Name   string
+}
+
+// String retourne le libellé le plus précis du titre, vide s'il n'est pas renseigné
+func (s StatementSecurity) String() string {
+	switch {
+	case s.Ticker != "":
+		return s.Ticker
+	case s.ID != "":
+		return s.ID
+	default:
+		return s.Name
+	}
+}
+
+// keys retourne les codes du titre dans l'ordre où ils sont essayés
+func (s StatementSecurity) keys() []string {
+	var keys []string
+	for _, k := range []string{s.ID, s.Ticker, s.Name} {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// StatementEntry est une opération d'un relevé
+type StatementEntry struct {
+	Date        time.Time
+	Kind        StatementEntryKind
+	Amount      Money    // Montant, signé pour StatementCash (positif au crédit), positif sinon
+	Units       Quantity // Parts achetées ou vendues, toujours positif
+	Price       Money    // Prix unitaire
+	Fees        Money    // Frais et commissions
+	Security    StatementSecurity
+	Description string // Libellé de l'opération (bénéficiaire, mémo)
+}
+
+// StatementPosition est une ligne détenue à la date du relevé
+type StatementPosition struct {
+	Date     time.Time
+	Security StatementSecurity
+	Units    Quantity
+	Price    Money
+	Value    Money // Valeur de marché de la ligne
+}
+
+// StatementAccount regroupe les opérations et positions d'un compte du relevé
+type StatementAccount struct {
+	ID        string // Numéro de compte (OFX) ou nom du compte (QIF)
+	Currency  Currency
+	Entries   []StatementEntry
+	Positions []StatementPosition
+}
+
+// Statement est un relevé importé par ImportStatement, lu par un Importer
+type Statement struct {
+	Accounts []StatementAccount
+}
+
+// StatementOptions paramètre la lecture d'un relevé
+type StatementOptions struct {
+	Account    string // Compte des opérations dont le relevé ne précise pas le compte
+	MonthFirst bool   // Dates ambiguës au format américain (MM/JJ/AAAA) plutôt que JJ/MM/AAAA
+}
+
+// StatementRule associe les opérations d'un compte ou d'un titre à un investissement. Les
+// motifs suivent la syntaxe de path.Match (« FR76* ») ; un motif vide accepte tout.
+type StatementRule struct {
+	Account    string `json:"account,omitempty"`    // Compte du relevé
+	Security   string `json:"security,omitempty"`   // Identifiant, ticker ou nom du titre
+	Investment string `json:"investment,omitempty"` // Investissement destinataire
+	Ignore     bool   `json:"ignore,omitempty"`     // Opérations à ne pas importer
+}
+
+// matches indique si la règle s'applique au compte et au titre
+func (r StatementRule) matches(account string, security StatementSecurity) bool {
+	if r.Account != "" {
+		if ok, _ := path.Match(r.Account, account); !ok {
+			return false
+		}
+	}
+	if r.Security == "" {
+		return true
+	}
+	for _, key := range security.keys() {
+		if ok, _ := path.Match(r.Security, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// StatementImportOptions paramètre ImportStatement
+type StatementImportOptions struct {
+	Rules  []StatementRule // Règles essayées avant celles du portefeuille
+	DryRun bool            // Calculer le bilan sans rien enregistrer
+	// Resolve est appelée pour un compte ou un titre qu'aucune règle ni aucun identifiant
+	// ne rattache à un investissement : elle retourne l'investissement destinataire, ou
+	// une règle Ignore. Nil pour laisser ces opérations non importées.
+	Resolve func(account string, security StatementSecurity) (StatementRule, error)
+}
+
+// StatementImportReport est le bilan d'un import de relevé
+type StatementImportReport struct {
+	Transactions  int      // Achats et ventes enregistrés
+	CashFlows     int      // Apports et retraits enregistrés
+	Distributions int      // Distributions enregistrées
+	NAVs          int      // NAV déduites des positions
+	Duplicates    int      // Opérations déjà présentes, non réimportées
+	Ignored       int      // Opérations écartées par une règle
+	Unmapped      []string // Comptes ou titres sans investissement, opérations non importées
+	Errors        []error  // Opérations refusées
+}
+
+// SetStatementRules remplace les règles de rattachement des relevés du portefeuille
+func (p *Portfolio) SetStatementRules(rules []StatementRule) error {
+	if err := validateStatementRules(rules); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.setStatementRules(rules)
+}
+
+// AddStatementRules ajoute des règles à la suite de celles du portefeuille
+func (p *Portfolio) AddStatementRules(rules ...StatementRule) error {
+	if err := validateStatementRules(rules); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.setStatementRules(append(append([]StatementRule(nil), p.StatementRules...), rules...))
+}
+
+// validateStatementRules vérifie que chaque règle désigne un investissement ou écarte
+// ses opérations, avec des motifs valides
+func validateStatementRules(rules []StatementRule) error {
+	for _, r := range rules {
+		if r.Investment == "" && !r.Ignore {
+			return InvalidField("investment", r.Investment, "la règle %s/%s ne désigne aucun investissement", r.Account, r.Security)
+		}
+		for _, pattern := range []string{r.Account, r.Security} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return InvalidField("pattern", pattern, "motif invalide: %s", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// setStatementRules remplace les règles en journalisant l'ancienne liste ; l'appelant
+// doit détenir p.mu
+func (p *Portfolio) setStatementRules(rules []StatementRule) error {
+	before, err := p.settingsState("statement_rules")
+	if err != nil {
+		return err
+	}
+
+	p.StatementRules = rules
+	return p.recordChange(JournalEntry{Op: OpSetStatementRules, Detail: fmt.Sprintf("%d règles", len(rules)), BeforeSettings: before})
+}
+
+// statementMapper rattache les opérations d'un relevé aux investissements
+type statementMapper struct {
+	p        *Portfolio
+	opts     StatementImportOptions
+	rules    []StatementRule
+	resolved map[string]StatementRule // Décisions de Resolve, par compte et titre
+	report   *StatementImportReport
+}
+
+// target retourne l'investissement d'une opération, vide si elle n'est pas importée :
+// première règle applicable, sinon investissement désigné par l'identifiant du titre
+// (ou, pour un mouvement d'espèces, par le nom du compte), sinon décision de Resolve
+func (m *statementMapper) target(account string, security StatementSecurity) (string, error) {
+	for _, r := range m.rules {
+		if r.matches(account, security) {
+			return m.apply(r), nil
+		}
+	}
+	keys := security.keys()
+	if len(keys) == 0 {
+		keys = []string{account}
+	}
+	for _, key := range keys {
+		if inv, err := m.p.Lookup(key); err == nil {
+			return inv.Name, nil
+		}
+	}
+
+	key := account
+	if s := security.String(); s != "" {
+		key += " / " + s
+	}
+	r, decided := m.resolved[key]
+	if !decided {
+		if m.opts.Resolve == nil {
+			if !slices.Contains(m.report.Unmapped, key) {
+				m.report.Unmapped = append(m.report.Unmapped, key)
+			}
+			return "", nil
+		}
+		var err error
+		if r, err = m.opts.Resolve(account, security); err != nil {
+			return "", err
+		}
+		m.resolved[key] = r
+	}
+	return m.apply(r), nil
+}
+
+// apply retourne l'investissement d'une règle, vide si elle écarte l'opération
+func (m *statementMapper) apply(r StatementRule) string {
+	if r.Ignore || r.Investment == "" {
+		m.report.Ignored++
+		return ""
+	}
+	return r.Investment
+}
+
+// ImportStatement enregistre les opérations et positions d'un relevé dans les
+// investissements auxquels les rattachent les règles (celles des options, puis celles
+// du portefeuille), les identifiants des titres ou la fonction Resolve. Les achats et
+// ventes deviennent des transactions, les mouvements d'espèces des apports ou retraits,
+// les revenus des distributions et les positions des NAV. Une opération identique à une
+// opération déjà enregistrée (même date, même nature, même montant) n'est pas
+// réimportée, ce qui permet d'importer des relevés qui se chevauchent. Les opérations
+// refusées sont listées dans le bilan sans interrompre l'import.
+func (p *Portfolio) ImportStatement(st *Statement, opts StatementImportOptions) (StatementImportReport, error) {
+	var report StatementImportReport
+	p.mu.RLock()
+	rules := append(append([]StatementRule(nil), opts.Rules...), p.StatementRules...)
+	p.mu.RUnlock()
+	m := &statementMapper{p: p, opts: opts, rules: rules, resolved: make(map[string]StatementRule), report: &report}
+
+	for _, acct := range st.Accounts {
+		entries := append([]StatementEntry(nil), acct.Entries...)
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+
+		for _, e := range entries {
+			name, err := m.target(acct.ID, e.Security)
+			if err != nil {
+				return report, err
+			}
+			if name == "" {
+				continue
+			}
+			if err := p.importStatementEntry(name, e, opts.DryRun, &report); err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("%s %s %s: %w", FormatDate(e.Date), e.Kind, e.Description, err))
+			}
+		}
+		for _, pos := range acct.Positions {
+			name, err := m.target(acct.ID, pos.Security)
+			if err != nil {
+				return report, err
+			}
+			if name == "" {
+				continue
+			}
+			if err := p.importStatementPosition(name, pos, opts.DryRun, &report); err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("position %s au %s: %w", pos.Security, FormatDate(pos.Date), err))
+			}
+		}
+	}
+	return report, nil
+}
+
+// importStatementEntry enregistre une opération dans un investissement, sauf si elle y
+// figure déjà
+func (p *Portfolio) importStatementEntry(name string, e StatementEntry, dryRun bool, report *StatementImportReport) error {
+	inv, err := p.Investment(name)
+	if err != nil {
+		return err
+	}
+	date := FormatDate(e.Date)
+
+	switch e.Kind {
+	case StatementBuy, StatementSell:
+		txType := Buy
+		if e.Kind == StatementSell {
+			txType = Sell
+		}
+		for _, tx := range inv.ledger() {
+			if tx.Date.Equal(e.Date) && tx.Type == txType && tx.Units == e.Units && tx.Price == NewPrice(e.Price.Float64()) {
+				report.Duplicates++
+				return nil
+			}
+		}
+		if !dryRun {
+			if err := p.AddTransaction(name, date, txType, e.Units.Float64(), e.Price.Float64(), e.Fees.Float64()); err != nil {
+				return err
+			}
+		}
+		report.Transactions++
+
+	case StatementCash:
+		flowType, amount := Contribution, e.Amount
+		if amount < 0 {
+			flowType, amount = Withdrawal, -amount
+		}
+		if amount == 0 {
+			return nil
+		}
+		for _, cf := range inv.CashFlows {
+			if cf.Date.Equal(e.Date) && cf.Type == flowType && cf.Amount == amount {
+				report.Duplicates++
+				return nil
+			}
+		}
+		if !dryRun {
+			if err := p.AddCashFlow(name, date, amount.Float64(), flowType); err != nil {
+				return err
+			}
+		}
+		report.CashFlows++
+
+	case StatementIncome, StatementReinvest:
+		reinvested := e.Kind == StatementReinvest
+		for _, d := range inv.Distributions {
+			if d.Date.Equal(e.Date) && d.Amount == e.Amount && d.Reinvested == reinvested {
+				report.Duplicates++
+				return nil
+			}
+		}
+		if !dryRun {
+			if err := p.AddDistribution(name, date, e.Amount.Float64(), reinvested); err != nil {
+				return err
+			}
+		}
+		report.Distributions++
+
+	default:
+		return InvalidField("kind", e.Kind, "opération inconnue: %s", e.Kind)
+	}
+	return nil
+}
+
+// importStatementPosition enregistre la valeur d'une position comme NAV de
+// l'investissement à la date du relevé
+func (p *Portfolio) importStatementPosition(name string, pos StatementPosition, dryRun bool, report *StatementImportReport) error {
+	value := pos.Value
+	if value == 0 {
+		value = pos.Price.Mul(pos.Units.Float64())
+	}
+	if value <= 0 {
+		return nil
+	}
+	inv, err := p.Investment(name)
+	if err != nil {
+		return err
+	}
+	if i, found := inv.navIndex(pos.Date); found {
+		if inv.NAVHistory[i].Value == value {
+			report.Duplicates++
+			return nil
+		}
+		// Simulation : la politique de doublon décide comme le ferait AddNAV
+		p.mu.RLock()
+		policy := p.DuplicateNAVPolicy
+		p.mu.RUnlock()
+		switch {
+		case !dryRun:
+		case policy == DuplicateNAVKeepExisting:
+			report.Duplicates++
+			return nil
+		case policy != DuplicateNAVReplace && policy != DuplicateNAVAverage:
+			return fmt.Errorf("NAV de %s au %s: %w", name, FormatDate(pos.Date), ErrDuplicateNAV)
+		}
+	}
+	if !dryRun {
+		if err := p.AddNAV(name, FormatDate(pos.Date), value.Float64()); err != nil {
+			return err
+		}
+	}
+	report.NAVs++
+	return nil
+}
+
+// parseStatementAmount lit un montant de relevé : point ou virgule décimale, séparateurs
+// de milliers (espace, virgule ou point) ignorés
+func parseStatementAmount(raw string) (Money, error) {
+	s := strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(strings.TrimSpace(raw))
+	comma, dot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case comma >= 0 && dot >= 0 && comma < dot:
+		s = strings.ReplaceAll(s, ",", "")
+	case comma >= 0 && dot >= 0:
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case comma >= 0:
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	m, err := ParseMoney(s)
+	if err != nil {
+		return 0, fmt.Errorf("montant '%s' invalide", raw)
+	}
+	return m, nil
+}
+
+// parseStatementUnits lit un nombre de parts, toujours retourné positif
+func parseStatementUnits(raw string) (Quantity, error) {
+	s := strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(raw), ",", "."), "-")
+	q, err := ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("nombre de parts '%s' invalide", raw)
+	}
+	return q, nil
+}
@@ -0,0 +1,205 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// SummaryLine est la ligne d'un investissement dans le résumé structuré du portefeuille
+type SummaryLine struct {
+	Name            string             `json:"name"`
+	Currency        Currency           `json:"currency"`
+	InvestmentDate  Date               `json:"investment_date"`
+	Closed          bool               `json:"closed,omitempty"`
+	ClosedDate      Date               `json:"closed_date,omitzero"` // Zéro tant que l'investissement n'est pas clôturé
+	AmountInvested  Money              `json:"amount_invested"`
+	NetInvested     Money              `json:"net_invested"`               // Capital net investi, flux inclus
+	ReferenceRate   float64            `json:"reference_rate"`             // Taux de référence (%)
+	Exposure        Exposure           `json:"-"`                          // Sens de la position
+	LatestNAV       *NAV               `json:"latest_nav,omitempty"`       // Dernière NAV, nil si aucune n'est enregistrée
+	PerformanceRate *float64           `json:"performance_rate,omitempty"` // Taux de performance annuel (%), nil sans au moins deux NAV
+	Value           float64            `json:"value"`                      // Valeur en devise de consolidation : dernière NAV, montant investi à défaut, 0 si clôturé, négative pour une vente à découvert
+	Distributions   Money              `json:"distributions,omitempty"`    // Distributions versées
+	Reinvested      Money              `json:"reinvested,omitempty"`       // Distributions réinvesties
+	Position        *Position          `json:"position,omitempty"`
+	Metrics         map[string]float64 `json:"metrics,omitempty"` // Indicateurs personnalisés définis pour l'investissement
+	Notes           []NoteEntry        `json:"-"`                 // Notes de l'investissement et de ses transactions
+}
+
+// PortfolioSummary est le résumé structuré du portefeuille, destiné aux tableaux de bord et tableurs
+type PortfolioSummary struct {
+	BaseCurrency  Currency      `json:"base_currency"`
+	TotalInvested float64       `json:"total_invested"`    // Capital net investi des investissements ouverts, en devise de consolidation
+	TotalValue    float64       `json:"total_value"`       // Somme des valeurs des investissements ouverts
+	Investments   []SummaryLine `json:"investments"`       // Triés par nom, sauf tri demandé
+	Metrics       []string      `json:"metrics,omitempty"` // Noms des indicateurs personnalisés, dans l'ordre de définition
+}
+
+// SummaryOptions choisit l'ordre et les investissements d'un résumé ; les investissements
+// clôturés sont retenus sauf si un filtre les écarte
+type SummaryOptions struct {
+	Locale     Locale    // Langue du résumé affiché, celle du portefeuille si vide
+	Sort       QuerySort // SortByName si vide
+	Descending bool
+	Filters    []QueryFilter
+	Metrics    []Metric // Indicateurs personnalisés ajoutés à chaque ligne
+}
+
+// query retourne la requête sélectionnant les investissements du résumé
+func (o SummaryOptions) query() QueryOptions {
+	return QueryOptions{Filters: o.Filters, Metrics: o.Metrics, Sort: o.Sort, Descending: o.Descending, IncludeClosed: true}
+}
+
+// PrintSummary affiche le résumé des investissements retenus par les filtres, dans
+// l'ordre demandé : deux appels sur les mêmes données produisent le même texte
+func (p *Portfolio) PrintSummary(opts SummaryOptions) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	results, err := p.query(opts.query())
+	if err != nil {
+		return err
+	}
+	p.printSummary(opts.Locale, results, opts.Metrics)
+	return nil
+}
+
+// Summary construit le résumé structuré du portefeuille. Les montants par ligne restent
+// dans la devise de l'investissement ; Value et les totaux sont convertis dans la devise
+// de consolidation, au taux de la date de la dernière NAV (ou de l'investissement).
+func (p *Portfolio) Summary() (*PortfolioSummary, error) {
+	return p.SummaryWith(SummaryOptions{})
+}
+
+// SummaryWith construit le résumé structuré des investissements retenus par les filtres,
+// dans l'ordre demandé ; les totaux ne portent que sur ces investissements
+func (p *Portfolio) SummaryWith(opts SummaryOptions) (*PortfolioSummary, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	results, err := p.query(opts.query())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PortfolioSummary{BaseCurrency: p.baseCurrency(), Investments: []SummaryLine{}}
+	for _, m := range opts.Metrics {
+		s.Metrics = append(s.Metrics, m.Name)
+	}
+	var totalInvested, totalValue Money
+	for _, r := range results {
+		name, inv := r.Name, p.Investments[r.Name]
+		line := SummaryLine{
+			Name:           name,
+			Currency:       inv.EffectiveCurrency(),
+			InvestmentDate: inv.InvestmentDate,
+			Closed:         inv.Closed,
+			ClosedDate:     inv.ClosedDate,
+			AmountInvested: inv.AmountInvested,
+			NetInvested:    inv.NetInvested(),
+			ReferenceRate:  inv.ReferenceRate,
+			Exposure:       inv.Exposure,
+			Metrics:        r.Metrics,
+			Notes:          inv.NoteEntries(),
+		}
+
+		date := inv.InvestmentDate
+		if latest, err := inv.GetLatestNAV(); err == nil {
+			line.LatestNAV = &latest
+			date = latest.Date
+			if len(inv.NAVHistory) >= 2 {
+				if rate, err := inv.CalculatePerformanceRate(); err == nil {
+					line.PerformanceRate = &rate
+				}
+			}
+		}
+		if len(inv.Distributions) > 0 {
+			if paid, reinvested, err := inv.TotalDistributions("", ""); err == nil {
+				line.Distributions, line.Reinvested = paid, reinvested
+			}
+		}
+		if len(inv.Transactions) > 0 {
+			if pos, err := inv.Position(); err == nil {
+				line.Position = &pos
+			}
+		}
+
+		if !inv.Closed {
+			converted, _, err := p.investmentValueAt(inv, date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			invested, err := p.toBase(line.NetInvested.Float64()*inv.Sign(), inv.Currency, date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			line.Value = NewMoney(converted).RoundCents().Float64()
+			totalValue += NewMoney(converted)
+			totalInvested += NewMoney(invested)
+		}
+		s.Investments = append(s.Investments, line)
+	}
+	s.TotalInvested = totalInvested.RoundCents().Float64()
+	s.TotalValue = totalValue.RoundCents().Float64()
+	return s, nil
+}
+
+// summaryCSVHeader est l'en-tête de l'export CSV du résumé
+var summaryCSVHeader = []string{
+	"name", "currency", "investment_date", "closed_date", "amount_invested", "net_invested",
+	"reference_rate", "latest_nav_date", "latest_nav", "performance_rate", "value",
+	"distributions", "reinvested", "units", "realized_gain", "unrealized_gain",
+}
+
+// WriteCSV écrit le résumé au format CSV, une ligne par investissement, les indicateurs
+// personnalisés en dernières colonnes. Les champs non renseignés (pas de NAV, pas de
+// transaction...) sont laissés vides.
+func (s *PortfolioSummary) WriteCSV(w io.Writer) error {
+	formatFloat := func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+
+	cw := csv.NewWriter(w)
+	header := append(slices.Clone(summaryCSVHeader), s.Metrics...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, l := range s.Investments {
+		record := make([]string, len(header))
+		record[0] = l.Name
+		record[1] = string(l.Currency)
+		record[2] = FormatDate(l.InvestmentDate.Time)
+		if l.Closed {
+			record[3] = FormatDate(l.ClosedDate.Time)
+		}
+		record[4] = l.AmountInvested.String()
+		record[5] = l.NetInvested.String()
+		record[6] = formatFloat(l.ReferenceRate, 2)
+		if l.LatestNAV != nil {
+			record[7] = FormatDate(l.LatestNAV.Date.Time)
+			record[8] = l.LatestNAV.Value.String()
+		}
+		if l.PerformanceRate != nil {
+			record[9] = formatFloat(*l.PerformanceRate, 4)
+		}
+		record[10] = formatFloat(l.Value, 2)
+		record[11] = l.Distributions.String()
+		record[12] = l.Reinvested.String()
+		if l.Position != nil {
+			record[13] = l.Position.Units.String()
+			record[14] = l.Position.RealizedGain.String()
+			record[15] = l.Position.UnrealizedGain.String()
+		}
+		for i, name := range s.Metrics {
+			if v, ok := l.Metrics[name]; ok {
+				record[len(summaryCSVHeader)+i] = formatFloat(v, 4)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,103 @@
+package portfolio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Clés d'étiquettes usuelles ; toute autre clé peut servir de label personnalisé
+const (
+	TagAssetClass = "asset_class" // Classe d'actifs (actions, obligations, immobilier…)
+	TagRegion     = "region"      // Zone géographique
+)
+
+// UntaggedLabel regroupe dans AllocationByTag les investissements sans valeur pour l'étiquette
+const UntaggedLabel = "non classé"
+
+// SetTag associe une valeur à une étiquette d'un investissement ; une valeur vide supprime l'étiquette
+func (p *Portfolio) SetTag(investmentName, tag, value string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// La modification d'une ligne est journalisée sur l'investissement composé de tête
+	root, _, _ := strings.Cut(investmentName, "/")
+	before := p.investmentState(root)
+	if err := p.setTag(investmentName, tag, value); err != nil {
+		return err
+	}
+	detail := fmt.Sprintf("%s=%q", strings.TrimSpace(tag), strings.TrimSpace(value))
+	if investmentName != root {
+		detail = investmentName + " " + detail
+	}
+	p.record(OpSetTag, root, detail, before)
+	return nil
+}
+
+// setTag associe une valeur à une étiquette (voir SetTag) ; l'appelant doit détenir p.mu
+func (p *Portfolio) setTag(investmentName, tag, value string) error {
+	// Une ligne d'investissement composé est désignée par son chemin ("Mandat/Fonds")
+	if parent, line, nested := strings.Cut(investmentName, "/"); nested {
+		holdings, err := p.holdingsAt(parent, false)
+		if err != nil {
+			return err
+		}
+		holdings.mu.Lock()
+		defer holdings.mu.Unlock()
+		return holdings.setTag(line, tag, value)
+	}
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return fmt.Errorf("le nom de l'étiquette ne peut pas être vide")
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		delete(inv.Tags, tag)
+		return nil
+	}
+	if inv.Tags == nil {
+		inv.Tags = make(map[string]string)
+	}
+	inv.Tags[tag] = value
+	return nil
+}
+
+// AllocationByTag répartit la valeur projetée du portefeuille à une date selon les
+// valeurs d'une étiquette et retourne le pourcentage de chaque valeur dans le total.
+// Les investissements non étiquetés sont regroupés sous UntaggedLabel, sauf ceux composés
+// de lignes, répartis selon les étiquettes de leurs lignes.
+func (p *Portfolio) AllocationByTag(tag string, date string) (map[string]float64, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values, totalValue, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, err
+	}
+	if totalValue == 0 {
+		return nil, fmt.Errorf("la valeur totale du portefeuille est nulle")
+	}
+
+	allocation := make(map[string]float64)
+	for name, value := range values {
+		labels, err := p.investmentLabels(p.Investments[name], tag, t)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		for label, share := range labels {
+			allocation[label] += share * value / totalValue * 100
+		}
+	}
+
+	return allocation, nil
+}
@@ -0,0 +1,214 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TaxWrapper est l'enveloppe fiscale d'un investissement ou d'un compte
+type TaxWrapper string
+
+const (
+	// TaxWrapperCTO est le compte-titres ordinaire : prélèvement forfaitaire unique sur les gains
+	TaxWrapperCTO TaxWrapper = "cto"
+	// TaxWrapperPEA est le plan d'épargne en actions : seuls les prélèvements sociaux après 5 ans
+	TaxWrapperPEA TaxWrapper = "pea"
+	// TaxWrapperLifeInsurance est l'assurance-vie : abattement et taux réduit après 8 ans
+	TaxWrapperLifeInsurance TaxWrapper = "assurance-vie"
+	// TaxWrapperExempt couvre les placements exonérés (livret A, LDDS, LEP)
+	TaxWrapperExempt TaxWrapper = "exonere"
+)
+
+// Paramètres de la fiscalité française des revenus du capital (%) et seuils associés
+const (
+	socialChargesRate        = 17.2   // Prélèvements sociaux
+	flatTaxIncomeRate        = 12.8   // Part impôt sur le revenu du PFU (30 % avec les prélèvements sociaux)
+	lifeInsuranceReducedRate = 7.5    // Impôt sur le revenu après 8 ans, primes jusqu'à 150 000 €
+	lifeInsurancePremiumCap  = 150000 // Primes éligibles au taux réduit
+	lifeInsuranceAllowance   = 4600   // Abattement annuel après 8 ans (le double pour un couple)
+	peaTaxFreeYears          = 5
+	lifeInsuranceTaxYears    = 8
+)
+
+// TaxSettings est la situation fiscale du compte
+type TaxSettings struct {
+	Wrapper TaxWrapper `json:"wrapper,omitempty"` // Enveloppe des investissements qui n'en précisent pas (compte-titres si vide)
+	Opened  string     `json:"opened,omitempty"`  // Date d'ouverture de l'enveloppe du compte (AAAA-MM-JJ), qui fait courir l'antériorité
+	Couple  bool       `json:"couple,omitempty"`  // Imposition commune : abattement de l'assurance-vie doublé
+}
+
+// TaxedValue est la valeur projetée d'un investissement avant et après impôts, en devise
+// de consolidation
+type TaxedValue struct {
+	Name     string
+	Wrapper  TaxWrapper
+	Years    float64 // Antériorité de l'enveloppe à la date de projection
+	Gross    float64 // Valeur brute
+	Invested float64 // Capital net investi, versements programmés compris
+	Gain     float64
+	Tax      float64
+	Net      float64 // Valeur nette d'impôts en cas de retrait total
+}
+
+// validTaxWrapper indique si w est une enveloppe connue (vide compris)
+func validTaxWrapper(w TaxWrapper) bool {
+	switch w {
+	case "", TaxWrapperCTO, TaxWrapperPEA, TaxWrapperLifeInsurance, TaxWrapperExempt:
+		return true
+	}
+	return false
+}
+
+// SetTaxWrapper définit l'enveloppe fiscale d'un investissement ; une enveloppe vide
+// revient à celle du compte
+func (p *Portfolio) SetTaxWrapper(investmentName string, wrapper TaxWrapper) error {
+	if !validTaxWrapper(wrapper) {
+		return InvalidField("wrapper", wrapper, "enveloppe fiscale inconnue: %s", wrapper)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[investmentName]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", investmentName, ErrInvestmentNotFound)
+	}
+	before := inv.clone()
+	inv.TaxWrapper = wrapper
+	p.record(OpSetTaxWrapper, investmentName, string(wrapper), before)
+	return nil
+}
+
+// SetTaxSettings définit la situation fiscale du compte
+func (p *Portfolio) SetTaxSettings(settings TaxSettings) error {
+	if !validTaxWrapper(settings.Wrapper) {
+		return InvalidField("wrapper", settings.Wrapper, "enveloppe fiscale inconnue: %s", settings.Wrapper)
+	}
+	if settings.Opened != "" {
+		if _, err := ParseDate(settings.Opened); err != nil {
+			return err
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before, err := p.settingsState("tax")
+	if err != nil {
+		return err
+	}
+
+	p.Tax = &settings
+	return p.recordChange(JournalEntry{Op: OpSetTaxSettings, Detail: string(settings.Wrapper), BeforeSettings: before})
+}
+
+// taxWrapper retourne l'enveloppe effective d'un investissement et la date à partir de
+// laquelle court son antériorité ; l'appelant doit détenir p.mu
+func (p *Portfolio) taxWrapper(inv *Investment) (TaxWrapper, time.Time) {
+	if inv.TaxWrapper != "" {
+		return inv.TaxWrapper, inv.InvestmentDate.Time
+	}
+	if p.Tax == nil || p.Tax.Wrapper == "" {
+		return TaxWrapperCTO, inv.InvestmentDate.Time
+	}
+	opened := inv.InvestmentDate
+	if t, err := ParseDate(p.Tax.Opened); err == nil && t.Before(opened.Time) {
+		opened = Date{t}
+	}
+	return p.Tax.Wrapper, opened.Time
+}
+
+// NetOfTaxProjection projette chaque investissement ouvert à une date et calcule l'impôt
+// dû sur le gain en cas de retrait total à cette date : PFU de 30 % sur un compte-titres
+// ou avant l'échéance fiscale de l'enveloppe, prélèvements sociaux seuls sur un PEA de
+// plus de 5 ans, et pour une assurance-vie de plus de 8 ans, prélèvements sociaux plus
+// 7,5 % (12,8 % sur la part des primes au-delà de 150 000 €) après un abattement réparti
+// entre les contrats au prorata de leurs gains. Les règles réelles comportent d'autres
+// cas (barème progressif, exonérations) : le résultat est une estimation.
+func (p *Portfolio) NetOfTaxProjection(date string) ([]TaxedValue, TaxedValue, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return nil, TaxedValue{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values, _, err := p.portfolioValue(t)
+	if err != nil {
+		return nil, TaxedValue{}, err
+	}
+
+	var lines []TaxedValue
+	var maturedLifeGains, lifePremiums float64
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		gross, open := values[name]
+		if !open {
+			continue
+		}
+		invested := inv.NetInvested()
+		if latest, err := inv.GetLatestNAV(); err == nil {
+			for _, c := range inv.PlannedContributions(latest.Date.Time, t) {
+				invested += c.Amount
+			}
+		}
+		investedBase, err := p.toBase(invested.Float64(), inv.Currency, t)
+		if err != nil {
+			return nil, TaxedValue{}, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+
+		wrapper, opened := p.taxWrapper(inv)
+		line := TaxedValue{
+			Name:     name,
+			Wrapper:  wrapper,
+			Years:    max(inv.conventions.YearsBetween(opened, t), 0),
+			Gross:    gross,
+			Invested: investedBase,
+			Gain:     gross - investedBase,
+		}
+		if wrapper == TaxWrapperLifeInsurance {
+			lifePremiums += investedBase
+			if line.Years >= lifeInsuranceTaxYears && line.Gain > 0 {
+				maturedLifeGains += line.Gain
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	allowance := float64(lifeInsuranceAllowance)
+	if p.Tax != nil && p.Tax.Couple {
+		allowance *= 2
+	}
+	// Part des primes d'assurance-vie éligible au taux réduit
+	reducedShare := 1.0
+	if lifePremiums > lifeInsurancePremiumCap {
+		reducedShare = lifeInsurancePremiumCap / lifePremiums
+	}
+
+	var total TaxedValue
+	for i := range lines {
+		line := &lines[i]
+		if line.Gain > 0 {
+			switch {
+			case line.Wrapper == TaxWrapperExempt:
+			case line.Wrapper == TaxWrapperPEA && line.Years >= peaTaxFreeYears:
+				line.Tax = line.Gain * socialChargesRate / 100
+			case line.Wrapper == TaxWrapperLifeInsurance && line.Years >= lifeInsuranceTaxYears:
+				taxable := max(line.Gain-allowance*line.Gain/max(maturedLifeGains, allowance), 0)
+				incomeRate := reducedShare*lifeInsuranceReducedRate + (1-reducedShare)*flatTaxIncomeRate
+				line.Tax = line.Gain*socialChargesRate/100 + taxable*incomeRate/100
+			default:
+				line.Tax = line.Gain * (flatTaxIncomeRate + socialChargesRate) / 100
+			}
+		}
+		line.Tax = math.Round(line.Tax*100) / 100
+		line.Net = line.Gross - line.Tax
+
+		total.Gross += line.Gross
+		total.Invested += line.Invested
+		total.Gain += line.Gain
+		total.Tax += line.Tax
+		total.Net += line.Net
+	}
+	return lines, total, nil
+}
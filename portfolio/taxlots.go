@@ -0,0 +1,179 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// CostMethod est la méthode d'affectation du prix de revient aux parts vendues
+type CostMethod string
+
+const (
+	// CostAverage retient le prix moyen pondéré des parts détenues, méthode imposée en
+	// France pour les valeurs mobilières
+	CostAverage CostMethod = "average"
+	// CostFIFO sort les parts dans leur ordre d'acquisition (premier entré, premier sorti)
+	CostFIFO CostMethod = "fifo"
+)
+
+// TaxLot est un lot de parts acquises ensemble et encore détenues
+type TaxLot struct {
+	Acquired time.Time // Date d'acquisition ; nulle pour le lot unique du prix moyen pondéré
+	Units    Quantity
+	Cost     Money // Prix de revient du lot, frais d'achat inclus
+}
+
+// RealizedGain est la plus ou moins-value d'une vente
+type RealizedGain struct {
+	Investment string
+	Date       time.Time
+	Currency   Currency
+	Units      Quantity
+	Proceeds   Money     // Prix de cession net des frais de vente
+	Cost       Money     // Prix de revient des parts vendues
+	Gain       Money     // Proceeds - Cost
+	Acquired   time.Time // Acquisition du plus ancien lot vendu (FIFO uniquement)
+}
+
+// GainsReport récapitule les cessions d'une année, en devise de consolidation, pour
+// préparer la déclaration des plus-values (formulaire 2074)
+type GainsReport struct {
+	Year     int       // Année de référence, désignée par son année de début
+	Period   YearStart // Début de l'année de référence (civile si vide)
+	Method   CostMethod
+	Currency Currency
+	Sales    []RealizedGain // Montants convertis au taux du jour de la vente, triées par date
+	Proceeds Money
+	Cost     Money
+	Gains    Money // Somme des plus-values
+	Losses   Money // Somme des moins-values (négative)
+	Net      Money // Gains + Losses
+}
+
+// TaxLots rejoue le registre des transactions selon la méthode et retourne les lots
+// encore détenus et les cessions réalisées
+func (inv *Investment) TaxLots(method CostMethod) ([]TaxLot, []RealizedGain, error) {
+	if method != CostAverage && method != CostFIFO {
+		return nil, nil, InvalidField("method", method, "méthode de prix de revient inconnue: %s", method)
+	}
+
+	var lots []TaxLot
+	var sales []RealizedGain
+	for _, tx := range inv.ledger() {
+		if tx.Type == Buy {
+			lot := TaxLot{Acquired: tx.Date.Time, Units: tx.Units, Cost: tx.Amount() + tx.Fees}
+			if method == CostAverage && len(lots) > 0 {
+				lots[0].Units += lot.Units
+				lots[0].Cost += lot.Cost
+				continue
+			}
+			if method == CostAverage {
+				lot.Acquired = time.Time{}
+			}
+			lots = append(lots, lot)
+			continue
+		}
+
+		sale := RealizedGain{
+			Investment: inv.Name,
+			Date:       tx.Date.Time,
+			Currency:   inv.EffectiveCurrency(),
+			Units:      tx.Units,
+			Proceeds:   tx.Amount() - tx.Fees,
+		}
+		remaining := tx.Units
+		for remaining > 0 && len(lots) > 0 {
+			lot := &lots[0]
+			if sale.Acquired.IsZero() {
+				sale.Acquired = lot.Acquired
+			}
+			sold := min(remaining, lot.Units)
+			cost := lot.Cost.Mul(sold.Float64() / lot.Units.Float64())
+			sale.Cost += cost
+			lot.Cost -= cost
+			lot.Units -= sold
+			remaining -= sold
+			if lot.Units <= 0 {
+				lots = lots[1:]
+			}
+		}
+		if remaining > 0 {
+			return nil, nil, fmt.Errorf("vente de %s parts non détenues au %s: %w", remaining, FormatDate(tx.Date.Time), ErrInvalidAmount)
+		}
+		sale.Gain = sale.Proceeds - sale.Cost
+		sales = append(sales, sale)
+	}
+	return lots, sales, nil
+}
+
+// RealizedGainsReport récapitule les cessions de l'année de référence (civile sauf
+// configuration year_start) de tous les investissements
+func (p *Portfolio) RealizedGainsReport(year int, method CostMethod) (*GainsReport, error) {
+	return p.RealizedGainsReportFrom(year, ReportingYear(), method)
+}
+
+// RealizedGainsReportFrom récapitule les cessions de l'année year commençant à ys
+func (p *Portfolio) RealizedGainsReportFrom(year int, ys YearStart, method CostMethod) (*GainsReport, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	r := &GainsReport{Year: year, Period: ys, Method: method, Currency: p.baseCurrency(), Sales: []RealizedGain{}}
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		_, sales, err := inv.TaxLots(method)
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		for _, sale := range sales {
+			if ys.YearOf(sale.Date) != year {
+				continue
+			}
+			rate, err := p.toBase(1, inv.Currency, sale.Date)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			sale.Currency = r.Currency
+			sale.Proceeds = sale.Proceeds.Mul(rate).RoundCents()
+			sale.Cost = sale.Cost.Mul(rate).RoundCents()
+			sale.Gain = sale.Proceeds - sale.Cost
+			r.Sales = append(r.Sales, sale)
+		}
+	}
+	sort.SliceStable(r.Sales, func(i, j int) bool { return r.Sales[i].Date.Before(r.Sales[j].Date) })
+
+	for _, sale := range r.Sales {
+		r.Proceeds += sale.Proceeds
+		r.Cost += sale.Cost
+		if sale.Gain >= 0 {
+			r.Gains += sale.Gain
+		} else {
+			r.Losses += sale.Gain
+		}
+	}
+	r.Net = r.Gains + r.Losses
+	return r, nil
+}
+
+// WriteCSV écrit une ligne par cession
+func (r *GainsReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "investment", "units", "proceeds", "cost", "gain", "acquired"}); err != nil {
+		return err
+	}
+	for _, s := range r.Sales {
+		acquired := ""
+		if !s.Acquired.IsZero() {
+			acquired = FormatDate(s.Acquired)
+		}
+		record := []string{FormatDate(s.Date), s.Investment, s.Units.String(),
+			s.Proceeds.String(), s.Cost.String(), s.Gain.String(), acquired}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,154 @@
+package portfolio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaxLots(t *testing.T) {
+	jan, mar := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		method   CostMethod
+		saleCost string
+		gain     string
+		acquired time.Time
+		lot      TaxLot
+	}{
+		{
+			name:     "FIFO",
+			method:   CostFIFO,
+			saleCost: "1320.80",
+			gain:     "1075.20",
+			acquired: jan,
+			lot:      TaxLot{Acquired: mar, Units: NewQuantity(30), Cost: NewMoney(481.20)},
+		},
+		{
+			name:     "prix moyen pondéré",
+			method:   CostAverage,
+			saleCost: "1441.60",
+			gain:     "954.40",
+			lot:      TaxLot{Units: NewQuantity(30), Cost: NewMoney(360.40)},
+		},
+	}
+
+	// 100 parts à 10, complétées de 50 parts à 16 (2 de frais) puis allégées de 120
+	// parts à 20 (4 de frais)
+	p := NewPortfolio()
+	if err := p.AddInvestmentWithQuantity("A", 100, 10, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddTransaction("A", "2024-03-01", Buy, 50, 16, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddTransaction("A", "2024-09-01", Sell, 120, 20, 4); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := p.Investment("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lots, sales, err := inv.TaxLots(tt.method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sales) != 1 {
+				t.Fatalf("%d cessions, 1 attendue", len(sales))
+			}
+			sale := sales[0]
+			if got := sale.Proceeds.String(); got != "2396.00" {
+				t.Errorf("prix de cession %s, 2396.00 attendu", got)
+			}
+			if got := sale.Cost.String(); got != tt.saleCost {
+				t.Errorf("prix de revient %s, %s attendu", got, tt.saleCost)
+			}
+			if got := sale.Gain.String(); got != tt.gain {
+				t.Errorf("plus-value %s, %s attendu", got, tt.gain)
+			}
+			if !sale.Acquired.Equal(tt.acquired) {
+				t.Errorf("acquisition %v, %v attendue", sale.Acquired, tt.acquired)
+			}
+			if len(lots) != 1 || lots[0] != tt.lot {
+				t.Errorf("lots %+v, [%+v] attendu", lots, tt.lot)
+			}
+		})
+	}
+}
+
+// Un lot de jetons à quelques cent-millièmes d'unité ne doit pas être arrondi à zéro
+func TestTaxLotsSubCentPrices(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.AddInvestmentWithQuantity("C", 50000000, 0.00002, 0, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddTransaction("C", "2024-02-01", Buy, 100000000, 0.000024, 0); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := p.Investment("C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lots, _, err := inv.TaxLots(CostFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaxLot{
+		{Acquired: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Units: NewQuantity(50000000), Cost: NewMoney(1000)},
+		{Acquired: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Units: NewQuantity(100000000), Cost: NewMoney(2400)},
+	}
+	if len(lots) != len(want) {
+		t.Fatalf("lots %+v, %+v attendu", lots, want)
+	}
+	for i := range want {
+		if lots[i] != want[i] {
+			t.Errorf("lot %d: %+v, %+v attendu", i, lots[i], want[i])
+		}
+	}
+}
+
+func TestTaxLotsErrors(t *testing.T) {
+	// 100 parts à 10, complétées de 50 parts à 16 (2 de frais) puis allégées de 120
+	// parts à 20 (4 de frais)
+	p := NewPortfolio()
+	if err := p.AddInvestmentWithQuantity("A", 100, 10, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddTransaction("A", "2024-03-01", Buy, 50, 16, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddTransaction("A", "2024-09-01", Sell, 120, 20, 4); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := p.Investment("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var validation *ValidationError
+	if _, _, err := inv.TaxLots("lifo"); !errors.As(err, &validation) {
+		t.Errorf("méthode inconnue: erreur %v, ValidationError attendue", err)
+	}
+
+	tests := []struct {
+		name  string
+		tx    TransactionType
+		units float64
+		price float64
+		fees  float64
+	}{
+		{name: "prix sous le cent-millionième", tx: Buy, units: 1, price: 0.000000001},
+		{name: "parts nulles", tx: Buy, units: 0, price: 10},
+		{name: "frais négatifs", tx: Buy, units: 1, price: 10, fees: -1},
+		{name: "vente de parts non détenues", tx: Sell, units: 31, price: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.AddTransaction("A", "2024-10-01", tt.tx, tt.units, tt.price, tt.fees)
+			if !errors.Is(err, ErrInvalidAmount) {
+				t.Errorf("erreur %v, %v attendu", err, ErrInvalidAmount)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// TrendFit est l'ajustement d'une tendance exponentielle à l'historique des NAV
+type TrendFit struct {
+	Rate       float64 `json:"rate"`        // Taux annuel de la tendance (%)
+	RSquared   float64 `json:"r_squared"`   // Coefficient de détermination (1 : ajustement parfait)
+	SlopeError float64 `json:"slope_error"` // Erreur type de la pente, en points de rendement logarithmique annuel (%)
+	Points     int     `json:"points"`      // Nombre de NAV utilisées
+}
+
+// TrendRate estime le taux annuel par régression log-linéaire sur toutes les NAV :
+// ln(indice) = a + b·t, l'indice étant la performance corrigée des flux. Contrairement
+// à CalculatePerformanceRate, une NAV extrême en début ou fin d'historique pèse peu.
+func (inv *Investment) TrendRate() (TrendFit, error) {
+	index := analytics.PerformanceIndex(inv.periodReturns())
+	if len(index) < 3 {
+		return TrendFit{}, fmt.Errorf("au moins 3 NAV sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	origin := index.First()
+	n := float64(len(index))
+	var meanT, meanY float64
+	for _, point := range index {
+		meanT += inv.conventions.YearsBetween(origin, point.Date)
+		meanY += point.Value
+	}
+	meanT /= n
+	meanY /= n
+
+	var sxx, sxy, syy float64
+	for _, point := range index {
+		dt := inv.conventions.YearsBetween(origin, point.Date) - meanT
+		dy := point.Value - meanY
+		sxx += dt * dt
+		sxy += dt * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return TrendFit{}, fmt.Errorf("l'intervalle de temps doit être positif")
+	}
+
+	slope := sxy / sxx
+	residuals := syy - slope*sxy
+	fit := TrendFit{
+		Rate:       inv.conventions.RateFromLog(slope),
+		RSquared:   1,
+		SlopeError: math.Sqrt(math.Max(residuals, 0)/(n-2)/sxx) * 100,
+		Points:     len(index),
+	}
+	if syy > 0 {
+		fit.RSquared = 1 - residuals/syy
+	}
+	return fit, nil
+}
@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// TurnoverYear mesure l'activité d'une année de référence, en devise de consolidation
+type TurnoverYear struct {
+	Year         int
+	Buys         Money   // Achats, hors réinvestissements de distributions
+	Sells        Money   // Ventes
+	Trades       int     // Nombre d'achats et de ventes, hors réinvestissements
+	Reinvested   int     // Achats financés par une distribution réinvestie
+	Costs        Money   // Frais de transaction, réinvestissements compris
+	AverageValue float64 // Valeur moyenne du portefeuille, relevée au début de chaque mois
+	Turnover     float64 // Rotation (%) : le plus petit des achats et des ventes rapporté à la valeur moyenne
+}
+
+// TurnoverReport récapitule la rotation du portefeuille année par année, d'après le
+// registre des transactions
+type TurnoverReport struct {
+	Period   YearStart
+	Currency Currency
+	Years    []TurnoverYear // Par année croissante, de la première transaction à aujourd'hui
+}
+
+// Turnover calcule la rotation de chaque année de référence (civile sauf configuration
+// year_start) depuis la première transaction. Les achats et ventes sont convertis au
+// taux du jour de la transaction. Prendre le plus petit des achats et des ventes écarte
+// les apports et retraits : un portefeuille seulement alimenté ne tourne pas.
+func (p *Portfolio) Turnover() (*TurnoverReport, error) {
+	ys := ReportingYear()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	r := &TurnoverReport{Period: ys, Currency: p.baseCurrency()}
+	years := make(map[int]*TurnoverYear)
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+		for _, tx := range inv.ledger() {
+			year := ys.YearOf(tx.Date.Time)
+			y, ok := years[year]
+			if !ok {
+				y = &TurnoverYear{Year: year}
+				years[year] = y
+			}
+			rate, err := p.toBase(1, inv.Currency, tx.Date.Time)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			y.Costs += tx.Fees.Mul(rate).RoundCents()
+			amount := tx.Amount().Mul(rate).RoundCents()
+			switch {
+			case tx.Reinvested:
+				y.Reinvested++
+			case tx.Type == Sell:
+				y.Sells += amount
+				y.Trades++
+			default:
+				y.Buys += amount
+				y.Trades++
+			}
+		}
+	}
+	if len(years) == 0 {
+		return nil, fmt.Errorf("aucune transaction enregistrée: %w", ErrInsufficientHistory)
+	}
+
+	first := ys.YearOf(Today())
+	for year := range years {
+		first = min(first, year)
+	}
+	for year := first; year <= ys.YearOf(Today()); year++ {
+		y, ok := years[year]
+		if !ok {
+			y = &TurnoverYear{Year: year}
+		}
+		average, err := p.averageValue(ys.Start(year), ys.Start(year+1))
+		if err != nil {
+			return nil, err
+		}
+		y.AverageValue = average
+		if average > 0 {
+			y.Turnover = min(y.Buys, y.Sells).Float64() / average * 100
+		}
+		r.Years = append(r.Years, *y)
+	}
+	return r, nil
+}
+
+// averageValue retourne la valeur moyenne du portefeuille entre start et end, relevée au
+// début de chaque mois jusqu'à aujourd'hui d'après les NAV connues ; l'appelant doit
+// détenir p.mu
+func (p *Portfolio) averageValue(start, end time.Time) (float64, error) {
+	var total float64
+	var n int
+	for t := start; t.Before(end) && !t.After(Today()); t = t.AddDate(0, 1, 0) {
+		for name, inv := range p.Investments {
+			value, _, err := p.investmentValueAt(inv, t)
+			if err != nil {
+				return 0, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			total += value
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return total / float64(n), nil
+}
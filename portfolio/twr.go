@@ -0,0 +1,34 @@
+package portfolio
+
+import (
+	"fmt"
+)
+
+// TimeWeightedReturn calcule le rendement pondéré par le temps (%, non annualisé)
+// entre from et to : les rendements des sous-périodes séparant deux NAV successives,
+// corrigés des apports et retraits, sont chaînés de sorte que le calendrier des
+// versements n'influence pas le résultat. Seules les NAV datées dans [from, to]
+// sont utilisées ; une borne vide signifie « sans limite ».
+func (inv *Investment) TimeWeightedReturn(from, to string) (float64, error) {
+	start, end, err := ParsePeriod(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var navs []NAV
+	for _, nav := range inv.NAVHistory {
+		if (from == "" || !nav.Date.Before(start)) && (to == "" || !nav.Date.After(end)) {
+			navs = append(navs, nav)
+		}
+	}
+	if len(navs) < 2 {
+		return 0, fmt.Errorf("au moins 2 NAV sont nécessaires entre %s et %s: %w", from, to, ErrInsufficientHistory)
+	}
+
+	growth := 1.0
+	for i := 1; i < len(navs); i++ {
+		growth *= 1 + inv.flowAdjustedReturn(navs[i-1], navs[i])
+	}
+
+	return (growth - 1) * 100, nil
+}
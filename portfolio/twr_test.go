@@ -0,0 +1,111 @@
+package portfolio
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTimeWeightedReturn(t *testing.T) {
+	// A vaut 1000 puis 2100 au 1er juillet 2024, jour d'un apport de 1000, et 2310 au
+	// 1er janvier 2025 : +10 % sur chaque semestre. B reçoit un apport de 100 au milieu
+	// de sa seule période.
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-01-01", 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddCashFlow("A", "2024-07-01", 1000, Contribution); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-07-01", 2100); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2025-01-01", 2310); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddInvestment("B", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("B", "2024-01-01", 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddCashFlow("B", "2024-01-16", 100, Contribution); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("B", "2024-01-31", 1200); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		investment string
+		from, to   string
+		want       float64
+	}{
+		{name: "historique complet", investment: "A", want: 21},
+		{name: "apport le jour d'une NAV", investment: "A", to: "2024-07-01", want: 10},
+		{name: "fenêtre ouverte sur l'apport", investment: "A", from: "2024-07-01", want: 10},
+		{name: "apport en milieu de période", investment: "B", want: 100.0 / 1050 * 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := p.Investment(tt.investment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := inv.TimeWeightedReturn(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TWR %.6f%%, %.6f%% attendu", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeWeightedReturnInsufficientHistory(t *testing.T) {
+	p := NewPortfolio()
+	if err := p.AddInvestment("A", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-01-01", 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-07-01", 1050); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2025-01-01", 1100); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddInvestment("C", 1000, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("C", "2024-01-01", 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		investment string
+		from, to   string
+	}{
+		{name: "une seule NAV", investment: "C"},
+		{name: "fenêtre d'une seule NAV", investment: "A", from: "2024-06-01", to: "2024-12-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := p.Investment(tt.investment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := inv.TimeWeightedReturn(tt.from, tt.to); !errors.Is(err, ErrInsufficientHistory) {
+				t.Errorf("erreur %v, %v attendu", err, ErrInsufficientHistory)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultMaxNAVJump est la variation maximale (%) entre deux NAV successives, corrigée
+// des flux, au-delà de laquelle Validate signale un saut suspect
+const DefaultMaxNAVJump = 50.0
+
+// ValidationIssue est une incohérence des données du portefeuille relevée par Validate
+type ValidationIssue struct {
+	Investment string    // Investissement concerné, vide pour le portefeuille
+	Field      string    // Donnée en cause (nav_history, currency, isin...)
+	Date       time.Time // Date de la donnée en cause, nulle si sans objet
+	Message    string
+}
+
+func (i ValidationIssue) String() string {
+	var b strings.Builder
+	if i.Investment != "" {
+		fmt.Fprintf(&b, "%s: ", i.Investment)
+	}
+	b.WriteString(i.Field)
+	if !i.Date.IsZero() {
+		fmt.Fprintf(&b, " au %s", FormatDate(i.Date))
+	}
+	fmt.Fprintf(&b, ": %s", i.Message)
+	return b.String()
+}
+
+// Validate relève toutes les incohérences des données : NAV antérieures à la date
+// d'investissement, non triées, nulles ou variant de plus de maxJump % (corrigé des
+// flux, DefaultMaxNAVJump si nul) d'une NAV à la suivante ; flux antérieurs à
+// l'investissement ; devise invalide ou sans taux de change vers la devise de
+// consolidation ; identifiants en double. Les investissements sont parcourus par nom.
+func (p *Portfolio) Validate(maxJump float64) []ValidationIssue {
+	if maxJump <= 0 {
+		maxJump = DefaultMaxNAVJump
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var issues []ValidationIssue
+	add := func(name, field string, date time.Time, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Investment: name, Field: field, Date: date, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Identifiants déjà rencontrés, par type, avec l'investissement qui les porte
+	seen := map[string]map[string]string{"id": {}, "isin": {}, "ticker": {}, "identifier": {}}
+	unique := func(name, field, value string) {
+		if value == "" {
+			return
+		}
+		if other, dup := seen[field][value]; dup {
+			add(name, field, time.Time{}, "%s est aussi celui de '%s'", value, other)
+			return
+		}
+		seen[field][value] = name
+	}
+
+	for _, name := range p.sortedInvestmentNames() {
+		inv := p.Investments[name]
+
+		for i, nav := range inv.NAVHistory {
+			if nav.Date.Before(inv.InvestmentDate.Time) {
+				add(name, "nav_history", nav.Date.Time, "NAV antérieure à la date d'investissement (%s)", FormatDate(inv.InvestmentDate.Time))
+			}
+			if nav.Value <= 0 && inv.Exposure != ExposureSigned {
+				add(name, "nav_history", nav.Date.Time, "NAV nulle ou négative (%.2f)", nav.Value.Float64())
+			}
+			if i == 0 {
+				continue
+			}
+			prev := inv.NAVHistory[i-1]
+			if !nav.Date.After(prev.Date.Time) {
+				add(name, "nav_history", nav.Date.Time, "NAV non triée ou en double (précédente au %s)", FormatDate(prev.Date.Time))
+				continue
+			}
+			if prev.Value <= 0 {
+				continue
+			}
+			if r := inv.flowAdjustedReturn(prev, nav) * 100; math.Abs(r) > maxJump {
+				add(name, "nav_history", nav.Date.Time, "variation de %+.1f%% depuis la NAV du %s (seuil %.0f%%)", r, FormatDate(prev.Date.Time), maxJump)
+			}
+		}
+		for _, cf := range inv.CashFlows {
+			if cf.Date.Before(inv.InvestmentDate.Time) {
+				add(name, "cash_flows", cf.Date.Time, "flux antérieur à la date d'investissement (%s)", FormatDate(inv.InvestmentDate.Time))
+			}
+		}
+		if inv.Closed && inv.ClosedDate.Before(inv.InvestmentDate.Time) {
+			add(name, "closed_date", inv.ClosedDate.Time, "clôture antérieure à la date d'investissement")
+		}
+
+		if c := string(inv.Currency); c != "" && (len(c) != 3 || strings.ToUpper(c) != c) {
+			add(name, "currency", time.Time{}, "code de devise invalide: %s", c)
+		} else if latest, err := inv.GetLatestNAV(); err == nil {
+			if _, err := p.toBase(latest.Value.Float64(), inv.Currency, latest.Date.Time); err != nil {
+				add(name, "currency", latest.Date.Time, "%v", err)
+			}
+		}
+
+		unique(name, "id", inv.ID)
+		unique(name, "isin", inv.ISIN)
+		if inv.Ticker != "" {
+			unique(name, "ticker", strings.TrimSuffix(inv.Ticker+"@"+inv.MIC, "@"))
+		}
+		unique(name, "identifier", inv.Identifier)
+	}
+	return issues
+}
@@ -0,0 +1,161 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+	"github.com/davidsportes-ship-it/david/timeseries"
+)
+
+// Classes d'actifs reconnues par les tests de résistance prédéfinis (valeurs de l'étiquette TagAssetClass)
+const (
+	AssetEquity      = "actions"
+	AssetBond        = "obligations"
+	AssetRealEstate  = "immobilier"
+	AssetCommodity   = "matieres-premieres"
+	AssetMoneyMarket = "monetaire"
+)
+
+// VaRResult est la perte potentielle du portefeuille sur un horizon, qui ne devrait
+// être dépassée qu'avec une probabilité 1 - confiance
+type VaRResult struct {
+	Value      float64 `json:"value"`      // Valeur actuelle du portefeuille (dernières NAV)
+	Historical float64 `json:"historical"` // VaR historique : quantile des rendements observés sur l'horizon
+	Parametric float64 `json:"parametric"` // VaR paramétrique : loi log-normale de rendement et volatilité historiques
+}
+
+// StressScenario applique un choc (%) à chaque classe d'actifs ; Default s'applique aux
+// investissements dont la classe n'est pas listée
+type StressScenario struct {
+	Name    string             `json:"name"`
+	Shocks  map[string]float64 `json:"shocks"`
+	Default float64            `json:"default,omitempty"`
+}
+
+// StressResult est l'effet d'un test de résistance sur le portefeuille
+type StressResult struct {
+	Scenario     string             `json:"scenario"`
+	Value        float64            `json:"value"`         // Valeur avant choc
+	Loss         float64            `json:"loss"`          // Perte (positive) sous le choc
+	LossPercent  float64            `json:"loss_percent"`  // Perte en % de la valeur
+	ByInvestment map[string]float64 `json:"by_investment"` // Perte de chaque investissement
+}
+
+// DefaultStressScenarios sont les tests de résistance prédéfinis, inspirés de crises passées
+var DefaultStressScenarios = []StressScenario{
+	{
+		Name:   "krach-2008",
+		Shocks: map[string]float64{AssetEquity: -45, AssetRealEstate: -30, AssetCommodity: -35, AssetBond: 5, AssetMoneyMarket: 0},
+		// Classe inconnue : traitée comme un actif risqué diversifié
+		Default: -25,
+	},
+	{
+		Name:    "choc-de-taux",
+		Shocks:  map[string]float64{AssetEquity: -10, AssetRealEstate: -20, AssetCommodity: 0, AssetBond: -15, AssetMoneyMarket: 0},
+		Default: -10,
+	},
+	{
+		Name:    "covid-2020",
+		Shocks:  map[string]float64{AssetEquity: -34, AssetRealEstate: -25, AssetCommodity: -30, AssetBond: -2, AssetMoneyMarket: 0},
+		Default: -20,
+	},
+}
+
+// varWindowStep est le décalage entre deux fenêtres de la VaR historique
+const varWindowStep = 24 * time.Hour
+
+// VaR calcule la valeur en risque du portefeuille à un niveau de confiance (ex. 0.95)
+// sur un horizon, par les méthodes historique et paramétrique. La VaR historique
+// parcourt les fenêtres de durée horizon de l'indice de performance du portefeuille.
+func (p *Portfolio) VaR(confidence float64, horizon time.Duration) (VaRResult, error) {
+	if err := validateConfidence(confidence); err != nil {
+		return VaRResult{}, err
+	}
+	if horizon <= 0 {
+		return VaRResult{}, InvalidField("horizon", horizon, "l'horizon doit être positif")
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, asOf := p.historyBounds()
+	value, complete, err := p.lastKnownValue(asOf)
+	if err != nil {
+		return VaRResult{}, err
+	}
+	if !complete || value <= 0 {
+		return VaRResult{}, fmt.Errorf("la valeur du portefeuille est inconnue: %w", ErrInsufficientHistory)
+	}
+	returns, err := p.alignedReturns(time.Time{}, time.Time{})
+	if err != nil {
+		return VaRResult{}, err
+	}
+	index := analytics.PerformanceIndex(returns)
+	if len(index) < 3 {
+		return VaRResult{}, fmt.Errorf("au moins 3 dates de valorisation sont nécessaires: %w", ErrInsufficientHistory)
+	}
+
+	// Historique : quantile des rendements logarithmiques sur toutes les fenêtres glissantes
+	windowReturns := timeseries.Rolling(index, horizon, varWindowStep, func(start, end time.Time) float64 {
+		growth, _ := timeseries.Change(index, start, end)
+		return growth
+	})
+	if len(windowReturns) == 0 {
+		return VaRResult{}, fmt.Errorf("l'historique est plus court que l'horizon: %w", ErrInsufficientHistory)
+	}
+	return VaRResult{
+		Value:      value,
+		Historical: analytics.HistoricalVaR(windowReturns, confidence) * value,
+		Parametric: analytics.ParametricVaR(returns, confidence, horizon.Hours()/24/365.25) * value,
+	}, nil
+}
+
+// StressTest applique un scénario de choc aux dernières valeurs connues des
+// investissements ouverts, selon leur classe d'actifs
+func (p *Portfolio) StressTest(scenario StressScenario) (StressResult, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, asOf := p.historyBounds()
+	result := StressResult{Scenario: scenario.Name, ByInvestment: make(map[string]float64)}
+	for name, inv := range p.Investments {
+		if inv.Closed {
+			continue
+		}
+		latestNAV, err := inv.GetLatestNAV()
+		if err != nil {
+			return StressResult{}, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		value, err := p.toBase(latestNAV.Value.Float64(), inv.Currency, asOf)
+		if err != nil {
+			return StressResult{}, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+
+		shock, listed := scenario.Shocks[inv.Tags[TagAssetClass]]
+		if !listed {
+			shock = scenario.Default
+		}
+		loss := -value * shock / 100
+		result.Value += value
+		result.Loss += loss
+		result.ByInvestment[name] = loss
+	}
+	if result.Value > 0 {
+		result.LossPercent = result.Loss / result.Value * 100
+	}
+	return result, nil
+}
+
+// StressTests applique chacun des tests de résistance prédéfinis
+func (p *Portfolio) StressTests() ([]StressResult, error) {
+	results := make([]StressResult, 0, len(DefaultStressScenarios))
+	for _, scenario := range DefaultStressScenarios {
+		result, err := p.StressTest(scenario)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,122 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// VestingSchedule décrit l'acquisition progressive de titres attribués par l'employeur
+// (actions gratuites, RSU, stock-options) : rien n'est acquis avant la fin de la période
+// de blocage (cliff), puis les titres sont acquis par tranches égales tous les Every mois
+// jusqu'à Months mois après l'attribution. Seule la part acquise compte dans la valeur
+// du portefeuille.
+type VestingSchedule struct {
+	Grant  Date `json:"grant"`  // Date d'attribution
+	Cliff  int  `json:"cliff"`  // Période de blocage (mois)
+	Every  int  `json:"every"`  // Périodicité des acquisitions (mois)
+	Months int  `json:"months"` // Durée totale d'acquisition (mois)
+}
+
+// VestEvent est une acquisition de titres
+type VestEvent struct {
+	Date     time.Time
+	Fraction float64 // Part acquise cumulée après l'événement
+	Value    Money   // Valeur projetée de la part acquise lors de l'événement
+}
+
+// fractionAt retourne la part acquise à une date
+func (v *VestingSchedule) fractionAt(t time.Time) float64 {
+	months := 0
+	for !v.Grant.AddDate(0, months+1, 0).After(t) {
+		months++
+	}
+	switch {
+	case months < v.Cliff:
+		return 0
+	case months >= v.Months:
+		return 1
+	default:
+		return float64(months/v.Every*v.Every) / float64(v.Months)
+	}
+}
+
+// events retourne les dates d'acquisition du calendrier
+func (v *VestingSchedule) events() []time.Time {
+	var dates []time.Time
+	previous := 0.0
+	for m := v.Every; ; m += v.Every {
+		m = min(max(m, v.Cliff), v.Months)
+		date := v.Grant.AddDate(0, m, 0)
+		if fraction := v.fractionAt(date); fraction > previous {
+			dates = append(dates, date)
+			previous = fraction
+		}
+		if m >= v.Months {
+			return dates
+		}
+	}
+}
+
+// VestedFraction retourne la part acquise d'un investissement à une date (1 sans calendrier)
+func (inv *Investment) VestedFraction(t time.Time) float64 {
+	if inv.Vesting == nil {
+		return 1
+	}
+	return inv.Vesting.fractionAt(t)
+}
+
+// SetVesting attache un calendrier d'acquisition à un investissement ; une durée nulle
+// le supprime
+func (p *Portfolio) SetVesting(name, grant string, cliff, every, months int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inv, exists := p.Investments[name]
+	if !exists {
+		return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, ErrInvestmentNotFound)
+	}
+	if months == 0 {
+		before := inv.clone()
+		inv.Vesting = nil
+		p.record(OpSetVesting, name, "aucun calendrier", before)
+		return nil
+	}
+	t, err := ParseDate(grant)
+	if err != nil {
+		return err
+	}
+	if months < 0 || cliff < 0 || every <= 0 || cliff > months || every > months {
+		return InvalidField("months", months, "calendrier invalide: blocage %d, périodicité %d, durée %d mois", cliff, every, months)
+	}
+	before := inv.clone()
+	inv.Vesting = &VestingSchedule{Grant: Date{t}, Cliff: cliff, Every: every, Months: months}
+	p.record(OpSetVesting, name, fmt.Sprintf("attribution le %s sur %d mois", grant, months), before)
+	return nil
+}
+
+// VestEvents retourne les acquisitions postérieures à from, valorisées à la valeur
+// projetée de l'investissement à leur date
+func (inv *Investment) VestEvents(from time.Time) ([]VestEvent, error) {
+	if inv.Vesting == nil {
+		return nil, fmt.Errorf("'%s' n'a pas de calendrier d'acquisition", inv.Name)
+	}
+	rate, err := inv.ProjectionRate(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []VestEvent
+	for _, date := range inv.Vesting.events() {
+		if !date.After(from) {
+			continue
+		}
+		value, err := inv.projectNAVAtRate(date, rate)
+		if err != nil {
+			return nil, err
+		}
+		fraction := inv.Vesting.fractionAt(date)
+		vested := fraction - inv.Vesting.fractionAt(date.AddDate(0, 0, -1))
+		events = append(events, VestEvent{Date: date, Fraction: fraction, Value: NewMoney(value * vested).RoundCents()})
+	}
+	return events, nil
+}
@@ -0,0 +1,78 @@
+package portfolio
+
+import "testing"
+
+func TestUnvestedValueIsZero(t *testing.T) {
+	tests := []struct {
+		name  string
+		value func(p *Portfolio) (float64, error)
+	}{
+		{name: "GetPortfolioValue", value: func(p *Portfolio) (float64, error) {
+			_, total, err := p.GetPortfolioValue("2024-06-01")
+			return total, err
+		}},
+		{name: "Summary", value: func(p *Portfolio) (float64, error) {
+			s, err := p.Summary()
+			if err != nil {
+				return 0, err
+			}
+			return s.TotalValue, nil
+		}},
+		{name: "Query", value: func(p *Portfolio) (float64, error) {
+			results, err := p.Query(QueryOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return results[0].Value, nil
+		}},
+		{name: "ValueSeries", value: func(p *Portfolio) (float64, error) {
+			series, err := p.ValueSeries("2024-06-01", "2024-06-01", StepMonthly)
+			if err != nil {
+				return 0, err
+			}
+			return series[0].Total, nil
+		}},
+		{name: "NetWorth", value: func(p *Portfolio) (float64, error) {
+			nw, err := p.NetWorth("2024-06-01")
+			return nw.Assets, err
+		}},
+		{name: "DecomposeGrowth", value: func(p *Portfolio) (float64, error) {
+			d, err := p.DecomposeGrowth("2024-02-01", "2024-06-01")
+			if err != nil {
+				return 0, err
+			}
+			return d.Total.End, nil
+		}},
+		{name: "ValueRecomputed", value: func(p *Portfolio) (float64, error) {
+			events, unsubscribe := p.Subscribe(EventValueRecomputed)
+			defer unsubscribe()
+			if err := p.UpdateNAV("R", "2024-06-01", 1060); err != nil {
+				return 0, err
+			}
+			return (<-events).(ValueRecomputed).Value, nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPortfolio()
+			if err := p.AddInvestment("R", 1000, 5, "2024-01-01"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.AddNAV("R", "2024-06-01", 1050); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SetVesting("R", "2024-01-01", 12, 12, 48); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := tt.value(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != 0 {
+				t.Errorf("valeur %.2f, 0 attendu avant la fin de la période de blocage", got)
+			}
+		})
+	}
+}
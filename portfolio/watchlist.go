@@ -0,0 +1,168 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WatchedInstrument est un titre suivi sans être détenu : son historique de cours
+// alimente les filtres de performance (voir QueryOptions.IncludeWatchlist) et la mise à
+// jour des cours, mais jamais les valorisations du portefeuille
+type WatchedInstrument struct {
+	Name       string            `json:"name"`
+	Identifier string            `json:"identifier,omitempty"` // ISIN ou ticker interrogé par RefreshNAVs
+	Currency   Currency          `json:"currency,omitempty"`   // Devise de cotation (EUR si vide)
+	Tags       map[string]string `json:"tags,omitempty"`
+	History    []NAV             `json:"history"` // Cours unitaires, triés par date
+}
+
+// investment retourne un investissement fictif d'une part achetée au premier cours, pour
+// mesurer rendement et drawdown comme ceux des investissements détenus
+func (w *WatchedInstrument) investment() *Investment {
+	inv := &Investment{Name: w.Name, Currency: w.Currency, Tags: w.Tags, NAVHistory: w.History, Quantity: NewQuantity(1)}
+	if len(w.History) > 0 {
+		inv.InvestmentDate, inv.AmountInvested = w.History[0].Date, w.History[0].Value
+	}
+	return inv
+}
+
+// Watch ajoute un titre à la liste de suivi, ou met à jour son identifiant et sa devise
+func (p *Portfolio) Watch(name, identifier string, currency Currency) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("le nom du titre suivi ne peut pas être vide")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, held := p.Investments[name]; held {
+		return fmt.Errorf("'%s' est un investissement du portefeuille: %w", name, ErrInvestmentExists)
+	}
+	before, err := p.settingsState("watchlist")
+	if err != nil {
+		return err
+	}
+	if p.Watchlist == nil {
+		p.Watchlist = make(map[string]*WatchedInstrument)
+	}
+	w, exists := p.Watchlist[name]
+	if !exists {
+		w = &WatchedInstrument{Name: name}
+		p.Watchlist[name] = w
+	}
+	w.Identifier, w.Currency = identifier, currency
+	return p.recordChange(JournalEntry{Op: OpWatch, Detail: name, BeforeSettings: before})
+}
+
+// Unwatch retire un titre de la liste de suivi, avec son historique
+func (p *Portfolio) Unwatch(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.Watchlist[name]; !exists {
+		return fmt.Errorf("le titre suivi '%s' n'existe pas: %w", name, ErrNotFound)
+	}
+	before, err := p.settingsState("watchlist")
+	if err != nil {
+		return err
+	}
+	delete(p.Watchlist, name)
+	return p.recordChange(JournalEntry{Op: OpUnwatch, Detail: name, BeforeSettings: before})
+}
+
+// AddWatchPrice enregistre le cours d'un titre suivi ; un cours déjà présent à cette
+// date est remplacé
+func (p *Portfolio) AddWatchPrice(name, date string, price float64) error {
+	if NewMoney(price) <= 0 {
+		return fmt.Errorf("le cours doit être positif: %w", ErrInvalidAmount)
+	}
+	nav, err := NewNAV(date, price)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.addWatchPrice(name, nav)
+}
+
+// addWatchPrice enregistre un cours et le journalise ; l'appelant doit détenir p.mu en
+// écriture
+func (p *Portfolio) addWatchPrice(name string, nav NAV) error {
+	w, exists := p.Watchlist[name]
+	if !exists {
+		return fmt.Errorf("le titre suivi '%s' n'existe pas: %w", name, ErrNotFound)
+	}
+	before, err := p.settingsState("watchlist")
+	if err != nil {
+		return err
+	}
+	i := sort.Search(len(w.History), func(i int) bool { return !w.History[i].Date.Before(nav.Date.Time) })
+	if i < len(w.History) && w.History[i].Date.Equal(nav.Date.Time) {
+		w.History[i].Value = nav.Value
+	} else {
+		w.History = append(w.History, nav)
+		sortNAVs(w.History)
+	}
+	detail := fmt.Sprintf("%s: %.4f au %s", name, nav.Value.Float64(), FormatDate(nav.Date.Time))
+	return p.recordChange(JournalEntry{Op: OpAddWatchPrice, Detail: detail, BeforeSettings: before})
+}
+
+// watchlistNames retourne les noms des titres suivis, triés ; l'appelant doit détenir p.mu
+func (p *Portfolio) watchlistNames() []string {
+	names := make([]string, 0, len(p.Watchlist))
+	for name := range p.Watchlist {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// refreshWatched met à jour le cours des titres suivis dotés d'un identifiant
+func (p *Portfolio) refreshWatched(ctx context.Context, provider QuoteProvider) []RefreshResult {
+	type target struct {
+		name, identifier string
+		currency         Currency
+	}
+	p.mu.RLock()
+	var targets []target
+	for _, name := range p.watchlistNames() {
+		if w := p.Watchlist[name]; w.Identifier != "" {
+			targets = append(targets, target{name, w.Identifier, w.investment().EffectiveCurrency()})
+		}
+	}
+	p.mu.RUnlock()
+
+	var results []RefreshResult
+	for _, t := range targets {
+		result := RefreshResult{Name: t.name, Identifier: t.identifier}
+		result.NAV, result.Err = p.refreshWatchPrice(ctx, provider, t.name, t.identifier, t.currency)
+		results = append(results, result)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return results
+}
+
+// refreshWatchPrice obtient le cours d'un titre suivi et l'enregistre
+func (p *Portfolio) refreshWatchPrice(ctx context.Context, provider QuoteProvider, name, identifier string, currency Currency) (NAV, error) {
+	quote, err := provider.Quote(ctx, identifier)
+	if err != nil {
+		return NAV{}, err
+	}
+	if quote.Currency != "" && quote.Currency != currency {
+		return NAV{}, fmt.Errorf("cours en %s pour un titre suivi en %s", quote.Currency, currency)
+	}
+	if quote.Price <= 0 {
+		return NAV{}, fmt.Errorf("cours %.4f: %w", quote.Price, ErrInvalidAmount)
+	}
+	nav := NAV{Date: Date{quote.Date}, Value: NewMoney(quote.Price)}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return nav, p.addWatchPrice(name, nav)
+}
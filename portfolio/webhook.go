@@ -0,0 +1,145 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Types d'événements propres aux webhooks, en plus de ceux du portefeuille (voir EventKind)
+const (
+	EventAlert  EventKind = "alert"  // Alertes déclenchées (commandes alerts et watch)
+	EventReport EventKind = "report" // Rapport écrit dans un fichier
+)
+
+// webhookClient envoie les webhooks sans Client : un destinataire qui accepte la connexion
+// sans jamais répondre ne bloque pas l'envoi au-delà de son délai
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// defaultWebhookEvents sont les événements transmis quand la configuration n'en précise pas
+var defaultWebhookEvents = []EventKind{EventNAVAdded, EventAlert, EventReport}
+
+// Webhook publie les événements du portefeuille par des requêtes POST JSON :
+//
+//	{"event": "nav-added", "time": "...", "data": {...}}
+//
+// Avec un secret, le corps est signé par HMAC-SHA256 dans l'en-tête
+// X-David-Signature ("sha256=<hex>"), à vérifier par le destinataire. Un envoi refusé
+// par une erreur réseau, un statut 429 ou 5xx est répété après un délai qui double à
+// chaque tentative.
+type Webhook struct {
+	URL     string
+	Secret  string
+	Events  []EventKind   // Événements transmis, defaultWebhookEvents si vide
+	Retries int           // Tentatives supplémentaires après un échec
+	Backoff time.Duration // Délai avant la première nouvelle tentative
+	Client  *http.Client  // webhookClient (délai de 10 s par tentative) si nil
+}
+
+// webhookPayload est le corps d'une requête de webhook
+type webhookPayload struct {
+	Event EventKind `json:"event"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+// Wants indique si le webhook transmet les événements de type kind
+func (w *Webhook) Wants(kind EventKind) bool {
+	if w == nil {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return slices.Contains(defaultWebhookEvents, kind)
+	}
+	return slices.Contains(w.Events, kind)
+}
+
+// Send publie un événement, avec les nouvelles tentatives prévues
+func (w *Webhook) Send(ctx context.Context, kind EventKind, data any) error {
+	body, err := json.Marshal(webhookPayload{Event: kind, Time: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	delivery := hex.EncodeToString(id)
+
+	delay := w.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, kind, delivery, body)
+		if err == nil || !retry || attempt >= w.Retries {
+			return err
+		}
+		Logger().Debug("webhook en échec, nouvelle tentative", "url", w.URL, "event", kind, "attempt", attempt+1, "err", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (dernier échec: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post effectue une tentative d'envoi et indique si un échec mérite d'être répété
+func (w *Webhook) post(ctx context.Context, kind EventKind, delivery string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-David-Event", string(kind))
+	req.Header.Set("X-David-Delivery", delivery)
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set("X-David-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = webhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook %s: HTTP %d", w.URL, resp.StatusCode)
+	}
+	return false, nil
+}
+
+// Notify publie les alertes déclenchées, ce qui fait du webhook un Notifier
+func (w *Webhook) Notify(ctx context.Context, alerts []Alert) error {
+	return w.Send(ctx, EventAlert, map[string][]Alert{"alerts": alerts})
+}
+
+// ParseWebhookEvents lit une liste d'événements séparés par des virgules
+func ParseWebhookEvents(s string) ([]EventKind, error) {
+	var kinds []EventKind
+	for _, field := range strings.Split(s, ",") {
+		kind := EventKind(strings.TrimSpace(field))
+		switch kind {
+		case "":
+			continue
+		case EventInvestmentAdded, EventNAVAdded, EventValueRecomputed, EventModified, EventAlert, EventReport:
+			kinds = append(kinds, kind)
+		default:
+			return nil, InvalidField("events", s, "événement de webhook inconnu: %s", kind)
+		}
+	}
+	return kinds, nil
+}
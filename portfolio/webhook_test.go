@@ -0,0 +1,186 @@
+package portfolio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookDelivery est une requête reçue par webhookReceiver
+type webhookDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver démarre un destinataire qui répond successivement les statuts donnés
+// (200 ensuite) et retourne les requêtes reçues
+func webhookReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []webhookDelivery) {
+	t.Helper()
+	var mu sync.Mutex
+	var deliveries []webhookDelivery
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		status := http.StatusOK
+		if len(deliveries) < len(statuses) {
+			status = statuses[len(deliveries)]
+		}
+		deliveries = append(deliveries, webhookDelivery{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []webhookDelivery {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(deliveries)
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{name: "sans secret"},
+		{name: "signé", secret: "s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := webhookReceiver(t)
+			w := &Webhook{URL: srv.URL, Secret: tt.secret}
+			if err := w.Send(context.Background(), EventNAVAdded, map[string]string{"investment": "A"}); err != nil {
+				t.Fatal(err)
+			}
+			deliveries := received()
+			if len(deliveries) != 1 {
+				t.Fatalf("%d requêtes, 1 attendue", len(deliveries))
+			}
+			d := deliveries[0]
+			if d.header.Get("Content-Type") != "application/json" || d.header.Get("X-David-Event") != "nav-added" || d.header.Get("X-David-Delivery") == "" {
+				t.Errorf("en-têtes %v", d.header)
+			}
+			var payload struct {
+				Event string            `json:"event"`
+				Time  time.Time         `json:"time"`
+				Data  map[string]string `json:"data"`
+			}
+			if err := json.Unmarshal(d.body, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.Event != "nav-added" || payload.Time.IsZero() || payload.Data["investment"] != "A" {
+				t.Errorf("corps %s", d.body)
+			}
+
+			signature := d.header.Get("X-David-Signature")
+			if tt.secret == "" {
+				if signature != "" {
+					t.Errorf("signature %q sans secret", signature)
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write(d.body)
+			if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+				t.Errorf("signature %q, %q attendue", signature, want)
+			}
+		})
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retries  int
+		attempts int
+		wantErr  bool
+	}{
+		{name: "succès", retries: 2, attempts: 1},
+		{name: "erreur serveur puis succès", statuses: []int{500, 503}, retries: 2, attempts: 3},
+		{name: "trop de requêtes", statuses: []int{429, 429}, retries: 1, attempts: 2, wantErr: true},
+		{name: "refus définitif", statuses: []int{400}, retries: 3, attempts: 1, wantErr: true},
+		{name: "sans nouvelle tentative", statuses: []int{502}, attempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := webhookReceiver(t, tt.statuses...)
+			w := &Webhook{URL: srv.URL, Retries: tt.retries, Backoff: time.Millisecond}
+			err := w.Send(context.Background(), EventAlert, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erreur %v, échec attendu: %t", err, tt.wantErr)
+			}
+			deliveries := received()
+			if len(deliveries) != tt.attempts {
+				t.Fatalf("%d tentatives, %d attendues", len(deliveries), tt.attempts)
+			}
+			for _, d := range deliveries[1:] {
+				if d.header.Get("X-David-Delivery") != deliveries[0].header.Get("X-David-Delivery") || string(d.body) != string(deliveries[0].body) {
+					t.Error("une nouvelle tentative devrait renvoyer le même envoi")
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookCancelledDuringBackoff(t *testing.T) {
+	srv, _ := webhookReceiver(t, 500, 500, 500)
+	w := &Webhook{URL: srv.URL, Retries: 5, Backoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Send(ctx, EventAlert, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("erreur %v, délai dépassé attendu", err)
+	}
+}
+
+func TestWebhookWants(t *testing.T) {
+	tests := []struct {
+		name   string
+		w      *Webhook
+		kind   EventKind
+		wanted bool
+	}{
+		{name: "sans webhook", kind: EventNAVAdded},
+		{name: "événement par défaut", w: &Webhook{}, kind: EventNAVAdded, wanted: true},
+		{name: "hors défaut", w: &Webhook{}, kind: EventModified},
+		{name: "liste explicite", w: &Webhook{Events: []EventKind{EventModified}}, kind: EventModified, wanted: true},
+		{name: "absent de la liste", w: &Webhook{Events: []EventKind{EventModified}}, kind: EventAlert},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.w.Wants(tt.kind); got != tt.wanted {
+				t.Errorf("Wants(%s) = %t", tt.kind, got)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEvents(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []EventKind
+		wantErr bool
+	}{
+		{in: ""},
+		{in: "alert", want: []EventKind{EventAlert}},
+		{in: " nav-added , report,", want: []EventKind{EventNAVAdded, EventReport}},
+		{in: "alert,deleted", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWebhookEvents(tt.in)
+		if (err != nil) != tt.wantErr || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ParseWebhookEvents(%q) = %v, %v ; %v attendu", tt.in, got, err, tt.want)
+		}
+	}
+}
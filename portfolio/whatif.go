@@ -0,0 +1,60 @@
+package portfolio
+
+import (
+	"fmt"
+)
+
+// ChangeAction est le type d'une modification hypothétique
+type ChangeAction string
+
+const (
+	ChangeAdd        ChangeAction = "add"        // Nouvel investissement
+	ChangeRemove     ChangeAction = "remove"     // Investissement retiré du portefeuille
+	ChangeContribute ChangeAction = "contribute" // Apport sur un investissement existant
+	ChangeWithdraw   ChangeAction = "withdraw"   // Retrait d'un investissement existant
+)
+
+// Change est une modification hypothétique appliquée par WhatIf
+type Change struct {
+	Action     ChangeAction
+	Investment string
+	Amount     float64 // Montant investi, apporté ou retiré
+	Rate       float64 // Taux de référence annuel (%) d'un nouvel investissement
+	Date       string  // Date de l'opération (AAAA-MM-JJ), aujourd'hui si vide
+}
+
+// WhatIf retourne une copie du portefeuille à laquelle les modifications sont appliquées
+// dans l'ordre, pour comparer ses projections à celles du portefeuille réel sans le
+// modifier. La copie ne doit pas être enregistrée.
+func (p *Portfolio) WhatIf(changes ...Change) (*Portfolio, error) {
+	c, err := p.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for i, ch := range changes {
+		date := ch.Date
+		if date == "" {
+			date = FormatDate(Today())
+		}
+		var err error
+		switch ch.Action {
+		case ChangeAdd:
+			// Une NAV égale au montant investi sert de point de départ aux projections
+			if err = c.AddInvestment(ch.Investment, ch.Amount, ch.Rate, date); err == nil {
+				err = c.AddNAV(ch.Investment, date, ch.Amount)
+			}
+		case ChangeRemove:
+			err = c.RemoveInvestment(ch.Investment)
+		case ChangeContribute:
+			err = c.AddCashFlow(ch.Investment, date, ch.Amount, Contribution)
+		case ChangeWithdraw:
+			err = c.AddCashFlow(ch.Investment, date, ch.Amount, Withdrawal)
+		default:
+			err = InvalidField("action", ch.Action, "modification inconnue: %s", ch.Action)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("modification %d (%s %s): %w", i+1, ch.Action, ch.Investment, err)
+		}
+	}
+	return c, nil
+}
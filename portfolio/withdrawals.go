@@ -0,0 +1,173 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// WithdrawalHorizonYears borne la durée de simulation des retraits
+const WithdrawalHorizonYears = 50
+
+// YearBalance est l'état du portefeuille à la fin d'une année de retraits
+type YearBalance struct {
+	Date      Date    `json:"date"`      // Fin de l'année de simulation
+	Value     float64 `json:"value"`     // Valeur restante
+	Withdrawn float64 `json:"withdrawn"` // Montant retiré pendant l'année
+}
+
+// WithdrawalSimulation est le résultat d'une simulation de retraits
+type WithdrawalSimulation struct {
+	StartValue float64       // Valeur projetée du portefeuille au premier retrait
+	Rate       float64       // Taux annuel (%) appliqué, moyenne des taux effectifs pondérée par les valeurs
+	Depletion  time.Time     // Date d'épuisement du capital (zéro s'il dure tout l'horizon)
+	Years      []YearBalance // Valeur restante en fin de chaque année
+}
+
+// Depleted indique si le capital est épuisé avant l'horizon de simulation
+func (w *WithdrawalSimulation) Depleted() bool {
+	return !w.Depletion.IsZero()
+}
+
+// SimulateWithdrawals simule des retraits mensuels sur le portefeuille projeté à la date
+// start : le capital restant croît au taux moyen du portefeuille et le retrait est
+// revalorisé chaque année de indexation (%). La simulation s'arrête à l'épuisement du
+// capital ou après WithdrawalHorizonYears ans.
+func (p *Portfolio) SimulateWithdrawals(start string, monthlyAmount float64, indexation float64) (*WithdrawalSimulation, error) {
+	if NewMoney(monthlyAmount) <= 0 {
+		return nil, fmt.Errorf("le retrait mensuel doit être positif: %w", ErrInvalidAmount)
+	}
+	t, err := ParseDate(start)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	value, rate, err := p.blendedProjection(t)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &WithdrawalSimulation{StartValue: value, Rate: rate}
+	monthlyGrowth := p.rateConventions().GrowthFactor(rate, 1.0/12)
+	withdrawal := monthlyAmount
+	var withdrawnThisYear float64
+	for month := 1; month <= WithdrawalHorizonYears*12; month++ {
+		value *= monthlyGrowth
+		date := t.AddDate(0, month, 0)
+		if value <= withdrawal {
+			withdrawnThisYear += value
+			sim.Depletion = date
+			sim.Years = append(sim.Years, YearBalance{Date: Date{date}, Withdrawn: withdrawnThisYear})
+			return sim, nil
+		}
+		value -= withdrawal
+		withdrawnThisYear += withdrawal
+
+		if month%12 == 0 {
+			sim.Years = append(sim.Years, YearBalance{
+				Date:      Date{date},
+				Value:     NewMoney(value).RoundCents().Float64(),
+				Withdrawn: NewMoney(withdrawnThisYear).RoundCents().Float64(),
+			})
+			withdrawnThisYear = 0
+			withdrawal *= 1 + indexation/100
+		}
+	}
+	return sim, nil
+}
+
+// WithdrawalSuccessRate estime par Monte-Carlo la probabilité (%) que le capital couvre
+// years années de retraits, les rendements mensuels étant tirés selon une loi
+// log-normale centrée sur le taux moyen avec la volatilité historique du portefeuille.
+func (p *Portfolio) WithdrawalSuccessRate(start string, monthlyAmount, indexation float64, years, n int, opts MonteCarloOptions) (float64, error) {
+	return p.WithdrawalSuccessRateContext(context.Background(), start, monthlyAmount, indexation, years, n, opts)
+}
+
+// WithdrawalSuccessRateContext est WithdrawalSuccessRate interrompue si ctx est annulé
+func (p *Portfolio) WithdrawalSuccessRateContext(ctx context.Context, start string, monthlyAmount, indexation float64, years, n int, opts MonteCarloOptions) (float64, error) {
+	if n <= 0 || years <= 0 {
+		return 0, InvalidField("paths", n, "le nombre de trajectoires et la durée doivent être positifs")
+	}
+	t, err := ParseDate(start)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	startValue, rate, err := p.blendedProjection(t)
+	if err != nil {
+		return 0, err
+	}
+	returns, err := p.alignedReturns(time.Time{}, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	if len(returns) < 2 {
+		return 0, fmt.Errorf("au moins 2 rendements sont nécessaires pour estimer la dispersion: %w", ErrInsufficientHistory)
+	}
+	drift := p.rateConventions().RateLog(rate) * monteCarloStep
+	volatility := analytics.AnnualVolatility(returns) * math.Sqrt(monteCarloStep)
+
+	rng := newMonteCarloRand(opts.Seed)
+	successes := 0
+	for path := 0; path < n; path++ {
+		if path%monteCarloCheckEvery == 0 && ctx.Err() != nil {
+			return 0, fmt.Errorf("simulation interrompue après %d trajectoires: %w", path, ctx.Err())
+		}
+		value, withdrawal := startValue, monthlyAmount
+		for month := 1; month <= years*12 && value > 0; month++ {
+			value = value*math.Exp(drift+volatility*rng.NormFloat64()) - withdrawal
+			if month%12 == 0 {
+				withdrawal *= 1 + indexation/100
+			}
+		}
+		if value > 0 {
+			successes++
+		}
+	}
+	return float64(successes) / float64(n) * 100, nil
+}
+
+// BlendedProjection retourne la valeur projetée du portefeuille à une date et le taux
+// moyen (%) de ses investissements ouverts, pondéré par leurs valeurs
+func (p *Portfolio) BlendedProjection(date string) (value, rate float64, err error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.blendedProjection(t)
+}
+
+// blendedProjection retourne la valeur projetée du portefeuille à une date et le taux
+// moyen (%) de ses investissements ouverts, pondéré par leurs valeurs. L'appelant doit détenir p.mu.
+func (p *Portfolio) blendedProjection(date time.Time) (value, rate float64, err error) {
+	values, total, err := p.portfolioValue(date)
+	if err != nil {
+		return 0, 0, err
+	}
+	if total <= 0 {
+		return 0, 0, fmt.Errorf("la valeur totale du portefeuille est nulle")
+	}
+
+	var logRate float64
+	for name, v := range values {
+		r, err := p.Investments[name].EffectiveRate()
+		if err != nil {
+			return 0, 0, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		logRate += v / total * p.rateConventions().RateLog(r)
+	}
+	return total, p.rateConventions().RateFromLog(logRate), nil
+}
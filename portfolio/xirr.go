@@ -0,0 +1,84 @@
+package portfolio
+
+import (
+	"fmt"
+
+	"github.com/davidsportes-ship-it/david/analytics"
+)
+
+// XIRR calcule le taux de rendement interne annualisé (%) de l'investissement à partir
+// du montant initial, des apports et retraits, et de la dernière NAV considérée comme
+// valeur de sortie ; les distributions versées en numéraire sont des flux reçus.
+// Contrairement à CalculatePerformanceRate, il tient compte du
+// calendrier des versements (rendement pondéré par les capitaux).
+func (inv *Investment) XIRR() (float64, error) {
+	flows, err := inv.xirrFlows()
+	if err != nil {
+		return 0, err
+	}
+	return inv.conventions.XIRR(flows)
+}
+
+// XIRR calcule le taux de rendement interne annualisé (%) de l'ensemble du portefeuille,
+// chaque flux étant converti dans la devise de consolidation au taux de sa date
+func (p *Portfolio) XIRR() (float64, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	flows, err := p.xirrFlows()
+	if err != nil {
+		return 0, err
+	}
+	return p.rateConventions().XIRR(flows)
+}
+
+// xirrFlows rassemble les flux de tous les investissements, convertis dans la devise de
+// consolidation ; l'appelant doit détenir p.mu
+func (p *Portfolio) xirrFlows() ([]analytics.Flow, error) {
+	var flows []analytics.Flow
+	for name, inv := range p.Investments {
+		invFlows, err := inv.xirrFlows()
+		if err != nil {
+			return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+		}
+		for _, f := range invFlows {
+			f.Amount, err = p.toBase(f.Amount, inv.Currency, f.Date)
+			if err != nil {
+				return nil, fmt.Errorf("erreur pour %s: %w", name, err)
+			}
+			flows = append(flows, f)
+		}
+	}
+	return flows, nil
+}
+
+// xirrFlows construit la série de flux de l'investissement jusqu'à sa dernière NAV
+func (inv *Investment) xirrFlows() ([]analytics.Flow, error) {
+	latestNAV, err := inv.GetLatestNAV()
+	if err != nil {
+		return nil, err
+	}
+
+	flows := []analytics.Flow{{Date: inv.InvestmentDate.Time, Amount: -inv.AmountInvested.Float64()}}
+
+	externalFlows := append(inv.paidDistributionFlows(), inv.CashFlows...)
+
+	// Position clôturée : les retraits ont tout restitué, pas de valeur de sortie
+	if inv.Closed {
+		for _, cf := range externalFlows {
+			flows = append(flows, analytics.Flow{Date: cf.Date.Time, Amount: -cf.SignedAmount().Float64()})
+		}
+		return flows, nil
+	}
+
+	for _, cf := range externalFlows {
+		// La dernière NAV inclut les flux de son jour, pas ceux postérieurs
+		if cf.Date.After(latestNAV.Date.Time) {
+			continue
+		}
+		flows = append(flows, analytics.Flow{Date: cf.Date.Time, Amount: -cf.SignedAmount().Float64()})
+	}
+	flows = append(flows, analytics.Flow{Date: latestNAV.Date.Time, Amount: latestNAV.Value.Float64()})
+
+	return flows, nil
+}
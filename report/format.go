@@ -0,0 +1,25 @@
+package report
+
+import (
+	"unicode/utf8"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// AmountFormatter retourne le formateur des montants consolidés, dans la langue imposée
+// par DAVID_LANG si elle est renseignée
+func AmountFormatter(p *portfolio.Portfolio) portfolio.AmountFormatter {
+	f := p.AmountFormatter()
+	if locale := portfolio.EnvLocale(); locale != "" {
+		f.Locale = locale
+	}
+	return f
+}
+
+// Truncate coupe un texte à n caractères
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
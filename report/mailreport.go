@@ -0,0 +1,176 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// ReportMailer envoie chaque mois le rapport du portefeuille par courriel : un résumé
+// texte avec les alertes déclenchées, suivi du rapport HTML ou PDF et de ses projections
+type ReportMailer struct {
+	Mail    portfolio.EmailNotifier
+	Format  string // html ou pdf
+	Day     int    // Jour du mois à partir duquel le rapport du mois est dû
+	Options ReportOptions
+	Stamp   string // Fichier de la date du dernier envoi, pour ne pas renvoyer après un redémarrage
+}
+
+// lastSent retourne la date du dernier envoi, zéro si aucun n'est enregistré
+func (m *ReportMailer) lastSent() (time.Time, error) {
+	data, err := os.ReadFile(m.Stamp)
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return portfolio.ParseDate(strings.TrimSpace(string(data)))
+}
+
+// Due indique si le rapport du mois de t reste à envoyer
+func (m *ReportMailer) Due(t time.Time) (bool, error) {
+	if t.Day() < m.Day {
+		return false, nil
+	}
+	last, err := m.lastSent()
+	if err != nil {
+		return false, err
+	}
+	return last.Year() != t.Year() || last.Month() != t.Month(), nil
+}
+
+// Send envoie le rapport du portefeuille à la date t et enregistre son envoi
+func (m *ReportMailer) Send(p *portfolio.Portfolio, t time.Time) error {
+	msg, err := m.message(p, t)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.Mail.Addr, m.Mail.Auth, m.Mail.From, m.Mail.To, msg); err != nil {
+		return err
+	}
+	if m.Stamp == "" {
+		return nil
+	}
+	return portfolio.WriteFileAtomic(m.Stamp, []byte(portfolio.FormatDate(t)+"\n"), 0o600)
+}
+
+// message compose le courriel : résumé texte et alertes, puis le rapport, en ligne pour
+// le HTML ou en pièce jointe pour le PDF
+func (m *ReportMailer) message(p *portfolio.Portfolio, t time.Time) ([]byte, error) {
+	summary, err := p.Summary()
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := p.EvaluateAlerts()
+	if err != nil {
+		return nil, err
+	}
+	var report bytes.Buffer
+	opts := m.Options
+	if opts.Title == "" {
+		opts.Title = "Rapport mensuel " + t.Format("2006-01")
+	}
+	render := RenderHTML
+	if m.Format == "pdf" {
+		render = RenderPDF
+	}
+	if err := render(p, &report, opts); err != nil {
+		return nil, err
+	}
+
+	amount := AmountFormatter(p).Format
+	var text strings.Builder
+	fmt.Fprintf(&text, "Portefeuille au %s\n\n", portfolio.FormatDate(t))
+	fmt.Fprintf(&text, "Valeur: %s\nMontant investi: %s\n", amount(summary.TotalValue), amount(summary.TotalInvested))
+	if len(alerts) == 0 {
+		text.WriteString("\nAucune alerte déclenchée\n")
+	} else {
+		fmt.Fprintf(&text, "\n%d alerte(s) déclenchée(s):\n", len(alerts))
+		for _, a := range alerts {
+			text.WriteString("- " + a.Message + "\n")
+		}
+	}
+	fmt.Fprintf(&text, "\nLe rapport complet, avec les projections, est %s.\n",
+		map[string]string{"html": "ci-dessous", "pdf": "joint"}[m.Format])
+
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "From: %s\r\nTo: %s\r\n", m.Mail.From, strings.Join(m.Mail.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "david: "+opts.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	name := "rapport-" + t.Format("2006-01") + "." + m.Format
+	parts := []struct {
+		header textproto.MIMEHeader
+		body   []byte
+	}{
+		{textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}}, []byte(text.String())},
+		{textproto.MIMEHeader{
+			"Content-Type":        {map[string]string{"html": "text/html; charset=utf-8", "pdf": "application/pdf"}[m.Format]},
+			"Content-Disposition": {map[string]string{"html": "inline", "pdf": "attachment"}[m.Format] + "; filename=" + name},
+		}, report.Bytes()},
+	}
+	for _, part := range parts {
+		part.header.Set("Content-Transfer-Encoding", "base64")
+		w, err := mw.CreatePart(part.header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeBase64Lines encode data en base64, en lignes de 76 caractères (RFC 2045)
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
+}
+
+// mailReport envoie le rapport mensuel s'il est dû ; un échec est journalisé et l'envoi
+// retenté au cycle suivant
+func (w *Watcher) mailReport() {
+	if w.Report == nil {
+		return
+	}
+	day := portfolio.Today()
+	due, err := w.Report.Due(day)
+	if err != nil {
+		w.Logger.Printf("rapport mensuel: %v", err)
+		return
+	}
+	if !due {
+		return
+	}
+	if err := w.Report.Send(w.Portfolio, day); err != nil {
+		w.Logger.Printf("envoi du rapport mensuel: %v", err)
+		return
+	}
+	w.Logger.Printf("rapport mensuel envoyé à %s", strings.Join(w.Report.Mail.To, ", "))
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// RenderMarkdown écrit le rapport en Markdown, à partir des mêmes données que les
+// rapports HTML et PDF : tableau de synthèse avec les totaux, performances annuelles,
+// répartition, projections, puis un tableau de caractéristiques par investissement.
+func RenderMarkdown(p *portfolio.Portfolio, w io.Writer, opts ReportOptions) error {
+	m, err := buildReport(p, opts)
+	if err != nil {
+		return err
+	}
+	base, l := string(m.Summary.BaseCurrency), m.Locale
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# %s\n\n", m.Title)
+	fmt.Fprintf(b, "%s\n\n", l.Tf("Généré le %s — devise de consolidation : %s", portfolio.FormatDate(m.Generated), base))
+
+	fmt.Fprintln(b, "## "+l.T("Synthèse"))
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %s | %s |\n", l.T("Investissement"), l.T("Devise"), l.T("Investi"),
+		l.T("Dernière NAV"), l.T("Date"), l.T("Performance annuelle"), l.Tf("Valeur (%s)", base))
+	fmt.Fprintln(b, "|---|---|--:|--:|---|--:|--:|")
+	for _, line := range m.Summary.Investments {
+		name, nav, date := markdownCell(line.Name), "—", "—"
+		if line.Closed {
+			name += " (" + l.Tf("clôturé le %s", portfolio.FormatDate(line.ClosedDate.Time)) + ")"
+		}
+		if line.LatestNAV != nil {
+			nav, date = fmt.Sprintf("%.2f", line.LatestNAV.Value.Float64()), portfolio.FormatDate(line.LatestNAV.Date.Time)
+		}
+		fmt.Fprintf(b, "| %s | %s | %.2f | %s | %s | %s | %.2f |\n",
+			name, line.Currency, line.NetInvested.Float64(), nav, date, markdownPercent(line.PerformanceRate), line.Value)
+	}
+	fmt.Fprintf(b, "| **%s** | | **%.2f** | | | | **%.2f** |\n\n", l.T("Total"), m.Summary.TotalInvested, m.Summary.TotalValue)
+
+	if t := m.Performance; t != nil {
+		fmt.Fprintln(b, "## "+l.T("Performances annuelles"))
+		fmt.Fprintln(b)
+		fmt.Fprint(b, "| |")
+		for _, year := range t.Years {
+			fmt.Fprintf(b, " %s |", t.YearLabel(year))
+		}
+		fmt.Fprintf(b, " %s |\n", l.T(t.YTDLabel()))
+		fmt.Fprint(b, "|---|")
+		fmt.Fprint(b, strings.Repeat("--:|", len(t.Years)+1))
+		fmt.Fprintln(b)
+		for _, row := range t.Rows {
+			fmt.Fprintf(b, "| %s |", markdownCell(row.Name))
+			for _, year := range t.Years {
+				value, ok := row.Annual[year]
+				fmt.Fprintf(b, " %s |", portfolio.FormatPercentCell(value, ok))
+			}
+			fmt.Fprintf(b, " %s |\n", markdownPercent(row.YTD))
+		}
+		fmt.Fprintln(b)
+	}
+
+	if len(m.Allocation) > 0 {
+		fmt.Fprintf(b, "## %s\n\n", l.Tf("Répartition (%s)", m.Tag))
+		fmt.Fprintf(b, "| %s | %s |\n", l.T("Valeur"), l.T("Part"))
+		fmt.Fprintln(b, "|---|--:|")
+		labels := make([]string, 0, len(m.Allocation))
+		for label := range m.Allocation {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			fmt.Fprintf(b, "| %s | %.2f%% |\n", markdownCell(label), m.Allocation[label])
+		}
+		fmt.Fprintln(b)
+	}
+
+	if len(m.Projections) > 0 {
+		fmt.Fprintln(b, "## "+l.T("Projections"))
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "| %s | %s |\n", l.T("Date"), l.Tf("Valeur projetée (%s)", base))
+		fmt.Fprintln(b, "|---|--:|")
+		for _, proj := range m.Projections {
+			fmt.Fprintf(b, "| %s | %.2f |\n", portfolio.FormatDate(proj.Date), proj.Total)
+		}
+		fmt.Fprintln(b)
+		for _, proj := range m.Projections {
+			if len(proj.Skipped) > 0 {
+				fmt.Fprintln(b, l.Tf("Écartés faute de NAV au %s : %s", portfolio.FormatDate(proj.Date), proj.SkippedList()))
+				fmt.Fprintln(b)
+			}
+		}
+	}
+
+	for _, line := range m.Summary.Investments {
+		cur := string(line.Currency)
+		fmt.Fprintf(b, "## %s\n\n", line.Name)
+		fmt.Fprintln(b, "| | |")
+		fmt.Fprintln(b, "|---|--:|")
+		fmt.Fprintf(b, "| %s | %s |\n", l.T("Date d'investissement"), portfolio.FormatDate(line.InvestmentDate.Time))
+		if line.Closed {
+			fmt.Fprintf(b, "| %s | %s |\n", l.T("Clôturé le"), portfolio.FormatDate(line.ClosedDate.Time))
+		}
+		fmt.Fprintf(b, "| %s | %.2f %s |\n", l.T("Montant investi"), line.AmountInvested.Float64(), cur)
+		fmt.Fprintf(b, "| %s | %.2f %s |\n", l.T("Capital net investi"), line.NetInvested.Float64(), cur)
+		fmt.Fprintf(b, "| %s | %.2f%% |\n", l.T("Taux de référence"), line.ReferenceRate)
+		if line.LatestNAV != nil {
+			fmt.Fprintf(b, "| %s | %.2f %s |\n", l.Tf("Dernière NAV (%s)", portfolio.FormatDate(line.LatestNAV.Date.Time)), line.LatestNAV.Value.Float64(), cur)
+		}
+		fmt.Fprintf(b, "| %s | %s |\n", l.T("Taux de performance annuel"), markdownPercent(line.PerformanceRate))
+		if line.Distributions != 0 || line.Reinvested != 0 {
+			fmt.Fprintf(b, "| %s | %.2f %s |\n", l.T("Distributions versées"), line.Distributions.Float64(), cur)
+			fmt.Fprintf(b, "| %s | %.2f %s |\n", l.T("Distributions réinvesties"), line.Reinvested.Float64(), cur)
+		}
+		if line.Position != nil {
+			fmt.Fprintf(b, "| %s | %s |\n", l.T("Parts détenues"), line.Position.Units)
+			fmt.Fprintf(b, "| %s | %.2f %s |\n", l.T("Plus-value réalisée"), line.Position.RealizedGain.Float64(), cur)
+			fmt.Fprintf(b, "| %s | %.2f %s |\n", l.T("Plus-value latente"), line.Position.UnrealizedGain.Float64(), cur)
+		}
+		for _, name := range m.Summary.Metrics {
+			value, ok := line.Metrics[name]
+			fmt.Fprintf(b, "| %s | %s |\n", markdownCell(name), portfolio.FormatMetric(value, ok))
+		}
+		fmt.Fprintf(b, "| %s | %.2f |\n\n", l.Tf("Valeur (%s)", base), line.Value)
+		if len(line.Notes) > 0 {
+			fmt.Fprintf(b, "### %s\n\n", l.T("Notes"))
+			for _, e := range line.Notes {
+				fmt.Fprintf(b, "- %s : %s\n", portfolio.FormatDate(e.Date.Time), e.Label(l))
+			}
+			fmt.Fprintln(b)
+		}
+	}
+	return b.Flush()
+}
+
+// markdownCell échappe les barres verticales qui couperaient une cellule de tableau
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// markdownPercent formate un pourcentage optionnel
+func markdownPercent(v *float64) string {
+	if v == nil {
+		return "—"
+	}
+	return fmt.Sprintf("%.2f%%", *v)
+}
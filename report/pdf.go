@@ -0,0 +1,396 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// Format de page A4 en points PDF
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// pdfDocument construit un document PDF minimal : pages A4, polices Helvetica standard
+// en codage WinAnsi, texte, traits et rectangles. Il suffit aux rapports et évite toute
+// dépendance externe.
+type pdfDocument struct {
+	locale portfolio.Locale
+	pages  []*bytes.Buffer
+	y      float64 // Position verticale du curseur sur la page courante
+}
+
+// newPage ouvre une nouvelle page et place le curseur sous la marge haute
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// page retourne le flux de contenu de la page courante
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensure ouvre une nouvelle page s'il reste moins de height points sous le curseur
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < pdfMargin {
+		d.newPage()
+	}
+}
+
+// text écrit une chaîne à la position (x, y), en gras si bold
+func (d *pdfDocument) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// textRight écrit une chaîne alignée à droite sur x. La largeur est estimée d'après
+// la chasse moyenne des chiffres Helvetica, ce qui suffit pour des colonnes de nombres.
+func (d *pdfDocument) textRight(x, y, size float64, s string) {
+	d.text(x-float64(len([]rune(s)))*size*0.556, y, size, false, s)
+}
+
+// line trace un segment
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// heading écrit un titre de section sous le curseur
+func (d *pdfDocument) heading(s string) {
+	d.ensure(40)
+	d.y -= 24
+	d.text(pdfMargin, d.y, 13, true, s)
+	d.y -= 6
+}
+
+// row écrit une ligne de tableau : la première colonne alignée à gauche, les suivantes
+// alignées à droite sur les bords donnés par columns
+func (d *pdfDocument) row(bold bool, columns []float64, cells ...string) {
+	d.ensure(16)
+	d.y -= 14
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		if i == 0 {
+			d.text(pdfMargin, d.y, 9, bold, cell)
+			continue
+		}
+		if bold {
+			d.text(columns[i]-float64(len([]rune(cell)))*9*0.6, d.y, 9, true, cell)
+		} else {
+			d.textRight(columns[i], d.y, 9, cell)
+		}
+	}
+}
+
+// pdfString code une chaîne en WinAnsi et échappe les caractères réservés du PDF
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		var c byte
+		switch {
+		case r == '€':
+			c = 0x80
+		case r == '’':
+			c = 0x92
+		case r == '–':
+			c = 0x96
+		case r == '—':
+			c = 0x97
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			c = byte(r)
+		default:
+			c = '?'
+		}
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// WriteTo assemble les objets du document et la table des références croisées
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objets 1 à 4 : catalogue, arbre des pages, polices ; puis une page et son contenu par page
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+// Bords droits des colonnes du tableau de synthèse
+var pdfSummaryColumns = []float64{0, 250, 330, 410, 475, pdfPageWidth - pdfMargin}
+
+// RenderPDF écrit le rapport au format PDF, à partir des mêmes données que le
+// rapport HTML : une page de synthèse (tableau récapitulatif, performances annuelles,
+// projections et graphique de la valeur historique et projetée), puis une page par
+// investissement.
+func RenderPDF(p *portfolio.Portfolio, w io.Writer, opts ReportOptions) error {
+	m, err := buildReport(p, opts)
+	if err != nil {
+		return err
+	}
+	base := string(m.Summary.BaseCurrency)
+
+	d := &pdfDocument{locale: m.Locale}
+	d.newPage()
+	d.text(pdfMargin, d.y, 18, true, m.Title)
+	d.y -= 16
+	d.text(pdfMargin, d.y, 9, false, d.locale.Tf("Généré le %s — devise de consolidation : %s", portfolio.FormatDate(m.Generated), base))
+
+	d.heading(d.locale.T("Synthèse"))
+	d.row(true, pdfSummaryColumns, d.locale.T("Investissement"), d.locale.T("Investi"), d.locale.T("Dernière NAV"), d.locale.T("Perf. ann."), d.locale.Tf("Valeur (%s)", base))
+	for _, l := range m.Summary.Investments {
+		name, nav, perf := l.Name, "—", "—"
+		if l.Closed {
+			name += " (" + d.locale.T("clôturé") + ")"
+		}
+		if l.LatestNAV != nil {
+			nav = fmt.Sprintf("%.2f", l.LatestNAV.Value.Float64())
+		}
+		if l.PerformanceRate != nil {
+			perf = fmt.Sprintf("%.2f %%", *l.PerformanceRate)
+		}
+		d.row(false, pdfSummaryColumns, name, fmt.Sprintf("%.2f", l.NetInvested.Float64()), nav, perf, fmt.Sprintf("%.2f", l.Value))
+	}
+	d.row(true, pdfSummaryColumns, d.locale.T("Total"), fmt.Sprintf("%.2f", m.Summary.TotalInvested), "", "", fmt.Sprintf("%.2f", m.Summary.TotalValue))
+
+	if m.Performance != nil {
+		d.heading(d.locale.T("Performances annuelles"))
+		d.performanceTable(m.Performance, nil)
+	}
+
+	if len(m.Projections) > 0 {
+		d.heading(d.locale.T("Projections"))
+		columns := []float64{0, 300}
+		for _, proj := range m.Projections {
+			d.row(false, columns, portfolio.FormatDate(proj.Date), fmt.Sprintf("%.2f %s", proj.Total, base))
+		}
+		for _, proj := range m.Projections {
+			if len(proj.Skipped) > 0 {
+				d.row(false, columns, d.locale.Tf("Écartés faute de NAV au %s : %s", portfolio.FormatDate(proj.Date), proj.SkippedList()))
+			}
+		}
+	}
+
+	if len(m.Series) > 0 {
+		d.heading(d.locale.T("Évolution et projection de la valeur"))
+		d.valueChart(m.Series, m.Projections)
+	}
+
+	if len(m.Allocation) > 0 {
+		d.heading(d.locale.Tf("Répartition (%s)", m.Tag))
+		labels := make([]string, 0, len(m.Allocation))
+		for label := range m.Allocation {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		columns := []float64{0, 300}
+		for _, label := range labels {
+			d.row(false, columns, label, fmt.Sprintf("%.2f %%", m.Allocation[label]))
+		}
+	}
+
+	for _, l := range m.Summary.Investments {
+		d.newPage()
+		d.investmentPage(l, m.Performance)
+	}
+
+	_, err = d.WriteTo(w)
+	return err
+}
+
+// investmentPage écrit le détail d'un investissement sur la page courante
+func (d *pdfDocument) investmentPage(l portfolio.SummaryLine, performance *portfolio.PerformanceTable) {
+	d.text(pdfMargin, d.y, 16, true, l.Name)
+	d.heading(d.locale.T("Caractéristiques"))
+
+	columns := []float64{0, 350}
+	cur := string(l.Currency)
+	d.row(false, columns, d.locale.T("Devise"), cur)
+	d.row(false, columns, d.locale.T("Date d'investissement"), portfolio.FormatDate(l.InvestmentDate.Time))
+	if l.Closed {
+		d.row(false, columns, d.locale.T("Clôturé le"), portfolio.FormatDate(l.ClosedDate.Time))
+	}
+	d.row(false, columns, d.locale.T("Montant investi"), fmt.Sprintf("%.2f %s", l.AmountInvested.Float64(), cur))
+	d.row(false, columns, d.locale.T("Capital net investi"), fmt.Sprintf("%.2f %s", l.NetInvested.Float64(), cur))
+	d.row(false, columns, d.locale.T("Taux de référence"), fmt.Sprintf("%.2f %%", l.ReferenceRate))
+	if l.LatestNAV != nil {
+		d.row(false, columns, d.locale.Tf("Dernière NAV (%s)", portfolio.FormatDate(l.LatestNAV.Date.Time)), fmt.Sprintf("%.2f %s", l.LatestNAV.Value.Float64(), cur))
+	}
+	if l.PerformanceRate != nil {
+		d.row(false, columns, d.locale.T("Taux de performance annuel"), fmt.Sprintf("%.2f %%", *l.PerformanceRate))
+	}
+	if l.Distributions != 0 || l.Reinvested != 0 {
+		d.row(false, columns, d.locale.T("Distributions versées"), fmt.Sprintf("%.2f %s", l.Distributions.Float64(), cur))
+		d.row(false, columns, d.locale.T("Distributions réinvesties"), fmt.Sprintf("%.2f %s", l.Reinvested.Float64(), cur))
+	}
+	if l.Position != nil {
+		d.row(false, columns, d.locale.T("Parts détenues"), l.Position.Units.String())
+		d.row(false, columns, d.locale.T("Prix de revient unitaire"), fmt.Sprintf("%.2f %s", l.Position.AverageCost.Float64(), cur))
+		d.row(false, columns, d.locale.T("Plus-value réalisée"), fmt.Sprintf("%.2f %s", l.Position.RealizedGain.Float64(), cur))
+		d.row(false, columns, d.locale.T("Plus-value latente"), fmt.Sprintf("%.2f %s", l.Position.UnrealizedGain.Float64(), cur))
+	}
+
+	if len(l.Notes) > 0 {
+		d.heading(d.locale.T("Notes"))
+		for _, e := range l.Notes {
+			d.row(false, []float64{0, 80}, portfolio.FormatDate(e.Date.Time), Truncate(e.Label(d.locale), 90))
+		}
+	}
+
+	if performance != nil {
+		for _, row := range performance.Rows {
+			if row.Name == l.Name {
+				d.heading(d.locale.T("Performances annuelles"))
+				d.performanceTable(performance, &row)
+				break
+			}
+		}
+	}
+}
+
+// performanceTable écrit le tableau des performances annuelles, limité à une ligne si only est renseigné
+func (d *pdfDocument) performanceTable(table *portfolio.PerformanceTable, only *portfolio.PerformanceRow) {
+	// Les années les plus récentes tiennent dans la largeur de la page
+	years := table.Years
+	if len(years) > 5 {
+		years = years[len(years)-5:]
+	}
+	columns := []float64{0}
+	header := []string{""}
+	for i, year := range years {
+		columns = append(columns, 220+float64(i+1)*55)
+		header = append(header, table.YearLabel(year))
+	}
+	columns = append(columns, pdfPageWidth-pdfMargin)
+	header = append(header, d.locale.T("Année en cours"))
+	d.row(true, columns, header...)
+
+	for _, row := range table.Rows {
+		if only != nil && row.Name != only.Name {
+			continue
+		}
+		cells := []string{row.Name}
+		for _, year := range years {
+			value, ok := row.Annual[year]
+			cells = append(cells, portfolio.FormatPercentCell(value, ok))
+		}
+		ytd := "-"
+		if row.YTD != nil {
+			ytd = portfolio.FormatPercentCell(*row.YTD, true)
+		}
+		d.row(false, columns, append(cells, ytd)...)
+	}
+}
+
+// valueChart trace la valeur historique du portefeuille en trait plein, prolongée en
+// pointillés jusqu'aux projections
+func (d *pdfDocument) valueChart(series []portfolio.ValuePoint, projections []reportProjection) {
+	const height = 200.0
+	d.ensure(height + 30)
+	left, right := pdfMargin+50, pdfPageWidth-pdfMargin
+	bottom, top := d.y-height, d.y-10
+
+	type point struct {
+		date  time.Time
+		value float64
+	}
+	history := make([]point, len(series))
+	for i, v := range series {
+		history[i] = point{v.Date.Time, v.Total}
+	}
+	projected := []point{history[len(history)-1]}
+	for _, proj := range projections {
+		if proj.Date.After(projected[len(projected)-1].date) {
+			projected = append(projected, point{proj.Date, proj.Total})
+		}
+	}
+
+	first, last := history[0].date, projected[len(projected)-1].date
+	minValue, maxValue := history[0].value, history[0].value
+	for _, pt := range append(history, projected...) {
+		minValue = min(minValue, pt.value)
+		maxValue = max(maxValue, pt.value)
+	}
+	if maxValue == minValue {
+		maxValue = minValue + 1
+	}
+	span := last.Sub(first).Seconds()
+	x := func(t time.Time) float64 {
+		if span == 0 {
+			return (left + right) / 2
+		}
+		return left + (right-left)*t.Sub(first).Seconds()/span
+	}
+	y := func(v float64) float64 {
+		return bottom + (top-bottom)*(v-minValue)/(maxValue-minValue)
+	}
+	path := func(points []point) {
+		for i, pt := range points {
+			op := "l"
+			if i == 0 {
+				op = "m"
+			}
+			fmt.Fprintf(d.page(), "%.2f %.2f %s ", x(pt.date), y(pt.value), op)
+		}
+		d.page().WriteString("S\n")
+	}
+
+	d.page().WriteString("0.5 G 0.5 w\n")
+	d.line(left, bottom, right, bottom)
+	d.line(left, bottom, left, top)
+	d.textRight(left-4, y(maxValue)-3, 8, fmt.Sprintf("%.0f", maxValue))
+	d.textRight(left-4, y(minValue)-3, 8, fmt.Sprintf("%.0f", minValue))
+	d.text(left, bottom-12, 8, false, portfolio.FormatDate(first))
+	d.textRight(right, bottom-12, 8, portfolio.FormatDate(last))
+
+	d.page().WriteString("0.16 0.44 0.69 RG 1.5 w\n")
+	path(history)
+	if len(projected) > 1 {
+		d.page().WriteString("[4 3] 0 d\n")
+		path(projected)
+		d.page().WriteString("[] 0 d\n")
+	}
+	d.page().WriteString("0 G 1 w\n")
+	d.y = bottom - 20
+}
@@ -0,0 +1,270 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// PlotFormat est le format d'image des graphiques
+type PlotFormat string
+
+const (
+	PlotSVG PlotFormat = "svg"
+	PlotPNG PlotFormat = "png"
+)
+
+// PlotOptions précise le graphique de valeur du portefeuille
+type PlotOptions struct {
+	From, To  string               // Période de l'historique (AAAA-MM-JJ), sans limite si vide
+	Step      portfolio.SeriesStep // Pas de l'historique (StepMonthly si vide)
+	ProjectTo string               // Date jusqu'à laquelle la valeur est projetée, aucune projection si vide
+}
+
+// Dimensions des graphiques, en pixels
+const (
+	plotWidth   = 720
+	plotHeight  = 320
+	plotPadding = 60
+)
+
+// plotPoint est un point d'un graphique
+type plotPoint struct {
+	date  time.Time
+	value float64
+}
+
+// plot est un graphique de valeur : historique en trait plein, prolongé en pointillés
+// par la projection
+type plot struct {
+	title      string
+	history    []plotPoint
+	projection []plotPoint // Commence au dernier point de l'historique
+}
+
+// PlotNAV trace l'historique des NAV de l'investissement, prolongé jusqu'à projectTo par
+// la projection au taux de l'investissement (aucune projection si projectTo est vide)
+func PlotNAV(inv *portfolio.Investment, w io.Writer, format PlotFormat, projectTo string) error {
+	if len(inv.NAVHistory) == 0 {
+		return fmt.Errorf("aucune NAV pour %s: %w", inv.Name, portfolio.ErrInsufficientHistory)
+	}
+	c := plot{title: inv.Name}
+	for _, nav := range inv.NAVHistory {
+		c.history = append(c.history, plotPoint{nav.Date.Time, nav.Value.Float64()})
+	}
+	if projectTo != "" {
+		err := c.project(projectTo, func(date string) (float64, error) { return inv.ProjectNAV(date) })
+		if err != nil {
+			return err
+		}
+	}
+	return c.write(w, format)
+}
+
+// PlotValue trace la valeur du portefeuille dans la devise de consolidation, prolongée
+// par la projection jusqu'à opts.ProjectTo
+func PlotValue(p *portfolio.Portfolio, w io.Writer, format PlotFormat, opts PlotOptions) error {
+	step := opts.Step
+	if step == "" {
+		step = portfolio.StepMonthly
+	}
+	series, err := p.ValueSeries(opts.From, opts.To, step)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		return fmt.Errorf("aucune valeur sur la période: %w", portfolio.ErrInsufficientHistory)
+	}
+	c := plot{title: fmt.Sprintf("Valeur du portefeuille (%s)", p.AmountFormatter().Currency)}
+	for _, point := range series {
+		c.history = append(c.history, plotPoint{point.Date.Time, point.Total})
+	}
+	if opts.ProjectTo != "" {
+		err := c.project(opts.ProjectTo, func(date string) (float64, error) {
+			_, total, err := p.GetPortfolioValue(date)
+			return total, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return c.write(w, format)
+}
+
+// project calcule la projection mois par mois depuis le dernier point de l'historique
+func (c *plot) project(to string, value func(date string) (float64, error)) error {
+	end, err := portfolio.ParseDate(to)
+	if err != nil {
+		return err
+	}
+	last := c.history[len(c.history)-1]
+	if !end.After(last.date) {
+		return fmt.Errorf("la date de projection doit suivre le %s: %w", portfolio.FormatDate(last.date), portfolio.ErrInvalidDate)
+	}
+	c.projection = []plotPoint{last}
+	for date := last.date.AddDate(0, 1, 0); ; date = date.AddDate(0, 1, 0) {
+		if date.After(end) {
+			date = end
+		}
+		v, err := value(portfolio.FormatDate(date))
+		if err != nil {
+			return err
+		}
+		c.projection = append(c.projection, plotPoint{date, v})
+		if date.Equal(end) {
+			return nil
+		}
+	}
+}
+
+// write rend le graphique dans le format demandé
+func (c *plot) write(w io.Writer, format PlotFormat) error {
+	switch format {
+	case PlotSVG:
+		return c.writeSVG(w)
+	case PlotPNG:
+		return c.writePNG(w)
+	default:
+		return portfolio.InvalidField("format", format, "format d'image inconnu: %s (svg, png)", format)
+	}
+}
+
+// scale retourne les fonctions de placement des points et les bornes des axes
+func (c *plot) scale() (x func(time.Time) float64, y func(float64) float64, first, last time.Time, low, high float64) {
+	points := append(append([]plotPoint(nil), c.history...), c.projection...)
+	first, last = points[0].date, points[len(points)-1].date
+	low, high = points[0].value, points[0].value
+	for _, pt := range points {
+		low, high = math.Min(low, pt.value), math.Max(high, pt.value)
+	}
+	if high == low {
+		high = low + 1
+	}
+	span := last.Sub(first).Seconds()
+	x = func(t time.Time) float64 {
+		if span == 0 {
+			return plotWidth / 2
+		}
+		return plotPadding + (plotWidth-2*plotPadding)*t.Sub(first).Seconds()/span
+	}
+	y = func(v float64) float64 {
+		return plotPadding + (plotHeight-2*plotPadding)*(1-(v-low)/(high-low))
+	}
+	return x, y, first, last, low, high
+}
+
+// writeSVG rend le graphique en SVG
+func (c *plot) writeSVG(w io.Writer) error {
+	x, y, first, last, low, high := c.scale()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`+"\n", plotWidth, plotHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", plotWidth, plotHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="24" font-size="14" font-weight="bold">%s</text>`+"\n", plotPadding, html.EscapeString(c.title))
+	fmt.Fprintf(&b, `<path d="M%d %d V%d H%d" fill="none" stroke="#999"/>`+"\n", plotPadding, plotPadding, plotHeight-plotPadding, plotWidth-plotPadding)
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.0f</text>`+"\n", plotPadding-6, y(high)+4, high)
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.0f</text>`+"\n", plotPadding-6, y(low)+4, low)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", plotPadding, plotHeight-plotPadding+18, portfolio.FormatDate(first))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", plotWidth-plotPadding, plotHeight-plotPadding+18, portfolio.FormatDate(last))
+
+	polyline := func(points []plotPoint, dash string) {
+		b.WriteString(`<polyline fill="none" stroke="#2a6fb0" stroke-width="2"` + dash + ` points="`)
+		for _, pt := range points {
+			fmt.Fprintf(&b, "%.1f,%.1f ", x(pt.date), y(pt.value))
+		}
+		b.WriteString("\"/>\n")
+	}
+	polyline(c.history, "")
+	if len(c.projection) > 1 {
+		polyline(c.projection, ` stroke-dasharray="6 4"`)
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writePNG rend le graphique en PNG ; les libellés des axes utilisent une police
+// matricielle réduite aux chiffres, ce qui suffit pour les dates et les montants
+func (c *plot) writePNG(w io.Writer) error {
+	x, y, first, last, low, high := c.scale()
+	img := image.NewRGBA(image.Rect(0, 0, plotWidth, plotHeight))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	axis := color.RGBA{0x99, 0x99, 0x99, 0xff}
+	line := color.RGBA{0x2a, 0x6f, 0xb0, 0xff}
+	text := color.RGBA{0x33, 0x33, 0x33, 0xff}
+
+	drawLine(img, plotPadding, plotPadding, plotPadding, plotHeight-plotPadding, axis, 1, 0)
+	drawLine(img, plotPadding, plotHeight-plotPadding, plotWidth-plotPadding, plotHeight-plotPadding, axis, 1, 0)
+	drawDigits(img, plotPadding-6, int(y(high))-5, fmt.Sprintf("%.0f", high), text, true)
+	drawDigits(img, plotPadding-6, int(y(low))-5, fmt.Sprintf("%.0f", low), text, true)
+	drawDigits(img, plotPadding, plotHeight-plotPadding+8, portfolio.FormatDate(first), text, false)
+	drawDigits(img, plotWidth-plotPadding, plotHeight-plotPadding+8, portfolio.FormatDate(last), text, true)
+
+	polyline := func(points []plotPoint, dash int) {
+		for i := 1; i < len(points); i++ {
+			drawLine(img, x(points[i-1].date), y(points[i-1].value), x(points[i].date), y(points[i].value), line, 2, dash)
+		}
+	}
+	polyline(c.history, 0)
+	polyline(c.projection, 6)
+	return png.Encode(w, img)
+}
+
+// drawLine trace un segment d'épaisseur width ; dash > 0 alterne traits et espaces de
+// dash pixels selon l'abscisse, pour que les pointillés se suivent d'un segment à l'autre
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA, width, dash int) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		px, py := int(math.Round(x0+(x1-x0)*t)), int(math.Round(y0+(y1-y0)*t))
+		if dash > 0 && (px/dash)%2 == 1 {
+			continue
+		}
+		for dx := 0; dx < width; dx++ {
+			for dy := 0; dy < width; dy++ {
+				img.SetRGBA(px+dx-width/2, py+dy-width/2, c)
+			}
+		}
+	}
+}
+
+// digitFont est une police matricielle 3×5 des chiffres, du tiret et du point ; chaque
+// ligne est un masque de 3 bits, le bit de poids fort à gauche
+var digitFont = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7}, '1': {2, 6, 2, 2, 7}, '2': {7, 1, 7, 4, 7}, '3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1}, '5': {7, 4, 7, 1, 7}, '6': {7, 4, 7, 5, 7}, '7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7}, '9': {7, 5, 7, 1, 7}, '-': {0, 0, 7, 0, 0}, '.': {0, 0, 0, 0, 2},
+}
+
+// drawDigits écrit un texte en police matricielle agrandie deux fois, à partir de x ou
+// se terminant en x si alignRight ; les caractères inconnus sont laissés en blanc
+func drawDigits(img *image.RGBA, x, y int, s string, c color.RGBA, alignRight bool) {
+	const scale, advance = 2, 8
+	if alignRight {
+		x -= len(s) * advance
+	}
+	for i, r := range s {
+		glyph := digitFont[r]
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				for dx := 0; dx < scale; dx++ {
+					for dy := 0; dy < scale; dy++ {
+						img.SetRGBA(x+i*advance+col*scale+dx, y+row*scale+dy, c)
+					}
+				}
+			}
+		}
+	}
+}
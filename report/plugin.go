@@ -0,0 +1,58 @@
+package report
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// pluginReport est la forme échangée d'un rapport (méthode render)
+type pluginReport struct {
+	Title       string                      `json:"title"`
+	Locale      portfolio.Locale            `json:"locale"`
+	Generated   string                      `json:"generated"`
+	Summary     *portfolio.PortfolioSummary `json:"summary"`
+	Series      []pluginPoint               `json:"series"`
+	Tag         string                      `json:"tag"`
+	Allocation  map[string]float64          `json:"allocation,omitempty"`
+	Performance *portfolio.PerformanceTable `json:"performance,omitempty"`
+	Projections []pluginPoint               `json:"projections"`
+}
+
+type pluginPoint struct {
+	Date   string             `json:"date"`
+	Total  float64            `json:"total"`
+	Values map[string]float64 `json:"values,omitempty"`
+}
+
+// RenderPlugin met en forme le rapport avec une extension de rendu, au format
+// format (le premier qu'elle déclare si vide)
+func RenderPlugin(p *portfolio.Portfolio, w io.Writer, pl portfolio.Plugin, format string, opts ReportOptions) error {
+	m, err := buildReport(p, opts)
+	if err != nil {
+		return err
+	}
+	report := pluginReport{
+		Title: m.Title, Locale: m.Locale, Generated: m.Generated.Format(time.RFC3339), Summary: m.Summary,
+		Tag: m.Tag, Allocation: m.Allocation, Performance: m.Performance,
+		Series: make([]pluginPoint, 0, len(m.Series)), Projections: make([]pluginPoint, 0, len(m.Projections)),
+	}
+	for _, v := range m.Series {
+		report.Series = append(report.Series, pluginPoint{Date: portfolio.FormatDate(v.Date.Time), Total: v.Total, Values: v.Values})
+	}
+	for _, proj := range m.Projections {
+		report.Projections = append(report.Projections, pluginPoint{Date: portfolio.FormatDate(proj.Date), Total: proj.Total})
+	}
+
+	var result struct {
+		Content string `json:"content"`
+	}
+	params := map[string]any{"format": format, "report": report}
+	if err := pl.Call(context.Background(), "render", params, &result); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, result.Content)
+	return err
+}
@@ -0,0 +1,313 @@
+// Package report met en forme le portefeuille : rapports HTML, Markdown, PDF, par gabarit
+// ou par extension, classeurs Excel, graphiques, envoi mensuel par courriel et
+// surveillance périodique.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// ReportOptions paramètre le rapport HTML
+type ReportOptions struct {
+	Title           string               // Titre de la page, "Rapport de portefeuille" par défaut
+	Step            portfolio.SeriesStep // Pas du graphique de valeur, mensuel par défaut
+	AllocationTag   string               // Étiquette de la répartition, TagAssetClass par défaut
+	ProjectionDates []string             // Dates (AAAA-MM-JJ) des projections affichées
+	Locale          portfolio.Locale     // Langue du rapport, celle du portefeuille si vide
+	Metrics         []portfolio.Metric   // Indicateurs personnalisés ajoutés au récapitulatif
+}
+
+// reportProjection est une ligne du tableau des projections
+type reportProjection struct {
+	Date    time.Time
+	Total   float64
+	Skipped []string // Investissements écartés faute de NAV, absents du total
+}
+
+// SkippedList énumère les investissements écartés de la projection
+func (r reportProjection) SkippedList() string {
+	return strings.Join(r.Skipped, ", ")
+}
+
+// reportModel rassemble les données d'un rapport, quel que soit son format de sortie
+type reportModel struct {
+	Locale      portfolio.Locale
+	Title       string
+	Generated   time.Time
+	Summary     *portfolio.PortfolioSummary
+	Series      []portfolio.ValuePoint      // Évolution de la valeur, vide sans historique
+	Tag         string                      // Étiquette de la répartition
+	Allocation  map[string]float64          // Répartition à la dernière date de la série, nil sans historique
+	Performance *portfolio.PerformanceTable // Performances annuelles, nil sans NAV
+	Projections []reportProjection
+}
+
+// buildReport collecte les données du rapport. Un portefeuille sans historique produit
+// un modèle sans série, répartition ni performances.
+func buildReport(p *portfolio.Portfolio, opts ReportOptions) (*reportModel, error) {
+	if opts.Locale == "" {
+		opts.Locale = p.DisplayLocale()
+	}
+	if err := opts.Locale.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Title == "" {
+		opts.Title = opts.Locale.T("Rapport de portefeuille")
+	}
+	if opts.Step == "" {
+		opts.Step = portfolio.StepMonthly
+	}
+	if opts.AllocationTag == "" {
+		opts.AllocationTag = portfolio.TagAssetClass
+	}
+
+	summary, err := p.SummaryWith(portfolio.SummaryOptions{Metrics: opts.Metrics})
+	if err != nil {
+		return nil, err
+	}
+	m := &reportModel{
+		Locale:    opts.Locale,
+		Title:     opts.Title,
+		Generated: time.Now(),
+		Summary:   summary,
+		Tag:       opts.AllocationTag,
+	}
+
+	if series, err := p.ValueSeries("", "", opts.Step); err == nil && len(series) > 0 {
+		m.Series = series
+		if allocation, err := p.AllocationByTag(opts.AllocationTag, portfolio.FormatDate(series[len(series)-1].Date.Time)); err == nil {
+			m.Allocation = allocation
+		}
+	}
+	if table, err := p.AnnualReturns(); err == nil {
+		m.Performance = table
+	}
+
+	for _, date := range opts.ProjectionDates {
+		t, err := portfolio.ParseDate(date)
+		if err != nil {
+			return nil, err
+		}
+		v, err := p.GetPortfolioValuation(date)
+		if err != nil {
+			return nil, fmt.Errorf("projection au %s: %w", date, err)
+		}
+		m.Projections = append(m.Projections, reportProjection{Date: t, Total: v.Total, Skipped: v.Skipped})
+	}
+	return m, nil
+}
+
+// HasNotes indique si un investissement du rapport porte des notes
+func (m *reportModel) HasNotes() bool {
+	for _, line := range m.Summary.Investments {
+		if len(line.Notes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlReport est le modèle du rapport complété des graphiques SVG
+type htmlReport struct {
+	*reportModel
+	ValueChart      template.HTML
+	AllocationChart template.HTML
+}
+
+// RenderHTML écrit un rapport HTML autonome : tableau récapitulatif, évolution de
+// la valeur du portefeuille, répartition selon une étiquette, performances annuelles et
+// projections. Les graphiques sont en SVG intégré, la page ne dépend d'aucune ressource
+// externe.
+func RenderHTML(p *portfolio.Portfolio, w io.Writer, opts ReportOptions) error {
+	m, err := buildReport(p, opts)
+	if err != nil {
+		return err
+	}
+	data := htmlReport{reportModel: m}
+	if len(m.Series) > 0 {
+		data.ValueChart = valueChartSVG(m.Series, m.Locale)
+	}
+	if m.Allocation != nil {
+		data.AllocationChart = allocationChartSVG(m.Allocation, m.Locale)
+	}
+
+	// Le gabarit est cloné pour lier ses traductions à la langue du rapport
+	tmpl, err := reportTemplate.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{"t": m.Locale.T, "tf": m.Locale.Tf, "note": func(e portfolio.NoteEntry) string { return e.Label(m.Locale) }})
+	return tmpl.Execute(w, data)
+}
+
+// Dimensions des graphiques SVG
+const (
+	chartWidth   = 720
+	chartHeight  = 260
+	chartPadding = 50
+	barHeight    = 22
+)
+
+// valueChartSVG trace la valeur totale du portefeuille au fil du temps
+func valueChartSVG(series []portfolio.ValuePoint, l portfolio.Locale) template.HTML {
+	minValue, maxValue := series[0].Total, series[0].Total
+	for _, point := range series {
+		minValue = min(minValue, point.Total)
+		maxValue = max(maxValue, point.Total)
+	}
+	if maxValue == minValue {
+		maxValue = minValue + 1
+	}
+
+	plotWidth := float64(chartWidth - 2*chartPadding)
+	plotHeight := float64(chartHeight - 2*chartPadding)
+	x := func(i int) float64 {
+		if len(series) == 1 {
+			return chartPadding + plotWidth/2
+		}
+		return chartPadding + plotWidth*float64(i)/float64(len(series)-1)
+	}
+	y := func(v float64) float64 {
+		return chartPadding + plotHeight*(1-(v-minValue)/(maxValue-minValue))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s">`, chartWidth, chartHeight, template.HTMLEscapeString(l.T("Évolution de la valeur")))
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" class="axis"/>`, chartPadding, chartHeight-chartPadding, chartWidth-chartPadding, chartHeight-chartPadding)
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="label" text-anchor="end">%.0f</text>`, chartPadding-6, y(maxValue)+4, maxValue)
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="label" text-anchor="end">%.0f</text>`, chartPadding-6, y(minValue)+4, minValue)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label">%s</text>`, chartPadding, chartHeight-chartPadding+18, portfolio.FormatDate(series[0].Date.Time))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label" text-anchor="end">%s</text>`, chartWidth-chartPadding, chartHeight-chartPadding+18, portfolio.FormatDate(series[len(series)-1].Date.Time))
+
+	b.WriteString(`<polyline class="line" points="`)
+	for i, point := range series {
+		fmt.Fprintf(&b, "%.1f,%.1f ", x(i), y(point.Total))
+	}
+	b.WriteString(`"/></svg>`)
+	return template.HTML(b.String())
+}
+
+// allocationChartSVG trace la répartition en barres horizontales, de la plus grande à la plus petite
+func allocationChartSVG(allocation map[string]float64, l portfolio.Locale) template.HTML {
+	labels := make([]string, 0, len(allocation))
+	for label := range allocation {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if allocation[labels[i]] != allocation[labels[j]] {
+			return allocation[labels[i]] > allocation[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+
+	height := len(labels)*(barHeight+8) + 10
+	labelWidth := 160
+	plotWidth := float64(chartWidth - labelWidth - 70)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s">`, chartWidth, height, template.HTMLEscapeString(l.T("Répartition")))
+	for i, label := range labels {
+		top := 5 + i*(barHeight+8)
+		width := plotWidth * max(allocation[label], 0) / 100
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="label" text-anchor="end">%s</text>`, labelWidth-8, top+barHeight-6, template.HTMLEscapeString(label))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" class="bar"/>`, labelWidth, top, width, barHeight)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="label">%.1f %%</text>`, float64(labelWidth)+width+6, top+barHeight-6, allocation[label])
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money":  func(m portfolio.Money) string { return fmt.Sprintf("%.2f", m.Float64()) },
+	"amount": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"percent": func(v *float64) string {
+		if v == nil {
+			return "—"
+		}
+		return fmt.Sprintf("%.2f %%", *v)
+	},
+	"annual": func(row portfolio.PerformanceRow, year int) string {
+		value, ok := row.Annual[year]
+		if !ok {
+			return "—"
+		}
+		return fmt.Sprintf("%.2f %%", value)
+	},
+	"date": func(v any) (string, error) {
+		t, err := templateDate(v)
+		return portfolio.FormatDate(t), err
+	},
+	// Traductions liées à la langue du rapport par RenderHTML
+	"t":    portfolio.DefaultLocale.T,
+	"tf":   portfolio.DefaultLocale.Tf,
+	"note": func(e portfolio.NoteEntry) string { return e.Label(portfolio.DefaultLocale) },
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; }
+td.num { text-align: right; }
+tr.closed { color: #888; }
+.axis { stroke: #888; }
+.line { fill: none; stroke: #2a6fb0; stroke-width: 2; }
+.bar { fill: #2a6fb0; }
+.label { font-size: 12px; fill: #444; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{tf "Généré le %s — devise de consolidation : %s" (date .Generated) .Summary.BaseCurrency}}</p>
+
+<h2>{{t "Synthèse"}}</h2>
+<table>
+<tr><th>{{t "Investissement"}}</th><th>{{t "Devise"}}</th><th>{{t "Investi"}}</th><th>{{t "Dernière NAV"}}</th><th>{{t "Date"}}</th><th>{{t "Performance annuelle"}}</th><th>{{tf "Valeur (%s)" .Summary.BaseCurrency}}</th></tr>
+{{range .Summary.Investments}}<tr{{if .Closed}} class="closed"{{end}}>
+<td>{{.Name}}{{if .Closed}} ({{tf "clôturé le %s" (date .ClosedDate)}}){{end}}</td>
+<td>{{.Currency}}</td>
+<td class="num">{{money .NetInvested}}</td>
+{{if .LatestNAV}}<td class="num">{{money .LatestNAV.Value}}</td><td>{{date .LatestNAV.Date}}</td>{{else}}<td>—</td><td>—</td>{{end}}
+<td class="num">{{percent .PerformanceRate}}</td>
+<td class="num">{{amount .Value}}</td>
+</tr>
+{{end}}<tr><th colspan="2">{{t "Total"}}</th><td class="num">{{amount .Summary.TotalInvested}}</td><td colspan="3"></td><td class="num">{{amount .Summary.TotalValue}}</td></tr>
+</table>
+{{if .ValueChart}}
+<h2>{{t "Évolution de la valeur"}}</h2>
+{{.ValueChart}}
+{{end}}{{if .AllocationChart}}
+<h2>{{tf "Répartition (%s)" .Tag}}</h2>
+{{.AllocationChart}}
+{{end}}{{with .Performance}}
+<h2>{{t "Performances annuelles"}}</h2>
+<table>
+<tr><th></th>{{range .Years}}<th>{{$.Performance.YearLabel .}}</th>{{end}}<th>{{t .YTDLabel}}</th></tr>
+{{range $row := .Rows}}<tr><td>{{$row.Name}}</td>{{range $.Performance.Years}}<td class="num">{{annual $row .}}</td>{{end}}<td class="num">{{percent $row.YTD}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Projections}}
+<h2>{{t "Projections"}}</h2>
+<table>
+<tr><th>{{t "Date"}}</th><th>{{tf "Valeur projetée (%s)" .Summary.BaseCurrency}}</th></tr>
+{{range .Projections}}<tr><td>{{date .Date}}</td><td class="num">{{amount .Total}}</td></tr>
+{{end}}</table>
+{{range .Projections}}{{if .Skipped}}<p>{{tf "Écartés faute de NAV au %s : %s" (date .Date) .SkippedList}}</p>
+{{end}}{{end}}{{end}}{{if .HasNotes}}
+<h2>{{t "Notes"}}</h2>
+{{range .Summary.Investments}}{{if .Notes}}<h3>{{.Name}}</h3>
+<ul>
+{{range .Notes}}<li>{{date .Date}} — {{note .}}</li>
+{{end}}</ul>
+{{end}}{{end}}{{end}}
+</body>
+</html>
+`))
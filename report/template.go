@@ -0,0 +1,110 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// RenderTemplate écrit un rapport mis en forme par un gabarit text/template de
+// l'utilisateur, un message d'une ligne comme une revue détaillée. Le gabarit reçoit les
+// données des autres rapports : .Title, .Generated, .Locale, .Summary (BaseCurrency,
+// TotalInvested, TotalValue et les lignes .Investments), .Series, .Tag, .Allocation,
+// .Performance et .Projections. Les fonctions t et tf traduisent, date formate une date,
+// amount un montant en devise de consolidation (amountIn dans une autre devise), percent
+// un pourcentage éventuellement absent ; upper, lower, join et repeat reprennent le
+// paquet strings.
+func RenderTemplate(p *portfolio.Portfolio, w io.Writer, name, text string, opts ReportOptions) error {
+	// Le gabarit est analysé avant la collecte des données pour signaler tôt ses erreurs
+	var m *reportModel
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(reportTemplateFuncs(func() *reportModel { return m })).Parse(text)
+	if err != nil {
+		return fmt.Errorf("gabarit %s: %w", name, err)
+	}
+	if m, err = buildReport(p, opts); err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, m); err != nil {
+		return fmt.Errorf("gabarit %s: %w", name, err)
+	}
+	return nil
+}
+
+// reportTemplateFuncs retourne les fonctions des gabarits de l'utilisateur ; model donne
+// le modèle en cours de rendu, qui fixe la langue et la devise de consolidation
+func reportTemplateFuncs(model func() *reportModel) template.FuncMap {
+	return template.FuncMap{
+		"t":  func(s string) string { return model().Locale.T(s) },
+		"tf": func(format string, args ...any) string { return model().Locale.Tf(format, args...) },
+		"date": func(v any) (string, error) {
+			t, err := templateDate(v)
+			if err != nil || t.IsZero() {
+				return "-", err
+			}
+			return portfolio.FormatDate(t), nil
+		},
+		"amount": func(v any) (string, error) {
+			m := model()
+			return templateAmount(v, m.Summary.BaseCurrency, m.Locale)
+		},
+		"amountIn": func(cur portfolio.Currency, v any) (string, error) {
+			return templateAmount(v, cur, model().Locale)
+		},
+		"percent": func(v any) (string, error) {
+			f, ok, err := templateNumber(v)
+			if err != nil {
+				return "", err
+			}
+			return portfolio.FormatPercentCell(f, ok), nil
+		},
+		"upper":  strings.ToUpper,
+		"lower":  strings.ToLower,
+		"join":   strings.Join,
+		"repeat": strings.Repeat,
+	}
+}
+
+// templateAmount formate un montant d'un gabarit, "-" s'il est absent
+func templateAmount(v any, cur portfolio.Currency, l portfolio.Locale) (string, error) {
+	f, ok, err := templateNumber(v)
+	if err != nil || !ok {
+		return "-", err
+	}
+	return portfolio.FormatAmount(f, cur, l), nil
+}
+
+// templateNumber convertit une valeur numérique d'un gabarit ; ok est faux pour un
+// pointeur nil (performance ou NAV absente)
+func templateNumber(v any) (f float64, ok bool, err error) {
+	switch n := v.(type) {
+	case float64:
+		return n, true, nil
+	case *float64:
+		if n == nil {
+			return 0, false, nil
+		}
+		return *n, true, nil
+	case portfolio.Money:
+		return n.Float64(), true, nil
+	case int:
+		return float64(n), true, nil
+	case nil:
+		return 0, false, nil
+	}
+	return 0, false, fmt.Errorf("valeur non numérique: %v (%T)", v, v)
+}
+
+// templateDate convertit une date d'un gabarit, Date du modèle ou time.Time
+func templateDate(v any) (time.Time, error) {
+	switch d := v.(type) {
+	case portfolio.Date:
+		return d.Time, nil
+	case time.Time:
+		return d, nil
+	}
+	return time.Time{}, fmt.Errorf("valeur non datée: %v (%T)", v, v)
+}
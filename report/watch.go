@@ -0,0 +1,158 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// Watcher met à jour périodiquement les NAV d'un portefeuille via son fournisseur de
+// cours, enregistre les versements programmés échus, enregistre le résultat, journalise
+// les variations et notifie les alertes déclenchées
+type Watcher struct {
+	Portfolio *portfolio.Portfolio
+	Every     time.Duration        // Intervalle entre deux mises à jour
+	Timeout   time.Duration        // Délai maximal d'une mise à jour, sans limite si nul
+	Save      func() error         // Persistance après chaque mise à jour, nil pour ne rien enregistrer
+	Logger    *log.Logger          // Journal des mises à jour, log.Default() si nil
+	Notifiers []portfolio.Notifier // Destinataires des alertes évaluées après chaque mise à jour
+	Report    *ReportMailer        // Envoi du rapport mensuel, nil sans envoi
+}
+
+// Run effectue une première mise à jour immédiatement, puis une à chaque intervalle,
+// jusqu'à l'annulation de ctx. L'échec d'une mise à jour est journalisé sans arrêter la
+// surveillance ; seule une erreur d'enregistrement y met fin.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.Every <= 0 {
+		return portfolio.InvalidField("every", w.Every, "l'intervalle de mise à jour doit être positif")
+	}
+	if w.Logger == nil {
+		w.Logger = log.Default()
+	}
+
+	ticker := time.NewTicker(w.Every)
+	defer ticker.Stop()
+	for {
+		if err := w.cycle(ctx); err != nil {
+			return err
+		}
+		w.mailReport()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// cycle met à jour les NAV, journalise les variations et les indicateurs recalculés
+func (w *Watcher) cycle(ctx context.Context) error {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	before, err := w.Portfolio.Summary()
+	if err != nil {
+		w.Logger.Printf("résumé avant mise à jour: %v", err)
+	}
+	previous := make(map[string]portfolio.NAV)
+	if before != nil {
+		for _, line := range before.Investments {
+			if line.LatestNAV != nil {
+				previous[line.Name] = *line.LatestNAV
+			}
+		}
+	}
+
+	flows, err := w.Portfolio.MaterializePlans(portfolio.Today())
+	if err != nil {
+		w.Logger.Printf("versements programmés: %v", err)
+	}
+	for _, f := range flows {
+		w.Logger.Printf("%s: versement de %.2f sur %s au %s", f.Plan, f.Amount.Float64(), f.Investment, portfolio.FormatDate(f.Date))
+	}
+
+	if day := portfolio.Today(); !w.Portfolio.BusinessCalendar().IsBusinessDay(day) {
+		w.Logger.Printf("%s: jour non ouvré, cours non mis à jour", portfolio.FormatDate(day))
+		if len(flows) > 0 && w.Save != nil {
+			if err := w.Save(); err != nil {
+				return fmt.Errorf("enregistrement après mise à jour: %w", err)
+			}
+		}
+		return nil
+	}
+
+	results, err := w.Portfolio.RefreshNAVs(ctx)
+	if err != nil && len(results) == 0 {
+		w.Logger.Printf("mise à jour impossible: %v", err)
+		if len(flows) > 0 && w.Save != nil {
+			if err := w.Save(); err != nil {
+				return fmt.Errorf("enregistrement après mise à jour: %w", err)
+			}
+		}
+		return nil
+	}
+
+	updated := len(flows)
+	for _, r := range results {
+		if r.Err != nil {
+			w.Logger.Printf("%s (%s): échec: %v", r.Name, r.Identifier, r.Err)
+			continue
+		}
+		updated++
+		old, known := previous[r.Name]
+		switch {
+		case !known:
+			w.Logger.Printf("%s: première NAV %.2f au %s", r.Name, r.NAV.Value.Float64(), portfolio.FormatDate(r.NAV.Date.Time))
+		case old.Value == r.NAV.Value && old.Date.Equal(r.NAV.Date.Time):
+			w.Logger.Printf("%s: inchangée (%.2f au %s)", r.Name, r.NAV.Value.Float64(), portfolio.FormatDate(r.NAV.Date.Time))
+		default:
+			change := (r.NAV.Value.Float64()/old.Value.Float64() - 1) * 100
+			w.Logger.Printf("%s: %.2f -> %.2f (%+.2f%%) au %s", r.Name, old.Value.Float64(), r.NAV.Value.Float64(), change, portfolio.FormatDate(r.NAV.Date.Time))
+		}
+	}
+	if updated == 0 {
+		return nil
+	}
+
+	if w.Save != nil {
+		if err := w.Save(); err != nil {
+			return fmt.Errorf("enregistrement après mise à jour: %w", err)
+		}
+	}
+
+	after, err := w.Portfolio.Summary()
+	if err != nil {
+		w.Logger.Printf("résumé après mise à jour: %v", err)
+		return nil
+	}
+	line := fmt.Sprintf("valeur du portefeuille: %.2f %s", after.TotalValue, after.BaseCurrency)
+	if before != nil && before.TotalValue != 0 {
+		line += fmt.Sprintf(" (%+.2f%%)", (after.TotalValue/before.TotalValue-1)*100)
+	}
+	if after.TotalInvested != 0 {
+		line += fmt.Sprintf(", plus-value latente: %.2f (%.2f%%)", after.TotalValue-after.TotalInvested,
+			(after.TotalValue/after.TotalInvested-1)*100)
+	}
+	w.Logger.Print(line)
+
+	if len(w.Notifiers) == 0 {
+		return nil
+	}
+	alerts, err := w.Portfolio.EvaluateAlerts()
+	if err != nil {
+		w.Logger.Printf("évaluation des alertes: %v", err)
+		return nil
+	}
+	if len(alerts) > 0 {
+		if err := portfolio.NotifyAll(ctx, w.Notifiers, alerts); err != nil {
+			w.Logger.Printf("envoi des alertes: %v", err)
+		}
+	}
+	return nil
+}
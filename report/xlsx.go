@@ -0,0 +1,234 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// xlsxSheet est une feuille du classeur : un nom et des lignes de cellules. Une cellule
+// vaut une chaîne, un nombre (float64), une date (time.Time) ou nil pour rester vide.
+type xlsxSheet struct {
+	Name string
+	Rows [][]any
+}
+
+// ExportXLSX écrit un classeur Excel : une feuille de synthèse (valeurs calculées, sans
+// formule), puis une feuille par investissement avec son historique de NAV et ses flux.
+// Les en-têtes sont dans la langue du portefeuille.
+func ExportXLSX(p *portfolio.Portfolio, path string) error {
+	summary, err := p.Summary()
+	if err != nil {
+		return err
+	}
+
+	l := p.DisplayLocale()
+	sheets := []xlsxSheet{summarySheet(summary, l)}
+	used := map[string]bool{strings.ToLower(sheets[0].Name): true}
+	for _, name := range p.InvestmentNames() {
+		inv, err := p.Investment(name)
+		if err != nil {
+			return err
+		}
+		sheet := investmentSheet(inv, l)
+		sheet.Name = uniqueSheetName(name, used)
+		sheets = append(sheets, sheet)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeXLSX(f, sheets); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// summarySheet présente le résumé du portefeuille, une ligne par investissement
+func summarySheet(s *portfolio.PortfolioSummary, l portfolio.Locale) xlsxSheet {
+	base := string(s.BaseCurrency)
+	sheet := xlsxSheet{Name: l.T("Synthèse"), Rows: [][]any{{
+		l.T("Investissement"), l.T("Devise"), l.T("Date d'investissement"), l.T("Clôture"), l.T("Montant investi"),
+		l.T("Capital net investi"), l.T("Taux de référence (%)"), l.T("Date dernière NAV"), l.T("Dernière NAV"),
+		l.T("Performance annuelle (%)"), l.Tf("Valeur (%s)", base), l.T("Distributions versées"), l.T("Distributions réinvesties"),
+	}}}
+	for _, l := range s.Investments {
+		var closed, navDate, nav, perf any
+		if l.Closed {
+			closed = l.ClosedDate
+		}
+		if l.LatestNAV != nil {
+			navDate, nav = l.LatestNAV.Date, l.LatestNAV.Value.Float64()
+		}
+		if l.PerformanceRate != nil {
+			perf = *l.PerformanceRate
+		}
+		sheet.Rows = append(sheet.Rows, []any{
+			l.Name, string(l.Currency), l.InvestmentDate, closed, l.AmountInvested.Float64(), l.NetInvested.Float64(),
+			l.ReferenceRate, navDate, nav, perf, l.Value, l.Distributions.Float64(), l.Reinvested.Float64(),
+		})
+	}
+	sheet.Rows = append(sheet.Rows, nil, []any{l.Tf("Total investi (%s)", base), nil, nil, nil, nil, s.TotalInvested})
+	sheet.Rows = append(sheet.Rows, []any{l.Tf("Valeur totale (%s)", base), nil, nil, nil, nil, s.TotalValue})
+	return sheet
+}
+
+// investmentSheet place l'historique de NAV en colonnes A-B et les flux en colonnes D-F.
+// L'appelant doit détenir p.mu.
+func investmentSheet(inv *portfolio.Investment, l portfolio.Locale) xlsxSheet {
+	rows := [][]any{{l.T("Date"), l.T("NAV"), nil, l.T("Date du flux"), l.T("Type"), l.T("Montant")}}
+	for i := 0; i < max(len(inv.NAVHistory), len(inv.CashFlows)); i++ {
+		row := make([]any, 6)
+		if i < len(inv.NAVHistory) {
+			row[0], row[1] = inv.NAVHistory[i].Date, inv.NAVHistory[i].Value.Float64()
+		}
+		if i < len(inv.CashFlows) {
+			cf := inv.CashFlows[i]
+			row[3], row[4], row[5] = cf.Date, string(cf.Type), cf.SignedAmount().Float64()
+		}
+		rows = append(rows, row)
+	}
+	return xlsxSheet{Rows: rows}
+}
+
+// uniqueSheetName adapte un nom d'investissement aux contraintes d'Excel : 31 caractères
+// au plus, sans []:*?/\, et distinct des feuilles déjà nommées
+func uniqueSheetName(name string, used map[string]bool) string {
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	truncate := func(s string, n int) string {
+		if r := []rune(s); len(r) > n {
+			return string(r[:n])
+		}
+		return s
+	}
+
+	candidate := truncate(clean, 31)
+	for i := 2; used[strings.ToLower(candidate)] || candidate == ""; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = truncate(clean, 31-len(suffix)) + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// excelEpoch est l'origine des numéros de série de dates d'Excel
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// columnName convertit un index de colonne (0 pour A) en lettres Excel
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xmlEscape échappe une chaîne pour un contenu XML
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// writeXLSX écrit un classeur Office Open XML minimal. Les chaînes sont écrites en ligne
+// (sans table de chaînes partagées) et les dates au format numérique de date d'Excel.
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	z := zip.NewWriter(w)
+	write := func(name, content string) error {
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, xml.Header+content)
+		return err
+	}
+
+	var overrides, entries, rels strings.Builder
+	for i, sheet := range sheets {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&entries, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.Name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + entries.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		// Style 0 : par défaut ; style 1 : date (format intégré 14) ; style 2 : en-tête en gras
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheetXML(sheet)})
+	}
+
+	for _, part := range parts {
+		if err := write(part.name, part.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// sheetXML produit le contenu d'une feuille, la première ligne servant d'en-tête
+func sheetXML(sheet xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range sheet.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			if d, ok := value.(portfolio.Date); ok {
+				value = d.Time
+			}
+			switch v := value.(type) {
+			case string:
+				style := ""
+				if r == 0 {
+					style = ` s="2"`
+				}
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"%s><is><t>%s</t></is></c>`, ref, style, xmlEscape(v))
+			case float64:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+			case time.Time:
+				days := v.Sub(excelEpoch).Hours() / 24
+				fmt.Fprintf(&b, `<c r="%s" s="1"><v>%s</v></c>`, ref, strconv.FormatFloat(days, 'f', -1, 64))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
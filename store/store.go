@@ -0,0 +1,424 @@
+// Package store conserve les investissements et leurs NAV dans une base SQL, pour les
+// portefeuilles trop volumineux pour un fichier JSON. Le pilote SQLite n'est compilé
+// qu'avec l'étiquette de construction sqlite.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// PortfolioStore est une couche de stockage incrémental : contrairement à SaveJSON,
+// elle permet d'ajouter des NAV ou de modifier un investissement sans réécrire tout le portefeuille
+type PortfolioStore interface {
+	// LoadPortfolio charge l'ensemble des investissements et leurs historiques
+	LoadPortfolio() (*portfolio.Portfolio, error)
+	// GetInvestment charge un investissement et son historique de NAV
+	GetInvestment(name string) (*portfolio.Investment, error)
+	// SaveInvestment crée ou met à jour les données d'un investissement (hors NAV)
+	SaveInvestment(inv *portfolio.Investment) error
+	// DeleteInvestment supprime un investissement et toutes ses NAV
+	DeleteInvestment(name string) error
+	// AddNAVs ajoute des NAV à un investissement, en remplaçant celles de même date
+	AddNAVs(name string, navs ...portfolio.NAV) error
+	// DeleteNAV supprime la NAV d'un investissement à une date donnée
+	DeleteNAV(name string, date string) error
+	// Close libère les ressources du stockage
+	Close() error
+}
+
+// sqliteDriverName est le nom sous lequel le driver SQLite s'enregistre auprès de database/sql
+// (voir store_sqlite.go, compilé avec le tag de build "sqlite")
+const sqliteDriverName = "sqlite"
+
+// Les données d'un investissement (hors NAV) sont stockées en JSON pour que les
+// évolutions du modèle ne nécessitent pas de migration ; les NAV, volumineuses,
+// ont leur propre table indexée par date. Leur valeur est un portfolio.Money stocké
+// en entier (dix-millièmes d'unité, voir Money.Value) pour être relue sans arrondi.
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS investments (
+		name TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS navs (
+		investment TEXT NOT NULL REFERENCES investments(name) ON DELETE CASCADE,
+		date TEXT NOT NULL,
+		value INTEGER NOT NULL,
+		PRIMARY KEY (investment, date)
+	)`,
+}
+
+// schemaVersion est la version du schéma, conservée dans PRAGMA user_version
+const schemaVersion = 1
+
+// sqlMigrations[v] fait passer une base de la version v à la version v+1
+var sqlMigrations = []func(tx *sql.Tx) error{
+	migrateNAVUnits,
+}
+
+// migrateNAVUnits convertit les valeurs des NAV des premières bases, des réels en
+// unités, en entiers en dix-millièmes d'unité
+func migrateNAVUnits(tx *sql.Tx) error {
+	var kind string
+	if err := tx.QueryRow("SELECT type FROM pragma_table_info('navs') WHERE name = 'value'").Scan(&kind); err != nil {
+		return err
+	}
+	if !strings.EqualFold(kind, "REAL") {
+		return nil
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE navs_units (
+			investment TEXT NOT NULL REFERENCES investments(name) ON DELETE CASCADE,
+			date TEXT NOT NULL,
+			value INTEGER NOT NULL,
+			PRIMARY KEY (investment, date)
+		)`,
+		`INSERT INTO navs_units (investment, date, value)
+			SELECT investment, date, CAST(ROUND(value * 10000) AS INTEGER) FROM navs`,
+		`DROP TABLE navs`,
+		`ALTER TABLE navs_units RENAME TO navs`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SQLStore implémente PortfolioStore sur une base SQL (SQLite en pratique)
+type SQLStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore ouvre (ou crée) une base SQLite et prépare son schéma
+func OpenSQLiteStore(path string) (*SQLStore, error) {
+	// Le pilote lit ses paramètres après le premier « ? » du nom de la base
+	if strings.ContainsRune(path, '?') {
+		return nil, fmt.Errorf("chemin de base '%s' invalide: « ? » non accepté", path)
+	}
+	// PRAGMA foreign_keys ne vaut que pour la connexion qui l'exécute : passé dans le
+	// nom de la base, il s'applique à chaque connexion ouverte par database/sql
+	db, err := sql.Open(sqliteDriverName, path+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("ouverture de %s: %w", path, err)
+	}
+	// SQLite n'accepte qu'un écrivain à la fois
+	db.SetMaxOpenConns(1)
+
+	store, err := NewSQLStore(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewSQLStore crée un stockage sur une connexion existante, prépare son schéma et met à
+// niveau une base créée par une version antérieure
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	for _, stmt := range sqlSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("création du schéma: %w", err)
+		}
+	}
+	s := &SQLStore{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, fmt.Errorf("mise à niveau du schéma: %w", err)
+	}
+	return s, nil
+}
+
+// migrate applique les migrations manquantes d'après PRAGMA user_version, chacune dans
+// sa transaction
+func (s *SQLStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("base de version %d, postérieure à celle de ce programme (%d)", version, schemaVersion)
+	}
+	for ; version < schemaVersion; version++ {
+		err := s.inTx(func(tx *sql.Tx) error {
+			if err := sqlMigrations[version](tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("version %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
+
+// LoadPortfolio charge l'ensemble des investissements et leurs historiques
+func (s *SQLStore) LoadPortfolio() (*portfolio.Portfolio, error) {
+	rows, err := s.db.Query("SELECT name, data FROM investments")
+	if err != nil {
+		return nil, fmt.Errorf("lecture des investissements: %w", err)
+	}
+	defer rows.Close()
+
+	var investments []*portfolio.Investment
+	byName := make(map[string]*portfolio.Investment)
+	for rows.Next() {
+		inv, err := scanInvestment(rows)
+		if err != nil {
+			return nil, err
+		}
+		investments = append(investments, inv)
+		byName[inv.Name] = inv
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("lecture des investissements: %w", err)
+	}
+
+	navRows, err := s.db.Query("SELECT investment, date, value FROM navs ORDER BY investment, date")
+	if err != nil {
+		return nil, fmt.Errorf("lecture des NAV: %w", err)
+	}
+	defer navRows.Close()
+
+	for navRows.Next() {
+		var name string
+		nav, err := scanNAV(navRows, &name)
+		if err != nil {
+			return nil, fmt.Errorf("lecture des NAV: %w", err)
+		}
+		if inv, ok := byName[name]; ok {
+			inv.NAVHistory = append(inv.NAVHistory, nav)
+		}
+	}
+	if err := navRows.Err(); err != nil {
+		return nil, fmt.Errorf("lecture des NAV: %w", err)
+	}
+
+	return portfolio.NewPortfolioFrom(investments), nil
+}
+
+// GetInvestment charge un investissement et son historique de NAV
+func (s *SQLStore) GetInvestment(name string) (*portfolio.Investment, error) {
+	row := s.db.QueryRow("SELECT name, data FROM investments WHERE name = ?", name)
+	inv, err := scanInvestment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, portfolio.ErrInvestmentNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query("SELECT date, value FROM navs WHERE investment = ? ORDER BY date", name)
+	if err != nil {
+		return nil, fmt.Errorf("lecture des NAV de %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		nav, err := scanNAV(rows)
+		if err != nil {
+			return nil, fmt.Errorf("lecture des NAV de %s: %w", name, err)
+		}
+		inv.NAVHistory = append(inv.NAVHistory, nav)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("lecture des NAV de %s: %w", name, err)
+	}
+
+	return inv, nil
+}
+
+// SaveInvestment crée ou met à jour les données d'un investissement (hors NAV)
+func (s *SQLStore) SaveInvestment(inv *portfolio.Investment) error {
+	meta := *inv
+	meta.NAVHistory = nil
+	data, err := json.Marshal(&meta)
+	if err != nil {
+		return fmt.Errorf("sérialisation de %s: %w", inv.Name, err)
+	}
+
+	_, err = s.db.Exec(`INSERT INTO investments (name, data) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET data = excluded.data`, inv.Name, string(data))
+	if err != nil {
+		return fmt.Errorf("enregistrement de %s: %w", inv.Name, err)
+	}
+	return nil
+}
+
+// DeleteInvestment supprime un investissement et toutes ses NAV
+func (s *SQLStore) DeleteInvestment(name string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM navs WHERE investment = ?", name); err != nil {
+			return err
+		}
+		res, err := tx.Exec("DELETE FROM investments WHERE name = ?", name)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, portfolio.ErrInvestmentNotFound)
+		}
+		return nil
+	})
+}
+
+// AddNAVs ajoute des NAV à un investissement, en remplaçant celles de même date
+func (s *SQLStore) AddNAVs(name string, navs ...portfolio.NAV) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRow("SELECT COUNT(*) FROM investments WHERE name = ?", name).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("l'investissement '%s' n'existe pas: %w", name, portfolio.ErrInvestmentNotFound)
+		}
+
+		stmt, err := tx.Prepare(`INSERT INTO navs (investment, date, value) VALUES (?, ?, ?)
+			ON CONFLICT(investment, date) DO UPDATE SET value = excluded.value`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, nav := range navs {
+			if nav.Value <= 0 {
+				return fmt.Errorf("la NAV doit être positive: %w", portfolio.ErrInvalidAmount)
+			}
+			if _, err := stmt.Exec(name, portfolio.FormatDate(nav.Date.Time), nav.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteNAV supprime la NAV d'un investissement à une date donnée
+func (s *SQLStore) DeleteNAV(name string, date string) error {
+	if _, err := portfolio.ParseDate(date); err != nil {
+		return err
+	}
+	res, err := s.db.Exec("DELETE FROM navs WHERE investment = ? AND date = ?", name, date)
+	if err != nil {
+		return fmt.Errorf("suppression de la NAV de %s au %s: %w", name, date, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("aucune NAV pour %s au %s: %w", name, date, portfolio.ErrNAVNotFound)
+	}
+	return nil
+}
+
+// CompactNAVs réduit les NAV d'un investissement antérieures à cutoff (vide : toutes) à
+// la granularité, comme Portfolio.CompactNAVs, et retourne le nombre de NAV supprimées
+func (s *SQLStore) CompactNAVs(name string, granularity portfolio.SeriesStep, cutoff string) (int, error) {
+	until, err := portfolio.ParseCutoff(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	err = s.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT date, value FROM navs WHERE investment = ? ORDER BY date", name)
+		if err != nil {
+			return fmt.Errorf("lecture des NAV de %s: %w", name, err)
+		}
+		var navs []portfolio.NAV
+		for rows.Next() {
+			nav, err := scanNAV(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("lecture des NAV de %s: %w", name, err)
+			}
+			navs = append(navs, nav)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("lecture des NAV de %s: %w", name, err)
+		}
+
+		kept, err := portfolio.DownsampleNAVs(navs, granularity, until)
+		if err != nil {
+			return err
+		}
+		stmt, err := tx.Prepare("DELETE FROM navs WHERE investment = ? AND date = ?")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		// kept est une sous-suite de navs : un seul parcours suffit pour trouver les supprimées
+		for _, nav := range navs {
+			if len(kept) > 0 && kept[0].Date.Equal(nav.Date.Time) {
+				kept = kept[1:]
+				continue
+			}
+			if _, err := stmt.Exec(name, portfolio.FormatDate(nav.Date.Time)); err != nil {
+				return fmt.Errorf("suppression de la NAV de %s au %s: %w", name, portfolio.FormatDate(nav.Date.Time), err)
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Close ferme la connexion à la base
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// inTx exécute fn dans une transaction, annulée si fn retourne une erreur
+func (s *SQLStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanInvestment décode une ligne (name, data) de la table investments
+func scanInvestment(row interface{ Scan(...any) error }) (*portfolio.Investment, error) {
+	var name, data string
+	if err := row.Scan(&name, &data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lecture d'un investissement: %w", err)
+	}
+
+	inv := &portfolio.Investment{}
+	if err := json.Unmarshal([]byte(data), inv); err != nil {
+		return nil, fmt.Errorf("lecture de %s: %w", name, err)
+	}
+	inv.Name = name
+	inv.NAVHistory = make([]portfolio.NAV, 0)
+	return inv, nil
+}
+
+// scanNAV décode une ligne ([investment,] date, value) de la table navs
+func scanNAV(row interface{ Scan(...any) error }, prefix ...any) (portfolio.NAV, error) {
+	var date string
+	var value portfolio.Money
+	if err := row.Scan(append(prefix, &date, &value)...); err != nil {
+		return portfolio.NAV{}, err
+	}
+	t, err := portfolio.ParseDate(date)
+	if err != nil {
+		return portfolio.NAV{}, err
+	}
+	return portfolio.NAV{Date: portfolio.Date{Time: t}, Value: value}, nil
+}
@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package store
+
+// Driver SQLite en Go pur, enregistré sous le nom "sqlite" (voir sqliteDriverName).
+// Compiler avec `go build -tags sqlite` pour activer OpenSQLiteStore.
+import _ "modernc.org/sqlite"
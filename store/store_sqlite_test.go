@@ -0,0 +1,204 @@
+//go:build sqlite
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davidsportes-ship-it/david/portfolio"
+)
+
+// openTestStore ouvre une base SQLite vide dans un répertoire temporaire
+func openTestStore(t *testing.T) *SQLStore {
+	t.Helper()
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "david.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStoreRoundTrip(t *testing.T) {
+	p := portfolio.NewPortfolio()
+	if err := p.AddInvestmentWithQuantity("A", 100, 10, 5, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2024-06-01", 1050.1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("A", "2025-01-01", 1120); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddInvestment("B", 2000, 3, "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNAV("B", "2024-06-01", 2030); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openTestStore(t)
+	for _, name := range p.InvestmentNames() {
+		inv, err := p.Investment(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveInvestment(inv); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddNAVs(name, inv.NAVHistory...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := s.LoadPortfolio()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv, err := s.GetInvestment("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := inv.NAVHistory[0].Value, portfolio.NewMoney(1050.1234); got != want {
+		t.Errorf("NAV relue %s, %s attendu", got, want)
+	}
+	for _, date := range []string{"2025-01-01", "2030-01-01"} {
+		_, want, err := p.GetPortfolioValue(date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, got, err := loaded.GetPortfolioValue(date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("valeur au %s: %.2f après relecture, %.2f attendu", date, got, want)
+		}
+	}
+}
+
+func TestSQLiteStoreErrors(t *testing.T) {
+	s := openTestStore(t)
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{name: "NAV sans investissement", run: func() error {
+			return s.AddNAVs("X", portfolio.NAV{Date: portfolio.Date{Time: mustParse(t, "2024-01-01")}, Value: portfolio.NewMoney(100)})
+		}, want: portfolio.ErrInvestmentNotFound},
+		{name: "suppression inconnue", run: func() error { return s.DeleteInvestment("X") }, want: portfolio.ErrInvestmentNotFound},
+		{name: "lecture inconnue", run: func() error { _, err := s.GetInvestment("X"); return err }, want: portfolio.ErrInvestmentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.want) {
+				t.Errorf("erreur %v, %v attendu", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreForeignKeys(t *testing.T) {
+	s := openTestStore(t)
+	// Plusieurs connexions : la contrainte doit valoir sur chacune
+	s.db.SetMaxOpenConns(4)
+	conns := make([]*sql.Conn, 4)
+	for i := range conns {
+		c, err := s.db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		conns[i] = c
+	}
+	for i, c := range conns {
+		if _, err := c.ExecContext(context.Background(), "INSERT INTO navs (investment, date, value) VALUES ('X', '2024-01-01', 1)"); err == nil {
+			t.Errorf("connexion %d: NAV d'un investissement inconnu acceptée", i)
+		}
+	}
+}
+
+func TestSQLiteStoreMigratesRealNAVs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "david.db")
+	db, err := sql.Open(sqliteDriverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Schéma des premières bases : NAV réelles en unités, sans version
+	for _, stmt := range []string{
+		`CREATE TABLE investments (name TEXT PRIMARY KEY, data TEXT NOT NULL)`,
+		`CREATE TABLE navs (
+			investment TEXT NOT NULL REFERENCES investments(name) ON DELETE CASCADE,
+			date TEXT NOT NULL,
+			value REAL NOT NULL,
+			PRIMARY KEY (investment, date)
+		)`,
+		`INSERT INTO investments (name, data) VALUES ('A', '{"amount_invested":1000,"investment_date":"2024-01-01"}')`,
+		`INSERT INTO navs (investment, date, value) VALUES ('A', '2024-06-01', 1050.1234), ('A', '2025-01-01', 0.0001)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	for round := 1; round <= 2; round++ {
+		s, err := OpenSQLiteStore(path)
+		if err != nil {
+			t.Fatalf("ouverture %d: %v", round, err)
+		}
+		var version int
+		if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+			t.Fatal(err)
+		}
+		if version != schemaVersion {
+			t.Errorf("ouverture %d: version %d, %d attendue", round, version, schemaVersion)
+		}
+		inv, err := s.GetInvestment("A")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []portfolio.Money{portfolio.NewMoney(1050.1234), portfolio.NewMoney(0.0001)}
+		if len(inv.NAVHistory) != len(want) {
+			t.Fatalf("ouverture %d: %d NAV, %d attendues", round, len(inv.NAVHistory), len(want))
+		}
+		for i, nav := range inv.NAVHistory {
+			if nav.Value != want[i] {
+				t.Errorf("ouverture %d: NAV %d = %s, %s attendu", round, i, nav.Value, want[i])
+			}
+		}
+		s.Close()
+	}
+}
+
+func TestSQLiteStoreRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "david.db")
+	db, err := sql.Open(sqliteDriverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if s, err := OpenSQLiteStore(path); err == nil {
+		s.Close()
+		t.Error("base d'une version future acceptée")
+	}
+}
+
+func mustParse(t *testing.T, date string) time.Time {
+	t.Helper()
+	d, err := portfolio.ParseDate(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
@@ -0,0 +1,159 @@
+// Package timeseries fournit les séries temporelles génériques communes aux analyses
+// du portefeuille : recherche, alignement, rééchantillonnage, écarts et fenêtres
+// glissantes. Il ne dépend d'aucun autre paquet du module.
+package timeseries
+
+import (
+	"sort"
+	"time"
+)
+
+// Point est une observation datée d'une série temporelle
+type Point[V any] struct {
+	Date  time.Time
+	Value V
+}
+
+// Series est une série temporelle triée par date croissante. Ses primitives sont
+// communes aux analyses : indices de performance, corrélations, rendements glissants, VaR.
+type Series[V any] []Point[V]
+
+// Search retourne l'indice du premier point postérieur à date (recherche dichotomique)
+func (s Series[V]) Search(date time.Time) int {
+	return sort.Search(len(s), func(i int) bool { return s[i].Date.After(date) })
+}
+
+// First retourne la date du premier point, nulle pour une série vide
+func (s Series[V]) First() time.Time {
+	if len(s) == 0 {
+		return time.Time{}
+	}
+	return s[0].Date
+}
+
+// Last retourne la date du dernier point, nulle pour une série vide
+func (s Series[V]) Last() time.Time {
+	if len(s) == 0 {
+		return time.Time{}
+	}
+	return s[len(s)-1].Date
+}
+
+// Values retourne les valeurs d'une série, sans les dates
+func Values[V any](s Series[V]) []V {
+	out := make([]V, len(s))
+	for i, p := range s {
+		out[i] = p.Value
+	}
+	return out
+}
+
+// LastKnown retourne la valeur du dernier point daté au plus tard à date, la première
+// valeur avant le premier point ; ok est faux pour une série vide
+func LastKnown[V any](s Series[V], date time.Time) (v V, ok bool) {
+	if len(s) == 0 {
+		return v, false
+	}
+	i := s.Search(date)
+	if i == 0 {
+		return s[0].Value, true
+	}
+	return s[i-1].Value, true
+}
+
+// Interpolate interpole linéairement une série de réels à une date, la valeur étant
+// prolongée avant le premier point et après le dernier ; ok est faux pour une série vide
+func Interpolate(s Series[float64], date time.Time) (v float64, ok bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	i := s.Search(date)
+	if i == 0 {
+		return s[0].Value, true
+	}
+	before := s[i-1]
+	if i == len(s) || before.Date.Equal(date) {
+		return before.Value, true
+	}
+	after := s[i]
+	weight := date.Sub(before.Date).Hours() / after.Date.Sub(before.Date).Hours()
+	return before.Value + weight*(after.Value-before.Value), true
+}
+
+// Change retourne l'écart de la série interpolée entre from et to : appliqué à un indice
+// logarithmique, le rendement logarithmique de la période ; ok est faux pour une série vide
+func Change(s Series[float64], from, to time.Time) (float64, bool) {
+	start, ok := Interpolate(s, from)
+	if !ok {
+		return 0, false
+	}
+	end, _ := Interpolate(s, to)
+	return end - start, true
+}
+
+// Resample retourne la série aux dates add(from, 0), add(from, 1), ... jusqu'à to
+// inclus, chaque valeur étant obtenue par at (Interpolate ou LastKnown). add avance
+// from de n pas calendaires et peut refuser un pas inconnu. La série rééchantillonnée
+// d'une série vide est vide.
+func Resample[V any](s Series[V], from, to time.Time, add func(from time.Time, n int) (time.Time, error), at func(Series[V], time.Time) (V, bool)) (Series[V], error) {
+	var out Series[V]
+	for n := 0; ; n++ {
+		date, err := add(from, n)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(to) {
+			return out, nil
+		}
+		v, ok := at(s, date)
+		if !ok {
+			return nil, nil
+		}
+		out = append(out, Point[V]{Date: date, Value: v})
+	}
+}
+
+// Diff retourne les écarts entre points successifs, datés du second point : appliquée à
+// un indice logarithmique, les rendements logarithmiques de chaque période
+func Diff(s Series[float64]) Series[float64] {
+	if len(s) < 2 {
+		return nil
+	}
+	out := make(Series[float64], len(s)-1)
+	for i := 1; i < len(s); i++ {
+		out[i-1] = Point[float64]{Date: s[i].Date, Value: s[i].Value - s[i-1].Value}
+	}
+	return out
+}
+
+// Join aligne deux séries sur leurs dates communes et retourne leurs valeurs à ces dates
+func Join[A, B any](a Series[A], b Series[B]) (Series[A], Series[B]) {
+	var ja Series[A]
+	var jb Series[B]
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].Date.Before(b[j].Date):
+			i++
+		case b[j].Date.Before(a[i].Date):
+			j++
+		default:
+			ja, jb = append(ja, a[i]), append(jb, b[j])
+			i, j = i+1, j+1
+		}
+	}
+	return ja, jb
+}
+
+// Rolling applique fn aux fenêtres [start, start+window] décalées de step, de la
+// première date de la série jusqu'à la dernière fenêtre entièrement couverte
+func Rolling[V, R any](s Series[V], window, step time.Duration, fn func(start, end time.Time) R) []R {
+	if len(s) == 0 || window <= 0 || step <= 0 {
+		return nil
+	}
+	var out []R
+	last := s.Last()
+	for start := s.First(); !start.Add(window).After(last); start = start.Add(step) {
+		out = append(out, fn(start, start.Add(window)))
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package timeseries
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+// series construit une série de réels aux jours de janvier 2024 donnés par paires
+// (jour, valeur)
+func series(pairs ...float64) Series[float64] {
+	var s Series[float64]
+	for i := 0; i < len(pairs); i += 2 {
+		s = append(s, Point[float64]{Date: day(int(pairs[i])), Value: pairs[i+1]})
+	}
+	return s
+}
+
+func TestLastKnown(t *testing.T) {
+	s := series(5, 10, 10, 20, 20, 30)
+	tests := []struct {
+		name   string
+		s      Series[float64]
+		date   time.Time
+		want   float64
+		wantOK bool
+	}{
+		{name: "série vide", s: nil, date: day(10)},
+		{name: "avant le premier point", s: s, date: day(1), want: 10, wantOK: true},
+		{name: "sur un point", s: s, date: day(10), want: 20, wantOK: true},
+		{name: "entre deux points", s: s, date: day(15), want: 20, wantOK: true},
+		{name: "après le dernier point", s: s, date: day(31), want: 30, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LastKnown(tt.s, tt.date)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("LastKnown = %v, %v ; %v, %v attendu", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	s := series(5, 10, 15, 20, 25, 40)
+	tests := []struct {
+		name   string
+		s      Series[float64]
+		date   time.Time
+		want   float64
+		wantOK bool
+	}{
+		{name: "série vide", s: nil, date: day(10)},
+		{name: "un seul point", s: series(5, 10), date: day(20), want: 10, wantOK: true},
+		{name: "avant le premier point", s: s, date: day(1), want: 10, wantOK: true},
+		{name: "sur un point", s: s, date: day(15), want: 20, wantOK: true},
+		{name: "milieu", s: s, date: day(10), want: 15, wantOK: true},
+		{name: "quart", s: s, date: day(17), want: 24, wantOK: true},
+		{name: "après le dernier point", s: s, date: day(31), want: 40, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Interpolate(tt.s, tt.date)
+			if math.Abs(got-tt.want) > 1e-9 || ok != tt.wantOK {
+				t.Errorf("Interpolate = %v, %v ; %v, %v attendu", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestChange(t *testing.T) {
+	s := series(1, 0, 11, 1, 21, 3)
+	tests := []struct {
+		name     string
+		s        Series[float64]
+		from, to time.Time
+		want     float64
+		wantOK   bool
+	}{
+		{name: "série vide", from: day(1), to: day(21)},
+		{name: "série entière", s: s, from: day(1), to: day(21), want: 3, wantOK: true},
+		{name: "entre deux points", s: s, from: day(6), to: day(16), want: 1.5, wantOK: true},
+		{name: "période nulle", s: s, from: day(11), to: day(11), want: 0, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Change(tt.s, tt.from, tt.to)
+			if math.Abs(got-tt.want) > 1e-9 || ok != tt.wantOK {
+				t.Errorf("Change = %v, %v ; %v, %v attendu", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResample(t *testing.T) {
+	weekly := func(from time.Time, n int) (time.Time, error) { return from.AddDate(0, 0, 7*n), nil }
+	tests := []struct {
+		name string
+		s    Series[float64]
+		at   func(Series[float64], time.Time) (float64, bool)
+		want Series[float64]
+	}{
+		{name: "série vide", at: Interpolate},
+		{name: "interpolation", s: series(1, 0, 29, 28), at: Interpolate, want: series(1, 0, 8, 7, 15, 14, 22, 21, 29, 28)},
+		{name: "dernière valeur", s: series(1, 0, 10, 5, 20, 9), at: LastKnown[float64], want: series(1, 0, 8, 0, 15, 5, 22, 9, 29, 9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resample(tt.s, day(1), day(29), weekly, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Resample = %v, %v attendu", got, tt.want)
+			}
+		})
+	}
+
+	refuse := func(time.Time, int) (time.Time, error) { return time.Time{}, fmt.Errorf("pas inconnu") }
+	if _, err := Resample(series(1, 0), day(1), day(29), refuse, Interpolate); err == nil {
+		t.Error("Resample avec un pas refusé: erreur attendue")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Series[float64]
+		want Series[float64]
+	}{
+		{name: "série vide"},
+		{name: "un seul point", s: series(1, 5)},
+		{name: "écarts", s: series(1, 5, 2, 7, 3, 4), want: series(2, 2, 3, -3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.s); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Diff = %v, %v attendu", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b         Series[float64]
+		wantA, wantB Series[float64]
+	}{
+		{name: "séries vides"},
+		{name: "une série vide", a: series(1, 1, 2, 2)},
+		{name: "dates communes", a: series(1, 1, 2, 2, 4, 4), b: series(2, 20, 3, 30, 4, 40), wantA: series(2, 2, 4, 4), wantB: series(2, 20, 4, 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Join(tt.a, tt.b)
+			if fmt.Sprint(a) != fmt.Sprint(tt.wantA) || fmt.Sprint(b) != fmt.Sprint(tt.wantB) {
+				t.Errorf("Join = %v, %v ; %v, %v attendu", a, b, tt.wantA, tt.wantB)
+			}
+		})
+	}
+}
+
+func TestRolling(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	s := series(1, 0, 29, 28)
+	tests := []struct {
+		name         string
+		s            Series[float64]
+		window, step time.Duration
+		want         []string
+	}{
+		{name: "série vide", window: week, step: week},
+		{name: "fenêtre nulle", s: s, step: week},
+		{name: "pas nul", s: s, window: week},
+		{name: "fenêtre trop longue", s: s, window: 30 * 24 * time.Hour, step: week},
+		{name: "fenêtres", s: s, window: 2 * week, step: week, want: []string{"01-15", "08-22", "15-29"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Rolling(tt.s, tt.window, tt.step, func(start, end time.Time) string {
+				return fmt.Sprintf("%02d-%02d", start.Day(), end.Day())
+			})
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Rolling = %v, %v attendu", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstLast(t *testing.T) {
+	var empty Series[int]
+	if !empty.First().IsZero() || !empty.Last().IsZero() {
+		t.Error("série vide: dates nulles attendues")
+	}
+	s := series(3, 1, 9, 2)
+	if !s.First().Equal(day(3)) || !s.Last().Equal(day(9)) {
+		t.Errorf("First, Last = %s, %s ; 3 et 9 janvier attendus", s.First(), s.Last())
+	}
+	if got := Values(s); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Values = %v, [1 2] attendu", got)
+	}
+}